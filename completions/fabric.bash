@@ -1,4 +1,4 @@
-# Bash completion for fabric CLI
+# Bash completion for fabric CLI, generated by fabric --completion bash
 #
 # Installation:
 # 1. Place this file in a standard completion directory, e.g.,
@@ -11,13 +11,12 @@
 _fabric() {
   local cur prev words cword
   if declare -F _comp_get_words &>/dev/null; then
-      _comp_get_words cur prev words cword
-   else
-      _get_comp_words_by_ref cur prev words cword
-   fi
+    _comp_get_words cur prev words cword
+  else
+    _get_comp_words_by_ref cur prev words cword
+  fi
 
-  # Define all possible options/flags
-  local opts="--pattern -p --variable -v --context -C --session --attachment -a --setup -S --temperature -t --topp -T --stream -s --presencepenalty -P --raw -r --frequencypenalty -F --listpatterns -l --readpattern --listmodels -L --listcontexts -x --listsessions -X --updatepatterns -U --copy -c --model -m --vendor -V --modelContextLength --output -o --output-session --latest -n --changeDefaultModel -d --youtube -y --playlist --transcript --transcript-with-timestamps --visual --visual-sensitivity --visual-fps --comments --metadata --yt-dlp-args --language -g --scrape_url -u --scrape_question -q --seed -e --thinking --wipecontext -w --wipesession -W --printcontext --printsession --readability --input-has-vars --no-variable-replacement --dry-run --serve --serveOllama --address --api-key --config --search --search-location --image-file --image-size --image-quality --image-compression --image-background --suppress-think --think-start-tag --think-end-tag --disable-responses-api --transcribe-file --transcribe-model --split-media-file --voice --list-gemini-voices --notification --notification-command --debug --version --listextensions --addextension --rmextension --strategy --liststrategies --listvendors --shell-complete-list --help -h"
+  local opts="--pattern -p --variable -v --context -C --context-var --context-cmd --session --session-max-messages --session-max-tokens --session-ttl --session-summarize --session-title --session-tags --session-sort --session-search --search-sessions --resume --attachment -a --doc --ocr --ocr-lang --ocr-model --image-max-dim --resize-images --strip-exif --convert-heic --setup -S --setup-vendor --setup-key --setup-url --setup-set --setup-default-model --temperature -t --topp -T --stream -s --presencepenalty -P --raw -r --model-param --logprobs --top-logprobs --frequencypenalty -F --listpatterns -l --tags --search-patterns --json --readpattern --listmodels -L --listcontexts -x --listsessions -X --spend --updatepatterns -U --sync --copy -c --model -m --vendor -V --modelContextLength --max-output-tokens --max-cost --keep-alive --num-gpu --num-thread --num-batch --mirostat --truncate --timeout --output -o --output-session --output-template --output-dir --output-name --print-path --latest -n --changeDefaultModel -d --youtube -y --playlist --transcript --transcript-with-timestamps --visual --visual-sensitivity --visual-fps --comments --metadata --yt-dlp-args --spotify --readwise --raindrop --notion-page --jira --linear --meeting --k8s --k8s-context --logs --since --follow --follow-interval --scan --rss --rss-limit --rss-transcribe --language -g --translate-output --scrape_url -u --scrape-native --scrape-js --scrape-no-sandbox --scrape_question -q --seed -e --deterministic --wipecontext -w --wipesession -W --printcontext --printsession --readability --md-keep-links --md-keep-images --input-has-vars --no-variable-replacement --dry-run --record --replay --proxy --ca-cert --serve --serveOllama --serve-slack --serve-discord --serve-telegram --serve-email --schedule --input-list --workflow --workflow-target --csv --csv-input-col --csv-output-col --csv-concurrency --watch --shell --tui --stdio-json --address --api-key --tls-cert --tls-key --tls-client-ca --cors-origin --trusted-proxy --max-concurrent --base-path --job-webhook --serve-cache --serve-cache-ttl --serve-state --config --doctor --version --listextensions --addextension --rmextension --strategy --refine --refine-threshold --refine-pattern --n --select --judge-pattern --liststrategies --listvendors --shell-complete-list --completion --search --search-location --provider-order --provider-sort --no-provider-fallbacks --image-file --image-size --image-quality --image-compression --image-background --suppress-think --think-start-tag --think-end-tag --disable-responses-api --transcribe-file --transcribe-model --split-media-file --voice --listen --tts-model --embed --embed-model --embed-file --embed-format --rerank --query --rerank-model --rerank-top --list-gemini-voices --list-transcription-models --notification --notification-command --webhook --webhook-secret --thinking --reasoning-effort --thinking-budget --show-think --think-output --post --diff --diff-style --apply --apply-code --render-mermaid --anki-deck --notion-append --stix --misp --post-comment --redact --redact-map --moderate --moderation-provider --pre-hook --post-hook --mcp --allow-browser --allow-exec --exec-sandbox --exec-timeout --exec-memory --allow-write --yes --show-metadata --quiet --plain --auto-model --debug --log-level --log-format --log-file --help -h"
 
   # Helper function for dynamic completions
   _fabric_get_list() {
@@ -26,18 +25,22 @@ _fabric() {
 
   # Handle completions based on the previous word
   case "${prev}" in
-  -p | --pattern | --readpattern)
+  -p | --pattern | --readpattern | --refine-pattern)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listpatterns)" -- "${cur}"))
     return 0
     ;;
-  -C | --context)
+  -C | --context | -w | --wipecontext | --printcontext)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listcontexts)" -- "${cur}"))
     return 0
     ;;
-  --session)
+  --session | -W | --wipesession | --printsession)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listsessions)" -- "${cur}"))
     return 0
     ;;
+  --sync)
+    COMPREPLY=($(compgen -W "push pull" -- "${cur}"))
+    return 0
+    ;;
   -m | --model)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listmodels)" -- "${cur}"))
     return 0
@@ -46,70 +49,97 @@ _fabric() {
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listvendors)" -- "${cur}"))
     return 0
     ;;
-  -w | --wipecontext)
-    COMPREPLY=($(compgen -W "$(_fabric_get_list --listcontexts)" -- "${cur}"))
+  --truncate)
+    COMPREPLY=($(compgen -W "head tail middle" -- "${cur}"))
     return 0
     ;;
-  -W | --wipesession)
-    COMPREPLY=($(compgen -W "$(_fabric_get_list --listsessions)" -- "${cur}"))
+  --rmextension)
+    COMPREPLY=($(compgen -W "$(_fabric_get_list --listextensions)" -- "${cur}"))
     return 0
     ;;
-  --printcontext)
-    COMPREPLY=($(compgen -W "$(_fabric_get_list --listcontexts)" -- "${cur}"))
+  --strategy)
+    COMPREPLY=($(compgen -W "$(_fabric_get_list --liststrategies)" -- "${cur}"))
     return 0
     ;;
-  --printsession)
-    COMPREPLY=($(compgen -W "$(_fabric_get_list --listsessions)" -- "${cur}"))
+  --completion)
+    COMPREPLY=($(compgen -W "zsh bash fish" -- "${cur}"))
     return 0
     ;;
-  --thinking)
-    COMPREPLY=($(compgen -W "off low medium high" -- "${cur}"))
+  --provider-sort)
+    COMPREPLY=($(compgen -W "price throughput latency" -- "${cur}"))
     return 0
     ;;
-  --rmextension)
-    COMPREPLY=($(compgen -W "$(_fabric_get_list --listextensions)" -- "${cur}"))
+  --image-size)
+    COMPREPLY=($(compgen -W "1024x1024 1536x1024 1024x1536 auto" -- "${cur}"))
     return 0
     ;;
-  --strategy)
-    COMPREPLY=($(compgen -W "$(_fabric_get_list --liststrategies)" -- "${cur}"))
+  --image-quality)
+    COMPREPLY=($(compgen -W "low medium high auto" -- "${cur}"))
     return 0
     ;;
-  --voice)
-    COMPREPLY=($(compgen -W "$(_fabric_get_list --list-gemini-voices)" -- "${cur}"))
+  --image-background)
+    COMPREPLY=($(compgen -W "opaque transparent" -- "${cur}"))
     return 0
     ;;
   --transcribe-model)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --list-transcription-models)" -- "${cur}"))
     return 0
     ;;
-  --debug)
-    COMPREPLY=($(compgen -W "0 1 2 3 4" -- "${cur}"))
+  --voice)
+    COMPREPLY=($(compgen -W "$(_fabric_get_list --list-gemini-voices)" -- "${cur}"))
     return 0
     ;;
-  # Options requiring file/directory paths
-  -a | --attachment | -o | --output | --config | --addextension | --image-file | --transcribe-file)
-    _filedir
+  --embed-format)
+    COMPREPLY=($(compgen -W "json jsonl" -- "${cur}"))
     return 0
     ;;
-  # Image generation options with specific values
-  --image-size)
-    COMPREPLY=($(compgen -W "1024x1024 1536x1024 1024x1536 auto" -- "$cur"))
+  --thinking)
+    COMPREPLY=($(compgen -W "off low medium high" -- "${cur}"))
     return 0
     ;;
-  --image-quality)
-    COMPREPLY=($(compgen -W "low medium high auto" -- "$cur"))
+  --reasoning-effort)
+    COMPREPLY=($(compgen -W "low medium high" -- "${cur}"))
     return 0
     ;;
-  --image-background)
-    COMPREPLY=($(compgen -W "opaque transparent" -- "$cur"))
+  --show-think)
+    COMPREPLY=($(compgen -W "dim stderr" -- "${cur}"))
+    return 0
+    ;;
+  --diff-style)
+    COMPREPLY=($(compgen -W "unified side-by-side" -- "${cur}"))
+    return 0
+    ;;
+  --moderate)
+    COMPREPLY=($(compgen -W "block annotate" -- "${cur}"))
+    return 0
+    ;;
+  --moderation-provider)
+    COMPREPLY=($(compgen -W "openai local" -- "${cur}"))
     return 0
     ;;
-  # Options requiring simple arguments (no specific completion logic here)
-  -v | --variable | -t | --temperature | -T | --topp | -P | --presencepenalty | -F | --frequencypenalty | --modelContextLength | -n | --latest | -y | --youtube | --visual-sensitivity | --visual-fps | --yt-dlp-args | -g | --language | -u | --scrape_url | -q | --scrape_question | -e | --seed | --address | --api-key | --search-location | --image-compression | --think-start-tag | --think-end-tag | --notification-command)
-    # No specific completion suggestions, user types the value
+  --exec-sandbox)
+    COMPREPLY=($(compgen -W "docker firejail" -- "${cur}"))
     return 0
     ;;
-  --spotify)
+  --debug)
+    COMPREPLY=($(compgen -W "0 1 2 3 4" -- "${cur}"))
+    return 0
+    ;;
+  --log-level)
+    COMPREPLY=($(compgen -W "debug info warn error" -- "${cur}"))
+    return 0
+    ;;
+  --log-format)
+    COMPREPLY=($(compgen -W "text json" -- "${cur}"))
+    return 0
+    ;;
+  # Options requiring file/directory paths
+  -a | --attachment | --doc | -o | --output | --output-template | --output-dir | --meeting | --logs | --scan | --record | --replay | --ca-cert | --csv | --watch | --tls-cert | --tls-key | --tls-client-ca | --config | --addextension | --image-file | --transcribe-file | --embed-file | --think-output | --diff | --apply-code | --render-mermaid | --anki-deck | --stix | --redact-map | --allow-write | --log-file)
+    _filedir
+    return 0
+    ;;
+  # Options requiring simple arguments, typed by the user
+  -v | --variable | --context-var | --context-cmd | --session-max-messages | --session-max-tokens | --session-ttl | --session-title | --session-tags | --session-sort | --session-search | --search-sessions | --ocr-lang | --ocr-model | --image-max-dim | --setup-vendor | --setup-key | --setup-url | --setup-set | --setup-default-model | -t | --temperature | -T | --topp | -P | --presencepenalty | --model-param | --top-logprobs | -F | --frequencypenalty | --tags | --search-patterns | --modelContextLength | --max-output-tokens | --max-cost | --keep-alive | --num-gpu | --num-thread | --num-batch | --mirostat | --timeout | --output-name | -n | --latest | -y | --youtube | --visual-sensitivity | --visual-fps | --yt-dlp-args | --spotify | --readwise | --raindrop | --notion-page | --jira | --linear | --k8s | --k8s-context | --since | --follow-interval | --rss | --rss-limit | -g | --language | --translate-output | -u | --scrape_url | -q | --scrape_question | -e | --seed | --proxy | --schedule | --input-list | --workflow | --workflow-target | --csv-input-col | --csv-output-col | --csv-concurrency | --address | --api-key | --cors-origin | --trusted-proxy | --max-concurrent | --base-path | --job-webhook | --serve-cache | --serve-cache-ttl | --serve-state | --refine | --refine-threshold | --n | --select | --judge-pattern | --search-location | --provider-order | --image-compression | --think-start-tag | --think-end-tag | --tts-model | --embed-model | --query | --rerank-model | --rerank-top | --notification-command | --webhook | --webhook-secret | --thinking-budget | --post | --notion-append | --pre-hook | --post-hook | --mcp | --exec-timeout | --exec-memory)
     return 0
     ;;
   esac
@@ -120,11 +150,7 @@ _fabric() {
     return 0
   fi
 
-  # Default: complete files/directories if no other rule matches
-  # _filedir
-  # Or provide no completions if it's not an option or argument following a known flag
   COMPREPLY=()
-
 }
 
 complete -F _fabric fabric fabric-ai
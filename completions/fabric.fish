@@ -1,4 +1,4 @@
-# Fish shell completion for fabric CLI
+# Fish shell completion for fabric CLI, generated by fabric --completion fish
 #
 # Installation:
 # Copy this file to ~/.config/fish/completions/fabric.fish
@@ -6,143 +6,259 @@
 # mkdir -p ~/.config/fish/completions
 # cp completions/fabric.fish ~/.config/fish/completions/
 
-# Helper functions for dynamic completions
-function __fabric_get_patterns
+# Helper function for dynamic completions
+function __fabric_list
         set cmd (commandline -opc)[1]
-        $cmd --listpatterns --shell-complete-list 2>/dev/null
+        $cmd $argv[1] --shell-complete-list 2>/dev/null
 end
 
-function __fabric_get_models
-        set cmd (commandline -opc)[1]
-        $cmd --listmodels --shell-complete-list 2>/dev/null
-end
-
-function __fabric_get_vendors
-        set cmd (commandline -opc)[1]
-        $cmd --listvendors --shell-complete-list 2>/dev/null
-end
-
-function __fabric_get_contexts
-        set cmd (commandline -opc)[1]
-        $cmd --listcontexts --shell-complete-list 2>/dev/null
-end
-
-function __fabric_get_sessions
-        set cmd (commandline -opc)[1]
-        $cmd --listsessions --shell-complete-list 2>/dev/null
-end
-
-function __fabric_get_strategies
-        set cmd (commandline -opc)[1]
-        $cmd --liststrategies --shell-complete-list 2>/dev/null
-end
-
-function __fabric_get_extensions
-        set cmd (commandline -opc)[1]
-        $cmd --listextensions --shell-complete-list 2>/dev/null
-end
-
-function __fabric_get_gemini_voices
-        set cmd (commandline -opc)[1]
-        $cmd --list-gemini-voices --shell-complete-list 2>/dev/null
-end
-
-function __fabric_get_transcription_models
-        set cmd (commandline -opc)[1]
-        $cmd --list-transcription-models --shell-complete-list 2>/dev/null
-end
-
-# Main completion function
 function __fabric_register_completions
         set cmd $argv[1]
         complete -c $cmd -f
 
-        # Flag completions with arguments
-        complete -c $cmd -s p -l pattern -d "Choose a pattern from the available patterns" -a "(__fabric_get_patterns)"
-        complete -c $cmd -l readpattern -d "Print the contents of the named pattern to the terminal" -a "(__fabric_get_patterns)"
-        complete -c $cmd -s v -l variable -d "Values for pattern variables, e.g. -v=#role:expert -v=#points:30"
-        complete -c $cmd -s C -l context -d "Choose a context from the available contexts" -a "(__fabric_get_contexts)"
-        complete -c $cmd -l session -d "Choose a session from the available sessions" -a "(__fabric_get_sessions)"
-        complete -c $cmd -s a -l attachment -d "Attachment path or URL (e.g. for OpenAI image recognition messages)" -r
-        complete -c $cmd -s t -l temperature -d "Set temperature (default: 0.7)"
-        complete -c $cmd -s T -l topp -d "Set top P (default: 0.9)"
-        complete -c $cmd -s P -l presencepenalty -d "Set presence penalty (default: 0.0)"
-        complete -c $cmd -s F -l frequencypenalty -d "Set frequency penalty (default: 0.0)"
-        complete -c $cmd -s m -l model -d "Choose model" -a "(__fabric_get_models)"
-        complete -c $cmd -s V -l vendor -d "Specify vendor for chosen model (e.g., -V \"LM Studio\" -m openai/gpt-oss-20b)" -a "(__fabric_get_vendors)"
-        complete -c $cmd -l modelContextLength -d "Model context length (only affects ollama)"
-        complete -c $cmd -s o -l output -d "Output to file" -r
-        complete -c $cmd -s n -l latest -d "Number of latest patterns to list (default: 0)"
-        complete -c $cmd -s y -l youtube -d "YouTube video or play list URL to grab transcript, comments from it"
-        complete -c $cmd -l visual-sensitivity -d "Tolerance for FFmpeg scene detection (0.0 - 1.0)"
-        complete -c $cmd -l visual-fps -d "Extract a specific number of frames per second instead of using scene detection"
-        complete -c $cmd -s g -l language -d "Specify the Language Code for the chat, e.g. -g=en -g=zh"
-        complete -c $cmd -s u -l scrape_url -d "Scrape website URL to markdown using Jina AI"
-        complete -c $cmd -s q -l scrape_question -d "Search question using Jina AI"
-        complete -c $cmd -s e -l seed -d "Seed to be used for LMM generation"
-        complete -c $cmd -l thinking -d "Set reasoning/thinking level" -a "off low medium high"
-        complete -c $cmd -s w -l wipecontext -d "Wipe context" -a "(__fabric_get_contexts)"
-        complete -c $cmd -s W -l wipesession -d "Wipe session" -a "(__fabric_get_sessions)"
-        complete -c $cmd -l printcontext -d "Print context" -a "(__fabric_get_contexts)"
-        complete -c $cmd -l printsession -d "Print session" -a "(__fabric_get_sessions)"
-        complete -c $cmd -l address -d "The address to bind the REST API (default: :8080)"
-        complete -c $cmd -l api-key -d "API key used to secure server routes"
-        complete -c $cmd -l config -d "Path to YAML config file" -r -a "*.yaml *.yml"
-        complete -c $cmd -l search-location -d "Set location for web search results (e.g., 'America/Los_Angeles')"
-        complete -c $cmd -l image-file -d "Save generated image to specified file path (e.g., 'output.png')" -r -a "*.png *.webp *.jpeg *.jpg"
-        complete -c $cmd -l image-size -d "Image dimensions: 1024x1024, 1536x1024, 1024x1536, auto (default: auto)" -a "1024x1024 1536x1024 1024x1536 auto"
-        complete -c $cmd -l image-quality -d "Image quality: low, medium, high, auto (default: auto)" -a "low medium high auto"
-        complete -c $cmd -l image-compression -d "Compression level 0-100 for JPEG/WebP formats (default: not set)" -r
-        complete -c $cmd -l image-background -d "Background type: opaque, transparent (default: opaque, only for PNG/WebP)" -a "opaque transparent"
-        complete -c $cmd -l addextension -d "Register a new extension from config file path" -r -a "*.yaml *.yml"
-        complete -c $cmd -l rmextension -d "Remove a registered extension by name" -a "(__fabric_get_extensions)"
-        complete -c $cmd -l strategy -d "Choose a strategy from the available strategies" -a "(__fabric_get_strategies)"
-        complete -c $cmd -l think-start-tag -d "Start tag for thinking sections (default: <think>)"
-        complete -c $cmd -l think-end-tag -d "End tag for thinking sections (default: </think>)"
-        complete -c $cmd -l voice -d "TTS voice name for supported models (e.g., Kore, Charon, Puck)" -a "(__fabric_get_gemini_voices)"
-        complete -c $cmd -l transcribe-file -d "Audio or video file to transcribe" -r -a "*.mp3 *.mp4 *.mpeg *.mpga *.m4a *.wav *.webm"
-        complete -c $cmd -l transcribe-model -d "Model to use for transcription (separate from chat model)" -a "(__fabric_get_transcription_models)"
-        complete -c $cmd -l debug -d "Set debug level (0=off, 1=basic, 2=detailed, 3=trace, 4=wire)" -a "0 1 2 3 4"
-        complete -c $cmd -l notification-command -d "Custom command to run for notifications (overrides built-in notifications)"
-
-        # Boolean flags (no arguments)
-        complete -c $cmd -s S -l setup -d "Run setup for all reconfigurable parts of fabric"
-        complete -c $cmd -s s -l stream -d "Stream"
-        complete -c $cmd -s r -l raw -d "Use the defaults of the model without sending chat options. Only affects OpenAI-compatible providers. Anthropic models always use smart parameter selection to comply with model-specific requirements."
-        complete -c $cmd -s l -l listpatterns -d "List all patterns"
-        complete -c $cmd -s L -l listmodels -d "List all available models"
-        complete -c $cmd -s x -l listcontexts -d "List all contexts"
-        complete -c $cmd -s X -l listsessions -d "List all sessions"
-        complete -c $cmd -s U -l updatepatterns -d "Update patterns"
-        complete -c $cmd -s c -l copy -d "Copy to clipboard"
-        complete -c $cmd -l output-session -d "Output the entire session to the output file"
-        complete -c $cmd -s d -l changeDefaultModel -d "Change default model"
-        complete -c $cmd -l playlist -d "Prefer playlist over video if both ids are present in the URL"
-        complete -c $cmd -l transcript -d "Grab transcript from YouTube video and send to chat"
-        complete -c $cmd -l transcript-with-timestamps -d "Grab transcript from YouTube video with timestamps"
-        complete -c $cmd -l visual -d "Extract visual data from video using OCR and FFmpeg"
-        complete -c $cmd -l comments -d "Grab comments from YouTube video and send to chat"
-        complete -c $cmd -l metadata -d "Output video metadata"
-        complete -c $cmd -l yt-dlp-args -d "Additional arguments to pass to yt-dlp (e.g. '--cookies-from-browser brave')"
-        complete -c $cmd -l readability -d "Convert HTML input into a clean, readable view"
-        complete -c $cmd -l input-has-vars -d "Apply variables to user input"
-        complete -c $cmd -l no-variable-replacement -d "Disable pattern variable replacement"
-        complete -c $cmd -l dry-run -d "Show what would be sent to the model without actually sending it"
-        complete -c $cmd -l search -d "Enable web search tool for supported models (Anthropic, OpenAI, Gemini)"
-        complete -c $cmd -l serve -d "Serve the Fabric Rest API"
-        complete -c $cmd -l serveOllama -d "Serve the Fabric Rest API with ollama endpoints"
-        complete -c $cmd -l version -d "Print current version"
-        complete -c $cmd -l listextensions -d "List all registered extensions"
-        complete -c $cmd -l liststrategies -d "List all strategies"
-        complete -c $cmd -l listvendors -d "List all vendors"
-        complete -c $cmd -l list-gemini-voices -d "List all available Gemini TTS voices"
-        complete -c $cmd -l shell-complete-list -d "Output raw list without headers/formatting (for shell completion)"
-        complete -c $cmd -l suppress-think -d "Suppress text enclosed in thinking tags"
-        complete -c $cmd -l disable-responses-api -d "Disable OpenAI Responses API (default: false)"
-        complete -c $cmd -l split-media-file -d "Split audio/video files larger than 25MB using ffmpeg"
-        complete -c $cmd -l notification -d "Send desktop notification when command completes"
-        complete -c $cmd -s h -l help -d "Show this help message"
-        complete -c $cmd -l spotify -d 'Spotify podcast or episode URL to grab metadata'
+        complete -c $cmd -s p -l pattern -d 'Choose a pattern from the available patterns' -a "(__fabric_list --listpatterns)" -r
+        complete -c $cmd -s v -l variable -d 'Values for pattern variables, e.g. -v=#role:expert -v=#points:30' -r
+        complete -c $cmd -s C -l context -d 'Choose contexts from the available contexts, repeatable or comma separated and joined in order' -a "(__fabric_list --listcontexts)" -r
+        complete -c $cmd -l context-var -d 'Values for context variables, e.g. --context-var=project:fabric' -r
+        complete -c $cmd -l context-cmd -d 'Run the shell command and add its output to the context, after confirmation unless allowed by contextCmdAllow' -r
+        complete -c $cmd -l session -d 'Choose a session from the available sessions' -a "(__fabric_list --listsessions)" -r
+        complete -c $cmd -l session-max-messages -d 'Keep at most this many user and assistant messages in sessions, dropping the oldest' -r
+        complete -c $cmd -l session-max-tokens -d 'Keep sessions under this estimated number of tokens, dropping the oldest messages' -r
+        complete -c $cmd -l session-ttl -d 'Start sessions unused for longer than this duration (e.g. 72h) over' -r
+        complete -c $cmd -l session-summarize -d 'Summarize the oldest messages of sessions instead of dropping them, also when a session outgrows the context window'
+        complete -c $cmd -l session-title -d 'Title the session, instead of the first line of its first message' -r
+        complete -c $cmd -l session-tags -d 'Tag the session with these comma-separated tags, or with --listsessions list the sessions having all of them' -r
+        complete -c $cmd -l session-sort -d 'Order of --listsessions: name, title, created or updated, the last two newest first' -r
+        complete -c $cmd -l session-search -d 'List the sessions with this keyword in their name, title or tags' -r
+        complete -c $cmd -l search-sessions -d 'Search the messages of the saved sessions and the contexts for these words, or by meaning with --embed-model, and print the matching excerpts' -r
+        complete -c $cmd -l resume -d 'Continue the interrupted response of the session, or of the last chat without a session'
+        complete -c $cmd -s a -l attachment -d 'Attachment path or URL (e.g. for OpenAI image recognition messages)' -F -r
+        complete -c $cmd -l doc -d 'Convert this EPUB or DOCX file to Markdown, with chapter or page markers, and add it to the input; also done for EPUB and DOCX attachments (repeatable)' -F -r
+        complete -c $cmd -l ocr -d 'Recognize the text of the image and PDF attachments and add it to the input; PDFs without a text layer are always recognized'
+        complete -c $cmd -l ocr-lang -d 'Languages of the text recognized by Tesseract, as Tesseract codes, e.g. eng+deu' -r
+        complete -c $cmd -l ocr-model -d 'Recognize the text with this vision model instead of Tesseract, e.g. gpt-4o or OpenAI|gpt-4o' -r
+        complete -c $cmd -l image-max-dim -d 'Downscale image attachments so their longest side is at most this many pixels (0 = no limit)' -r
+        complete -c $cmd -l resize-images -d 'Downscale image attachments to the largest size the vendor takes, unless --image-max-dim is set'
+        complete -c $cmd -l strip-exif -d 'Strip EXIF and other metadata from image attachments before sending them'
+        complete -c $cmd -l convert-heic -d 'Convert HEIC/HEIF image attachments, which most vendors reject, to JPEG'
+        complete -c $cmd -s S -l setup -d 'Run setup for all reconfigurable parts of fabric'
+        complete -c $cmd -l setup-vendor -d 'Set up the vendor without asking questions, e.g. for containers and CI' -r
+        complete -c $cmd -l setup-key -d 'API key of the vendor set up by --setup-vendor' -r
+        complete -c $cmd -l setup-url -d 'API base URL of the vendor set up by --setup-vendor' -r
+        complete -c $cmd -l setup-set -d 'Other setting of the vendor set up by --setup-vendor, e.g. --setup-set=API_VERSION:2024-10-21' -r
+        complete -c $cmd -l setup-default-model -d 'Set the default model without asking questions' -r
+        complete -c $cmd -s t -l temperature -d 'Set temperature' -r
+        complete -c $cmd -s T -l topp -d 'Set top P' -r
+        complete -c $cmd -s s -l stream -d 'Stream'
+        complete -c $cmd -s P -l presencepenalty -d 'Set presence penalty' -r
+        complete -c $cmd -s r -l raw -d 'Use the defaults of the model without sending chat options (temperature, top_p, etc.). Only affects OpenAI-compatible providers. Anthropic models always use smart parameter selection to comply with model-specific requirements.'
+        complete -c $cmd -l model-param -d 'Merge key=value into the JSON request sent to the vendor, e.g. top_k=40 or response_format.type=json_object, the value being JSON or a string (can be used multiple times)' -r
+        complete -c $cmd -l logprobs -d 'Return the log probability of each token of the response with --json (OpenAI and compatible vendors)'
+        complete -c $cmd -l top-logprobs -d 'Also return this many most likely alternatives of each token, up to 20 (implies --logprobs)' -r
+        complete -c $cmd -s F -l frequencypenalty -d 'Set frequency penalty' -r
+        complete -c $cmd -s l -l listpatterns -d 'List all patterns'
+        complete -c $cmd -l tags -d 'List the patterns having all these comma-separated tags, with their description' -r
+        complete -c $cmd -l search-patterns -d 'List the patterns with this keyword in their name, description or tags' -r
+        complete -c $cmd -l json -d 'Print the pattern or session listing or the spend report as JSON, with the description, tags and variables of the patterns or the metadata of the sessions, or the response as JSON with its model and --logprobs'
+        complete -c $cmd -l readpattern -d 'Print the contents of the named pattern to the terminal' -a "(__fabric_list --listpatterns)" -r
+        complete -c $cmd -s L -l listmodels -d 'List all available models'
+        complete -c $cmd -s x -l listcontexts -d 'List all contexts'
+        complete -c $cmd -s X -l listsessions -d 'List all sessions'
+        complete -c $cmd -l spend -d 'Print the spend of today, this week and this month by vendor and model, or as JSON with --json'
+        complete -c $cmd -s U -l updatepatterns -d 'Update patterns'
+        complete -c $cmd -l sync -d 'Sync custom patterns, sessions and contexts with the backend set up with --setup: push or pull' -a "push pull" -r
+        complete -c $cmd -s c -l copy -d 'Copy to clipboard'
+        complete -c $cmd -s m -l model -d 'Choose model' -a "(__fabric_list --listmodels)" -r
+        complete -c $cmd -s V -l vendor -d 'Specify vendor for the selected model (e.g., -V "LM Studio" -m openai/gpt-oss-20b)' -a "(__fabric_list --listvendors)" -r
+        complete -c $cmd -l modelContextLength -d 'Model context length (only affects ollama)' -r
+        complete -c $cmd -l max-output-tokens -d 'Limit the response to this many tokens' -r
+        complete -c $cmd -l max-cost -d 'Abort the request when its estimated cost in USD is over this amount, before sending it or while it streams, e.g. --max-cost 0.50' -r
+        complete -c $cmd -l keep-alive -d 'How long the model stays loaded after the request, like 10m, or -1 for ever (only affects ollama)' -r
+        complete -c $cmd -l num-gpu -d 'Number of model layers offloaded to the GPUs, 0 for the CPU only (only affects ollama)' -r
+        complete -c $cmd -l num-thread -d 'Number of CPU threads, by default the number of physical cores (only affects ollama)' -r
+        complete -c $cmd -l num-batch -d 'Number of prompt tokens processed at once (only affects ollama)' -r
+        complete -c $cmd -l mirostat -d 'Sample with Mirostat for a steady perplexity: 1, 2 for Mirostat 2.0, or 0 for off (only affects ollama)' -r
+        complete -c $cmd -l truncate -d 'Truncate input exceeding the model context window instead of failing: head, tail or middle (the part dropped)' -a "head tail middle" -r
+        complete -c $cmd -l timeout -d 'Abort the request when it takes longer than this duration (e.g. 120s)' -r
+        complete -c $cmd -s o -l output -d 'Output to file' -F -r
+        complete -c $cmd -l output-session -d 'Output the entire session (also a temporary one) to the output file'
+        complete -c $cmd -l output-template -d 'Go template file or text for the output file, with {{.Output}}, {{.Pattern}}, {{.Title}}, {{.Model}}, {{.Vendor}}, {{.Date}} and {{.SourceURL}}' -F -r
+        complete -c $cmd -l output-dir -d 'Write the output to a new file in this directory, named after --output-name' -F -r
+        complete -c $cmd -l output-name -d 'File name template of --output-dir, with slugified fields (default: {{.Date}}-{{.Pattern}}-{{.Title}})' -r
+        complete -c $cmd -l print-path -d 'Print the path of the output file instead of the output'
+        complete -c $cmd -s n -l latest -d 'Number of latest patterns to list' -r
+        complete -c $cmd -s d -l changeDefaultModel -d 'Change default model'
+        complete -c $cmd -s y -l youtube -d 'YouTube video or play list "URL" to grab transcript, comments from it and send to chat or print it put to the console and store it in the output file' -r
+        complete -c $cmd -l playlist -d 'Prefer playlist over video if both ids are present in the URL'
+        complete -c $cmd -l transcript -d 'Grab transcript from YouTube video and send to chat (it is used per default).'
+        complete -c $cmd -l transcript-with-timestamps -d 'Grab transcript from YouTube video with timestamps and send to chat'
+        complete -c $cmd -l visual -d ''
+        complete -c $cmd -l visual-sensitivity -d '' -r
+        complete -c $cmd -l visual-fps -d '' -r
+        complete -c $cmd -l comments -d 'Grab comments from YouTube video and send to chat'
+        complete -c $cmd -l metadata -d 'Output video metadata'
+        complete -c $cmd -l yt-dlp-args -d 'Additional arguments to pass to yt-dlp (e.g. \'--cookies-from-browser brave\')' -r
+        complete -c $cmd -l spotify -d 'Spotify podcast or episode URL to grab metadata from and send to chat' -r
+        complete -c $cmd -l readwise -d 'Send the Readwise highlights of the query to chat, e.g. since:7d category:books tag:ai (default since:7d)' -r
+        complete -c $cmd -l raindrop -d 'Send the Raindrop.io bookmarks of the query to chat, e.g. since:7d collection:Reading tag:ai golang (default since:7d)' -r
+        complete -c $cmd -l notion-page -d 'Send the Notion page of this id or URL to chat as Markdown' -r
+        complete -c $cmd -l jira -d 'Send the Jira issue of this key or URL, e.g. PROJ-123, with its comments to chat' -r
+        complete -c $cmd -l linear -d 'Send the Linear issue of this identifier or URL, e.g. ENG-42, with its comments to chat' -r
+        complete -c $cmd -l meeting -d 'Send the transcript of a meeting to chat with its speakers: a .vtt, .srt or .txt file, zoom:<meeting ID or link> (zoom: for the latest recording), or meet:<ID or link> of a Google Meet transcript in Drive' -F -r
+        complete -c $cmd -l k8s -d 'Send the state of this Kubernetes namespace, or all, to chat: its failing pods, warning events and recent logs, size-limited and redacted, after confirmation unless --yes' -r
+        complete -c $cmd -l k8s-context -d 'Use this kubeconfig context for --k8s instead of the current one' -r
+        complete -c $cmd -l logs -d 'Send the log file, optionally gzipped, to chat condensed: timestamps normalized to UTC, repeated messages counted once and the rest sampled to a size limit, errors and warnings first' -F -r
+        complete -c $cmd -l since -d 'Only send the --logs entries of this last duration, e.g. 1h or 7d, or since this date or time' -r
+        complete -c $cmd -l follow -d 'Follow the file of --logs and run the chat on its new lines in batches, every --follow-interval'
+        complete -c $cmd -l follow-interval -d 'How often --follow sends the new lines of the log' -r
+        complete -c $cmd -l scan -d 'Send the report of a security tool to chat as structured Markdown: nmap XML, nuclei JSON or JSONL, or a Burp Suite XML issues export (repeatable)' -F -r
+        complete -c $cmd -l rss -d 'RSS or Atom feed URL; processes the latest entries one by one and writes one output file per entry (--output sets the directory)' -r
+        complete -c $cmd -l rss-limit -d 'Number of latest feed entries to process' -r
+        complete -c $cmd -l rss-transcribe -d 'Download and transcribe audio enclosures of feed entries (requires --transcribe-model)'
+        complete -c $cmd -s g -l language -d 'Specify the Language Code for the chat, e.g. -g=en -g=zh' -r
+        complete -c $cmd -l translate-output -d 'Translate the output into this language once complete, with a second request, e.g. --translate-output=fr' -r
+        complete -c $cmd -s u -l scrape_url -d 'Scrape website URL to markdown (uses Jina AI when configured, otherwise the built-in scraper)' -r
+        complete -c $cmd -l scrape-native -d 'Use the built-in scraper for --scrape_url even when Jina AI is configured'
+        complete -c $cmd -l scrape-js -d 'Render JavaScript with headless Chrome/Chromium before extracting content (built-in scraper only)'
+        complete -c $cmd -l scrape-no-sandbox -d 'Start headless Chrome without its sandbox for --scrape-js, for containers where it cannot start otherwise'
+        complete -c $cmd -s q -l scrape_question -d 'Search question using Jina AI' -r
+        complete -c $cmd -s e -l seed -d 'Seed to be used for LMM generation' -r
+        complete -c $cmd -l deterministic -d 'Sample reproducibly for pipelines and tests: temperature 0, top P 1 and the --seed or a fixed seed, over the other sampling options'
+        complete -c $cmd -s w -l wipecontext -d 'Wipe context' -a "(__fabric_list --listcontexts)" -r
+        complete -c $cmd -s W -l wipesession -d 'Wipe session' -a "(__fabric_list --listsessions)" -r
+        complete -c $cmd -l printcontext -d 'Print context' -a "(__fabric_list --listcontexts)" -r
+        complete -c $cmd -l printsession -d 'Print session' -a "(__fabric_list --listsessions)" -r
+        complete -c $cmd -l readability -d 'Convert HTML input into a clean, readable view'
+        complete -c $cmd -l md-keep-links -d 'Keep hyperlinks when converting HTML to Markdown (--readability, --scrape_url)'
+        complete -c $cmd -l md-keep-images -d 'Keep images instead of only their alt text when converting HTML to Markdown'
+        complete -c $cmd -l input-has-vars -d 'Apply variables to user input'
+        complete -c $cmd -l no-variable-replacement -d 'Disable pattern variable replacement'
+        complete -c $cmd -l dry-run -d 'Print the request that would be sent to the model as JSON, with token counts, without sending it'
+        complete -c $cmd -l record -d 'Record the HTTP requests to the AI vendors and their responses in a directory' -F -r
+        complete -c $cmd -l replay -d 'Answer the HTTP requests with the responses recorded by --record in a directory, without network' -F -r
+        complete -c $cmd -l proxy -d 'Send the HTTP requests through this proxy URL, except to the hosts of NO_PROXY (default: HTTPS_PROXY)' -r
+        complete -c $cmd -l ca-cert -d 'Trust the certificate authorities of this PEM file besides those of the system' -F -r
+        complete -c $cmd -l serve -d 'Serve the Fabric Rest API'
+        complete -c $cmd -l serveOllama -d 'Serve the Fabric Rest API with ollama endpoints'
+        complete -c $cmd -l serve-slack -d 'Run a Slack bot over Socket Mode answering mentions and /fabric with patterns (needs SLACK_APP_TOKEN and SLACK_BOT_TOKEN)'
+        complete -c $cmd -l serve-discord -d 'Run a Discord bot answering mentions and direct messages with patterns (needs DISCORD_BOT_TOKEN)'
+        complete -c $cmd -l serve-telegram -d 'Run a Telegram bot answering /fabric, mentions and private messages with patterns (needs TELEGRAM_BOT_TOKEN)'
+        complete -c $cmd -l serve-email -d 'Watch the configured mailbox and answer the mails matching the email rules of the config file with their patterns'
+        complete -c $cmd -l schedule -d 'Run a pattern on a source periodically, as "<cron> <source> <pattern>" with youtube:<channel>, rss:<feed URL>, url:<page URL>, readwise:<query> or raindrop:<query> sources (repeatable)' -r
+        complete -c $cmd -l input-list -d 'Run the chat on each URL or file path of this file, one per line, writing one output file per entry; entries with an output file are skipped when run again' -r
+        complete -c $cmd -l workflow -d 'Run the steps of a YAML workflow file on the input, also run as fabric run workflow.yaml' -r
+        complete -c $cmd -l workflow-target -d 'Run this target of the --workflow file and the targets it needs, unless their inputs are unchanged (repeatable)' -r
+        complete -c $cmd -l csv -d 'Run the chat on a column of each row of this CSV or TSV file, writing the file with the outputs in another column to --output or stdout; interrupted runs resume from a checkpoint' -F -r
+        complete -c $cmd -l csv-input-col -d 'Column of the --csv file to run the chat on, the other columns being pattern variables' -r
+        complete -c $cmd -l csv-output-col -d 'Column of the --csv file to write the outputs to, added when missing; rows with an output are skipped' -r
+        complete -c $cmd -l csv-concurrency -d 'Number of --csv rows to run at a time' -r
+        complete -c $cmd -l watch -d 'Run the chat on this file, then again whenever it changes; a directory is run on its changed files' -F -r
+        complete -c $cmd -l shell -d 'Suggest a shell command doing the request, run it once confirmed and send its output back with follow-up requests'
+        complete -c $cmd -l tui -d 'Browse the patterns with their README and pick the model, context and session in a terminal interface, then stream the output'
+        complete -c $cmd -l stdio-json -d 'Read line-delimited JSON chat requests from stdin and write the responses as JSON lines to stdout, for notebooks and editors running fabric as a child process'
+        complete -c $cmd -l address -d 'The address to bind the REST API' -r
+        complete -c $cmd -l api-key -d 'API key used to secure server routes' -r
+        complete -c $cmd -l tls-cert -d 'Serve HTTPS with this certificate file (PEM), reloaded when it changes' -F -r
+        complete -c $cmd -l tls-key -d 'Private key file (PEM) of --tls-cert' -F -r
+        complete -c $cmd -l tls-client-ca -d 'Require client certificates signed by this CA file (PEM), for mutual TLS' -F -r
+        complete -c $cmd -l cors-origin -d 'Let browser frontends of this origin call the REST API, * for any (can be used multiple times)' -r
+        complete -c $cmd -l trusted-proxy -d 'Take the client address from X-Forwarded-For when sent by this proxy address or CIDR range (can be used multiple times)' -r
+        complete -c $cmd -l max-concurrent -d 'Run at most this many vendor requests of the REST API at once, queueing the others fairly between clients (0 = no limit)' -r
+        complete -c $cmd -l base-path -d 'Serve the REST API under this path, e.g. /fabric behind a reverse proxy' -r
+        complete -c $cmd -l job-webhook -d 'Let the jobs of the REST API post their result to this webhook URL (can be used multiple times)' -r
+        complete -c $cmd -l serve-cache -d 'Answer identical /chat requests of the REST API with the cached response: memory, or a redis:// URL of a cache shared between servers' -r
+        complete -c $cmd -l serve-cache-ttl -d 'How long --serve-cache keeps the responses' -r
+        complete -c $cmd -l serve-state -d 'Keep the jobs and sessions of the REST API in a Redis server (redis:// URL) or PostgreSQL database (postgres:// URL) shared by several replicas, rather than in memory and the local files. The request queue and the resumable streams stay per replica' -r
+        complete -c $cmd -l config -d 'Path to YAML config file' -F -r
+        complete -c $cmd -l doctor -d 'Check the config file, vendor keys, data directories and patterns version, suggesting fixes'
+        complete -c $cmd -l version -d 'Print current version'
+        complete -c $cmd -l listextensions -d 'List all registered extensions'
+        complete -c $cmd -l addextension -d 'Register a new extension from config file path' -F -r
+        complete -c $cmd -l rmextension -d 'Remove a registered extension by name' -a "(__fabric_list --listextensions)" -r
+        complete -c $cmd -l strategy -d 'Choose a strategy from the available strategies, combine several with +' -a "(__fabric_list --liststrategies)" -r
+        complete -c $cmd -l refine -d 'Critique and revise the response up to this many times, stopping once the critique scores it --refine-threshold or higher' -r
+        complete -c $cmd -l refine-threshold -d 'Critique score out of 10 ending --refine early' -r
+        complete -c $cmd -l refine-pattern -d 'Pattern critiquing the response for --refine instead of the built-in critique, ending with a Score: N/10 line' -a "(__fabric_list --listpatterns)" -r
+        complete -c $cmd -l n -d 'Sample this many responses, up to 10, and combine them as --select says' -r
+        complete -c $cmd -l select -d 'How --n combines the responses: vote for the answer most agree on, best for the one a judge prefers, all to print every one' -r
+        complete -c $cmd -l judge-pattern -d 'Pattern picking the best response for --select best instead of the built-in judge, replying with its number' -r
+        complete -c $cmd -l liststrategies -d 'List all strategies'
+        complete -c $cmd -l listvendors -d 'List all vendors'
+        complete -c $cmd -l shell-complete-list -d 'Output raw list without headers/formatting (for shell completion)'
+        complete -c $cmd -l completion -d 'Print the completion script of the shell: zsh, bash or fish' -a "zsh bash fish" -r
+        complete -c $cmd -l search -d 'Enable web search tool for supported models (Anthropic, OpenAI, Gemini, Grok)'
+        complete -c $cmd -l search-location -d 'Set location for web search results (e.g., \'America/Los_Angeles\')' -r
+        complete -c $cmd -l provider-order -d 'Comma-separated upstream providers to try first (OpenRouter)' -r
+        complete -c $cmd -l provider-sort -d 'Prefer upstream providers by price, throughput or latency (OpenRouter)' -a "price throughput latency" -r
+        complete -c $cmd -l no-provider-fallbacks -d 'Only use the providers given by --provider-order (OpenRouter)'
+        complete -c $cmd -l image-file -d 'Save generated image to specified file path (e.g., \'output.png\')' -F -r
+        complete -c $cmd -l image-size -d 'Image dimensions: 1024x1024, 1536x1024, 1024x1536, auto (default: auto)' -a "1024x1024 1536x1024 1024x1536 auto" -r
+        complete -c $cmd -l image-quality -d 'Image quality: low, medium, high, auto (default: auto)' -a "low medium high auto" -r
+        complete -c $cmd -l image-compression -d 'Compression level 0-100 for JPEG/WebP formats (default: not set)' -r
+        complete -c $cmd -l image-background -d 'Background type: opaque, transparent (default: opaque, only for PNG/WebP)' -a "opaque transparent" -r
+        complete -c $cmd -l suppress-think -d 'Suppress text enclosed in thinking tags'
+        complete -c $cmd -l think-start-tag -d 'Start tag for thinking sections' -r
+        complete -c $cmd -l think-end-tag -d 'End tag for thinking sections' -r
+        complete -c $cmd -l disable-responses-api -d 'Disable OpenAI Responses API (default: false)'
+        complete -c $cmd -l transcribe-file -d 'Audio or video file to transcribe' -F -r
+        complete -c $cmd -l transcribe-model -d 'Model to use for transcription (separate from chat model)' -a "(__fabric_list --list-transcription-models)" -r
+        complete -c $cmd -l split-media-file -d 'Split audio/video files larger than 25MB using ffmpeg'
+        complete -c $cmd -l voice -d 'TTS voice name for supported models (e.g., Kore, Charon, Puck)' -a "(__fabric_list --list-gemini-voices)" -r
+        complete -c $cmd -l listen -d 'Voice assistant mode: record the microphone, transcribe it, run the pattern and speak the reply'
+        complete -c $cmd -l tts-model -d 'Text-to-speech model used by --listen, and by default by /v1/audio/speech of --serve (e.g., gpt-4o-mini-tts, gemini-2.5-flash-preview-tts)' -r
+        complete -c $cmd -l embed -d 'Output the embeddings of the input and of --embed-file files instead of chatting'
+        complete -c $cmd -l embed-model -d 'Embedding model used by --embed (e.g., text-embedding-3-small, nomic-embed-text, voyage-3.5)' -r
+        complete -c $cmd -l embed-file -d 'File to embed with --embed (can be used multiple times)' -F -r
+        complete -c $cmd -l embed-format -d 'Output format of --embed: json, jsonl (default: json)' -a "json jsonl" -r
+        complete -c $cmd -l rerank -d 'Order the documents read from stdin (one per line, text or JSON) by relevance to --query'
+        complete -c $cmd -l query -d 'Query used by --rerank' -r
+        complete -c $cmd -l rerank-model -d 'Reranking model used by --rerank (default depends on the service: Cohere, Voyage or Jina)' -r
+        complete -c $cmd -l rerank-top -d 'Only return the N most relevant documents with --rerank' -r
+        complete -c $cmd -l list-gemini-voices -d 'List all available Gemini TTS voices'
+        complete -c $cmd -l list-transcription-models -d 'List all available transcription models'
+        complete -c $cmd -l notification -d 'Send desktop notification when command completes'
+        complete -c $cmd -l notification-command -d 'Custom command to run for notifications (overrides built-in notifications)' -r
+        complete -c $cmd -l webhook -d 'POST the output and its metadata as JSON to this URL when the command completes' -r
+        complete -c $cmd -l webhook-secret -d 'Sign webhooks, including those of --serve jobs, with HMAC-SHA256 and this secret (default: $FABRIC_WEBHOOK_SECRET)' -r
+        complete -c $cmd -l thinking -d 'Set reasoning/thinking level (e.g., off, low, medium, high, or numeric tokens for Anthropic or Google Gemini)' -a "off low medium high" -r
+        complete -c $cmd -l reasoning-effort -d 'Reasoning effort for reasoning models: low, medium, high (overrides --thinking)' -a "low medium high" -r
+        complete -c $cmd -l thinking-budget -d 'Thinking budget in tokens (overrides --reasoning-effort and --thinking)' -r
+        complete -c $cmd -l show-think -d 'Show the model\'s thinking: dimmed while streaming (dim) or on stderr (stderr)' -a "dim stderr"
+        complete -c $cmd -l think-output -d 'Save the model\'s thinking to a file, keeping it out of the answer' -F -r
+        complete -c $cmd -l post -d 'Post-process the output: fences, codeblock, s/regex/replacement/[g] or jq:expression (can be used multiple times)' -r
+        complete -c $cmd -l diff -d 'Show the diff between a file and the output instead of the output' -F -r
+        complete -c $cmd -l diff-style -d 'Style of --diff: unified, side-by-side (default: unified)' -a "unified side-by-side" -r
+        complete -c $cmd -l apply -d 'Write the output to the --diff file after showing the diff, or the files of --apply-code without confirmation'
+        complete -c $cmd -l apply-code -d 'Write the code blocks of the output annotated with a file path to this directory, after showing their diff and confirmation' -F -r
+        complete -c $cmd -l render-mermaid -d 'Render the Mermaid diagrams of the output to this SVG, PNG or PDF file with the Mermaid CLI (mmdc), the next ones to file-2.svg and so on' -F -r
+        complete -c $cmd -l anki-deck -d 'Write the question and answer pairs of the output, e.g. of to_flashcards, as an Anki deck named after this .apkg package or .csv/.txt import file' -F -r
+        complete -c $cmd -l notion-append -d 'Append the output to the end of the Notion page of this id or URL as blocks' -r
+        complete -c $cmd -l stix -d 'Write the IOCs, ATT&CK techniques and CVEs of the output, e.g. of analyze_threat_report, to this JSON file as a STIX 2.1 bundle' -F -r
+        complete -c $cmd -l misp -d 'Push the IOCs, ATT&CK techniques and CVEs of the output to the configured MISP instance as a new event'
+        complete -c $cmd -l post-comment -d 'Post the output as a comment of the issue of --jira or --linear'
+        complete -c $cmd -l redact -d 'Mask emails, phone numbers, API keys and credit cards before sending the input, restoring them in the response'
+        complete -c $cmd -l redact-map -d 'Save the values masked by --redact to a JSON file' -F -r
+        complete -c $cmd -l moderate -d 'Moderate the input and the response: block flagged content (block) or annotate it (annotate)' -a "block annotate"
+        complete -c $cmd -l moderation-provider -d 'Moderator used by --moderate: openai, or local for the moderationTerms of the config file (default: openai)' -a "openai local" -r
+        complete -c $cmd -l pre-hook -d 'Run this command on the request as JSON before it is sent, using its changed JSON output if any (can be used multiple times)' -r
+        complete -c $cmd -l post-hook -d 'Run this command on the response as JSON before it is shown, using its changed JSON output if any (can be used multiple times)' -r
+        complete -c $cmd -l mcp -d 'Let the model call the tools of this MCP server of the mcpServers of the config file, or of all of them with all (can be used multiple times)' -r
+        complete -c $cmd -l allow-browser -d 'Let the model navigate, read and extract web pages with a headless browser driven by the Playwright MCP server'
+        complete -c $cmd -l allow-exec -d 'Let the model run Python and Go code in a sandbox without network to compute its answer'
+        complete -c $cmd -l exec-sandbox -d 'Sandbox of --allow-exec: docker or firejail (default: docker when installed, else firejail)' -a "docker firejail" -r
+        complete -c $cmd -l exec-timeout -d 'Kill the code run by --allow-exec after this duration (default: 30s)' -r
+        complete -c $cmd -l exec-memory -d 'Memory limit in MB of the code run by --allow-exec (default: 512)' -r
+        complete -c $cmd -l allow-write -d 'Let the model save files to this workspace directory, asking before each one unless --yes is set, and recording them in its .fabric-writes.jsonl' -F -r
+        complete -c $cmd -l yes -d 'Write the files of --allow-write, or send the state of --k8s, without asking'
+        complete -c $cmd -l show-metadata -d 'Print metadata to stderr'
+        complete -c $cmd -l quiet -d 'Do not show the progress indicator while waiting for a response'
+        complete -c $cmd -l plain -d 'Print the output as is instead of rendering its Markdown in the terminal'
+        complete -c $cmd -l auto-model -d 'Pick the model from the autoModels preference list based on pattern hints, attachments and input size'
+        complete -c $cmd -l debug -d 'Set debug level (0=off, 1=basic, 2=detailed, 3=trace, 4=wire)' -a "0 1 2 3 4" -r
+        complete -c $cmd -l log-level -d 'Log the messages from this level: debug, info, warn or error (default: info)' -a "debug info warn error" -r
+        complete -c $cmd -l log-format -d 'Format of the logs: text or json (default: text)' -a "text json" -r
+        complete -c $cmd -l log-file -d 'Append the logs to a file instead of stderr' -F -r
+        complete -c $cmd -s h -l help -d 'Show this help message'
 end
 
 __fabric_register_completions fabric
@@ -0,0 +1,221 @@
+// Package bots runs fabric commands sent from chat platforms, so that a team
+// can share fabric without everyone installing the CLI.
+package bots
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	restapi "github.com/danielmiessler/fabric/internal/server"
+)
+
+// UpdateInterval is how often bots edit a reply while it streams, to stay
+// within the rate limits of the platforms
+const UpdateInterval = 1500 * time.Millisecond
+
+// Config is the configuration of a bot, under bots.<platform> in the YAML
+// config file
+type Config struct {
+	// AllowedUsers are the IDs of the users allowed to send commands, everyone
+	// being allowed when empty
+	AllowedUsers []string `yaml:"allowedUsers"`
+	// ChannelPatterns are the patterns run in channels, by channel ID, when the
+	// message does not start with the name of a pattern
+	ChannelPatterns map[string]string `yaml:"channelPatterns"`
+}
+
+// Allowed reports whether the user may send commands
+func (c *Config) Allowed(user string) bool {
+	return len(c.AllowedUsers) == 0 || slices.Contains(c.AllowedUsers, user)
+}
+
+// Replier posts and edits the replies of a bot on its platform
+type Replier interface {
+	// Post posts a new reply and returns its ID
+	Post(ctx context.Context, text string) (id string, err error)
+	// Edit replaces the text of a reply
+	Edit(ctx context.Context, id, text string) error
+	// Limit is the longest text of a message
+	Limit() int
+}
+
+// Handler runs the commands of the bots with the registry
+type Handler struct {
+	Config        Config
+	Registry      *core.PluginRegistry
+	Vendor        string
+	Model         string
+	ContextLength int
+	Language      string
+	// Fetch returns the text behind a link given as the only input, e.g. the
+	// transcript of a video or a scraped page. Links are sent as is when nil.
+	Fetch func(url string) (string, error)
+	// Queue lets the users run their commands in turns when not nil
+	Queue *restapi.RequestQueue
+}
+
+// Command is a message sent to a bot
+type Command struct {
+	Pattern string
+	Input   string
+}
+
+// ParseCommand splits "<pattern> <input>" when the first word names a
+// pattern, the whole text being the input otherwise.
+func ParseCommand(text string, patterns []string) (ret Command) {
+	text = strings.TrimSpace(text)
+	first, rest, _ := strings.Cut(text, " ")
+	if first = strings.TrimSpace(first); first != "" && slices.Contains(patterns, first) {
+		return Command{Pattern: first, Input: strings.TrimSpace(rest)}
+	}
+	return Command{Input: text}
+}
+
+// Parse parses the text sent in the channel with the patterns of the
+// registry, falling back to the pattern of the channel.
+func (h *Handler) Parse(channel, text string) (ret Command) {
+	patterns, _ := h.Registry.Db.Patterns.GetNames()
+	if ret = ParseCommand(text, patterns); ret.Pattern == "" {
+		ret.Pattern = h.Config.ChannelPatterns[channel]
+	}
+	return
+}
+
+// Reply runs the command sent by the user in the channel and streams the
+// response into a reply, continued in new replies past the limit of the
+// platform. Errors are replied too.
+func (h *Handler) Reply(ctx context.Context, user, channel, text string, replier Replier) {
+	if !h.Config.Allowed(user) {
+		if _, err := replier.Post(ctx, i18n.T("bots_user_not_allowed")); err != nil {
+			slog.Error("Replying failed", "error", err)
+		}
+		return
+	}
+
+	id, err := replier.Post(ctx, i18n.T("bots_working"))
+	if err != nil {
+		slog.Error("Replying failed", "error", err)
+		return
+	}
+
+	result, err := h.Run(ctx, user, h.Parse(channel, text), func(partial string) {
+		if editErr := replier.Edit(ctx, id, Split(partial, replier.Limit())[0]); editErr != nil {
+			slog.Error("Updating the reply failed", "error", editErr)
+		}
+	})
+	if err != nil {
+		result = fmt.Sprintf(i18n.T("bots_command_failed"), err)
+	}
+
+	parts := Split(result, replier.Limit())
+	if err = replier.Edit(ctx, id, parts[0]); err != nil {
+		slog.Error("Updating the reply failed", "error", err)
+		return
+	}
+	for _, part := range parts[1:] {
+		if _, err = replier.Post(ctx, part); err != nil {
+			slog.Error("Replying failed", "error", err)
+			return
+		}
+	}
+}
+
+// Run runs the command of the user, calling onUpdate with the response so far
+// at most every UpdateInterval while it streams, and returns the full response.
+func (h *Handler) Run(ctx context.Context, user string, command Command, onUpdate func(string)) (ret string, err error) {
+	if command.Input == "" && command.Pattern == "" {
+		return "", fmt.Errorf("%s", i18n.T("bots_empty_command"))
+	}
+
+	input := command.Input
+	if h.Fetch != nil && isLink(input) {
+		if input, err = h.Fetch(input); err != nil {
+			return
+		}
+	}
+
+	if h.Queue != nil {
+		var release func()
+		if release, err = h.Queue.Acquire(ctx, user); err != nil {
+			return
+		}
+		defer release()
+	}
+
+	var chatter *core.Chatter
+	if chatter, err = h.Registry.GetChatter(h.Model, h.ContextLength, h.Vendor, true, false); err != nil {
+		return
+	}
+
+	updates := make(chan domain.StreamUpdate)
+	done := make(chan struct{})
+	var response strings.Builder
+	go func() {
+		defer close(done)
+		var last time.Time
+		for update := range updates {
+			if update.Type != domain.StreamTypeContent {
+				continue
+			}
+			response.WriteString(update.Content)
+			if onUpdate != nil && time.Since(last) >= UpdateInterval {
+				last = time.Now()
+				onUpdate(response.String())
+			}
+		}
+	}()
+
+	request := &domain.ChatRequest{
+		Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: input},
+		PatternName: command.Pattern,
+		Language:    h.Language,
+	}
+	var session *fsdb.Session
+	session, err = chatter.Send(ctx, request, &domain.ChatOptions{Model: h.Model, UpdateChan: updates, Quiet: true})
+	close(updates)
+	<-done
+	if err != nil || session == nil {
+		return
+	}
+	if last := session.GetLastMessage(); last != nil {
+		ret = last.Content
+	}
+	return
+}
+
+// isLink reports whether the input is a single http(s) link
+func isLink(input string) bool {
+	return (strings.HasPrefix(input, "https://") || strings.HasPrefix(input, "http://")) && !strings.ContainsAny(input, " \n\t")
+}
+
+// Split splits the text in parts of at most limit bytes, at the last line
+// break or space of each part when there is one, for platforms limiting the
+// length of messages.
+func Split(text string, limit int) (ret []string) {
+	for len(text) > limit {
+		cut := strings.LastIndex(text[:limit], "\n")
+		if cut <= 0 {
+			cut = strings.LastIndex(text[:limit], " ")
+		}
+		if cut <= 0 {
+			cut = limit
+			// Do not cut a UTF-8 sequence
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		ret = append(ret, text[:cut])
+		text = strings.TrimLeft(text[cut:], "\n ")
+	}
+	return append(ret, text)
+}
@@ -0,0 +1,40 @@
+package bots
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	patterns := []string{"summarize", "extract_wisdom"}
+	tests := []struct {
+		text string
+		want Command
+	}{
+		{text: "summarize https://example.com", want: Command{Pattern: "summarize", Input: "https://example.com"}},
+		{text: "  extract_wisdom  ", want: Command{Pattern: "extract_wisdom"}},
+		{text: "summarise this", want: Command{Input: "summarise this"}},
+		{text: "what is fabric?", want: Command{Input: "what is fabric?"}},
+	}
+	for _, tt := range tests {
+		if got := ParseCommand(tt.text, patterns); got != tt.want {
+			t.Errorf("ParseCommand(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	text := "first line\nsecond line\n" + strings.Repeat("é", 10)
+	parts := Split(text, 15)
+	if strings.Join(parts, "") != strings.ReplaceAll(text, "\n", "") {
+		t.Errorf("Split() lost text: %q", parts)
+	}
+	for _, part := range parts {
+		if len(part) > 15 || !strings.HasPrefix(text[strings.Index(text, part):], part) {
+			t.Errorf("Split() part %q is longer than 15 bytes or cuts a character", part)
+		}
+	}
+	if got := Split("short", 15); len(got) != 1 || got[0] != "short" {
+		t.Errorf("Split(short) = %q", got)
+	}
+}
@@ -0,0 +1,324 @@
+// Package discord runs fabric as a Discord bot over the gateway, answering
+// mentions and direct messages.
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/bots"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/gorilla/websocket"
+)
+
+// apiURL is the base URL of the Discord REST API
+var apiURL = "https://discord.com/api/v10"
+
+// reconnectDelay is the delay before reconnecting after the connection dropped
+var reconnectDelay = 5 * time.Second
+
+const (
+	// messageLimit is the longest content of a message
+	messageLimit = 2000
+	// intents are the guild messages, the direct messages and their content
+	intents = 1<<9 | 1<<12 | 1<<15
+)
+
+// Opcodes of the gateway
+const (
+	opDispatch       = 0
+	opHeartbeat      = 1
+	opIdentify       = 2
+	opReconnect      = 7
+	opInvalidSession = 9
+	opHello          = 10
+)
+
+// Bot answers the commands sent to the Discord application
+type Bot struct {
+	Token   string
+	Handler *bots.Handler
+
+	client *http.Client
+	// userID is the ID of the bot, known once connected
+	userID string
+}
+
+func New(token string, handler *bots.Handler) *Bot {
+	return &Bot{Token: token, Handler: handler, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// payload is a message of the gateway
+type payload struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d,omitempty"`
+	S  *int            `json:"s,omitempty"`
+	T  string          `json:"t,omitempty"`
+}
+
+type message struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+	GuildID   string `json:"guild_id"`
+	Content   string `json:"content"`
+	Author    struct {
+		ID  string `json:"id"`
+		Bot bool   `json:"bot"`
+	} `json:"author"`
+	Mentions []struct {
+		ID string `json:"id"`
+	} `json:"mentions"`
+}
+
+// Run answers the commands until the context is done, reconnecting whenever
+// Discord drops the connection. It fails at once when Discord refuses the
+// token.
+func (b *Bot) Run(ctx context.Context) (err error) {
+	for {
+		var ready bool
+		ready, err = b.connect(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !ready {
+			return
+		}
+		slog.Warn("Discord connection closed, reconnecting", "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// connect identifies on the gateway and reads its events until the
+// connection is closed, reporting whether Discord accepted the session.
+func (b *Bot) connect(ctx context.Context) (ready bool, err error) {
+	var gateway struct {
+		URL string `json:"url"`
+	}
+	if err = b.call(ctx, http.MethodGet, "/gateway/bot", nil, &gateway); err != nil {
+		return
+	}
+
+	var conn *websocket.Conn
+	if conn, _, err = websocket.DefaultDialer.DialContext(ctx, gateway.URL+"/?v=10&encoding=json", nil); err != nil {
+		return
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	// The heartbeats are written while the events are read
+	var writeMu sync.Mutex
+	write := func(p any) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteJSON(p)
+	}
+
+	var hello struct {
+		HeartbeatInterval int `json:"heartbeat_interval"`
+	}
+	var first payload
+	if err = conn.ReadJSON(&first); err != nil {
+		return
+	}
+	if first.Op != opHello {
+		return false, fmt.Errorf(i18n.T("discord_unexpected_payload"), first.Op)
+	}
+	if err = json.Unmarshal(first.D, &hello); err != nil {
+		return
+	}
+
+	identify := map[string]any{
+		"token":   b.Token,
+		"intents": intents,
+		"properties": map[string]string{
+			"os": runtime.GOOS, "browser": "fabric", "device": "fabric",
+		},
+	}
+	if err = write(map[string]any{"op": opIdentify, "d": identify}); err != nil {
+		return
+	}
+
+	var seqMu sync.Mutex
+	var seq *int
+	heartbeat := func() error {
+		seqMu.Lock()
+		defer seqMu.Unlock()
+		return write(map[string]any{"op": opHeartbeat, "d": seq})
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(time.Duration(hello.HeartbeatInterval) * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if heartbeat() != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		var event payload
+		if err = conn.ReadJSON(&event); err != nil {
+			return
+		}
+		if event.S != nil {
+			seqMu.Lock()
+			seq = event.S
+			seqMu.Unlock()
+		}
+
+		switch event.Op {
+		case opDispatch:
+			switch event.T {
+			case "READY":
+				var r struct {
+					User struct {
+						ID string `json:"id"`
+					} `json:"user"`
+				}
+				if err = json.Unmarshal(event.D, &r); err != nil {
+					return
+				}
+				b.userID, ready = r.User.ID, true
+			case "MESSAGE_CREATE":
+				var m message
+				if err = json.Unmarshal(event.D, &m); err != nil {
+					slog.Warn("Invalid Discord message", "error", err)
+					continue
+				}
+				b.handleMessage(ctx, &m)
+			}
+		case opHeartbeat:
+			if err = heartbeat(); err != nil {
+				return
+			}
+		case opReconnect, opInvalidSession:
+			return ready, fmt.Errorf(i18n.T("discord_unexpected_payload"), event.Op)
+		}
+	}
+}
+
+// handleMessage answers the direct messages and the messages mentioning the
+// bot
+func (b *Bot) handleMessage(ctx context.Context, m *message) {
+	if m.Author.Bot || m.Author.ID == b.userID {
+		return
+	}
+	mentioned := false
+	for _, mention := range m.Mentions {
+		mentioned = mentioned || mention.ID == b.userID
+	}
+	if m.GuildID != "" && !mentioned {
+		return
+	}
+
+	text := strings.NewReplacer("<@"+b.userID+">", "", "<@!"+b.userID+">", "").Replace(m.Content)
+	go b.Handler.Reply(ctx, m.Author.ID, m.ChannelID, strings.TrimSpace(text), &reply{bot: b, channelID: m.ChannelID, messageID: m.ID})
+}
+
+// reply posts the replies to a message
+type reply struct {
+	bot       *Bot
+	channelID string
+	messageID string
+}
+
+func (r *reply) Post(ctx context.Context, text string) (id string, err error) {
+	var posted struct {
+		ID string `json:"id"`
+	}
+	body := map[string]any{
+		"content":           text,
+		"message_reference": map[string]string{"message_id": r.messageID},
+		// Replies quoting @everyone or a role do not notify anyone
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+	err = r.bot.call(ctx, http.MethodPost, "/channels/"+r.channelID+"/messages", body, &posted)
+	return posted.ID, err
+}
+
+func (r *reply) Edit(ctx context.Context, id, text string) error {
+	return r.bot.call(ctx, http.MethodPatch, "/channels/"+r.channelID+"/messages/"+id, map[string]string{"content": text}, nil)
+}
+
+func (r *reply) Limit() int {
+	return messageLimit
+}
+
+// call calls the REST API, waiting once when rate limited, and decodes the
+// response into result when not nil
+func (b *Bot) call(ctx context.Context, method, path string, params any, result any) (err error) {
+	var body []byte
+	if params != nil {
+		if body, err = json.Marshal(params); err != nil {
+			return
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		var req *http.Request
+		if req, err = http.NewRequestWithContext(ctx, method, apiURL+path, bytes.NewReader(body)); err != nil {
+			return
+		}
+		req.Header.Set("Authorization", "Bot "+b.Token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "DiscordBot (https://github.com/danielmiessler/fabric, 1.0)")
+
+		var resp *http.Response
+		if resp, err = b.client.Do(req); err != nil {
+			return
+		}
+		var data []byte
+		data, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt == 0 {
+			var limited struct {
+				RetryAfter float64 `json:"retry_after"`
+			}
+			_ = json.Unmarshal(data, &limited)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(limited.RetryAfter * float64(time.Second))):
+			}
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			var failed struct {
+				Message string `json:"message"`
+			}
+			if json.Unmarshal(data, &failed) != nil || failed.Message == "" {
+				failed.Message = resp.Status
+			}
+			return fmt.Errorf(i18n.T("discord_api_failed"), path, failed.Message)
+		}
+		if result != nil {
+			err = json.Unmarshal(data, result)
+		}
+		return
+	}
+}
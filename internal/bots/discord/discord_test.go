@@ -0,0 +1,159 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/bots"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gorilla/websocket"
+)
+
+// fakeDiscord serves the REST API and a gateway sending the messages once the
+// bot identified
+type fakeDiscord struct {
+	t        *testing.T
+	server   *httptest.Server
+	messages []map[string]any
+	done     chan struct{}
+
+	mu       sync.Mutex
+	identify map[string]any
+	calls    []string
+}
+
+func newFakeDiscord(t *testing.T, messages ...map[string]any) *fakeDiscord {
+	f := &fakeDiscord{t: t, messages: messages, done: make(chan struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /gateway/bot", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bot token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"401: Unauthorized","code":0}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"url":"ws` + strings.TrimPrefix(f.server.URL, "http") + `/gateway"}`))
+	})
+	mux.HandleFunc("POST /channels/{channel}/messages", func(w http.ResponseWriter, r *http.Request) {
+		f.record("post " + r.PathValue("channel"))
+		_, _ = w.Write([]byte(`{"id":"500"}`))
+	})
+	mux.HandleFunc("PATCH /channels/{channel}/messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+		f.record("edit " + r.PathValue("channel") + " " + r.PathValue("id"))
+		_, _ = w.Write([]byte(`{"id":"500"}`))
+	})
+	mux.HandleFunc("/gateway/", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade error = %v", err)
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteJSON(map[string]any{"op": opHello, "d": map[string]int{"heartbeat_interval": 60000}})
+		var identify struct {
+			D map[string]any `json:"d"`
+		}
+		if err = conn.ReadJSON(&identify); err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.identify = identify.D
+		f.mu.Unlock()
+
+		seq := 1
+		_ = conn.WriteJSON(map[string]any{"op": opDispatch, "s": seq, "t": "READY", "d": map[string]any{"user": map[string]string{"id": "1"}}})
+		for _, message := range f.messages {
+			seq++
+			_ = conn.WriteJSON(map[string]any{"op": opDispatch, "s": seq, "t": "MESSAGE_CREATE", "d": message})
+		}
+		<-f.done
+	})
+	f.server = httptest.NewServer(mux)
+	return f
+}
+
+func (f *fakeDiscord) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func TestBot_AnswersMentions(t *testing.T) {
+	author := map[string]any{"id": "42"}
+	discord := newFakeDiscord(t,
+		map[string]any{"id": "10", "channel_id": "C1", "guild_id": "G", "content": "not for the bot", "author": author},
+		map[string]any{"id": "11", "channel_id": "C1", "guild_id": "G", "content": "a bot", "author": map[string]any{"id": "43", "bot": true},
+			"mentions": []map[string]string{{"id": "1"}}},
+		map[string]any{"id": "12", "channel_id": "C2", "guild_id": "G", "content": "<@1> hello", "author": author,
+			"mentions": []map[string]string{{"id": "1"}}},
+	)
+	defer discord.server.Close()
+	defer close(discord.done)
+	apiURL = discord.server.URL
+	defer func() { apiURL = "https://discord.com/api/v10" }()
+
+	registry, err := core.NewPluginRegistry(fsdb.NewDb(t.TempDir()))
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = New("token", &bots.Handler{Registry: registry}).Run(ctx) }()
+
+	// No vendor is configured, so the reply ends with the error
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		discord.mu.Lock()
+		calls := append([]string(nil), discord.calls...)
+		identify := discord.identify
+		discord.mu.Unlock()
+		if len(calls) == 2 {
+			if want := []string{"post C2", "edit C2 500"}; calls[0] != want[0] || calls[1] != want[1] {
+				t.Errorf("calls = %v, want %v", calls, want)
+			}
+			if identify["token"] != "token" || identify["intents"] != float64(intents) {
+				t.Errorf("identify = %v", identify)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the bot did not reply")
+}
+
+func TestBot_InvalidToken(t *testing.T) {
+	discord := newFakeDiscord(t)
+	defer discord.server.Close()
+	defer close(discord.done)
+	apiURL = discord.server.URL
+	defer func() { apiURL = "https://discord.com/api/v10" }()
+
+	if err := New("wrong", &bots.Handler{}).Run(context.Background()); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("Run() error = %v, want Unauthorized", err)
+	}
+}
+
+func TestReply_AllowedMentions(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"id":"7"}`))
+	}))
+	defer server.Close()
+	apiURL = server.URL
+	defer func() { apiURL = "https://discord.com/api/v10" }()
+
+	r := &reply{bot: New("token", nil), channelID: "C", messageID: "1"}
+	if id, err := r.Post(context.Background(), "@everyone look"); err != nil || id != "7" {
+		t.Fatalf("Post() = %q, %v", id, err)
+	}
+	if mentions, ok := body["allowed_mentions"].(map[string]any); !ok || len(mentions["parse"].([]any)) != 0 {
+		t.Errorf("allowed_mentions = %v, want none parsed", body["allowed_mentions"])
+	}
+}
@@ -0,0 +1,432 @@
+// Package email runs the patterns of rules on the mails arriving in a
+// mailbox, replying with the response or saving it as a draft.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/bots"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/converter"
+	"github.com/danielmiessler/fabric/internal/tools/mailbox"
+)
+
+// Actions of the rules
+const (
+	ActionReply = "reply"
+	ActionDraft = "draft"
+)
+
+// defaultPollInterval is how often the mailbox is checked by default
+const defaultPollInterval = time.Minute
+
+// Config is the configuration of the mailbox, under email in the YAML config
+// file
+type Config struct {
+	// Folder is the folder watched, INBOX by default
+	Folder string `yaml:"folder"`
+	// DraftsFolder is where the drafts are saved, Drafts by default
+	DraftsFolder string `yaml:"draftsFolder"`
+	// PollInterval is how often the folder is checked, every minute by default
+	PollInterval time.Duration `yaml:"pollInterval"`
+	// Rules are tried in order, the first matching a mail running its pattern
+	Rules []Rule `yaml:"rules"`
+	// TrustUnauthenticated replies to senders whose address the mail server
+	// did not authenticate with DMARC or DKIM, e.g. on servers adding no
+	// Authentication-Results header. The From header is easily forged, so
+	// replies could then be sent to anyone.
+	TrustUnauthenticated bool `yaml:"trustUnauthenticated"`
+}
+
+// Rule runs a pattern on the mails with a tag in their subject, or from some
+// senders, or both
+type Rule struct {
+	Name string `yaml:"name"`
+	// SubjectTag is text the subject contains, e.g. [fabric], matched
+	// regardless of case and removed from the subject of the reply
+	SubjectTag string `yaml:"subjectTag"`
+	// From are the addresses of the senders, with * wildcards, e.g.
+	// *@substack.com
+	From []string `yaml:"from"`
+	// Pattern is the pattern run on the subject and the text of the mail
+	Pattern string `yaml:"pattern"`
+	// Action is reply, the default, or draft to save the reply in the drafts
+	Action string `yaml:"action"`
+	// To, when set, receives the reply instead of the sender, e.g. for
+	// summaries of newsletters
+	To string `yaml:"to"`
+}
+
+// repliesToSender reports whether the rule sends its replies to the sender of
+// the mail
+func (r *Rule) repliesToSender() bool {
+	return r.Action != ActionDraft && r.To == ""
+}
+
+// Validate checks that every rule has a pattern, an action it knows and
+// something to match, and that the rules replying to the sender match it
+func (c *Config) Validate() error {
+	if len(c.Rules) == 0 {
+		return errors.New(i18n.T("email_no_rules"))
+	}
+	for i, rule := range c.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if rule.SubjectTag == "" && len(rule.From) == 0 {
+			return fmt.Errorf(i18n.T("email_rule_missing_match"), name)
+		}
+		if rule.Pattern == "" {
+			return fmt.Errorf(i18n.T("email_rule_missing_pattern"), name)
+		}
+		if rule.Action != "" && rule.Action != ActionReply && rule.Action != ActionDraft {
+			return fmt.Errorf(i18n.T("email_rule_invalid_action"), name, rule.Action)
+		}
+		// Otherwise anyone could have the mailbox send them responses
+		if rule.repliesToSender() && len(rule.From) == 0 {
+			return fmt.Errorf(i18n.T("email_rule_reply_needs_from"), name)
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule matching the sender and the subject, or nil
+func (c *Config) Match(from, subject string) *Rule {
+	from = strings.ToLower(from)
+	subject = strings.ToLower(subject)
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if rule.SubjectTag != "" && !strings.Contains(subject, strings.ToLower(rule.SubjectTag)) {
+			continue
+		}
+		if len(rule.From) > 0 && !matchesAny(rule.From, from) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, address string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), address); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Bot answers the mails matching the rules
+type Bot struct {
+	Mailbox *mailbox.Mailbox
+	Config  Config
+	Handler *bots.Handler
+
+	// skipped are the unread mails left alone, not fetched again
+	skipped map[uint32]bool
+}
+
+func New(box *mailbox.Mailbox, config Config, handler *bots.Handler) *Bot {
+	return &Bot{Mailbox: box, Config: config, Handler: handler, skipped: map[uint32]bool{}}
+}
+
+// Run checks the mailbox until the context is done. It fails at once when the
+// rules are invalid or the first login fails.
+func (b *Bot) Run(ctx context.Context) (err error) {
+	if err = b.Config.Validate(); err != nil {
+		return
+	}
+	interval := b.Config.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	for first := true; ; first = false {
+		if err = b.check(ctx); err != nil {
+			if first {
+				return
+			}
+			slog.Warn("Checking the mailbox failed, retrying", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// check answers the unread mails matching a rule, marking them as read
+func (b *Bot) check(ctx context.Context) (err error) {
+	var session *mailbox.Session
+	if session, err = b.Mailbox.Open(ctx, orDefault(b.Config.Folder, "INBOX")); err != nil {
+		return
+	}
+	defer session.Close()
+
+	var uids []uint32
+	if uids, err = session.Unseen(); err != nil {
+		return
+	}
+	for _, uid := range uids {
+		if b.skipped[uid] {
+			continue
+		}
+		if err = b.handle(ctx, session, uid); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// A mail failing is not retried, so that it does not run the
+			// pattern on every check
+			slog.Error("Answering the mail failed", "uid", uid, "error", err)
+			b.skipped[uid] = true
+		}
+	}
+	return nil
+}
+
+// handle runs the rule matching the mail, if any
+func (b *Bot) handle(ctx context.Context, session *mailbox.Session, uid uint32) (err error) {
+	var header []byte
+	if header, err = session.Fetch(uid, true); err != nil {
+		return
+	}
+	var msg *mail.Message
+	if msg, err = mail.ReadMessage(io.MultiReader(bytes.NewReader(header), strings.NewReader("\r\n"))); err != nil {
+		return
+	}
+	from, subject := sender(msg.Header), decodeHeader(msg.Header.Get("Subject"))
+	rule := b.Config.Match(from, subject)
+	// Automatic replies, ours included, are never answered to avoid loops
+	if rule == nil || from == "" || strings.EqualFold(from, b.Mailbox.From()) || isAutomatic(msg.Header) {
+		b.skipped[uid] = true
+		return
+	}
+
+	// The reply only goes to the matched sender, once the mail server vouched
+	// for the address
+	if rule.repliesToSender() && !b.Config.TrustUnauthenticated && !authenticated(msg.Header, from) {
+		slog.Warn("Not replying to a sender the mail server did not authenticate", "uid", uid, "from", from)
+		b.skipped[uid] = true
+		return
+	}
+
+	var raw []byte
+	if raw, err = session.Fetch(uid, false); err != nil {
+		return
+	}
+	if msg, err = mail.ReadMessage(bytes.NewReader(raw)); err != nil {
+		return
+	}
+	var text string
+	if text, err = bodyText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body); err != nil {
+		return
+	}
+
+	input := fmt.Sprintf("Subject: %s\n\n%s", subject, text)
+	var result string
+	if result, err = b.Handler.Run(ctx, from, bots.Command{Pattern: rule.Pattern, Input: input}, nil); err != nil {
+		return
+	}
+
+	to := orDefault(rule.To, from)
+	reply := compose(b.Mailbox.From(), to, replySubject(subject, rule.SubjectTag), msg.Header, result)
+	if rule.Action == ActionDraft {
+		err = session.AppendDraft(orDefault(b.Config.DraftsFolder, "Drafts"), reply)
+	} else {
+		err = b.Mailbox.Send([]string{to}, reply)
+	}
+	if err != nil {
+		return
+	}
+	return session.MarkSeen(uid)
+}
+
+// sender returns the address of the sender
+func sender(header mail.Header) string {
+	if addresses, err := header.AddressList("From"); err == nil && len(addresses) > 0 {
+		return addresses[0].Address
+	}
+	return ""
+}
+
+// authenticated reports whether the mail server authenticated the domain of
+// the sender, its Authentication-Results header showing that DMARC passed for
+// it or a DKIM signature of it was valid. Only the topmost header, added by
+// the server of the mailbox, is trusted, the others coming with the mail.
+func authenticated(header mail.Header, from string) bool {
+	results := header["Authentication-Results"]
+	_, domain, found := strings.Cut(strings.ToLower(from), "@")
+	if len(results) == 0 || !found {
+		return false
+	}
+	// The first part of the header is the server that authenticated the mail
+	for _, result := range strings.Split(results[0], ";")[1:] {
+		fields := strings.Fields(strings.ToLower(result))
+		if len(fields) == 0 {
+			continue
+		}
+		var property string
+		switch fields[0] {
+		case "dmarc=pass":
+			property = "header.from="
+		case "dkim=pass":
+			property = "header.d="
+		default:
+			continue
+		}
+		for _, field := range fields[1:] {
+			if value, ok := strings.CutPrefix(field, property); ok && value == domain {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isAutomatic reports whether the mail was sent by a program, e.g. an
+// out-of-office reply
+func isAutomatic(header mail.Header) bool {
+	autoSubmitted := strings.ToLower(header.Get("Auto-Submitted"))
+	return (autoSubmitted != "" && autoSubmitted != "no") || header.Get("X-Autoreply") != ""
+}
+
+// replySubject prefixes the subject with Re:, without the tag of the rule
+func replySubject(subject, tag string) string {
+	if tag != "" {
+		if i := strings.Index(strings.ToLower(subject), strings.ToLower(tag)); i >= 0 {
+			subject = subject[:i] + subject[i+len(tag):]
+		}
+	}
+	subject = strings.Join(strings.Fields(subject), " ")
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+	return subject
+}
+
+// compose writes the reply to the mail, threaded with it
+func compose(from, to, subject string, original mail.Header, body string) []byte {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+		}
+	}
+	header("From", from)
+	header("To", to)
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(from))
+	if id := original.Get("Message-ID"); id != "" {
+		header("In-Reply-To", id)
+		header("References", strings.TrimSpace(original.Get("References")+" "+id))
+	}
+	header("Auto-Submitted", "auto-replied")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	writer := quotedprintable.NewWriter(&buf)
+	_, _ = writer.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	_ = writer.Close()
+	return buf.Bytes()
+}
+
+// messageID returns a new Message-ID in the domain of the address
+func messageID(from string) string {
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	domain := "fabric.local"
+	if _, host, found := strings.Cut(from, "@"); found && host != "" {
+		domain = host
+	}
+	return "<" + hex.EncodeToString(random) + "@" + domain + ">"
+}
+
+// bodyText returns the text of the body, the plain text part of multipart
+// mails being preferred over the HTML one, which is converted to Markdown.
+func bodyText(contentType, encoding string, body io.Reader) (ret string, err error) {
+	mediaType, params, parseErr := mime.ParseMediaType(contentType)
+	if parseErr != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var html string
+		for {
+			var part *multipart.Part
+			if part, err = reader.NextRawPart(); err != nil {
+				if err == io.EOF {
+					return html, nil
+				}
+				return
+			}
+			if strings.HasPrefix(part.Header.Get("Content-Disposition"), "attachment") {
+				continue
+			}
+			var text string
+			if text, err = bodyText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part); err != nil {
+				return
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			if partType == "text/html" {
+				html = orDefault(html, text)
+			} else if text != "" {
+				return text, nil
+			}
+		}
+	}
+	if !strings.HasPrefix(mediaType, "text/") {
+		return "", nil
+	}
+
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	var data []byte
+	if data, err = io.ReadAll(body); err != nil {
+		return
+	}
+	ret = string(data)
+	if mediaType == "text/html" {
+		ret, err = converter.HtmlToMarkdown(ret, converter.MarkdownOptions{KeepLinks: true})
+	}
+	return strings.TrimSpace(ret), err
+}
+
+// decodeHeader decodes the encoded words of a header, e.g. of a subject
+func decodeHeader(value string) string {
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(value); err == nil {
+		return decoded
+	}
+	return value
+}
+
+func orDefault(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
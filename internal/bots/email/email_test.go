@@ -0,0 +1,142 @@
+package email
+
+import (
+	"bytes"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+		valid bool
+	}{
+		{name: "no rules"},
+		{name: "nothing to match", rules: []Rule{{Pattern: "summarize"}}},
+		{name: "no pattern", rules: []Rule{{From: []string{"*@example.com"}}}},
+		{name: "unknown action", rules: []Rule{{SubjectTag: "[fabric]", Pattern: "ai", Action: "forward"}}},
+		{name: "valid", rules: []Rule{{SubjectTag: "[fabric]", Pattern: "ai", Action: ActionDraft}}, valid: true},
+		{name: "reply to any sender", rules: []Rule{{SubjectTag: "[fabric]", Pattern: "ai"}}},
+		{name: "reply to the matched sender", rules: []Rule{{SubjectTag: "[fabric]", From: []string{"me@example.com"}, Pattern: "ai"}}, valid: true},
+		{name: "reply to a fixed address", rules: []Rule{{SubjectTag: "[fabric]", Pattern: "ai", To: "me@example.com"}}, valid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Rules: tt.rules}
+			if err := config.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestConfig_Match(t *testing.T) {
+	config := Config{Rules: []Rule{
+		{Name: "tagged", SubjectTag: "[Fabric]", From: []string{"*@example.com"}, Pattern: "ai"},
+		{Name: "newsletters", From: []string{"*@substack.com", "news@example.org"}, Pattern: "summarize"},
+	}}
+	tests := []struct {
+		from, subject, want string
+	}{
+		{"Me@Example.com", "[fabric] question", "tagged"},
+		{"me@example.com", "question", ""},
+		{"someone@other.com", "[fabric] question", ""},
+		{"writer@substack.com", "Issue 42", "newsletters"},
+		{"news@example.org", "[fabric] Issue 42", "newsletters"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if rule := config.Match(tt.from, tt.subject); rule != nil {
+			got = rule.Name
+		}
+		if got != tt.want {
+			t.Errorf("Match(%q, %q) = %q, want %q", tt.from, tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticated(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []string
+		want    bool
+	}{
+		{name: "no header"},
+		{name: "dmarc pass", results: []string{"mx.example.net; spf=pass smtp.mailfrom=example.com; dmarc=pass (p=NONE) header.from=example.com"}, want: true},
+		{name: "dkim pass", results: []string{"mx.example.net; dkim=pass header.i=@example.com header.d=example.com"}, want: true},
+		{name: "dkim of another domain", results: []string{"mx.example.net; dkim=pass header.d=attacker.com"}},
+		{name: "dmarc fail", results: []string{"mx.example.net; dmarc=fail header.from=example.com"}},
+		{name: "forged lower header", results: []string{"mx.example.net; dmarc=fail header.from=example.com", "evil; dmarc=pass header.from=example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := mail.Header{}
+			if tt.results != nil {
+				header["Authentication-Results"] = tt.results
+			}
+			if got := authenticated(header, "Me@Example.com"); got != tt.want {
+				t.Errorf("authenticated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyText(t *testing.T) {
+	raw := "Content-Type: multipart/alternative; boundary=b\r\n\r\n" +
+		"--b\r\nContent-Type: text/html\r\n\r\n<p>HTML</p>\r\n" +
+		"--b\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\nCaf=C3=A9 news\r\n" +
+		"--b--\r\n"
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := bodyText(msg.Header.Get("Content-Type"), "", msg.Body)
+	if err != nil || got != "Café news" {
+		t.Errorf("bodyText() = %q, %v, want the plain text part", got, err)
+	}
+
+	got, err = bodyText("text/html", "base64", strings.NewReader("PGgxPlRpdGxl\r\nPC9oMT4="))
+	if err != nil || got != "# Title" {
+		t.Errorf("bodyText() = %q, %v, want the HTML as Markdown", got, err)
+	}
+}
+
+func TestReplySubject(t *testing.T) {
+	tests := []struct {
+		subject, tag, want string
+	}{
+		{"[fabric] Summarize this", "[FABRIC]", "Re: Summarize this"},
+		{"Re: Weekly", "", "Re: Weekly"},
+		{"Issue 42", "", "Re: Issue 42"},
+	}
+	for _, tt := range tests {
+		if got := replySubject(tt.subject, tt.tag); got != tt.want {
+			t.Errorf("replySubject(%q, %q) = %q, want %q", tt.subject, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestCompose_Threaded(t *testing.T) {
+	original := mail.Header{"Message-Id": {"<2@example.com>"}, "References": {"<1@example.com>"}}
+	reply, err := mail.ReadMessage(bytes.NewReader(compose("fabric@example.com", "me@example.com", "Re: Café", original, "Résumé")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reply.Header.Get("In-Reply-To"); got != "<2@example.com>" {
+		t.Errorf("In-Reply-To = %q", got)
+	}
+	if got := reply.Header.Get("References"); got != "<1@example.com> <2@example.com>" {
+		t.Errorf("References = %q", got)
+	}
+	if got := decodeHeader(reply.Header.Get("Subject")); got != "Re: Café" {
+		t.Errorf("Subject = %q", got)
+	}
+	if !isAutomatic(reply.Header) {
+		t.Error("the reply is not marked as automatic, it could be answered in a loop")
+	}
+	if body, _ := bodyText(reply.Header.Get("Content-Type"), reply.Header.Get("Content-Transfer-Encoding"), reply.Body); body != "Résumé" {
+		t.Errorf("body = %q", body)
+	}
+}
@@ -0,0 +1,288 @@
+// Package slack runs fabric as a Slack bot over Socket Mode, answering
+// mentions, direct messages and the /fabric slash command in threads.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/bots"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/gorilla/websocket"
+)
+
+// apiURL is the base URL of the Slack Web API
+var apiURL = "https://slack.com/api/"
+
+// reconnectDelay is the delay before reconnecting after the connection dropped
+var reconnectDelay = 5 * time.Second
+
+// messageLimit is the length above which replies continue in new messages
+const messageLimit = 39000
+
+var (
+	mentionRegex = regexp.MustCompile(`<@[A-Z0-9]+(\|[^>]*)?>`)
+	linkRegex    = regexp.MustCompile(`<((?:https?|mailto):[^|>]+)(\|[^>]*)?>`)
+)
+
+// Bot answers the commands sent to the Slack app
+type Bot struct {
+	// AppToken is the app-level token (xapp-) opening Socket Mode connections
+	AppToken string
+	// BotToken is the bot token (xoxb-) posting the replies
+	BotToken string
+	Handler  *bots.Handler
+
+	client *http.Client
+}
+
+func New(appToken, botToken string, handler *bots.Handler) *Bot {
+	return &Bot{AppToken: appToken, BotToken: botToken, Handler: handler, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// envelope is a message of the Socket Mode connection
+type envelope struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+type eventPayload struct {
+	Event struct {
+		Type        string `json:"type"`
+		Subtype     string `json:"subtype"`
+		ChannelType string `json:"channel_type"`
+		BotID       string `json:"bot_id"`
+		User        string `json:"user"`
+		Text        string `json:"text"`
+		Channel     string `json:"channel"`
+		TS          string `json:"ts"`
+		ThreadTS    string `json:"thread_ts"`
+	} `json:"event"`
+}
+
+type slashCommand struct {
+	Command     string `json:"command"`
+	Text        string `json:"text"`
+	UserID      string `json:"user_id"`
+	ChannelID   string `json:"channel_id"`
+	ResponseURL string `json:"response_url"`
+}
+
+// Run answers the commands until the context is done, reconnecting whenever
+// Slack drops the connection. It fails at once when Slack refuses the tokens.
+func (b *Bot) Run(ctx context.Context) (err error) {
+	for {
+		var connected bool
+		connected, err = b.connect(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !connected {
+			return
+		}
+		slog.Warn("Slack connection closed, reconnecting", "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// connect opens a Socket Mode connection and reads its envelopes until it is
+// closed, reporting whether it could connect.
+func (b *Bot) connect(ctx context.Context) (connected bool, err error) {
+	var opened struct {
+		URL string `json:"url"`
+	}
+	if err = b.call(ctx, "apps.connections.open", b.AppToken, nil, &opened); err != nil {
+		return
+	}
+
+	var conn *websocket.Conn
+	if conn, _, err = websocket.DefaultDialer.DialContext(ctx, opened.URL, nil); err != nil {
+		return
+	}
+	defer conn.Close()
+	connected = true
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for {
+		var message envelope
+		if err = conn.ReadJSON(&message); err != nil {
+			return
+		}
+		if message.EnvelopeID != "" {
+			if err = conn.WriteJSON(map[string]string{"envelope_id": message.EnvelopeID}); err != nil {
+				return
+			}
+		}
+
+		switch message.Type {
+		case "events_api":
+			b.handleEvent(ctx, message.Payload)
+		case "slash_commands":
+			b.handleSlashCommand(ctx, message.Payload)
+		case "disconnect":
+			return true, errors.New(string(message.Payload))
+		}
+	}
+}
+
+func (b *Bot) handleEvent(ctx context.Context, payload json.RawMessage) {
+	var event eventPayload
+	if err := json.Unmarshal(payload, &event); err != nil {
+		slog.Warn("Invalid Slack event", "error", err)
+		return
+	}
+
+	e := event.Event
+	// The replies of bots, this one included, are not commands
+	if e.BotID != "" || e.Subtype != "" {
+		return
+	}
+	if e.Type != "app_mention" && (e.Type != "message" || e.ChannelType != "im") {
+		return
+	}
+
+	ts := e.ThreadTS
+	if ts == "" {
+		ts = e.TS
+	}
+	go b.Handler.Reply(ctx, e.User, e.Channel, cleanText(e.Text), &thread{bot: b, channel: e.Channel, ts: ts})
+}
+
+func (b *Bot) handleSlashCommand(ctx context.Context, payload json.RawMessage) {
+	var command slashCommand
+	if err := json.Unmarshal(payload, &command); err != nil {
+		slog.Warn("Invalid Slack command", "error", err)
+		return
+	}
+
+	go func() {
+		if !b.Handler.Config.Allowed(command.UserID) {
+			b.respondEphemeral(ctx, command.ResponseURL, i18n.T("bots_user_not_allowed"))
+			return
+		}
+		// The command is posted first so that the reply has a thread
+		text := fmt.Sprintf("<@%s> `%s %s`", command.UserID, command.Command, command.Text)
+		ts, err := b.post(ctx, command.ChannelID, "", text)
+		if err != nil {
+			b.respondEphemeral(ctx, command.ResponseURL, fmt.Sprintf(i18n.T("slack_post_failed"), err))
+			return
+		}
+		b.Handler.Reply(ctx, command.UserID, command.ChannelID, cleanText(command.Text), &thread{bot: b, channel: command.ChannelID, ts: ts})
+	}()
+}
+
+// thread posts the replies in a thread of a channel
+type thread struct {
+	bot     *Bot
+	channel string
+	ts      string
+}
+
+func (t *thread) Post(ctx context.Context, text string) (string, error) {
+	return t.bot.post(ctx, t.channel, t.ts, text)
+}
+
+func (t *thread) Edit(ctx context.Context, ts, text string) error {
+	return t.bot.call(ctx, "chat.update", t.bot.BotToken, map[string]string{"channel": t.channel, "ts": ts, "text": text}, nil)
+}
+
+func (t *thread) Limit() int {
+	return messageLimit
+}
+
+// post posts the text in the channel, in the thread when not empty, and
+// returns the timestamp identifying the message
+func (b *Bot) post(ctx context.Context, channel, thread, text string) (ts string, err error) {
+	var posted struct {
+		TS string `json:"ts"`
+	}
+	params := map[string]string{"channel": channel, "text": text}
+	if thread != "" {
+		params["thread_ts"] = thread
+	}
+	err = b.call(ctx, "chat.postMessage", b.BotToken, params, &posted)
+	return posted.TS, err
+}
+
+// respondEphemeral shows the text to the user of a slash command only
+func (b *Bot) respondEphemeral(ctx context.Context, responseURL, text string) {
+	body, _ := json.Marshal(map[string]string{"response_type": "ephemeral", "text": text})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		slog.Error("Responding to the Slack command failed", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		slog.Error("Responding to the Slack command failed", "error", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// call calls a method of the Web API, decoding its response into result
+// when not nil
+func (b *Bot) call(ctx context.Context, method, token string, params any, result any) (err error) {
+	var body []byte
+	if params != nil {
+		if body, err = json.Marshal(params); err != nil {
+			return
+		}
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, apiURL+method, bytes.NewReader(body)); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	var resp *http.Response
+	if resp, err = b.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var raw json.RawMessage
+	if err = json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return fmt.Errorf(i18n.T("slack_api_failed"), method, resp.Status)
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err = json.Unmarshal(raw, &status); err != nil {
+		return
+	}
+	if !status.OK {
+		return fmt.Errorf(i18n.T("slack_api_failed"), method, status.Error)
+	}
+	if result != nil {
+		err = json.Unmarshal(raw, result)
+	}
+	return
+}
+
+// cleanText turns the markup of Slack back into the text the user typed,
+// without the mentions of the bot.
+func cleanText(text string) string {
+	text = mentionRegex.ReplaceAllString(text, "")
+	text = linkRegex.ReplaceAllString(text, "$1")
+	return strings.TrimSpace(html.UnescapeString(text))
+}
@@ -0,0 +1,146 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/bots"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gorilla/websocket"
+)
+
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "<@U0BOT> summarize <https://example.com/a?b=1&amp;c=2>", want: "summarize https://example.com/a?b=1&c=2"},
+		{text: "<@U0BOT|fabric> extract_wisdom <https://example.com|example.com>", want: "extract_wisdom https://example.com"},
+		{text: "what is 1 &lt; 2?", want: "what is 1 < 2?"},
+	}
+	for _, tt := range tests {
+		if got := cleanText(tt.text); got != tt.want {
+			t.Errorf("cleanText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// fakeSlack serves the Web API and a Socket Mode connection sending one mention
+type fakeSlack struct {
+	t      *testing.T
+	server *httptest.Server
+	acks   chan string
+
+	mu    sync.Mutex
+	calls []map[string]string
+	done  chan struct{}
+}
+
+func newFakeSlack(t *testing.T) *fakeSlack {
+	f := &fakeSlack{t: t, acks: make(chan string, 1), done: make(chan struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/apps.connections.open", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer xapp-test" {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"url":"ws` + strings.TrimPrefix(f.server.URL, "http") + `/ws"}`))
+	})
+	mux.HandleFunc("/chat.postMessage", f.record("chat.postMessage"))
+	mux.HandleFunc("/chat.update", f.record("chat.update"))
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade error = %v", err)
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteJSON(map[string]string{"type": "hello"})
+		_ = conn.WriteJSON(map[string]any{
+			"envelope_id": "env-1",
+			"type":        "events_api",
+			"payload": map[string]any{"event": map[string]string{
+				"type": "app_mention", "user": "U1", "channel": "C1", "ts": "100.1", "text": "<@U0BOT> hello",
+			}},
+		})
+		var ack map[string]string
+		if err = conn.ReadJSON(&ack); err == nil {
+			f.acks <- ack["envelope_id"]
+		}
+		<-f.done
+	})
+	f.server = httptest.NewServer(mux)
+	return f
+}
+
+func (f *fakeSlack) record(method string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		params["method"] = method
+		f.mu.Lock()
+		f.calls = append(f.calls, params)
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok":true,"ts":"200.1"}`))
+	}
+}
+
+func TestBot_RepliesInThread(t *testing.T) {
+	slack := newFakeSlack(t)
+	defer slack.server.Close()
+	defer close(slack.done)
+	apiURL = slack.server.URL + "/"
+	defer func() { apiURL = "https://slack.com/api/" }()
+
+	registry, err := core.NewPluginRegistry(fsdb.NewDb(t.TempDir()))
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bot := New("xapp-test", "xoxb-test", &bots.Handler{Registry: registry})
+	go func() { _ = bot.Run(ctx) }()
+
+	if id := <-slack.acks; id != "env-1" {
+		t.Errorf("ack = %q, want env-1", id)
+	}
+
+	// No vendor is configured, so the reply ends with the error
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		slack.mu.Lock()
+		calls := append([]map[string]string(nil), slack.calls...)
+		slack.mu.Unlock()
+		if len(calls) == 2 {
+			if post := calls[0]; post["method"] != "chat.postMessage" || post["channel"] != "C1" || post["thread_ts"] != "100.1" {
+				t.Errorf("first call = %v, want a reply in the thread", post)
+			}
+			if update := calls[1]; update["method"] != "chat.update" || update["ts"] != "200.1" || update["text"] == "" {
+				t.Errorf("second call = %v, want the reply updated", update)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the bot did not reply")
+}
+
+func TestBot_InvalidToken(t *testing.T) {
+	slack := newFakeSlack(t)
+	defer slack.server.Close()
+	defer close(slack.done)
+	apiURL = slack.server.URL + "/"
+	defer func() { apiURL = "https://slack.com/api/" }()
+
+	bot := New("xapp-wrong", "xoxb-test", &bots.Handler{})
+	if err := bot.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid_auth") {
+		t.Errorf("Run() error = %v, want invalid_auth", err)
+	}
+}
@@ -0,0 +1,205 @@
+// Package telegram runs fabric as a Telegram bot with long polling, answering
+// private messages, mentions and the /fabric command.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/bots"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// apiURL is the base URL of the Telegram Bot API
+var apiURL = "https://api.telegram.org"
+
+// retryDelay is the delay before polling again after an error
+var retryDelay = 5 * time.Second
+
+const (
+	// messageLimit is the longest text of a message
+	messageLimit = 4096
+	// pollTimeout is how long Telegram holds a poll without updates, in seconds
+	pollTimeout = 50
+)
+
+// Bot answers the commands sent to the Telegram bot
+type Bot struct {
+	Token   string
+	Handler *bots.Handler
+
+	client *http.Client
+	// username is the name of the bot, known once started
+	username string
+}
+
+func New(token string, handler *bots.Handler) *Bot {
+	return &Bot{Token: token, Handler: handler, client: &http.Client{Timeout: (pollTimeout + 10) * time.Second}}
+}
+
+type update struct {
+	UpdateID int      `json:"update_id"`
+	Message  *message `json:"message"`
+}
+
+type message struct {
+	MessageID int    `json:"message_id"`
+	Text      string `json:"text"`
+	From      *struct {
+		ID    int64 `json:"id"`
+		IsBot bool  `json:"is_bot"`
+	} `json:"from"`
+	Chat struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"`
+	} `json:"chat"`
+}
+
+// Run answers the commands until the context is done. It fails at once when
+// Telegram refuses the token.
+func (b *Bot) Run(ctx context.Context) (err error) {
+	var me struct {
+		Username string `json:"username"`
+	}
+	if err = b.call(ctx, "getMe", nil, &me); err != nil {
+		return
+	}
+	b.username = me.Username
+
+	offset := 0
+	for {
+		var updates []update
+		params := map[string]any{"offset": offset, "timeout": pollTimeout, "allowed_updates": []string{"message"}}
+		if err = b.call(ctx, "getUpdates", params, &updates); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Warn("Polling Telegram failed, retrying", "error", err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message != nil {
+				b.handleMessage(ctx, u.Message)
+			}
+		}
+	}
+}
+
+// handleMessage answers the private messages, and in groups the /fabric
+// command and the messages mentioning the bot
+func (b *Bot) handleMessage(ctx context.Context, m *message) {
+	if m.From == nil || m.From.IsBot || m.Text == "" {
+		return
+	}
+
+	text := m.Text
+	if command, rest, _ := strings.Cut(text, " "); strings.HasPrefix(command, "/") {
+		// Only /fabric is a command of the bot, e.g. not /start
+		if name, bot, _ := strings.Cut(command, "@"); name != "/fabric" || (bot != "" && bot != b.username) {
+			return
+		}
+		text = rest
+	} else if mention := "@" + b.username; b.username != "" && strings.Contains(text, mention) {
+		text = strings.ReplaceAll(text, mention, "")
+	} else if m.Chat.Type != "private" {
+		return
+	}
+
+	chatID := strconv.FormatInt(m.Chat.ID, 10)
+	go b.Handler.Reply(ctx, strconv.FormatInt(m.From.ID, 10), chatID, strings.TrimSpace(text),
+		&reply{bot: b, chatID: chatID, messageID: m.MessageID})
+}
+
+// reply posts the replies to a message
+type reply struct {
+	bot       *Bot
+	chatID    string
+	messageID int
+}
+
+func (r *reply) Post(ctx context.Context, text string) (string, error) {
+	var posted message
+	params := map[string]any{
+		"chat_id":          r.chatID,
+		"text":             text,
+		"reply_parameters": map[string]any{"message_id": r.messageID},
+	}
+	err := r.bot.call(ctx, "sendMessage", params, &posted)
+	return strconv.Itoa(posted.MessageID), err
+}
+
+func (r *reply) Edit(ctx context.Context, id, text string) error {
+	messageID, err := strconv.Atoi(id)
+	if err != nil {
+		return err
+	}
+	err = r.bot.call(ctx, "editMessageText", map[string]any{"chat_id": r.chatID, "message_id": messageID, "text": text}, nil)
+	// The last update may have sent the whole response already
+	if err != nil && strings.Contains(err.Error(), "message is not modified") {
+		return nil
+	}
+	return err
+}
+
+func (r *reply) Limit() int {
+	return messageLimit
+}
+
+// call calls a method of the Bot API, decoding its result into result when
+// not nil
+func (b *Bot) call(ctx context.Context, method string, params any, result any) (err error) {
+	if params == nil {
+		params = map[string]any{}
+	}
+	var body []byte
+	if body, err = json.Marshal(params); err != nil {
+		return
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"/bot"+b.Token+"/"+method, bytes.NewReader(body)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp *http.Response
+	if resp, err = b.client.Do(req); err != nil {
+		// The URL holds the token, keep it out of the logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf(i18n.T("telegram_api_failed"), method, err.Error())
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf(i18n.T("telegram_api_failed"), method, resp.Status)
+	}
+	if !response.OK {
+		return fmt.Errorf(i18n.T("telegram_api_failed"), method, response.Description)
+	}
+	if result != nil {
+		err = json.Unmarshal(response.Result, result)
+	}
+	return
+}
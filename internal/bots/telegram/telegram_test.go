@@ -0,0 +1,140 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/bots"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// fakeTelegram serves the Bot API, sending the messages as one update
+type fakeTelegram struct {
+	server   *httptest.Server
+	messages []string
+
+	polled atomic.Bool
+
+	mu      sync.Mutex
+	replies []map[string]any
+}
+
+func newFakeTelegram(messages ...string) *fakeTelegram {
+	f := &fakeTelegram{messages: messages}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/bottoken/") {
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Unauthorized"}`))
+			return
+		}
+		var params map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		if strings.HasSuffix(r.URL.Path, "/getUpdates") && f.polled.Load() {
+			// Like a long poll without updates
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch strings.TrimPrefix(r.URL.Path, "/bottoken/") {
+		case "getMe":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"username":"fabric_bot"}}`))
+		case "getUpdates":
+			var updates []string
+			if !f.polled.Load() {
+				for i, text := range f.messages {
+					updates = append(updates, fmt.Sprintf(`{"update_id":%d,"message":{"message_id":%d,"text":%q,"from":{"id":42},"chat":{"id":-100,"type":"group"}}}`, i+1, i+10, text))
+				}
+				f.polled.Store(true)
+			}
+			_, _ = w.Write([]byte(`{"ok":true,"result":[` + strings.Join(updates, ",") + `]}`))
+		case "sendMessage", "editMessageText":
+			params["method"] = strings.TrimPrefix(r.URL.Path, "/bottoken/")
+			f.replies = append(f.replies, params)
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":99}}`))
+		}
+	}))
+	return f
+}
+
+func TestBot_AnswersCommandsInGroups(t *testing.T) {
+	telegram := newFakeTelegram("just chatting", "/start", "/fabric@fabric_bot hello")
+	defer telegram.server.Close()
+	apiURL = telegram.server.URL
+	defer func() { apiURL = "https://api.telegram.org" }()
+
+	registry, err := core.NewPluginRegistry(fsdb.NewDb(t.TempDir()))
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = New("token", &bots.Handler{Registry: registry}).Run(ctx) }()
+
+	// Only the /fabric command is answered; no vendor is configured, so the
+	// reply ends with the error
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		telegram.mu.Lock()
+		replies := append([]map[string]any(nil), telegram.replies...)
+		telegram.mu.Unlock()
+		if len(replies) == 2 {
+			if replies[0]["method"] != "sendMessage" || replies[0]["reply_parameters"].(map[string]any)["message_id"] != float64(12) {
+				t.Errorf("first call = %v, want a reply to the command", replies[0])
+			}
+			if replies[1]["method"] != "editMessageText" || replies[1]["message_id"] != float64(99) {
+				t.Errorf("second call = %v, want the reply edited", replies[1])
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the bot did not reply")
+}
+
+func TestBot_NotAllowed(t *testing.T) {
+	telegram := newFakeTelegram("/fabric hello")
+	defer telegram.server.Close()
+	apiURL = telegram.server.URL
+	defer func() { apiURL = "https://api.telegram.org" }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler := &bots.Handler{Config: bots.Config{AllowedUsers: []string{"7"}}}
+	go func() { _ = New("token", handler).Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		telegram.mu.Lock()
+		replies := append([]map[string]any(nil), telegram.replies...)
+		telegram.mu.Unlock()
+		if len(replies) > 0 {
+			if len(replies) != 1 || replies[0]["method"] != "sendMessage" {
+				t.Errorf("replies = %v, want only the refusal", replies)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the bot did not refuse the command")
+}
+
+func TestBot_InvalidToken(t *testing.T) {
+	telegram := newFakeTelegram()
+	defer telegram.server.Close()
+	apiURL = telegram.server.URL
+	defer func() { apiURL = "https://api.telegram.org" }()
+
+	err := New("wrong", &bots.Handler{}).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Unauthorized") || strings.Contains(err.Error(), "wrong") {
+		t.Errorf("Run() error = %v, want Unauthorized without the token", err)
+	}
+}
@@ -0,0 +1,87 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// ParseDataURL decodes a base64 encoded RFC 2397 data URL into its MIME type and content.
+func ParseDataURL(value string) (mimeType string, data []byte, err error) {
+	meta, payload, found := strings.Cut(strings.TrimPrefix(value, "data:"), ",")
+	if !strings.HasPrefix(value, "data:") || !found {
+		err = fmt.Errorf(i18n.T("chat_error_invalid_data_url"), truncateURL(value))
+		return
+	}
+
+	metaParts := strings.Split(meta, ";")
+	mimeType = strings.TrimSpace(metaParts[0])
+	isBase64 := false
+	for _, part := range metaParts[1:] {
+		if strings.EqualFold(strings.TrimSpace(part), "base64") {
+			isBase64 = true
+		}
+	}
+	if mimeType == "" || !isBase64 {
+		err = fmt.Errorf(i18n.T("chat_error_invalid_data_url"), truncateURL(value))
+		return
+	}
+
+	if data, err = base64.StdEncoding.DecodeString(strings.TrimSpace(payload)); err != nil {
+		err = fmt.Errorf(i18n.T("chat_error_invalid_data_url"), err)
+	}
+	return
+}
+
+// LoadImage returns the content and MIME type of the image referenced by an image
+// part URL, for vendors that need the image bytes inline rather than a URL. Data URLs
+// are decoded in place; remote URLs are downloaded with client.
+func LoadImage(ctx context.Context, client *http.Client, imageURL string) (data []byte, mimeType string, err error) {
+	if strings.HasPrefix(imageURL, "data:") {
+		mimeType, data, err = ParseDataURL(imageURL)
+		return
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		err = fmt.Errorf(i18n.T("chat_error_fetching_image"), imageURL, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf(i18n.T("chat_error_fetching_image"), imageURL, resp.Status)
+		return
+	}
+
+	if data, err = io.ReadAll(resp.Body); err != nil {
+		err = fmt.Errorf(i18n.T("chat_error_fetching_image"), imageURL, err)
+		return
+	}
+
+	mimeType, _, _ = strings.Cut(resp.Header.Get("Content-Type"), ";")
+	if mimeType = strings.TrimSpace(mimeType); mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = http.DetectContentType(data)
+	}
+	return
+}
+
+// truncateURL shortens data URLs in error messages, which would otherwise embed the
+// whole base64 payload.
+func truncateURL(value string) string {
+	const maxLen = 64
+	if len(value) <= maxLen {
+		return value
+	}
+	return value[:maxLen] + "..."
+}
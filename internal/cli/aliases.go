@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/kballard/go-shellquote"
+)
+
+// aliasPlaceholder matches the placeholders of aliases: {1} for the first
+// argument after the alias, {date} and {time}
+var aliasPlaceholder = regexp.MustCompile(`\{(\d+|date|time)\}`)
+
+// expandAlias replaces the alias, the first positional argument, with its
+// arguments, placed first so that the flags of the command line override them.
+// The arguments after the alias fill its placeholders, those left over staying
+// on the command line as the message.
+func expandAlias(cliArgs, args []string, alias string, now time.Time) (ret []string, err error) {
+	var words []string
+	if words, err = shellquote.Split(alias); err != nil {
+		return nil, fmt.Errorf(i18n.T("alias_invalid"), args[0], err)
+	}
+
+	used := make([]bool, len(args))
+	for _, word := range words {
+		word = aliasPlaceholder.ReplaceAllStringFunc(word, func(placeholder string) string {
+			switch name := placeholder[1 : len(placeholder)-1]; name {
+			case "date":
+				return now.Format(time.DateOnly)
+			case "time":
+				return now.Format("150405")
+			default:
+				n, _ := strconv.Atoi(name)
+				if n < 1 || n >= len(args) {
+					err = fmt.Errorf(i18n.T("alias_missing_argument"), args[0], n)
+					return placeholder
+				}
+				used[n] = true
+				return args[n]
+			}
+		})
+		ret = append(ret, word)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the alias and the arguments of its placeholders from the command line
+	rest := slices.Clone(cliArgs)
+	for i, arg := range args {
+		if i == 0 || used[i] {
+			if index := slices.Index(rest, arg); index >= 0 {
+				rest = slices.Delete(rest, index, index+1)
+			}
+		}
+	}
+	return append(ret, rest...), nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandAlias(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	alias := `-y {1} -p extract_wisdom -o "notes/{date} {time}.md"`
+
+	got, err := expandAlias([]string{"-s", "yt", "https://youtu.be/abc", "focus", "on", "AI"},
+		[]string{"yt", "https://youtu.be/abc", "focus", "on", "AI"}, alias, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-y", "https://youtu.be/abc", "-p", "extract_wisdom", "-o", "notes/2025-03-14 092653.md",
+		"-s", "focus", "on", "AI"}, got)
+
+	_, err = expandAlias([]string{"yt"}, []string{"yt"}, alias, now)
+	assert.ErrorContains(t, err, "{1}")
+
+	_, err = expandAlias([]string{"yt"}, []string{"yt"}, `-p "unclosed`, now)
+	assert.Error(t, err)
+}
+
+func TestInitWithAlias(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`
+aliases:
+  wisdom: "-u {1} -p extract_wisdom"
+`), 0644))
+
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"cmd", "--config", config, "wisdom", "https://example.com", "-p", "summarize"}
+	flags, err := Init()
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", flags.ScrapeURL)
+	// The flags of the command line override those of the alias
+	assert.Equal(t, "summarize", flags.Pattern)
+	assert.Empty(t, flags.Message)
+
+	// Only the first argument names an alias
+	os.Args = []string{"cmd", "--config", config, "extract", "wisdom"}
+	flags, err = Init()
+	require.NoError(t, err)
+	assert.Equal(t, "extract wisdom", flags.Message)
+}
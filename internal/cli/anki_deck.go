@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/tools/anki"
+)
+
+// handleAnkiDeck writes the question and answer pairs of the output to the
+// --anki-deck file, once they are valid cards
+func handleAnkiDeck(flags *Flags, output string) (err error) {
+	var cards []anki.Card
+	if cards, err = anki.ParseCards(output); err != nil {
+		return
+	}
+	if err = anki.Validate(cards); err != nil {
+		return
+	}
+	if err = anki.Write(cards, flags.AnkiDeck); err != nil {
+		return
+	}
+	debuglog.Log(i18n.T("anki_deck_written"), len(cards), anki.DeckName(flags.AnkiDeck), flags.AnkiDeck)
+	return
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+)
+
+// handleApplyCode writes the files of the path-annotated code blocks of the
+// output to the --apply-code directory. The diff of each file is shown first
+// and the files are written once confirmed, or right away with --apply.
+func handleApplyCode(flags *Flags, output string) (err error) {
+	var changes []domain.FileChange
+	if changes, err = domain.ParseCodeBlocks(output); err != nil {
+		return
+	}
+	if changes, err = codeChanges(flags.ApplyCode, changes, flags.DiffStyle, os.Stdout); err != nil {
+		return
+	}
+	if len(changes) == 0 {
+		debuglog.Log(i18n.T("apply_code_no_changes"), flags.ApplyCode)
+		return
+	}
+
+	if !flags.Apply {
+		if !mdrender.IsTerminal(os.Stdin) {
+			return errors.New(i18n.T("apply_code_not_confirmed"))
+		}
+		if !confirm(os.Stdin, os.Stderr, i18n.T("apply_code_confirm"), len(changes), flags.ApplyCode) {
+			return
+		}
+	}
+	return domain.ApplyFileChanges(flags.ApplyCode, changes)
+}
+
+// codeChanges prints the diff of the files that change in the directory and
+// returns them, with the operation creating or updating them. The files are
+// read within the directory, which a symlink cannot escape.
+func codeChanges(dir string, files []domain.FileChange, diffStyle string, out io.Writer) (ret []domain.FileChange, err error) {
+	// A missing directory has only files to create
+	var root *os.Root
+	if root, err = os.OpenRoot(dir); err == nil {
+		defer root.Close()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf(i18n.T("diff_error_reading_file"), dir, err)
+	}
+	for _, file := range files {
+		path := filepath.FromSlash(file.Path)
+		file.Operation = "update"
+		var current []byte
+		if root == nil {
+			file.Operation = "create"
+		} else if current, err = root.ReadFile(path); errors.Is(err, fs.ErrNotExist) {
+			file.Operation = "create"
+		} else if err != nil {
+			return nil, fmt.Errorf(i18n.T("diff_error_reading_file"), filepath.Join(dir, path), err)
+		}
+		err = nil
+
+		var diff string
+		if diff, err = renderDiff(string(current), file.Content, file.Path, diffStyle); err != nil {
+			return
+		}
+		if diff == "" {
+			continue
+		}
+		fmt.Fprint(out, diff)
+		ret = append(ret, file)
+	}
+	return
+}
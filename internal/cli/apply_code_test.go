@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+func TestCodeChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "same.go"), []byte("package same\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []domain.FileChange{
+		{Path: "same.go", Content: "package same\n"},
+		{Path: "main.go", Content: "package main\n\nfunc main() {}\n"},
+		{Path: "internal/app/app.go", Content: "package app\n"},
+	}
+	var out bytes.Buffer
+	got, err := codeChanges(dir, files, "", &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Operation != "update" || got[1].Operation != "create" {
+		t.Fatalf("codeChanges() = %+v, want main.go updated and app.go created", got)
+	}
+	diff := out.String()
+	if strings.Contains(diff, "same.go") || !strings.Contains(diff, "+func main() {}") || !strings.Contains(diff, "+package app") {
+		t.Errorf("diff = %q", diff)
+	}
+
+	if err = domain.ApplyFileChanges(dir, got); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "internal", "app", "app.go")); string(data) != "package app\n" {
+		t.Errorf("app.go = %q", data)
+	}
+}
+
+func TestCodeChangesSymlink(t *testing.T) {
+	dir, outside := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+
+	// The file the symlink leads to is neither read nor written
+	files := []domain.FileChange{{Path: "link/secret.txt", Content: "replaced\n"}}
+	var out bytes.Buffer
+	if _, err := codeChanges(dir, files, "", &out); err == nil || strings.Contains(out.String(), "secret") {
+		t.Errorf("codeChanges() through a symlink = %v, diff %q; want an error", err, out.String())
+	}
+	files[0].Operation = "update"
+	if err := domain.ApplyFileChanges(dir, files); err == nil {
+		t.Errorf("ApplyFileChanges() through a symlink expected an error")
+	}
+	if data, _ := os.ReadFile(filepath.Join(outside, "secret.txt")); string(data) != "secret\n" {
+		t.Errorf("secret.txt = %q, written through the symlink", data)
+	}
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+const (
+	// longContextTokens is the context window required by long_context patterns.
+	longContextTokens = 100000
+	// unknownContextTokens is what models with an unknown context window are assumed to handle.
+	unknownContextTokens = 8192
+)
+
+// modelNeeds are the capabilities a request requires from a model.
+type modelNeeds struct {
+	vision     bool
+	reasoning  bool
+	minContext int
+}
+
+func (o modelNeeds) satisfiedBy(caps ai.ModelCapabilities) bool {
+	if (o.vision && !caps.Vision) || (o.reasoning && !caps.Reasoning) {
+		return false
+	}
+	contextWindow := caps.ContextWindow
+	if contextWindow == 0 {
+		contextWindow = unknownContextTokens
+	}
+	return o.minContext <= contextWindow
+}
+
+func (o modelNeeds) String() string {
+	var parts []string
+	if o.vision {
+		parts = append(parts, fsdb.RequiresVision)
+	}
+	if o.reasoning {
+		parts = append(parts, fsdb.RequiresReasoning)
+	}
+	parts = append(parts, fmt.Sprintf("%d tokens of context", o.minContext))
+	return strings.Join(parts, ", ")
+}
+
+// selectAutoModel picks the first model of the autoModels preference list able to
+// handle the request, judging by the pattern hints, attachments, thinking level and
+// input size.
+func (o *Flags) selectAutoModel(patterns *fsdb.PatternsEntity) (err error) {
+	if len(o.AutoModels) == 0 {
+		return errors.New(i18n.T("auto_model_no_preferences"))
+	}
+
+	var hints []string
+	if o.Pattern != "" && patterns != nil {
+		var metadata *fsdb.PatternMetadata
+		if metadata, err = patterns.GetMetadata(o.Pattern); err != nil {
+			return
+		}
+		hints = metadata.Requires
+	}
+
+	needs := o.modelNeeds(hints)
+	for _, spec := range o.AutoModels {
+		candidate := Flags{Model: spec, ModelAliases: o.ModelAliases}
+		candidate.resolveModel()
+		if needs.satisfiedBy(o.lookupModelCapabilities(candidate.Vendor, candidate.Model)) {
+			debuglog.Debug(debuglog.Basic, "Auto model selected %s for %s\n", spec, needs)
+			o.Model = spec
+			return
+		}
+	}
+	return fmt.Errorf(i18n.T("auto_model_no_match"), needs)
+}
+
+func (o *Flags) modelNeeds(hints []string) (ret modelNeeds) {
+	ret.minContext = ai.EstimateTokens(o.Message)
+	for _, hint := range hints {
+		switch strings.ToLower(hint) {
+		case fsdb.RequiresVision:
+			ret.vision = true
+		case fsdb.RequiresReasoning:
+			ret.reasoning = true
+		case fsdb.RequiresLongContext:
+			ret.minContext = max(ret.minContext, longContextTokens)
+		default:
+			debuglog.Debug(debuglog.Basic, "Ignoring unknown pattern hint %q\n", hint)
+		}
+	}
+	for _, attachment := range o.Attachments {
+		if isImageAttachment(attachment) {
+			ret.vision = true
+		}
+	}
+	if o.Thinking != "" && o.Thinking != domain.ThinkingOff {
+		ret.reasoning = true
+	}
+	return
+}
+
+// lookupModelCapabilities returns the capabilities of a model, preferring the
+// modelCapabilities config entries ("vendor|model" or "model") over the built-in table.
+func (o *Flags) lookupModelCapabilities(vendor, model string) ai.ModelCapabilities {
+	keys := []string{model}
+	if vendor != "" {
+		keys = []string{vendor + "|" + model, model}
+	}
+	for _, key := range keys {
+		for name, caps := range o.ModelCapabilities {
+			if strings.EqualFold(name, key) {
+				return caps
+			}
+		}
+	}
+	caps, _ := ai.LookupModelCapabilities(model)
+	return caps
+}
+
+// lookupModelPricing returns the modelPricing config entry of a model
+// ("vendor|model" or "model"), nil when there is none.
+func (o *Flags) lookupModelPricing(vendor, model string) *ai.ModelPricing {
+	for _, key := range []string{vendor + "|" + model, model} {
+		for name, pricing := range o.ModelPricing {
+			if strings.EqualFold(name, key) {
+				return &pricing
+			}
+		}
+	}
+	return nil
+}
+
+// isImageAttachment guesses from the file extension whether an attachment path or URL is an image.
+func isImageAttachment(attachment string) bool {
+	name := attachment
+	if parsed, err := url.Parse(attachment); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		name = parsed.Path
+	}
+	return strings.HasPrefix(mime.TypeByExtension(strings.ToLower(path.Ext(name))), "image/")
+}
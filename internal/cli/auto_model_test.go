@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectAutoModel(t *testing.T) {
+	patterns := &fsdb.PatternsEntity{
+		StorageEntity:     &fsdb.StorageEntity{Dir: t.TempDir(), ItemIsDir: true},
+		SystemPatternFile: "system.md",
+	}
+	for name, requires := range map[string]string{"describe_image": "[vision]", "prove": "[reasoning]", "summarize_book": "[long_context]", "plain": ""} {
+		dir := filepath.Join(patterns.Dir, name)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "system.md"), []byte("pattern"), 0644))
+		if requires != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, fsdb.PatternMetadataFile), []byte("requires: "+requires+"\n"), 0644))
+		}
+	}
+
+	preferences := []string{"local", "gpt-4o-mini", "o3", "gemini-2.5-pro"}
+	tests := []struct {
+		name  string
+		flags Flags
+		want  string
+	}{
+		{name: "first preference for plain input", flags: Flags{Pattern: "plain", Message: "hi"}, want: "local"},
+		{name: "vision hint", flags: Flags{Pattern: "describe_image"}, want: "gpt-4o-mini"},
+		{name: "image attachment", flags: Flags{Attachments: []string{"https://example.com/photo.JPG?x=1"}}, want: "gpt-4o-mini"},
+		{name: "reasoning hint", flags: Flags{Pattern: "prove"}, want: "o3"},
+		{name: "thinking flag", flags: Flags{Thinking: domain.ThinkingHigh}, want: "o3"},
+		{name: "long context hint", flags: Flags{Pattern: "summarize_book"}, want: "gpt-4o-mini"},
+		{name: "huge input", flags: Flags{Message: strings.Repeat("word ", 250000)}, want: "gemini-2.5-pro"},
+		{name: "pattern file path", flags: Flags{Pattern: "./missing.md"}, want: "local"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := tt.flags
+			flags.AutoModels = preferences
+			flags.ModelAliases = map[string]string{"local": "Ollama|my-local-model"}
+			require.NoError(t, flags.selectAutoModel(patterns))
+			assert.Equal(t, tt.want, flags.Model)
+		})
+	}
+
+	t.Run("no match", func(t *testing.T) {
+		flags := Flags{AutoModels: []string{"gpt-4o-mini"}, Pattern: "prove"}
+		assert.Error(t, flags.selectAutoModel(patterns))
+	})
+
+	t.Run("no preferences", func(t *testing.T) {
+		flags := Flags{}
+		assert.Error(t, flags.selectAutoModel(patterns))
+	})
+
+	t.Run("configured capabilities override the built-in table", func(t *testing.T) {
+		flags := Flags{
+			AutoModels:        []string{"Ollama|my-vision-model", "gpt-4o"},
+			Pattern:           "describe_image",
+			ModelCapabilities: map[string]ai.ModelCapabilities{"ollama|my-vision-model": {Vision: true}},
+		}
+		require.NoError(t, flags.selectAutoModel(patterns))
+		assert.Equal(t, "Ollama|my-vision-model", flags.Model)
+	})
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/danielmiessler/fabric/internal/bots"
+	"github.com/danielmiessler/fabric/internal/bots/discord"
+	"github.com/danielmiessler/fabric/internal/bots/email"
+	"github.com/danielmiessler/fabric/internal/bots/slack"
+	"github.com/danielmiessler/fabric/internal/bots/telegram"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	restapi "github.com/danielmiessler/fabric/internal/server"
+)
+
+// IsBotServer reports whether a chat bot or the mailbox watcher is to be run
+func (o *Flags) IsBotServer() bool {
+	return o.ServeSlack || o.ServeDiscord || o.ServeTelegram || o.ServeEmail
+}
+
+// botHandler returns the handler running the commands sent to the bot of the
+// platform, with the model, vendor and language of the flags and the settings
+// of bots.<platform> in the config file.
+func botHandler(flags *Flags, registry *core.PluginRegistry, platform string, queue *restapi.RequestQueue) *bots.Handler {
+	return &bots.Handler{
+		Config:        flags.Bots[platform],
+		Registry:      registry,
+		Vendor:        flags.Vendor,
+		Model:         flags.Model,
+		ContextLength: flags.ModelContextLength,
+		Language:      flags.Language,
+		Fetch:         func(url string) (string, error) { return fetchLink(flags, registry, url) },
+		Queue:         queue,
+	}
+}
+
+// fetchLink returns the transcript of a YouTube video or else the scraped page
+// behind a link sent to a bot.
+func fetchLink(flags *Flags, registry *core.PluginRegistry, url string) (string, error) {
+	if registry.YouTube.IsConfigured() {
+		if videoId, _, err := registry.YouTube.GetVideoOrPlaylistId(url); err == nil && videoId != "" {
+			return processYoutubeVideo(flags, registry, videoId)
+		}
+	}
+	scrape := *flags
+	scrape.ScrapeURL = url
+	return scrapeURL(&scrape, registry)
+}
+
+// serveBots runs the bots of --serve-slack, --serve-discord, --serve-telegram
+// and --serve-email, with the tokens of the environment and the mailbox of the
+// setup, until one of them fails. Their users take turns within
+// --max-concurrent.
+func serveBots(flags *Flags, registry *core.PluginRegistry) error {
+	queue := restapi.NewRequestQueue(flags.MaxConcurrent)
+	var runs []func(context.Context) error
+
+	if flags.ServeSlack {
+		appToken, botToken := os.Getenv("SLACK_APP_TOKEN"), os.Getenv("SLACK_BOT_TOKEN")
+		if appToken == "" || botToken == "" {
+			return errors.New(i18n.T("slack_tokens_required"))
+		}
+		runs = append(runs, slack.New(appToken, botToken, botHandler(flags, registry, "slack", queue)).Run)
+	}
+	if flags.ServeDiscord {
+		token := os.Getenv("DISCORD_BOT_TOKEN")
+		if token == "" {
+			return errors.New(i18n.T("discord_token_required"))
+		}
+		runs = append(runs, discord.New(token, botHandler(flags, registry, "discord", queue)).Run)
+	}
+	if flags.ServeTelegram {
+		token := os.Getenv("TELEGRAM_BOT_TOKEN")
+		if token == "" {
+			return errors.New(i18n.T("telegram_token_required"))
+		}
+		runs = append(runs, telegram.New(token, botHandler(flags, registry, "telegram", queue)).Run)
+	}
+	if flags.ServeEmail {
+		if !registry.Email.IsConfigured() {
+			return errors.New(i18n.T("email_not_configured"))
+		}
+		runs = append(runs, email.New(registry.Email, flags.Email, botHandler(flags, registry, "email", queue)).Run)
+	}
+
+	errs := make(chan error, len(runs))
+	for _, run := range runs {
+		go func() { errs <- run(context.Background()) }()
+	}
+	return <-errs
+}
@@ -2,23 +2,41 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"text/template"
 
 	"github.com/danielmiessler/fabric/internal/core"
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/plugins/strategy"
+	"github.com/danielmiessler/fabric/internal/tools/anki"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+	"github.com/danielmiessler/fabric/internal/tools/mermaid"
 	"github.com/danielmiessler/fabric/internal/tools/notifications"
+	"github.com/danielmiessler/fabric/internal/tools/postprocess"
+	"github.com/danielmiessler/fabric/internal/tools/redact"
+	"github.com/danielmiessler/fabric/internal/tools/stix"
 )
 
 // handleChatProcessing handles the main chat processing logic
 func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, messageTools string) (err error) {
+	_, err = runChat(currentFlags, registry, messageTools)
+	return
+}
+
+// runChat sends the chat request built from the flags, prints and stores the response
+// as requested, and returns the response text.
+func runChat(currentFlags *Flags, registry *core.PluginRegistry, messageTools string) (result string, err error) {
 	if messageTools != "" {
 		currentFlags.AppendMessage(messageTools)
 	}
@@ -35,18 +53,101 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 			}
 		}
 	}
+	if currentFlags.AutoModel && currentFlags.Model == "" {
+		if err = currentFlags.selectAutoModel(registry.Db.Patterns); err != nil {
+			return
+		}
+	}
+	currentFlags.resolveModel()
+
+	var post postprocess.Pipeline
+	if post, err = postprocess.Parse(currentFlags.postProcessors()); err != nil {
+		return
+	}
+	var outputTemplate *template.Template
+	if outputTemplate, err = parseOutputTemplate(currentFlags.OutputTemplate); err != nil {
+		return
+	}
+	if currentFlags.Apply && currentFlags.Diff == "" && currentFlags.ApplyCode == "" {
+		err = errors.New(i18n.T("apply_requires_diff"))
+		return
+	}
+	if currentFlags.RenderMermaid != "" {
+		if err = mermaid.CheckOutput(currentFlags.RenderMermaid); err != nil {
+			return
+		}
+	}
+	if currentFlags.AnkiDeck != "" {
+		if err = anki.CheckOutput(currentFlags.AnkiDeck); err != nil {
+			return
+		}
+	}
+	if currentFlags.NotionAppend != "" {
+		if err = registry.Notion.CheckPage(currentFlags.NotionAppend); err != nil {
+			return
+		}
+	}
+	if currentFlags.STIX != "" {
+		if err = stix.CheckOutput(currentFlags.STIX); err != nil {
+			return
+		}
+	}
+	if currentFlags.MISP {
+		if err = registry.MISP.CheckConfigured(); err != nil {
+			return
+		}
+	}
+	if currentFlags.PostComment && currentFlags.Jira == "" && currentFlags.Linear == "" {
+		err = errors.New(i18n.T("post_comment_requires_issue"))
+		return
+	}
+	if (currentFlags.Logprobs || currentFlags.TopLogprobs > 0) && !currentFlags.JSON {
+		err = errors.New(i18n.T("logprobs_requires_json"))
+		return
+	}
+	if len(post) > 0 || currentFlags.Diff != "" || currentFlags.PrintPath || currentFlags.JSON {
+		// Post-processors, diffs and JSON need the whole response, so it is printed once complete
+		currentFlags.Stream = false
+	}
+	var plan *strategy.Plan
+	if plan, err = strategy.Compose(currentFlags.Strategy); err != nil {
+		return
+	}
+	if plan.Samples > 1 || currentFlags.Refine > 0 || currentFlags.TranslateOutput != "" || currentFlags.usesTools() {
+		// Sampled, refined, translated and tool-augmented responses are only final once complete
+		currentFlags.Stream = false
+	}
 
 	var chatter *core.Chatter
 	if chatter, err = registry.GetChatter(currentFlags.Model, currentFlags.ModelContextLength,
 		currentFlags.Vendor, currentFlags.Stream, currentFlags.DryRun); err != nil {
 		return
 	}
+	if err = currentFlags.applyVendorConfig(chatter.ServingVendorName()); err != nil {
+		return
+	}
+	if currentFlags.Redact {
+		chatter.Redactor = redact.New()
+	}
+	if currentFlags.MaxCost < 0 {
+		err = fmt.Errorf(i18n.T("invalid_max_cost"), currentFlags.MaxCost)
+		return
+	}
+	if currentFlags.MaxCost > 0 {
+		chatter.Budget = &core.Budget{
+			MaxCost: currentFlags.MaxCost,
+			Pricing: currentFlags.lookupModelPricing(chatter.ServingVendorName(), chatter.Model()),
+		}
+	}
 
 	var session *fsdb.Session
 	var chatReq *domain.ChatRequest
 	if chatReq, err = currentFlags.BuildChatRequest(strings.Join(os.Args[1:], " ")); err != nil {
 		return
 	}
+	if chatReq.EphemeralContext, err = currentFlags.contextCommandsOutput(os.Stdin, os.Stderr); err != nil {
+		return
+	}
 
 	if chatReq.Language == "" {
 		chatReq.Language = registry.Language.DefaultLanguage.Value
@@ -89,19 +190,99 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 		chatOptions.AudioFormat = "wav" // Default to WAV format
 	}
 
-	if session, err = chatter.Send(context.Background(), chatReq, chatOptions); err != nil {
+	var progress *spinner
+	if (!currentFlags.Stream || currentFlags.SuppressThink) && !currentFlags.DryRun {
+		// Nothing is printed until the response is complete
+		progress = currentFlags.startSpinner(fmt.Sprintf(i18n.T("spinner_waiting_for_model"), chatter.Model()))
+	}
+	ctx, cancel := currentFlags.requestContext()
+	var closeTools func()
+	if closeTools, err = currentFlags.connectTools(ctx, chatter); err == nil {
+		session, err = chatter.Send(ctx, chatReq, chatOptions)
+		closeTools()
+	}
+	cancel()
+	progress.Stop()
+	if err != nil {
 		return
 	}
 
-	result := session.GetLastMessage().Content
+	result = session.GetLastMessage().Content
+	if result, err = post.Apply(result); err != nil {
+		return
+	}
+	// --json prints and writes the response with its metadata
+	printed := result
+	if currentFlags.JSON {
+		if printed, err = formatChatJSON(currentFlags, chatter, result); err != nil {
+			return
+		}
+	}
 
-	if !currentFlags.Stream || currentFlags.SuppressThink {
+	if currentFlags.RedactMap != "" && chatter.Redactor != nil {
+		if err = saveRedactionMap(chatter.Redactor, currentFlags.RedactMap); err != nil {
+			return
+		}
+	}
+
+	outputData := newOutputTemplateData(currentFlags, chatter.Model(), chatter.VendorName(), result)
+	if currentFlags.Output == "" && currentFlags.OutputDir != "" {
+		if currentFlags.Output, err = currentFlags.outputDirPath(outputData); err != nil {
+			return
+		}
+	}
+
+	if currentFlags.Diff != "" {
+		if err = handleDiff(currentFlags, result); err != nil {
+			return
+		}
+	} else if !currentFlags.silent && !currentFlags.PrintPath && (!currentFlags.Stream || currentFlags.SuppressThink) {
 		// For TTS models with audio output, show a user-friendly message instead of raw data
 		if isTTSModel && isAudioOutput && strings.HasPrefix(result, "FABRIC_AUDIO_DATA:") {
 			fmt.Printf(i18n.T("tts_audio_generated_successfully"), currentFlags.Output)
 		} else {
 			// print the result if it was not streamed already or suppress-think disabled streaming output
-			fmt.Println(result)
+			if chatOptions.RenderMarkdown && !currentFlags.JSON {
+				fmt.Println(mdrender.Render(result, mdrender.TerminalWidth(os.Stdout)))
+			} else {
+				fmt.Println(printed)
+			}
+		}
+	}
+
+	if currentFlags.ApplyCode != "" {
+		if err = handleApplyCode(currentFlags, result); err != nil {
+			return
+		}
+	}
+
+	if currentFlags.RenderMermaid != "" {
+		if err = handleRenderMermaid(currentFlags, result); err != nil {
+			return
+		}
+	}
+
+	if currentFlags.AnkiDeck != "" {
+		if err = handleAnkiDeck(currentFlags, result); err != nil {
+			return
+		}
+	}
+
+	if currentFlags.NotionAppend != "" {
+		if err = handleNotionAppend(currentFlags, registry, result); err != nil {
+			return
+		}
+	}
+
+	if currentFlags.STIX != "" || currentFlags.MISP {
+		if err = handleSTIX(currentFlags, registry, result); err != nil {
+			return
+		}
+	}
+
+	if currentFlags.PostComment {
+		if err = handlePostComment(currentFlags, registry, result); err != nil {
+			return
 		}
 	}
 
@@ -130,9 +311,21 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 					err = CreateOutputFile(result, currentFlags.Output)
 				}
 			} else {
-				err = CreateOutputFile(result, currentFlags.Output)
+				output := printed
+				if outputTemplate != nil {
+					if output, err = renderOutputTemplate(outputTemplate, outputData); err != nil {
+						return
+					}
+				}
+				err = CreateOutputFile(output, currentFlags.Output)
 			}
 		}
+		if err != nil {
+			return
+		}
+		if currentFlags.PrintPath {
+			fmt.Println(currentFlags.Output)
+		}
 	}
 
 	// Send notification if requested
@@ -143,9 +336,30 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 		}
 	}
 
+	if currentFlags.Webhook != "" {
+		if webhookErr := currentFlags.sendWebhook(chatReq, chatter.VendorName(), chatter.Model(), result); webhookErr != nil {
+			// Like notifications, a failed webhook does not fail the command
+			debuglog.Log(i18n.T("webhook_send_failed"), webhookErr)
+		}
+	}
+
 	return
 }
 
+// requestContext returns the context of a request, canceled by Ctrl-C or SIGTERM
+// and after --timeout, so that an interrupted request is aborted cleanly.
+func (o *Flags) requestContext() (ctx context.Context, cancel context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if o.Timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancelTimeout := context.WithTimeout(ctx, o.Timeout)
+	return ctx, func() {
+		cancelTimeout()
+		stop()
+	}
+}
+
 // sendNotification sends a desktop notification about command completion.
 //
 // When truncating the result for notification display, this function counts Unicode code points,
@@ -200,3 +414,16 @@ func isTTSModel(modelName string) bool {
 		strings.Contains(lowerModel, "preview-tts") ||
 		strings.Contains(lowerModel, "text-to-speech")
 }
+
+// saveRedactionMap writes the values masked by --redact, by placeholder, to a
+// JSON file so that they can be restored later.
+func saveRedactionMap(redactor *redact.Redactor, fileName string) (err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(redactor.Mapping(), "", "  "); err != nil {
+		return
+	}
+	if err = os.WriteFile(fileName, append(data, '\n'), 0600); err != nil {
+		err = fmt.Errorf(i18n.T("redact_error_writing_map"), fileName, err)
+	}
+	return
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"encoding/json"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+// maxTopLogprobs is the most alternatives per token the vendors return
+const maxTopLogprobs = 20
+
+// chatJSON is the response printed by --json
+type chatJSON struct {
+	Vendor   string                `json:"vendor"`
+	Model    string                `json:"model"`
+	Pattern  string                `json:"pattern,omitempty"`
+	Output   string                `json:"output"`
+	Logprobs []domain.TokenLogprob `json:"logprobs,omitempty"`
+}
+
+// formatChatJSON returns the response as the indented JSON of --json, with the
+// log probabilities of its tokens when --logprobs asked for them.
+func formatChatJSON(flags *Flags, chatter *core.Chatter, output string) (ret string, err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(chatJSON{
+		Vendor:   chatter.VendorName(),
+		Model:    chatter.Model(),
+		Pattern:  flags.Pattern,
+		Output:   output,
+		Logprobs: chatter.Logprobs,
+	}, "", "  "); err != nil {
+		return
+	}
+	return string(data), nil
+}
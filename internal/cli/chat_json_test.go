@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatChatJSON(t *testing.T) {
+	chatter := &core.Chatter{Logprobs: []domain.TokenLogprob{{Token: "Yes", Logprob: -0.2}}}
+	out, err := formatChatJSON(&Flags{Pattern: "classify"}, chatter, "Yes")
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	assert.Equal(t, "classify", got["pattern"])
+	assert.Equal(t, "Yes", got["output"])
+	assert.Equal(t, []any{map[string]any{"token": "Yes", "logprob": -0.2}}, got["logprobs"])
+
+	// Without --logprobs the field is left out
+	out, err = formatChatJSON(&Flags{}, &core.Chatter{}, "Yes")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "logprobs")
+}
+
+func TestBuildChatOptionsLogprobs(t *testing.T) {
+	options, err := (&Flags{TopLogprobs: 5}).BuildChatOptions()
+	require.NoError(t, err)
+	assert.True(t, options.Logprobs, "--top-logprobs implies --logprobs")
+	assert.Equal(t, 5, options.TopLogprobs)
+
+	for _, top := range []int{-1, maxTopLogprobs + 1} {
+		_, err = (&Flags{TopLogprobs: top}).BuildChatOptions()
+		assert.Error(t, err, top)
+	}
+}
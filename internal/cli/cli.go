@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/danielmiessler/fabric/internal/core"
@@ -10,6 +11,7 @@ import (
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
 	"github.com/danielmiessler/fabric/internal/tools/converter"
+	"github.com/danielmiessler/fabric/internal/tools/nettransport"
 	"github.com/danielmiessler/fabric/internal/tools/youtube"
 )
 
@@ -25,7 +27,7 @@ func Cli(version string) (err error) {
 		return
 	}
 
-	if currentFlags.Setup {
+	if currentFlags.Setup || currentFlags.IsUnattendedSetup() {
 		if err = ensureEnvFile(); err != nil {
 			return
 		}
@@ -36,10 +38,27 @@ func Cli(version string) (err error) {
 		return
 	}
 
+	if currentFlags.Completion != "" {
+		err = writeCompletion(currentFlags.Completion, os.Stdout)
+		return
+	}
+
+	// The proxy and certificates go into the transport recorded by --record
+	if err = nettransport.Configure(currentFlags.Proxy, currentFlags.CACert); err != nil {
+		return
+	}
+	if err = configureHTTPRecording(currentFlags); err != nil {
+		return
+	}
+
 	// Initialize database and registry
 	var registry, err2 = initializeFabric()
+	if currentFlags.Doctor {
+		err = runDoctor(currentFlags, registry, err2, os.Stdout)
+		return
+	}
 	if err2 != nil {
-		if !currentFlags.Setup {
+		if !currentFlags.Setup && !currentFlags.IsUnattendedSetup() {
 			debuglog.Log("%s\n", err2.Error())
 			currentFlags.Setup = true
 		}
@@ -52,6 +71,18 @@ func Cli(version string) (err error) {
 	// Configure OpenAI Responses API setting based on CLI flag
 	if registry != nil {
 		configureOpenAIResponsesAPI(registry, currentFlags.DisableResponsesAPI)
+
+		// Moderate chats, including those served by --serve
+		if err = configureModeration(currentFlags, registry); err != nil {
+			return
+		}
+		if err = configureSessionPolicy(currentFlags, registry); err != nil {
+			return
+		}
+		configureHooks(currentFlags, registry)
+		if err = configureSpend(currentFlags, registry); err != nil {
+			return
+		}
 	}
 
 	// Handle setup and server commands
@@ -80,6 +111,24 @@ func Cli(version string) (err error) {
 		return
 	}
 
+	// Compute embeddings instead of chatting
+	if currentFlags.Embed {
+		err = handleEmbed(currentFlags, registry)
+		return
+	}
+
+	// Search the sessions and contexts instead of chatting
+	if currentFlags.SearchSessions != "" {
+		err = handleSearchSessions(currentFlags, registry)
+		return
+	}
+
+	// Rerank documents instead of chatting
+	if currentFlags.Rerank {
+		err = handleRerank(currentFlags, registry)
+		return
+	}
+
 	// Handle transcription if specified
 	if currentFlags.TranscribeFile != "" {
 		var transcriptionMessage string
@@ -89,9 +138,79 @@ func Cli(version string) (err error) {
 		currentFlags.Message = AppendMessage(currentFlags.Message, transcriptionMessage)
 	}
 
+	// Convert the EPUB and DOCX documents to Markdown
+	if err = handleDocuments(currentFlags); err != nil {
+		return
+	}
+
+	// Recognize the text of the scanned documents and images
+	if err = handleOCR(currentFlags, registry); err != nil {
+		return
+	}
+
+	// Run the chat on each entry of a list of URLs and files
+	if currentFlags.InputList != "" {
+		err = handleInputList(currentFlags, registry)
+		return
+	}
+
+	// Run the chat on a column of each row of a CSV file
+	if currentFlags.CSV != "" {
+		err = handleCSV(currentFlags, registry)
+		return
+	}
+
+	// Run the steps of a workflow file on the message
+	if currentFlags.Workflow != "" {
+		err = handleWorkflow(currentFlags, registry)
+		return
+	}
+
+	// Handle RSS/Atom feeds, processing each entry separately
+	if currentFlags.RSS != "" {
+		err = handleRSSFeed(currentFlags, registry)
+		return
+	}
+
+	// Re-run the chat whenever the watched path changes
+	if currentFlags.Watch != "" {
+		err = handleWatch(currentFlags, registry)
+		return
+	}
+
+	// Run the chat on the new lines of the followed log file
+	if currentFlags.Follow {
+		err = handleFollow(currentFlags, registry)
+		return
+	}
+
+	// Suggest shell commands and run them once confirmed
+	if currentFlags.Shell {
+		err = handleShell(currentFlags, registry)
+		return
+	}
+
+	// Pick the pattern and settings in a terminal interface
+	if currentFlags.TUI {
+		err = handleTUI(currentFlags, registry)
+		return
+	}
+
+	// Answer JSON requests of a parent process
+	if currentFlags.StdioJSON {
+		err = handleStdioJSON(currentFlags, registry)
+		return
+	}
+
+	// Hands-free voice assistant loop
+	if currentFlags.Listen {
+		err = handleListen(currentFlags, registry)
+		return
+	}
+
 	// Process HTML readability if needed
 	if currentFlags.HtmlReadability {
-		if msg, cleanErr := converter.HtmlReadability(currentFlags.Message); cleanErr != nil {
+		if msg, cleanErr := converter.HtmlReadability(currentFlags.Message, currentFlags.MarkdownOptions()); cleanErr != nil {
 			fmt.Println(i18n.T("html_readability_error"), cleanErr)
 		} else {
 			currentFlags.Message = msg
@@ -0,0 +1,350 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// completionLists maps the flags taking a name to the flag listing the names,
+// which --shell-complete-list prints one per line for the completion scripts
+var completionLists = map[string]string{
+	"pattern":          "--listpatterns",
+	"readpattern":      "--listpatterns",
+	"refine-pattern":   "--listpatterns",
+	"context":          "--listcontexts",
+	"wipecontext":      "--listcontexts",
+	"printcontext":     "--listcontexts",
+	"session":          "--listsessions",
+	"wipesession":      "--listsessions",
+	"printsession":     "--listsessions",
+	"model":            "--listmodels",
+	"vendor":           "--listvendors",
+	"strategy":         "--liststrategies",
+	"rmextension":      "--listextensions",
+	"voice":            "--list-gemini-voices",
+	"transcribe-model": "--list-transcription-models",
+}
+
+// completionChoices are the values of the flags taking one of a few
+var completionChoices = map[string][]string{
+	"thinking":            {"off", "low", "medium", "high"},
+	"reasoning-effort":    {"low", "medium", "high"},
+	"show-think":          {"dim", "stderr"},
+	"debug":               {"0", "1", "2", "3", "4"},
+	"truncate":            {"head", "tail", "middle"},
+	"provider-sort":       {"price", "throughput", "latency"},
+	"embed-format":        {"json", "jsonl"},
+	"moderate":            {"block", "annotate"},
+	"moderation-provider": {"openai", "local"},
+	"diff-style":          {"unified", "side-by-side"},
+	"sync":                {"push", "pull"},
+	"image-size":          {"1024x1024", "1536x1024", "1024x1536", "auto"},
+	"image-quality":       {"low", "medium", "high", "auto"},
+	"image-background":    {"opaque", "transparent"},
+	"completion":          {"zsh", "bash", "fish"},
+	"log-level":           {"debug", "info", "warn", "error"},
+	"log-format":          {"text", "json"},
+	"exec-sandbox":        {"docker", "firejail"},
+}
+
+// completionFiles are the flags taking a path, with the glob of the files
+// they expect when there is one
+var completionFiles = map[string]string{
+	"attachment":      "",
+	"doc":             "*.epub *.docx",
+	"output":          "",
+	"config":          "*.yaml *.yml",
+	"addextension":    "*.yaml *.yml",
+	"image-file":      "*.png *.webp *.jpeg *.jpg",
+	"transcribe-file": "*.mp3 *.mp4 *.mpeg *.mpga *.m4a *.wav *.webm",
+	"think-output":    "",
+	"embed-file":      "",
+	"redact-map":      "",
+	"diff":            "",
+	"output-template": "",
+	"output-dir":      "",
+	"tls-cert":        "",
+	"tls-key":         "",
+	"tls-client-ca":   "",
+	"watch":           "",
+	"apply-code":      "",
+	"render-mermaid":  "*.svg *.png *.pdf",
+	"anki-deck":       "*.apkg *.csv *.txt",
+	"record":          "",
+	"replay":          "",
+	"log-file":        "",
+	"ca-cert":         "*.pem *.crt *.cer",
+	"allow-write":     "",
+	"csv":             "*.csv *.tsv",
+	"meeting":         "*.vtt *.srt *.txt",
+	"logs":            "",
+	"scan":            "*.xml *.json *.jsonl",
+	"stix":            "*.json",
+}
+
+// completionFlag is a flag as the completion scripts see it
+type completionFlag struct {
+	long, short, description string
+	// value is set for the flags taking a value, optional for those where it
+	// can be left out
+	value, optional bool
+	// repeatable flags can be given several times
+	repeatable bool
+}
+
+// completionFlags returns the flags of the command line, in the order of the
+// Flags struct, followed by --help
+func completionFlags() (ret []completionFlag) {
+	for field := range reflect.TypeFor[Flags]().Fields() {
+		long := field.Tag.Get("long")
+		if long == "" {
+			continue
+		}
+		kind := field.Type.Kind()
+		ret = append(ret, completionFlag{
+			long:        long,
+			short:       field.Tag.Get("short"),
+			description: field.Tag.Get("description"),
+			value:       kind != reflect.Bool,
+			optional:    field.Tag.Get("optional") != "",
+			repeatable:  kind == reflect.Slice || kind == reflect.Map,
+		})
+	}
+	return append(ret, completionFlag{long: "help", short: "h", description: "Show this help message"})
+}
+
+// writeCompletion prints the completion script of the shell, completing the
+// names of patterns, models, contexts, sessions and the like with the lists of
+// the fabric command being completed
+func writeCompletion(shell string, w io.Writer) error {
+	flags := completionFlags()
+	switch shell {
+	case "zsh":
+		writeZshCompletion(flags, w)
+	case "bash":
+		writeBashCompletion(flags, w)
+	case "fish":
+		writeFishCompletion(flags, w)
+	default:
+		return fmt.Errorf(i18n.T("completion_unknown_shell"), shell)
+	}
+	return nil
+}
+
+func writeZshCompletion(flags []completionFlag, w io.Writer) {
+	fmt.Fprint(w, `#compdef fabric fabric-ai
+
+# Zsh completion for fabric CLI, generated by fabric --completion zsh
+# Place this file in a directory in your $fpath (e.g. /usr/local/share/zsh/site-functions)
+
+_fabric_list() {
+  local -a items
+  items=(${(f)"$(${words[1]} $1 --shell-complete-list 2>/dev/null)"})
+  compadd -a items
+}
+
+_fabric() {
+  local curcontext="$curcontext" state line
+  typeset -A opt_args
+
+  _arguments -C \
+`)
+	escape := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`)
+	for _, flag := range flags {
+		// '(-p --pattern)'{-p,--pattern}' or '(--session)--session, repeatable
+		// flags not excluding themselves
+		exclusion := "*"
+		if !flag.repeatable {
+			exclusion = "(--" + flag.long + ")"
+			if flag.short != "" {
+				exclusion = "(-" + flag.short + " --" + flag.long + ")"
+			}
+		}
+		names := "'" + exclusion + "--" + flag.long
+		if flag.optional {
+			// go-flags only takes optional values after =
+			names += "=-"
+		}
+		if flag.short != "" {
+			names = fmt.Sprintf("'%s'{-%s,--%s}'", exclusion, flag.short, flag.long)
+		}
+
+		argument := ""
+		if flag.value {
+			colons := ":"
+			if flag.optional {
+				colons = "::"
+			}
+			argument = colons + flag.long + ":" + zshAction(flag.long)
+		}
+		fmt.Fprintf(w, "    %s[%s]%s' \\\n", names, escape.Replace(flag.description), argument)
+	}
+	fmt.Fprint(w, `    '*:arguments:'
+}
+
+_fabric "$@"
+`)
+}
+
+// zshAction returns how zsh completes the value of the flag
+func zshAction(long string) string {
+	if list, ok := completionLists[long]; ok {
+		return "{_fabric_list " + list + "}"
+	}
+	if choices, ok := completionChoices[long]; ok {
+		return "(" + strings.Join(choices, " ") + ")"
+	}
+	if glob, ok := completionFiles[long]; ok {
+		if glob != "" {
+			return `_files -g "` + glob + `"`
+		}
+		return "_files"
+	}
+	return ""
+}
+
+func writeBashCompletion(flags []completionFlag, w io.Writer) {
+	var opts, files, values []string
+	// The flags completed with a list or with choices, grouped by list
+	var groups [][]string
+	groupIndex := map[string]int{}
+	for _, flag := range flags {
+		names := []string{"--" + flag.long}
+		if flag.short != "" {
+			names = []string{"-" + flag.short, "--" + flag.long}
+		}
+		opts = append(opts, "--"+flag.long)
+		if flag.short != "" {
+			opts = append(opts, "-"+flag.short)
+		}
+		if !flag.value {
+			continue
+		}
+
+		words := ""
+		if list, ok := completionLists[flag.long]; ok {
+			words = `$(_fabric_get_list ` + list + `)`
+		} else if choices, ok := completionChoices[flag.long]; ok {
+			words = strings.Join(choices, " ")
+		} else if _, ok := completionFiles[flag.long]; ok {
+			files = append(files, names...)
+			continue
+		} else {
+			values = append(values, names...)
+			continue
+		}
+		if i, ok := groupIndex[words]; ok {
+			groups[i] = append(groups[i], names...)
+			continue
+		}
+		groupIndex[words] = len(groups)
+		groups = append(groups, append([]string{words}, names...))
+	}
+
+	fmt.Fprintf(w, `# Bash completion for fabric CLI, generated by fabric --completion bash
+#
+# Installation:
+# 1. Place this file in a standard completion directory, e.g.,
+#    - /etc/bash_completion.d/
+#    - /usr/local/etc/bash_completion.d/
+#    - ~/.local/share/bash-completion/completions/
+# 2. Or, source it directly in your ~/.bashrc or ~/.bash_profile:
+#    source /path/to/fabric.bash
+
+_fabric() {
+  local cur prev words cword
+  if declare -F _comp_get_words &>/dev/null; then
+    _comp_get_words cur prev words cword
+  else
+    _get_comp_words_by_ref cur prev words cword
+  fi
+
+  local opts="%s"
+
+  # Helper function for dynamic completions
+  _fabric_get_list() {
+    "${COMP_WORDS[0]}" "$1" --shell-complete-list 2>/dev/null
+  }
+
+  # Handle completions based on the previous word
+  case "${prev}" in
+`, strings.Join(opts, " "))
+	for _, group := range groups {
+		fmt.Fprintf(w, "  %s)\n    COMPREPLY=($(compgen -W \"%s\" -- \"${cur}\"))\n    return 0\n    ;;\n", strings.Join(group[1:], " | "), group[0])
+	}
+	fmt.Fprintf(w, `  # Options requiring file/directory paths
+  %s)
+    _filedir
+    return 0
+    ;;
+  # Options requiring simple arguments, typed by the user
+  %s)
+    return 0
+    ;;
+  esac
+
+  # If the current word starts with '-', suggest options
+  if [[ "${cur}" == -* ]]; then
+    COMPREPLY=($(compgen -W "${opts}" -- "${cur}"))
+    return 0
+  fi
+
+  COMPREPLY=()
+}
+
+complete -F _fabric fabric fabric-ai
+`, strings.Join(files, " | "), strings.Join(values, " | "))
+}
+
+func writeFishCompletion(flags []completionFlag, w io.Writer) {
+	fmt.Fprint(w, `# Fish shell completion for fabric CLI, generated by fabric --completion fish
+#
+# Installation:
+# Copy this file to ~/.config/fish/completions/fabric.fish
+# or run:
+# mkdir -p ~/.config/fish/completions
+# cp completions/fabric.fish ~/.config/fish/completions/
+
+# Helper function for dynamic completions
+function __fabric_list
+        set cmd (commandline -opc)[1]
+        $cmd $argv[1] --shell-complete-list 2>/dev/null
+end
+
+function __fabric_register_completions
+        set cmd $argv[1]
+        complete -c $cmd -f
+
+`)
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	for _, flag := range flags {
+		line := "        complete -c $cmd"
+		if flag.short != "" {
+			line += " -s " + flag.short
+		}
+		line += " -l " + flag.long + " -d '" + quote.Replace(flag.description) + "'"
+		if flag.value {
+			if list, ok := completionLists[flag.long]; ok {
+				line += ` -a "(__fabric_list ` + list + `)"`
+			} else if choices, ok := completionChoices[flag.long]; ok {
+				line += ` -a "` + strings.Join(choices, " ") + `"`
+			}
+			if _, ok := completionFiles[flag.long]; ok {
+				line += " -F"
+			}
+			if !flag.optional {
+				line += " -r"
+			}
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprint(w, `end
+
+__fabric_register_completions fabric
+__fabric_register_completions fabric-ai
+`)
+}
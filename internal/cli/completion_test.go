@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCompletionMatchesCompletionFiles(t *testing.T) {
+	files := map[string]string{"zsh": "_fabric", "bash": "fabric.bash", "fish": "fabric.fish"}
+	for shell, file := range files {
+		t.Run(shell, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, writeCompletion(shell, &out))
+			want, err := os.ReadFile(filepath.Join("..", "..", "completions", file))
+			require.NoError(t, err)
+			assert.Equal(t, string(want), out.String(), "regenerate completions/%s with fabric --completion %s", file, shell)
+		})
+	}
+}
+
+func TestWriteCompletionCompletesNames(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeCompletion("zsh", &out))
+	script := out.String()
+	assert.Contains(t, script, `'(-p --pattern)'{-p,--pattern}'[`)
+	assert.Contains(t, script, ":pattern:{_fabric_list --listpatterns}'")
+	assert.Contains(t, script, ":model:{_fabric_list --listmodels}'")
+	assert.Contains(t, script, ":strategy:{_fabric_list --liststrategies}'")
+	assert.Contains(t, script, `'*'{-C,--context}'[`)
+	assert.Contains(t, script, "'(--show-think)--show-think=-[")
+
+	out.Reset()
+	require.NoError(t, writeCompletion("fish", &out))
+	assert.Contains(t, out.String(), `complete -c $cmd -l session -d `)
+	assert.Contains(t, out.String(), `-a "(__fabric_list --listsessions)" -r`)
+}
+
+func TestWriteCompletionListsEveryFlag(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeCompletion("bash", &out))
+	for _, flag := range completionFlags() {
+		assert.True(t, strings.Contains(out.String(), " --"+flag.long+" ") || strings.Contains(out.String(), " --"+flag.long+"\""),
+			"--%s missing", flag.long)
+	}
+}
+
+func TestWriteCompletionUnknownShell(t *testing.T) {
+	err := writeCompletion("tcsh", &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"tcsh"`)
+}
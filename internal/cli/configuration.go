@@ -16,6 +16,11 @@ func handleConfigurationCommands(currentFlags *Flags, registry *core.PluginRegis
 		return true, err
 	}
 
+	if currentFlags.Sync != "" {
+		err = handleSync(currentFlags, registry)
+		return true, err
+	}
+
 	if currentFlags.ChangeDefaultModel {
 		if err = registry.Defaults.Setup(); err != nil {
 			return true, err
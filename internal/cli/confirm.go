@@ -0,0 +1,17 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// confirm writes the prompt, formatted with the args, to out and reads the
+// answer from in, which is yes only for y or yes, defaulting to no.
+func confirm(in io.Reader, out io.Writer, prompt string, args ...any) bool {
+	fmt.Fprintf(out, prompt, args...)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfirm(t *testing.T) {
+	for answer, want := range map[string]bool{"y\n": true, "Yes\n": true, " YES \n": true, "\n": false, "no\n": false, "": false} {
+		var out bytes.Buffer
+		if got := confirm(strings.NewReader(answer), &out, "Write %d files to %s? [y/N] ", 2, "app"); got != want {
+			t.Errorf("confirm(%q) = %v, want %v", answer, got, want)
+		}
+		if out.String() != "Write 2 files to app? [y/N] " {
+			t.Errorf("prompt = %q", out.String())
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+)
+
+// shellOperators chain, substitute or redirect commands
+const shellOperators = ";&|`$<>()\n"
+
+// contextCommandsOutput runs the --context-cmd commands and returns their output
+// as an ephemeral context. Commands missing from contextCmdAllow are confirmed
+// on the terminal first.
+func (o *Flags) contextCommandsOutput(in *os.File, out io.Writer) (ret string, err error) {
+	var sections []string
+	for _, command := range o.ContextCmd {
+		if !o.contextCommandAllowed(command) {
+			if !mdrender.IsTerminal(in) {
+				return "", fmt.Errorf(i18n.T("context_cmd_not_allowed"), command)
+			}
+			if !confirm(in, out, i18n.T("context_cmd_confirm"), command) {
+				return "", fmt.Errorf(i18n.T("context_cmd_declined"), command)
+			}
+		}
+
+		var output string
+		if output, err = o.runContextCommand(command); err != nil {
+			return
+		}
+		sections = append(sections, fmt.Sprintf("Output of `%s`:\n```\n%s\n```", command, strings.TrimRight(output, "\n")))
+	}
+	ret = strings.Join(sections, "\n\n")
+	return
+}
+
+// contextCommandAllowed reports whether the command starts with the words of
+// one of the contextCmdAllow entries. Commands chaining or redirecting with the
+// shell always need a confirmation, so that an allowed prefix cannot run more.
+func (o *Flags) contextCommandAllowed(command string) bool {
+	if strings.ContainsAny(command, shellOperators) {
+		return false
+	}
+	words := strings.Fields(command)
+	for _, allowed := range o.ContextCmdAllow {
+		prefix := strings.Fields(allowed)
+		if len(prefix) > 0 && len(prefix) <= len(words) && slices.Equal(words[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// runContextCommand runs the command through the shell, returning its standard
+// output. Its standard error goes to the terminal.
+func (o *Flags) runContextCommand(command string) (ret string, err error) {
+	ctx, cancel := o.requestContext()
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return "", fmt.Errorf(i18n.T("context_cmd_error"), command, err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextCommandAllowed(t *testing.T) {
+	flags := &Flags{ContextCmdAllow: []string{"kubectl get", "uptime"}}
+
+	assert.True(t, flags.contextCommandAllowed("kubectl get pods -A"))
+	assert.True(t, flags.contextCommandAllowed("uptime"))
+	assert.False(t, flags.contextCommandAllowed("kubectl delete pods --all"))
+	assert.False(t, flags.contextCommandAllowed("kubectl"))
+	assert.False(t, flags.contextCommandAllowed("kubectl get pods; rm -rf ~"))
+	assert.False(t, flags.contextCommandAllowed("kubectl get $(whoami)"))
+}
+
+func TestContextCommandsOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	flags := &Flags{ContextCmd: []string{"echo pods"}, ContextCmdAllow: []string{"echo"}}
+
+	got, err := flags.contextCommandsOutput(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Output of `echo pods`:\n```\npods\n```", got)
+
+	// Commands that are not allowed need a terminal to be confirmed
+	flags.ContextCmd = []string{"printf secret"}
+	_, err = flags.contextCommandsOutput(nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+)
+
+// csvTable is a CSV or TSV file, its first row naming the columns
+type csvTable struct {
+	Header []string
+	Rows   [][]string
+	// Comma is the separator of the file, a tab for .tsv files
+	Comma rune
+}
+
+// readCSVTable reads the CSV file, or TSV file for the .tsv extension
+func readCSVTable(path string) (ret *csvTable, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	ret = &csvTable{Comma: ','}
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		ret.Comma = '\t'
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.Comma = ret.Comma
+	reader.FieldsPerRecord = -1
+	var records [][]string
+	if records, err = reader.ReadAll(); err != nil {
+		return nil, fmt.Errorf(i18n.T("csv_invalid"), path, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf(i18n.T("csv_empty"), path)
+	}
+	ret.Header, ret.Rows = records[0], records[1:]
+	return
+}
+
+// column returns the index of the column, or -1
+func (o *csvTable) column(name string) int {
+	return slices.IndexFunc(o.Header, func(header string) bool {
+		return strings.EqualFold(strings.TrimSpace(header), name)
+	})
+}
+
+// write writes the table with its separator
+func (o *csvTable) write(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = o.Comma
+	if err := writer.Write(o.Header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(o.Rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// csvCheckpointEntry is the output of a row, recorded with the hash of its
+// input so that the rows of a changed file run again
+type csvCheckpointEntry struct {
+	Row    int    `json:"row"`
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+// csvCheckpointPath returns the checkpoint of the outputs of the rows, next
+// to the output file, or else to the CSV file
+func csvCheckpointPath(flags *Flags) string {
+	return cmp.Or(flags.Output, flags.CSV) + ".checkpoint.jsonl"
+}
+
+func csvInputHash(input string) string {
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadCSVCheckpoint returns the outputs of the rows recorded by an earlier
+// run, by row
+func loadCSVCheckpoint(path string) (ret map[int]csvCheckpointEntry, err error) {
+	ret = map[int]csvCheckpointEntry{}
+	var data []byte
+	if data, err = os.ReadFile(path); errors.Is(err, os.ErrNotExist) {
+		return ret, nil
+	} else if err != nil {
+		return
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		var entry csvCheckpointEntry
+		// A line cut by an interruption is run again
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			ret[entry.Row] = entry
+		}
+	}
+	return ret, scanner.Err()
+}
+
+// csvRowRunner returns the output of the pattern on the input of the row,
+// the cells of the row being its pattern variables
+type csvRowRunner func(input string, variables map[string]string) (string, error)
+
+// fillCSVColumn runs the rows whose output cell is empty, concurrency at a
+// time, the outputs of the checkpoint being used for the rows with the same
+// input. The outputs are recorded as they come, and the number of rows that
+// failed is returned.
+func fillCSVColumn(table *csvTable, inputCol, outputCol, concurrency int, checkpoint map[int]csvCheckpointEntry,
+	record func(entry csvCheckpointEntry) error, run csvRowRunner) (failed int, err error) {
+	var pending []int
+	for i, row := range table.Rows {
+		for len(row) < len(table.Header) {
+			row = append(row, "")
+		}
+		table.Rows[i] = row
+		input := strings.TrimSpace(row[inputCol])
+		if input == "" || row[outputCol] != "" {
+			continue
+		}
+		if entry, ok := checkpoint[i]; ok && entry.Input == csvInputHash(input) {
+			row[outputCol] = entry.Output
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	done := 0
+	rows := make(chan int)
+	var wg sync.WaitGroup
+	for range max(concurrency, 1) {
+		wg.Go(func() {
+			for i := range rows {
+				row := table.Rows[i]
+				variables := map[string]string{}
+				for col, header := range table.Header {
+					variables[strings.TrimSpace(header)] = row[col]
+				}
+				output, runErr := run(strings.TrimSpace(row[inputCol]), variables)
+
+				mu.Lock()
+				done++
+				if runErr != nil {
+					debuglog.Log(i18n.T("csv_row_failed"), i+2, runErr)
+					failed++
+				} else {
+					row[outputCol] = output
+					if recordErr := record(csvCheckpointEntry{Row: i, Input: csvInputHash(strings.TrimSpace(row[inputCol])), Output: output}); recordErr != nil {
+						errs = append(errs, recordErr)
+					}
+					debuglog.Log(i18n.T("csv_row_done"), done, len(pending))
+				}
+				mu.Unlock()
+			}
+		})
+	}
+	for _, i := range pending {
+		rows <- i
+	}
+	close(rows)
+	wg.Wait()
+	return failed, errors.Join(errs...)
+}
+
+// handleCSV runs the pattern on the --csv-input-col cell of each row of the
+// --csv file, writing the file with the outputs in --csv-output-col to
+// --output, or else to stdout. The rows run --csv-concurrency at a time, and
+// their outputs are recorded in a checkpoint as they come, so that a run
+// interrupted or partly failed resumes with the rows left when run again.
+func handleCSV(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	var table *csvTable
+	if table, err = readCSVTable(currentFlags.CSV); err != nil {
+		return
+	}
+	inputCol := table.column(currentFlags.CSVInputCol)
+	if currentFlags.CSVInputCol == "" && len(table.Header) == 1 {
+		inputCol = 0
+	}
+	if inputCol < 0 {
+		return fmt.Errorf(i18n.T("csv_column_not_found"), currentFlags.CSVInputCol, currentFlags.CSV, strings.Join(table.Header, ", "))
+	}
+	outputName := cmp.Or(currentFlags.CSVOutputCol, "output")
+	outputCol := table.column(outputName)
+	if outputCol < 0 {
+		table.Header = append(table.Header, outputName)
+		outputCol = len(table.Header) - 1
+	}
+
+	checkpointPath := csvCheckpointPath(currentFlags)
+	var checkpoint map[int]csvCheckpointEntry
+	if checkpoint, err = loadCSVCheckpoint(checkpointPath); err != nil {
+		return
+	}
+	var checkpointFile *os.File
+	if checkpointFile, err = os.OpenFile(checkpointPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600); err != nil {
+		return
+	}
+	record := func(entry csvCheckpointEntry) error {
+		data, marshalErr := json.Marshal(entry)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		_, writeErr := checkpointFile.Write(append(data, '\n'))
+		return writeErr
+	}
+
+	failed, err := fillCSVColumn(table, inputCol, outputCol, currentFlags.CSVConcurrency, checkpoint, record,
+		func(input string, variables map[string]string) (string, error) {
+			rowFlags := *currentFlags
+			rowFlags.CSV = ""
+			rowFlags.Message = AppendMessage(currentFlags.Message, input)
+			rowFlags.PatternVariables = maps.Clone(variables)
+			maps.Copy(rowFlags.PatternVariables, currentFlags.PatternVariables)
+			rowFlags.Output = ""
+			rowFlags.OutputDir = ""
+			rowFlags.Copy = false
+			// Rows run in parallel, the table being printed once complete
+			rowFlags.Stream = false
+			rowFlags.Quiet = true
+			rowFlags.silent = true
+			output, runErr := runChat(&rowFlags, registry, "")
+			return strings.TrimSpace(output), runErr
+		})
+	checkpointFile.Close()
+	if err != nil {
+		return
+	}
+
+	var sb strings.Builder
+	if err = table.write(&sb); err != nil {
+		return
+	}
+	if currentFlags.Output == "" {
+		fmt.Print(sb.String())
+	} else if err = os.WriteFile(currentFlags.Output, []byte(sb.String()), 0o644); err != nil {
+		return fmt.Errorf(i18n.T("error_writing_to_file"), err)
+	}
+	if failed > 0 {
+		return fmt.Errorf(i18n.T("csv_rows_failed"), failed, len(table.Rows))
+	}
+	// Every row has its output, the next runs starting over
+	return os.Remove(checkpointPath)
+}
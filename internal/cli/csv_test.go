@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReadCSVTable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reviews.tsv")
+	if err := os.WriteFile(path, []byte("\ufeffid\tText\n1\tgreat, really\n2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	table, err := readCSVTable(path)
+	if err != nil {
+		t.Fatalf("readCSVTable() error = %v", err)
+	}
+	if table.Comma != '\t' || table.column("text") != 1 || table.column("id") != 0 || len(table.Rows) != 2 {
+		t.Errorf("readCSVTable() = %+v", table)
+	}
+
+	var sb strings.Builder
+	if err = table.write(&sb); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if want := "id\tText\n1\tgreat, really\n2\n"; sb.String() != want {
+		t.Errorf("write() = %q, want %q", sb.String(), want)
+	}
+}
+
+func TestFillCSVColumn(t *testing.T) {
+	table := &csvTable{
+		Header: []string{"id", "text", "summary"},
+		Rows: [][]string{
+			{"1", "first"},
+			{"2", "second", "done before"},
+			{"3", " "},
+			{"4", "fourth"},
+			{"5", "changed"},
+			{"6", "fails"},
+		},
+	}
+	checkpoint := map[int]csvCheckpointEntry{
+		3: {Row: 3, Input: csvInputHash("fourth"), Output: "from checkpoint"},
+		4: {Row: 4, Input: csvInputHash("old"), Output: "stale"},
+	}
+	var mu sync.Mutex
+	var recorded []csvCheckpointEntry
+	record := func(entry csvCheckpointEntry) error {
+		recorded = append(recorded, entry)
+		return nil
+	}
+	var ran []string
+	failed, err := fillCSVColumn(table, 1, 2, 3, checkpoint, record, func(input string, variables map[string]string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, input)
+		if input == "fails" {
+			return "", errors.New("boom")
+		}
+		return "summary of " + variables["id"] + ": " + input, nil
+	})
+	if err != nil {
+		t.Fatalf("fillCSVColumn() error = %v", err)
+	}
+	if failed != 1 || len(ran) != 3 || len(recorded) != 2 {
+		t.Errorf("fillCSVColumn() failed %d, ran %v, recorded %v; want the first, changed and failing rows run", failed, ran, recorded)
+	}
+	want := []string{"summary of 1: first", "done before", "", "from checkpoint", "summary of 5: changed", ""}
+	for i, row := range table.Rows {
+		if row[2] != want[i] {
+			t.Errorf("row %d output = %q, want %q", i, row[2], want[i])
+		}
+	}
+}
+
+func TestLoadCSVCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv.checkpoint.jsonl")
+	checkpoint, err := loadCSVCheckpoint(path)
+	if err != nil || len(checkpoint) != 0 {
+		t.Fatalf("loadCSVCheckpoint() of a missing file = %v, %v", checkpoint, err)
+	}
+	content := `{"row":0,"input":"a","output":"x"}` + "\n" + `{"row":2,"input":"b","output":"y"}` + "\n" + `{"row":3,"inp`
+	if err = os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint, err = loadCSVCheckpoint(path); err != nil {
+		t.Fatalf("loadCSVCheckpoint() error = %v", err)
+	}
+	if len(checkpoint) != 2 || checkpoint[2].Output != "y" {
+		t.Errorf("loadCSVCheckpoint() = %v, want the complete lines", checkpoint)
+	}
+}
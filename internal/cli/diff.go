@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	diffStyleUnified    = "unified"
+	diffStyleSideBySide = "side-by-side"
+
+	diffContextLines = 3
+	// sideBySideWidth is the width of each column of a side-by-side diff
+	sideBySideWidth = 60
+)
+
+// handleDiff prints the diff between the file given by --diff and the output,
+// then writes the output to the file with --apply. A missing file is compared as
+// empty, so --apply can create it.
+func handleDiff(flags *Flags, output string) (err error) {
+	fileName := flags.Diff
+	var current []byte
+	if current, err = os.ReadFile(fileName); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf(i18n.T("diff_error_reading_file"), fileName, err)
+	}
+	err = nil
+	if output != "" && !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+
+	var diff string
+	if diff, err = renderDiff(string(current), output, fileName, flags.DiffStyle); err != nil {
+		return
+	}
+	if diff == "" {
+		debuglog.Log(i18n.T("diff_no_changes"), fileName)
+		return
+	}
+	fmt.Print(diff)
+
+	if !flags.Apply {
+		return
+	}
+	mode := fs.FileMode(0644)
+	if info, statErr := os.Stat(fileName); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+	if err = os.WriteFile(fileName, []byte(output), mode); err != nil {
+		return fmt.Errorf(i18n.T("diff_error_writing_file"), fileName, err)
+	}
+	debuglog.Log(i18n.T("diff_applied"), fileName)
+	return
+}
+
+// renderDiff returns the diff from before to after in the given style, or an
+// empty string when they are equal.
+func renderDiff(before, after, fileName, style string) (ret string, err error) {
+	if before == after {
+		return
+	}
+	a, b := splitDiffLines(before), splitDiffLines(after)
+	switch style {
+	case "", diffStyleUnified:
+		return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        a,
+			B:        b,
+			FromFile: fileName,
+			ToFile:   fileName + " (output)",
+			Context:  diffContextLines,
+		})
+	case diffStyleSideBySide:
+		return renderSideBySide(a, b), nil
+	default:
+		return "", fmt.Errorf(i18n.T("invalid_diff_style"), style)
+	}
+}
+
+// renderSideBySide renders the changed hunks in two columns, marking changed
+// lines with |, removed ones with < and added ones with >, like diff -y.
+func renderSideBySide(a, b []string) string {
+	var sb strings.Builder
+	matcher := difflib.NewMatcher(a, b)
+	for i, group := range matcher.GetGroupedOpCodes(diffContextLines) {
+		if i > 0 {
+			sb.WriteString(strings.Repeat("-", sideBySideWidth*2+3) + "\n")
+		}
+		for _, op := range group {
+			switch op.Tag {
+			case 'e':
+				for k := op.I1; k < op.I2; k++ {
+					writeSideBySideLine(&sb, a[k], " ", b[op.J1+k-op.I1])
+				}
+			case 'd':
+				for k := op.I1; k < op.I2; k++ {
+					writeSideBySideLine(&sb, a[k], "<", "")
+				}
+			case 'i':
+				for k := op.J1; k < op.J2; k++ {
+					writeSideBySideLine(&sb, "", ">", b[k])
+				}
+			case 'r':
+				for k := 0; k < max(op.I2-op.I1, op.J2-op.J1); k++ {
+					left, right, marker := "", "", "|"
+					if op.I1+k < op.I2 {
+						left = a[op.I1+k]
+					} else {
+						marker = ">"
+					}
+					if op.J1+k < op.J2 {
+						right = b[op.J1+k]
+					} else {
+						marker = "<"
+					}
+					writeSideBySideLine(&sb, left, marker, right)
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+func writeSideBySideLine(sb *strings.Builder, left, marker, right string) {
+	left = fitColumn(strings.TrimRight(left, "\n"))
+	right = strings.TrimRight(right, "\n")
+	line := left + strings.Repeat(" ", sideBySideWidth-utf8.RuneCountInString(left)) + " " + marker + " " + right
+	sb.WriteString(strings.TrimRight(line, " ") + "\n")
+}
+
+// fitColumn truncates a line to the column width.
+func fitColumn(line string) string {
+	line = strings.ReplaceAll(line, "\t", "    ")
+	if utf8.RuneCountInString(line) <= sideBySideWidth {
+		return line
+	}
+	return string([]rune(line)[:sideBySideWidth-1]) + "…"
+}
+
+// splitDiffLines splits text into lines that all end with a new line.
+func splitDiffLines(text string) (ret []string) {
+	if text == "" {
+		return
+	}
+	ret = strings.SplitAfter(text, "\n")
+	if ret[len(ret)-1] == "" {
+		ret = ret[:len(ret)-1]
+	} else {
+		ret[len(ret)-1] += "\n"
+	}
+	return
+}
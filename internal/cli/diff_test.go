@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDiff(t *testing.T) {
+	before := "one\ntwo\nthree\n"
+	after := "one\n2\nthree\nfour\n"
+
+	t.Run("unified", func(t *testing.T) {
+		diff, err := renderDiff(before, after, "notes.txt", "")
+		require.NoError(t, err)
+		assert.Equal(t, "--- notes.txt\n+++ notes.txt (output)\n@@ -1,3 +1,4 @@\n one\n-two\n+2\n three\n+four\n", diff)
+	})
+
+	t.Run("side by side", func(t *testing.T) {
+		diff, err := renderDiff(before, after, "notes.txt", "side-by-side")
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+		require.Len(t, lines, 4)
+		assert.Equal(t, "one", strings.Fields(lines[0])[0])
+		assert.Equal(t, []string{"two", "|", "2"}, strings.Fields(lines[1]))
+		assert.Equal(t, []string{">", "four"}, strings.Fields(lines[3]))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		diff, err := renderDiff(before, before, "notes.txt", "")
+		require.NoError(t, err)
+		assert.Empty(t, diff)
+	})
+
+	t.Run("invalid style", func(t *testing.T) {
+		_, err := renderDiff(before, after, "notes.txt", "html")
+		assert.Error(t, err)
+	})
+}
+
+func TestHandleDiffApply(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "doc.md")
+	require.NoError(t, os.WriteFile(fileName, []byte("old\n"), 0600))
+
+	require.NoError(t, handleDiff(&Flags{Diff: fileName, Apply: true}, "new"))
+
+	content, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(content))
+	info, err := os.Stat(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/githelper"
+	"gopkg.in/yaml.v3"
+)
+
+// doctorTimeout bounds each network check of --doctor
+const doctorTimeout = 20 * time.Second
+
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarning
+	doctorProblem
+)
+
+// doctorFinding is the result of one --doctor check, with the way to fix it
+// unless it passed.
+type doctorFinding struct {
+	Status doctorStatus
+	Detail string
+	Fix    string
+}
+
+func (o doctorFinding) print(out io.Writer) {
+	symbol := "✓"
+	switch o.Status {
+	case doctorWarning:
+		symbol = "⚠"
+	case doctorProblem:
+		symbol = "✗"
+	}
+	fmt.Fprintf(out, "  %s %s\n", symbol, o.Detail)
+	if o.Fix != "" {
+		fmt.Fprintf(out, "      → %s\n", o.Fix)
+	}
+}
+
+// runDoctor checks the configuration of fabric and prints what is wrong and
+// how to fix it. initErr is the error initializing the registry, if any. It
+// fails when a check finds a problem, warnings only being printed.
+func runDoctor(flags *Flags, registry *core.PluginRegistry, initErr error, out io.Writer) (err error) {
+	fmt.Fprintln(out, i18n.T("doctor_header"))
+
+	findings := []doctorFinding{checkConfigFile(flags.Config)}
+	if registry == nil || initErr != nil {
+		findings = append(findings, doctorFinding{Status: doctorProblem,
+			Detail: fmt.Sprintf(i18n.T("doctor_init_failed"), initErr), Fix: i18n.T("doctor_fix_setup")})
+	} else {
+		findings = append(findings, checkEnvFile(registry.Db.EnvFilePath))
+		findings = append(findings, checkVendors(registry)...)
+		findings = append(findings, checkDirectories(registry.Db)...)
+		findings = append(findings, checkPatternsVersion(registry))
+		findings = append(findings, checkStrategies(registry))
+	}
+
+	problems := 0
+	for _, finding := range findings {
+		finding.print(out)
+		if finding.Status == doctorProblem {
+			problems++
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf(i18n.T("doctor_problems_found"), problems)
+	}
+	fmt.Fprintln(out, i18n.T("doctor_no_problems"))
+	return
+}
+
+// checkConfigFile parses the YAML config strictly, so that misspelled keys
+// that are otherwise ignored are reported.
+func checkConfigFile(path string) doctorFinding {
+	if path == "" {
+		return doctorFinding{Detail: i18n.T("doctor_config_none")}
+	}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = decodeConfigStrictly(data)
+	}
+	if err != nil {
+		return doctorFinding{Status: doctorProblem,
+			Detail: fmt.Sprintf(i18n.T("doctor_config_invalid"), path, err), Fix: i18n.T("doctor_fix_config")}
+	}
+	return doctorFinding{Detail: fmt.Sprintf(i18n.T("doctor_config_ok"), path)}
+}
+
+// decodeConfigStrictly decodes the config failing on unknown keys, apart from
+// the blocks of the vendors, whose options must be known.
+func decodeConfigStrictly(data []byte) (err error) {
+	var root yaml.Node
+	if err = yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return
+	}
+	if document := root.Content[0]; document.Kind == yaml.MappingNode {
+		var blocks map[string]any
+		if err = document.Decode(&blocks); err != nil {
+			return
+		}
+		vendors, options := vendorConfigs(blocks), vendorOptionTags()
+		var content []*yaml.Node
+		for i := 0; i+1 < len(document.Content); i += 2 {
+			name := document.Content[i].Value
+			block, ok := vendors[name]
+			if !ok {
+				content = append(content, document.Content[i], document.Content[i+1])
+				continue
+			}
+			for key := range block {
+				if _, ok = options[normalizeOptionName(key)]; !ok {
+					return fmt.Errorf(i18n.T("vendor_config_unknown_option"), key, name)
+				}
+			}
+		}
+		document.Content = content
+		if data, err = yaml.Marshal(&root); err != nil {
+			return
+		}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&Flags{}); errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return
+}
+
+// checkEnvFile warns when the .env file holding the API keys can be read by
+// other users.
+func checkEnvFile(path string) doctorFinding {
+	info, err := os.Stat(path)
+	if err != nil && os.Getenv("DEFAULT_MODEL") != "" {
+		return doctorFinding{Detail: i18n.T("doctor_env_environment_only")}
+	}
+	if err != nil {
+		return doctorFinding{Status: doctorProblem,
+			Detail: fmt.Sprintf(i18n.T("doctor_env_missing"), path), Fix: i18n.T("doctor_fix_setup")}
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return doctorFinding{Status: doctorWarning,
+			Detail: fmt.Sprintf(i18n.T("doctor_env_permissions"), path, info.Mode().Perm()),
+			Fix:    fmt.Sprintf(i18n.T("doctor_fix_env_permissions"), path)}
+	}
+	return doctorFinding{Detail: fmt.Sprintf(i18n.T("doctor_env_ok"), path)}
+}
+
+// checkVendors lists the models of every configured vendor, proving its key
+// and endpoint work, and checks that the default model is one of them.
+func checkVendors(registry *core.PluginRegistry) (ret []doctorFinding) {
+	registry.ConfigureVendors()
+	vendors := registry.VendorManager.Vendors
+	if len(vendors) == 0 {
+		return []doctorFinding{{Status: doctorProblem, Detail: i18n.T("doctor_vendors_none"), Fix: i18n.T("doctor_fix_setup")}}
+	}
+
+	models := make([][]string, len(vendors))
+	errs := make([]error, len(vendors))
+	var wg sync.WaitGroup
+	for i, vendor := range vendors {
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+			defer cancel()
+			models[i], errs[i] = vendor.ListModels(ctx)
+		})
+	}
+	wg.Wait()
+
+	defaultVendor, defaultModel := registry.Defaults.Vendor.Value, registry.Defaults.Model.Value
+	defaultIndex := -1
+	for i, vendor := range vendors {
+		if strings.EqualFold(vendor.GetName(), defaultVendor) {
+			defaultIndex = i
+		}
+		if errs[i] != nil {
+			ret = append(ret, doctorFinding{Status: doctorProblem,
+				Detail: fmt.Sprintf(i18n.T("doctor_vendor_failed"), vendor.GetName(), errs[i]),
+				Fix:    fmt.Sprintf(i18n.T("doctor_fix_vendor"), vendor.GetName())})
+			continue
+		}
+		ret = append(ret, doctorFinding{Detail: fmt.Sprintf(i18n.T("doctor_vendor_ok"), vendor.GetName(), len(models[i]))})
+	}
+
+	switch {
+	case defaultIndex < 0 || defaultModel == "":
+		ret = append(ret, doctorFinding{Status: doctorProblem, Detail: i18n.T("doctor_defaults_missing"), Fix: i18n.T("doctor_fix_defaults")})
+	case errs[defaultIndex] != nil:
+		// The failure of the vendor is reported already
+		ret = append(ret, doctorFinding{Status: doctorWarning,
+			Detail: fmt.Sprintf(i18n.T("doctor_defaults_unchecked"), defaultVendor, defaultModel)})
+	case !slices.ContainsFunc(models[defaultIndex], func(name string) bool { return strings.EqualFold(name, defaultModel) }):
+		ret = append(ret, doctorFinding{Status: doctorWarning,
+			Detail: fmt.Sprintf(i18n.T("doctor_defaults_unknown_model"), defaultModel, defaultVendor), Fix: i18n.T("doctor_fix_defaults")})
+	default:
+		ret = append(ret, doctorFinding{Detail: fmt.Sprintf(i18n.T("doctor_defaults_ok"), defaultVendor, defaultModel)})
+	}
+	return
+}
+
+// checkDirectories checks that the data directories can be written, and
+// reports patterns without a system prompt and sessions that cannot be read.
+func checkDirectories(db *fsdb.Db) (ret []doctorFinding) {
+	dirs := []string{db.Patterns.Dir, db.Contexts.Dir, db.Sessions.Dir}
+	if db.Patterns.CustomPatternsDir != "" {
+		dirs = append(dirs, db.Patterns.CustomPatternsDir)
+	}
+	for _, dir := range dirs {
+		ret = append(ret, checkWritableDir(dir))
+	}
+
+	var incomplete []string
+	for _, dir := range []string{db.Patterns.Dir, db.Patterns.CustomPatternsDir} {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, entry.Name(), db.Patterns.SystemPatternFile)); err != nil {
+				incomplete = append(incomplete, entry.Name())
+			}
+		}
+	}
+	if len(incomplete) > 0 {
+		ret = append(ret, doctorFinding{Status: doctorWarning,
+			Detail: fmt.Sprintf(i18n.T("doctor_patterns_incomplete"), db.Patterns.SystemPatternFile, strings.Join(incomplete, ", ")),
+			Fix:    i18n.T("doctor_fix_patterns_incomplete")})
+	}
+
+	var corrupt []string
+	names, _ := db.Sessions.GetNames()
+	for _, name := range names {
+		var messages []any
+		if err := db.Sessions.LoadAsJson(name, &messages); err != nil {
+			corrupt = append(corrupt, name)
+		}
+	}
+	if len(corrupt) > 0 {
+		ret = append(ret, doctorFinding{Status: doctorWarning,
+			Detail: fmt.Sprintf(i18n.T("doctor_sessions_corrupt"), strings.Join(corrupt, ", ")),
+			Fix:    i18n.T("doctor_fix_sessions_corrupt")})
+	}
+	return
+}
+
+// checkWritableDir checks that a file can be created in the directory.
+func checkWritableDir(dir string) doctorFinding {
+	file, err := os.CreateTemp(dir, ".fabric-doctor-*")
+	if err != nil {
+		return doctorFinding{Status: doctorProblem,
+			Detail: fmt.Sprintf(i18n.T("doctor_dir_failed"), dir, err), Fix: fmt.Sprintf(i18n.T("doctor_fix_dir"), dir)}
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return doctorFinding{Detail: fmt.Sprintf(i18n.T("doctor_dir_ok"), dir)}
+}
+
+// checkPatternsVersion compares the commit the patterns were downloaded from
+// with the HEAD of the patterns repository.
+func checkPatternsVersion(registry *core.PluginRegistry) doctorFinding {
+	commit, updated, err := registry.PatternsLoader.LoadedVersion()
+	if err != nil {
+		return doctorFinding{Status: doctorProblem, Detail: i18n.T("doctor_patterns_missing"), Fix: i18n.T("doctor_fix_update_patterns")}
+	}
+	date := updated.Format(time.DateOnly)
+	if commit == "" {
+		return doctorFinding{Status: doctorWarning,
+			Detail: fmt.Sprintf(i18n.T("doctor_patterns_version_unknown"), date), Fix: i18n.T("doctor_fix_update_patterns")}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	head, err := githelper.RemoteHead(ctx, registry.PatternsLoader.DefaultGitRepoUrl.Value)
+	if err != nil {
+		return doctorFinding{Status: doctorWarning, Detail: fmt.Sprintf(i18n.T("doctor_patterns_remote_failed"), err)}
+	}
+	if head != commit {
+		return doctorFinding{Status: doctorWarning,
+			Detail: fmt.Sprintf(i18n.T("doctor_patterns_outdated"), date, shortCommit(commit), shortCommit(head)),
+			Fix:    i18n.T("doctor_fix_update_patterns")}
+	}
+	return doctorFinding{Detail: fmt.Sprintf(i18n.T("doctor_patterns_current"), shortCommit(commit), date)}
+}
+
+func shortCommit(hash string) string {
+	return hash[:min(len(hash), 7)]
+}
+
+func checkStrategies(registry *core.PluginRegistry) doctorFinding {
+	if !registry.Strategies.IsConfigured() {
+		return doctorFinding{Status: doctorWarning, Detail: i18n.T("doctor_strategies_missing"), Fix: i18n.T("doctor_fix_setup")}
+	}
+	return doctorFinding{Detail: fmt.Sprintf(i18n.T("doctor_strategies_ok"), len(registry.Strategies.Strategies))}
+}
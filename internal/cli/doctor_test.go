@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+func TestCheckConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		status doctorStatus
+	}{
+		{name: "no config", path: "", status: doctorOK},
+		{name: "example", path: "example.yaml", status: doctorOK},
+		{name: "empty", path: write("empty.yaml", ""), status: doctorOK},
+		{name: "misspelled key", path: write("typo.yaml", "model: gpt-4o\ntemprature: 0.2\n"), status: doctorProblem},
+		{name: "vendor block", path: write("vendor.yaml", "model: gpt-4o\nollama:\n  keep_alive: 10m\n"), status: doctorOK},
+		{name: "misspelled vendor option", path: write("vendor-typo.yaml", "openai:\n  temprature: 1.0\n"), status: doctorProblem},
+		{name: "invalid yaml", path: write("invalid.yaml", "model: [gpt-4o\n"), status: doctorProblem},
+		{name: "missing", path: filepath.Join(dir, "missing.yaml"), status: doctorProblem},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if finding := checkConfigFile(tt.path); finding.Status != tt.status {
+				t.Errorf("checkConfigFile(%q) = %+v, want status %d", tt.path, finding, tt.status)
+			}
+		})
+	}
+}
+
+func TestCheckEnvFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not checked on Windows")
+	}
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("DEFAULT_MODEL=gpt-4o\n"), 0o644); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+	if finding := checkEnvFile(path); finding.Status != doctorWarning {
+		t.Errorf("world readable env file: got %+v, want a warning", finding)
+	}
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatalf("failed to chmod env file: %v", err)
+	}
+	if finding := checkEnvFile(path); finding.Status != doctorOK {
+		t.Errorf("private env file: got %+v", finding)
+	}
+
+	t.Setenv("DEFAULT_MODEL", "")
+	if finding := checkEnvFile(path + ".missing"); finding.Status != doctorProblem {
+		t.Errorf("missing env file: got %+v, want a problem", finding)
+	}
+	t.Setenv("DEFAULT_MODEL", "gpt-4o")
+	if finding := checkEnvFile(path + ".missing"); finding.Status != doctorOK {
+		t.Errorf("environment only configuration: got %+v", finding)
+	}
+}
+
+func TestCheckDirectories(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	for _, dir := range []string{db.Contexts.Dir, db.Sessions.Dir, filepath.Join(db.Patterns.Dir, "summarize"), filepath.Join(db.Patterns.Dir, "empty")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(db.Patterns.Dir, "summarize", "system.md"), []byte("Summarize"), 0o644); err != nil {
+		t.Fatalf("failed to write pattern: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.Sessions.Dir, "good.json"), []byte(`[{"role":"user","content":"hi"}]`), 0o644); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.Sessions.Dir, "broken.json"), []byte(`[{"role":`), 0o644); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+
+	var warnings []string
+	for _, finding := range checkDirectories(db) {
+		switch finding.Status {
+		case doctorProblem:
+			t.Errorf("unexpected problem %+v", finding)
+		case doctorWarning:
+			warnings = append(warnings, finding.Detail)
+		}
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "empty") || !strings.Contains(warnings[1], "broken") {
+		t.Errorf("warnings = %q, want the incomplete pattern and the broken session", warnings)
+	}
+}
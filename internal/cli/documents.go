@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/danielmiessler/fabric/internal/tools/converter"
+)
+
+// handleDocuments converts the --doc files and the EPUB and DOCX attachments
+// to Markdown, appended to the message so that patterns get books and reports
+// as text, chapter and page markers included
+func handleDocuments(currentFlags *Flags) (err error) {
+	docs := currentFlags.Docs
+	var attachments []string
+	for _, attachment := range currentFlags.Attachments {
+		if converter.IsDocument(attachment) && !isInputListURL(attachment) {
+			docs = append(docs, attachment)
+		} else {
+			attachments = append(attachments, attachment)
+		}
+	}
+	currentFlags.Attachments = attachments
+	for _, doc := range docs {
+		var text string
+		if text, err = readDocument(doc, currentFlags.MarkdownOptions()); err != nil {
+			return
+		}
+		currentFlags.Message = AppendMessage(currentFlags.Message, text)
+	}
+	currentFlags.Docs = nil
+	return
+}
+
+// readDocument returns the EPUB or DOCX file as Markdown
+func readDocument(path string, opts converter.MarkdownOptions) (ret string, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	return converter.DocumentToMarkdown(path, data, opts)
+}
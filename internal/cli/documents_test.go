@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestHandleDocuments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.docx")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(file)
+	part, err := archive.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = part.Write([]byte(`<document><body><p><r><t>Quarterly figures</t></r></p></body></document>`)); err != nil {
+		t.Fatal(err)
+	}
+	if err = archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	flags := &Flags{Message: "summarize:", Attachments: []string{"photo.png", path, "https://example.com/book.epub"}}
+	if err = handleDocuments(flags); err != nil {
+		t.Fatalf("handleDocuments() error = %v", err)
+	}
+	if flags.Message != "summarize:\nQuarterly figures" {
+		t.Errorf("handleDocuments() message = %q", flags.Message)
+	}
+	if want := []string{"photo.png", "https://example.com/book.epub"}; !slices.Equal(flags.Attachments, want) {
+		t.Errorf("handleDocuments() attachments = %v, want %v", flags.Attachments, want)
+	}
+}
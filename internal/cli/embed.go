@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+const (
+	embedFormatJSON  = "json"
+	embedFormatJSONL = "jsonl"
+
+	embedVendorVoyage = "Voyage"
+	embedStdinInput   = "stdin"
+)
+
+// embedding is a single --embed result.
+type embedding struct {
+	Input     string    `json:"input"`
+	Model     string    `json:"model"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// handleEmbed computes the embeddings of the message (stdin and arguments) and of
+// each --embed-file, and writes them as JSON or JSONL to stdout or the output file.
+func handleEmbed(flags *Flags, registry *core.PluginRegistry) (err error) {
+	if flags.EmbedModel == "" {
+		return errors.New(i18n.T("embed_model_required"))
+	}
+	// Validate the format before calling the vendor
+	if _, err = formatEmbeddings(nil, flags.EmbedFormat); err != nil {
+		return
+	}
+
+	var labels, inputs []string
+	if message := strings.TrimSpace(flags.Message); message != "" {
+		labels = append(labels, embedStdinInput)
+		inputs = append(inputs, message)
+	}
+	for _, path := range flags.EmbedFiles {
+		var content []byte
+		if content, err = os.ReadFile(path); err != nil {
+			return fmt.Errorf(i18n.T("embed_error_reading_file"), path, err)
+		}
+		labels = append(labels, path)
+		inputs = append(inputs, string(content))
+	}
+	if len(inputs) == 0 {
+		return errors.New(i18n.T("embed_no_input"))
+	}
+
+	var embedder ai.Embedder
+	if embedder, err = findEmbedder(flags.Vendor, registry); err != nil {
+		return
+	}
+
+	var vectors [][]float64
+	ctx, cancel := flags.requestContext()
+	defer cancel()
+	if vectors, err = embedder.Embeddings(ctx, inputs, flags.EmbedModel); err != nil {
+		return
+	}
+
+	results := make([]embedding, len(inputs))
+	for i := range inputs {
+		results[i] = embedding{Input: labels[i], Model: flags.EmbedModel, Embedding: vectors[i]}
+	}
+
+	var output string
+	if output, err = formatEmbeddings(results, flags.EmbedFormat); err != nil {
+		return
+	}
+	if flags.Output != "" {
+		return CreateOutputFile(output, flags.Output)
+	}
+	fmt.Println(output)
+	return
+}
+
+// findEmbedder returns the embedding client of the vendor, OpenAI by default.
+func findEmbedder(vendorName string, registry *core.PluginRegistry) (ret ai.Embedder, err error) {
+	if vendorName == "" {
+		vendorName = "OpenAI"
+	}
+
+	if strings.EqualFold(vendorName, embedVendorVoyage) {
+		if registry.Voyage.ApiKey.Value == "" {
+			return nil, fmt.Errorf(i18n.T("vendor_not_configured"), embedVendorVoyage)
+		}
+		return registry.Voyage, nil
+	}
+
+	vendor := registry.VendorManager.FindByName(vendorName)
+	if vendor == nil {
+		return nil, fmt.Errorf(i18n.T("vendor_not_configured"), vendorName)
+	}
+	var ok bool
+	if ret, ok = vendor.(ai.Embedder); !ok {
+		return nil, fmt.Errorf(i18n.T("vendor_no_embedding_support"), vendorName)
+	}
+	return
+}
+
+// formatEmbeddings renders the embeddings as a JSON array or as JSON lines.
+func formatEmbeddings(results []embedding, format string) (ret string, err error) {
+	var data []byte
+	switch format {
+	case "", embedFormatJSON:
+		if data, err = json.MarshalIndent(results, "", "  "); err != nil {
+			return
+		}
+		return string(data), nil
+	case embedFormatJSONL:
+		lines := make([]string, len(results))
+		for i, result := range results {
+			if data, err = json.Marshal(result); err != nil {
+				return
+			}
+			lines[i] = string(data)
+		}
+		return strings.Join(lines, "\n"), nil
+	default:
+		return "", fmt.Errorf(i18n.T("invalid_embed_format"), format)
+	}
+}
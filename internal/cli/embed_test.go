@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatEmbeddings(t *testing.T) {
+	results := []embedding{
+		{Input: "stdin", Model: "text-embedding-3-small", Embedding: []float64{0.1, 0.2}},
+		{Input: "notes.md", Model: "text-embedding-3-small", Embedding: []float64{0.3}},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		output, err := formatEmbeddings(results, "json")
+		require.NoError(t, err)
+
+		var decoded []embedding
+		require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+		assert.Equal(t, results, decoded)
+	})
+
+	t.Run("default is json", func(t *testing.T) {
+		output, err := formatEmbeddings(results, "")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(output, "["))
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		output, err := formatEmbeddings(results, "jsonl")
+		require.NoError(t, err)
+
+		lines := strings.Split(output, "\n")
+		require.Len(t, lines, 2)
+		for i, line := range lines {
+			var decoded embedding
+			require.NoError(t, json.Unmarshal([]byte(line), &decoded))
+			assert.Equal(t, results[i], decoded)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		_, err := formatEmbeddings(results, "csv")
+		assert.Error(t, err)
+	})
+}
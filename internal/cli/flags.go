@@ -11,11 +11,23 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/danielmiessler/fabric/internal/bots"
+	"github.com/danielmiessler/fabric/internal/bots/email"
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/plugins/strategy"
+	restapi "github.com/danielmiessler/fabric/internal/server"
+	"github.com/danielmiessler/fabric/internal/tools/converter"
+	"github.com/danielmiessler/fabric/internal/tools/imageproc"
+	"github.com/danielmiessler/fabric/internal/tools/mcp"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
 	"github.com/danielmiessler/fabric/internal/util"
 	"github.com/jessevdk/go-flags"
 	"golang.org/x/text/language"
@@ -28,29 +40,75 @@ import (
 type Flags struct {
 	Pattern                         string               `short:"p" long:"pattern" yaml:"pattern" description:"Choose a pattern from the available patterns" default:""`
 	PatternVariables                map[string]string    `short:"v" long:"variable" description:"Values for pattern variables, e.g. -v=#role:expert -v=#points:30"`
-	Context                         string               `short:"C" long:"context" description:"Choose a context from the available contexts" default:""`
+	Context                         []string             `short:"C" long:"context" description:"Choose contexts from the available contexts, repeatable or comma separated and joined in order"`
+	ContextVariables                map[string]string    `long:"context-var" description:"Values for context variables, e.g. --context-var=project:fabric"`
+	ContextCmd                      []string             `long:"context-cmd" description:"Run the shell command and add its output to the context, after confirmation unless allowed by contextCmdAllow"`
 	Session                         string               `long:"session" description:"Choose a session from the available sessions"`
+	SessionMaxMessages              int                  `long:"session-max-messages" yaml:"sessionMaxMessages" description:"Keep at most this many user and assistant messages in sessions, dropping the oldest"`
+	SessionMaxTokens                int                  `long:"session-max-tokens" yaml:"sessionMaxTokens" description:"Keep sessions under this estimated number of tokens, dropping the oldest messages"`
+	SessionTTL                      time.Duration        `long:"session-ttl" yaml:"sessionTTL" description:"Start sessions unused for longer than this duration (e.g. 72h) over"`
+	SessionSummarize                bool                 `long:"session-summarize" yaml:"sessionSummarize" description:"Summarize the oldest messages of sessions instead of dropping them, also when a session outgrows the context window"`
+	SessionTitle                    string               `long:"session-title" description:"Title the session, instead of the first line of its first message"`
+	SessionTags                     string               `long:"session-tags" description:"Tag the session with these comma-separated tags, or with --listsessions list the sessions having all of them"`
+	SessionSort                     string               `long:"session-sort" yaml:"sessionSort" description:"Order of --listsessions: name, title, created or updated, the last two newest first" default:"name"`
+	SessionSearch                   string               `long:"session-search" description:"List the sessions with this keyword in their name, title or tags"`
+	SearchSessions                  string               `long:"search-sessions" description:"Search the messages of the saved sessions and the contexts for these words, or by meaning with --embed-model, and print the matching excerpts"`
+	Resume                          bool                 `long:"resume" description:"Continue the interrupted response of the session, or of the last chat without a session"`
 	Attachments                     []string             `short:"a" long:"attachment" description:"Attachment path or URL (e.g. for OpenAI image recognition messages)"`
+	Docs                            []string             `long:"doc" description:"Convert this EPUB or DOCX file to Markdown, with chapter or page markers, and add it to the input; also done for EPUB and DOCX attachments (repeatable)"`
+	OCR                             bool                 `long:"ocr" description:"Recognize the text of the image and PDF attachments and add it to the input; PDFs without a text layer are always recognized"`
+	OCRLanguages                    string               `long:"ocr-lang" description:"Languages of the text recognized by Tesseract, as Tesseract codes, e.g. eng+deu" default:"eng"`
+	OCRModel                        string               `long:"ocr-model" description:"Recognize the text with this vision model instead of Tesseract, e.g. gpt-4o or OpenAI|gpt-4o"`
+	ImageMaxDim                     int                  `long:"image-max-dim" yaml:"imageMaxDim" description:"Downscale image attachments so their longest side is at most this many pixels (0 = no limit)"`
+	ResizeImages                    bool                 `long:"resize-images" yaml:"resizeImages" description:"Downscale image attachments to the largest size the vendor takes, unless --image-max-dim is set"`
+	StripEXIF                       bool                 `long:"strip-exif" yaml:"stripExif" description:"Strip EXIF and other metadata from image attachments before sending them"`
+	ConvertHEIC                     bool                 `long:"convert-heic" yaml:"convertHeic" description:"Convert HEIC/HEIF image attachments, which most vendors reject, to JPEG"`
 	Setup                           bool                 `short:"S" long:"setup" description:"Run setup for all reconfigurable parts of fabric"`
+	SetupVendor                     string               `long:"setup-vendor" description:"Set up the vendor without asking questions, e.g. for containers and CI"`
+	SetupKey                        string               `long:"setup-key" description:"API key of the vendor set up by --setup-vendor"`
+	SetupURL                        string               `long:"setup-url" description:"API base URL of the vendor set up by --setup-vendor"`
+	SetupSettings                   map[string]string    `long:"setup-set" description:"Other setting of the vendor set up by --setup-vendor, e.g. --setup-set=API_VERSION:2024-10-21"`
+	SetupDefaultModel               string               `long:"setup-default-model" description:"Set the default model without asking questions"`
 	Temperature                     float64              `short:"t" long:"temperature" yaml:"temperature" description:"Set temperature" default:"0.7"`
 	TopP                            float64              `short:"T" long:"topp" yaml:"topp" description:"Set top P" default:"0.9"`
 	Stream                          bool                 `short:"s" long:"stream" yaml:"stream" description:"Stream"`
 	PresencePenalty                 float64              `short:"P" long:"presencepenalty" yaml:"presencepenalty" description:"Set presence penalty" default:"0.0"`
 	Raw                             bool                 `short:"r" long:"raw" yaml:"raw" description:"Use the defaults of the model without sending chat options (temperature, top_p, etc.). Only affects OpenAI-compatible providers. Anthropic models always use smart parameter selection to comply with model-specific requirements."`
+	ModelParam                      []string             `long:"model-param" yaml:"modelParams" description:"Merge key=value into the JSON request sent to the vendor, e.g. top_k=40 or response_format.type=json_object, the value being JSON or a string (can be used multiple times)"`
+	Logprobs                        bool                 `long:"logprobs" description:"Return the log probability of each token of the response with --json (OpenAI and compatible vendors)"`
+	TopLogprobs                     int                  `long:"top-logprobs" description:"Also return this many most likely alternatives of each token, up to 20 (implies --logprobs)"`
 	FrequencyPenalty                float64              `short:"F" long:"frequencypenalty" yaml:"frequencypenalty" description:"Set frequency penalty" default:"0.0"`
 	ListPatterns                    bool                 `short:"l" long:"listpatterns" description:"List all patterns"`
+	Tags                            string               `long:"tags" description:"List the patterns having all these comma-separated tags, with their description"`
+	SearchPatterns                  string               `long:"search-patterns" description:"List the patterns with this keyword in their name, description or tags"`
+	JSON                            bool                 `long:"json" description:"Print the pattern or session listing or the spend report as JSON, with the description, tags and variables of the patterns or the metadata of the sessions, or the response as JSON with its model and --logprobs"`
 	ReadPattern                     string               `long:"readpattern" description:"Print the contents of the named pattern to the terminal"`
 	ListAllModels                   bool                 `short:"L" long:"listmodels" description:"List all available models"`
 	ListAllContexts                 bool                 `short:"x" long:"listcontexts" description:"List all contexts"`
 	ListAllSessions                 bool                 `short:"X" long:"listsessions" description:"List all sessions"`
+	Spend                           bool                 `long:"spend" description:"Print the spend of today, this week and this month by vendor and model, or as JSON with --json"`
 	UpdatePatterns                  bool                 `short:"U" long:"updatepatterns" description:"Update patterns"`
+	Sync                            string               `long:"sync" description:"Sync custom patterns, sessions and contexts with the backend set up with --setup: push or pull"`
 	Message                         string               `hidden:"true" description:"Messages to send to chat"`
 	Copy                            bool                 `short:"c" long:"copy" description:"Copy to clipboard"`
 	Model                           string               `short:"m" long:"model" yaml:"model" description:"Choose model"`
 	Vendor                          string               `short:"V" long:"vendor" yaml:"vendor" description:"Specify vendor for the selected model (e.g., -V \"LM Studio\" -m openai/gpt-oss-20b)"`
 	ModelContextLength              int                  `long:"modelContextLength" yaml:"modelContextLength" description:"Model context length (only affects ollama)"`
+	MaxOutputTokens                 int                  `long:"max-output-tokens" yaml:"maxOutputTokens" description:"Limit the response to this many tokens"`
+	MaxCost                         float64              `long:"max-cost" yaml:"maxCost" description:"Abort the request when its estimated cost in USD is over this amount, before sending it or while it streams, e.g. --max-cost 0.50"`
+	KeepAlive                       string               `long:"keep-alive" yaml:"keepAlive" description:"How long the model stays loaded after the request, like 10m, or -1 for ever (only affects ollama)"`
+	NumGPU                          *int                 `long:"num-gpu" yaml:"numGPU" description:"Number of model layers offloaded to the GPUs, 0 for the CPU only (only affects ollama)"`
+	NumThread                       int                  `long:"num-thread" yaml:"numThread" description:"Number of CPU threads, by default the number of physical cores (only affects ollama)"`
+	NumBatch                        int                  `long:"num-batch" yaml:"numBatch" description:"Number of prompt tokens processed at once (only affects ollama)"`
+	Mirostat                        int                  `long:"mirostat" yaml:"mirostat" description:"Sample with Mirostat for a steady perplexity: 1, 2 for Mirostat 2.0, or 0 for off (only affects ollama)"`
+	Truncate                        string               `long:"truncate" yaml:"truncate" description:"Truncate input exceeding the model context window instead of failing: head, tail or middle (the part dropped)"`
+	Timeout                         time.Duration        `long:"timeout" yaml:"timeout" description:"Abort the request when it takes longer than this duration (e.g. 120s)"`
 	Output                          string               `short:"o" long:"output" description:"Output to file" default:""`
 	OutputSession                   bool                 `long:"output-session" description:"Output the entire session (also a temporary one) to the output file"`
+	OutputTemplate                  string               `long:"output-template" yaml:"outputTemplate" description:"Go template file or text for the output file, with {{.Output}}, {{.Pattern}}, {{.Title}}, {{.Model}}, {{.Vendor}}, {{.Date}} and {{.SourceURL}}"`
+	OutputDir                       string               `long:"output-dir" yaml:"outputDir" description:"Write the output to a new file in this directory, named after --output-name"`
+	OutputName                      string               `long:"output-name" yaml:"outputName" description:"File name template of --output-dir, with slugified fields (default: {{.Date}}-{{.Pattern}}-{{.Title}})"`
+	PrintPath                       bool                 `long:"print-path" description:"Print the path of the output file instead of the output"`
 	LatestPatterns                  string               `short:"n" long:"latest" description:"Number of latest patterns to list" default:"0"`
 	ChangeDefaultModel              bool                 `short:"d" long:"changeDefaultModel" description:"Change default model"`
 	YouTube                         string               `short:"y" long:"youtube" description:"YouTube video or play list \"URL\" to grab transcript, comments from it and send to chat or print it put to the console and store it in the output file"`
@@ -64,33 +122,98 @@ type Flags struct {
 	YouTubeMetadata                 bool                 `long:"metadata" description:"Output video metadata"`
 	YtDlpArgs                       string               `long:"yt-dlp-args" yaml:"ytDlpArgs" description:"Additional arguments to pass to yt-dlp (e.g. '--cookies-from-browser brave')"`
 	Spotify                         string               `long:"spotify" description:"Spotify podcast or episode URL to grab metadata from and send to chat"`
+	Readwise                        string               `long:"readwise" description:"Send the Readwise highlights of the query to chat, e.g. since:7d category:books tag:ai (default since:7d)"`
+	Raindrop                        string               `long:"raindrop" description:"Send the Raindrop.io bookmarks of the query to chat, e.g. since:7d collection:Reading tag:ai golang (default since:7d)"`
+	NotionPage                      string               `long:"notion-page" description:"Send the Notion page of this id or URL to chat as Markdown"`
+	Jira                            string               `long:"jira" description:"Send the Jira issue of this key or URL, e.g. PROJ-123, with its comments to chat"`
+	Linear                          string               `long:"linear" description:"Send the Linear issue of this identifier or URL, e.g. ENG-42, with its comments to chat"`
+	Meeting                         string               `long:"meeting" description:"Send the transcript of a meeting to chat with its speakers: a .vtt, .srt or .txt file, zoom:<meeting ID or link> (zoom: for the latest recording), or meet:<ID or link> of a Google Meet transcript in Drive"`
+	K8s                             string               `long:"k8s" description:"Send the state of this Kubernetes namespace, or all, to chat: its failing pods, warning events and recent logs, size-limited and redacted, after confirmation unless --yes"`
+	K8sContext                      string               `long:"k8s-context" description:"Use this kubeconfig context for --k8s instead of the current one"`
+	Logs                            string               `long:"logs" description:"Send the log file, optionally gzipped, to chat condensed: timestamps normalized to UTC, repeated messages counted once and the rest sampled to a size limit, errors and warnings first"`
+	Since                           string               `long:"since" description:"Only send the --logs entries of this last duration, e.g. 1h or 7d, or since this date or time"`
+	Follow                          bool                 `long:"follow" description:"Follow the file of --logs and run the chat on its new lines in batches, every --follow-interval"`
+	FollowInterval                  time.Duration        `long:"follow-interval" description:"How often --follow sends the new lines of the log" default:"30s"`
+	Scan                            []string             `long:"scan" description:"Send the report of a security tool to chat as structured Markdown: nmap XML, nuclei JSON or JSONL, or a Burp Suite XML issues export (repeatable)"`
+	RSS                             string               `long:"rss" description:"RSS or Atom feed URL; processes the latest entries one by one and writes one output file per entry (--output sets the directory)"`
+	RSSLimit                        int                  `long:"rss-limit" description:"Number of latest feed entries to process" default:"5"`
+	RSSTranscribe                   bool                 `long:"rss-transcribe" description:"Download and transcribe audio enclosures of feed entries (requires --transcribe-model)"`
 	Language                        string               `short:"g" long:"language" description:"Specify the Language Code for the chat, e.g. -g=en -g=zh" default:""`
-	ScrapeURL                       string               `short:"u" long:"scrape_url" description:"Scrape website URL to markdown using Jina AI"`
+	TranslateOutput                 string               `long:"translate-output" yaml:"translateOutput" description:"Translate the output into this language once complete, with a second request, e.g. --translate-output=fr"`
+	ScrapeURL                       string               `short:"u" long:"scrape_url" description:"Scrape website URL to markdown (uses Jina AI when configured, otherwise the built-in scraper)"`
+	ScrapeNative                    bool                 `long:"scrape-native" yaml:"scrapeNative" description:"Use the built-in scraper for --scrape_url even when Jina AI is configured"`
+	ScrapeJS                        bool                 `long:"scrape-js" yaml:"scrapeJS" description:"Render JavaScript with headless Chrome/Chromium before extracting content (built-in scraper only)"`
+	ScrapeNoSandbox                 bool                 `long:"scrape-no-sandbox" yaml:"scrapeNoSandbox" description:"Start headless Chrome without its sandbox for --scrape-js, for containers where it cannot start otherwise"`
 	ScrapeQuestion                  string               `short:"q" long:"scrape_question" description:"Search question using Jina AI"`
 	Seed                            int                  `short:"e" long:"seed" yaml:"seed" description:"Seed to be used for LMM generation"`
+	Deterministic                   bool                 `long:"deterministic" yaml:"deterministic" description:"Sample reproducibly for pipelines and tests: temperature 0, top P 1 and the --seed or a fixed seed, over the other sampling options"`
 	WipeContext                     string               `short:"w" long:"wipecontext" description:"Wipe context"`
 	WipeSession                     string               `short:"W" long:"wipesession" description:"Wipe session"`
 	PrintContext                    string               `long:"printcontext" description:"Print context"`
 	PrintSession                    string               `long:"printsession" description:"Print session"`
 	HtmlReadability                 bool                 `long:"readability" description:"Convert HTML input into a clean, readable view"`
+	MarkdownKeepLinks               bool                 `long:"md-keep-links" yaml:"mdKeepLinks" description:"Keep hyperlinks when converting HTML to Markdown (--readability, --scrape_url)"`
+	MarkdownKeepImages              bool                 `long:"md-keep-images" yaml:"mdKeepImages" description:"Keep images instead of only their alt text when converting HTML to Markdown"`
 	InputHasVars                    bool                 `long:"input-has-vars" description:"Apply variables to user input"`
 	NoVariableReplacement           bool                 `long:"no-variable-replacement" description:"Disable pattern variable replacement"`
-	DryRun                          bool                 `long:"dry-run" description:"Show what would be sent to the model without actually sending it"`
+	DryRun                          bool                 `long:"dry-run" description:"Print the request that would be sent to the model as JSON, with token counts, without sending it"`
+	Record                          string               `long:"record" description:"Record the HTTP requests to the AI vendors and their responses in a directory"`
+	Replay                          string               `long:"replay" description:"Answer the HTTP requests with the responses recorded by --record in a directory, without network"`
+	Proxy                           string               `long:"proxy" yaml:"proxy" description:"Send the HTTP requests through this proxy URL, except to the hosts of NO_PROXY (default: HTTPS_PROXY)"`
+	CACert                          string               `long:"ca-cert" yaml:"caCert" description:"Trust the certificate authorities of this PEM file besides those of the system"`
 	Serve                           bool                 `long:"serve" description:"Serve the Fabric Rest API"`
 	ServeOllama                     bool                 `long:"serveOllama" description:"Serve the Fabric Rest API with ollama endpoints"`
+	ServeSlack                      bool                 `long:"serve-slack" description:"Run a Slack bot over Socket Mode answering mentions and /fabric with patterns (needs SLACK_APP_TOKEN and SLACK_BOT_TOKEN)"`
+	ServeDiscord                    bool                 `long:"serve-discord" description:"Run a Discord bot answering mentions and direct messages with patterns (needs DISCORD_BOT_TOKEN)"`
+	ServeTelegram                   bool                 `long:"serve-telegram" description:"Run a Telegram bot answering /fabric, mentions and private messages with patterns (needs TELEGRAM_BOT_TOKEN)"`
+	ServeEmail                      bool                 `long:"serve-email" description:"Watch the configured mailbox and answer the mails matching the email rules of the config file with their patterns"`
+	Schedule                        []string             `long:"schedule" description:"Run a pattern on a source periodically, as \"<cron> <source> <pattern>\" with youtube:<channel>, rss:<feed URL>, url:<page URL>, readwise:<query> or raindrop:<query> sources (repeatable)"`
+	InputList                       string               `long:"input-list" description:"Run the chat on each URL or file path of this file, one per line, writing one output file per entry; entries with an output file are skipped when run again"`
+	Workflow                        string               `long:"workflow" description:"Run the steps of a YAML workflow file on the input, also run as fabric run workflow.yaml"`
+	WorkflowTarget                  []string             `long:"workflow-target" description:"Run this target of the --workflow file and the targets it needs, unless their inputs are unchanged (repeatable)"`
+	CSV                             string               `long:"csv" description:"Run the chat on a column of each row of this CSV or TSV file, writing the file with the outputs in another column to --output or stdout; interrupted runs resume from a checkpoint"`
+	CSVInputCol                     string               `long:"csv-input-col" description:"Column of the --csv file to run the chat on, the other columns being pattern variables"`
+	CSVOutputCol                    string               `long:"csv-output-col" description:"Column of the --csv file to write the outputs to, added when missing; rows with an output are skipped" default:"output"`
+	CSVConcurrency                  int                  `long:"csv-concurrency" description:"Number of --csv rows to run at a time" default:"4"`
+	Watch                           string               `long:"watch" description:"Run the chat on this file, then again whenever it changes; a directory is run on its changed files"`
+	Shell                           bool                 `long:"shell" description:"Suggest a shell command doing the request, run it once confirmed and send its output back with follow-up requests"`
+	TUI                             bool                 `long:"tui" description:"Browse the patterns with their README and pick the model, context and session in a terminal interface, then stream the output"`
+	StdioJSON                       bool                 `long:"stdio-json" description:"Read line-delimited JSON chat requests from stdin and write the responses as JSON lines to stdout, for notebooks and editors running fabric as a child process"`
 	ServeAddress                    string               `long:"address" description:"The address to bind the REST API" default:":8080"`
 	ServeAPIKey                     string               `long:"api-key" description:"API key used to secure server routes" default:""`
+	TLSCert                         string               `long:"tls-cert" description:"Serve HTTPS with this certificate file (PEM), reloaded when it changes"`
+	TLSKey                          string               `long:"tls-key" description:"Private key file (PEM) of --tls-cert"`
+	TLSClientCA                     string               `long:"tls-client-ca" description:"Require client certificates signed by this CA file (PEM), for mutual TLS"`
+	CORSOrigins                     []string             `long:"cors-origin" description:"Let browser frontends of this origin call the REST API, * for any (can be used multiple times)"`
+	TrustedProxies                  []string             `long:"trusted-proxy" description:"Take the client address from X-Forwarded-For when sent by this proxy address or CIDR range (can be used multiple times)"`
+	MaxConcurrent                   int                  `long:"max-concurrent" description:"Run at most this many vendor requests of the REST API at once, queueing the others fairly between clients (0 = no limit)"`
+	BasePath                        string               `long:"base-path" description:"Serve the REST API under this path, e.g. /fabric behind a reverse proxy"`
+	JobWebhooks                     []string             `long:"job-webhook" description:"Let the jobs of the REST API post their result to this webhook URL (can be used multiple times)"`
+	ServeCache                      string               `long:"serve-cache" description:"Answer identical /chat requests of the REST API with the cached response: memory, or a redis:// URL of a cache shared between servers"`
+	ServeCacheTTL                   time.Duration        `long:"serve-cache-ttl" description:"How long --serve-cache keeps the responses" default:"1h"`
+	ServeState                      string               `long:"serve-state" description:"Keep the jobs and sessions of the REST API in a Redis server (redis:// URL) or PostgreSQL database (postgres:// URL) shared by several replicas, rather than in memory and the local files. The request queue and the resumable streams stay per replica"`
 	Config                          string               `long:"config" description:"Path to YAML config file"`
+	Doctor                          bool                 `long:"doctor" description:"Check the config file, vendor keys, data directories and patterns version, suggesting fixes"`
 	Version                         bool                 `long:"version" description:"Print current version"`
 	ListExtensions                  bool                 `long:"listextensions" description:"List all registered extensions"`
 	AddExtension                    string               `long:"addextension" description:"Register a new extension from config file path"`
 	RemoveExtension                 string               `long:"rmextension" description:"Remove a registered extension by name"`
-	Strategy                        string               `long:"strategy" description:"Choose a strategy from the available strategies" default:""`
+	Strategy                        string               `long:"strategy" description:"Choose a strategy from the available strategies, combine several with +" default:""`
+	Refine                          int                  `long:"refine" description:"Critique and revise the response up to this many times, stopping once the critique scores it --refine-threshold or higher"`
+	RefineThreshold                 int                  `long:"refine-threshold" description:"Critique score out of 10 ending --refine early" default:"8"`
+	RefinePattern                   string               `long:"refine-pattern" yaml:"refinePattern" description:"Pattern critiquing the response for --refine instead of the built-in critique, ending with a Score: N/10 line"`
+	Samples                         int                  `long:"n" description:"Sample this many responses, up to 10, and combine them as --select says"`
+	Select                          string               `long:"select" yaml:"select" description:"How --n combines the responses: vote for the answer most agree on, best for the one a judge prefers, all to print every one"`
+	JudgePattern                    string               `long:"judge-pattern" yaml:"judgePattern" description:"Pattern picking the best response for --select best instead of the built-in judge, replying with its number"`
 	ListStrategies                  bool                 `long:"liststrategies" description:"List all strategies"`
 	ListVendors                     bool                 `long:"listvendors" description:"List all vendors"`
 	ShellCompleteOutput             bool                 `long:"shell-complete-list" description:"Output raw list without headers/formatting (for shell completion)"`
+	Completion                      string               `long:"completion" description:"Print the completion script of the shell: zsh, bash or fish"`
 	Search                          bool                 `long:"search" description:"Enable web search tool for supported models (Anthropic, OpenAI, Gemini, Grok)"`
 	SearchLocation                  string               `long:"search-location" description:"Set location for web search results (e.g., 'America/Los_Angeles')"`
+	ProviderOrder                   string               `long:"provider-order" yaml:"providerOrder" description:"Comma-separated upstream providers to try first (OpenRouter)"`
+	ProviderSort                    string               `long:"provider-sort" yaml:"providerSort" description:"Prefer upstream providers by price, throughput or latency (OpenRouter)"`
+	NoProviderFallbacks             bool                 `long:"no-provider-fallbacks" yaml:"noProviderFallbacks" description:"Only use the providers given by --provider-order (OpenRouter)"`
 	ImageFile                       string               `long:"image-file" description:"Save generated image to specified file path (e.g., 'output.png')"`
 	ImageSize                       string               `long:"image-size" description:"Image dimensions: 1024x1024, 1536x1024, 1024x1536, auto (default: auto)"`
 	ImageQuality                    string               `long:"image-quality" description:"Image quality: low, medium, high, auto (default: auto)"`
@@ -104,21 +227,204 @@ type Flags struct {
 	TranscribeModel                 string               `long:"transcribe-model" yaml:"transcribeModel" description:"Model to use for transcription (separate from chat model)"`
 	SplitMediaFile                  bool                 `long:"split-media-file" yaml:"splitMediaFile" description:"Split audio/video files larger than 25MB using ffmpeg"`
 	Voice                           string               `long:"voice" yaml:"voice" description:"TTS voice name for supported models (e.g., Kore, Charon, Puck)" default:"Kore"`
+	Listen                          bool                 `long:"listen" description:"Voice assistant mode: record the microphone, transcribe it, run the pattern and speak the reply"`
+	TTSModel                        string               `long:"tts-model" yaml:"ttsModel" description:"Text-to-speech model used by --listen, and by default by /v1/audio/speech of --serve (e.g., gpt-4o-mini-tts, gemini-2.5-flash-preview-tts)"`
+	Embed                           bool                 `long:"embed" description:"Output the embeddings of the input and of --embed-file files instead of chatting"`
+	EmbedModel                      string               `long:"embed-model" yaml:"embedModel" description:"Embedding model used by --embed (e.g., text-embedding-3-small, nomic-embed-text, voyage-3.5)"`
+	EmbedFiles                      []string             `long:"embed-file" description:"File to embed with --embed (can be used multiple times)"`
+	EmbedFormat                     string               `long:"embed-format" yaml:"embedFormat" description:"Output format of --embed: json, jsonl (default: json)"`
+	Rerank                          bool                 `long:"rerank" description:"Order the documents read from stdin (one per line, text or JSON) by relevance to --query"`
+	Query                           string               `long:"query" description:"Query used by --rerank"`
+	RerankModel                     string               `long:"rerank-model" yaml:"rerankModel" description:"Reranking model used by --rerank (default depends on the service: Cohere, Voyage or Jina)"`
+	RerankTop                       int                  `long:"rerank-top" description:"Only return the N most relevant documents with --rerank"`
 	ListGeminiVoices                bool                 `long:"list-gemini-voices" description:"List all available Gemini TTS voices"`
 	ListTranscriptionModels         bool                 `long:"list-transcription-models" description:"List all available transcription models"`
 	Notification                    bool                 `long:"notification" yaml:"notification" description:"Send desktop notification when command completes"`
 	NotificationCommand             string               `long:"notification-command" yaml:"notificationCommand" description:"Custom command to run for notifications (overrides built-in notifications)"`
+	Webhook                         string               `long:"webhook" yaml:"webhook" description:"POST the output and its metadata as JSON to this URL when the command completes"`
+	WebhookSecret                   string               `long:"webhook-secret" yaml:"webhookSecret" description:"Sign webhooks, including those of --serve jobs, with HMAC-SHA256 and this secret (default: $FABRIC_WEBHOOK_SECRET)"`
 	Thinking                        domain.ThinkingLevel `long:"thinking" yaml:"thinking" description:"Set reasoning/thinking level (e.g., off, low, medium, high, or numeric tokens for Anthropic or Google Gemini)"`
+	ReasoningEffort                 string               `long:"reasoning-effort" yaml:"reasoningEffort" description:"Reasoning effort for reasoning models: low, medium, high (overrides --thinking)"`
+	ThinkingBudget                  int                  `long:"thinking-budget" yaml:"thinkingBudget" description:"Thinking budget in tokens (overrides --reasoning-effort and --thinking)"`
+	ShowThink                       string               `long:"show-think" yaml:"showThink" optional:"yes" optional-value:"dim" description:"Show the model's thinking: dimmed while streaming (dim) or on stderr (stderr)"`
+	ThinkOutput                     string               `long:"think-output" yaml:"thinkOutput" description:"Save the model's thinking to a file, keeping it out of the answer"`
+	Post                            []string             `long:"post" description:"Post-process the output: fences, codeblock, s/regex/replacement/[g] or jq:expression (can be used multiple times)"`
+	Diff                            string               `long:"diff" description:"Show the diff between a file and the output instead of the output"`
+	DiffStyle                       string               `long:"diff-style" yaml:"diffStyle" description:"Style of --diff: unified, side-by-side (default: unified)"`
+	Apply                           bool                 `long:"apply" description:"Write the output to the --diff file after showing the diff, or the files of --apply-code without confirmation"`
+	ApplyCode                       string               `long:"apply-code" description:"Write the code blocks of the output annotated with a file path to this directory, after showing their diff and confirmation"`
+	RenderMermaid                   string               `long:"render-mermaid" description:"Render the Mermaid diagrams of the output to this SVG, PNG or PDF file with the Mermaid CLI (mmdc), the next ones to file-2.svg and so on"`
+	AnkiDeck                        string               `long:"anki-deck" description:"Write the question and answer pairs of the output, e.g. of to_flashcards, as an Anki deck named after this .apkg package or .csv/.txt import file"`
+	NotionAppend                    string               `long:"notion-append" description:"Append the output to the end of the Notion page of this id or URL as blocks"`
+	STIX                            string               `long:"stix" description:"Write the IOCs, ATT&CK techniques and CVEs of the output, e.g. of analyze_threat_report, to this JSON file as a STIX 2.1 bundle"`
+	MISP                            bool                 `long:"misp" description:"Push the IOCs, ATT&CK techniques and CVEs of the output to the configured MISP instance as a new event"`
+	PostComment                     bool                 `long:"post-comment" description:"Post the output as a comment of the issue of --jira or --linear"`
+	Redact                          bool                 `long:"redact" yaml:"redact" description:"Mask emails, phone numbers, API keys and credit cards before sending the input, restoring them in the response"`
+	RedactMap                       string               `long:"redact-map" description:"Save the values masked by --redact to a JSON file"`
+	Moderate                        string               `long:"moderate" yaml:"moderate" optional:"yes" optional-value:"block" description:"Moderate the input and the response: block flagged content (block) or annotate it (annotate)"`
+	ModerationProvider              string               `long:"moderation-provider" yaml:"moderationProvider" description:"Moderator used by --moderate: openai, or local for the moderationTerms of the config file (default: openai)"`
+	PreHook                         []string             `long:"pre-hook" yaml:"preHooks" description:"Run this command on the request as JSON before it is sent, using its changed JSON output if any (can be used multiple times)"`
+	PostHook                        []string             `long:"post-hook" yaml:"postHooks" description:"Run this command on the response as JSON before it is shown, using its changed JSON output if any (can be used multiple times)"`
+	MCP                             []string             `long:"mcp" yaml:"mcp" description:"Let the model call the tools of this MCP server of the mcpServers of the config file, or of all of them with all (can be used multiple times)"`
+	AllowBrowser                    bool                 `long:"allow-browser" yaml:"allowBrowser" description:"Let the model navigate, read and extract web pages with a headless browser driven by the Playwright MCP server"`
+	AllowExec                       bool                 `long:"allow-exec" yaml:"allowExec" description:"Let the model run Python and Go code in a sandbox without network to compute its answer"`
+	ExecSandbox                     string               `long:"exec-sandbox" yaml:"execSandbox" description:"Sandbox of --allow-exec: docker or firejail (default: docker when installed, else firejail)"`
+	ExecTimeout                     time.Duration        `long:"exec-timeout" yaml:"execTimeout" description:"Kill the code run by --allow-exec after this duration (default: 30s)"`
+	ExecMemory                      int                  `long:"exec-memory" yaml:"execMemory" description:"Memory limit in MB of the code run by --allow-exec (default: 512)"`
+	AllowWrite                      string               `long:"allow-write" yaml:"allowWrite" description:"Let the model save files to this workspace directory, asking before each one unless --yes is set, and recording them in its .fabric-writes.jsonl"`
+	Yes                             bool                 `long:"yes" description:"Write the files of --allow-write, or send the state of --k8s, without asking"`
 	ShowMetadata                    bool                 `long:"show-metadata" description:"Print metadata to stderr"`
+	Quiet                           bool                 `long:"quiet" yaml:"quiet" description:"Do not show the progress indicator while waiting for a response"`
+	Plain                           bool                 `long:"plain" yaml:"plain" description:"Print the output as is instead of rendering its Markdown in the terminal"`
+	AutoModel                       bool                 `long:"auto-model" yaml:"autoModel" description:"Pick the model from the autoModels preference list based on pattern hints, attachments and input size"`
 	Debug                           int                  `long:"debug" description:"Set debug level (0=off, 1=basic, 2=detailed, 3=trace, 4=wire)" default:"0"`
+	LogLevel                        string               `long:"log-level" yaml:"logLevel" description:"Log the messages from this level: debug, info, warn or error (default: info)"`
+	LogFormat                       string               `long:"log-format" yaml:"logFormat" description:"Format of the logs: text or json (default: text)"`
+	LogFile                         string               `long:"log-file" yaml:"logFile" description:"Append the logs to a file instead of stderr"`
+
+	// Settings only available in the YAML config file
+	ModelAliases         map[string]string               `yaml:"modelAliases" no-flag:"true"`
+	ModelRoutes          []ModelRoute                    `yaml:"modelRoutes" no-flag:"true"`
+	AutoModels           []string                        `yaml:"autoModels" no-flag:"true"`
+	ModelCapabilities    map[string]ai.ModelCapabilities `yaml:"modelCapabilities" no-flag:"true"`
+	ModelPricing         map[string]ai.ModelPricing      `yaml:"modelPricing" no-flag:"true"`
+	SpendLimits          map[string]float64              `yaml:"spendLimits" no-flag:"true"`
+	ModerationThresholds map[string]float64              `yaml:"moderationThresholds" no-flag:"true"`
+	ModerationTerms      map[string][]string             `yaml:"moderationTerms" no-flag:"true"`
+	PatternPost          map[string][]string             `yaml:"patternPost" no-flag:"true"`
+	ContextCmdAllow      []string                        `yaml:"contextCmdAllow" no-flag:"true"`
+	Bots                 map[string]bots.Config          `yaml:"bots" no-flag:"true"`
+	Email                email.Config                    `yaml:"email" no-flag:"true"`
+	Schedules            []ScheduledJob                  `yaml:"schedules" no-flag:"true"`
+	Presets              map[string]map[string]any       `yaml:"presets" no-flag:"true"`
+	Aliases              map[string]string               `yaml:"aliases" no-flag:"true"`
+	MCPServers           map[string]mcp.Server           `yaml:"mcpServers" no-flag:"true"`
+
+	// sourceURL is the URL of the RSS entry being processed
+	sourceURL string
+	// silent keeps the response from being printed, as for the steps of a
+	// workflow
+	silent bool
+	// vendorConfigs holds the config blocks of the vendors, by their key
+	vendorConfigs map[string]map[string]any
+	// usedFlags holds the yaml tags of the flags set on the command line,
+	// which the YAML config does not override
+	usedFlags map[string]bool
+	// servingVendor is the name of the vendor serving the model, whose
+	// maximum image size --resize-images takes
+	servingVendor string
 }
 
 // Init Initialize flags. returns a Flags struct and an error
 func Init() (ret *Flags, err error) {
 	debuglog.SetLevel(debuglog.LevelFromInt(parseDebugLevel(os.Args[1:])))
+
+	// Parse CLI flags first
+	var args []string
+	if ret, args, err = parseArgs(os.Args[1:]); err != nil {
+		return
+	}
+
+	// Check to see if a ~/.config/fabric/config.yaml config file exists (only when user didn't specify a config)
+	if ret.Config == "" {
+		// Default to ~/.config/fabric/config.yaml if no config specified
+		if defaultConfigPath, err := util.GetDefaultConfigPath(); err == nil && defaultConfigPath != "" {
+			ret.Config = defaultConfigPath
+		} else if err != nil {
+			debuglog.Debug(debuglog.Detailed, "Could not determine default config path: %v\n", err)
+		}
+	}
+
+	// If config specified, load it to apply its YAML for unused flags below
+	var yamlFlags *Flags
+	if ret.Config != "" {
+		if yamlFlags, err = loadYAMLConfig(ret.Config); err != nil {
+			if !ret.Doctor {
+				return
+			}
+			// --doctor reports the invalid config itself
+			yamlFlags, err = &Flags{}, nil
+		}
+
+	}
+
+	// A preset given as @name or an alias of the config file is expanded into
+	// flags placed before those of the command line
+	if len(args) > 0 {
+		var presets map[string]map[string]any
+		var aliases map[string]string
+		if yamlFlags != nil {
+			presets, aliases = yamlFlags.Presets, yamlFlags.Aliases
+		}
+		var expanded []string
+		invalid := "preset_invalid"
+		if isPresetArg(args[0]) {
+			expanded, err = expandPreset(os.Args[1:], args[0], presets)
+		} else if alias, ok := aliases[args[0]]; ok {
+			expanded, err = expandAlias(os.Args[1:], args, alias, time.Now())
+			invalid = "alias_invalid"
+		}
+		if err != nil {
+			return
+		}
+		if expanded != nil {
+			config, name := ret.Config, args[0]
+			if ret, args, err = parseArgs(expanded); err != nil {
+				return nil, fmt.Errorf(i18n.T(invalid), name, err)
+			}
+			ret.Config = config
+		}
+	}
+
+	// fabric run workflow.yaml is fabric --workflow workflow.yaml
+	if len(args) > 1 && args[0] == "run" && isWorkflowFile(args[1]) {
+		ret.Workflow, args = args[1], args[2:]
+	}
+
+	if ret.Pattern == "" {
+		execName := filepath.Base(os.Args[0])
+		execName = strings.TrimSuffix(execName, filepath.Ext(execName))
+		if execName != "fabric" && execName != "main" && execName != "cmd" && execName != "" {
+			ret.Pattern = execName
+			ret.usedFlags["pattern"] = true
+		}
+	}
+
+	debuglog.SetLevel(debuglog.LevelFromInt(ret.Debug))
+
+	if yamlFlags != nil {
+		ret.applyYAMLConfig(yamlFlags)
+	}
+
+	if err = ret.configureLogging(); err != nil {
+		return
+	}
+
+	// Handle stdin and messages
+	info, _ := os.Stdin.Stat()
+	pipedToStdin := (info.Mode() & os.ModeCharDevice) == 0
+
+	// Append positional arguments to the message (custom message)
+	if len(args) > 0 {
+		ret.Message = AppendMessage(ret.Message, strings.Join(args, " "))
+	}
+
+	// With --stdio-json, stdin carries the requests
+	if pipedToStdin && !ret.StdioJSON {
+		var pipedMessage string
+		if pipedMessage, err = readStdin(); err != nil {
+			return
+		}
+		ret.Message = AppendMessage(ret.Message, pipedMessage)
+	}
+	return
+}
+
+// parseArgs parses the command line arguments into flags, returning the
+// positional arguments
+func parseArgs(cliArgs []string) (ret *Flags, args []string, err error) {
 	// Track which yaml-configured flags were set on CLI
 	usedFlags := make(map[string]bool)
-	yamlArgsScan := os.Args[1:]
 
 	// Create mapping from flag names (both short and long) to yaml tag names
 	flagToYamlTag := make(map[string]string)
@@ -140,7 +446,7 @@ func Init() (ret *Flags, err error) {
 	}
 
 	// Scan args for that are provided by cli and might be in yaml
-	for _, arg := range yamlArgsScan {
+	for _, arg := range cliArgs {
 		flag := extractFlag(arg)
 
 		if flag != "" {
@@ -151,12 +457,9 @@ func Init() (ret *Flags, err error) {
 		}
 	}
 
-	// Parse CLI flags first
-	ret = &Flags{}
+	ret = &Flags{usedFlags: usedFlags}
 	parser := flags.NewParser(ret, flags.HelpFlag|flags.PassDoubleDash)
-
-	var args []string
-	if args, err = parser.Parse(); err != nil {
+	if args, err = parser.ParseArgs(cliArgs); err != nil {
 		// Check if this is a help request and handle it with our custom help
 		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
 			CustomHelpHandler(parser, os.Stdout)
@@ -164,78 +467,6 @@ func Init() (ret *Flags, err error) {
 		}
 		return
 	}
-
-	if ret.Pattern == "" {
-		execName := filepath.Base(os.Args[0])
-		execName = strings.TrimSuffix(execName, filepath.Ext(execName))
-		if execName != "fabric" && execName != "main" && execName != "cmd" && execName != "" {
-			ret.Pattern = execName
-			usedFlags["pattern"] = true
-		}
-	}
-
-	debuglog.SetLevel(debuglog.LevelFromInt(ret.Debug))
-
-	// Check to see if a ~/.config/fabric/config.yaml config file exists (only when user didn't specify a config)
-	if ret.Config == "" {
-		// Default to ~/.config/fabric/config.yaml if no config specified
-		if defaultConfigPath, err := util.GetDefaultConfigPath(); err == nil && defaultConfigPath != "" {
-			ret.Config = defaultConfigPath
-		} else if err != nil {
-			debuglog.Debug(debuglog.Detailed, "Could not determine default config path: %v\n", err)
-		}
-	}
-
-	// If config specified, load and apply YAML for unused flags
-	if ret.Config != "" {
-		var yamlFlags *Flags
-		if yamlFlags, err = loadYAMLConfig(ret.Config); err != nil {
-			return
-		}
-
-		// Apply YAML values where CLI flags weren't used
-		flagsVal := reflect.ValueOf(ret).Elem()
-		yamlVal := reflect.ValueOf(yamlFlags).Elem()
-		flagsType := flagsVal.Type()
-
-		for i := 0; i < flagsType.NumField(); i++ {
-			field := flagsType.Field(i)
-			if yamlTag := field.Tag.Get("yaml"); yamlTag != "" {
-				if !usedFlags[yamlTag] {
-					flagField := flagsVal.Field(i)
-					yamlField := yamlVal.Field(i)
-					if flagField.CanSet() {
-						if yamlField.Type() != flagField.Type() {
-							if err := assignWithConversion(flagField, yamlField); err != nil {
-								debuglog.Debug(debuglog.Detailed, "Type conversion failed for %s: %v\n", yamlTag, err)
-								continue
-							}
-						} else {
-							flagField.Set(yamlField)
-						}
-						debuglog.Debug(debuglog.Detailed, "Applied YAML value for %s: %v\n", yamlTag, yamlField.Interface())
-					}
-				}
-			}
-		}
-	}
-
-	// Handle stdin and messages
-	info, _ := os.Stdin.Stat()
-	pipedToStdin := (info.Mode() & os.ModeCharDevice) == 0
-
-	// Append positional arguments to the message (custom message)
-	if len(args) > 0 {
-		ret.Message = AppendMessage(ret.Message, strings.Join(args, " "))
-	}
-
-	if pipedToStdin {
-		var pipedMessage string
-		if pipedMessage, err = readStdin(); err != nil {
-			return
-		}
-		ret.Message = AppendMessage(ret.Message, pipedMessage)
-	}
 	return
 }
 
@@ -323,12 +554,47 @@ func loadYAMLConfig(configPath string) (*Flags, error) {
 	if err := yaml.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf(i18n.T("error_parsing_config_file"), err)
 	}
+	var blocks map[string]any
+	if err := yaml.Unmarshal(data, &blocks); err != nil {
+		return nil, fmt.Errorf(i18n.T("error_parsing_config_file"), err)
+	}
+	config.vendorConfigs = vendorConfigs(blocks)
 
 	debuglog.Debug(debuglog.Detailed, "Config: %v\n", config)
 
 	return config, nil
 }
 
+// applyYAMLConfig sets the values of the YAML config where no flag was set on
+// the command line
+func (o *Flags) applyYAMLConfig(yamlFlags *Flags) {
+	o.vendorConfigs = yamlFlags.vendorConfigs
+	flagsVal := reflect.ValueOf(o).Elem()
+	yamlVal := reflect.ValueOf(yamlFlags).Elem()
+	flagsType := flagsVal.Type()
+
+	for i := 0; i < flagsType.NumField(); i++ {
+		field := flagsType.Field(i)
+		if yamlTag := field.Tag.Get("yaml"); yamlTag != "" {
+			if !o.usedFlags[yamlTag] {
+				flagField := flagsVal.Field(i)
+				yamlField := yamlVal.Field(i)
+				if flagField.CanSet() {
+					if yamlField.Type() != flagField.Type() {
+						if err := assignWithConversion(flagField, yamlField); err != nil {
+							debuglog.Debug(debuglog.Detailed, "Type conversion failed for %s: %v\n", yamlTag, err)
+							continue
+						}
+					} else {
+						flagField.Set(yamlField)
+					}
+					debuglog.Debug(debuglog.Detailed, "Applied YAML value for %s: %v\n", yamlTag, yamlField.Interface())
+				}
+			}
+		}
+	}
+}
+
 // readStdin reads from stdin and returns the input as a string or an error
 func readStdin() (ret string, err error) {
 	reader := bufio.NewReader(os.Stdin)
@@ -442,6 +708,78 @@ func (o *Flags) BuildChatOptions() (ret *domain.ChatOptions, err error) {
 		return nil, err
 	}
 
+	switch o.Truncate {
+	case "", domain.TruncateHead, domain.TruncateTail, domain.TruncateMiddle:
+	default:
+		return nil, fmt.Errorf(i18n.T("invalid_truncate_mode"), o.Truncate)
+	}
+
+	switch o.ProviderSort {
+	case "", "price", "throughput", "latency":
+	default:
+		return nil, fmt.Errorf(i18n.T("invalid_provider_sort"), o.ProviderSort)
+	}
+	var providerOrder []string
+	for _, provider := range strings.Split(o.ProviderOrder, ",") {
+		if provider = strings.TrimSpace(provider); provider != "" {
+			providerOrder = append(providerOrder, provider)
+		}
+	}
+
+	thinking := o.Thinking
+	switch o.ReasoningEffort {
+	case "":
+	case string(domain.ThinkingLow), string(domain.ThinkingMedium), string(domain.ThinkingHigh):
+		thinking = domain.ThinkingLevel(o.ReasoningEffort)
+	default:
+		return nil, fmt.Errorf(i18n.T("invalid_reasoning_effort"), o.ReasoningEffort)
+	}
+	var modelParams map[string]any
+	if len(o.ModelParam) > 0 {
+		if modelParams, err = plugins.ParseBodyParams(o.ModelParam); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.TopLogprobs < 0 || o.TopLogprobs > maxTopLogprobs {
+		return nil, fmt.Errorf(i18n.T("invalid_top_logprobs"), o.TopLogprobs, maxTopLogprobs)
+	}
+
+	if o.Mirostat < 0 || o.Mirostat > 2 {
+		return nil, fmt.Errorf(i18n.T("invalid_mirostat"), o.Mirostat)
+	}
+	if o.NumGPU != nil && *o.NumGPU < 0 {
+		return nil, fmt.Errorf(i18n.T("invalid_negative_count"), "num-gpu", *o.NumGPU)
+	}
+	if o.NumThread < 0 {
+		return nil, fmt.Errorf(i18n.T("invalid_negative_count"), "num-thread", o.NumThread)
+	}
+	if o.NumBatch < 0 {
+		return nil, fmt.Errorf(i18n.T("invalid_negative_count"), "num-batch", o.NumBatch)
+	}
+	if o.MaxOutputTokens < 0 {
+		return nil, fmt.Errorf(i18n.T("invalid_negative_count"), "max-output-tokens", o.MaxOutputTokens)
+	}
+	var keepAlive *time.Duration
+	if o.KeepAlive != "" {
+		if keepAlive, err = parseKeepAlive(o.KeepAlive); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.ThinkingBudget < 0 {
+		return nil, fmt.Errorf(i18n.T("invalid_thinking_budget"), o.ThinkingBudget)
+	}
+	if o.ThinkingBudget > 0 {
+		thinking = domain.ThinkingLevel(strconv.Itoa(o.ThinkingBudget))
+	}
+
+	switch o.ShowThink {
+	case "", domain.ShowThinkDim, domain.ShowThinkStderr:
+	default:
+		return nil, fmt.Errorf(i18n.T("invalid_show_think"), o.ShowThink)
+	}
+
 	startTag := o.ThinkStartTag
 	if startTag == "" {
 		startTag = "<think>"
@@ -459,10 +797,20 @@ func (o *Flags) BuildChatOptions() (ret *domain.ChatOptions, err error) {
 		FrequencyPenalty:    o.FrequencyPenalty,
 		Raw:                 o.Raw,
 		Seed:                o.Seed,
-		Thinking:            o.Thinking,
+		Thinking:            thinking,
 		ModelContextLength:  o.ModelContextLength,
+		MaxTokens:           o.MaxOutputTokens,
+		KeepAlive:           keepAlive,
+		NumGPU:              o.NumGPU,
+		NumThread:           o.NumThread,
+		NumBatch:            o.NumBatch,
+		Mirostat:            o.Mirostat,
+		Truncate:            o.Truncate,
 		Search:              o.Search,
 		SearchLocation:      o.SearchLocation,
+		ProviderOrder:       providerOrder,
+		ProviderSort:        o.ProviderSort,
+		NoProviderFallbacks: o.NoProviderFallbacks,
 		ImageFile:           o.ImageFile,
 		ImageSize:           o.ImageSize,
 		ImageQuality:        o.ImageQuality,
@@ -471,24 +819,61 @@ func (o *Flags) BuildChatOptions() (ret *domain.ChatOptions, err error) {
 		SuppressThink:       o.SuppressThink,
 		ThinkStartTag:       startTag,
 		ThinkEndTag:         endTag,
+		ShowThink:           o.ShowThink,
+		ThinkOutput:         o.ThinkOutput,
 		Voice:               o.Voice,
 		Notification:        o.Notification || o.NotificationCommand != "",
 		NotificationCommand: o.NotificationCommand,
 		ShowMetadata:        o.ShowMetadata,
+		RenderMarkdown:      o.renderMarkdown(),
+		Logprobs:            o.Logprobs || o.TopLogprobs > 0,
+		TopLogprobs:         o.TopLogprobs,
+		ModelParams:         modelParams,
+	}
+	if o.Deterministic {
+		ret.SetDeterministic()
 	}
 	return
 }
 
+// renderMarkdown reports whether the output is rendered as Markdown, which is
+// the case when printing to a terminal unless --plain or NO_COLOR is set.
+func (o *Flags) renderMarkdown() bool {
+	return !o.Plain && os.Getenv("NO_COLOR") == "" && mdrender.IsTerminal(os.Stdout)
+}
+
 func (o *Flags) BuildChatRequest(Meta string) (ret *domain.ChatRequest, err error) {
 	ret = &domain.ChatRequest{
-		ContextName:           o.Context,
+		ContextName:           strings.Join(o.Context, ","),
+		ContextVariables:      o.ContextVariables,
 		SessionName:           o.Session,
+		SessionTitle:          o.SessionTitle,
+		SessionTags:           splitTags(o.SessionTags),
 		PatternName:           o.Pattern,
 		StrategyName:          o.Strategy,
+		Refine:                o.Refine,
+		RefineThreshold:       o.RefineThreshold,
+		RefinePattern:         o.RefinePattern,
+		Samples:               o.Samples,
+		Select:                o.Select,
+		JudgePattern:          o.JudgePattern,
 		PatternVariables:      o.PatternVariables,
 		InputHasVars:          o.InputHasVars,
 		NoVariableReplacement: o.NoVariableReplacement,
 		Meta:                  Meta,
+		Resume:                o.Resume,
+	}
+	if o.Samples < 0 || o.Samples > strategy.MaxSamples {
+		return nil, fmt.Errorf(i18n.T("invalid_samples"), o.Samples, strategy.MaxSamples)
+	}
+	if o.Select != "" && !strategy.IsAggregate(o.Select) {
+		return nil, fmt.Errorf(i18n.T("invalid_select"), o.Select)
+	}
+	if o.Resume {
+		if ret.SessionName == "" {
+			ret.SessionName = fsdb.InterruptedSessionName
+		}
+		return
 	}
 
 	var message *chat.ChatCompletionMessage
@@ -509,6 +894,9 @@ func (o *Flags) BuildChatRequest(Meta string) (ret *domain.ChatRequest, err erro
 			if attachment, err = domain.NewAttachment(attachmentValue); err != nil {
 				return
 			}
+			if err = o.preprocessImageAttachment(attachment); err != nil {
+				return
+			}
 			url := attachment.URL
 			if url == nil {
 				var base64Image string
@@ -543,6 +931,49 @@ func (o *Flags) BuildChatRequest(Meta string) (ret *domain.ChatRequest, err erro
 			ret.Language = langTag.String()
 		}
 	}
+	if o.TranslateOutput != "" {
+		var langTag language.Tag
+		if langTag, err = language.Parse(o.TranslateOutput); err != nil {
+			return nil, fmt.Errorf(i18n.T("translate_output_invalid_language"), o.TranslateOutput)
+		}
+		ret.TranslateOutput = langTag.String()
+	}
+	return
+}
+
+// preprocessImageAttachment resizes, strips metadata from, or converts (HEIC) an image
+// attachment in place, inlining its processed content. Without --image-max-dim,
+// --resize-images, --strip-exif or --convert-heic, and for non-image attachments,
+// the attachment is left untouched.
+func (o *Flags) preprocessImageAttachment(attachment *domain.Attachment) (err error) {
+	opts := imageproc.Options{MaxDim: o.ImageMaxDim, StripEXIF: o.StripEXIF, ConvertHEIC: o.ConvertHEIC}
+	if opts.MaxDim == 0 && o.ResizeImages {
+		opts.MaxDim = ai.LookupImageMaxDim(o.servingVendor)
+	}
+	if !opts.Enabled() {
+		return
+	}
+
+	var mimeType string
+	if mimeType, err = attachment.ResolveType(); err != nil {
+		return
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return
+	}
+
+	var content []byte
+	if content, err = attachment.ContentBytes(); err != nil {
+		return
+	}
+	if content, mimeType, err = imageproc.Process(content, mimeType, opts); err != nil {
+		return
+	}
+
+	attachment.Content = content
+	attachment.Type = &mimeType
+	attachment.Path = nil
+	attachment.URL = nil
 	return
 }
 
@@ -550,15 +981,72 @@ func (o *Flags) AppendMessage(message string) {
 	o.Message = AppendMessage(o.Message, message)
 }
 
+// MarkdownOptions returns the HTML-to-Markdown conversion options selected by the flags.
+func (o *Flags) MarkdownOptions() converter.MarkdownOptions {
+	return converter.MarkdownOptions{
+		KeepLinks:  o.MarkdownKeepLinks,
+		KeepImages: o.MarkdownKeepImages,
+	}
+}
+
 func (o *Flags) IsChatRequest() (ret bool) {
-	ret = o.Message != "" || len(o.Attachments) > 0 || o.Context != "" || o.Session != "" || o.Pattern != ""
+	ret = o.Message != "" || len(o.Attachments) > 0 || len(o.Context) > 0 || len(o.ContextCmd) > 0 || o.Session != "" || o.Pattern != "" || o.Resume
+	return
+}
+
+// ServeOptions returns the options of the REST API server
+func (o *Flags) ServeOptions() (ret *restapi.ServeOptions, err error) {
+	ret = &restapi.ServeOptions{
+		Address:        o.ServeAddress,
+		APIKey:         o.ServeAPIKey,
+		TLS:            &restapi.TLSOptions{CertFile: o.TLSCert, KeyFile: o.TLSKey, ClientCAFile: o.TLSClientCA},
+		CORSOrigins:    o.CORSOrigins,
+		TrustedProxies: o.TrustedProxies,
+		MaxConcurrent:  o.MaxConcurrent,
+		WebhookSecret:  o.webhookSecret(),
+		BasePath:       o.BasePath,
+		JobWebhooks:    o.JobWebhooks,
+		TTSModel:       o.TTSModel,
+		Voice:          o.Voice,
+	}
+	if o.ServeCache != "" {
+		if o.ServeCacheTTL <= 0 {
+			return nil, errors.New(i18n.T("server_cache_invalid_ttl"))
+		}
+		var cache restapi.ResponseCache
+		if cache, err = restapi.NewResponseCache(o.ServeCache); err != nil {
+			return nil, err
+		}
+		ret.Cache = &restapi.ChatCache{Cache: cache, TTL: o.ServeCacheTTL}
+	}
+	if o.ServeState != "" {
+		if ret.State, err = restapi.NewServerState(o.ServeState); err != nil {
+			return nil, err
+		}
+	}
 	return
 }
 
+// IsUnattendedSetup tells whether a --setup-* flag asks to set up fabric
+// without asking questions.
+func (o *Flags) IsUnattendedSetup() bool {
+	return o.SetupVendor != "" || o.SetupKey != "" || o.SetupURL != "" || len(o.SetupSettings) > 0 || o.SetupDefaultModel != ""
+}
+
 func (o *Flags) WriteOutput(message string) (err error) {
-	fmt.Println(message)
-	if o.Output != "" {
-		err = CreateOutputFile(message, o.Output)
+	outputFile := o.Output
+	if outputFile == "" && o.OutputDir != "" {
+		if outputFile, err = o.outputDirPath(newOutputTemplateData(o, "", "", message)); err != nil {
+			return
+		}
+	}
+	if !o.PrintPath {
+		fmt.Println(message)
+	}
+	if outputFile != "" {
+		if err = CreateOutputFile(message, outputFile); err == nil && o.PrintPath {
+			fmt.Println(outputFile)
+		}
 	}
 	return
 }
@@ -571,3 +1059,12 @@ func AppendMessage(message string, newMessage string) (ret string) {
 	}
 	return
 }
+
+// postProcessors returns the --post processors, or those configured for the
+// pattern in patternPost when none is given.
+func (o *Flags) postProcessors() []string {
+	if len(o.Post) > 0 || o.Pattern == "" {
+		return o.Post
+	}
+	return o.PatternPost[o.Pattern]
+}
@@ -2,11 +2,14 @@ package cli
 
 import (
 	"bytes"
+	"image"
+	"image/png"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/stretchr/testify/assert"
@@ -99,6 +102,78 @@ func TestBuildChatOptionsDefaultSeed(t *testing.T) {
 	assert.Equal(t, expectedOptions, options)
 }
 
+func TestBuildChatOptionsDeterministic(t *testing.T) {
+	flags := &Flags{Temperature: 0.8, TopP: 0.9, PresencePenalty: 0.1, Raw: true, Deterministic: true}
+	options, err := flags.BuildChatOptions()
+	assert.NoError(t, err)
+	assert.True(t, options.Deterministic)
+	assert.Equal(t, 0.0, options.Temperature)
+	assert.Equal(t, 1.0, options.TopP)
+	assert.Equal(t, 0.0, options.PresencePenalty)
+	assert.Equal(t, domain.DeterministicSeed, options.Seed)
+	assert.False(t, options.Raw)
+
+	flags.Seed = 7
+	options, err = flags.BuildChatOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, 7, options.Seed, "the --seed is kept")
+}
+
+func TestBuildChatOptionsOllamaRuntime(t *testing.T) {
+	cpuOnly := 0
+	flags := &Flags{KeepAlive: "-1", NumGPU: &cpuOnly, NumThread: 8, Mirostat: 2}
+	options, err := flags.BuildChatOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, -time.Second, *options.KeepAlive)
+	assert.Equal(t, 0, *options.NumGPU)
+	assert.Equal(t, 8, options.NumThread)
+	assert.Equal(t, 2, options.Mirostat)
+
+	for _, invalid := range []*Flags{{KeepAlive: "soon"}, {Mirostat: 3}, {NumBatch: -1}} {
+		_, err = invalid.BuildChatOptions()
+		assert.Error(t, err)
+	}
+}
+
+func TestBuildChatOptionsReasoning(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   Flags
+		want    domain.ThinkingLevel
+		wantErr bool
+	}{
+		{name: "thinking", flags: Flags{Thinking: domain.ThinkingLow}, want: domain.ThinkingLow},
+		{name: "effort overrides thinking", flags: Flags{Thinking: domain.ThinkingLow, ReasoningEffort: "high"}, want: domain.ThinkingHigh},
+		{name: "budget overrides effort", flags: Flags{ReasoningEffort: "high", ThinkingBudget: 8000}, want: domain.ThinkingLevel("8000")},
+		{name: "invalid effort", flags: Flags{ReasoningEffort: "extreme"}, wantErr: true},
+		{name: "negative budget", flags: Flags{ThinkingBudget: -1}, wantErr: true},
+		{name: "invalid show-think", flags: Flags{ShowThink: "bright"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := tt.flags.BuildChatOptions()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, options.Thinking)
+		})
+	}
+}
+
+func TestBuildChatOptionsModelParams(t *testing.T) {
+	flags := &Flags{ModelParam: []string{"top_k=40", "response_format.type=json_object"}}
+	options, err := flags.BuildChatOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"top_k": float64(40), "response_format.type": "json_object"}, options.ModelParams)
+
+	flags.ModelParam = []string{"top_k"}
+	_, err = flags.BuildChatOptions()
+	assert.Error(t, err)
+}
+
 func TestBuildChatOptionsSuppressThink(t *testing.T) {
 	flags := &Flags{
 		SuppressThink: true,
@@ -120,6 +195,7 @@ temperature: 0.9
 model: gpt-4
 pattern: analyze
 stream: true
+timeout: 2m
 `
 	tmpfile, err := os.CreateTemp("", "config.*.yaml")
 	if err != nil {
@@ -146,6 +222,7 @@ stream: true
 		assert.Equal(t, "gpt-4", flags.Model)
 		assert.Equal(t, "analyze", flags.Pattern)
 		assert.True(t, flags.Stream)
+		assert.Equal(t, 2*time.Minute, flags.Timeout)
 	})
 
 	// Test 2: CLI overrides YAML
@@ -482,3 +559,81 @@ func TestExtractFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestPostProcessors(t *testing.T) {
+	patternPost := map[string][]string{"create_command": {"codeblock"}}
+
+	flags := &Flags{Pattern: "create_command", PatternPost: patternPost}
+	assert.Equal(t, []string{"codeblock"}, flags.postProcessors())
+
+	flags.Post = []string{"fences"}
+	assert.Equal(t, []string{"fences"}, flags.postProcessors())
+
+	flags = &Flags{Pattern: "summarize", PatternPost: patternPost}
+	assert.Empty(t, flags.postProcessors())
+}
+
+func TestBuildChatRequestContexts(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	os.Args = []string{"cmd", "-C", "persona", "-C", "project,task", "--context-var", "project:fabric"}
+
+	flags, err := Init()
+	assert.NoError(t, err)
+
+	request, err := flags.BuildChatRequest("")
+	assert.NoError(t, err)
+	assert.Equal(t, "persona,project,task", request.ContextName)
+	assert.Equal(t, map[string]string{"project": "fabric"}, request.ContextVariables)
+}
+
+func TestBuildChatRequestTranslateOutput(t *testing.T) {
+	request, err := (&Flags{TranslateOutput: "pt-br", Language: "fr"}).BuildChatRequest("")
+	assert.NoError(t, err)
+	assert.Equal(t, "pt-BR", request.TranslateOutput)
+	assert.Equal(t, "fr", request.Language)
+
+	_, err = (&Flags{TranslateOutput: "not a language"}).BuildChatRequest("")
+	assert.ErrorContains(t, err, `"not a language"`)
+}
+
+func TestBuildChatRequestSamples(t *testing.T) {
+	request, err := (&Flags{Samples: 5, Select: "best", JudgePattern: "judge"}).BuildChatRequest("")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, request.Samples)
+	assert.Equal(t, "best", request.Select)
+	assert.Equal(t, "judge", request.JudgePattern)
+
+	_, err = (&Flags{Samples: 11}).BuildChatRequest("")
+	assert.ErrorContains(t, err, "11")
+
+	_, err = (&Flags{Samples: 3, Select: "first"}).BuildChatRequest("")
+	assert.ErrorContains(t, err, `"first"`)
+}
+
+func TestPreprocessImageAttachment(t *testing.T) {
+	var data bytes.Buffer
+	assert.NoError(t, png.Encode(&data, image.NewNRGBA(image.Rect(0, 0, 2000, 1000))))
+	path := filepath.Join(t.TempDir(), "photo.png")
+	assert.NoError(t, os.WriteFile(path, data.Bytes(), 0o644))
+
+	// Without an option, the attachment is not even read
+	missing := filepath.Join(t.TempDir(), "missing.png")
+	attachment := &domain.Attachment{Path: &missing}
+	assert.NoError(t, (&Flags{}).preprocessImageAttachment(attachment))
+	assert.Equal(t, &missing, attachment.Path)
+
+	attachment = &domain.Attachment{Path: &path}
+	flags := &Flags{ResizeImages: true, servingVendor: "Anthropic"}
+	assert.NoError(t, flags.preprocessImageAttachment(attachment))
+	config, err := png.DecodeConfig(bytes.NewReader(attachment.Content))
+	assert.NoError(t, err)
+	assert.Equal(t, 1568, config.Width)
+
+	attachment = &domain.Attachment{Path: &path}
+	flags.ImageMaxDim = 500
+	assert.NoError(t, flags.preprocessImageAttachment(attachment))
+	config, err = png.DecodeConfig(bytes.NewReader(attachment.Content))
+	assert.NoError(t, err)
+	assert.Equal(t, 500, config.Width)
+}
@@ -17,9 +17,34 @@ var flagDescriptionMap = map[string]string{
 	"pattern":                    "choose_pattern_from_available",
 	"variable":                   "pattern_variables_help",
 	"context":                    "choose_context_from_available",
+	"context-var":                "context_variables_help",
+	"context-cmd":                "context_cmd_help",
 	"session":                    "choose_session_from_available",
+	"resume":                     "resume_help",
+	"session-max-messages":       "session_max_messages_help",
+	"session-max-tokens":         "session_max_tokens_help",
+	"session-ttl":                "session_ttl_help",
+	"session-summarize":          "session_summarize_help",
+	"session-title":              "session_title_help",
+	"session-tags":               "session_tags_help",
+	"session-sort":               "session_sort_help",
+	"session-search":             "session_search_help",
+	"search-sessions":            "search_sessions_help",
 	"attachment":                 "attachment_path_or_url_help",
+	"doc":                        "doc_help",
+	"ocr":                        "ocr_help",
+	"ocr-lang":                   "ocr_lang_help",
+	"ocr-model":                  "ocr_model_help",
+	"image-max-dim":              "image_max_dim_help",
+	"resize-images":              "resize_images_help",
+	"strip-exif":                 "strip_exif_help",
+	"convert-heic":               "convert_heic_help",
 	"setup":                      "run_setup_for_reconfigurable_parts",
+	"setup-vendor":               "setup_vendor_help",
+	"setup-key":                  "setup_key_help",
+	"setup-url":                  "setup_url_help",
+	"setup-set":                  "setup_set_help",
+	"setup-default-model":        "setup_default_model_help",
 	"temperature":                "set_temperature",
 	"topp":                       "set_top_p",
 	"stream":                     "stream_help",
@@ -27,16 +52,32 @@ var flagDescriptionMap = map[string]string{
 	"raw":                        "use_model_defaults_raw_help",
 	"frequencypenalty":           "set_frequency_penalty",
 	"listpatterns":               "list_all_patterns",
+	"tags":                       "tags_help",
+	"search-patterns":            "search_patterns_help",
+	"json":                       "json_help",
 	"listmodels":                 "list_all_available_models",
 	"listcontexts":               "list_all_contexts",
 	"listsessions":               "list_all_sessions",
+	"spend":                      "spend_help",
 	"updatepatterns":             "update_patterns",
+	"sync":                       "sync_help",
 	"copy":                       "copy_to_clipboard",
 	"model":                      "choose_model",
 	"vendor":                     "specify_vendor_for_model",
 	"modelContextLength":         "model_context_length_ollama",
+	"max-output-tokens":          "max_output_tokens_help",
+	"max-cost":                   "max_cost_help",
+	"keep-alive":                 "keep_alive_help",
+	"num-gpu":                    "num_gpu_help",
+	"num-thread":                 "num_thread_help",
+	"num-batch":                  "num_batch_help",
+	"mirostat":                   "mirostat_help",
 	"output":                     "output_to_file",
 	"output-session":             "output_entire_session",
+	"output-template":            "output_template_help",
+	"output-dir":                 "output_dir_help",
+	"output-name":                "output_name_help",
+	"print-path":                 "print_path_help",
 	"latest":                     "number_of_latest_patterns",
 	"changeDefaultModel":         "change_default_model",
 	"youtube":                    "youtube_url_help",
@@ -49,28 +90,94 @@ var flagDescriptionMap = map[string]string{
 	"comments":                   "grab_comments_from_youtube",
 	"metadata":                   "output_video_metadata",
 	"yt-dlp-args":                "additional_yt_dlp_args",
+	"rss":                        "rss_feed_url_help",
+	"rss-limit":                  "rss_limit_help",
+	"rss-transcribe":             "rss_transcribe_help",
+	"readwise":                   "readwise_help",
+	"raindrop":                   "raindrop_help",
+	"notion-page":                "notion_page_help",
+	"jira":                       "jira_help",
+	"linear":                     "linear_help",
+	"meeting":                    "meeting_help",
+	"k8s":                        "k8s_help",
+	"k8s-context":                "k8s_context_help",
+	"logs":                       "logs_help",
+	"since":                      "since_help",
+	"follow":                     "follow_help",
+	"follow-interval":            "follow_interval_help",
+	"scan":                       "scan_help",
+	"listen":                     "listen_help",
+	"auto-model":                 "auto_model_help",
+	"truncate":                   "truncate_help",
+	"timeout":                    "timeout_help",
+	"tts-model":                  "tts_model_help",
 	"language":                   "specify_language_code",
+	"translate-output":           "translate_output_help",
 	"scrape_url":                 "scrape_website_url",
+	"scrape-native":              "scrape_native_help",
+	"scrape-js":                  "scrape_js_help",
+	"scrape-no-sandbox":          "scrape_no_sandbox_help",
 	"scrape_question":            "search_question_jina",
 	"seed":                       "seed_for_lmm_generation",
+	"deterministic":              "deterministic_help",
 	"wipecontext":                "wipe_context",
 	"wipesession":                "wipe_session",
 	"printcontext":               "print_context",
 	"printsession":               "print_session",
 	"readability":                "convert_html_readability",
+	"md-keep-links":              "md_keep_links_help",
+	"md-keep-images":             "md_keep_images_help",
 	"input-has-vars":             "apply_variables_to_input",
 	"no-variable-replacement":    "disable_pattern_variable_replacement",
 	"dry-run":                    "show_dry_run",
+	"record":                     "record_help",
+	"replay":                     "replay_help",
+	"proxy":                      "proxy_help",
+	"ca-cert":                    "ca_cert_help",
 	"serve":                      "serve_fabric_rest_api",
 	"serveOllama":                "serve_fabric_api_ollama_endpoints",
+	"serve-slack":                "serve_slack_help",
+	"serve-discord":              "serve_discord_help",
+	"serve-telegram":             "serve_telegram_help",
+	"serve-email":                "serve_email_help",
+	"schedule":                   "schedule_help",
+	"watch":                      "watch_help",
+	"input-list":                 "input_list_help",
+	"workflow":                   "workflow_help",
+	"workflow-target":            "workflow_target_help",
+	"csv":                        "csv_help",
+	"csv-input-col":              "csv_input_col_help",
+	"csv-output-col":             "csv_output_col_help",
+	"csv-concurrency":            "csv_concurrency_help",
+	"shell":                      "shell_help",
+	"tui":                        "tui_help",
+	"stdio-json":                 "stdio_json_help",
 	"address":                    "address_to_bind_rest_api",
 	"api-key":                    "api_key_secure_server_routes",
+	"tls-cert":                   "tls_cert_help",
+	"tls-key":                    "tls_key_help",
+	"tls-client-ca":              "tls_client_ca_help",
+	"cors-origin":                "cors_origin_help",
+	"trusted-proxy":              "trusted_proxy_help",
+	"max-concurrent":             "max_concurrent_help",
+	"serve-cache":                "serve_cache_help",
+	"serve-cache-ttl":            "serve_cache_ttl_help",
+	"serve-state":                "serve_state_help",
+	"base-path":                  "base_path_help",
+	"job-webhook":                "job_webhook_help",
 	"config":                     "path_to_yaml_config",
+	"doctor":                     "doctor_help",
 	"version":                    "print_current_version",
 	"listextensions":             "list_all_registered_extensions",
 	"addextension":               "register_new_extension",
 	"rmextension":                "remove_registered_extension",
 	"strategy":                   "choose_strategy_from_available",
+	"refine":                     "refine_help",
+	"refine-threshold":           "refine_threshold_help",
+	"refine-pattern":             "refine_pattern_help",
+	"n":                          "samples_help",
+	"select":                     "select_help",
+	"judge-pattern":              "judge_pattern_help",
 	"liststrategies":             "list_all_strategies",
 	"listvendors":                "list_all_vendors",
 	"shell-complete-list":        "output_raw_list_shell_completion",
@@ -93,8 +200,59 @@ var flagDescriptionMap = map[string]string{
 	"list-transcription-models":  "list_transcription_models",
 	"notification":               "send_desktop_notification",
 	"notification-command":       "custom_notification_command",
+	"webhook":                    "webhook_help",
+	"webhook-secret":             "webhook_secret_help",
 	"thinking":                   "set_reasoning_thinking_level",
+	"reasoning-effort":           "reasoning_effort_help",
+	"thinking-budget":            "thinking_budget_help",
+	"show-think":                 "show_think_help",
+	"think-output":               "think_output_help",
+	"provider-order":             "provider_order_help",
+	"provider-sort":              "provider_sort_help",
+	"no-provider-fallbacks":      "no_provider_fallbacks_help",
+	"embed":                      "embed_help",
+	"embed-model":                "embed_model_help",
+	"embed-file":                 "embed_file_help",
+	"embed-format":               "embed_format_help",
+	"rerank":                     "rerank_help",
+	"query":                      "rerank_query_help",
+	"rerank-model":               "rerank_model_help",
+	"rerank-top":                 "rerank_top_help",
+	"post":                       "post_help",
+	"diff":                       "diff_help",
+	"diff-style":                 "diff_style_help",
+	"apply":                      "apply_help",
+	"apply-code":                 "apply_code_help",
+	"render-mermaid":             "render_mermaid_help",
+	"anki-deck":                  "anki_deck_help",
+	"notion-append":              "notion_append_help",
+	"post-comment":               "post_comment_help",
+	"stix":                       "stix_help",
+	"misp":                       "misp_help",
+	"completion":                 "completion_help",
+	"redact":                     "redact_help",
+	"redact-map":                 "redact_map_help",
+	"moderate":                   "moderate_help",
+	"moderation-provider":        "moderation_provider_help",
+	"pre-hook":                   "pre_hook_help",
+	"model-param":                "model_param_help",
+	"logprobs":                   "logprobs_help",
+	"top-logprobs":               "top_logprobs_help",
+	"post-hook":                  "post_hook_help",
+	"mcp":                        "mcp_help",
+	"allow-browser":              "allow_browser_help",
+	"allow-exec":                 "allow_exec_help",
+	"exec-sandbox":               "exec_sandbox_help",
+	"exec-timeout":               "exec_timeout_help",
+	"exec-memory":                "exec_memory_help",
+	"allow-write":                "allow_write_help",
+	"yes":                        "yes_help",
+	"quiet":                      "quiet_help",
+	"plain":                      "plain_help",
 	"debug":                      "set_debug_level",
+	"log-level":                  "log_level_help",
+	"log-format":                 "log_format_help",
+	"log-file":                   "log_file_help",
 }
 
 // TranslatedHelpWriter provides custom help output with translated descriptions
@@ -260,11 +418,16 @@ func (h *TranslatedHelpWriter) writeAllFlags() {
 			longTag == "transcript-with-timestamps" || longTag == "comments" ||
 			longTag == "metadata" || longTag == "readability" ||
 			longTag == "input-has-vars" || longTag == "no-variable-replacement" ||
-			longTag == "dry-run" || longTag == "serve" || longTag == "serveOllama" ||
+			longTag == "dry-run" || longTag == "serve" || longTag == "serveOllama" || longTag == "serve-slack" ||
+			longTag == "serve-discord" || longTag == "serve-telegram" || longTag == "serve-email" ||
 			longTag == "version" || longTag == "shell-complete-list" ||
 			longTag == "search" || longTag == "suppress-think" ||
 			longTag == "disable-responses-api" || longTag == "split-media-file" ||
-			longTag == "notification"
+			longTag == "notification" || longTag == "rss-transcribe" ||
+			longTag == "scrape-native" || longTag == "scrape-js" || longTag == "scrape-no-sandbox" ||
+			longTag == "md-keep-links" || longTag == "md-keep-images" ||
+			longTag == "strip-exif" || longTag == "resize-images" || longTag == "convert-heic" || longTag == "listen" || longTag == "stdio-json" ||
+			longTag == "auto-model" || longTag == "print-path" || longTag == "plain" || longTag == "quiet" || longTag == "resume" || longTag == "session-summarize"
 
 		if !isBoolFlag {
 			flagLine.WriteString("=")
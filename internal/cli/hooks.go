@@ -0,0 +1,15 @@
+package cli
+
+import (
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/tools/hooks"
+)
+
+// configureHooks sets up the hooks of every chat, including those served by
+// --serve, when --pre-hook or --post-hook is given.
+func configureHooks(flags *Flags, registry *core.PluginRegistry) {
+	if len(flags.PreHook) == 0 && len(flags.PostHook) == 0 {
+		return
+	}
+	registry.Hooks = &hooks.Hooks{Pre: flags.PreHook, Post: flags.PostHook}
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/tools/converter"
+)
+
+// handleInputList runs the chat on each entry of --input-list, a URL or a file
+// path per line, writing one output file per entry like --rss does. Entries
+// whose output file exists are skipped, and a failed entry does not stop the
+// others, so that running the list again resumes it.
+func handleInputList(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	var entries []string
+	if entries, err = readInputList(currentFlags.InputList); err != nil {
+		return
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf(i18n.T("input_list_empty"), currentFlags.InputList)
+	}
+
+	outputDir := cmp.Or(currentFlags.OutputDir, currentFlags.Output)
+	if outputDir != "" {
+		if err = os.MkdirAll(outputDir, ConfigDirPerms); err != nil {
+			return fmt.Errorf(i18n.T("error_creating_file"), err)
+		}
+	}
+
+	names := inputListNames(entries, currentFlags.Pattern)
+	failed := 0
+	for i, entry := range entries {
+		outputFile := filepath.Join(outputDir, names[i])
+		if _, statErr := os.Stat(outputFile); statErr == nil {
+			debuglog.Log(i18n.T("input_list_skipping_existing_output"), entry, outputFile)
+			continue
+		}
+		debuglog.Log(i18n.T("input_list_processing_entry"), i+1, len(entries), entry)
+		if entryErr := runInputListEntry(currentFlags, registry, entry, outputFile); entryErr != nil {
+			debuglog.Log(i18n.T("input_list_entry_failed"), entry, entryErr)
+			failed++
+		}
+	}
+	if failed > 0 {
+		err = fmt.Errorf(i18n.T("input_list_failed"), failed, len(entries))
+	}
+	return
+}
+
+// readInputList returns the entries of the list, without blank lines and #
+// comments, the relative paths resolved from the directory of the list
+func readInputList(path string) (ret []string, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	dir := filepath.Dir(path)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if !isInputListURL(entry) && !filepath.IsAbs(entry) {
+			entry = filepath.Join(dir, entry)
+		}
+		ret = append(ret, entry)
+	}
+	err = scanner.Err()
+	return
+}
+
+func isInputListURL(entry string) bool {
+	return strings.HasPrefix(entry, "http://") || strings.HasPrefix(entry, "https://")
+}
+
+// inputListNames returns the names of the output files of the entries: the
+// slug of the URL or of the file name, with a hash of the entry when two
+// entries have the same slug so that the names stay the same between runs,
+// then the pattern, so that the outputs do not replace the files of the list
+func inputListNames(entries []string, pattern string) (ret []string) {
+	suffix := "." + cmp.Or(slugify(pattern), "output") + ".md"
+	slugs := make([]string, len(entries))
+	// The entries of each slug, the same entry twice getting the same file
+	bySlug := map[string]map[string]bool{}
+	for i, entry := range entries {
+		name := strings.TrimSuffix(filepath.Base(entry), filepath.Ext(entry))
+		if isInputListURL(entry) {
+			_, name, _ = strings.Cut(entry, "://")
+		}
+		slugs[i] = cmp.Or(slugify(name), "entry")
+		if bySlug[slugs[i]] == nil {
+			bySlug[slugs[i]] = map[string]bool{}
+		}
+		bySlug[slugs[i]][entry] = true
+	}
+	for i, entry := range entries {
+		name := slugs[i]
+		if len(bySlug[name]) > 1 {
+			sum := sha256.Sum256([]byte(entry))
+			name += "-" + hex.EncodeToString(sum[:3])
+		}
+		ret = append(ret, name+suffix)
+	}
+	return
+}
+
+// runInputListEntry runs the chat on the entry: the scraped page or the
+// YouTube transcript of a URL, the Markdown of an EPUB or DOCX file, the
+// content of a text file, or else the file as an attachment, recognized when
+// scanned
+func runInputListEntry(currentFlags *Flags, registry *core.PluginRegistry, entry, outputFile string) (err error) {
+	entryFlags := *currentFlags
+	entryFlags.InputList = ""
+	entryFlags.Output = outputFile
+	if isInputListURL(entry) {
+		entryFlags.sourceURL = entry
+		if videoID, playlistID, _ := registry.YouTube.GetVideoOrPlaylistId(entry); videoID != "" || playlistID != "" {
+			entryFlags.YouTube = entry
+		} else {
+			entryFlags.ScrapeURL = entry
+		}
+		return runJobTools(&entryFlags, registry)
+	}
+
+	if converter.IsDocument(entry) {
+		var text string
+		if text, err = readDocument(entry, currentFlags.MarkdownOptions()); err != nil {
+			return
+		}
+		entryFlags.Message = AppendMessage(currentFlags.Message, text)
+		return handleChatProcessing(&entryFlags, registry, "")
+	}
+	var data []byte
+	if data, err = os.ReadFile(entry); err != nil {
+		return
+	}
+	if utf8.Valid(data) && !bytes.ContainsRune(data, 0) {
+		entryFlags.Message = AppendMessage(currentFlags.Message, string(data))
+	} else {
+		entryFlags.Attachments = append(append([]string{}, currentFlags.Attachments...), entry)
+		if err = handleOCR(&entryFlags, registry); err != nil {
+			return
+		}
+	}
+	return handleChatProcessing(&entryFlags, registry, "")
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadInputList(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list.txt")
+	content := "# sources\nhttps://example.com/post\n\n  notes/a.md  \n/abs/b.pdf\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readInputList(path)
+	if err != nil {
+		t.Fatalf("readInputList() error = %v", err)
+	}
+	want := []string{"https://example.com/post", filepath.Join(dir, "notes", "a.md"), "/abs/b.pdf"}
+	if !slices.Equal(got, want) {
+		t.Errorf("readInputList() = %v, want %v", got, want)
+	}
+}
+
+func TestInputListNames(t *testing.T) {
+	entries := []string{
+		"https://example.com/blog/Post",
+		"docs/notes.md",
+		"other/notes.txt",
+		"docs/notes.md",
+		"???",
+	}
+	got := inputListNames(entries, "summarize")
+	if got[0] != "example-com-blog-post.summarize.md" || got[4] != "entry.summarize.md" {
+		t.Errorf("inputListNames() = %v", got)
+	}
+	// Different entries with the same slug get different names, the same
+	// entry twice the same one
+	if got[1] == got[2] || got[1] != got[3] || !strings.HasPrefix(got[1], "notes-") {
+		t.Errorf("inputListNames() = %v, want the notes told apart by a hash", got)
+	}
+	if again := inputListNames(entries, "summarize"); !slices.Equal(again, got) {
+		t.Errorf("inputListNames() = %v then %v, want the same names on each run", got, again)
+	}
+	if got = inputListNames([]string{"essay.md"}, ""); got[0] != "essay.output.md" {
+		t.Errorf("inputListNames() without a pattern = %v", got)
+	}
+}
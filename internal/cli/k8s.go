@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/k8s"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+)
+
+// collectK8s collects the state of the --k8s namespace once confirmed, or
+// right away with --yes, as the cluster state leaves the machine
+func collectK8s(flags *Flags) (ret string, err error) {
+	ctx := context.Background()
+	collector := &k8s.Collector{Context: flags.K8sContext, Namespace: flags.K8s}
+	if collector.Context == "" {
+		if collector.Context, err = k8s.CurrentContext(ctx); err != nil {
+			return
+		}
+	}
+	if !flags.Yes {
+		if !mdrender.IsTerminal(os.Stdin) {
+			return "", errors.New(i18n.T("k8s_not_confirmed"))
+		}
+		if !confirm(os.Stdin, os.Stderr, i18n.T("k8s_confirm"), collector.Describe()) {
+			return "", errors.New(i18n.T("k8s_cancelled"))
+		}
+	}
+	return collector.Collect(ctx)
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/tools/audio"
+)
+
+// handleListen runs a hands-free voice loop: record the microphone until Enter is
+// pressed, transcribe the recording, send it through the chat (pattern, context and
+// session flags apply as usual) and speak the reply. Entering "q" quits.
+func handleListen(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	if currentFlags.TranscribeModel == "" {
+		return errors.New(i18n.T("transcription_model_required"))
+	}
+	if currentFlags.TTSModel == "" {
+		return errors.New(i18n.T("listen_tts_model_required"))
+	}
+
+	var spk ai.Speaker
+	if spk, err = findSpeaker(currentFlags, registry); err != nil {
+		return
+	}
+
+	var tempDir string
+	if tempDir, err = os.MkdirTemp("", "fabric-listen-"); err != nil {
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	ctx := context.Background()
+	stdin := bufio.NewReader(os.Stdin)
+	for turn := 1; ; turn++ {
+		fmt.Fprint(os.Stderr, i18n.T("listen_press_enter_to_speak"))
+		line, readErr := stdin.ReadString('\n')
+		if readErr != nil || strings.EqualFold(strings.TrimSpace(line), "q") {
+			return nil
+		}
+
+		inputFile := filepath.Join(tempDir, fmt.Sprintf("input-%d.wav", turn))
+		stop := make(chan struct{})
+		recorded := make(chan error, 1)
+		go func() { recorded <- audio.Record(ctx, inputFile, stop) }()
+
+		fmt.Fprint(os.Stderr, i18n.T("listen_recording"))
+		_, _ = stdin.ReadString('\n')
+		close(stop)
+		if err = <-recorded; err != nil {
+			return
+		}
+
+		turnFlags := *currentFlags
+		turnFlags.TranscribeFile = inputFile
+		if !vendorSupports[transcriber](registry, turnFlags.Vendor) {
+			turnFlags.Vendor = ""
+		}
+
+		var transcript string
+		if transcript, err = handleTranscription(&turnFlags, registry); err != nil {
+			return
+		}
+		if transcript = strings.TrimSpace(transcript); transcript == "" {
+			debuglog.Log("%s\n", i18n.T("listen_nothing_heard"))
+			continue
+		}
+		debuglog.Log(i18n.T("listen_you_said"), transcript)
+
+		turnFlags = *currentFlags
+		turnFlags.Message = AppendMessage(currentFlags.Message, transcript)
+		// Every turn would otherwise try to write the same output file
+		turnFlags.Output = ""
+
+		var reply string
+		if reply, err = runChat(&turnFlags, registry, ""); err != nil {
+			return
+		}
+		if strings.TrimSpace(reply) == "" {
+			continue
+		}
+
+		var speech []byte
+		if speech, err = spk.SynthesizeSpeech(ctx, reply, currentFlags.TTSModel, currentFlags.Voice); err != nil {
+			return
+		}
+		replyFile := filepath.Join(tempDir, fmt.Sprintf("reply-%d.wav", turn))
+		if err = os.WriteFile(replyFile, speech, 0600); err != nil {
+			return
+		}
+		if err = audio.Play(ctx, replyFile); err != nil {
+			return
+		}
+	}
+}
+
+// findSpeaker returns the vendor used to speak replies: the --vendor one if it can
+// synthesize speech, otherwise Gemini for gemini-* TTS models and OpenAI for the rest.
+func findSpeaker(currentFlags *Flags, registry *core.PluginRegistry) (ret ai.Speaker, err error) {
+	vendorName := currentFlags.Vendor
+	if !vendorSupports[ai.Speaker](registry, vendorName) {
+		vendorName = "OpenAI"
+		if strings.HasPrefix(strings.ToLower(currentFlags.TTSModel), "gemini") {
+			vendorName = "Gemini"
+		}
+	}
+
+	vendor := registry.VendorManager.FindByName(vendorName)
+	if vendor == nil {
+		return nil, fmt.Errorf(i18n.T("vendor_not_configured"), vendorName)
+	}
+	var ok bool
+	if ret, ok = vendor.(ai.Speaker); !ok {
+		return nil, fmt.Errorf(i18n.T("listen_vendor_no_speech_support"), vendorName)
+	}
+	return
+}
+
+// vendorSupports reports whether the named, configured vendor implements T.
+func vendorSupports[T any](registry *core.PluginRegistry, vendorName string) bool {
+	if vendorName == "" {
+		return false
+	}
+	vendor := registry.VendorManager.FindByName(vendorName)
+	if vendor == nil {
+		return false
+	}
+	_, ok := vendor.(T)
+	return ok
+}
@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	openai "github.com/openai/openai-go"
 
@@ -34,14 +38,14 @@ func handleListingCommands(currentFlags *Flags, fabricDb *fsdb.Db, registry *cor
 		return true, err
 	}
 
-	if currentFlags.ListPatterns {
+	if currentFlags.ListPatterns || currentFlags.Tags != "" || currentFlags.SearchPatterns != "" {
 		// Check if patterns exist before listing
 		var names []string
 		if names, err = fabricDb.Patterns.GetNames(); err != nil {
 			return true, err
 		}
 
-		if len(names) == 0 && !currentFlags.ShellCompleteOutput {
+		if len(names) == 0 && !currentFlags.ShellCompleteOutput && !currentFlags.JSON {
 			// No patterns found - provide helpful guidance
 			fmt.Println("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 			fmt.Println(i18n.T("patterns_not_found_header"))
@@ -57,6 +61,10 @@ func handleListingCommands(currentFlags *Flags, fabricDb *fsdb.Db, registry *cor
 			return true, nil
 		}
 
+		if currentFlags.Tags != "" || currentFlags.SearchPatterns != "" || currentFlags.JSON {
+			err = listPatternMetadata(currentFlags, fabricDb.Patterns, os.Stdout)
+			return true, err
+		}
 		err = fabricDb.Patterns.ListNames(currentFlags.ShellCompleteOutput)
 		return true, err
 	}
@@ -85,7 +93,12 @@ func handleListingCommands(currentFlags *Flags, fabricDb *fsdb.Db, registry *cor
 	}
 
 	if currentFlags.ListAllSessions {
-		err = fabricDb.Sessions.ListNames(currentFlags.ShellCompleteOutput)
+		err = listSessions(currentFlags, fabricDb.Sessions, os.Stdout)
+		return true, err
+	}
+
+	if currentFlags.Spend {
+		err = printSpend(currentFlags, fabricDb.Spend, time.Now(), os.Stdout)
 		return true, err
 	}
 
@@ -113,6 +126,116 @@ func handleListingCommands(currentFlags *Flags, fabricDb *fsdb.Db, registry *cor
 	return false, nil
 }
 
+// listPatternMetadata prints the patterns having the tags of --tags and the
+// keyword of --search-patterns, with their description, tags and variables, or
+// as JSON with --json
+func listPatternMetadata(flags *Flags, patterns *fsdb.PatternsEntity, out io.Writer) (err error) {
+	var found []*fsdb.PatternMetadata
+	if found, err = patterns.FindMetadata(splitTags(flags.Tags), strings.TrimSpace(flags.SearchPatterns)); err != nil {
+		return
+	}
+
+	if flags.JSON {
+		if found == nil {
+			found = []*fsdb.PatternMetadata{}
+		}
+		for _, metadata := range found {
+			// Lists rather than nulls for the patterns without metadata
+			metadata.Tags = append(make([]string, 0, len(metadata.Tags)), metadata.Tags...)
+			metadata.Variables = append(make([]string, 0, len(metadata.Variables)), metadata.Variables...)
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(found)
+	}
+
+	if flags.ShellCompleteOutput {
+		for _, metadata := range found {
+			fmt.Fprintln(out, metadata.Name)
+		}
+		return
+	}
+	if len(found) == 0 {
+		fmt.Fprintln(out, i18n.T("patterns_none_matching"))
+		return
+	}
+	width := 0
+	for _, metadata := range found {
+		width = max(width, len(metadata.Name))
+	}
+	for _, metadata := range found {
+		line := fmt.Sprintf("%-*s  %s", width, metadata.Name, metadata.Description)
+		if len(metadata.Tags) > 0 {
+			line += " [" + strings.Join(metadata.Tags, ", ") + "]"
+		}
+		if len(metadata.Variables) > 0 {
+			line += " " + fmt.Sprintf(i18n.T("patterns_listing_variables"), strings.Join(metadata.Variables, ", "))
+		}
+		fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+	return
+}
+
+// listSessions lists the sessions matching --session-tags and --session-search
+// in the --session-sort order, with their title, tags, last update and model,
+// or as JSON with all their metadata.
+func listSessions(flags *Flags, sessions *fsdb.SessionsEntity, out io.Writer) (err error) {
+	switch flags.SessionSort {
+	case "", fsdb.SessionSortName, fsdb.SessionSortTitle, fsdb.SessionSortCreated, fsdb.SessionSortUpdated:
+	default:
+		return fmt.Errorf(i18n.T("invalid_session_sort"), flags.SessionSort)
+	}
+	var found []*fsdb.SessionInfo
+	if found, err = sessions.FindSessions(splitTags(flags.SessionTags), strings.TrimSpace(flags.SessionSearch), flags.SessionSort); err != nil {
+		return
+	}
+
+	if flags.JSON {
+		if found == nil {
+			found = []*fsdb.SessionInfo{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(found)
+	}
+
+	if flags.ShellCompleteOutput {
+		for _, info := range found {
+			fmt.Fprintln(out, info.Name)
+		}
+		return
+	}
+	if len(found) == 0 {
+		fmt.Fprintln(out, fmt.Sprintf(i18n.T("no_items_found"), sessions.Label))
+		return
+	}
+	width := 0
+	for _, info := range found {
+		width = max(width, len(info.Name))
+	}
+	for _, info := range found {
+		line := fmt.Sprintf("%-*s  %s  %s", width, info.Name, info.Updated.Local().Format(time.DateTime), info.Title)
+		if len(info.Tags) > 0 {
+			line += " [" + strings.Join(info.Tags, ", ") + "]"
+		}
+		if info.Model != "" {
+			line += " (" + strings.TrimPrefix(info.Vendor+"|"+info.Model, "|") + ")"
+		}
+		fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+	return
+}
+
+// splitTags returns the comma-separated tags of the value
+func splitTags(value string) (ret []string) {
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			ret = append(ret, tag)
+		}
+	}
+	return
+}
+
 // listTranscriptionModels lists all available transcription models
 func listTranscriptionModels(shellComplete bool) {
 	models := []string{
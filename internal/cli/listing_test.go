@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPatternMetadata(t *testing.T) {
+	patterns := &fsdb.PatternsEntity{
+		StorageEntity:     &fsdb.StorageEntity{Dir: t.TempDir(), ItemIsDir: true},
+		SystemPatternFile: "system.md",
+	}
+	for name, metadata := range map[string]string{
+		"summarize": "description: Summarize content.\ntags: [SUMMARIZE, WRITING]\n",
+		"translate": "description: Translate the input.\ntags: [CONVERSION]\nvariables: [lang_code]\n",
+		"plain":     "",
+	} {
+		dir := filepath.Join(patterns.Dir, name)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "system.md"), []byte("pattern"), 0644))
+		if metadata != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, fsdb.PatternMetadataFile), []byte(metadata), 0644))
+		}
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, listPatternMetadata(&Flags{SearchPatterns: "a"}, patterns, &out))
+	assert.Equal(t, "plain\n"+
+		"summarize  Summarize content. [SUMMARIZE, WRITING]\n"+
+		"translate  Translate the input. [CONVERSION] (variables: lang_code)\n", out.String())
+
+	out.Reset()
+	require.NoError(t, listPatternMetadata(&Flags{Tags: "writing, summarize"}, patterns, &out))
+	assert.Equal(t, "summarize  Summarize content. [SUMMARIZE, WRITING]\n", out.String())
+
+	out.Reset()
+	require.NoError(t, listPatternMetadata(&Flags{Tags: "writing", ShellCompleteOutput: true}, patterns, &out))
+	assert.Equal(t, "summarize\n", out.String())
+
+	out.Reset()
+	require.NoError(t, listPatternMetadata(&Flags{SearchPatterns: "nothing"}, patterns, &out))
+	assert.Equal(t, "No patterns match the tags and keyword\n", out.String())
+}
+
+func TestListPatternMetadataJSON(t *testing.T) {
+	patterns := &fsdb.PatternsEntity{
+		StorageEntity:     &fsdb.StorageEntity{Dir: t.TempDir(), ItemIsDir: true},
+		SystemPatternFile: "system.md",
+	}
+	dir := filepath.Join(patterns.Dir, "plain")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "system.md"), []byte("pattern"), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, listPatternMetadata(&Flags{JSON: true}, patterns, &out))
+	var listed []map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &listed))
+	assert.Equal(t, []map[string]any{{"name": "plain", "description": "", "tags": []any{}, "variables": []any{}}}, listed)
+
+	out.Reset()
+	require.NoError(t, listPatternMetadata(&Flags{JSON: true, Tags: "none"}, patterns, &out))
+	assert.Equal(t, "[]\n", out.String())
+}
+
+func TestListSessions(t *testing.T) {
+	sessions := &fsdb.SessionsEntity{
+		StorageEntity: &fsdb.StorageEntity{Label: "Sessions", Dir: t.TempDir(), FileExtension: ".json"},
+	}
+	for _, session := range []*fsdb.Session{
+		{Name: "proxy", Meta: fsdb.SessionMeta{Tags: []string{"work"}, Vendor: "OpenAI", Model: "gpt-4o"}},
+		{Name: "trip", Meta: fsdb.SessionMeta{Title: "Lisbon trip"}},
+	} {
+		session.Messages = []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Set up the proxy"}}
+		require.NoError(t, sessions.SaveSession(session))
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, listSessions(&Flags{SessionTags: "work"}, sessions, &out))
+	assert.Regexp(t, `^proxy  \d{4}-\d\d-\d\d \d\d:\d\d:\d\d  Set up the proxy \[work\] \(OpenAI\|gpt-4o\)\n$`, out.String())
+
+	out.Reset()
+	require.NoError(t, listSessions(&Flags{SessionSort: "title", ShellCompleteOutput: true}, sessions, &out))
+	assert.Equal(t, "trip\nproxy\n", out.String())
+
+	out.Reset()
+	require.NoError(t, listSessions(&Flags{JSON: true, SessionSearch: "lisbon"}, sessions, &out))
+	var listed []map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, "trip", listed[0]["name"])
+	assert.Equal(t, "Lisbon trip", listed[0]["title"])
+	assert.NotContains(t, listed[0], "tags")
+	assert.Equal(t, float64(1), listed[0]["messages"])
+	assert.NotEmpty(t, listed[0]["updated"])
+
+	assert.Error(t, listSessions(&Flags{SessionSort: "size"}, sessions, &out))
+}
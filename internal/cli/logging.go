@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+)
+
+// configureLogging sends the logs to slog for --log-level, --log-format and
+// --log-file. The messages are printed as they are without them. The debug
+// level shows the messages of --debug, at its basic verbosity when not given.
+func (o *Flags) configureLogging() (err error) {
+	if o.LogLevel == "" && o.LogFormat == "" && o.LogFile == "" {
+		return
+	}
+	level := slog.LevelInfo
+	if o.LogLevel != "" {
+		if level, err = debuglog.ParseLevel(o.LogLevel); err != nil {
+			return
+		}
+	} else if o.Debug > 0 {
+		level = slog.LevelDebug
+	}
+	if level <= slog.LevelDebug && o.Debug == 0 {
+		debuglog.SetLevel(debuglog.Basic)
+	}
+
+	var w io.Writer = os.Stderr
+	if o.LogFile != "" {
+		// The file stays open as long as fabric runs
+		if w, err = os.OpenFile(o.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err != nil {
+			return
+		}
+	}
+	return debuglog.Configure(level, o.LogFormat, w)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/logs"
+)
+
+// logsOptions returns the options of the --logs summaries
+func logsOptions(flags *Flags) (ret logs.Options, err error) {
+	ret.Since, err = logs.ParseSince(flags.Since, time.Now())
+	return
+}
+
+// handleFollow runs the chat on the lines appended to the file of --logs,
+// in batches every --follow-interval, until interrupted. With --since, the
+// entries of that period are run first. The responses are written to
+// --output, or printed with a divider between them.
+func handleFollow(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	if currentFlags.Logs == "" {
+		return errors.New(i18n.T("logs_follow_requires_logs"))
+	}
+	var options logs.Options
+	if options, err = logsOptions(currentFlags); err != nil {
+		return
+	}
+	var tail *logs.Tail
+	if tail, err = logs.NewTail(currentFlags.Logs); err != nil {
+		return
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	run := func(message string, count int) {
+		if currentFlags.Output == "" {
+			fmt.Printf("\n"+i18n.T("logs_divider")+"\n\n", time.Now().Format(time.TimeOnly), count)
+		}
+		runFlags := *currentFlags
+		runFlags.Message = AppendMessage(currentFlags.Message, message)
+		if chatErr := handleChatProcessing(&runFlags, registry, ""); chatErr != nil {
+			fmt.Fprintln(os.Stderr, chatErr)
+		}
+	}
+
+	if !options.Since.IsZero() {
+		if message, readErr := logs.ReadFile(currentFlags.Logs, options); readErr != nil {
+			fmt.Fprintln(os.Stderr, readErr)
+		} else {
+			run(message, 0)
+		}
+	}
+	fmt.Fprintf(os.Stderr, i18n.T("logs_following")+"\n", currentFlags.Logs, currentFlags.FollowInterval)
+	followLines(ctx, tail, max(currentFlags.FollowInterval, time.Second), func(lines []string) {
+		summary := logs.NewSummary(logs.Options{})
+		for _, line := range lines {
+			summary.Add(line)
+		}
+		run(summary.Markdown(fmt.Sprintf(i18n.T("logs_new_heading"), filepath.Base(currentFlags.Logs))), len(lines))
+	})
+	return nil
+}
+
+// followLines calls run with the lines appended to the tail every interval,
+// until the context is done
+func followLines(ctx context.Context, tail *logs.Tail, interval time.Duration, run func(lines []string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		lines, err := tail.Lines()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		if len(lines) > 0 {
+			run(lines)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/tools/logs"
+)
+
+func TestFollowLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tail, err := logs.NewTail(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	batches := make(chan []string, 10)
+	go followLines(ctx, tail, 20*time.Millisecond, func(lines []string) { batches <- lines })
+
+	// The lines appended between two ticks come in one batch
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	_, _ = file.WriteString("first\nsecond\n")
+	_ = file.Close()
+	select {
+	case lines := <-batches:
+		if strings.Join(lines, "|") != "first|second" {
+			t.Errorf("batch = %q, want [first second]", lines)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the new lines were not run")
+	}
+	select {
+	case lines := <-batches:
+		t.Errorf("ran again with %q, want no batch without new lines", lines)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/browser"
+	"github.com/danielmiessler/fabric/internal/tools/mcp"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+	"github.com/danielmiessler/fabric/internal/tools/sandbox"
+	"github.com/danielmiessler/fabric/internal/tools/workspace"
+)
+
+// closingToolbox is a toolbox to close once the chat is over
+type closingToolbox interface {
+	core.Toolbox
+	Close() error
+}
+
+// toolboxes offers the tools of several toolboxes as one
+type toolboxes []closingToolbox
+
+func (o toolboxes) Tools() (ret []domain.Tool) {
+	for _, toolbox := range o {
+		ret = append(ret, toolbox.Tools()...)
+	}
+	return
+}
+
+func (o toolboxes) Call(ctx context.Context, name string, arguments string) (string, error) {
+	for _, toolbox := range o {
+		for _, tool := range toolbox.Tools() {
+			if tool.Name == name {
+				return toolbox.Call(ctx, name, arguments)
+			}
+		}
+	}
+	return "", fmt.Errorf(i18n.T("mcp_error_tool_not_found"), name)
+}
+
+func (o toolboxes) Close() (err error) {
+	for _, toolbox := range o {
+		err = errors.Join(err, toolbox.Close())
+	}
+	return
+}
+
+// usesTools reports whether the model is offered tools, by --mcp,
+// --allow-browser, --allow-exec or --allow-write
+func (o *Flags) usesTools() bool {
+	return len(o.MCP) > 0 || o.AllowBrowser || o.AllowExec || o.AllowWrite != ""
+}
+
+// connectTools connects to the MCP servers of --mcp, to the browser with
+// --allow-browser, to the sandbox with --allow-exec and to the workspace of
+// --allow-write, and offers their tools to the model of the chatter. The
+// returned function disconnects them. A dry run shows the request without
+// connecting.
+func (o *Flags) connectTools(ctx context.Context, chatter *core.Chatter) (closeTools func(), err error) {
+	if !o.usesTools() || o.DryRun {
+		return func() {}, nil
+	}
+	var opened toolboxes
+	if len(o.MCP) > 0 {
+		var toolbox *mcp.Toolbox
+		if toolbox, err = mcp.Open(ctx, o.MCPServers, o.MCP); err != nil {
+			return
+		}
+		opened = append(opened, toolbox)
+	}
+	if o.AllowBrowser {
+		server := browser.DefaultServer
+		if configured, ok := o.MCPServers[browser.ServerName]; ok {
+			server = configured
+		}
+		var toolbox *browser.Toolbox
+		if toolbox, err = browser.Open(ctx, &server); err != nil {
+			_ = opened.Close()
+			return
+		}
+		toolbox.Markdown = o.MarkdownOptions()
+		opened = append(opened, toolbox)
+	}
+	if o.AllowExec {
+		var toolbox *sandbox.Sandbox
+		if o.ExecMemory < 0 {
+			err = fmt.Errorf(i18n.T("invalid_negative_count"), "exec-memory", o.ExecMemory)
+		} else {
+			toolbox, err = sandbox.New(o.ExecSandbox, o.ExecTimeout, o.ExecMemory)
+		}
+		if err != nil {
+			_ = opened.Close()
+			return
+		}
+		opened = append(opened, toolbox)
+	}
+	if o.AllowWrite != "" {
+		var ask workspace.Confirm
+		if !o.Yes {
+			if !mdrender.IsTerminal(os.Stdin) {
+				_ = opened.Close()
+				return nil, errors.New(i18n.T("workspace_not_confirmed"))
+			}
+			ask = func(path, operation string, content string) bool {
+				return confirm(os.Stdin, os.Stderr, i18n.T("workspace_confirm"), path, operation, len(content))
+			}
+		}
+		var toolbox *workspace.Workspace
+		if toolbox, err = workspace.Open(o.AllowWrite, ask); err != nil {
+			_ = opened.Close()
+			return
+		}
+		opened = append(opened, toolbox)
+	}
+	chatter.Tools = opened
+	return func() { _ = opened.Close() }, nil
+}
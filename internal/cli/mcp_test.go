@@ -0,0 +1,12 @@
+package cli
+
+import "testing"
+
+func TestUsesTools(t *testing.T) {
+	if (&Flags{}).usesTools() {
+		t.Error("expected no tools without --mcp, --allow-browser, --allow-exec or --allow-write")
+	}
+	if !(&Flags{AllowWrite: "out"}).usesTools() {
+		t.Error("expected --allow-write to offer tools")
+	}
+}
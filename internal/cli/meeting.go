@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/tools/meetings"
+)
+
+// meetingTranscript reads the transcript of the --meeting source: a Zoom
+// cloud recording, a Google Meet transcript in Drive, or a captions file
+func meetingTranscript(source string, registry *core.PluginRegistry) (meetings.Transcript, error) {
+	if meeting, ok := strings.CutPrefix(source, "zoom:"); ok {
+		return registry.Zoom.Transcript(meeting)
+	}
+	if doc, ok := strings.CutPrefix(source, "meet:"); ok {
+		return meetings.GoogleMeet(context.Background(), doc)
+	}
+	if meetings.IsGoogleDoc(source) {
+		return meetings.GoogleMeet(context.Background(), source)
+	}
+	return meetings.ReadFile(source)
+}
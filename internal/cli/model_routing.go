@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"strings"
+
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+// ModelRoute picks a model when all of its conditions match. Conditions left empty
+// always match, so a route without any acts as a catch-all.
+type ModelRoute struct {
+	// MinInputTokens matches inputs estimated at this many tokens or more.
+	MinInputTokens int `yaml:"minInputTokens,omitempty"`
+	// Pattern matches when the given pattern is used.
+	Pattern string `yaml:"pattern,omitempty"`
+	// Model is the model (or alias) to use; "vendor|model" selects the vendor too.
+	Model string `yaml:"model"`
+}
+
+func (o ModelRoute) matches(pattern string, inputTokens int) bool {
+	if o.Pattern != "" && !strings.EqualFold(o.Pattern, pattern) {
+		return false
+	}
+	return inputTokens >= o.MinInputTokens
+}
+
+// resolveModel applies the configured routing rules and model aliases. Routes are
+// only consulted when no model was chosen, and the first matching one wins.
+func (o *Flags) resolveModel() {
+	if o.Model == "" {
+		inputTokens := ai.EstimateTokens(o.Message)
+		for _, route := range o.ModelRoutes {
+			if route.matches(o.Pattern, inputTokens) {
+				debuglog.Debug(debuglog.Basic, "Model route matched (pattern %q, ~%d input tokens): %s\n", o.Pattern, inputTokens, route.Model)
+				o.setModelSpec(route.Model)
+				break
+			}
+		}
+	}
+
+	if spec, ok := o.lookupModelAlias(o.Model); ok {
+		debuglog.Debug(debuglog.Basic, "Model alias %s resolved to %s\n", o.Model, spec)
+		o.setModelSpec(spec)
+	}
+}
+
+// lookupModelAlias finds the alias named name, preferring an exact match over a
+// case-insensitive one.
+func (o *Flags) lookupModelAlias(name string) (spec string, ok bool) {
+	if name == "" {
+		return
+	}
+	if spec, ok = o.ModelAliases[name]; ok {
+		return
+	}
+	for alias, aliasSpec := range o.ModelAliases {
+		if strings.EqualFold(alias, name) {
+			return aliasSpec, true
+		}
+	}
+	return
+}
+
+// setModelSpec sets the model from a "model" or "vendor|model" spec, the same
+// format as FABRIC_MODEL_* variables. An explicit --vendor is kept.
+func (o *Flags) setModelSpec(spec string) {
+	vendor, model, found := strings.Cut(spec, "|")
+	if !found {
+		o.Model = spec
+		return
+	}
+	o.Model = model
+	if o.Vendor == "" {
+		o.Vendor = vendor
+	}
+}
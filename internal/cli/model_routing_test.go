@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveModel(t *testing.T) {
+	aliases := map[string]string{
+		"fast":  "gpt-4o-mini",
+		"smart": "Anthropic|claude-opus-4-1",
+		"long":  "gemini-2.5-pro",
+	}
+	routes := []ModelRoute{
+		{MinInputTokens: 50000, Model: "long"},
+		{Pattern: "summarize", Model: "fast"},
+	}
+
+	tests := []struct {
+		name       string
+		flags      Flags
+		wantModel  string
+		wantVendor string
+	}{
+		{name: "alias", flags: Flags{Model: "fast"}, wantModel: "gpt-4o-mini"},
+		{name: "alias is case-insensitive", flags: Flags{Model: "FAST"}, wantModel: "gpt-4o-mini"},
+		{name: "alias with vendor", flags: Flags{Model: "smart"}, wantModel: "claude-opus-4-1", wantVendor: "Anthropic"},
+		{name: "explicit vendor kept", flags: Flags{Model: "smart", Vendor: "Bedrock"}, wantModel: "claude-opus-4-1", wantVendor: "Bedrock"},
+		{name: "unknown model unchanged", flags: Flags{Model: "gpt-4o"}, wantModel: "gpt-4o"},
+		{name: "route by input size", flags: Flags{Message: strings.Repeat("word ", 50000)}, wantModel: "gemini-2.5-pro"},
+		{name: "route by pattern", flags: Flags{Pattern: "summarize", Message: "short"}, wantModel: "gpt-4o-mini"},
+		{name: "first matching route wins", flags: Flags{Pattern: "summarize", Message: strings.Repeat("word ", 50000)}, wantModel: "gemini-2.5-pro"},
+		{name: "no route matches", flags: Flags{Pattern: "extract_wisdom", Message: "short"}, wantModel: ""},
+		{name: "routes skipped with explicit model", flags: Flags{Model: "gpt-4o", Pattern: "summarize"}, wantModel: "gpt-4o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := tt.flags
+			flags.ModelAliases = aliases
+			flags.ModelRoutes = routes
+			flags.resolveModel()
+			assert.Equal(t, tt.wantModel, flags.Model)
+			assert.Equal(t, tt.wantVendor, flags.Vendor)
+		})
+	}
+}
+
+func TestLoadYAMLConfigModelRouting(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	config := `modelAliases:
+  fast: gpt-4o-mini
+  smart: Anthropic|claude-opus-4-1
+modelRoutes:
+  - minInputTokens: 50000
+    model: smart
+  - pattern: summarize
+    model: fast
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+
+	flags, err := loadYAMLConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Anthropic|claude-opus-4-1", flags.ModelAliases["smart"])
+	assert.Equal(t, []ModelRoute{
+		{MinInputTokens: 50000, Model: "smart"},
+		{Pattern: "summarize", Model: "fast"},
+	}, flags.ModelRoutes)
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+const (
+	moderateBlock    = "block"
+	moderateAnnotate = "annotate"
+
+	moderationProviderOpenAI = "openai"
+	moderationProviderLocal  = "local"
+)
+
+// configureModeration sets up the moderation of every chat, including those
+// served by --serve, when --moderate is given.
+func configureModeration(flags *Flags, registry *core.PluginRegistry) (err error) {
+	if flags.Moderate == "" {
+		return
+	}
+
+	moderation := &core.Moderation{Thresholds: flags.ModerationThresholds}
+	switch flags.Moderate {
+	case moderateBlock:
+	case moderateAnnotate:
+		moderation.Annotate = true
+	default:
+		return fmt.Errorf(i18n.T("invalid_moderate"), flags.Moderate)
+	}
+
+	switch strings.ToLower(flags.ModerationProvider) {
+	case "", moderationProviderOpenAI:
+		vendor := registry.VendorManager.FindByName("OpenAI")
+		if vendor == nil {
+			return fmt.Errorf(i18n.T("vendor_not_configured"), "OpenAI")
+		}
+		var ok bool
+		if moderation.Moderator, ok = vendor.(ai.Moderator); !ok {
+			return fmt.Errorf(i18n.T("vendor_no_moderation_support"), "OpenAI")
+		}
+	case moderationProviderLocal:
+		if len(flags.ModerationTerms) == 0 {
+			return errors.New(i18n.T("moderation_terms_required"))
+		}
+		if moderation.Moderator, err = ai.NewTermModerator(flags.ModerationTerms); err != nil {
+			return fmt.Errorf(i18n.T("invalid_moderation_terms"), err)
+		}
+	default:
+		return fmt.Errorf(i18n.T("invalid_moderation_provider"), flags.ModerationProvider)
+	}
+
+	registry.Moderation = moderation
+	return
+}
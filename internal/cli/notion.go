@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+)
+
+// handleNotionAppend appends the output to the end of the --notion-append
+// page as blocks
+func handleNotionAppend(flags *Flags, registry *core.PluginRegistry, output string) (err error) {
+	var count int
+	if count, err = registry.Notion.AppendMarkdown(flags.NotionAppend, output); err != nil {
+		return
+	}
+	debuglog.Log(i18n.T("notion_appended"), count, flags.NotionAppend)
+	return
+}
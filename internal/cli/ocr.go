@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/ocr"
+)
+
+// ocrPrompt asks the --ocr-model for the text of a page
+const ocrPrompt = "Transcribe all the text of this image exactly as written, in reading order, " +
+	"keeping its headings, lists and tables as Markdown. Reply with the text only, without any comment."
+
+// handleOCR replaces the scanned attachments by their recognized text,
+// appended to the message so that scanned documents become pattern input:
+// the images and PDFs with --ocr, and else the PDFs without a text layer
+func handleOCR(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	var attachments []string
+	for _, attachment := range currentFlags.Attachments {
+		if isInputListURL(attachment) || !currentFlags.needsOCR(attachment) {
+			attachments = append(attachments, attachment)
+			continue
+		}
+		debuglog.Log(i18n.T("ocr_recognizing"), attachment)
+		engine := currentFlags.ocrEngine(registry)
+		var text string
+		if ocr.IsPDF(attachment) {
+			text, err = ocr.PDF(attachment, engine)
+		} else {
+			text, err = engine(attachment)
+		}
+		if err != nil {
+			return
+		}
+		currentFlags.Message = AppendMessage(currentFlags.Message, text)
+	}
+	currentFlags.Attachments = attachments
+	return
+}
+
+// needsOCR reports whether the attachment is to be recognized: an image or a
+// PDF with --ocr, and else a PDF without a text layer. PDFs are sent as they
+// are when pdftotext cannot tell.
+func (o *Flags) needsOCR(path string) bool {
+	if o.OCR {
+		return ocr.IsImage(path) || ocr.IsPDF(path)
+	}
+	if !ocr.IsPDF(path) {
+		return false
+	}
+	text, err := ocr.PDFText(path)
+	if err != nil {
+		debuglog.Debug(debuglog.Basic, "Cannot check the text layer of %s: %v\n", path, err)
+		return false
+	}
+	return text == ""
+}
+
+// ocrEngine returns the vision model of --ocr-model, or else Tesseract in the
+// --ocr-lang languages
+func (o *Flags) ocrEngine(registry *core.PluginRegistry) ocr.Engine {
+	if o.OCRModel == "" {
+		return ocr.Tesseract(o.OCRLanguages)
+	}
+	return func(imagePath string) (ret string, err error) {
+		ocrFlags := *o
+		ocrFlags.Model = ""
+		ocrFlags.setModelSpec(o.OCRModel)
+		ocrFlags.resolveModel()
+		ocrFlags.Pattern, ocrFlags.Strategy, ocrFlags.Session = "", "", ""
+		ocrFlags.Context, ocrFlags.ContextCmd = nil, nil
+		ocrFlags.Samples, ocrFlags.Refine = 0, 0
+		ocrFlags.TranslateOutput = ""
+		ocrFlags.Message = ocrPrompt
+		ocrFlags.Attachments = []string{imagePath}
+
+		var chatter *core.Chatter
+		if chatter, err = registry.GetChatter(ocrFlags.Model, ocrFlags.ModelContextLength,
+			ocrFlags.Vendor, false, ocrFlags.DryRun); err != nil {
+			return
+		}
+		ocrFlags.servingVendor = chatter.ServingVendorName()
+		var chatOptions *domain.ChatOptions
+		if chatOptions, err = ocrFlags.BuildChatOptions(); err != nil {
+			return
+		}
+		var chatReq *domain.ChatRequest
+		if chatReq, err = ocrFlags.BuildChatRequest(""); err != nil {
+			return
+		}
+		ctx, cancel := ocrFlags.requestContext()
+		defer cancel()
+		var session *fsdb.Session
+		if session, err = chatter.Send(ctx, chatReq, chatOptions); err != nil {
+			return
+		}
+		return domain.StripThinkBlocks(session.GetLastMessage().Content, chatOptions.ThinkStartTag, chatOptions.ThinkEndTag), nil
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestHandleOCR(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake tools are shell scripts")
+	}
+	dir := t.TempDir()
+	for name, script := range map[string]string{
+		"tesseract": `echo "text of ${1##*/}"`,
+		"pdftotext": `case "$2" in *scan.pdf) ;; *) echo "text layer" ;; esac`,
+		"pdftoppm":  `: > "$5-1.png"`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	// Without --ocr only the PDFs without a text layer are recognized
+	flags := &Flags{Attachments: []string{"photo.png", "scan.pdf", "report.pdf"}}
+	if err := handleOCR(flags, nil); err != nil {
+		t.Fatalf("handleOCR() error = %v", err)
+	}
+	if flags.Message != "text of page-1.png" || !slices.Equal(flags.Attachments, []string{"photo.png", "report.pdf"}) {
+		t.Errorf("handleOCR() = %q, %v", flags.Message, flags.Attachments)
+	}
+
+	flags = &Flags{OCR: true, Message: "extract:", Attachments: []string{"photo.png", "https://example.com/a.png", "notes.md"}}
+	if err := handleOCR(flags, nil); err != nil {
+		t.Fatalf("handleOCR() error = %v", err)
+	}
+	if flags.Message != "extract:\ntext of photo.png" || !slices.Equal(flags.Attachments, []string{"https://example.com/a.png", "notes.md"}) {
+		t.Errorf("handleOCR() with --ocr = %q, %v", flags.Message, flags.Attachments)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	defaultOutputName   = "{{.Date}}-{{.Pattern}}-{{.Title}}"
+	maxOutputSlugLength = 60
+)
+
+// outputDirPath returns the path of a new file in --output-dir for the output,
+// named after the --output-name template. The template fields are slugified and
+// a number is appended to the name when the file already exists.
+func (o *Flags) outputDirPath(data *outputTemplateData) (ret string, err error) {
+	nameTemplate := o.OutputName
+	if nameTemplate == "" {
+		nameTemplate = defaultOutputName
+	}
+	var tmpl *template.Template
+	if tmpl, err = template.New("name").Parse(nameTemplate); err != nil {
+		err = fmt.Errorf(i18n.T("output_name_error_parsing"), err)
+		return
+	}
+
+	slugged := *data
+	slugged.Pattern = slugify(data.Pattern)
+	slugged.Model = slugify(data.Model)
+	slugged.Vendor = slugify(data.Vendor)
+	slugged.Title = slugify(data.Title)
+	slugged.SourceURL = slugify(data.SourceURL)
+	slugged.Output = ""
+
+	var sb strings.Builder
+	if err = tmpl.Execute(&sb, &slugged); err != nil {
+		err = fmt.Errorf(i18n.T("output_name_error_rendering"), err)
+		return
+	}
+	name := cleanOutputName(sb.String())
+	if name == "" {
+		name = "output"
+	}
+	if filepath.Ext(name) == "" {
+		name += ".md"
+	}
+
+	if err = os.MkdirAll(o.OutputDir, ConfigDirPerms); err != nil {
+		err = fmt.Errorf(i18n.T("error_creating_file"), err)
+		return
+	}
+	ret = uniquePath(filepath.Join(o.OutputDir, name))
+	return
+}
+
+// outputTitle returns the title of an output: its first Markdown heading, or
+// else its first non-empty line.
+func outputTitle(output string) (ret string) {
+	for line := range strings.Lines(output) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if heading := strings.TrimLeft(line, "#"); heading != line {
+			return strings.TrimSpace(heading)
+		}
+		if ret == "" {
+			ret = line
+		}
+	}
+	return
+}
+
+// slugify lowercases text and replaces anything but letters and digits with
+// dashes, keeping at most maxOutputSlugLength characters.
+func slugify(text string) string {
+	var sb strings.Builder
+	dash := false
+	count := 0
+	for _, r := range strings.ToLower(text) {
+		if count >= maxOutputSlugLength {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+				count++
+			}
+			sb.WriteRune(r)
+			count++
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// cleanOutputName removes path separators and the dashes left around empty
+// template fields from a rendered file name.
+func cleanOutputName(name string) string {
+	name = strings.NewReplacer("/", "-", "\\", "-").Replace(strings.TrimSpace(name))
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	name = strings.ReplaceAll(name, "-.", ".")
+	return strings.Trim(name, "-.")
+}
+
+// uniquePath returns path, or when it exists, path with the first free number
+// appended to the name.
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if _, err := os.Stat(candidate); err != nil {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Hello, World!":            "hello-world",
+		"  extract_wisdom  ":       "extract-wisdom",
+		"Ünïcode Títle":            "ünïcode-títle",
+		"https://youtu.be/abc?t=1": "https-youtu-be-abc-t-1",
+		"":                         "",
+	}
+	for input, want := range tests {
+		if got := slugify(input); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestOutputTitle(t *testing.T) {
+	tests := map[string]string{
+		"\n# Main Title\n\nText": "Main Title",
+		"Intro line\n## Section": "Section",
+		"First line\nSecond":     "First line",
+		"":                       "",
+	}
+	for input, want := range tests {
+		if got := outputTitle(input); got != want {
+			t.Errorf("outputTitle(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestOutputDirPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "notes")
+	flags := &Flags{Pattern: "summarize", OutputDir: dir}
+	data := newOutputTemplateData(flags, "", "", "# A Great Talk\n\nSummary")
+	date := time.Now().Format(time.DateOnly)
+
+	path, err := flags.outputDirPath(data)
+	if err != nil {
+		t.Fatalf("outputDirPath() error = %v", err)
+	}
+	if want := filepath.Join(dir, date+"-summarize-a-great-talk.md"); path != want {
+		t.Errorf("outputDirPath() = %q, want %q", path, want)
+	}
+
+	if err = os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err = flags.outputDirPath(data); err != nil {
+		t.Fatalf("outputDirPath() error = %v", err)
+	}
+	if want := filepath.Join(dir, date+"-summarize-a-great-talk-2.md"); path != want {
+		t.Errorf("outputDirPath() with collision = %q, want %q", path, want)
+	}
+}
+
+func TestOutputDirPathName(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		template string
+		pattern  string
+		output   string
+		want     string
+	}{
+		{name: "empty fields", template: "{{.Pattern}}{{.Title}}", want: "output.md"},
+		{name: "missing pattern", output: "Title", want: time.Now().Format(time.DateOnly) + "-title.md"},
+		{name: "custom", template: "{{.Pattern}}/{{.Title}}.txt", pattern: "p", output: "Title", want: "p-title.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := &Flags{Pattern: tt.pattern, OutputDir: dir, OutputName: tt.template}
+			path, err := flags.outputDirPath(newOutputTemplateData(flags, "", "", tt.output))
+			if err != nil {
+				t.Fatalf("outputDirPath() error = %v", err)
+			}
+			if got := filepath.Base(path); got != tt.want {
+				t.Errorf("outputDirPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
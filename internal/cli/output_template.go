@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// outputTemplateData holds the fields available to --output-template.
+type outputTemplateData struct {
+	Pattern   string
+	Title     string
+	Model     string
+	Vendor    string
+	Date      string
+	Now       time.Time
+	SourceURL string
+	Output    string
+}
+
+// parseOutputTemplate parses the --output-template value, which is either the
+// path of a template file or the template itself.
+func parseOutputTemplate(value string) (ret *template.Template, err error) {
+	if value == "" {
+		return
+	}
+	text := value
+	if info, statErr := os.Stat(value); statErr == nil && !info.IsDir() {
+		var data []byte
+		if data, err = os.ReadFile(value); err != nil {
+			err = fmt.Errorf(i18n.T("output_template_error_reading"), value, err)
+			return
+		}
+		text = string(data)
+	}
+	if ret, err = template.New("output").Parse(text); err != nil {
+		err = fmt.Errorf(i18n.T("output_template_error_parsing"), err)
+	}
+	return
+}
+
+// renderOutputTemplate renders the output through the template.
+func renderOutputTemplate(tmpl *template.Template, data *outputTemplateData) (ret string, err error) {
+	var sb strings.Builder
+	if err = tmpl.Execute(&sb, data); err != nil {
+		err = fmt.Errorf(i18n.T("output_template_error_rendering"), err)
+		return
+	}
+	ret = sb.String()
+	return
+}
+
+// newOutputTemplateData collects the template fields for an output.
+func newOutputTemplateData(flags *Flags, model, vendor, output string) *outputTemplateData {
+	now := time.Now()
+	return &outputTemplateData{
+		Pattern:   flags.Pattern,
+		Title:     outputTitle(output),
+		Model:     model,
+		Vendor:    vendor,
+		Date:      now.Format(time.DateOnly),
+		Now:       now,
+		SourceURL: flags.sourceURLValue(),
+		Output:    output,
+	}
+}
+
+// sourceURLValue returns the URL the input was taken from, if any.
+func (o *Flags) sourceURLValue() string {
+	switch {
+	case o.sourceURL != "":
+		return o.sourceURL
+	case o.YouTube != "":
+		return o.YouTube
+	case o.ScrapeURL != "":
+		return o.ScrapeURL
+	default:
+		return o.Spotify
+	}
+}
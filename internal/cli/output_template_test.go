@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderOutputTemplate(t *testing.T) {
+	tmpl, err := parseOutputTemplate("---\npattern: {{.Pattern}}\nsource: {{.SourceURL}}\n---\n{{.Output}}")
+	if err != nil {
+		t.Fatalf("parseOutputTemplate() error = %v", err)
+	}
+
+	flags := &Flags{Pattern: "summarize", YouTube: "https://youtu.be/abc"}
+	got, err := renderOutputTemplate(tmpl, newOutputTemplateData(flags, "gpt-4o", "OpenAI", "the summary"))
+	if err != nil {
+		t.Fatalf("renderOutputTemplate() error = %v", err)
+	}
+	want := "---\npattern: summarize\nsource: https://youtu.be/abc\n---\nthe summary"
+	if got != want {
+		t.Errorf("renderOutputTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestParseOutputTemplateFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "note.tmpl")
+	if err := os.WriteFile(file, []byte("date: {{.Date}}\n{{.Model}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := parseOutputTemplate(file)
+	if err != nil {
+		t.Fatalf("parseOutputTemplate() error = %v", err)
+	}
+	got, err := renderOutputTemplate(tmpl, newOutputTemplateData(&Flags{}, "llama3", "Ollama", ""))
+	if err != nil {
+		t.Fatalf("renderOutputTemplate() error = %v", err)
+	}
+	want := "date: " + time.Now().Format(time.DateOnly) + "\nllama3"
+	if got != want {
+		t.Errorf("renderOutputTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestOutputTemplateErrors(t *testing.T) {
+	if _, err := parseOutputTemplate("{{.Output"); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+
+	tmpl, err := parseOutputTemplate("{{.Unknown}}")
+	if err != nil {
+		t.Fatalf("parseOutputTemplate() error = %v", err)
+	}
+	if _, err = renderOutputTemplate(tmpl, newOutputTemplateData(&Flags{}, "", "", "")); err == nil || !strings.Contains(err.Error(), "Unknown") {
+		t.Errorf("expected an error for an unknown field, got %v", err)
+	}
+}
+
+func TestSourceURLValue(t *testing.T) {
+	flags := &Flags{ScrapeURL: "https://example.com", sourceURL: "https://feed.example.com/item"}
+	if got := flags.sourceURLValue(); got != "https://feed.example.com/item" {
+		t.Errorf("sourceURLValue() = %q", got)
+	}
+	flags.sourceURL = ""
+	if got := flags.sourceURLValue(); got != "https://example.com" {
+		t.Errorf("sourceURLValue() = %q", got)
+	}
+}
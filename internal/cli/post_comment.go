@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+)
+
+// handlePostComment posts the output as a comment of the issues of --jira and
+// --linear
+func handlePostComment(flags *Flags, registry *core.PluginRegistry, output string) (err error) {
+	if flags.Jira != "" {
+		if err = registry.Jira.PostComment(flags.Jira, output); err != nil {
+			return
+		}
+		debuglog.Log(i18n.T("comment_posted"), "Jira", flags.Jira)
+	}
+	if flags.Linear != "" {
+		if err = registry.Linear.PostComment(flags.Linear, output); err != nil {
+			return
+		}
+		debuglog.Log(i18n.T("comment_posted"), "Linear", flags.Linear)
+	}
+	return
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// isPresetArg reports whether the first positional argument names a preset,
+// like @blog
+func isPresetArg(arg string) bool {
+	return len(arg) > 1 && strings.HasPrefix(arg, "@") && !strings.ContainsAny(arg, " \t\n")
+}
+
+// expandPreset replaces the @name argument with the flags of the preset of the
+// config file, placed first so that the flags of the command line override them
+func expandPreset(cliArgs []string, arg string, presets map[string]map[string]any) (ret []string, err error) {
+	name := strings.TrimPrefix(arg, "@")
+	preset, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf(i18n.T("preset_not_found"), name)
+	}
+	ret = presetArgs(preset)
+	index := slices.Index(cliArgs, arg)
+	ret = append(ret, cliArgs[:index]...)
+	return append(ret, cliArgs[index+1:]...), nil
+}
+
+// presetArgs returns the flags of a preset, whose keys are long flag names.
+// Lists repeat the flag, maps give key:value pairs like --variable and true
+// sets a switch.
+func presetArgs(preset map[string]any) (ret []string) {
+	for _, flag := range slices.Sorted(maps.Keys(preset)) {
+		switch value := preset[flag].(type) {
+		case bool:
+			if value {
+				ret = append(ret, "--"+flag)
+			}
+		case []any:
+			for _, item := range value {
+				ret = append(ret, fmt.Sprintf("--%s=%v", flag, item))
+			}
+		case map[string]any:
+			for _, key := range slices.Sorted(maps.Keys(value)) {
+				ret = append(ret, fmt.Sprintf("--%s=%s:%v", flag, key, value[key]))
+			}
+		case nil:
+		default:
+			ret = append(ret, fmt.Sprintf("--%s=%v", flag, value))
+		}
+	}
+	return
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitWithPreset(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`
+temperature: 0.5
+model: gpt-4o
+presets:
+  blog:
+    pattern: write_essay
+    model: claude-sonnet-4-5
+    temperature: 1.0
+    strategy: cot
+    stream: true
+    variable:
+      tone: casual
+`), 0644))
+
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"cmd", "--config", config, "@blog", "remote", "work"}
+	flags, err := Init()
+	require.NoError(t, err)
+	assert.Equal(t, "write_essay", flags.Pattern)
+	assert.Equal(t, "claude-sonnet-4-5", flags.Model)
+	assert.Equal(t, 1.0, flags.Temperature)
+	assert.Equal(t, "cot", flags.Strategy)
+	assert.True(t, flags.Stream)
+	assert.Equal(t, map[string]string{"tone": "casual"}, flags.PatternVariables)
+	assert.Equal(t, "remote work", flags.Message)
+
+	// The flags of the command line override those of the preset
+	os.Args = []string{"cmd", "--config", config, "@blog", "-m", "gpt-5", "remote work"}
+	flags, err = Init()
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-5", flags.Model)
+	assert.Equal(t, "write_essay", flags.Pattern)
+
+	os.Args = []string{"cmd", "--config", config, "@vlog", "remote work"}
+	_, err = Init()
+	assert.ErrorContains(t, err, "vlog")
+}
+
+func TestPresetArgs(t *testing.T) {
+	got := presetArgs(map[string]any{
+		"pattern":  "summarize",
+		"context":  []any{"persona", "project"},
+		"stream":   true,
+		"copy":     false,
+		"variable": map[string]any{"role": "expert", "points": 30},
+	})
+	want := []string{"--context=persona", "--context=project", "--pattern=summarize", "--stream", "--variable=points:30", "--variable=role:expert"}
+	assert.Equal(t, want, got)
+}
+
+func TestIsPresetArg(t *testing.T) {
+	assert.True(t, isPresetArg("@blog"))
+	assert.False(t, isPresetArg("@"))
+	assert.False(t, isPresetArg("@someone said hi"))
+	assert.False(t, isPresetArg("blog"))
+}
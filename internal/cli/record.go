@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/httprecord"
+)
+
+// configureHTTPRecording records the HTTP requests of the run in the directory
+// of --record, or answers them from the one of --replay. It must run before the
+// vendors are configured, as some of them keep the default transport.
+func configureHTTPRecording(flags *Flags) (err error) {
+	if flags.Record != "" && flags.Replay != "" {
+		return errors.New(i18n.T("record_replay_together"))
+	}
+	var transport *httprecord.Transport
+	switch {
+	case flags.Record != "":
+		transport, err = httprecord.NewRecorder(flags.Record, http.DefaultTransport)
+	case flags.Replay != "":
+		transport, err = httprecord.NewReplayer(flags.Replay)
+	default:
+		return
+	}
+	if err != nil {
+		return
+	}
+	httprecord.Install(transport)
+	return
+}
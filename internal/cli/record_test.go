@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestConfigureHTTPRecording(t *testing.T) {
+	if err := configureHTTPRecording(&Flags{Record: "a", Replay: "b"}); err == nil {
+		t.Error("expected an error for --record with --replay")
+	}
+	if err := configureHTTPRecording(&Flags{Replay: t.TempDir() + "/missing"}); err == nil {
+		t.Error("expected an error for a missing replay directory")
+	}
+
+	previous := http.DefaultTransport
+	defer func() { http.DefaultTransport = previous }()
+	if err := configureHTTPRecording(&Flags{}); err != nil || http.DefaultTransport != previous {
+		t.Errorf("expected the transport to be kept without the flags, got error %v", err)
+	}
+	if err := configureHTTPRecording(&Flags{Record: t.TempDir()}); err != nil || http.DefaultTransport == previous {
+		t.Errorf("expected the recording transport to be installed, got error %v", err)
+	}
+}
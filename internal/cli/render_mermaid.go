@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/tools/mermaid"
+)
+
+// handleRenderMermaid renders the Mermaid diagrams of the output to the
+// --render-mermaid file, the next ones to numbered files next to it
+func handleRenderMermaid(flags *Flags, output string) (err error) {
+	diagrams := mermaid.Diagrams(output)
+	if len(diagrams) == 0 {
+		debuglog.Log("%s\n", i18n.T("render_mermaid_no_diagram"))
+		return
+	}
+	for i, diagram := range diagrams {
+		path := mermaid.OutputPath(flags.RenderMermaid, i)
+		if err = mermaid.Render(diagram, path); err != nil {
+			return
+		}
+		debuglog.Log(i18n.T("render_mermaid_rendered"), path)
+	}
+	return
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+// defaultRerankModels are the models used when --rerank-model is not set, by
+// rerank service.
+var defaultRerankModels = map[string]string{
+	"cohere": "rerank-v3.5",
+	"voyage": "rerank-2.5",
+	"jina":   "jina-reranker-v2-base-multilingual",
+}
+
+// rerankDocument is a document read from the --rerank input.
+type rerankDocument struct {
+	text string
+	// raw is the input line as JSON, returned as is in the output
+	raw json.RawMessage
+}
+
+// rerankResult is a single --rerank output line.
+type rerankResult struct {
+	Index    int             `json:"index"`
+	Score    float64         `json:"score"`
+	Document json.RawMessage `json:"document"`
+}
+
+// handleRerank orders the documents read from stdin, one per line, by relevance
+// to --query and writes them as JSON lines to stdout or the output file.
+func handleRerank(flags *Flags, registry *core.PluginRegistry) (err error) {
+	if strings.TrimSpace(flags.Query) == "" {
+		return errors.New(i18n.T("rerank_query_required"))
+	}
+
+	documents := parseRerankDocuments(flags.Message)
+	if len(documents) == 0 {
+		return errors.New(i18n.T("rerank_no_documents"))
+	}
+
+	vendorName := flags.Vendor
+	if vendorName == "" {
+		vendorName = "Cohere"
+	}
+	var reranker ai.Reranker
+	if reranker, err = findReranker(vendorName, registry); err != nil {
+		return
+	}
+	model := flags.RerankModel
+	if model == "" {
+		model = defaultRerankModels[strings.ToLower(vendorName)]
+	}
+
+	texts := make([]string, len(documents))
+	for i, document := range documents {
+		texts[i] = document.text
+	}
+
+	var results []ai.RerankResult
+	ctx, cancel := flags.requestContext()
+	defer cancel()
+	if results, err = reranker.Rerank(ctx, flags.Query, texts, model, flags.RerankTop); err != nil {
+		return
+	}
+
+	var output string
+	if output, err = formatRerankResults(results, documents); err != nil {
+		return
+	}
+	if flags.Output != "" {
+		return CreateOutputFile(output, flags.Output)
+	}
+	fmt.Println(output)
+	return
+}
+
+// findReranker returns the configured rerank service with the given name.
+func findReranker(vendorName string, registry *core.PluginRegistry) (ai.Reranker, error) {
+	switch strings.ToLower(vendorName) {
+	case "cohere":
+		if registry.Cohere.ApiKey.Value != "" {
+			return registry.Cohere, nil
+		}
+	case "voyage":
+		if registry.Voyage.ApiKey.Value != "" {
+			return registry.Voyage, nil
+		}
+	case "jina":
+		if registry.Jina.ApiKey.Value != "" {
+			return registry.Jina, nil
+		}
+	default:
+		return nil, fmt.Errorf(i18n.T("vendor_no_rerank_support"), vendorName)
+	}
+	return nil, fmt.Errorf(i18n.T("vendor_not_configured"), vendorName)
+}
+
+// parseRerankDocuments reads one document per non-empty line. A line holding a
+// JSON object is reranked on its text, content or document field, a JSON string
+// on its value and any other line on its raw text.
+func parseRerankDocuments(input string) (ret []rerankDocument) {
+	for _, line := range strings.Split(input, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		document := rerankDocument{text: line}
+		var value any
+		if json.Unmarshal([]byte(line), &value) == nil {
+			document.raw = json.RawMessage(line)
+			switch typed := value.(type) {
+			case string:
+				document.text = typed
+			case map[string]any:
+				for _, field := range []string{"text", "content", "document"} {
+					if text, ok := typed[field].(string); ok {
+						document.text = text
+						break
+					}
+				}
+			}
+		} else {
+			document.raw, _ = json.Marshal(line)
+		}
+		ret = append(ret, document)
+	}
+	return
+}
+
+// formatRerankResults renders the reranked documents as JSON lines, most relevant
+// first.
+func formatRerankResults(results []ai.RerankResult, documents []rerankDocument) (ret string, err error) {
+	lines := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.Index < 0 || result.Index >= len(documents) {
+			return "", fmt.Errorf(i18n.T("rerank_invalid_index"), result.Index)
+		}
+		var data []byte
+		if data, err = json.Marshal(rerankResult{
+			Index:    result.Index,
+			Score:    result.Score,
+			Document: documents[result.Index].raw,
+		}); err != nil {
+			return
+		}
+		lines = append(lines, string(data))
+	}
+	return strings.Join(lines, "\n"), nil
+}
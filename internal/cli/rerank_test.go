@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRerankDocuments(t *testing.T) {
+	input := `{"id":1,"text":"first"}
+
+"second"
+plain third
+{"id":4,"content":"fourth"}
+{"id":5}`
+
+	documents := parseRerankDocuments(input)
+	require.Len(t, documents, 5)
+
+	texts := make([]string, len(documents))
+	for i, document := range documents {
+		texts[i] = document.text
+	}
+	assert.Equal(t, []string{"first", "second", "plain third", "fourth", `{"id":5}`}, texts)
+	assert.Equal(t, json.RawMessage(`"plain third"`), documents[2].raw)
+}
+
+func TestFormatRerankResults(t *testing.T) {
+	documents := parseRerankDocuments("{\"id\":1,\"text\":\"first\"}\nsecond")
+
+	output, err := formatRerankResults([]ai.RerankResult{{Index: 1, Score: 0.9}, {Index: 0, Score: 0.1}}, documents)
+	require.NoError(t, err)
+	assert.Equal(t, `{"index":1,"score":0.9,"document":"second"}`+"\n"+
+		`{"index":0,"score":0.1,"document":{"id":1,"text":"first"}}`, output)
+
+	_, err = formatRerankResults([]ai.RerankResult{{Index: 2}}, documents)
+	assert.Error(t, err)
+}
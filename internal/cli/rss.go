@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/tools/rss"
+)
+
+// handleRSSFeed fetches an RSS/Atom feed and processes its latest entries one by one.
+// Each entry gets its own output file named after the entry title; when --output-dir
+// or --output is set it is used as the directory for those files. Entries whose output
+// file already exists are skipped, so re-running against the same feed only processes
+// new entries.
+func handleRSSFeed(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	client := rss.NewClient()
+
+	var feed *rss.Feed
+	if feed, err = client.FetchFeed(currentFlags.RSS); err != nil {
+		return
+	}
+
+	items := feed.Latest(currentFlags.RSSLimit)
+	if len(items) == 0 {
+		return fmt.Errorf(i18n.T("rss_no_items_found"), currentFlags.RSS)
+	}
+
+	outputDir := cmp.Or(currentFlags.OutputDir, currentFlags.Output)
+	if outputDir != "" {
+		if err = os.MkdirAll(outputDir, ConfigDirPerms); err != nil {
+			return fmt.Errorf(i18n.T("error_creating_file"), err)
+		}
+	}
+
+	var tempDir string
+	if currentFlags.RSSTranscribe {
+		if tempDir, err = os.MkdirTemp("", "fabric-rss-"); err != nil {
+			return
+		}
+		defer os.RemoveAll(tempDir)
+	}
+
+	for i, item := range items {
+		outputFile := filepath.Join(outputDir, item.TitleNormalized+".md")
+		if _, statErr := os.Stat(outputFile); statErr == nil {
+			debuglog.Log(i18n.T("rss_skipping_existing_output"), item.Title, outputFile)
+			continue
+		}
+
+		debuglog.Log(i18n.T("rss_processing_item"), i+1, len(items), item.Title)
+
+		var message string
+		if message, err = buildRSSItemMessage(currentFlags, registry, client, item, tempDir); err != nil {
+			return
+		}
+
+		if !currentFlags.IsChatRequest() {
+			if err = WriteOutput(message, outputFile); err != nil {
+				return
+			}
+			continue
+		}
+
+		itemFlags := *currentFlags
+		itemFlags.Message = AppendMessage(currentFlags.Message, message)
+		itemFlags.Output = outputFile
+		itemFlags.sourceURL = item.Link
+		if err = handleChatProcessing(&itemFlags, registry, ""); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// buildRSSItemMessage renders the feed entry as text, appending the transcript of its
+// audio enclosure when --rss-transcribe is set.
+func buildRSSItemMessage(currentFlags *Flags, registry *core.PluginRegistry, client *rss.Client,
+	item *rss.Item, tempDir string) (message string, err error) {
+
+	message = item.FormatAsText()
+
+	if !currentFlags.RSSTranscribe || item.Enclosure == nil || !item.Enclosure.IsAudio() {
+		return
+	}
+
+	var audioFile string
+	if audioFile, err = client.DownloadEnclosure(item, tempDir); err != nil {
+		return
+	}
+	defer os.Remove(audioFile)
+
+	itemFlags := *currentFlags
+	itemFlags.TranscribeFile = audioFile
+
+	var transcript string
+	if transcript, err = handleTranscription(&itemFlags, registry); err != nil {
+		return
+	}
+	message = AppendMessage(message, "Transcript:\n"+transcript)
+	return
+}
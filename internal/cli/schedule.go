@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/cron"
+	"github.com/danielmiessler/fabric/internal/tools/rss"
+	"github.com/danielmiessler/fabric/internal/tools/youtube"
+	"github.com/danielmiessler/fabric/internal/util"
+)
+
+// Sources of the scheduled jobs
+const (
+	sourceYouTube  = "youtube"
+	sourceRSS      = "rss"
+	sourceURL      = "url"
+	sourceReadwise = "readwise"
+	sourceRaindrop = "raindrop"
+)
+
+// ScheduledJob runs a pattern on a source periodically, from the schedules of
+// the YAML config or --schedule
+type ScheduledJob struct {
+	Name string `yaml:"name"`
+	// Cron is when the job runs, e.g. "0 7 * * *" or @hourly
+	Cron string `yaml:"cron"`
+	// Source is youtube:<channel> for its new videos, rss:<feed URL> for its
+	// new entries, url:<page URL>, or readwise:<query> and raindrop:<query>
+	// for the highlights and bookmarks of the query
+	Source  string `yaml:"source"`
+	Pattern string `yaml:"pattern"`
+	// OutputDir and Webhook replace --output-dir and --webhook for the job
+	OutputDir string `yaml:"outputDir"`
+	Webhook   string `yaml:"webhook"`
+
+	schedule *cron.Schedule
+	// since is when the job last ran, older videos of the channel being skipped
+	since time.Time
+}
+
+// parseScheduleFlag parses --schedule "<cron> <source> <pattern>", the cron
+// expression having five fields or being a macro like @daily
+func parseScheduleFlag(value string) (ret ScheduledJob, err error) {
+	fields := strings.Fields(value)
+	cronFields := 5
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		cronFields = 1
+	}
+	if len(fields) != cronFields+2 {
+		return ret, fmt.Errorf(i18n.T("schedule_invalid"), value)
+	}
+	return ScheduledJob{
+		Name:    value,
+		Cron:    strings.Join(fields[:cronFields], " "),
+		Source:  fields[cronFields],
+		Pattern: fields[cronFields+1],
+	}, nil
+}
+
+// scheduledJobs returns the jobs of the config and of --schedule, checked
+func (o *Flags) scheduledJobs() (ret []*ScheduledJob, err error) {
+	for _, job := range o.Schedules {
+		ret = append(ret, &job)
+	}
+	for _, value := range o.Schedule {
+		var job ScheduledJob
+		if job, err = parseScheduleFlag(value); err != nil {
+			return
+		}
+		ret = append(ret, &job)
+	}
+
+	for i, job := range ret {
+		job.Name = cmp.Or(job.Name, fmt.Sprintf("#%d", i+1))
+		if job.schedule, err = cron.Parse(job.Cron); err != nil {
+			return
+		}
+		if kind, _ := splitSource(job.Source); kind == "" {
+			return nil, fmt.Errorf(i18n.T("schedule_invalid_source"), job.Source)
+		}
+		if job.Pattern == "" {
+			return nil, fmt.Errorf(i18n.T("schedule_missing_pattern"), job.Name)
+		}
+		// The config file is not expanded by a shell
+		if job.OutputDir != "" {
+			if job.OutputDir, err = util.GetAbsolutePath(job.OutputDir); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// splitSource returns the kind of the source of a job and its target, plain
+// links being pages
+func splitSource(source string) (kind, target string) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return sourceURL, source
+	}
+	kind, target, _ = strings.Cut(source, ":")
+	switch kind {
+	case sourceYouTube, sourceRSS, sourceURL:
+		if target != "" {
+			return
+		}
+	case sourceReadwise, sourceRaindrop:
+		// The query defaults to the last week
+		return
+	}
+	return "", ""
+}
+
+// runSchedules runs the scheduled jobs until the context is done, one at a
+// time. The jobs failing are logged and run again at their next time.
+func runSchedules(ctx context.Context, flags *Flags, registry *core.PluginRegistry, jobs []*ScheduledJob) {
+	var running sync.Mutex
+	var wg sync.WaitGroup
+	now := time.Now()
+	for _, job := range jobs {
+		job.since = now
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				next := job.schedule.Next(time.Now())
+				if next.IsZero() {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Until(next)):
+				}
+
+				running.Lock()
+				slog.Info("Running scheduled job", "job", job.Name)
+				if err := runScheduledJob(flags, registry, job, next); err != nil {
+					slog.Error("Scheduled job failed", "job", job.Name, "error", err)
+				}
+				running.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// startSchedules runs the scheduled jobs of the config and of --schedule in
+// the background of a server
+func startSchedules(flags *Flags, registry *core.PluginRegistry) (err error) {
+	var jobs []*ScheduledJob
+	if jobs, err = flags.scheduledJobs(); err != nil || len(jobs) == 0 {
+		return
+	}
+	go runSchedules(context.Background(), flags, registry, jobs)
+	return
+}
+
+// runScheduledJob runs the pattern of the job on its source, writing the
+// outputs and posting the webhooks like the command line does
+func runScheduledJob(flags *Flags, registry *core.PluginRegistry, job *ScheduledJob, runAt time.Time) (err error) {
+	jobFlags := *flags
+	jobFlags.Pattern = job.Pattern
+	jobFlags.OutputDir = cmp.Or(job.OutputDir, flags.OutputDir)
+	jobFlags.Webhook = cmp.Or(job.Webhook, flags.Webhook)
+	jobFlags.Message = ""
+	jobFlags.Output = ""
+	// Jobs run in the background, their outputs printed once complete
+	jobFlags.Stream = false
+	jobFlags.Quiet = true
+
+	kind, target := splitSource(job.Source)
+	switch kind {
+	case sourceRSS:
+		jobFlags.RSS = target
+		return handleRSSFeed(&jobFlags, registry)
+	case sourceURL:
+		jobFlags.ScrapeURL = target
+		jobFlags.sourceURL = target
+		return runJobTools(&jobFlags, registry)
+	case sourceReadwise:
+		jobFlags.Readwise = cmp.Or(target, "since:7d")
+		return runJobTools(&jobFlags, registry)
+	case sourceRaindrop:
+		jobFlags.Raindrop = cmp.Or(target, "since:7d")
+		return runJobTools(&jobFlags, registry)
+	}
+
+	var feedURL string
+	if feedURL, err = youtube.ChannelFeedURL(target); err != nil {
+		return
+	}
+	var feed *rss.Feed
+	if feed, err = rss.NewClient().FetchFeed(feedURL); err != nil {
+		return
+	}
+	since := job.since
+	job.since = runAt
+	for _, video := range feed.Latest(0) {
+		if !video.Published.After(since) {
+			break
+		}
+		videoFlags := jobFlags
+		videoFlags.YouTube = video.Link
+		videoFlags.sourceURL = video.Link
+		if err = runJobTools(&videoFlags, registry); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// runJobTools fetches the input of a job with the tools and runs its pattern
+func runJobTools(flags *Flags, registry *core.PluginRegistry) (err error) {
+	var messageTools string
+	if messageTools, err = handleToolProcessing(flags, registry); err != nil {
+		return
+	}
+	return handleChatProcessing(flags, registry, messageTools)
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseScheduleFlag(t *testing.T) {
+	tests := []struct {
+		value, cron, source, pattern string
+	}{
+		{"0 7 * * * youtube:@fabric summarize", "0 7 * * *", "youtube:@fabric", "summarize"},
+		{"@hourly rss:https://example.com/feed extract_wisdom", "@hourly", "rss:https://example.com/feed", "extract_wisdom"},
+		{"@weekly readwise:category:books summarize", "@weekly", "readwise:category:books", "summarize"},
+	}
+	for _, tt := range tests {
+		job, err := parseScheduleFlag(tt.value)
+		if err != nil {
+			t.Errorf("parseScheduleFlag(%q) error = %v", tt.value, err)
+			continue
+		}
+		if job.Cron != tt.cron || job.Source != tt.source || job.Pattern != tt.pattern {
+			t.Errorf("parseScheduleFlag(%q) = %+v", tt.value, job)
+		}
+	}
+
+	for _, value := range []string{"", "0 7 * * * summarize", "@daily rss:https://example.com/feed summarize extra"} {
+		if _, err := parseScheduleFlag(value); err == nil {
+			t.Errorf("parseScheduleFlag(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestScheduledJobs(t *testing.T) {
+	flags := &Flags{
+		Schedules: []ScheduledJob{{Cron: "*/30 * * * *", Source: "https://example.com/status", Pattern: "summarize"}},
+		Schedule:  []string{"@daily youtube:UCabcdefghijklmnopqrstuv extract_wisdom"},
+	}
+	jobs, err := flags.scheduledJobs()
+	if err != nil {
+		t.Fatalf("scheduledJobs() error = %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].Name != "#1" || jobs[0].schedule == nil || jobs[1].schedule == nil {
+		t.Errorf("scheduledJobs() = %+v", jobs)
+	}
+	if kind, target := splitSource(jobs[0].Source); kind != sourceURL || target != "https://example.com/status" {
+		t.Errorf("splitSource() = %q, %q, want the page", kind, target)
+	}
+	if kind, target := splitSource("raindrop:"); kind != sourceRaindrop || target != "" {
+		t.Errorf("splitSource(raindrop:) = %q, %q, want the bookmarks of the last week", kind, target)
+	}
+
+	tests := []struct {
+		name string
+		job  ScheduledJob
+		want string
+	}{
+		{"cron", ScheduledJob{Cron: "0 25 * * *", Source: "rss:https://example.com/feed", Pattern: "summarize"}, "hour"},
+		{"source", ScheduledJob{Cron: "@daily", Source: "ftp:example.com", Pattern: "summarize"}, "ftp:example.com"},
+		{"pattern", ScheduledJob{Name: "digest", Cron: "@daily", Source: "rss:https://example.com/feed"}, "digest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := &Flags{Schedules: []ScheduledJob{tt.job}}
+			if _, err := flags.scheduledJobs(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("scheduledJobs() error = %v, want one about %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+const (
+	searchSourceSession = "session"
+	searchSourceContext = "context"
+
+	// maxSearchHits is the number of excerpts --search-sessions prints
+	maxSearchHits = 20
+	// searchExcerptContext is the number of bytes of text kept on each side of
+	// the match in an excerpt
+	searchExcerptContext = 100
+	// searchEmbedBatch is the number of texts embedded per request, and
+	// searchEmbedLength the bytes of each text embedded
+	searchEmbedBatch  = 64
+	searchEmbedLength = 8000
+)
+
+// searchDoc is a message of a session or a paragraph of a context.
+type searchDoc struct {
+	Source string
+	Name   string
+	Role   string
+	Text   string
+}
+
+// searchHit is a --search-sessions result.
+type searchHit struct {
+	Source  string  `json:"source"`
+	Name    string  `json:"name"`
+	Role    string  `json:"role,omitempty"`
+	Score   float64 `json:"score"`
+	Excerpt string  `json:"excerpt"`
+}
+
+// handleSearchSessions searches the user and assistant messages of the saved
+// sessions and the paragraphs of the contexts for --search-sessions, and prints
+// the best matching excerpts. The search is by words, or by meaning with
+// --embed-model.
+func handleSearchSessions(flags *Flags, registry *core.PluginRegistry) (err error) {
+	var docs []searchDoc
+	if docs, err = collectSearchDocs(registry.Db); err != nil {
+		return
+	}
+
+	var hits []searchHit
+	if flags.EmbedModel == "" {
+		hits = searchFullText(docs, flags.SearchSessions)
+	} else {
+		var embedder ai.Embedder
+		if embedder, err = findEmbedder(flags.Vendor, registry); err != nil {
+			return
+		}
+		ctx, cancel := flags.requestContext()
+		defer cancel()
+		if hits, err = searchSemantic(ctx, embedder, flags.EmbedModel, docs, flags.SearchSessions); err != nil {
+			return
+		}
+	}
+	return printSearchHits(hits, flags.JSON, os.Stdout)
+}
+
+// collectSearchDocs returns the messages of the sessions, without the system
+// and meta ones, and the paragraphs of the contexts.
+func collectSearchDocs(db *fsdb.Db) (ret []searchDoc, err error) {
+	var names []string
+	if names, err = db.Sessions.GetNames(); err != nil {
+		return
+	}
+	for _, name := range names {
+		var messages []*chat.ChatCompletionMessage
+		if err = db.Sessions.LoadAsJson(name, &messages); err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			if message.Role != chat.ChatMessageRoleUser && message.Role != chat.ChatMessageRoleAssistant {
+				continue
+			}
+			text := message.Content
+			for _, part := range message.MultiContent {
+				if part.Type == chat.ChatMessagePartTypeText {
+					text += "\n" + part.Text
+				}
+			}
+			if text = strings.TrimSpace(text); text != "" {
+				ret = append(ret, searchDoc{Source: searchSourceSession, Name: name, Role: message.Role, Text: text})
+			}
+		}
+	}
+
+	if names, err = db.Contexts.GetNames(); err != nil {
+		return
+	}
+	for _, name := range names {
+		var item *fsdb.Context
+		if item, err = db.Contexts.Get(name); err != nil {
+			return nil, err
+		}
+		for paragraph := range strings.SplitSeq(strings.ReplaceAll(item.Content, "\r\n", "\n"), "\n\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				ret = append(ret, searchDoc{Source: searchSourceContext, Name: name, Text: paragraph})
+			}
+		}
+	}
+	return
+}
+
+// searchFullText returns the documents having all the words of the query,
+// ignoring case, the ones with the most occurrences first.
+func searchFullText(docs []searchDoc, query string) (ret []searchHit) {
+	var terms []*regexp.Regexp
+	for _, word := range strings.Fields(query) {
+		terms = append(terms, regexp.MustCompile("(?i)"+regexp.QuoteMeta(word)))
+	}
+	if len(terms) == 0 {
+		return
+	}
+
+	for _, doc := range docs {
+		occurrences := 0
+		for _, term := range terms {
+			found := len(term.FindAllStringIndex(doc.Text, -1))
+			if found == 0 {
+				occurrences = 0
+				break
+			}
+			occurrences += found
+		}
+		if occurrences == 0 {
+			continue
+		}
+		match := terms[0].FindStringIndex(doc.Text)
+		ret = append(ret, searchHit{Source: doc.Source, Name: doc.Name, Role: doc.Role,
+			Score: float64(occurrences), Excerpt: excerpt(doc.Text, match[0], match[1])})
+	}
+	slices.SortStableFunc(ret, func(a, b searchHit) int { return cmp.Compare(b.Score, a.Score) })
+	return ret[:min(len(ret), maxSearchHits)]
+}
+
+// searchSemantic returns the documents closest in meaning to the query, by the
+// cosine similarity of their embeddings.
+func searchSemantic(ctx context.Context, embedder ai.Embedder, model string, docs []searchDoc, query string) (ret []searchHit, err error) {
+	if len(docs) == 0 {
+		return
+	}
+	var queryVectors [][]float64
+	if queryVectors, err = embedder.Embeddings(ctx, []string{query}, model); err != nil {
+		return
+	}
+
+	for start := 0; start < len(docs); start += searchEmbedBatch {
+		batch := docs[start:min(start+searchEmbedBatch, len(docs))]
+		texts := make([]string, len(batch))
+		for i, doc := range batch {
+			texts[i] = truncateUTF8(doc.Text, searchEmbedLength)
+		}
+		var vectors [][]float64
+		if vectors, err = embedder.Embeddings(ctx, texts, model); err != nil {
+			return nil, err
+		}
+		for i, doc := range batch {
+			ret = append(ret, searchHit{Source: doc.Source, Name: doc.Name, Role: doc.Role,
+				Score: cosineSimilarity(queryVectors[0], vectors[i]), Excerpt: excerpt(doc.Text, 0, 0)})
+		}
+	}
+	slices.SortStableFunc(ret, func(a, b searchHit) int { return cmp.Compare(b.Score, a.Score) })
+	return ret[:min(len(ret), maxSearchHits)], nil
+}
+
+// printSearchHits prints the hits with their session or context, or as JSON
+func printSearchHits(hits []searchHit, asJSON bool, out io.Writer) (err error) {
+	if asJSON {
+		if hits == nil {
+			hits = []searchHit{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(hits)
+	}
+
+	if len(hits) == 0 {
+		fmt.Fprintln(out, i18n.T("search_sessions_no_results"))
+		return
+	}
+	for i, hit := range hits {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		if hit.Source == searchSourceSession {
+			fmt.Fprintf(out, i18n.T("search_sessions_session_hit")+"\n", hit.Name, hit.Role)
+		} else {
+			fmt.Fprintf(out, i18n.T("search_sessions_context_hit")+"\n", hit.Name)
+		}
+		fmt.Fprintf(out, "  %s\n", hit.Excerpt)
+	}
+	return
+}
+
+// excerpt returns the text around the match from start to end on one line,
+// with an ellipsis where the text is cut.
+func excerpt(text string, start, end int) string {
+	from := max(0, start-searchExcerptContext)
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	to := min(len(text), end+searchExcerptContext)
+	if start == end {
+		// No match: the beginning of the text
+		to = min(len(text), 2*searchExcerptContext)
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+
+	ret := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		ret = "…" + ret
+	}
+	if to < len(text) {
+		ret += "…"
+	}
+	return ret
+}
+
+// truncateUTF8 cuts the text to at most length bytes without splitting a rune
+func truncateUTF8(text string, length int) string {
+	if len(text) <= length {
+		return text
+	}
+	for length > 0 && !utf8.RuneStart(text[length]) {
+		length--
+	}
+	return text[:length]
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range min(len(a), len(b)) {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectSearchDocs(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	require.NoError(t, os.MkdirAll(db.Sessions.Dir, 0755))
+	require.NoError(t, os.MkdirAll(db.Contexts.Dir, 0755))
+	require.NoError(t, db.Sessions.SaveSession(&fsdb.Session{Name: "dns", Messages: []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "You are a network engineer."},
+		{Role: "meta", Content: "--session dns"},
+		{Role: chat.ChatMessageRoleUser, Content: "How long do resolvers cache answers?"},
+		{Role: chat.ChatMessageRoleAssistant, Content: "For the TTL of the record."},
+	}}))
+	require.NoError(t, os.WriteFile(filepath.Join(db.Contexts.Dir, "persona"), []byte("First paragraph.\r\n\r\nSecond paragraph.\n"), 0644))
+
+	docs, err := collectSearchDocs(db)
+	require.NoError(t, err)
+	assert.Equal(t, []searchDoc{
+		{Source: "session", Name: "dns", Role: "user", Text: "How long do resolvers cache answers?"},
+		{Source: "session", Name: "dns", Role: "assistant", Text: "For the TTL of the record."},
+		{Source: "context", Name: "persona", Text: "First paragraph."},
+		{Source: "context", Name: "persona", Text: "Second paragraph."},
+	}, docs)
+}
+
+func TestSearchFullText(t *testing.T) {
+	docs := []searchDoc{
+		{Source: "session", Name: "dns", Role: "assistant", Text: "Resolvers cache the record for its TTL."},
+		{Source: "context", Name: "notes", Text: strings.Repeat("filler ", 40) + "The TTL of a DNS record, and the TTL again." + strings.Repeat(" filler", 40)},
+		{Source: "session", Name: "cooking", Role: "user", Text: "How long to cache bread dough?"},
+	}
+
+	hits := searchFullText(docs, "ttl RECORD")
+	require.Len(t, hits, 2)
+	assert.Equal(t, "notes", hits[0].Name, "the most occurrences first")
+	assert.Equal(t, 3.0, hits[0].Score)
+	assert.True(t, strings.HasPrefix(hits[0].Excerpt, "…") && strings.HasSuffix(hits[0].Excerpt, "…"), hits[0].Excerpt)
+	assert.Contains(t, hits[0].Excerpt, "The TTL of a DNS record")
+	assert.Equal(t, searchHit{Source: "session", Name: "dns", Role: "assistant", Score: 2,
+		Excerpt: "Resolvers cache the record for its TTL."}, hits[1])
+
+	assert.Empty(t, searchFullText(docs, "  "))
+}
+
+// vectorEmbedder embeds the texts with the vector of the first of its words
+// found in the text
+type vectorEmbedder map[string][]float64
+
+func (o vectorEmbedder) Embeddings(_ context.Context, inputs []string, _ string) (ret [][]float64, err error) {
+	for _, input := range inputs {
+		vector := []float64{0, 0}
+		for word, wordVector := range o {
+			if strings.Contains(input, word) {
+				vector = wordVector
+			}
+		}
+		ret = append(ret, vector)
+	}
+	return
+}
+
+func TestSearchSemantic(t *testing.T) {
+	embedder := vectorEmbedder{"dns": {1, 0}, "bread": {0, 1}, "name servers": {0.9, 0.1}}
+	docs := []searchDoc{
+		{Source: "session", Name: "cooking", Role: "user", Text: "bread"},
+		{Source: "context", Name: "infra", Text: "Our name servers"},
+	}
+
+	hits, err := searchSemantic(context.Background(), embedder, "model", docs, "dns")
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "infra", hits[0].Name)
+	assert.Equal(t, "Our name servers", hits[0].Excerpt)
+	assert.InDelta(t, 0.99, hits[0].Score, 0.01)
+}
+
+func TestPrintSearchHits(t *testing.T) {
+	hits := []searchHit{
+		{Source: "session", Name: "dns", Role: "assistant", Score: 2, Excerpt: "For the TTL of the record."},
+		{Source: "context", Name: "notes", Score: 1, Excerpt: "…the TTL…"},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, printSearchHits(hits, false, &out))
+	assert.Equal(t, "session dns (assistant)\n  For the TTL of the record.\n\ncontext notes\n  …the TTL…\n", out.String())
+
+	out.Reset()
+	require.NoError(t, printSearchHits(nil, true, &out))
+	var decoded []searchHit
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Empty(t, decoded)
+	assert.Equal(t, "[]\n", out.String())
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/util"
+)
+
+// serveReloadInterval is how often the server checks its configuration for
+// changes
+const serveReloadInterval = 2 * time.Second
+
+// watchServeConfig reloads the config file and the .env file into the registry
+// whenever they change while serving, until the context is done. Patterns are
+// read on every request, so their changes are only logged.
+func watchServeConfig(ctx context.Context, flags *Flags, registry *core.PluginRegistry) {
+	paths := []string{flags.Config, registry.Db.EnvFilePath, registry.Db.Patterns.Dir, registry.Db.Patterns.CustomPatternsDir}
+	go util.WatchFiles(ctx, paths, serveReloadInterval, func(changed []string) {
+		if err := reloadServeConfig(flags, registry, changed); err != nil {
+			debuglog.Log(i18n.T("serve_reload_failed"), err)
+		}
+	})
+}
+
+// reloadServeConfig reloads what changed among the files of watchServeConfig.
+// An invalid config keeps the moderation and session limits in use.
+func reloadServeConfig(flags *Flags, registry *core.PluginRegistry, changed []string) (err error) {
+	if slices.Contains(changed, registry.Db.EnvFilePath) {
+		if err = registry.Db.ReloadEnvFile(); err != nil {
+			return
+		}
+		if err = registry.Configure(); err != nil {
+			return
+		}
+		debuglog.Log(i18n.T("serve_reloaded"), registry.Db.EnvFilePath)
+	}
+
+	if flags.Config != "" && slices.Contains(changed, flags.Config) {
+		var yamlFlags *Flags
+		if yamlFlags, err = loadYAMLConfig(flags.Config); err != nil {
+			return
+		}
+		reloaded := *flags
+		reloaded.applyYAMLConfig(yamlFlags)
+
+		moderation, sessionPolicy := registry.Moderation, registry.SessionPolicy
+		registry.Moderation, registry.SessionPolicy = nil, nil
+		if err = configureModeration(&reloaded, registry); err == nil {
+			err = configureSessionPolicy(&reloaded, registry)
+		}
+		if err != nil {
+			registry.Moderation, registry.SessionPolicy = moderation, sessionPolicy
+			return
+		}
+		registry.Hooks = nil
+		configureHooks(&reloaded, registry)
+		debuglog.Log(i18n.T("serve_reloaded"), flags.Config)
+	}
+
+	var patterns []string
+	for _, path := range changed {
+		for _, dir := range []string{registry.Db.Patterns.Dir, registry.Db.Patterns.CustomPatternsDir} {
+			if rel, relErr := filepath.Rel(dir, path); dir != "" && relErr == nil && !strings.HasPrefix(rel, "..") {
+				if name := strings.Split(filepath.ToSlash(rel), "/")[0]; !slices.Contains(patterns, name) {
+					patterns = append(patterns, name)
+				}
+			}
+		}
+	}
+	if len(patterns) > 0 {
+		debuglog.Log(i18n.T("serve_patterns_changed"), strings.Join(patterns, ", "))
+	}
+	return
+}
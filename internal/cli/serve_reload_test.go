@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+func TestReloadServeConfig(t *testing.T) {
+	t.Setenv("DEFAULT_VENDOR", "OpenAI")
+	t.Setenv("DEFAULT_MODEL", "gpt-4o")
+	dir := t.TempDir()
+	db := fsdb.NewDb(dir)
+	if err := db.SaveEnv("DEFAULT_VENDOR=OpenAI\nDEFAULT_MODEL=gpt-4o\n"); err != nil {
+		t.Fatalf("failed to save env: %v", err)
+	}
+	registry, err := core.NewPluginRegistry(db)
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	flags := &Flags{Config: configPath, SessionMaxMessages: 4, usedFlags: map[string]bool{"sessionMaxMessages": true}}
+
+	if err = db.SaveEnv("DEFAULT_VENDOR=Anthropic\nDEFAULT_MODEL=claude-sonnet-4\n"); err != nil {
+		t.Fatalf("failed to save env: %v", err)
+	}
+	if err = os.WriteFile(configPath, []byte("sessionMaxMessages: 10\nsessionMaxTokens: 2000\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err = reloadServeConfig(flags, registry, []string{db.EnvFilePath, configPath}); err != nil {
+		t.Fatalf("reloadServeConfig() error = %v", err)
+	}
+	if got := registry.Defaults.Model.Value; got != "claude-sonnet-4" {
+		t.Errorf("default model = %q, want the reloaded one", got)
+	}
+	// The flag set on the command line wins over the config
+	if policy := registry.SessionPolicy; policy == nil || policy.MaxMessages != 4 || policy.MaxTokens != 2000 {
+		t.Errorf("session policy = %+v, want 4 messages and 2000 tokens", policy)
+	}
+
+	if err = os.WriteFile(configPath, []byte("sessionMaxTokens: -1\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err = reloadServeConfig(flags, registry, []string{configPath}); err == nil {
+		t.Error("expected an error for invalid session limits")
+	}
+	if policy := registry.SessionPolicy; policy == nil || policy.MaxTokens != 2000 {
+		t.Errorf("session policy = %+v, want the previous one kept", policy)
+	}
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// configureSessionPolicy sets up the limits of every session, including those
+// of --serve, when any session limit is given.
+func configureSessionPolicy(flags *Flags, registry *core.PluginRegistry) (err error) {
+	if flags.SessionMaxMessages < 0 || flags.SessionMaxTokens < 0 || flags.SessionTTL < 0 {
+		return errors.New(i18n.T("invalid_session_limits"))
+	}
+	if flags.SessionMaxMessages == 0 && flags.SessionMaxTokens == 0 && flags.SessionTTL == 0 && !flags.SessionSummarize {
+		return
+	}
+	registry.SessionPolicy = &core.SessionPolicy{
+		MaxMessages: flags.SessionMaxMessages,
+		MaxTokens:   flags.SessionMaxTokens,
+		TTL:         flags.SessionTTL,
+		Summarize:   flags.SessionSummarize,
+	}
+	return
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"context"
+
 	"github.com/danielmiessler/fabric/internal/core"
 	restapi "github.com/danielmiessler/fabric/internal/server"
 )
@@ -14,17 +16,65 @@ func handleSetupAndServerCommands(currentFlags *Flags, registry *core.PluginRegi
 		return true, err
 	}
 
+	if currentFlags.IsUnattendedSetup() {
+		err = registry.SetupUnattended(&core.SetupOptions{
+			Vendor:       currentFlags.SetupVendor,
+			APIKey:       currentFlags.SetupKey,
+			BaseURL:      currentFlags.SetupURL,
+			Settings:     currentFlags.SetupSettings,
+			DefaultModel: currentFlags.SetupDefaultModel,
+		})
+		return true, err
+	}
+
 	if currentFlags.Serve {
 		registry.ConfigureVendors()
-		err = restapi.Serve(registry, currentFlags.ServeAddress, currentFlags.ServeAPIKey)
+		watchServeConfig(context.Background(), currentFlags, registry)
+		if err = startSchedules(currentFlags, registry); err != nil {
+			return true, err
+		}
+		var options *restapi.ServeOptions
+		if options, err = currentFlags.ServeOptions(); err != nil {
+			return true, err
+		}
+		err = restapi.Serve(registry, options)
 		return true, err
 	}
 
 	if currentFlags.ServeOllama {
 		registry.ConfigureVendors()
-		err = restapi.ServeOllama(registry, currentFlags.ServeAddress, version)
+		watchServeConfig(context.Background(), currentFlags, registry)
+		if err = startSchedules(currentFlags, registry); err != nil {
+			return true, err
+		}
+		var options *restapi.ServeOptions
+		if options, err = currentFlags.ServeOptions(); err != nil {
+			return true, err
+		}
+		err = restapi.ServeOllama(registry, options, version)
 		return true, err
 	}
 
+	if currentFlags.IsBotServer() {
+		registry.ConfigureVendors()
+		watchServeConfig(context.Background(), currentFlags, registry)
+		if err = startSchedules(currentFlags, registry); err != nil {
+			return true, err
+		}
+		err = serveBots(currentFlags, registry)
+		return true, err
+	}
+
+	// --schedule without a server runs the jobs in the foreground
+	if len(currentFlags.Schedule) > 0 {
+		registry.ConfigureVendors()
+		var jobs []*ScheduledJob
+		if jobs, err = currentFlags.scheduledJobs(); err != nil {
+			return true, err
+		}
+		runSchedules(context.Background(), currentFlags, registry, jobs)
+		return true, nil
+	}
+
 	return false, nil
 }
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+)
+
+const (
+	shellPrompt = "You turn requests into a command for the %s shell on %s. Reply with the command only, " +
+		"without explanation or Markdown, joining several steps into one command line when needed. Later " +
+		"messages give the output of the command you suggested, followed by the next request."
+	shellOutputPrompt = "The command exited with status %d and printed:\n```\n%s\n```\n\n%s"
+	// shellOutputLimit is how much of the end of the output of a command is sent
+	// back to the model
+	shellOutputLimit = 16 * 1024
+)
+
+// shell is the shell that runs the suggested commands
+type shell struct {
+	// name is what the model is told, e.g. bash or powershell
+	name string
+	// command runs a command line when it is appended
+	command []string
+}
+
+// detectShell returns the shell of $SHELL, otherwise PowerShell on Windows and
+// sh elsewhere
+func detectShell(goos string, getenv func(string) string) shell {
+	if path := getenv("SHELL"); path != "" {
+		// Git Bash sets a Windows path
+		name := strings.TrimSuffix(path[strings.LastIndexAny(path, `/\`)+1:], ".exe")
+		return shell{name: name, command: []string{path, "-c"}}
+	}
+	if goos == "windows" {
+		return shell{name: "powershell", command: []string{"powershell", "-NoProfile", "-Command"}}
+	}
+	return shell{name: "sh", command: []string{"/bin/sh", "-c"}}
+}
+
+// handleShell asks the model for a command doing the request of the message,
+// shows it and runs it once confirmed. Its output is sent back to the model
+// with the follow-up request, until no follow-up is entered. Without --session
+// the conversation is kept in a temporary session.
+func handleShell(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	if strings.TrimSpace(currentFlags.Message) == "" {
+		return errors.New(i18n.T("shell_missing_request"))
+	}
+	if !mdrender.IsTerminal(os.Stdin) {
+		return errors.New(i18n.T("shell_requires_terminal"))
+	}
+	currentFlags.resolveModel()
+
+	var chatter *core.Chatter
+	if chatter, err = registry.GetChatter(currentFlags.Model, currentFlags.ModelContextLength,
+		currentFlags.Vendor, false, currentFlags.DryRun); err != nil {
+		return
+	}
+	if err = currentFlags.applyVendorConfig(chatter.ServingVendorName()); err != nil {
+		return
+	}
+	var chatOptions *domain.ChatOptions
+	if chatOptions, err = currentFlags.BuildChatOptions(); err != nil {
+		return
+	}
+
+	sessionName := currentFlags.Session
+	if sessionName == "" {
+		sessionName = fmt.Sprintf("shell-%d", time.Now().UnixNano())
+		if err = registry.Db.Sessions.SaveSession(&fsdb.Session{Name: sessionName}); err != nil {
+			return
+		}
+		defer registry.Db.Sessions.Delete(sessionName)
+	}
+
+	sh := detectShell(runtime.GOOS, os.Getenv)
+	prompt := fmt.Sprintf(shellPrompt, sh.name, runtime.GOOS)
+	meta := strings.Join(os.Args[1:], " ")
+	send := func(message string) (reply string, sendErr error) {
+		turnFlags := *currentFlags
+		turnFlags.Message = message
+		turnFlags.Session = sessionName
+		var chatReq *domain.ChatRequest
+		if chatReq, sendErr = turnFlags.BuildChatRequest(meta); sendErr != nil {
+			return
+		}
+		chatReq.EphemeralContext = prompt
+		if chatReq.Language == "" {
+			chatReq.Language = registry.Language.DefaultLanguage.Value
+		}
+
+		progress := turnFlags.startSpinner(fmt.Sprintf(i18n.T("spinner_waiting_for_model"), chatter.Model()))
+		ctx, cancel := turnFlags.requestContext()
+		defer cancel()
+		var session *fsdb.Session
+		session, sendErr = chatter.Send(ctx, chatReq, chatOptions)
+		progress.Stop()
+		if sendErr != nil {
+			return
+		}
+		return domain.StripThinkBlocks(session.GetLastMessage().Content, chatOptions.ThinkStartTag, chatOptions.ThinkEndTag), nil
+	}
+	execute := func(command string) (string, int, error) {
+		return runShellCommand(sh, command, os.Stdout)
+	}
+	return runShell(os.Stdin, os.Stderr, currentFlags.Message, send, execute)
+}
+
+// runShell runs the loop of --shell: send the request, show the command of the
+// reply and ask what to do with it. Anything else than yes or no revises the
+// command with the answer.
+func runShell(in io.Reader, out io.Writer, request string, send func(message string) (string, error),
+	execute func(command string) (string, int, error)) (err error) {
+
+	reader := bufio.NewReader(in)
+	readLine := func() string {
+		line, _ := reader.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+
+	message := request
+	for {
+		var reply string
+		if reply, err = send(message); err != nil {
+			return
+		}
+		command := shellCommand(reply)
+		if command == "" {
+			return
+		}
+		fmt.Fprintf(out, "\n    %s\n\n", command)
+
+		fmt.Fprint(out, i18n.T("shell_confirm"))
+		answer := readLine()
+		switch strings.ToLower(answer) {
+		case "", "n", "no", "q":
+			return nil
+		case "y", "yes":
+		default:
+			message = answer
+			continue
+		}
+
+		output, status, runErr := execute(command)
+		if runErr != nil {
+			return runErr
+		}
+		fmt.Fprint(out, i18n.T("shell_follow_up"))
+		followUp := readLine()
+		if followUp == "" {
+			return nil
+		}
+		message = fmt.Sprintf(shellOutputPrompt, status, output, followUp)
+	}
+}
+
+// shellCommand returns the command of a reply, without the Markdown code block
+// the models add despite being asked not to
+func shellCommand(reply string) string {
+	reply = strings.TrimSpace(reply)
+	if strings.HasPrefix(reply, "```") {
+		reply = strings.TrimPrefix(reply, "```")
+		// The language of the block
+		if i := strings.IndexByte(reply, '\n'); i >= 0 {
+			reply = reply[i+1:]
+		}
+		reply, _, _ = strings.Cut(reply, "```")
+	}
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(reply), "`"))
+}
+
+// runShellCommand runs the command with the shell, printing its output to out
+// as it comes. It returns the end of the output and the exit status.
+func runShellCommand(sh shell, command string, out io.Writer) (ret string, status int, err error) {
+	cmd := exec.Command(sh.command[0], append(sh.command[1:], command)...)
+	cmd.Stdin = os.Stdin
+	var output bytes.Buffer
+	// The same writer keeps the standard output and error in order
+	cmd.Stdout = io.MultiWriter(out, &output)
+	cmd.Stderr = cmd.Stdout
+	if err = cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", 0, fmt.Errorf(i18n.T("shell_run_error"), command, err)
+		}
+		status, err = exitErr.ExitCode(), nil
+	}
+
+	ret = output.String()
+	if len(ret) > shellOutputLimit {
+		ret = ret[len(ret)-shellOutputLimit:]
+	}
+	return strings.TrimRight(ret, "\n"), status, nil
+}
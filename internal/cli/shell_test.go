@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDetectShell(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	tests := []struct {
+		goos  string
+		env   map[string]string
+		want  string
+		start string
+	}{
+		{"linux", map[string]string{"SHELL": "/usr/bin/zsh"}, "zsh", "/usr/bin/zsh"},
+		{"linux", nil, "sh", "/bin/sh"},
+		{"windows", nil, "powershell", "powershell"},
+		{"windows", map[string]string{"SHELL": `C:\Program Files\Git\bin\bash.exe`}, "bash", `C:\Program Files\Git\bin\bash.exe`},
+	}
+	for _, tt := range tests {
+		got := detectShell(tt.goos, env(tt.env))
+		if got.name != tt.want || got.command[0] != tt.start {
+			t.Errorf("detectShell(%s, %v) = %+v, want %s run by %s", tt.goos, tt.env, got, tt.want, tt.start)
+		}
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	tests := []struct {
+		reply, want string
+	}{
+		{"ls -la\n", "ls -la"},
+		{"```bash\ndu -sh * | sort -h\n```", "du -sh * | sort -h"},
+		{"```\nGet-ChildItem\n```\n", "Get-ChildItem"},
+		{"`pwd`", "pwd"},
+	}
+	for _, tt := range tests {
+		if got := shellCommand(tt.reply); got != tt.want {
+			t.Errorf("shellCommand(%q) = %q, want %q", tt.reply, got, tt.want)
+		}
+	}
+}
+
+func TestRunShell(t *testing.T) {
+	replies := []string{"ls", "ls -S", "rm big.iso"}
+	var sent, executed []string
+	send := func(message string) (string, error) {
+		sent = append(sent, message)
+		reply := replies[0]
+		replies = replies[1:]
+		return reply, nil
+	}
+	execute := func(command string) (string, int, error) {
+		executed = append(executed, command)
+		return "big.iso", 0, nil
+	}
+
+	// Revise the first command, run the second, follow up and decline the third
+	in := strings.NewReader("sort by size\ny\nremove the largest\nn\n")
+	var out bytes.Buffer
+	if err := runShell(in, &out, "list files", send, execute); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(executed) != 1 || executed[0] != "ls -S" {
+		t.Errorf("executed = %q, want only the confirmed command", executed)
+	}
+	if len(sent) != 3 || sent[1] != "sort by size" {
+		t.Fatalf("sent = %q", sent)
+	}
+	if !strings.Contains(sent[2], "status 0") || !strings.Contains(sent[2], "big.iso") || !strings.HasSuffix(sent[2], "remove the largest") {
+		t.Errorf("follow-up = %q, want the output with the request", sent[2])
+	}
+	if !strings.Contains(out.String(), "rm big.iso") {
+		t.Errorf("output %q does not show the last command", out.String())
+	}
+}
+
+func TestRunShellCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("runs sh")
+	}
+	var out bytes.Buffer
+	output, status, err := runShellCommand(detectShell("linux", func(string) string { return "" }), "echo hello; echo oops >&2; exit 3", &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != 3 || output != "hello\noops" {
+		t.Errorf("runShellCommand() = %q, %d, want the output and exit status", output, status)
+	}
+	if out.String() != "hello\noops\n" {
+		t.Errorf("printed %q", out.String())
+	}
+}
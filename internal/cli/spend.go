@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// configureSpend records the spend of every chat, including those of --serve,
+// warning over the spendLimits of the config file.
+func configureSpend(flags *Flags, registry *core.PluginRegistry) (err error) {
+	for name, limit := range flags.SpendLimits {
+		if limit < 0 {
+			return fmt.Errorf(i18n.T("invalid_spend_limit"), name, limit)
+		}
+	}
+	registry.Spend = &core.SpendTracking{Limits: flags.SpendLimits, Pricing: flags.lookupModelPricing}
+	return
+}
+
+// spendPeriod is the spend of a period of the --spend report
+type spendPeriod struct {
+	Period string             `json:"period"`
+	Since  time.Time          `json:"since"`
+	Cost   float64            `json:"cost"`
+	Totals []*fsdb.SpendTotal `json:"totals"`
+}
+
+// printSpend prints the spend of the day, the week and the month of now by
+// vendor and model, or as JSON with --json
+func printSpend(flags *Flags, spend *fsdb.SpendEntity, now time.Time, out io.Writer) (err error) {
+	// The week can start in the previous month
+	from := fsdb.SpendPeriodStart(now, fsdb.SpendPeriodMonth)
+	if weekStart := fsdb.SpendPeriodStart(now, fsdb.SpendPeriodWeek); weekStart.Before(from) {
+		from = weekStart
+	}
+	var entries []fsdb.SpendEntry
+	if entries, err = spend.Since(from); err != nil {
+		return
+	}
+
+	unpriced := false
+	var periods []*spendPeriod
+	for _, period := range []string{fsdb.SpendPeriodDay, fsdb.SpendPeriodWeek, fsdb.SpendPeriodMonth} {
+		since := fsdb.SpendPeriodStart(now, period)
+		var inPeriod []fsdb.SpendEntry
+		for _, entry := range entries {
+			if !entry.Time.Before(since) {
+				inPeriod = append(inPeriod, entry)
+				unpriced = unpriced || entry.Cost == 0
+			}
+		}
+		report := &spendPeriod{Period: period, Since: since, Totals: fsdb.SummarizeSpend(inPeriod)}
+		if report.Totals == nil {
+			report.Totals = []*fsdb.SpendTotal{}
+		}
+		for _, total := range report.Totals {
+			report.Cost += total.Cost
+		}
+		periods = append(periods, report)
+	}
+
+	if flags.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(periods)
+	}
+
+	width := len(i18n.T("spend_report_total"))
+	for _, period := range periods {
+		for _, total := range period.Totals {
+			width = max(width, len(total.Vendor+"|"+total.Model))
+		}
+	}
+	for i, period := range periods {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, i18n.T("spend_report_"+period.Period)+"\n", period.Since.Format(time.DateOnly))
+		if len(period.Totals) == 0 {
+			fmt.Fprintln(out, "  "+i18n.T("spend_report_none"))
+			continue
+		}
+		requests, input, output := 0, 0, 0
+		for _, total := range period.Totals {
+			fmt.Fprintf(out, "  %-*s  "+i18n.T("spend_report_row")+"\n", width, total.Vendor+"|"+total.Model,
+				total.Requests, total.InputTokens, total.OutputTokens, total.Cost)
+			requests += total.Requests
+			input += total.InputTokens
+			output += total.OutputTokens
+		}
+		if len(period.Totals) > 1 {
+			fmt.Fprintf(out, "  %-*s  "+i18n.T("spend_report_row")+"\n", width, i18n.T("spend_report_total"),
+				requests, input, output, period.Cost)
+		}
+	}
+	if unpriced {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, i18n.T("spend_report_unpriced"))
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintSpend(t *testing.T) {
+	spend := &fsdb.SpendEntity{FilePath: filepath.Join(t.TempDir(), "spend.jsonl")}
+	// A Friday
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	for _, entry := range []fsdb.SpendEntry{
+		{Time: now.AddDate(0, 0, -10), Vendor: "OpenAI", Model: "gpt-4o", InputTokens: 1000, OutputTokens: 100, Cost: 0.25},
+		{Time: now.AddDate(0, 0, -2), Vendor: "Ollama", Model: "llama3", InputTokens: 500, OutputTokens: 50},
+		{Time: now.Add(-time.Hour), Vendor: "OpenAI", Model: "gpt-4o", InputTokens: 2000, OutputTokens: 200, Cost: 0.5},
+	} {
+		require.NoError(t, spend.Add(entry))
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, printSpend(&Flags{}, spend, now, &out))
+	assert.Equal(t, `Today (since 2026-10-16)
+  OpenAI|gpt-4o  1 requests, 2000 input and 200 output tokens, $0.5000
+
+This week (since 2026-10-12)
+  OpenAI|gpt-4o  1 requests, 2000 input and 200 output tokens, $0.5000
+  Ollama|llama3  1 requests, 500 input and 50 output tokens, $0.0000
+  Total          2 requests, 2500 input and 250 output tokens, $0.5000
+
+This month (since 2026-10-01)
+  OpenAI|gpt-4o  2 requests, 3000 input and 300 output tokens, $0.7500
+  Ollama|llama3  1 requests, 500 input and 50 output tokens, $0.0000
+  Total          3 requests, 3500 input and 350 output tokens, $0.7500
+
+Requests to models without a known price count as $0: set their price in the modelPricing of the config file.
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, printSpend(&Flags{JSON: true}, spend, now, &out))
+	var periods []spendPeriod
+	require.NoError(t, json.Unmarshal(out.Bytes(), &periods))
+	require.Len(t, periods, 3)
+	assert.Equal(t, "month", periods[2].Period)
+	assert.InDelta(t, 0.75, periods[2].Cost, 1e-9)
+	assert.Len(t, periods[2].Totals, 2)
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+)
+
+const spinnerInterval = 100 * time.Millisecond
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinner shows a spinner with the elapsed time while waiting for a response, so
+// that long calls do not look frozen.
+type spinner struct {
+	out      io.Writer
+	message  string
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// startSpinner starts a spinner on stderr when it is a terminal, unless --quiet
+// is set. The returned spinner may be nil, which Stop accepts.
+func (o *Flags) startSpinner(message string) *spinner {
+	if o.Quiet || !mdrender.IsTerminal(os.Stderr) {
+		return nil
+	}
+	ret := newSpinner(os.Stderr, message, spinnerInterval)
+	ret.start()
+	return ret
+}
+
+func newSpinner(out io.Writer, message string, interval time.Duration) *spinner {
+	return &spinner{out: out, message: message, interval: interval, stop: make(chan struct{})}
+}
+
+func (o *spinner) start() {
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		started := time.Now()
+		ticker := time.NewTicker(o.interval)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			elapsed := time.Since(started).Truncate(time.Second)
+			fmt.Fprintf(o.out, "\r\033[K%s %s (%s)", spinnerFrames[frame%len(spinnerFrames)], o.message, elapsed)
+			select {
+			case <-o.stop:
+				fmt.Fprint(o.out, "\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the spinner and clears its line.
+func (o *spinner) Stop() {
+	if o == nil {
+		return
+	}
+	close(o.stop)
+	o.wg.Wait()
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *syncBuffer) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+func (o *syncBuffer) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.String()
+}
+
+func TestSpinner(t *testing.T) {
+	var out syncBuffer
+	progress := newSpinner(&out, "Waiting for gpt-5", time.Millisecond)
+	progress.start()
+	time.Sleep(20 * time.Millisecond)
+	progress.Stop()
+
+	got := out.String()
+	if !strings.Contains(got, spinnerFrames[0]+" Waiting for gpt-5 (0s)") {
+		t.Errorf("spinner output %q does not show the message and elapsed time", got)
+	}
+	if !strings.Contains(got, spinnerFrames[1]) {
+		t.Errorf("spinner output %q does not animate", got)
+	}
+	if !strings.HasSuffix(got, "\r\033[K") {
+		t.Errorf("spinner output %q does not end by clearing the line", got)
+	}
+}
+
+func TestSpinnerStopNil(t *testing.T) {
+	var progress *spinner
+	progress.Stop()
+
+	if (&Flags{Quiet: true}).startSpinner("message") != nil {
+		t.Error("expected no spinner with --quiet")
+	}
+}
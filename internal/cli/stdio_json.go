@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// stdioRequest is a line of the stdin of --stdio-json. The fields left empty
+// keep the value of the flags.
+type stdioRequest struct {
+	// ID is echoed in the responses to match them with the request
+	ID        json.RawMessage   `json:"id,omitempty"`
+	Message   string            `json:"message"`
+	Pattern   string            `json:"pattern,omitempty"`
+	Context   string            `json:"context,omitempty"`
+	Session   string            `json:"session,omitempty"`
+	Strategy  string            `json:"strategy,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	Vendor    string            `json:"vendor,omitempty"`
+	Language  string            `json:"language,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+	// Stream sends the content of the response as it comes before the complete response
+	Stream bool `json:"stream,omitempty"`
+}
+
+// stdioResponse is a line of the stdout of --stdio-json, typed like the
+// responses of the REST API: "content", "usage", "error" or "complete". Every
+// request ends with a "complete" or "error" response.
+type stdioResponse struct {
+	ID      json.RawMessage       `json:"id,omitempty"`
+	Type    string                `json:"type"`
+	Content string                `json:"content,omitempty"`
+	Usage   *domain.UsageMetadata `json:"usage,omitempty"`
+}
+
+// handleStdioJSON answers the chat requests read from stdin one after the
+// other until stdin is closed, so that fabric can run as a long-lived child
+// process of a notebook or an editor.
+func handleStdioJSON(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	meta := strings.Join(os.Args[1:], " ")
+	send := func(req *stdioRequest, update func(domain.StreamUpdate)) (reply string, sendErr error) {
+		turnFlags := *currentFlags
+		turnFlags.applyStdioRequest(req)
+		turnFlags.resolveModel()
+
+		var chatter *core.Chatter
+		if chatter, sendErr = registry.GetChatter(turnFlags.Model, turnFlags.ModelContextLength,
+			turnFlags.Vendor, req.Stream, turnFlags.DryRun); sendErr != nil {
+			return
+		}
+		if sendErr = turnFlags.applyVendorConfig(chatter.ServingVendorName()); sendErr != nil {
+			return
+		}
+		var chatReq *domain.ChatRequest
+		if chatReq, sendErr = turnFlags.BuildChatRequest(meta); sendErr != nil {
+			return
+		}
+		if chatReq.Language == "" {
+			chatReq.Language = registry.Language.DefaultLanguage.Value
+		}
+		var chatOptions *domain.ChatOptions
+		if chatOptions, sendErr = turnFlags.BuildChatOptions(); sendErr != nil {
+			return
+		}
+		// Stdout is for the responses only
+		chatOptions.Quiet = true
+
+		if req.Stream {
+			updates := make(chan domain.StreamUpdate)
+			forwarded := make(chan struct{})
+			go func() {
+				defer close(forwarded)
+				for u := range updates {
+					update(u)
+				}
+			}()
+			chatOptions.UpdateChan = updates
+			defer func() {
+				close(updates)
+				<-forwarded
+			}()
+		}
+
+		ctx, cancel := turnFlags.requestContext()
+		defer cancel()
+		var session *fsdb.Session
+		if session, sendErr = chatter.Send(ctx, chatReq, chatOptions); sendErr != nil {
+			return
+		}
+		return domain.StripThinkBlocks(session.GetLastMessage().Content, chatOptions.ThinkStartTag, chatOptions.ThinkEndTag), nil
+	}
+	return runStdioJSON(os.Stdin, os.Stdout, send)
+}
+
+// applyStdioRequest overrides the flags with the fields set in the request
+func (o *Flags) applyStdioRequest(req *stdioRequest) {
+	o.Message = req.Message
+	// The output options of the flags apply to the terminal, not to the responses
+	o.Output, o.OutputDir, o.Copy = "", "", false
+	if req.Pattern != "" {
+		o.Pattern = req.Pattern
+	}
+	if req.Context != "" {
+		o.Context = strings.Split(req.Context, ",")
+	}
+	if req.Session != "" {
+		o.Session = req.Session
+	}
+	if req.Strategy != "" {
+		o.Strategy = req.Strategy
+	}
+	if req.Model != "" {
+		o.setModelSpec(req.Model)
+	}
+	if req.Vendor != "" {
+		o.Vendor = req.Vendor
+	}
+	if req.Language != "" {
+		o.Language = req.Language
+	}
+	if len(req.Variables) > 0 {
+		variables := make(map[string]string, len(o.PatternVariables)+len(req.Variables))
+		for name, value := range o.PatternVariables {
+			variables[name] = value
+		}
+		for name, value := range req.Variables {
+			variables[name] = value
+		}
+		o.PatternVariables = variables
+	}
+}
+
+// runStdioJSON reads a request per line of in and writes its responses to out.
+// A request that fails gets an "error" response and the next one is read.
+func runStdioJSON(in io.Reader, out io.Writer, send func(req *stdioRequest, update func(domain.StreamUpdate)) (string, error)) (err error) {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+
+	scanner := bufio.NewScanner(in)
+	// Requests carry whole documents
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req stdioRequest
+		if decodeErr := json.Unmarshal([]byte(line), &req); decodeErr != nil {
+			if err = encoder.Encode(stdioResponse{Type: "error", Content: fmt.Sprintf(i18n.T("stdio_json_invalid_request"), decodeErr)}); err != nil {
+				return
+			}
+			continue
+		}
+
+		var writeErr error
+		update := func(u domain.StreamUpdate) {
+			response := stdioResponse{ID: req.ID, Content: u.Content}
+			switch u.Type {
+			case domain.StreamTypeContent:
+				response.Type = "content"
+			case domain.StreamTypeUsage:
+				response.Type, response.Usage = "usage", u.Usage
+			default:
+				// Errors end the request with its error response
+				return
+			}
+			if writeErr == nil {
+				writeErr = encoder.Encode(response)
+			}
+		}
+
+		reply, sendErr := send(&req, update)
+		if writeErr != nil {
+			return writeErr
+		}
+		response := stdioResponse{ID: req.ID, Type: "complete", Content: reply}
+		if sendErr != nil {
+			response = stdioResponse{ID: req.ID, Type: "error", Content: sendErr.Error()}
+		}
+		if err = encoder.Encode(response); err != nil {
+			return
+		}
+	}
+	return scanner.Err()
+}
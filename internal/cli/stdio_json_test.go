@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+func TestRunStdioJSON(t *testing.T) {
+	var sent []stdioRequest
+	send := func(req *stdioRequest, update func(domain.StreamUpdate)) (string, error) {
+		sent = append(sent, *req)
+		if req.Message == "fail" {
+			return "", errors.New("no model")
+		}
+		if req.Stream {
+			update(domain.StreamUpdate{Type: domain.StreamTypeContent, Content: "Hel"})
+			update(domain.StreamUpdate{Type: domain.StreamTypeContent, Content: "lo"})
+		}
+		return "Hello", nil
+	}
+
+	in := strings.NewReader(`{"id":1,"message":"hi","pattern":"summarize","variables":{"role":"expert"}}` + "\n" +
+		"\n" +
+		"not json\n" +
+		`{"id":"b","message":"fail"}` + "\n" +
+		`{"id":3,"message":"hi","stream":true}` + "\n")
+	var out bytes.Buffer
+	if err := runStdioJSON(in, &out, send); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != 3 || sent[0].Pattern != "summarize" || sent[0].Variables["role"] != "expert" {
+		t.Fatalf("sent = %+v", sent)
+	}
+	want := []string{
+		`{"id":1,"type":"complete","content":"Hello"}`,
+		`{"type":"error","content":"invalid request: invalid character 'o' in literal null (expecting 'u')"}`,
+		`{"id":"b","type":"error","content":"no model"}`,
+		`{"id":3,"type":"content","content":"Hel"}`,
+		`{"id":3,"type":"content","content":"lo"}`,
+		`{"id":3,"type":"complete","content":"Hello"}`,
+	}
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("responses = %q, want %q", got, want)
+	}
+	for i := range want {
+		if !json.Valid([]byte(got[i])) || got[i] != want[i] {
+			t.Errorf("response %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestApplyStdioRequest(t *testing.T) {
+	flags := &Flags{
+		Pattern:          "summarize",
+		Vendor:           "OpenAI",
+		Output:           "out.md",
+		PatternVariables: map[string]string{"lang": "en", "role": "writer"},
+	}
+	flags.applyStdioRequest(&stdioRequest{
+		Message:   "text",
+		Context:   "a,b",
+		Model:     "Anthropic|claude-sonnet-4",
+		Variables: map[string]string{"role": "expert"},
+	})
+
+	if flags.Message != "text" || flags.Pattern != "summarize" || flags.Output != "" {
+		t.Errorf("flags = %+v", flags)
+	}
+	if len(flags.Context) != 2 || flags.Context[1] != "b" {
+		t.Errorf("Context = %q, want [a b]", flags.Context)
+	}
+	if flags.Model != "claude-sonnet-4" || flags.Vendor != "OpenAI" {
+		t.Errorf("model = %s|%s, want the vendor of the flags kept", flags.Vendor, flags.Model)
+	}
+	if flags.PatternVariables["lang"] != "en" || flags.PatternVariables["role"] != "expert" {
+		t.Errorf("PatternVariables = %v, want the request ones over the flag ones", flags.PatternVariables)
+	}
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/tools/stix"
+)
+
+// handleSTIX writes the IOCs, techniques and CVEs of the output to the
+// --stix file as a STIX 2.1 bundle, and pushes it to MISP with --misp
+func handleSTIX(flags *Flags, registry *core.PluginRegistry, output string) (err error) {
+	name := "fabric"
+	if flags.Pattern != "" {
+		name += " " + flags.Pattern
+	}
+	var bundle *stix.Bundle
+	if bundle, err = stix.Extract(output).Bundle(name, output, time.Now()); err != nil {
+		return
+	}
+	if flags.STIX != "" {
+		if err = stix.Write(bundle, flags.STIX); err != nil {
+			return
+		}
+		debuglog.Log(i18n.T("stix_written"), len(bundle.Objects), flags.STIX)
+	}
+	if flags.MISP {
+		var eventURL string
+		if eventURL, err = registry.MISP.Push(bundle); err != nil {
+			return
+		}
+		debuglog.Log(i18n.T("misp_pushed"), eventURL)
+	}
+	return
+}
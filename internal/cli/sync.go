@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/remotesync"
+)
+
+const (
+	syncPush = "push"
+	syncPull = "pull"
+)
+
+// handleSync pushes or pulls the custom patterns, sessions and contexts to or
+// from the sync backend.
+func handleSync(flags *Flags, registry *core.PluginRegistry) (err error) {
+	if flags.Sync != syncPush && flags.Sync != syncPull {
+		return fmt.Errorf(i18n.T("invalid_sync_direction"), flags.Sync)
+	}
+	if !registry.Sync.IsConfigured() {
+		return fmt.Errorf("%s", i18n.T("sync_not_configured"))
+	}
+
+	ctx, cancel := flags.requestContext()
+	defer cancel()
+
+	db := registry.Db
+	syncer := &remotesync.Syncer{
+		Dirs: map[string]string{
+			"sessions": db.Sessions.Dir,
+			"contexts": db.Contexts.Dir,
+		},
+		StatePath: db.FilePath("sync_state.json"),
+	}
+	if db.Patterns.CustomPatternsDir != "" {
+		syncer.Dirs["patterns"] = db.Patterns.CustomPatternsDir
+	}
+	if syncer.Backend, err = registry.Sync.NewBackend(ctx, db.FilePath("sync")); err != nil {
+		return
+	}
+
+	var result *remotesync.Result
+	if flags.Sync == syncPush {
+		result, err = syncer.Push(ctx)
+	} else {
+		result, err = syncer.Pull(ctx)
+	}
+	if err != nil {
+		return
+	}
+
+	for _, name := range result.Transferred {
+		fmt.Printf("%s %s\n", flags.Sync, name)
+	}
+	for _, name := range result.Conflicts {
+		if flags.Sync == syncPush {
+			fmt.Printf(i18n.T("sync_conflict_push"), name)
+		} else {
+			fmt.Printf(i18n.T("sync_conflict_pull"), name, name+remotesync.ConflictSuffix)
+		}
+	}
+	if len(result.Conflicts) > 0 {
+		return fmt.Errorf(i18n.T("sync_error_conflicts"), len(result.Conflicts))
+	}
+	fmt.Printf(i18n.T("sync_done"), len(result.Transferred))
+	return
+}
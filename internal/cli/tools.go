@@ -6,10 +6,16 @@ import (
 
 	"github.com/danielmiessler/fabric/internal/core"
 	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/issues"
+	"github.com/danielmiessler/fabric/internal/tools/logs"
+	"github.com/danielmiessler/fabric/internal/tools/meetings"
+	"github.com/danielmiessler/fabric/internal/tools/scans"
+	"github.com/danielmiessler/fabric/internal/tools/scraper"
 	"github.com/danielmiessler/fabric/internal/tools/youtube"
 )
 
-// handleToolProcessing handles YouTube and web scraping tool processing
+// handleToolProcessing handles the input tools: YouTube, scraping and the
+// connectors
 func handleToolProcessing(currentFlags *Flags, registry *core.PluginRegistry) (messageTools string, err error) {
 	if currentFlags.YouTube != "" {
 		if !registry.YouTube.IsConfigured() {
@@ -59,14 +65,11 @@ func handleToolProcessing(currentFlags *Flags, registry *core.PluginRegistry) (m
 	}
 
 	if currentFlags.ScrapeURL != "" || currentFlags.ScrapeQuestion != "" {
-		if !registry.Jina.IsConfigured() {
-			err = errors.New(i18n.T("scraping_not_configured"))
-			return
-		}
-		// Check if the scrape_url flag is set and call ScrapeURL
+		// Check if the scrape_url flag is set and call ScrapeURL, falling back to the
+		// built-in scraper when Jina is not configured
 		if currentFlags.ScrapeURL != "" {
 			var website string
-			if website, err = registry.Jina.ScrapeURL(currentFlags.ScrapeURL); err != nil {
+			if website, err = scrapeURL(currentFlags, registry); err != nil {
 				return
 			}
 			messageTools = AppendMessage(messageTools, website)
@@ -74,6 +77,10 @@ func handleToolProcessing(currentFlags *Flags, registry *core.PluginRegistry) (m
 
 		// Check if the scrape_question flag is set and call ScrapeQuestion
 		if currentFlags.ScrapeQuestion != "" {
+			if !registry.Jina.IsConfigured() {
+				err = errors.New(i18n.T("scraping_not_configured"))
+				return
+			}
 			var website string
 			if website, err = registry.Jina.ScrapeQuestion(currentFlags.ScrapeQuestion); err != nil {
 				return
@@ -109,5 +116,143 @@ func handleToolProcessing(currentFlags *Flags, registry *core.PluginRegistry) (m
 		}
 	}
 
+	// Handle the Readwise highlights and Raindrop.io bookmarks
+	if currentFlags.Readwise != "" || currentFlags.Raindrop != "" {
+		if currentFlags.Readwise != "" {
+			var highlights string
+			if highlights, err = registry.Readwise.Highlights(currentFlags.Readwise); err != nil {
+				return
+			}
+			messageTools = AppendMessage(messageTools, highlights)
+		}
+
+		if currentFlags.Raindrop != "" {
+			var bookmarks string
+			if bookmarks, err = registry.Raindrop.Bookmarks(currentFlags.Raindrop); err != nil {
+				return
+			}
+			messageTools = AppendMessage(messageTools, bookmarks)
+		}
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
+	// Handle the Notion page
+	if currentFlags.NotionPage != "" {
+		var page string
+		if page, err = registry.Notion.ReadPage(currentFlags.NotionPage); err != nil {
+			return
+		}
+		messageTools = AppendMessage(messageTools, page)
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
+	// Handle the Jira and Linear issues
+	if currentFlags.Jira != "" || currentFlags.Linear != "" {
+		if currentFlags.Jira != "" {
+			var issue *issues.Issue
+			if issue, err = registry.Jira.Issue(currentFlags.Jira); err != nil {
+				return
+			}
+			messageTools = AppendMessage(messageTools, issue.Markdown())
+		}
+
+		if currentFlags.Linear != "" {
+			var issue *issues.Issue
+			if issue, err = registry.Linear.Issue(currentFlags.Linear); err != nil {
+				return
+			}
+			messageTools = AppendMessage(messageTools, issue.Markdown())
+		}
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
+	// Handle the meeting transcript
+	if currentFlags.Meeting != "" {
+		var transcript meetings.Transcript
+		if transcript, err = meetingTranscript(currentFlags.Meeting, registry); err != nil {
+			return
+		}
+		messageTools = AppendMessage(messageTools, transcript.Markdown())
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
+	// Handle the Kubernetes state
+	if currentFlags.K8s != "" {
+		var state string
+		if state, err = collectK8s(currentFlags); err != nil {
+			return
+		}
+		messageTools = AppendMessage(messageTools, state)
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
+	// Handle the log file
+	if currentFlags.Logs != "" {
+		var options logs.Options
+		if options, err = logsOptions(currentFlags); err != nil {
+			return
+		}
+		var summary string
+		if summary, err = logs.ReadFile(currentFlags.Logs, options); err != nil {
+			return
+		}
+		messageTools = AppendMessage(messageTools, summary)
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
+	// Handle the security tool reports
+	if len(currentFlags.Scan) > 0 {
+		for _, path := range currentFlags.Scan {
+			var report string
+			if report, err = scans.ReadFile(path); err != nil {
+				return
+			}
+			messageTools = AppendMessage(messageTools, report)
+		}
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
 	return
 }
+
+// scrapeURL scrapes --scrape_url with Jina AI when it is configured, otherwise (or when
+// --scrape-native/--scrape-js is set) with the built-in readability scraper.
+func scrapeURL(currentFlags *Flags, registry *core.PluginRegistry) (ret string, err error) {
+	if registry.Jina.IsConfigured() && !currentFlags.ScrapeNative && !currentFlags.ScrapeJS {
+		return registry.Jina.ScrapeURL(currentFlags.ScrapeURL)
+	}
+
+	client := scraper.NewClient()
+	client.RenderJS = currentFlags.ScrapeJS
+	client.NoSandbox = currentFlags.ScrapeNoSandbox
+	client.Markdown = currentFlags.MarkdownOptions()
+	return client.ScrapeURL(currentFlags.ScrapeURL)
+}
@@ -31,7 +31,9 @@ func handleTranscription(flags *Flags, registry *core.PluginRegistry) (message s
 	if model == "" {
 		return "", errors.New(i18n.T("transcription_model_required"))
 	}
-	if message, err = tr.TranscribeFile(context.Background(), flags.TranscribeFile, model, flags.SplitMediaFile); err != nil {
+	ctx, cancel := flags.requestContext()
+	defer cancel()
+	if message, err = tr.TranscribeFile(ctx, flags.TranscribeFile, model, flags.SplitMediaFile); err != nil {
 		return
 	}
 	return
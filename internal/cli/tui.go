@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+	"github.com/danielmiessler/fabric/internal/tools/tui"
+)
+
+// tuiStep is a picker of --tui choosing one setting of the chat
+type tuiStep struct {
+	title string
+	// skip is set when the setting is given by a flag
+	skip  bool
+	items func() ([]tui.Item, error)
+	apply func(flags *Flags, item *tui.Item)
+}
+
+// handleTUI runs --tui: pick a pattern, previewed with its README, then the
+// model, context and session, type or paste the input and watch the streamed
+// output, then go back to the patterns. The settings given by flags are not
+// asked, and an input given on the command line is reused for every pattern.
+func handleTUI(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	if !mdrender.IsTerminal(os.Stdin) || !mdrender.IsTerminal(os.Stdout) {
+		return errors.New(i18n.T("tui_requires_terminal"))
+	}
+	steps := tuiSteps(currentFlags, registry)
+	for {
+		runFlags := *currentFlags
+		var picked bool
+		if picked, err = runTUISteps(steps, &runFlags, pickInTerminal); err != nil || !picked {
+			return
+		}
+
+		if strings.TrimSpace(runFlags.Message) == "" {
+			input := tui.NewInput(fmt.Sprintf(i18n.T("tui_input_prompt"), runFlags.Pattern))
+			var result tui.Result
+			if runFlags.Message, result, err = tui.ReadInput(input, os.Stdin, os.Stdout); err != nil || result == tui.Quit {
+				return
+			} else if result == tui.Back {
+				continue
+			}
+		}
+		runFlags.Stream = true
+		if chatErr := handleChatProcessing(&runFlags, registry, ""); chatErr != nil {
+			fmt.Fprintln(os.Stderr, chatErr)
+		}
+
+		fmt.Fprintln(os.Stderr)
+		var again bool
+		if again, err = tui.Again(i18n.T("tui_again"), os.Stdin, os.Stderr); err != nil || !again {
+			return
+		}
+	}
+}
+
+// pickInTerminal shows the picker full screen
+func pickInTerminal(picker *tui.Picker) (tui.Result, error) {
+	return tui.Pick(picker, os.Stdin, os.Stdout)
+}
+
+// runTUISteps runs the pickers of the steps not skipped, applying the chosen
+// items to the flags. Escape goes back to the previous step, and leaves the
+// first one. It returns whether every step was picked.
+func runTUISteps(steps []tuiStep, flags *Flags, pick func(*tui.Picker) (tui.Result, error)) (picked bool, err error) {
+	first := nextTUIStep(steps, -1, 1)
+	for i := first; i < len(steps); {
+		step := steps[i]
+		var items []tui.Item
+		if items, err = step.items(); err != nil {
+			return
+		}
+		picker := tui.NewPicker(step.title, items)
+		var result tui.Result
+		if result, err = pick(picker); err != nil {
+			return
+		}
+		switch result {
+		case tui.Selected:
+			step.apply(flags, picker.Selected())
+			i = nextTUIStep(steps, i, 1)
+		case tui.Back:
+			if i == first {
+				return false, nil
+			}
+			i = nextTUIStep(steps, i, -1)
+		default:
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// nextTUIStep returns the index of the next step not skipped in the direction
+func nextTUIStep(steps []tuiStep, i, delta int) int {
+	i += delta
+	for i >= 0 && i < len(steps) && steps[i].skip {
+		i += delta
+	}
+	return i
+}
+
+// tuiSteps returns the pickers of the pattern, model, context and session
+func tuiSteps(flags *Flags, registry *core.PluginRegistry) []tuiStep {
+	db := registry.Db
+	var models []tui.Item
+	return []tuiStep{
+		{
+			title: i18n.T("tui_pick_pattern"),
+			skip:  flags.Pattern != "",
+			items: func() (ret []tui.Item, err error) {
+				var names []string
+				if names, err = db.Patterns.GetNames(); err != nil {
+					return
+				}
+				for _, name := range names {
+					ret = append(ret, tui.Item{Value: name, Preview: func() string {
+						if readme, readErr := db.Patterns.GetReadme(name); readErr == nil && readme != "" {
+							return readme
+						}
+						if pattern, readErr := db.Patterns.GetRaw(name); readErr == nil {
+							return pattern.Pattern
+						}
+						return ""
+					}})
+				}
+				if len(ret) == 0 {
+					err = errors.New(i18n.T("tui_no_patterns"))
+				}
+				return
+			},
+			apply: func(flags *Flags, item *tui.Item) { flags.Pattern = item.Value },
+		},
+		{
+			title: i18n.T("tui_pick_model"),
+			skip:  flags.Model != "",
+			items: func() (ret []tui.Item, err error) {
+				if models != nil {
+					return models, nil
+				}
+				var vendorsModels *ai.VendorsModels
+				if vendorsModels, err = registry.VendorManager.GetModels(); err != nil {
+					return
+				}
+				ret = append(ret, tui.Item{Label: fmt.Sprintf(i18n.T("tui_default_model"),
+					registry.Defaults.Model.Value), Detail: registry.Defaults.Vendor.Value})
+				for _, group := range vendorsModels.GroupsItems {
+					for _, model := range group.Items {
+						ret = append(ret, tui.Item{Value: model, Detail: group.Group})
+					}
+				}
+				models = ret
+				return
+			},
+			apply: func(flags *Flags, item *tui.Item) {
+				if item.Value != "" {
+					flags.Model, flags.Vendor = item.Value, item.Detail
+				}
+			},
+		},
+		{
+			title: i18n.T("tui_pick_context"),
+			skip:  len(flags.Context) > 0,
+			items: func() (ret []tui.Item, err error) {
+				var names []string
+				if names, err = db.Contexts.GetNames(); err != nil {
+					return
+				}
+				ret = append(ret, tui.Item{Label: i18n.T("tui_none")})
+				for _, name := range names {
+					ret = append(ret, tui.Item{Value: name, Preview: func() string {
+						if context, readErr := db.Contexts.Get(name); readErr == nil {
+							return context.Content
+						}
+						return ""
+					}})
+				}
+				return
+			},
+			apply: func(flags *Flags, item *tui.Item) {
+				if item.Value != "" {
+					flags.Context = []string{item.Value}
+				}
+			},
+		},
+		{
+			title: i18n.T("tui_pick_session"),
+			skip:  flags.Session != "",
+			items: func() (ret []tui.Item, err error) {
+				var names []string
+				if names, err = db.Sessions.GetNames(); err != nil {
+					return
+				}
+				ret = append(ret, tui.Item{Label: i18n.T("tui_none")})
+				for _, name := range names {
+					ret = append(ret, tui.Item{Value: name, Preview: func() string {
+						if session, readErr := db.Sessions.Get(name); readErr == nil {
+							return session.String()
+						}
+						return ""
+					}})
+				}
+				return
+			},
+			apply: func(flags *Flags, item *tui.Item) { flags.Session = item.Value },
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/tools/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTUIStep(title string, skip bool, values ...string) tuiStep {
+	return tuiStep{
+		title: title,
+		skip:  skip,
+		items: func() (ret []tui.Item, err error) {
+			for _, value := range values {
+				ret = append(ret, tui.Item{Value: value})
+			}
+			return
+		},
+		apply: func(flags *Flags, item *tui.Item) {
+			switch title {
+			case "pattern":
+				flags.Pattern = item.Value
+			case "model":
+				flags.Model = item.Value
+			case "session":
+				flags.Session = item.Value
+			}
+		},
+	}
+}
+
+// scriptedPick answers the pickers in turn, recording their titles
+func scriptedPick(titles *[]string, results ...tui.Result) func(*tui.Picker) (tui.Result, error) {
+	return func(picker *tui.Picker) (tui.Result, error) {
+		*titles = append(*titles, picker.Title)
+		if len(results) == 0 {
+			return tui.Quit, errors.New("no more answers")
+		}
+		result := results[0]
+		results = results[1:]
+		return result, nil
+	}
+}
+
+func TestRunTUISteps(t *testing.T) {
+	steps := []tuiStep{
+		testTUIStep("pattern", false, "summarize"),
+		testTUIStep("model", true, "gpt-4o"),
+		testTUIStep("session", false, "notes"),
+	}
+
+	var titles []string
+	flags := &Flags{}
+	picked, err := runTUISteps(steps, flags, scriptedPick(&titles, tui.Selected, tui.Back, tui.Selected, tui.Selected))
+	require.NoError(t, err)
+	assert.True(t, picked)
+	// The skipped step is passed in both directions
+	assert.Equal(t, []string{"pattern", "session", "pattern", "session"}, titles)
+	assert.Equal(t, "summarize", flags.Pattern)
+	assert.Empty(t, flags.Model)
+	assert.Equal(t, "notes", flags.Session)
+}
+
+func TestRunTUIStepsLeft(t *testing.T) {
+	steps := []tuiStep{testTUIStep("pattern", true), testTUIStep("session", false, "notes")}
+
+	var titles []string
+	picked, err := runTUISteps(steps, &Flags{}, scriptedPick(&titles, tui.Back))
+	require.NoError(t, err)
+	assert.False(t, picked)
+
+	picked, err = runTUISteps(steps, &Flags{}, scriptedPick(&titles, tui.Quit))
+	require.NoError(t, err)
+	assert.False(t, picked)
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"gopkg.in/yaml.v3"
+)
+
+// vendorConfigs returns the blocks of the config file that are not options,
+// like `ollama: {modelContextLength: 32768}`, by their key. They hold the
+// options of the vendor named by the key.
+func vendorConfigs(config map[string]any) (ret map[string]map[string]any) {
+	known := map[string]bool{}
+	for field := range reflect.TypeFor[Flags]().Fields() {
+		if yamlTag := field.Tag.Get("yaml"); yamlTag != "" {
+			known[yamlTag] = true
+		}
+	}
+	for key, value := range config {
+		if block, ok := value.(map[string]any); ok && !known[key] {
+			if ret == nil {
+				ret = map[string]map[string]any{}
+			}
+			ret[key] = block
+		}
+	}
+	return
+}
+
+// applyVendorConfig sets the options of the config block of the vendor serving
+// the model, named ignoring case, over those of the config file but not over
+// the flags of the command line. The options are named by their config key or
+// their long flag, with - or _ between words or not.
+func (o *Flags) applyVendorConfig(vendorName string) (err error) {
+	o.servingVendor = vendorName
+	var block map[string]any
+	for key, value := range o.vendorConfigs {
+		if strings.EqualFold(key, vendorName) {
+			block = value
+			break
+		}
+	}
+	if len(block) == 0 {
+		return
+	}
+
+	options := vendorOptionTags()
+	values := map[string]any{}
+	for key, value := range block {
+		yamlTag, ok := options[normalizeOptionName(key)]
+		if !ok {
+			return fmt.Errorf(i18n.T("vendor_config_unknown_option"), key, vendorName)
+		}
+		if !o.usedFlags[yamlTag] {
+			values[yamlTag] = value
+		}
+	}
+
+	// The YAML decoder converts the values as it does for the config file
+	vendorFlags := &Flags{}
+	var data []byte
+	if data, err = yaml.Marshal(values); err == nil {
+		err = yaml.Unmarshal(data, vendorFlags)
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.T("vendor_config_invalid"), vendorName, err)
+	}
+
+	flagsVal := reflect.ValueOf(o).Elem()
+	vendorVal := reflect.ValueOf(vendorFlags).Elem()
+	flagsType := flagsVal.Type()
+	for i := 0; i < flagsType.NumField(); i++ {
+		yamlTag := flagsType.Field(i).Tag.Get("yaml")
+		if _, ok := values[yamlTag]; ok {
+			flagsVal.Field(i).Set(vendorVal.Field(i))
+			debuglog.Debug(debuglog.Detailed, "Applied %s config value for %s: %v\n", vendorName, yamlTag, vendorVal.Field(i).Interface())
+		}
+	}
+	return
+}
+
+// vendorOptionTags returns the config keys of the flags a vendor block can set,
+// by their normalized config key and long flag. The model and the vendor are
+// not, as they choose the block.
+func vendorOptionTags() (ret map[string]string) {
+	ret = map[string]string{}
+	for field := range reflect.TypeFor[Flags]().Fields() {
+		yamlTag, longTag := field.Tag.Get("yaml"), field.Tag.Get("long")
+		if yamlTag == "" || longTag == "" || yamlTag == "model" || yamlTag == "vendor" {
+			continue
+		}
+		ret[normalizeOptionName(yamlTag)] = yamlTag
+		ret[normalizeOptionName(longTag)] = yamlTag
+	}
+	return
+}
+
+// normalizeOptionName lowers the name and drops the - and _ between its words,
+// so that keep_alive, keep-alive and keepAlive name the same option
+func normalizeOptionName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+}
+
+// parseKeepAlive parses a duration like 10m, or a number of seconds as Ollama
+// accepts it, negative to keep the model loaded for ever
+func parseKeepAlive(value string) (ret *time.Duration, err error) {
+	var duration time.Duration
+	if seconds, convErr := strconv.ParseFloat(value, 64); convErr == nil {
+		duration = time.Duration(seconds * float64(time.Second))
+	} else if duration, err = time.ParseDuration(value); err != nil {
+		return nil, fmt.Errorf(i18n.T("invalid_keep_alive"), value)
+	}
+	return &duration, nil
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyVendorConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+temperature: 0.5
+topp: 0.8
+presets:
+  blog:
+    pattern: write_essay
+openai:
+  temperature: 1.0
+ollama:
+  modelContextLength: 32768
+  keep_alive: 10m
+  top-p: 0.95
+`), 0644))
+
+	initFlags := func(t *testing.T, args ...string) *Flags {
+		oldArgs := os.Args
+		defer func() { os.Args = oldArgs }()
+		os.Args = append([]string{"cmd", "--config", configPath}, args...)
+		flags, err := Init()
+		require.NoError(t, err)
+		return flags
+	}
+
+	t.Run("blocks of vendors only", func(t *testing.T) {
+		flags := initFlags(t)
+		assert.Len(t, flags.vendorConfigs, 2)
+		assert.Contains(t, flags.vendorConfigs, "openai")
+		assert.Contains(t, flags.vendorConfigs, "ollama")
+	})
+
+	t.Run("vendor options over the config", func(t *testing.T) {
+		flags := initFlags(t)
+		require.NoError(t, flags.applyVendorConfig("Ollama"))
+		assert.Equal(t, 0.5, flags.Temperature)
+		assert.Equal(t, 0.95, flags.TopP)
+		assert.Equal(t, 32768, flags.ModelContextLength)
+		assert.Equal(t, "10m", flags.KeepAlive)
+	})
+
+	t.Run("command line over the vendor options", func(t *testing.T) {
+		flags := initFlags(t, "--temperature=0.2")
+		require.NoError(t, flags.applyVendorConfig("OpenAI"))
+		assert.Equal(t, 0.2, flags.Temperature)
+		assert.Equal(t, 0.8, flags.TopP)
+	})
+
+	t.Run("other vendors", func(t *testing.T) {
+		flags := initFlags(t)
+		require.NoError(t, flags.applyVendorConfig("Anthropic"))
+		assert.Equal(t, 0.5, flags.Temperature)
+		assert.Empty(t, flags.KeepAlive)
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		flags := &Flags{vendorConfigs: map[string]map[string]any{"Groq": {"temprature": 1}}}
+		assert.ErrorContains(t, flags.applyVendorConfig("groq"), "temprature")
+	})
+
+	t.Run("model is not a vendor option", func(t *testing.T) {
+		flags := &Flags{vendorConfigs: map[string]map[string]any{"groq": {"model": "llama"}}}
+		assert.Error(t, flags.applyVendorConfig("Groq"))
+	})
+}
+
+func TestParseKeepAlive(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"10m", 10 * time.Minute},
+		{"90", 90 * time.Second},
+		{"-1", -time.Second},
+		{"0", 0},
+	}
+	for _, test := range tests {
+		keepAlive, err := parseKeepAlive(test.value)
+		require.NoError(t, err, test.value)
+		assert.Equal(t, test.expected, *keepAlive, test.value)
+	}
+
+	_, err := parseKeepAlive("soon")
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/util"
+)
+
+const (
+	// watchInterval is how often --watch checks the path for changes
+	watchInterval = 500 * time.Millisecond
+	// watchDebounce is how long the path must stay unchanged before the
+	// pattern runs again, so that a save in several writes runs it once
+	watchDebounce = time.Second
+)
+
+// handleWatch runs the chat on the file of --watch, then again whenever it
+// changes, until interrupted. A directory is run on its changed files. The
+// responses are written to --output, or printed with a divider between them.
+func handleWatch(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(currentFlags.Watch); err != nil {
+		return
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, i18n.T("watch_started")+"\n", currentFlags.Watch)
+	run := func(changed []string) {
+		message, readErr := watchMessage(currentFlags.Watch, changed)
+		if readErr != nil || message == "" {
+			if readErr != nil {
+				fmt.Fprintln(os.Stderr, readErr)
+			}
+			return
+		}
+		if currentFlags.Output == "" {
+			fmt.Printf("\n"+i18n.T("watch_divider")+"\n\n", time.Now().Format(time.TimeOnly), strings.Join(changed, ", "))
+		}
+		runFlags := *currentFlags
+		runFlags.Message = AppendMessage(currentFlags.Message, message)
+		if chatErr := handleChatProcessing(&runFlags, registry, ""); chatErr != nil {
+			fmt.Fprintln(os.Stderr, chatErr)
+		}
+	}
+
+	if !info.IsDir() {
+		run([]string{currentFlags.Watch})
+	}
+	watchChanges(ctx, currentFlags.Watch, watchInterval, watchDebounce, run)
+	return nil
+}
+
+// watchChanges calls run with the files changed under the path once they
+// stayed unchanged for debounce, until the context is done. The changes made
+// while run runs are passed to the next call.
+func watchChanges(ctx context.Context, path string, interval, debounce time.Duration, run func(changed []string)) {
+	changes := make(chan []string)
+	go util.WatchFiles(ctx, []string{path}, interval, func(changed []string) {
+		select {
+		case changes <- changed:
+		case <-ctx.Done():
+		}
+	})
+
+	pending := map[string]bool{}
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case changed := <-changes:
+			for _, file := range changed {
+				pending[file] = true
+			}
+			timer = time.After(debounce)
+		case <-timer:
+			changed := slices.Sorted(maps.Keys(pending))
+			clear(pending)
+			timer = nil
+			run(changed)
+		}
+	}
+}
+
+// watchMessage returns the content of the watched file, or for a directory
+// the changed files still there, each under its path
+func watchMessage(path string, changed []string) (ret string, err error) {
+	if info, statErr := os.Stat(path); statErr == nil && !info.IsDir() {
+		var data []byte
+		if data, err = os.ReadFile(path); err != nil {
+			return
+		}
+		return string(data), nil
+	}
+
+	var sb strings.Builder
+	for _, file := range changed {
+		data, readErr := os.ReadFile(file)
+		if readErr != nil {
+			// Removed files have no content to review
+			continue
+		}
+		name := file
+		if rel, relErr := filepath.Rel(path, file); relErr == nil {
+			name = filepath.ToSlash(rel)
+		}
+		fmt.Fprintf(&sb, "File: %s\n```\n%s\n```\n\n", name, strings.TrimRight(string(data), "\n"))
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
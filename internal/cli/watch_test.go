@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchChanges_Debounced(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "essay.md")
+	if err := os.WriteFile(file, []byte("draft"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := make(chan []string, 10)
+	go watchChanges(ctx, dir, 10*time.Millisecond, 100*time.Millisecond, func(changed []string) { runs <- changed })
+
+	// A save in several writes runs the pattern once
+	time.Sleep(30 * time.Millisecond)
+	for _, content := range []string{"draft 2", "draft 23", "draft 234"} {
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	select {
+	case changed := <-runs:
+		if len(changed) != 1 || changed[0] != file {
+			t.Errorf("changed = %v, want [%s]", changed, file)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not run")
+	}
+	select {
+	case changed := <-runs:
+		t.Errorf("ran again with %v, want a single run", changed)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestWatchMessage(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := watchMessage(file, []string{file}); err != nil || got != "package main\n" {
+		t.Errorf("watchMessage(file) = %q, %v", got, err)
+	}
+	got, err := watchMessage(dir, []string{file, filepath.Join(dir, "removed.go")})
+	if want := "File: main.go\n```\npackage main\n```"; err != nil || got != want {
+		t.Errorf("watchMessage(dir) = %q, %v, want %q", got, err, want)
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/tools/webhook"
+)
+
+// webhookSecret returns the secret signing webhooks, from the flag or else
+// the environment, so that it stays out of the shell history.
+func (o *Flags) webhookSecret() string {
+	if o.WebhookSecret != "" {
+		return o.WebhookSecret
+	}
+	return os.Getenv("FABRIC_WEBHOOK_SECRET")
+}
+
+// sendWebhook posts the result of the chat request to --webhook.
+func (o *Flags) sendWebhook(request *domain.ChatRequest, vendor, model, result string) error {
+	payload := &webhook.Payload{
+		Event:    webhook.EventCompleted,
+		Output:   result,
+		Pattern:  request.PatternName,
+		Strategy: request.StrategyName,
+		Context:  request.ContextName,
+		Session:  request.SessionName,
+		Vendor:   vendor,
+		Model:    model,
+		Finished: time.Now(),
+	}
+	return webhook.Send(context.Background(), o.Webhook, o.webhookSecret(), payload)
+}
@@ -0,0 +1,656 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow is a pipeline of fabric calls read from a YAML file by --workflow,
+// or `fabric run workflow.yaml`, as a reproducible alternative to shell
+// pipelines of fabric commands.
+type Workflow struct {
+	Name string `yaml:"name"`
+	// Model and Vendor are used by the steps without a model of their own
+	Model  string `yaml:"model"`
+	Vendor string `yaml:"vendor"`
+	// Vars are the variables of the steps, -v overriding them
+	Vars  map[string]string `yaml:"vars"`
+	Steps []*WorkflowStep   `yaml:"steps"`
+	// Targets are named steps run by --workflow-target, or before the steps
+	// needing them, once. Their outputs are cached next to the workflow file,
+	// a target running again only when its input, files, variables, pattern
+	// or model changed.
+	Targets map[string]*WorkflowStep `yaml:"targets"`
+	// Default are the targets run when the workflow has no steps and
+	// --workflow-target names none, all of them by default
+	Default []string `yaml:"default"`
+}
+
+// WorkflowStep runs a pattern on its input, or runs its parallel steps at the
+// same time. Its input, variables and condition are expanded with {{name}},
+// name being a variable, the ID of an earlier step for its output, input for
+// the input of the workflow or previous for the output of the previous step.
+type WorkflowStep struct {
+	ID       string `yaml:"id"`
+	Pattern  string `yaml:"pattern"`
+	Model    string `yaml:"model"`
+	Vendor   string `yaml:"vendor"`
+	Strategy string `yaml:"strategy"`
+	Context  string `yaml:"context"`
+	Session  string `yaml:"session"`
+	// Needs are the targets run before the step
+	Needs []string `yaml:"needs"`
+	// Input is sent to the model, the output of the previous step by default,
+	// or the input of the workflow for targets
+	Input string `yaml:"input"`
+	// Files are added to the input under their path, glob patterns matching
+	// several files
+	Files []string `yaml:"files"`
+	// Variables are the pattern variables of the step
+	Variables map[string]string `yaml:"variables"`
+	// When skips the step unless it is true: "a == b", "a != b", "a contains
+	// b", or a value that is neither empty, false, no nor 0
+	When string `yaml:"when"`
+	// Output is the file the output is written to, replacing it
+	Output string `yaml:"output"`
+	// Print prints the output, which only the last step does by default
+	Print bool `yaml:"print"`
+	// Parallel are steps run at the same time, their outputs joined
+	Parallel []*WorkflowStep `yaml:"parallel"`
+
+	// target is set for the targets and their parallel steps, whose outputs
+	// are cached
+	target bool
+}
+
+// workflowVariable matches the {{name}} of the workflow templates; the other
+// {{...}}, such as template plugins, are left to the patterns
+var workflowVariable = regexp.MustCompile(`\{\{\s*([\w-]+)\s*\}\}`)
+
+// isWorkflowFile tells whether the argument of `fabric run` is a workflow
+func isWorkflowFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// loadWorkflow reads and checks the workflow file
+func loadWorkflow(path string) (ret *Workflow, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	ret = &Workflow{}
+	if err = yaml.Unmarshal(data, ret); err != nil {
+		return nil, fmt.Errorf(i18n.T("workflow_invalid"), path, err)
+	}
+	if err = ret.check(); err != nil {
+		return nil, fmt.Errorf(i18n.T("workflow_invalid"), path, err)
+	}
+	return
+}
+
+// check names the steps without an ID and the targets, checks the targets
+// they need and makes the last step print its output when none does
+func (o *Workflow) check() (err error) {
+	if len(o.Steps) == 0 && len(o.Targets) == 0 {
+		return errors.New(i18n.T("workflow_no_steps"))
+	}
+	ids := map[string]bool{"input": true, "previous": true}
+	count := 0
+	printed := false
+	var checkSteps func(steps []*WorkflowStep, target bool) error
+	checkSteps = func(steps []*WorkflowStep, target bool) error {
+		for _, step := range steps {
+			if step == nil {
+				return errors.New(i18n.T("workflow_empty_step"))
+			}
+			count++
+			if step.ID == "" {
+				step.ID = fmt.Sprintf("step%d", count)
+			}
+			if ids[step.ID] {
+				return fmt.Errorf(i18n.T("workflow_duplicate_step"), step.ID)
+			}
+			ids[step.ID] = true
+			step.target = target
+			for _, name := range step.Needs {
+				if o.Targets[name] == nil {
+					return fmt.Errorf(i18n.T("workflow_unknown_target"), name)
+				}
+			}
+			printed = printed || step.Print && !target
+			if len(step.Parallel) == 0 {
+				continue
+			}
+			if step.Pattern != "" || step.Input != "" || len(step.Files) > 0 {
+				return fmt.Errorf(i18n.T("workflow_parallel_with_pattern"), step.ID)
+			}
+			if err := checkSteps(step.Parallel, target); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(o.Targets)) {
+		if target := o.Targets[name]; target != nil {
+			target.ID = name
+		}
+		if err = checkSteps([]*WorkflowStep{o.Targets[name]}, true); err != nil {
+			return
+		}
+	}
+	if err = checkSteps(o.Steps, false); err != nil {
+		return
+	}
+	for _, name := range o.Default {
+		if o.Targets[name] == nil {
+			return fmt.Errorf(i18n.T("workflow_unknown_target"), name)
+		}
+	}
+	if err = o.checkCycles(); err != nil {
+		return
+	}
+	if !printed && len(o.Steps) > 0 {
+		o.Steps[len(o.Steps)-1].Print = true
+	}
+	printBranches(o.Steps, false)
+	return
+}
+
+// printBranches makes the branches of printed parallel steps print their
+// outputs
+func printBranches(steps []*WorkflowStep, print bool) {
+	for _, step := range steps {
+		step.Print = step.Print || print
+		printBranches(step.Parallel, step.Print)
+	}
+}
+
+// checkCycles fails when targets need each other, directly or not
+func (o *Workflow) checkCycles() error {
+	const visiting, visited = 1, 2
+	states := map[string]int{}
+	var visit func(name string) error
+	var needs func(step *WorkflowStep) error
+	needs = func(step *WorkflowStep) error {
+		for _, name := range step.Needs {
+			if err := visit(name); err != nil {
+				return err
+			}
+		}
+		for _, branch := range step.Parallel {
+			if err := needs(branch); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	visit = func(name string) error {
+		switch states[name] {
+		case visiting:
+			return fmt.Errorf(i18n.T("workflow_target_cycle"), name)
+		case visited:
+			return nil
+		}
+		states[name] = visiting
+		if err := needs(o.Targets[name]); err != nil {
+			return err
+		}
+		states[name] = visited
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(o.Targets)) {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workflowStepRunner sends the input of the step with its pattern variables,
+// returning the output
+type workflowStepRunner func(step *WorkflowStep, input string, variables map[string]string) (string, error)
+
+// workflowRun holds the values of a running workflow: its variables, its
+// input and the outputs of the steps
+type workflowRun struct {
+	run     workflowStepRunner
+	vars    map[string]string
+	input   string
+	targets map[string]*workflowTarget
+	mu      sync.Mutex
+	values  map[string]string
+}
+
+// workflowTarget runs a target once, for all the steps needing it
+type workflowTarget struct {
+	step   *WorkflowStep
+	once   sync.Once
+	output string
+	err    error
+}
+
+// Run runs the targets, printing their outputs, or else the steps on the
+// input, the variables overriding those of the workflow, and returns the
+// output of the last step or target that ran
+func (o *Workflow) Run(input string, vars map[string]string, targets []string, run workflowStepRunner) (ret string, err error) {
+	r := &workflowRun{run: run, vars: map[string]string{}, input: input, targets: map[string]*workflowTarget{}, values: map[string]string{}}
+	maps.Copy(r.vars, o.Vars)
+	maps.Copy(r.vars, vars)
+	maps.Copy(r.values, r.vars)
+	r.values["input"] = input
+	for name, step := range o.Targets {
+		r.targets[name] = &workflowTarget{step: step}
+	}
+
+	if len(targets) == 0 && len(o.Steps) == 0 {
+		if targets = o.Default; len(targets) == 0 {
+			targets = slices.Sorted(maps.Keys(o.Targets))
+		}
+	}
+	if len(targets) > 0 {
+		for _, name := range targets {
+			if o.Targets[name] == nil {
+				return "", fmt.Errorf(i18n.T("workflow_unknown_target"), name)
+			}
+			printBranches([]*WorkflowStep{o.Targets[name]}, true)
+		}
+		for _, name := range targets {
+			if ret, err = r.target(name); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	ret = input
+	for _, step := range o.Steps {
+		var output string
+		var ran bool
+		if output, ran, err = r.step(step, ret); err != nil {
+			return
+		}
+		if ran {
+			ret = output
+		}
+	}
+	return
+}
+
+// step runs the step after the one whose output is previous, and records its
+// output, empty when the step was skipped
+func (r *workflowRun) step(step *WorkflowStep, previous string) (output string, ran bool, err error) {
+	for _, name := range step.Needs {
+		if _, err = r.target(name); err != nil {
+			return
+		}
+	}
+	values := r.snapshot(previous)
+	if step.When != "" {
+		if ran, err = evalWorkflowCondition(step.ID, step.When, values); err != nil {
+			return
+		}
+		if !ran {
+			debuglog.Log(i18n.T("workflow_skipping_step"), step.ID)
+			r.skip(step)
+			return
+		}
+	}
+
+	if len(step.Parallel) > 0 {
+		outputs := make([]string, len(step.Parallel))
+		rans := make([]bool, len(step.Parallel))
+		errs := make([]error, len(step.Parallel))
+		var wg sync.WaitGroup
+		for i, branch := range step.Parallel {
+			wg.Go(func() {
+				outputs[i], rans[i], errs[i] = r.step(branch, previous)
+			})
+		}
+		wg.Wait()
+		if err = errors.Join(errs...); err != nil {
+			return
+		}
+		var joined []string
+		for i := range outputs {
+			if rans[i] {
+				joined = append(joined, outputs[i])
+			}
+		}
+		output, ran = strings.Join(joined, "\n\n"), len(joined) > 0
+		r.set(step.ID, output)
+		return
+	}
+
+	input := previous
+	if step.Input != "" {
+		if input, err = expandWorkflowTemplate(step.ID, step.Input, values); err != nil {
+			return
+		}
+	}
+	if len(step.Files) > 0 {
+		var files string
+		if files, err = workflowFiles(step.Files); err != nil {
+			return
+		}
+		input = AppendMessage(input, files)
+	}
+	variables := maps.Clone(r.vars)
+	for name, value := range step.Variables {
+		if variables[name], err = expandWorkflowTemplate(step.ID, value, values); err != nil {
+			return
+		}
+	}
+	if output, err = r.run(step, input, variables); err != nil {
+		return "", false, fmt.Errorf(i18n.T("workflow_step_failed"), step.ID, err)
+	}
+	r.set(step.ID, output)
+	return output, true, nil
+}
+
+// target runs the target, on the input of the workflow, unless it already ran
+func (r *workflowRun) target(name string) (string, error) {
+	target := r.targets[name]
+	target.once.Do(func() {
+		target.output, _, target.err = r.step(target.step, r.input)
+	})
+	return target.output, target.err
+}
+
+// workflowFiles returns the content of the files matching the patterns, each
+// under its path
+func workflowFiles(patterns []string) (ret string, err error) {
+	var paths []string
+	for _, pattern := range patterns {
+		var matches []string
+		if matches, err = filepath.Glob(pattern); err != nil {
+			return
+		}
+		if len(matches) == 0 {
+			return "", fmt.Errorf(i18n.T("workflow_no_files"), pattern)
+		}
+		paths = append(paths, matches...)
+	}
+	slices.Sort(paths)
+	var sb strings.Builder
+	for _, path := range slices.Compact(paths) {
+		var data []byte
+		if data, err = os.ReadFile(path); err != nil {
+			return
+		}
+		fmt.Fprintf(&sb, "File: %s\n```\n%s\n```\n\n", filepath.ToSlash(path), strings.TrimRight(string(data), "\n"))
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// snapshot returns the values the templates of a step are expanded with
+func (r *workflowRun) snapshot(previous string) (ret map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret = maps.Clone(r.values)
+	ret["previous"] = previous
+	return
+}
+
+func (r *workflowRun) set(id, output string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[id] = output
+}
+
+// skip records empty outputs for the step and its parallel steps, so that
+// the next steps can test them
+func (r *workflowRun) skip(step *WorkflowStep) {
+	r.set(step.ID, "")
+	for _, branch := range step.Parallel {
+		r.skip(branch)
+	}
+}
+
+// expandWorkflowTemplate replaces the {{name}} of the template with the
+// values, once, so that outputs holding {{...}} are not expanded again
+func expandWorkflowTemplate(stepID, template string, values map[string]string) (ret string, err error) {
+	ret = workflowVariable.ReplaceAllStringFunc(template, func(match string) string {
+		name := workflowVariable.FindStringSubmatch(match)[1]
+		value, ok := values[name]
+		if !ok && err == nil {
+			err = fmt.Errorf(i18n.T("workflow_unknown_variable"), stepID, name)
+		}
+		return value
+	})
+	return
+}
+
+// evalWorkflowCondition tells whether the condition of the step holds, its
+// operands being compared trimmed and ignoring case
+func evalWorkflowCondition(stepID, condition string, values map[string]string) (ret bool, err error) {
+	for _, operator := range []string{" == ", " != ", " contains "} {
+		left, right, found := strings.Cut(condition, operator)
+		if !found {
+			continue
+		}
+		if left, err = expandWorkflowTemplate(stepID, left, values); err != nil {
+			return
+		}
+		if right, err = expandWorkflowTemplate(stepID, right, values); err != nil {
+			return
+		}
+		left, right = strings.ToLower(strings.TrimSpace(left)), strings.ToLower(strings.TrimSpace(right))
+		switch operator {
+		case " == ":
+			return left == right, nil
+		case " != ":
+			return left != right, nil
+		default:
+			return strings.Contains(left, right), nil
+		}
+	}
+	var value string
+	if value, err = expandWorkflowTemplate(stepID, condition, values); err != nil {
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "no", "0":
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleWorkflow runs the workflow of --workflow on the message, writing the
+// output of its last step to --output and the clipboard as a chat would
+func handleWorkflow(currentFlags *Flags, registry *core.PluginRegistry) (err error) {
+	var workflow *Workflow
+	if workflow, err = loadWorkflow(currentFlags.Workflow); err != nil {
+		return
+	}
+	var cache *workflowCache
+	if cache, err = loadWorkflowCache(workflowCachePath(currentFlags.Workflow)); err != nil {
+		return
+	}
+	var result string
+	if result, err = workflow.Run(currentFlags.Message, currentFlags.PatternVariables, currentFlags.WorkflowTarget,
+		func(step *WorkflowStep, input string, variables map[string]string) (output string, err error) {
+			stepFlags := currentFlags.workflowStepFlags(workflow, step, input, variables)
+			var key string
+			if step.target && !currentFlags.DryRun {
+				key = stepFlags.workflowCacheKey(registry)
+				var cached bool
+				if output, cached = cache.get(step.ID, key); cached {
+					debuglog.Log(i18n.T("workflow_target_up_to_date"), step.ID)
+					if step.Print {
+						fmt.Println(output)
+					}
+					// The output file may have been removed since
+					if _, statErr := os.Stat(step.Output); step.Output != "" && statErr != nil {
+						err = writeWorkflowOutput(step.Output, output)
+					}
+					return
+				}
+			}
+			debuglog.Log(i18n.T("workflow_running_step"), step.ID)
+			if output, err = runChat(stepFlags, registry, ""); err != nil {
+				return
+			}
+			if step.Output != "" {
+				if err = writeWorkflowOutput(step.Output, output); err != nil {
+					return
+				}
+			}
+			if key != "" {
+				err = cache.put(step.ID, key, output)
+			}
+			return
+		}); err != nil {
+		return
+	}
+
+	if currentFlags.Copy {
+		if err = CopyToClipboard(result); err != nil {
+			return
+		}
+	}
+	if currentFlags.Output != "" {
+		err = CreateOutputFile(result, currentFlags.Output)
+	}
+	return
+}
+
+// writeWorkflowOutput writes the output of a step to its file, replacing it
+// so that the workflow can run again
+func writeWorkflowOutput(path, output string) (err error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err = os.MkdirAll(dir, ConfigDirPerms); err != nil {
+			return fmt.Errorf(i18n.T("error_creating_file"), err)
+		}
+	}
+	if !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+	if err = os.WriteFile(path, []byte(output), 0o644); err != nil {
+		err = fmt.Errorf(i18n.T("error_writing_to_file"), err)
+	}
+	return
+}
+
+// workflowStepFlags returns the flags running the step, with the model of the
+// step, or else of the workflow, or else of the command line
+func (o *Flags) workflowStepFlags(workflow *Workflow, step *WorkflowStep, input string, variables map[string]string) *Flags {
+	stepFlags := *o
+	stepFlags.Workflow = ""
+	stepFlags.WorkflowTarget = nil
+	stepFlags.Pattern = step.Pattern
+	if step.Model != "" {
+		stepFlags.Model, stepFlags.Vendor = step.Model, step.Vendor
+	} else if workflow.Model != "" {
+		stepFlags.Model, stepFlags.Vendor = workflow.Model, workflow.Vendor
+	}
+	stepFlags.Strategy = step.Strategy
+	stepFlags.Context = nil
+	if step.Context != "" {
+		stepFlags.Context = []string{step.Context}
+	}
+	stepFlags.Session = step.Session
+	stepFlags.PatternVariables = variables
+	stepFlags.Message = input
+	// The output is written by the workflow, replacing the file of an
+	// earlier run
+	stepFlags.Output = ""
+	stepFlags.OutputDir = ""
+	stepFlags.Copy = false
+	stepFlags.Webhook = ""
+	// Steps may run in parallel, their outputs printed once complete
+	stepFlags.Stream = false
+	stepFlags.Quiet = true
+	stepFlags.silent = !step.Print
+	return &stepFlags
+}
+
+// workflowCacheKey returns the key of what the output of a target depends
+// on: its input and variables, its pattern and the model running it
+func (o *Flags) workflowCacheKey(registry *core.PluginRegistry) string {
+	var pattern string
+	if o.Pattern != "" {
+		if raw, err := registry.Db.Patterns.GetRaw(o.Pattern); err == nil {
+			pattern = raw.Pattern
+		}
+	}
+	data, _ := json.Marshal([]any{o.Pattern, pattern, o.Model, o.Vendor, o.Strategy, o.Context, o.Session,
+		o.Message, o.PatternVariables})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// workflowCache keeps the outputs of the targets of a workflow, with the key
+// of what they depend on, in a file next to the workflow
+type workflowCache struct {
+	path    string
+	mu      sync.Mutex
+	Targets map[string]workflowCacheEntry `json:"targets"`
+}
+
+type workflowCacheEntry struct {
+	Key    string `json:"key"`
+	Output string `json:"output"`
+}
+
+// workflowCachePath returns the cache of the workflow: .research.cache.json
+// for research.yaml
+func workflowCachePath(workflowPath string) string {
+	dir, base := filepath.Split(workflowPath)
+	return filepath.Join(dir, "."+strings.TrimSuffix(base, filepath.Ext(base))+".cache.json")
+}
+
+// loadWorkflowCache reads the cache, empty when there is none yet
+func loadWorkflowCache(path string) (ret *workflowCache, err error) {
+	ret = &workflowCache{path: path, Targets: map[string]workflowCacheEntry{}}
+	var data []byte
+	if data, err = os.ReadFile(path); errors.Is(err, os.ErrNotExist) {
+		return ret, nil
+	} else if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, ret); err != nil {
+		return nil, fmt.Errorf(i18n.T("workflow_invalid_cache"), path, err)
+	}
+	if ret.Targets == nil {
+		ret.Targets = map[string]workflowCacheEntry{}
+	}
+	return
+}
+
+// get returns the output of the target when it has the key
+func (o *workflowCache) get(id, key string) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entry, ok := o.Targets[id]
+	return entry.Output, ok && entry.Key == key
+}
+
+// put records the output of the target, saving the cache so that an
+// interrupted workflow keeps the targets that ran
+func (o *workflowCache) put(id, key, output string) (err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Targets[id] = workflowCacheEntry{Key: key, Output: output}
+	var data []byte
+	if data, err = json.MarshalIndent(o, "", "  "); err != nil {
+		return
+	}
+	// The outputs may be as private as the inputs
+	return os.WriteFile(o.path, data, 0o600)
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLoadWorkflow(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "workflow.yaml")
+	if err := os.WriteFile(path, []byte(`
+name: research
+vars:
+  topic: tides
+steps:
+  - pattern: create_outline
+  - id: drafts
+    parallel:
+      - pattern: write_essay
+      - pattern: summarize
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	workflow, err := loadWorkflow(path)
+	if err != nil {
+		t.Fatalf("loadWorkflow() error = %v", err)
+	}
+	if workflow.Steps[0].ID != "step1" || workflow.Steps[1].Parallel[1].ID != "step4" {
+		t.Errorf("steps without an ID are not named by their position")
+	}
+	if workflow.Steps[0].Print || !workflow.Steps[1].Print || !workflow.Steps[1].Parallel[0].Print {
+		t.Errorf("only the last step and its branches should print their outputs")
+	}
+
+	for name, content := range map[string]string{
+		"empty":     "name: nothing\n",
+		"duplicate": "steps:\n  - id: a\n  - id: a\n",
+		"parallel":  "steps:\n  - pattern: summarize\n    parallel:\n      - pattern: summarize\n",
+		"reserved":  "steps:\n  - id: input\n",
+	} {
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadWorkflow(path); err == nil {
+			t.Errorf("loadWorkflow(%s) expected an error", name)
+		}
+	}
+}
+
+func TestWorkflowRun(t *testing.T) {
+	workflow := &Workflow{
+		Vars: map[string]string{"topic": "tides", "tone": "dry"},
+		Steps: []*WorkflowStep{
+			{ID: "outline", Pattern: "outline", Input: "{{input}} about {{topic}}"},
+			{ID: "drafts", Parallel: []*WorkflowStep{
+				{ID: "essay", Pattern: "essay"},
+				{ID: "poem", Pattern: "poem", When: "{{tone}} == lyrical"},
+				{ID: "tweet", Pattern: "tweet", When: "{{outline}} contains TIDES"},
+			}},
+			{ID: "review", Pattern: "review", Variables: map[string]string{"draft": "{{essay}}"}},
+			{ID: "skipped", Pattern: "nope", When: "{{poem}}"},
+			{ID: "group", When: "no", Parallel: []*WorkflowStep{{ID: "branch"}}},
+			{ID: "after", Pattern: "nope", When: "{{branch}}"},
+		},
+	}
+	if err := workflow.check(); err != nil {
+		t.Fatalf("check() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	inputs := map[string]string{}
+	result, err := workflow.Run("notes", map[string]string{"tone": "dry"}, nil, func(step *WorkflowStep, input string, variables map[string]string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		inputs[step.ID] = input
+		if step.ID == "review" && variables["draft"] != "essay of outline of notes about tides" {
+			t.Errorf("review variables = %v", variables)
+		}
+		return step.Pattern + " of " + input, nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if inputs["outline"] != "notes about tides" {
+		t.Errorf("outline input = %q", inputs["outline"])
+	}
+	if _, ran := inputs["poem"]; ran {
+		t.Errorf("the poem ran although its condition is false")
+	}
+	if _, ran := inputs["after"]; ran {
+		t.Errorf("a step ran on the output of a skipped parallel step")
+	}
+	if _, ran := inputs["skipped"]; ran {
+		t.Errorf("a step ran on the output of a skipped step")
+	}
+	// The parallel steps get the output of the previous step, the next step
+	// their joined outputs
+	if inputs["essay"] != "outline of notes about tides" || inputs["tweet"] != inputs["essay"] {
+		t.Errorf("parallel inputs = %q, %q", inputs["essay"], inputs["tweet"])
+	}
+	if !strings.HasPrefix(inputs["review"], "essay of ") || !strings.Contains(inputs["review"], "\n\ntweet of ") {
+		t.Errorf("review input = %q, want the joined outputs of the drafts", inputs["review"])
+	}
+	if !strings.HasPrefix(result, "review of essay of ") {
+		t.Errorf("Run() = %q, want the output of the last step that ran", result)
+	}
+}
+
+func TestExpandWorkflowTemplate(t *testing.T) {
+	values := map[string]string{"a": "{{b}}", "b": "x"}
+	if got, err := expandWorkflowTemplate("s", "{{ a }} {{plugin:text:upper:y}}", values); err != nil || got != "{{b}} {{plugin:text:upper:y}}" {
+		t.Errorf("expandWorkflowTemplate() = %q, %v; outputs must not be expanded again", got, err)
+	}
+	if _, err := expandWorkflowTemplate("s", "{{missing}}", values); err == nil {
+		t.Errorf("expandWorkflowTemplate() of an unknown variable expected an error")
+	}
+	for condition, want := range map[string]bool{"{{b}}": true, " no ": false, "{{b}} == X": true, "{{b}} != x": false, "yes": true, "0": false} {
+		if got, err := evalWorkflowCondition("s", condition, values); err != nil || got != want {
+			t.Errorf("evalWorkflowCondition(%q) = %v, %v; want %v", condition, got, err, want)
+		}
+	}
+}
+
+func TestWorkflowTargets(t *testing.T) {
+	workflow := &Workflow{
+		Targets: map[string]*WorkflowStep{
+			"notes":   {Pattern: "notes"},
+			"outline": {Pattern: "outline", Needs: []string{"notes"}, Input: "{{notes}}"},
+			"essay":   {Pattern: "essay", Needs: []string{"notes", "outline"}, Input: "{{outline}}"},
+		},
+		Steps: []*WorkflowStep{{ID: "review", Needs: []string{"essay"}, Input: "{{essay}}"}},
+	}
+	if err := workflow.check(); err != nil {
+		t.Fatalf("check() error = %v", err)
+	}
+	if !workflow.Targets["essay"].target || workflow.Steps[0].target {
+		t.Errorf("only the targets should be cached")
+	}
+
+	var mu sync.Mutex
+	var ran []string
+	run := func(step *WorkflowStep, input string, _ map[string]string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, step.ID)
+		return step.ID + "(" + input + ")", nil
+	}
+	result, err := workflow.Run("in", nil, nil, run)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if strings.Join(ran, " ") != "notes outline essay review" || result != "review(essay(outline(notes(in))))" {
+		t.Errorf("Run() ran %v = %q, want each target once before the steps needing it", ran, result)
+	}
+
+	ran = nil
+	if result, err = workflow.Run("in", nil, []string{"outline"}, run); err != nil || result != "outline(notes(in))" {
+		t.Errorf("Run(outline) = %q, %v", result, err)
+	}
+	if !workflow.Targets["outline"].Print || workflow.Targets["notes"].Print {
+		t.Errorf("only the targets asked for should print their outputs")
+	}
+	if _, err = workflow.Run("in", nil, []string{"nope"}, run); err == nil {
+		t.Errorf("Run() of an unknown target expected an error")
+	}
+
+	cycle := &Workflow{Targets: map[string]*WorkflowStep{
+		"a": {Needs: []string{"b"}},
+		"b": {Parallel: []*WorkflowStep{{ID: "c", Needs: []string{"a"}}}},
+	}}
+	if err = cycle.check(); err == nil {
+		t.Errorf("check() of targets needing each other expected an error")
+	}
+	unknown := &Workflow{Steps: []*WorkflowStep{{Needs: []string{"nope"}}}}
+	if err = unknown.check(); err == nil {
+		t.Errorf("check() of a step needing an unknown target expected an error")
+	}
+}
+
+func TestWorkflowCache(t *testing.T) {
+	if got := workflowCachePath(filepath.Join("docs", "research.yaml")); got != filepath.Join("docs", ".research.cache.json") {
+		t.Errorf("workflowCachePath() = %q", got)
+	}
+	path := filepath.Join(t.TempDir(), ".research.cache.json")
+	cache, err := loadWorkflowCache(path)
+	if err != nil {
+		t.Fatalf("loadWorkflowCache() of a missing file error = %v", err)
+	}
+	if err = cache.put("outline", "key", "output"); err != nil {
+		t.Fatalf("put() error = %v", err)
+	}
+	if cache, err = loadWorkflowCache(path); err != nil {
+		t.Fatalf("loadWorkflowCache() error = %v", err)
+	}
+	if output, ok := cache.get("outline", "key"); !ok || output != "output" {
+		t.Errorf("get() = %q, %v; want the saved output", output, ok)
+	}
+	if _, ok := cache.get("outline", "changed"); ok {
+		t.Errorf("get() with another key should miss")
+	}
+
+	if err = os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loadWorkflowCache(path); err == nil {
+		t.Errorf("loadWorkflowCache() of an invalid file expected an error")
+	}
+}
+
+func TestWorkflowFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"b.md": "second\n", "a.md": "first"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := workflowFiles([]string{filepath.Join(dir, "*.md"), filepath.Join(dir, "a.md")})
+	if err != nil {
+		t.Fatalf("workflowFiles() error = %v", err)
+	}
+	want := "File: " + filepath.ToSlash(filepath.Join(dir, "a.md")) + "\n```\nfirst\n```\n\nFile: " +
+		filepath.ToSlash(filepath.Join(dir, "b.md")) + "\n```\nsecond\n```"
+	if got != want {
+		t.Errorf("workflowFiles() = %q, want %q", got, want)
+	}
+	if _, err = workflowFiles([]string{filepath.Join(dir, "*.txt")}); err == nil {
+		t.Errorf("workflowFiles() of a pattern matching nothing expected an error")
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+// Budget caps the cost of a request
+type Budget struct {
+	// MaxCost is the most the request may cost, in USD
+	MaxCost float64
+	// Pricing is the configured price of the model, else the one reported by
+	// the vendor or the built-in table is used
+	Pricing *ai.ModelPricing
+}
+
+// modelPricing returns the price of the model: the configured one, the one
+// reported by the vendor API, or the built-in table
+func (o *Chatter) modelPricing(ctx context.Context, configured *ai.ModelPricing) (ret ai.ModelPricing, err error) {
+	if configured != nil {
+		return *configured, nil
+	}
+	if provider, ok := o.vendor.(ai.ModelPricingProvider); ok {
+		if ret, err = provider.ModelPricing(ctx, o.model); err == nil && (ret.Input > 0 || ret.Output > 0) {
+			return
+		}
+		debuglog.Debug(debuglog.Detailed, "Could not get the price of %s from the vendor: %v\n", o.model, err)
+	}
+	var found bool
+	if ret, found = ai.LookupModelPricing(o.model); !found {
+		return ret, fmt.Errorf(i18n.T("budget_error_no_pricing"), o.model)
+	}
+	return ret, nil
+}
+
+// applyBudget estimates the cost of the input of the requests before they are
+// sent, failing when it is over the budget, and lowers the max output tokens
+// so that the responses fit in what is left. It returns the meter of the cost
+// of a streamed response.
+func (o *Chatter) applyBudget(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, requests int) (ret *costMeter, err error) {
+	var pricing ai.ModelPricing
+	if pricing, err = o.modelPricing(ctx, o.Budget.Pricing); err != nil {
+		return
+	}
+
+	inputTokens := estimateInputTokens(msgs)
+	inputCost := pricing.Cost(inputTokens, 0) * float64(requests)
+	if inputCost >= o.Budget.MaxCost {
+		return nil, fmt.Errorf(i18n.T("budget_error_input_over"), inputTokens*requests, inputCost, o.Budget.MaxCost)
+	}
+
+	if pricing.Output > 0 {
+		maxTokens := int((o.Budget.MaxCost - inputCost) / float64(requests) / pricing.Output * 1e6)
+		if opts.MaxTokens == 0 || opts.MaxTokens > maxTokens {
+			opts.MaxTokens = max(maxTokens, 1)
+			debuglog.Log(i18n.T("budget_log_max_tokens"), opts.MaxTokens, o.Budget.MaxCost)
+		}
+	}
+	return &costMeter{pricing: pricing, maxCost: o.Budget.MaxCost, inputCost: inputCost}, nil
+}
+
+// estimateInputTokens estimates the tokens of the text of the messages
+func estimateInputTokens(msgs []*chat.ChatCompletionMessage) (ret int) {
+	for _, msg := range msgs {
+		for _, text := range messageTexts(msg) {
+			ret += ai.EstimateTokens(*text)
+		}
+	}
+	return
+}
+
+// costMeter estimates the running cost of a streamed response
+type costMeter struct {
+	pricing   ai.ModelPricing
+	maxCost   float64
+	inputCost float64
+	// runes is the length of the response so far, reasoning included
+	runes int
+}
+
+// add counts the text of the response and returns an error once the cost is
+// over the budget
+func (o *costMeter) add(text string) error {
+	o.runes += utf8.RuneCountInString(text)
+	if cost := o.inputCost + o.pricing.Cost(0, (o.runes+3)/4); cost > o.maxCost {
+		return fmt.Errorf(i18n.T("budget_error_stream_over"), cost, o.maxCost)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// pricingVendor reports a fixed price for every model.
+type pricingVendor struct {
+	mockVendor
+	pricing ai.ModelPricing
+}
+
+func (m *pricingVendor) ModelPricing(context.Context, string) (ai.ModelPricing, error) {
+	return m.pricing, nil
+}
+
+func TestApplyBudget(t *testing.T) {
+	// 1000 tokens of input
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: strings.Repeat("a", 4000)}}
+	pricing := &ai.ModelPricing{Input: 100, Output: 1000}
+
+	t.Run("caps the output tokens", func(t *testing.T) {
+		// $0.10 of input, $0.40 left for 400 output tokens
+		chatter := &Chatter{model: "test-model", vendor: &mockVendor{}, Budget: &Budget{MaxCost: 0.5, Pricing: pricing}}
+		opts := &domain.ChatOptions{}
+		if _, err := chatter.applyBudget(context.Background(), msgs, opts, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.MaxTokens != 400 {
+			t.Errorf("MaxTokens = %d, want 400", opts.MaxTokens)
+		}
+
+		opts = &domain.ChatOptions{MaxTokens: 100}
+		if _, err := chatter.applyBudget(context.Background(), msgs, opts, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.MaxTokens != 100 {
+			t.Errorf("MaxTokens = %d, want the lower --max-output-tokens 100", opts.MaxTokens)
+		}
+
+		// Each of the 2 samples has $0.15 left
+		opts = &domain.ChatOptions{}
+		if _, err := chatter.applyBudget(context.Background(), msgs, opts, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.MaxTokens != 150 {
+			t.Errorf("MaxTokens = %d, want 150", opts.MaxTokens)
+		}
+	})
+
+	t.Run("input over budget", func(t *testing.T) {
+		chatter := &Chatter{model: "test-model", vendor: &mockVendor{}, Budget: &Budget{MaxCost: 0.05, Pricing: pricing}}
+		if _, err := chatter.applyBudget(context.Background(), msgs, &domain.ChatOptions{}, 1); err == nil {
+			t.Fatal("expected the input to be over the budget")
+		}
+	})
+
+	t.Run("price from the vendor", func(t *testing.T) {
+		chatter := &Chatter{model: "test-model", vendor: &pricingVendor{pricing: *pricing}, Budget: &Budget{MaxCost: 0.5}}
+		opts := &domain.ChatOptions{}
+		if _, err := chatter.applyBudget(context.Background(), msgs, opts, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.MaxTokens != 400 {
+			t.Errorf("MaxTokens = %d, want 400", opts.MaxTokens)
+		}
+	})
+
+	t.Run("unknown price", func(t *testing.T) {
+		chatter := &Chatter{model: "test-model", vendor: &mockVendor{}, Budget: &Budget{MaxCost: 0.5}}
+		if _, err := chatter.applyBudget(context.Background(), msgs, &domain.ChatOptions{}, 1); err == nil {
+			t.Fatal("expected an error for a model without a price")
+		}
+	})
+}
+
+func TestChatter_Send_StopsStreamOverBudget(t *testing.T) {
+	var chunks []domain.StreamUpdate
+	for range 10 {
+		// 100 tokens, $0.10 each
+		chunks = append(chunks, domain.StreamUpdate{Type: domain.StreamTypeContent, Content: strings.Repeat("b", 400)})
+	}
+	chatter := &Chatter{
+		db:     fsdb.NewDb(t.TempDir()),
+		Stream: true,
+		vendor: &mockVendor{streamChunks: chunks},
+		model:  "test-model",
+		Budget: &Budget{MaxCost: 0.35, Pricing: &ai.ModelPricing{Input: 0, Output: 1000}},
+	}
+	// The vendor ignores the max output tokens, so the stream is stopped
+	_, err := chatter.Send(context.Background(), &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test message"},
+	}, &domain.ChatOptions{Model: "test-model"})
+	if err == nil || !strings.Contains(err.Error(), "--max-cost") {
+		t.Fatalf("expected the stream to stop over --max-cost, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -12,10 +13,15 @@ import (
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins"
 	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/dryrun"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 	"github.com/danielmiessler/fabric/internal/plugins/strategy"
 	"github.com/danielmiessler/fabric/internal/plugins/template"
+	"github.com/danielmiessler/fabric/internal/tools/hooks"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+	"github.com/danielmiessler/fabric/internal/tools/redact"
 )
 
 type Chatter struct {
@@ -24,6 +30,26 @@ type Chatter struct {
 	Stream bool
 	DryRun bool
 
+	// Moderation, when set, screens the input and the response
+	Moderation *Moderation
+	// Redactor, when set, masks sensitive data sent to the vendor and restores it
+	// in the response
+	Redactor *redact.Redactor
+	// Hooks, when set, transform the messages sent to the vendor and the response
+	Hooks *hooks.Hooks
+	// SessionPolicy, when set, limits the size and the age of sessions
+	SessionPolicy *SessionPolicy
+	// Tools, when set, are offered to the model, which can call them before it
+	// answers
+	Tools Toolbox
+	// Budget, when set, caps the cost of the request
+	Budget *Budget
+	// Spend, when set, records the spend of the requests
+	Spend *SpendTracking
+	// Logprobs are the log probabilities of the tokens of the last response,
+	// set by Send when opts.Logprobs asks for them
+	Logprobs []domain.TokenLogprob
+
 	model              string
 	modelContextLength int
 	vendor             ai.Vendor
@@ -56,6 +82,52 @@ func joinPromptSections(parts ...string) string {
 	return strings.Join(sections, "\n")
 }
 
+// loadContexts joins the contexts of the comma separated names in order,
+// replacing the {{variables}} in them when context variables are given.
+func (o *Chatter) loadContexts(names string, variables map[string]string) (ret string, err error) {
+	var contents []string
+	for name := range strings.SplitSeq(names, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		var ctx *fsdb.Context
+		if ctx, err = o.db.Contexts.Get(name); err != nil {
+			return "", fmt.Errorf(i18n.T("chatter_error_find_context"), name, err)
+		}
+		content := ctx.Content
+		if len(variables) > 0 {
+			if content, err = template.ApplyTemplate(content, variables, ""); err != nil {
+				return "", fmt.Errorf(i18n.T("chatter_error_apply_context_variables"), name, err)
+			}
+		}
+		contents = append(contents, content)
+	}
+	ret = joinPromptSections(contents...)
+	return
+}
+
+// Model returns the name of the model the chatter sends requests to.
+func (o *Chatter) Model() string {
+	return o.model
+}
+
+// VendorName returns the name of the vendor serving the model.
+func (o *Chatter) VendorName() (ret string) {
+	if o.vendor != nil {
+		ret = o.vendor.GetName()
+	}
+	return
+}
+
+// ServingVendorName returns the name of the vendor serving the model, which is
+// the one a dry run shows the request of
+func (o *Chatter) ServingVendorName() string {
+	if client, ok := o.vendor.(*dryrun.Client); ok && client.Vendor != nil {
+		return client.Vendor.GetName()
+	}
+	return o.VendorName()
+}
+
 // Send processes a chat request and applies file changes for create_coding_feature pattern
 func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *domain.ChatOptions) (session *fsdb.Session, err error) {
 	// Use o.model (normalized) for NeedsRawMode check instead of opts.Model
@@ -63,12 +135,22 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 	if o.vendor.NeedsRawMode(o.model) {
 		opts.Raw = true
 	}
-	if session, err = o.BuildSession(request, opts.Raw); err != nil {
-		return
+	var vendorMessages []*chat.ChatCompletionMessage
+	if request.Resume {
+		if session, err = o.resumeSession(request); err != nil {
+			return
+		}
+		vendorMessages = resumeMessages(session)
+	} else {
+		if session, err = o.BuildSession(request, opts.Raw); err != nil {
+			return
+		}
+		if err = o.applySessionPolicy(ctx, session, opts); err != nil {
+			return
+		}
+		vendorMessages = session.GetVendorMessages()
 	}
 
-	vendorMessages := session.GetVendorMessages()
-
 	if debuglog.GetLevel() >= debuglog.Wire {
 		debuglog.Debug(debuglog.Wire, "FABRIC->LLM request messages (%d)\n", len(vendorMessages))
 		for i, msg := range vendorMessages {
@@ -89,6 +171,19 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 		return
 	}
 
+	if err = o.fitContextWindow(ctx, vendorMessages, opts); err != nil {
+		return
+	}
+
+	if err = o.moderateInput(ctx, request, opts); err != nil {
+		return
+	}
+
+	sendMessages := vendorMessages
+	if o.Redactor != nil {
+		sendMessages = o.Redactor.RedactMessages(vendorMessages)
+	}
+
 	// Always use the normalized model name from the Chatter
 	// This handles cases where user provides "GPT-5" but we've normalized it to "gpt-5"
 	opts.Model = o.model
@@ -97,50 +192,147 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 		opts.ModelContextLength = o.modelContextLength
 	}
 
+	if ctx, sendMessages, err = o.runPreHooks(ctx, request, sendMessages); err != nil {
+		return
+	}
+	if len(opts.ModelParams) > 0 {
+		ctx = plugins.WithBodyParams(ctx, opts.ModelParams)
+	}
+
+	plan, err := strategy.Compose(request.StrategyName)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("chatter_error_load_strategy"), request.StrategyName, err)
+	}
+	if request.Samples > 0 {
+		plan.Samples = request.Samples
+	}
+	if request.Select != "" {
+		plan.Aggregate = request.Select
+	}
+	var meter *costMeter
+	if o.Budget != nil {
+		if meter, err = o.applyBudget(ctx, sendMessages, opts, max(plan.Samples, 1)); err != nil {
+			return
+		}
+	}
+
 	message := ""
+	var reasoning strings.Builder
+	// usage is the spend reported by the vendor
+	var usage *domain.UsageMetadata
+	trackSpend := o.Spend != nil && !o.DryRun
 
-	if o.Stream {
+	// Log probabilities and the results of tool calls come with the whole response
+	if o.Stream && plan.Samples <= 1 && request.Refine == 0 && request.TranslateOutput == "" && !opts.Logprobs && o.Tools == nil {
 		responseChan := make(chan domain.StreamUpdate)
 		errChan := make(chan error, 1)
 		done := make(chan struct{})
 		printedStream := false
+		holdOutput := o.holdsOutput()
+
+		// Markdown is rendered line by line as the response streams in
+		var out io.Writer = os.Stdout
+		var markdownOut *mdrender.Writer
+		if opts.RenderMarkdown && !opts.SuppressThink && !opts.Quiet {
+			markdownOut = mdrender.NewWriter(os.Stdout, mdrender.TerminalWidth(os.Stdout))
+			out = markdownOut
+		}
+
+		// Without a thinking display, reasoning updates are not printed and content is printed as is
+		var thinkOut *thinkPrinter
+		if (opts.ShowThink != "" || opts.ThinkOutput != "") && !opts.SuppressThink && !opts.Quiet {
+			var think io.Writer
+			switch opts.ShowThink {
+			case domain.ShowThinkDim:
+				think = out
+			case domain.ShowThinkStderr:
+				think = os.Stderr
+			}
+			thinkOut = newThinkPrinter(out, think, opts.ThinkStartTag, opts.ThinkEndTag)
+			if markdownOut != nil && thinkOut.inline() {
+				thinkOut.color = true
+			}
+		}
+
+		if request.Resume && !opts.SuppressThink && !opts.Quiet {
+			// The continuation is printed after the response it continues
+			_, _ = io.WriteString(out, session.GetLastMessage().Content)
+			printedStream = true
+		}
+
+		// The stream is stopped once it is over the budget
+		streamCtx, stopStream := context.WithCancel(ctx)
+		defer stopStream()
+		stopped := false
 
 		go func() {
 			defer close(done)
-			if streamErr := o.vendor.SendStream(ctx, session.GetVendorMessages(), opts, responseChan); streamErr != nil {
+			vendorChan := responseChan
+			if o.Redactor != nil {
+				vendorChan = o.Redactor.RestoreStream(responseChan)
+			}
+			if streamErr := o.vendor.SendStream(streamCtx, sendMessages, opts, vendorChan); streamErr != nil {
 				recordFirstStreamError(errChan, streamErr)
 			}
 		}()
 
 		for update := range responseChan {
+			if stopped {
+				continue
+			}
+			if meter != nil && (update.Type == domain.StreamTypeContent || update.Type == domain.StreamTypeReasoning) {
+				if budgetErr := meter.add(update.Content); budgetErr != nil {
+					// The error of the budget comes before that of the stopped stream
+					recordFirstStreamError(errChan, budgetErr)
+					stopStream()
+					stopped = true
+					continue
+				}
+			}
 			if debuglog.GetLevel() >= debuglog.Wire {
 				debuglog.Debug(debuglog.Wire, "LLM->FABRIC stream update type=%s content=%q\n", update.Type, update.Content)
 				if update.Usage != nil {
 					debuglog.Debug(debuglog.Wire, "LLM->FABRIC stream usage input=%d output=%d total=%d\n", update.Usage.InputTokens, update.Usage.OutputTokens, update.Usage.TotalTokens)
 				}
 			}
-			if opts.UpdateChan != nil {
+			// Content held back for moderation is sent once the response passes it
+			held := holdOutput && update.Type == domain.StreamTypeContent
+			if opts.UpdateChan != nil && !held {
 				opts.UpdateChan <- update
 			}
 			switch update.Type {
 			case domain.StreamTypeContent:
 				message += update.Content
-				if !opts.SuppressThink && !opts.Quiet {
-					fmt.Print(update.Content)
+				if held {
+					break
+				}
+				if thinkOut != nil {
+					thinkOut.Content(update.Content)
 					printedStream = true
+				} else if !opts.SuppressThink && !opts.Quiet {
+					_, _ = io.WriteString(out, update.Content)
+					printedStream = true
+				}
+			case domain.StreamTypeReasoning:
+				reasoning.WriteString(update.Content)
+				if thinkOut != nil {
+					thinkOut.Reasoning(update.Content)
 				}
 			case domain.StreamTypeUsage:
+				if update.Usage != nil {
+					usage = update.Usage
+				}
 				if opts.ShowMetadata && update.Usage != nil && !opts.Quiet {
-					fmt.Fprintf(
-						os.Stderr,
-						"\n%s\n",
-						fmt.Sprintf(
-							i18n.T("chatter_log_stream_usage_metadata"),
-							update.Usage.InputTokens,
-							update.Usage.OutputTokens,
-							update.Usage.TotalTokens,
-						),
+					usage := fmt.Sprintf(
+						i18n.T("chatter_log_stream_usage_metadata"),
+						update.Usage.InputTokens,
+						update.Usage.OutputTokens,
+						update.Usage.TotalTokens,
 					)
+					if update.Usage.Cost > 0 {
+						usage += fmt.Sprintf(i18n.T("chatter_log_stream_usage_cost"), update.Usage.Cost)
+					}
+					fmt.Fprintf(os.Stderr, "\n%s\n", usage)
 				}
 			case domain.StreamTypeError:
 				if !opts.Quiet {
@@ -150,35 +342,91 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 			}
 		}
 
+		if thinkOut != nil {
+			thinkOut.Flush()
+		}
+		if markdownOut != nil {
+			_ = markdownOut.Flush()
+		}
 		if printedStream && !opts.SuppressThink && !strings.HasSuffix(message, "\n") && !opts.Quiet {
 			fmt.Println()
 		}
 
 		// Wait for goroutine to finish
 		<-done
+		if trackSpend && (message != "" || reasoning.Len() > 0 || usage != nil) {
+			// A stopped response is paid for as well
+			o.recordSpend(ctx, sendMessages, message+reasoning.String(), usage, 1)
+		}
 
 		// Check for errors in errChan
 		select {
 		case streamErr := <-errChan:
 			if streamErr != nil {
-				err = streamErr
+				err = o.interruption(ctx, session, request, message, streamErr)
 				return
 			}
 		default:
 			// No errors, continue
 		}
+		if ctx.Err() != nil {
+			err = o.interruption(ctx, session, request, message, ctx.Err())
+			return
+		}
 	} else {
-		if message, err = o.vendor.Send(ctx, session.GetVendorMessages(), opts); err != nil {
+		if plan.Samples > 1 {
+			message, err = o.sendSamples(ctx, request, sendMessages, opts, plan)
+		} else if opts.Logprobs && !o.DryRun {
+			message, err = o.sendWithLogprobs(ctx, sendMessages, opts)
+		} else if o.Tools != nil && !o.DryRun {
+			message, err = o.sendWithTools(ctx, sendMessages, opts)
+		} else {
+			message, err = o.vendor.Send(ctx, sendMessages, opts)
+		}
+		if err == nil && trackSpend {
+			o.recordSpend(ctx, sendMessages, message, nil, max(plan.Samples, 1))
+		}
+		if err == nil && request.Refine > 0 {
+			message, err = o.refine(ctx, request, sendMessages, message, opts)
+		}
+		if err == nil && request.TranslateOutput != "" {
+			message, err = o.translateOutput(ctx, message, request.TranslateOutput, opts)
+		}
+		if err != nil {
+			err = o.interruption(ctx, session, request, "", err)
 			return
 		}
+		if o.Redactor != nil {
+			message = o.Redactor.Restore(message)
+		}
+		if o.Stream && opts.UpdateChan != nil {
+			// Sampled, refined and translated responses cannot stream, so streaming clients get the final one at once
+			opts.UpdateChan <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: message}
+		}
 		if debuglog.GetLevel() >= debuglog.Wire {
 			debuglog.Debug(debuglog.Wire, "LLM->FABRIC response content=%q\n", message)
 		}
 	}
 
-	if opts.SuppressThink && !o.DryRun {
-		message = domain.StripThinkBlocks(message, opts.ThinkStartTag, opts.ThinkEndTag)
+	if !o.DryRun {
+		if err = o.separateThinking(message, reasoning.String(), opts); err != nil {
+			return
+		}
+		if opts.SuppressThink || opts.ShowThink == domain.ShowThinkStderr || opts.ThinkOutput != "" {
+			message = domain.StripThinkBlocks(message, opts.ThinkStartTag, opts.ThinkEndTag)
+		}
+	}
+
+	streamed := message
+	if message, err = o.runPostHooks(ctx, request, message); err != nil {
+		session = nil
+		return
+	}
+	if message, err = o.moderateOutput(ctx, message, opts); err != nil {
+		session = nil
+		return
 	}
+	o.releaseOutput(streamed, message, opts)
 
 	if message == "" {
 		session = nil
@@ -207,9 +455,12 @@ func (o *Chatter) Send(ctx context.Context, request *domain.ChatRequest, opts *d
 		message = summary
 	}
 
-	session.Append(&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: message})
+	appendResponse(session, request, message)
 
-	if session.Name != "" {
+	if session.Name == fsdb.InterruptedSessionName && request.Resume {
+		// The interrupted response is complete
+		err = o.db.Sessions.Delete(session.Name)
+	} else if session.Name != "" {
 		err = o.db.Sessions.SaveSession(session)
 	}
 	return
@@ -223,6 +474,16 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 			return
 		}
 		session = sess
+		if request.SessionTitle != "" {
+			session.Meta.Title = request.SessionTitle
+		}
+		if len(request.SessionTags) > 0 {
+			session.Meta.Tags = request.SessionTags
+		}
+		session.Meta.Vendor, session.Meta.Model = o.VendorName(), o.model
+		if request.PatternName != "" {
+			session.Meta.Pattern = request.PatternName
+		}
 	} else {
 		session = &fsdb.Session{}
 	}
@@ -231,16 +492,12 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 		session.Append(&chat.ChatCompletionMessage{Role: domain.ChatMessageRoleMeta, Content: request.Meta})
 	}
 
-	// if a context name is provided, retrieve it from the database
+	// if context names are provided, retrieve them from the database
 	var contextContent string
-	if request.ContextName != "" {
-		var ctx *fsdb.Context
-		if ctx, err = o.db.Contexts.Get(request.ContextName); err != nil {
-			err = fmt.Errorf(i18n.T("chatter_error_find_context"), request.ContextName, err)
-			return
-		}
-		contextContent = ctx.Content
+	if contextContent, err = o.loadContexts(request.ContextName, request.ContextVariables); err != nil {
+		return
 	}
+	contextContent = joinPromptSections(contextContent, request.EphemeralContext)
 
 	// Process template variables in message content
 	// Double curly braces {{variable}} indicate template substitution
@@ -262,35 +519,36 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 
 	var patternContent string
 	inputUsed := false
+	// A pattern written in the language answers in it without being told to
+	localized := false
 	if request.PatternName != "" {
 		var pattern *fsdb.Pattern
 		if request.NoVariableReplacement {
-			pattern, err = o.db.Patterns.GetWithoutVariables(request.PatternName, request.Message.Content)
+			pattern, err = o.db.Patterns.GetLocalizedWithoutVariables(request.PatternName, request.Language, request.Message.Content)
 		} else {
-			pattern, err = o.db.Patterns.GetApplyVariables(request.PatternName, request.PatternVariables, request.Message.Content)
+			pattern, err = o.db.Patterns.GetLocalizedApplyVariables(request.PatternName, request.Language, request.PatternVariables, request.Message.Content)
 		}
 
 		if err != nil {
 			return nil, fmt.Errorf(i18n.T("chatter_error_get_pattern"), request.PatternName, err)
 		}
 		patternContent = pattern.Pattern
+		localized = pattern.Language != ""
 		inputUsed = true
 	}
 
 	systemMessage := joinPromptSections(contextContent, patternContent)
 
 	if request.StrategyName != "" {
-		strategy, err := strategy.LoadStrategy(request.StrategyName)
+		plan, err := strategy.Compose(request.StrategyName)
 		if err != nil {
 			return nil, fmt.Errorf(i18n.T("chatter_error_load_strategy"), request.StrategyName, err)
 		}
-		if strategy != nil && strategy.Prompt != "" {
-			systemMessage = joinPromptSections(strategy.Prompt, systemMessage)
-		}
+		systemMessage = joinPromptSections(plan.Prompt, systemMessage)
 	}
 
 	// Apply refined language instruction if specified
-	if request.Language != "" && request.Language != "en" {
+	if request.Language != "" && request.Language != "en" && !localized {
 		// Refined instruction: Execute pattern using user input, then translate the entire response.
 		systemMessage = fmt.Sprintf(i18n.T("chatter_prompt_enforce_response_language"), systemMessage, request.Language)
 	}
@@ -351,3 +609,14 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 	}
 	return
 }
+
+// sendWithLogprobs sends the messages to a vendor able to return the log
+// probabilities of the response tokens, keeping them in Logprobs.
+func (o *Chatter) sendWithLogprobs(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+	sender, ok := o.vendor.(ai.LogprobsSender)
+	if !ok {
+		return "", fmt.Errorf(i18n.T("chatter_error_vendor_no_logprobs"), o.VendorName())
+	}
+	ret, o.Logprobs, err = sender.SendWithLogprobs(ctx, msgs, opts)
+	return
+}
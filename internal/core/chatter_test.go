@@ -4,14 +4,23 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/hooks"
+	"github.com/danielmiessler/fabric/internal/tools/redact"
 )
 
 // mockVendor implements the ai.Vendor interface for testing
@@ -44,6 +53,10 @@ func (m *mockVendor) Setup() error {
 func (m *mockVendor) SetupFillEnvFileContent(*bytes.Buffer) {
 }
 
+func (m *mockVendor) GetSettings() plugins.Settings {
+	return nil
+}
+
 func (m *mockVendor) ListModels(context.Context) ([]string, error) {
 	return []string{"test-model"}, nil
 }
@@ -193,6 +206,48 @@ func TestChatter_Send_SuppressThink(t *testing.T) {
 	}
 }
 
+func TestChatter_Send_ThinkOutput(t *testing.T) {
+	thinkFile := filepath.Join(t.TempDir(), "thinking.md")
+	mockVendor := &mockVendor{
+		streamChunks: []domain.StreamUpdate{
+			{Type: domain.StreamTypeReasoning, Content: "vendor reasoning"},
+			{Type: domain.StreamTypeContent, Content: "<think>tagged</think>\n\nvisible"},
+		},
+	}
+	chatter := &Chatter{
+		db:     fsdb.NewDb(t.TempDir()),
+		Stream: true,
+		vendor: mockVendor,
+		model:  "test-model",
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test"},
+	}
+	opts := &domain.ChatOptions{
+		Model:         "test-model",
+		ThinkStartTag: "<think>",
+		ThinkEndTag:   "</think>",
+		ThinkOutput:   thinkFile,
+		Quiet:         true,
+	}
+
+	session, err := chatter.Send(context.Background(), request, opts)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if last := session.GetLastMessage(); last.Content != "visible" {
+		t.Errorf("expected clean answer 'visible', got %q", last.Content)
+	}
+
+	thinking, err := os.ReadFile(thinkFile)
+	if err != nil {
+		t.Fatalf("failed to read thinking file: %v", err)
+	}
+	if string(thinking) != "vendor reasoning\n\ntagged\n" {
+		t.Errorf("unexpected thinking %q", thinking)
+	}
+}
+
 func TestChatter_BuildSession_SeparatesSystemSections(t *testing.T) {
 	tempDir := t.TempDir()
 	db := fsdb.NewDb(tempDir)
@@ -263,6 +318,40 @@ func TestChatter_BuildSession_SeparatesSystemSections(t *testing.T) {
 	}
 }
 
+func TestChatter_BuildSession_ComposesContexts(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(db.Contexts.Dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"persona": "You are a reviewer.",
+		"project": "The project is {{project}}.",
+	} {
+		if err := os.WriteFile(filepath.Join(db.Contexts.Dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chatter := &Chatter{db: db}
+	request := &domain.ChatRequest{
+		ContextName:      "persona, project",
+		ContextVariables: map[string]string{"project": "fabric"},
+		Message:          &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "review this"},
+	}
+	session, err := chatter.BuildSession(request, false)
+	if err != nil {
+		t.Fatalf("BuildSession returned error: %v", err)
+	}
+	if got, want := session.GetVendorMessages()[0].Content, "You are a reviewer.\nThe project is fabric."; got != want {
+		t.Errorf("system message = %q, want %q", got, want)
+	}
+
+	request.ContextName = "persona,missing"
+	if _, err = chatter.BuildSession(request, false); err == nil {
+		t.Error("expected an error for a missing context")
+	}
+}
+
 func TestChatter_Send_StreamingErrorPropagation(t *testing.T) {
 	// Create a temporary database for testing
 	tempDir := t.TempDir()
@@ -519,3 +608,597 @@ func TestChatter_Send_StreamingMetadataPropagation(t *testing.T) {
 		t.Error("Expected to receive a usage metadata update, but didn't")
 	}
 }
+
+func TestChatter_Send_Redact(t *testing.T) {
+	var sent string
+	chatter := &Chatter{
+		db: fsdb.NewDb(t.TempDir()),
+		vendor: &mockVendor{
+			sendFunc: func(_ context.Context, messages []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+				sent = messages[len(messages)-1].Content
+				return "I will write to [EMAIL_1]", nil
+			},
+		},
+		model:    "test-model",
+		Redactor: redact.New(),
+	}
+
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "Email jane@example.com"},
+	}
+	session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if sent != "Email [EMAIL_1]" {
+		t.Errorf("vendor received %q, expected the email to be masked", sent)
+	}
+	if got := session.GetLastMessage().Content; got != "I will write to jane@example.com" {
+		t.Errorf("response = %q, expected the email to be restored", got)
+	}
+	if request.Message.Content != "Email jane@example.com" {
+		t.Errorf("request message was modified: %q", request.Message.Content)
+	}
+}
+
+// logprobsVendor returns the log probabilities of its response
+type logprobsVendor struct {
+	mockVendor
+	opts *domain.ChatOptions
+}
+
+func (m *logprobsVendor) SendWithLogprobs(_ context.Context, _ []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, []domain.TokenLogprob, error) {
+	m.opts = opts
+	return "Yes", []domain.TokenLogprob{{Token: "Yes", Logprob: -0.2, TopLogprobs: []domain.TokenLogprob{{Token: "No", Logprob: -1.7}}}}, nil
+}
+
+func TestChatter_Send_Logprobs(t *testing.T) {
+	vendor := &logprobsVendor{}
+	chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: vendor, model: "test-model", Stream: true}
+	request := &domain.ChatRequest{Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "Is it?"}}
+
+	session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Logprobs: true, TopLogprobs: 1, Quiet: true})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := session.GetLastMessage().Content; got != "Yes" {
+		t.Errorf("response = %q, want Yes", got)
+	}
+	if vendor.opts == nil || vendor.opts.TopLogprobs != 1 {
+		t.Errorf("the log probabilities were not requested from the vendor, even when streaming")
+	}
+	if len(chatter.Logprobs) != 1 || chatter.Logprobs[0].TopLogprobs[0].Token != "No" {
+		t.Errorf("Logprobs = %+v", chatter.Logprobs)
+	}
+
+	// A vendor without log probabilities fails rather than silently dropping them
+	chatter = &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: &mockVendor{}, model: "test-model"}
+	if _, err = chatter.Send(context.Background(), request, &domain.ChatOptions{Logprobs: true}); err == nil {
+		t.Error("Send() succeeded with a vendor unable to return log probabilities")
+	}
+}
+
+func TestChatter_Send_InterruptedSavesPartialSession(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(db.Sessions.Dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	chatter := &Chatter{
+		db:     db,
+		Stream: true,
+		vendor: &mockVendor{
+			streamChunks:    []domain.StreamUpdate{{Type: domain.StreamTypeContent, Content: "partial answer"}},
+			sendStreamError: context.Canceled,
+		},
+		model: "test-model",
+	}
+	request := &domain.ChatRequest{
+		SessionName: "interrupted",
+		Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+	}
+
+	_, err := chatter.Send(ctx, request, &domain.ChatOptions{Model: "test-model", Quiet: true})
+	if err == nil || err.Error() != "the request was interrupted" {
+		t.Fatalf("Send() error = %v, expected the request to be interrupted", err)
+	}
+
+	session, err := db.Sessions.Get("interrupted")
+	if err != nil {
+		t.Fatalf("failed to load the session: %v", err)
+	}
+	if len(session.Messages) != 2 || session.Messages[1].Content != "partial answer" {
+		t.Errorf("session messages = %+v, expected the question and the partial answer", session.Messages)
+	}
+}
+
+func TestChatter_Send_RecordsSessionMetadata(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(db.Sessions.Dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chatter := &Chatter{
+		db: db,
+		vendor: &mockVendor{sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+			return "answer", nil
+		}},
+		model: "test-model",
+	}
+	request := &domain.ChatRequest{
+		SessionName: "notes",
+		SessionTags: []string{"work"},
+		Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "What is DNS?"},
+	}
+	if _, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model", Quiet: true}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	meta, err := db.Sessions.GetMeta("notes")
+	if err != nil {
+		t.Fatalf("GetMeta() error = %v", err)
+	}
+	if meta.Title != "What is DNS?" || meta.Vendor != "mock" || meta.Model != "test-model" || !slices.Equal(meta.Tags, []string{"work"}) {
+		t.Errorf("session metadata = %+v", meta)
+	}
+}
+
+func TestChatter_Send_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	chatter := &Chatter{
+		db: fsdb.NewDb(t.TempDir()),
+		vendor: &mockVendor{
+			sendFunc: func(ctx context.Context, _ []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+		},
+		model: "test-model",
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "question"},
+	}
+
+	if _, err := chatter.Send(ctx, request, &domain.ChatOptions{Model: "test-model"}); err == nil || err.Error() != "the request timed out" {
+		t.Errorf("Send() error = %v, expected a timeout", err)
+	}
+}
+
+func TestChatter_Send_StreamErrorSavesPartialForResume(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.MkdirAll(db.Sessions.Dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chatter := &Chatter{
+		db:     db,
+		Stream: true,
+		vendor: &mockVendor{
+			streamChunks:    []domain.StreamUpdate{{Type: domain.StreamTypeContent, Content: "The first half"}},
+			sendStreamError: errors.New("connection reset"),
+		},
+		model: "test-model",
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "write a story"},
+	}
+
+	if _, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model", Quiet: true}); err == nil || err.Error() != "connection reset" {
+		t.Fatalf("Send() error = %v, expected the stream error", err)
+	}
+	if !db.Sessions.Exists(fsdb.InterruptedSessionName) {
+		t.Fatal("expected the partial response to be saved for --resume")
+	}
+
+	// Resuming sends the conversation with a continuation prompt
+	var sent []string
+	chatter.Stream = false
+	chatter.vendor = &mockVendor{
+		sendFunc: func(_ context.Context, messages []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+			for _, message := range messages {
+				sent = append(sent, message.Content)
+			}
+			return " and the second half", nil
+		},
+	}
+	resume := &domain.ChatRequest{SessionName: fsdb.InterruptedSessionName, Resume: true}
+	session, err := chatter.Send(context.Background(), resume, &domain.ChatOptions{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("Send() resume error = %v", err)
+	}
+
+	if want := []string{"write a story", "The first half", continuationPrompt}; !slices.Equal(sent, want) {
+		t.Errorf("resume sent %q, want %q", sent, want)
+	}
+	if got := session.GetLastMessage().Content; got != "The first half and the second half" {
+		t.Errorf("resumed response = %q", got)
+	}
+	if db.Sessions.Exists(fsdb.InterruptedSessionName) {
+		t.Error("expected the interrupted session to be removed once resumed")
+	}
+}
+
+func TestChatter_Send_ResumeWithoutResponse(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	chatter := &Chatter{db: db, vendor: &mockVendor{}, model: "test-model"}
+
+	request := &domain.ChatRequest{SessionName: "missing", Resume: true}
+	if _, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model"}); err == nil {
+		t.Error("expected an error when there is nothing to resume")
+	}
+}
+
+func TestChatter_Send_SamplesAndAggregates(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	strategyDir := filepath.Join(homeDir, ".config", "fabric", "strategies")
+	if err := os.MkdirAll(strategyDir, 0o755); err != nil {
+		t.Fatalf("failed to create strategy directory: %v", err)
+	}
+	strategy := `{"prompt":"Reason, then answer.","samples":3,"aggregate":"vote"}`
+	if err := os.WriteFile(filepath.Join(strategyDir, "vote.json"), []byte(strategy), 0o644); err != nil {
+		t.Fatalf("failed to write strategy: %v", err)
+	}
+
+	var mu sync.Mutex
+	var seeds []int
+	var aggregate []*chat.ChatCompletionMessage
+	chatter := &Chatter{
+		db:     fsdb.NewDb(t.TempDir()),
+		Stream: true,
+		vendor: &mockVendor{
+			sendFunc: func(_ context.Context, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if messages[0].Content == votePrompt {
+					aggregate = messages
+					return "42", nil
+				}
+				seeds = append(seeds, opts.Seed)
+				return fmt.Sprintf("<think>sample %d</think>%d", len(seeds), 40+len(seeds)), nil
+			},
+		},
+		model: "test-model",
+	}
+	request := &domain.ChatRequest{
+		StrategyName: "vote:n=4",
+		Message:      &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "what is the answer?"},
+	}
+
+	session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model", Seed: 7, Quiet: true, ThinkStartTag: "<think>", ThinkEndTag: "</think>"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	slices.Sort(seeds)
+	if want := []int{7, 8, 9, 10}; !slices.Equal(seeds, want) {
+		t.Errorf("sampled with seeds %v, want %v", seeds, want)
+	}
+	if aggregate == nil {
+		t.Fatal("expected an aggregation request")
+	}
+	candidates := aggregate[1].Content
+	for _, want := range []string{"what is the answer?", "Answer 1:\n\n4", "Answer 4:\n\n4"} {
+		if !strings.Contains(candidates, want) {
+			t.Errorf("aggregation request %q does not contain %q", candidates, want)
+		}
+	}
+	if strings.Contains(candidates, "<think>") {
+		t.Errorf("aggregation request %q contains thinking", candidates)
+	}
+	if got := session.GetLastMessage().Content; got != "42" {
+		t.Errorf("response = %q, want the aggregate", got)
+	}
+}
+
+func TestChatter_Send_SelectsSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domain.ChatRequest
+		samples []string
+		judge   string
+		want    string
+	}{
+		{
+			name:    "all",
+			request: domain.ChatRequest{Samples: 2, Select: "all"},
+			samples: []string{"red", "blue"},
+			want:    "## Response 1\n\nred\n\n## Response 2\n\nblue",
+		},
+		{
+			name:    "vote counts equal structured answers",
+			request: domain.ChatRequest{Samples: 3},
+			samples: []string{`{"a": 1, "b": 2}`, "```json\n{\"b\":2,\"a\":1}\n```", `{"a": 3}`},
+			want:    `{"a": 1, "b": 2}`,
+		},
+		{
+			name:    "vote asks the model on a tie",
+			request: domain.ChatRequest{Samples: 2, Select: "vote"},
+			samples: []string{"yes", "no"},
+			want:    "voted",
+		},
+		{
+			name:    "best",
+			request: domain.ChatRequest{Samples: 3, Select: "best"},
+			samples: []string{"short", "thorough", "wrong"},
+			judge:   "Answer 2 is the best.",
+			want:    "thorough",
+		},
+		{
+			name:    "best keeps the first answer when the judge names none",
+			request: domain.ChatRequest{Samples: 2, Select: "best"},
+			samples: []string{"short", "thorough"},
+			judge:   "Both are fine.",
+			want:    "short",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			sent := 0
+			chatter := &Chatter{
+				db: fsdb.NewDb(t.TempDir()),
+				vendor: &mockVendor{
+					sendFunc: func(_ context.Context, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						switch messages[0].Content {
+						case judgePrompt:
+							return tt.judge, nil
+						case votePrompt:
+							return "voted", nil
+						}
+						sent++
+						// The samples are sent concurrently, with seeds 1, 2, ...
+						return tt.samples[opts.Seed-1], nil
+					},
+				},
+				model: "test-model",
+			}
+			request := tt.request
+			request.Message = &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "pick a color"}
+
+			session, err := chatter.Send(context.Background(), &request, &domain.ChatOptions{Model: "test-model", Seed: 1, Quiet: true})
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if sent != len(tt.samples) {
+				t.Errorf("sampled %d responses, want %d", sent, len(tt.samples))
+			}
+			if got := session.GetLastMessage().Content; got != tt.want {
+				t.Errorf("response = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChatter_Send_RefinesUntilThreshold(t *testing.T) {
+	scores := []string{"Too vague.\nScore: 5/10", "Score: 9 / 10"}
+	var critiques, revisions int
+	var revised []*chat.ChatCompletionMessage
+	chatter := &Chatter{
+		db: fsdb.NewDb(t.TempDir()),
+		vendor: &mockVendor{
+			sendFunc: func(_ context.Context, messages []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+				switch {
+				case messages[0].Content == critiquePrompt:
+					critiques++
+					return scores[critiques-1], nil
+				case len(messages) > 1:
+					revisions++
+					revised = messages
+					return fmt.Sprintf("draft %d", revisions+1), nil
+				}
+				return "draft 1", nil
+			},
+		},
+		model: "test-model",
+	}
+	request := &domain.ChatRequest{
+		Refine:          3,
+		RefineThreshold: 8,
+		Message:         &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "explain DNS"},
+	}
+
+	session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model", Quiet: true})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if critiques != 2 || revisions != 1 {
+		t.Errorf("got %d critiques and %d revisions, want 2 and 1", critiques, revisions)
+	}
+	if len(revised) != 3 || revised[1].Content != "draft 1" || !strings.Contains(revised[2].Content, "Too vague.") {
+		t.Errorf("unexpected revision request %+v", revised)
+	}
+	if got := session.GetLastMessage().Content; got != "draft 2" {
+		t.Errorf("response = %q, want the revised draft", got)
+	}
+}
+
+func TestChatter_BuildSession_LocalizedPattern(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	patternDir := filepath.Join(db.Patterns.Dir, "summarize")
+	if err := os.MkdirAll(patternDir, 0o755); err != nil {
+		t.Fatalf("failed to create pattern directory: %v", err)
+	}
+	for file, content := range map[string]string{"system.md": "Summarize.", "system.fr.md": "Résume."} {
+		if err := os.WriteFile(filepath.Join(patternDir, file), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write pattern: %v", err)
+		}
+	}
+
+	chatter := &Chatter{db: db}
+	tests := []struct {
+		language string
+		want     string
+	}{
+		// The localized variant is used as is
+		{language: "fr", want: "Résume.\ninput"},
+		{language: "fr-CA", want: "Résume.\ninput"},
+		// Otherwise the pattern is told to answer in the language
+		{language: "de", want: fmt.Sprintf(i18n.T("chatter_prompt_enforce_response_language"), "Summarize.\ninput", "de")},
+		{language: "", want: "Summarize.\ninput"},
+	}
+	for _, tt := range tests {
+		request := &domain.ChatRequest{
+			PatternName: "summarize",
+			Language:    tt.language,
+			Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "input"},
+		}
+		session, err := chatter.BuildSession(request, false)
+		if err != nil {
+			t.Fatalf("BuildSession(%q) error = %v", tt.language, err)
+		}
+		if got := session.GetVendorMessages()[0].Content; got != tt.want {
+			t.Errorf("BuildSession(%q) system message = %q, want %q", tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestChatter_Send_TranslatesOutput(t *testing.T) {
+	var translation []*chat.ChatCompletionMessage
+	chatter := &Chatter{
+		db: fsdb.NewDb(t.TempDir()),
+		vendor: &mockVendor{
+			sendFunc: func(_ context.Context, messages []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+				if messages[0].Role == chat.ChatMessageRoleSystem {
+					translation = messages
+					return "Le DNS traduit les noms.", nil
+				}
+				return "<think>plan</think>DNS translates names.", nil
+			},
+		},
+		model:  "test-model",
+		Stream: true,
+	}
+	request := &domain.ChatRequest{
+		TranslateOutput: "fr",
+		Message:         &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "explain DNS"},
+	}
+
+	opts := &domain.ChatOptions{Model: "test-model", Quiet: true, ThinkStartTag: "<think>", ThinkEndTag: "</think>"}
+	session, err := chatter.Send(context.Background(), request, opts)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(translation) != 2 || translation[0].Content != fmt.Sprintf(translatePrompt, "fr") || translation[1].Content != "DNS translates names." {
+		t.Errorf("unexpected translation request %+v", translation)
+	}
+	if got := session.GetLastMessage().Content; got != "Le DNS traduit les noms." {
+		t.Errorf("response = %q, want the translation", got)
+	}
+}
+
+func TestChatter_Send_Hooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks of the test are sh commands")
+	}
+	var sent string
+	chatter := &Chatter{
+		db: fsdb.NewDb(t.TempDir()),
+		vendor: &mockVendor{
+			sendFunc: func(_ context.Context, messages []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+				sent = messages[len(messages)-1].Content
+				return "the password is hunter2", nil
+			},
+			streamChunks: []domain.StreamUpdate{{Type: domain.StreamTypeContent, Content: "the password is hunter2"}},
+		},
+		model: "test-model",
+		Hooks: &hooks.Hooks{
+			Pre:  []string{`sed 's/Hello/Bonjour/'`},
+			Post: []string{`sed 's/hunter2/[scrubbed]/'`},
+		},
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "Hello"},
+	}
+
+	session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent != "Bonjour" {
+		t.Errorf("vendor received %q, expected the pre hook to rewrite it", sent)
+	}
+	if got := session.GetLastMessage().Content; got != "the password is [scrubbed]" {
+		t.Errorf("response = %q, expected the post hook to scrub it", got)
+	}
+
+	// A streamed response is held back until the post hooks ran
+	chatter.Stream = true
+	updates := make(chan domain.StreamUpdate, 10)
+	if _, err = chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model", Quiet: true, UpdateChan: updates}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	close(updates)
+	var streamed strings.Builder
+	for update := range updates {
+		streamed.WriteString(update.Content)
+	}
+	if got := streamed.String(); got != "the password is [scrubbed]" {
+		t.Errorf("streamed %q, expected only the scrubbed response", got)
+	}
+}
+
+// toolsVendor calls the weather tool once, then answers with its result
+type toolsVendor struct {
+	mockVendor
+	sent [][]*chat.ChatCompletionMessage
+}
+
+func (m *toolsVendor) SendWithTools(_ context.Context, msgs []*chat.ChatCompletionMessage, _ *domain.ChatOptions, tools []domain.Tool) (*chat.ChatCompletionMessage, error) {
+	m.sent = append(m.sent, msgs)
+	last := msgs[len(msgs)-1]
+	if last.Role != chat.ChatMessageRoleTool {
+		return &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, ToolCalls: []chat.ToolCall{{
+			ID: "call_1", Type: chat.ToolTypeFunction,
+			Function: chat.FunctionCall{Name: tools[0].Name, Arguments: `{"city":"Paris"}`},
+		}}}, nil
+	}
+	return &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: "It is " + last.Content}, nil
+}
+
+// weatherToolbox has a weather tool failing for every city but Paris
+type weatherToolbox struct{}
+
+func (weatherToolbox) Tools() []domain.Tool {
+	return []domain.Tool{{Name: "weather__forecast", Description: "Forecast of a city"}}
+}
+
+func (weatherToolbox) Call(_ context.Context, name string, arguments string) (string, error) {
+	if name != "weather__forecast" || arguments != `{"city":"Paris"}` {
+		return "", errors.New("unknown city")
+	}
+	return "sunny", nil
+}
+
+func TestChatter_Send_Tools(t *testing.T) {
+	vendor := &toolsVendor{}
+	chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: vendor, model: "test-model", Stream: true, Tools: weatherToolbox{}}
+	request := &domain.ChatRequest{Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "Weather in Paris?"}}
+
+	session, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model", Quiet: true})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := session.GetLastMessage().Content; got != "It is sunny" {
+		t.Errorf("response = %q, want It is sunny", got)
+	}
+	if len(vendor.sent) != 2 {
+		t.Fatalf("vendor called %d times, want 2", len(vendor.sent))
+	}
+	result := vendor.sent[1][len(vendor.sent[1])-1]
+	if result.ToolCallID != "call_1" || result.Name != "weather__forecast" {
+		t.Errorf("tool result = %+v, expected it to answer the call", result)
+	}
+
+	// A vendor unable to call tools fails rather than answering without them
+	chatter = &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: &mockVendor{}, model: "test-model", Tools: weatherToolbox{}}
+	if _, err = chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model"}); err == nil {
+		t.Error("Send() succeeded with a vendor unable to call tools")
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+// maxResponseReserve is the part of the context window kept free for the response
+// when --max-tokens is not set.
+const maxResponseReserve = 4096
+
+// contextWindow returns the context window of the model: the configured context
+// length, the one reported by the vendor API, or the built-in table. 0 means unknown.
+func (o *Chatter) contextWindow(ctx context.Context, opts *domain.ChatOptions) int {
+	if opts.ModelContextLength > 0 {
+		return opts.ModelContextLength
+	}
+	if provider, ok := o.vendor.(ai.ContextWindowProvider); ok {
+		window, err := provider.ContextWindow(ctx, o.model)
+		if err == nil && window > 0 {
+			return window
+		}
+		debuglog.Debug(debuglog.Detailed, "Could not get context window of %s from the vendor: %v\n", o.model, err)
+	}
+	caps, _ := ai.LookupModelCapabilities(o.model)
+	return caps.ContextWindow
+}
+
+// fitContextWindow checks that the messages fit the model context window before
+// they are sent. Oversized input is truncated as requested by opts.Truncate;
+// without it an error explains the problem instead of an opaque vendor failure.
+func (o *Chatter) fitContextWindow(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (err error) {
+	window := o.contextWindow(ctx, opts)
+	if window == 0 {
+		return
+	}
+
+	reserve := min(maxResponseReserve, window/4)
+	if opts.MaxTokens > 0 {
+		reserve = opts.MaxTokens
+	}
+	budget := window - reserve
+
+	total := 0
+	for _, msg := range msgs {
+		for _, text := range messageTexts(msg) {
+			total += ai.EstimateTokens(*text)
+		}
+	}
+	if total <= budget {
+		return
+	}
+	if opts.Truncate == "" {
+		return fmt.Errorf(i18n.T("chatter_error_context_window_exceeded"), total, o.model, window, reserve)
+	}
+
+	// Shorten the largest text of the latest user message, which holds the input
+	var input *string
+	for i := len(msgs) - 1; i >= 0 && input == nil; i-- {
+		if msgs[i].Role != chat.ChatMessageRoleUser {
+			continue
+		}
+		for _, text := range messageTexts(msgs[i]) {
+			if input == nil || len(*text) > len(*input) {
+				input = text
+			}
+		}
+	}
+
+	inputTokens := 0
+	if input != nil {
+		inputTokens = ai.EstimateTokens(*input)
+	}
+	keepTokens := inputTokens - (total - budget)
+	if keepTokens <= 0 {
+		return fmt.Errorf(i18n.T("chatter_error_context_window_no_room_for_input"), total-inputTokens, o.model, window, reserve)
+	}
+
+	*input = domain.TruncateText(*input, keepTokens*4, opts.Truncate)
+	debuglog.Log(i18n.T("chatter_warning_input_truncated"), inputTokens, keepTokens, opts.Truncate, o.model, window)
+	return
+}
+
+// messageTexts returns pointers to the text content of a message.
+func messageTexts(msg *chat.ChatCompletionMessage) (ret []*string) {
+	if msg.Content != "" {
+		ret = append(ret, &msg.Content)
+	}
+	for i := range msg.MultiContent {
+		if msg.MultiContent[i].Type == chat.ChatMessagePartTypeText {
+			ret = append(ret, &msg.MultiContent[i].Text)
+		}
+	}
+	return
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+// windowVendor reports a fixed context window for every model.
+type windowVendor struct {
+	mockVendor
+	window int
+}
+
+func (m *windowVendor) ContextWindow(context.Context, string) (int, error) {
+	return m.window, nil
+}
+
+func TestFitContextWindow(t *testing.T) {
+	newMessages := func(input string) []*chat.ChatCompletionMessage {
+		return []*chat.ChatCompletionMessage{
+			{Role: chat.ChatMessageRoleSystem, Content: strings.Repeat("s", 400)},
+			{Role: chat.ChatMessageRoleUser, Content: input},
+		}
+	}
+	// 1000 tokens window, 250 kept free for the response, 100 used by the system message
+	chatter := &Chatter{model: "test-model", vendor: &windowVendor{window: 1000}}
+
+	t.Run("fits", func(t *testing.T) {
+		msgs := newMessages(strings.Repeat("a", 2000))
+		if err := chatter.fitContextWindow(context.Background(), msgs, &domain.ChatOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("too large without truncation", func(t *testing.T) {
+		msgs := newMessages(strings.Repeat("a", 4000))
+		if err := chatter.fitContextWindow(context.Background(), msgs, &domain.ChatOptions{}); err == nil {
+			t.Fatal("expected context window error")
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		msgs := newMessages(strings.Repeat("a", 3000) + strings.Repeat("b", 1000))
+		if err := chatter.fitContextWindow(context.Background(), msgs, &domain.ChatOptions{Truncate: domain.TruncateTail}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		input := msgs[1].Content
+		if !strings.HasSuffix(input, domain.TruncationMarker) || strings.Contains(input, "b") {
+			t.Errorf("expected the tail to be dropped, got %q...", input[:20])
+		}
+		if got := len([]rune(input)); got > 650*4 {
+			t.Errorf("truncated input has %d runes, want at most %d", got, 650*4)
+		}
+	})
+
+	t.Run("no room for input", func(t *testing.T) {
+		msgs := newMessages("hi")
+		msgs[0].Content = strings.Repeat("s", 4000)
+		if err := chatter.fitContextWindow(context.Background(), msgs, &domain.ChatOptions{Truncate: domain.TruncateHead}); err == nil {
+			t.Fatal("expected error when the system prompt alone exceeds the window")
+		}
+	})
+
+	t.Run("configured context length wins", func(t *testing.T) {
+		msgs := newMessages(strings.Repeat("a", 2000))
+		if err := chatter.fitContextWindow(context.Background(), msgs, &domain.ChatOptions{ModelContextLength: 200}); err == nil {
+			t.Fatal("expected error with a 200-token context length")
+		}
+	})
+
+	t.Run("unknown window skips the check", func(t *testing.T) {
+		unknown := &Chatter{model: "unknown-model", vendor: &mockVendor{}}
+		msgs := newMessages(strings.Repeat("a", 1000000))
+		if err := unknown.fitContextWindow(context.Background(), msgs, &domain.ChatOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
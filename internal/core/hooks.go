@@ -0,0 +1,44 @@
+package core
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/tools/hooks"
+)
+
+// runPreHooks passes the messages sent to the vendor through the pre hooks,
+// returning them with the context of the request, which carries the headers
+// the hooks added.
+func (o *Chatter) runPreHooks(ctx context.Context, request *domain.ChatRequest, msgs []*chat.ChatCompletionMessage) (retCtx context.Context, ret []*chat.ChatCompletionMessage, err error) {
+	if o.Hooks == nil || len(o.Hooks.Pre) == 0 {
+		return ctx, msgs, nil
+	}
+	hookRequest := &hooks.Request{Vendor: o.VendorName(), Model: o.model, Pattern: request.PatternName, Messages: msgs}
+	if err = o.Hooks.RunPre(ctx, hookRequest); err != nil {
+		return ctx, nil, err
+	}
+	if len(hookRequest.Headers) > 0 {
+		header := http.Header{}
+		for name, value := range hookRequest.Headers {
+			header.Set(name, value)
+		}
+		ctx = plugins.WithHeaders(ctx, header)
+	}
+	return ctx, hookRequest.Messages, nil
+}
+
+// runPostHooks passes the response through the post hooks.
+func (o *Chatter) runPostHooks(ctx context.Context, request *domain.ChatRequest, message string) (ret string, err error) {
+	if o.Hooks == nil || len(o.Hooks.Post) == 0 || o.DryRun {
+		return message, nil
+	}
+	response := &hooks.Response{Vendor: o.VendorName(), Model: o.model, Pattern: request.PatternName, Output: message}
+	if err = o.Hooks.RunPost(ctx, response); err != nil {
+		return
+	}
+	return response.Output, nil
+}
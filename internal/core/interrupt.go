@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// continuationPrompt asks the model to continue a response that was cut off.
+const continuationPrompt = "Your previous response was cut off. Continue it exactly where it stopped, " +
+	"without repeating anything and without any preamble."
+
+// interruption returns the error of a chat interrupted by the vendor or by the
+// cancellation or the timeout of its context. The session is saved with the
+// part of the response received so far, under fsdb.InterruptedSessionName when
+// it has no name, so that --resume can continue it.
+func (o *Chatter) interruption(ctx context.Context, session *fsdb.Session, request *domain.ChatRequest,
+	partial string, err error) error {
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = errors.New(i18n.T("chatter_error_timeout"))
+	} else if ctx.Err() != nil {
+		err = errors.New(i18n.T("chatter_error_interrupted"))
+	}
+	if o.DryRun {
+		return err
+	}
+
+	// Content held back for moderation has not passed it
+	if partial = strings.TrimSpace(partial); partial != "" && !o.holdsOutput() {
+		appendResponse(session, request, partial)
+		if session.Name == "" {
+			session.Name = fsdb.InterruptedSessionName
+		}
+	} else if ctx.Err() == nil || session.Name == "" || session.Name == fsdb.InterruptedSessionName {
+		return err
+	}
+	if saveErr := o.db.Sessions.SaveSession(session); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	debuglog.Log(i18n.T("chatter_log_partial_session_saved"), session.Name)
+	return err
+}
+
+// resumeSession loads the session of a --resume request, whose last message is
+// the response to continue.
+func (o *Chatter) resumeSession(request *domain.ChatRequest) (session *fsdb.Session, err error) {
+	if !o.db.Sessions.Exists(request.SessionName) {
+		return nil, fmt.Errorf(i18n.T("chatter_error_nothing_to_resume"), request.SessionName)
+	}
+	if session, err = o.db.Sessions.Get(request.SessionName); err != nil {
+		return nil, fmt.Errorf(i18n.T("chatter_error_find_session"), request.SessionName, err)
+	}
+	if last := session.GetLastMessage(); last == nil || last.Role != chat.ChatMessageRoleAssistant {
+		return nil, fmt.Errorf(i18n.T("chatter_error_nothing_to_resume"), request.SessionName)
+	}
+	return
+}
+
+// resumeMessages returns the messages asking the model to continue the last
+// response of the session, without adding the request to the session.
+func resumeMessages(session *fsdb.Session) []*chat.ChatCompletionMessage {
+	return append(slices.Clone(session.GetVendorMessages()),
+		&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: continuationPrompt})
+}
+
+// appendResponse adds the response to the session, or to the response it
+// continues for a --resume request.
+func appendResponse(session *fsdb.Session, request *domain.ChatRequest, message string) {
+	if last := session.GetLastMessage(); request.Resume && last != nil && last.Role == chat.ChatMessageRoleAssistant {
+		last.Content += message
+		return
+	}
+	session.Append(&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: message})
+}
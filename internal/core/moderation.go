@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/tools/mdrender"
+	"github.com/danielmiessler/fabric/internal/tools/redact"
+)
+
+// Moderation screens the user input of a chat before it is sent to the vendor
+// and the response before it is returned.
+type Moderation struct {
+	Moderator ai.Moderator
+	// Annotate marks flagged content instead of blocking it
+	Annotate bool
+	// Thresholds are the scores from which categories are flagged, by category
+	Thresholds map[string]float64
+}
+
+// flaggedCategories returns the categories for which text is flagged, masking it
+// with the redactor if any.
+func (o *Moderation) flaggedCategories(ctx context.Context, text string, redactor *redact.Redactor) (ret []string, err error) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if redactor != nil {
+		// Sensitive data is not sent to the moderator either
+		text = redactor.Redact(text)
+	}
+	var results []ai.ModerationResult
+	if results, err = o.Moderator.Moderate(ctx, []string{text}); err != nil {
+		return nil, fmt.Errorf(i18n.T("moderation_error"), err)
+	}
+	for _, result := range results {
+		ret = append(ret, result.FlaggedCategories(o.Thresholds)...)
+	}
+	return
+}
+
+// holdsOutput reports whether a streamed response is held back until it passes
+// moderation and the post hooks, so that blocked or scrubbed content never
+// reaches the user.
+func (o *Chatter) holdsOutput() bool {
+	blocks := o.Moderation != nil && !o.Moderation.Annotate
+	rewrites := o.Hooks != nil && len(o.Hooks.Post) > 0
+	return (blocks || rewrites) && !o.DryRun
+}
+
+// moderateInput blocks a chat whose user input is flagged, or warns about it
+// when annotating.
+func (o *Chatter) moderateInput(ctx context.Context, request *domain.ChatRequest, opts *domain.ChatOptions) (err error) {
+	if o.Moderation == nil || o.DryRun || request.Message == nil {
+		return
+	}
+	var categories []string
+	if categories, err = o.Moderation.flaggedCategories(ctx, request.Message.Content, o.Redactor); err != nil || len(categories) == 0 {
+		return o.reportModeration(err, opts)
+	}
+	if o.Moderation.Annotate {
+		if !opts.Quiet {
+			debuglog.Log(i18n.T("moderation_input_flagged"), strings.Join(categories, ", "))
+		}
+		return
+	}
+	return o.reportModeration(fmt.Errorf(i18n.T("moderation_input_blocked"), strings.Join(categories, ", ")), opts)
+}
+
+// moderateOutput blocks a flagged response, or appends a note to it when
+// annotating.
+func (o *Chatter) moderateOutput(ctx context.Context, message string, opts *domain.ChatOptions) (ret string, err error) {
+	ret = message
+	if o.Moderation == nil || o.DryRun {
+		return
+	}
+	var categories []string
+	if categories, err = o.Moderation.flaggedCategories(ctx, message, o.Redactor); err != nil {
+		return ret, o.reportModeration(err, opts)
+	}
+
+	if len(categories) > 0 && !o.Moderation.Annotate {
+		return ret, o.reportModeration(fmt.Errorf(i18n.T("moderation_output_blocked"), strings.Join(categories, ", ")), opts)
+	}
+
+	if len(categories) > 0 {
+		ret += "\n\n" + fmt.Sprintf(i18n.T("moderation_output_annotation"), strings.Join(categories, ", "))
+	}
+	return
+}
+
+// releaseOutput prints and streams the part of the final response that was not
+// streamed yet: all of it when it was held back, or the note of moderation.
+func (o *Chatter) releaseOutput(streamed, message string, opts *domain.ChatOptions) {
+	addition := message
+	if !o.holdsOutput() {
+		addition = strings.TrimPrefix(message, streamed)
+	}
+	if o.Stream && addition != "" {
+		if opts.UpdateChan != nil {
+			opts.UpdateChan <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: addition}
+		}
+		if !opts.Quiet {
+			// A streamed answer already ends with a new line
+			addition = strings.TrimRight(strings.TrimPrefix(addition, "\n"), "\n")
+			if opts.RenderMarkdown {
+				addition = mdrender.Render(addition, mdrender.TerminalWidth(os.Stdout))
+			}
+			fmt.Println(addition)
+		}
+	}
+}
+
+// reportModeration sends a moderation error to the stream, if any, so that
+// server clients learn why the response stopped.
+func (o *Chatter) reportModeration(err error, opts *domain.ChatOptions) error {
+	if err != nil && opts.UpdateChan != nil {
+		opts.UpdateChan <- domain.StreamUpdate{Type: domain.StreamTypeError, Content: err.Error()}
+	}
+	return err
+}
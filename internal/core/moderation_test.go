@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newModerationChatter(t *testing.T, response string, annotate bool) *Chatter {
+	moderator, err := ai.NewTermModerator(map[string][]string{"secrets": {"password"}})
+	require.NoError(t, err)
+
+	return &Chatter{
+		db:     fsdb.NewDb(t.TempDir()),
+		Stream: false,
+		vendor: &mockVendor{
+			sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+				return response, nil
+			},
+			streamChunks: []domain.StreamUpdate{{Type: domain.StreamTypeContent, Content: response}},
+		},
+		model:      "test-model",
+		Moderation: &Moderation{Moderator: moderator, Annotate: annotate},
+	}
+}
+
+func moderationRequest(content string) *domain.ChatRequest {
+	return &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: content},
+	}
+}
+
+func TestChatter_Send_ModerationBlocksInput(t *testing.T) {
+	chatter := newModerationChatter(t, "fine", false)
+
+	_, err := chatter.Send(context.Background(), moderationRequest("my Password is hunter2"), &domain.ChatOptions{Model: "test-model"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secrets")
+}
+
+func TestChatter_Send_ModerationBlocksOutput(t *testing.T) {
+	chatter := newModerationChatter(t, "the password is hunter2", false)
+
+	session, err := chatter.Send(context.Background(), moderationRequest("hello"), &domain.ChatOptions{Model: "test-model"})
+	require.Error(t, err)
+	assert.Nil(t, session)
+}
+
+func TestChatter_Send_ModerationHoldsBlockedStream(t *testing.T) {
+	chatter := newModerationChatter(t, "the password is hunter2", false)
+	chatter.Stream = true
+
+	updates := make(chan domain.StreamUpdate, 10)
+	_, err := chatter.Send(context.Background(), moderationRequest("hello"), &domain.ChatOptions{Model: "test-model", Quiet: true, UpdateChan: updates})
+	require.Error(t, err)
+	close(updates)
+
+	var types []domain.StreamType
+	for update := range updates {
+		types = append(types, update.Type)
+	}
+	assert.Equal(t, []domain.StreamType{domain.StreamTypeError}, types)
+}
+
+func TestChatter_Send_ModerationAnnotatesOutput(t *testing.T) {
+	chatter := newModerationChatter(t, "the password is hunter2", true)
+
+	session, err := chatter.Send(context.Background(), moderationRequest("hello"), &domain.ChatOptions{Model: "test-model", Quiet: true})
+	require.NoError(t, err)
+	message := session.GetLastMessage().Content
+	assert.True(t, strings.HasPrefix(message, "the password is hunter2"))
+	assert.Contains(t, message, "secrets")
+}
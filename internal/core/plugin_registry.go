@@ -23,13 +23,18 @@ import (
 	"github.com/danielmiessler/fabric/internal/plugins/ai/digitalocean"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/dryrun"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/exolab"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/fastinference"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/gemini"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/llamacpp"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/lmstudio"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/mock"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/ollama"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/openai_compatible"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/openrouter"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/perplexity"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/vertexai"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/xai"
 	"github.com/danielmiessler/fabric/internal/plugins/strategy"
 
 	"github.com/samber/lo"
@@ -39,10 +44,20 @@ import (
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 	"github.com/danielmiessler/fabric/internal/plugins/template"
 	"github.com/danielmiessler/fabric/internal/tools"
+	"github.com/danielmiessler/fabric/internal/tools/cohere"
 	"github.com/danielmiessler/fabric/internal/tools/custom_patterns"
+	"github.com/danielmiessler/fabric/internal/tools/highlights"
+	"github.com/danielmiessler/fabric/internal/tools/hooks"
+	"github.com/danielmiessler/fabric/internal/tools/issues"
 	"github.com/danielmiessler/fabric/internal/tools/jina"
 	"github.com/danielmiessler/fabric/internal/tools/lang"
+	"github.com/danielmiessler/fabric/internal/tools/mailbox"
+	"github.com/danielmiessler/fabric/internal/tools/meetings"
+	"github.com/danielmiessler/fabric/internal/tools/notion"
+	"github.com/danielmiessler/fabric/internal/tools/remotesync"
 	"github.com/danielmiessler/fabric/internal/tools/spotify"
+	"github.com/danielmiessler/fabric/internal/tools/stix"
+	"github.com/danielmiessler/fabric/internal/tools/voyage"
 	"github.com/danielmiessler/fabric/internal/tools/youtube"
 	"github.com/danielmiessler/fabric/internal/util"
 )
@@ -58,6 +73,17 @@ func NewPluginRegistry(db *fsdb.Db) (ret *PluginRegistry, err error) {
 		Language:       lang.NewLanguage(),
 		Jina:           jina.NewClient(),
 		Spotify:        spotify.NewSpotify(),
+		Readwise:       highlights.NewReadwise(),
+		Raindrop:       highlights.NewRaindrop(),
+		Notion:         notion.NewNotion(),
+		Jira:           issues.NewJira(),
+		Linear:         issues.NewLinear(),
+		Zoom:           meetings.NewZoom(),
+		MISP:           stix.NewMISP(),
+		Voyage:         voyage.NewClient(),
+		Cohere:         cohere.NewClient(),
+		Email:          mailbox.NewMailbox(),
+		Sync:           remotesync.NewSync(),
 		Strategies:     strategy.NewStrategiesManager(),
 	}
 
@@ -76,6 +102,9 @@ func NewPluginRegistry(db *fsdb.Db) (ret *PluginRegistry, err error) {
 	vendors = append(vendors,
 		openai.NewClient(),
 		digitalocean.NewClient(),
+		fastinference.NewGroqClient(),
+		fastinference.NewCerebrasClient(),
+		fastinference.NewSambaNovaClient(),
 		ollama.NewClient(),
 		azure.NewClient(),
 		azureaigateway.NewClient(),
@@ -84,11 +113,15 @@ func NewPluginRegistry(db *fsdb.Db) (ret *PluginRegistry, err error) {
 		anthropic.NewClient(),
 		vertexai.NewClient(),
 		lmstudio.NewClient(),
+		llamacpp.NewClient(),
 		exolab.NewClient(),
+		openrouter.NewClient(),
 		perplexity.NewClient(),
 		codex.NewClient(),
 		copilot.NewClient(), // Microsoft 365 Copilot
 		bedrock.NewClient(), // AWS Bedrock - credentials configured via setup or AWS credential chain
+		xai.NewClient(),     // xAI Grok with live search
+		mock.NewClient(),    // Canned responses for developing patterns without an API
 	)
 
 	// Add all OpenAI-compatible providers
@@ -132,8 +165,27 @@ type PluginRegistry struct {
 	Language           *lang.Language
 	Jina               *jina.Client
 	Spotify            *spotify.Spotify
+	Readwise           *highlights.Readwise
+	Raindrop           *highlights.Raindrop
+	Notion             *notion.Notion
+	Jira               *issues.Jira
+	Linear             *issues.Linear
+	Zoom               *meetings.Zoom
+	MISP               *stix.MISP
+	Voyage             *voyage.Client
+	Cohere             *cohere.Client
+	Email              *mailbox.Mailbox
+	Sync               *remotesync.Sync
 	TemplateExtensions *template.ExtensionManager
 	Strategies         *strategy.StrategiesManager
+	// Moderation, when set, screens the chats of every chatter
+	Moderation *Moderation
+	// SessionPolicy, when set, limits the sessions of every chatter
+	SessionPolicy *SessionPolicy
+	// Hooks, when set, transform the requests and responses of every chatter
+	Hooks *hooks.Hooks
+	// Spend, when set, records the spend of every chatter
+	Spend *SpendTracking
 }
 
 func (o *PluginRegistry) SaveEnvFile() (err error) {
@@ -152,6 +204,17 @@ func (o *PluginRegistry) SaveEnvFile() (err error) {
 	o.YouTube.SetupFillEnvFileContent(&envFileContent)
 	o.Jina.SetupFillEnvFileContent(&envFileContent)
 	o.Spotify.SetupFillEnvFileContent(&envFileContent)
+	o.Readwise.SetupFillEnvFileContent(&envFileContent)
+	o.Raindrop.SetupFillEnvFileContent(&envFileContent)
+	o.Notion.SetupFillEnvFileContent(&envFileContent)
+	o.Jira.SetupFillEnvFileContent(&envFileContent)
+	o.Linear.SetupFillEnvFileContent(&envFileContent)
+	o.Zoom.SetupFillEnvFileContent(&envFileContent)
+	o.MISP.SetupFillEnvFileContent(&envFileContent)
+	o.Voyage.SetupFillEnvFileContent(&envFileContent)
+	o.Cohere.SetupFillEnvFileContent(&envFileContent)
+	o.Email.SetupFillEnvFileContent(&envFileContent)
+	o.Sync.SetupFillEnvFileContent(&envFileContent)
 	o.Language.SetupFillEnvFileContent(&envFileContent)
 
 	err = o.Db.SaveEnv(envFileContent.String())
@@ -325,7 +388,7 @@ func (o *PluginRegistry) runInteractiveSetup() (err error) {
 	groupsPlugins.AddGroupItems(i18n.T("setup_required_tools"), o.Defaults, o.PatternsLoader, o.Strategies)
 
 	// Add optional tools
-	groupsPlugins.AddGroupItems(i18n.T("setup_optional_configuration_header"), o.CustomPatterns, o.Jina, o.Language, o.Spotify, o.YouTube)
+	groupsPlugins.AddGroupItems(i18n.T("setup_optional_configuration_header"), o.CustomPatterns, o.Cohere, o.Email, o.Jina, o.Jira, o.Language, o.Linear, o.MISP, o.Notion, o.Raindrop, o.Readwise, o.Spotify, o.Sync, o.Voyage, o.YouTube, o.Zoom)
 
 	for {
 		groupsPlugins.Print(false)
@@ -466,19 +529,34 @@ func (o *PluginRegistry) Configure() (err error) {
 		o.PatternsLoader.Patterns.CustomPatternsDir = customPatternsDir
 	}
 
-	//YouTube, Jina, Spotify are not mandatory, so ignore not configured error
+	//YouTube, Jina, Spotify, Readwise, Raindrop, Notion, Jira, Linear, Zoom, MISP, Voyage, Cohere, Email are not mandatory, so ignore not configured error
 	_ = o.YouTube.Configure()
 	_ = o.Jina.Configure()
 	_ = o.Spotify.Configure()
+	_ = o.Readwise.Configure()
+	_ = o.Raindrop.Configure()
+	_ = o.Notion.Configure()
+	_ = o.Jira.Configure()
+	_ = o.Linear.Configure()
+	_ = o.Zoom.Configure()
+	_ = o.MISP.Configure()
+	_ = o.Voyage.Configure()
+	_ = o.Cohere.Configure()
+	_ = o.Email.Configure()
+	_ = o.Sync.Configure()
 	_ = o.Language.Configure()
 	return
 }
 
 func (o *PluginRegistry) GetChatter(model string, modelContextLength int, vendorName string, stream bool, dryRun bool) (ret *Chatter, err error) {
 	ret = &Chatter{
-		db:     o.Db,
-		Stream: stream,
-		DryRun: dryRun,
+		db:            o.Db,
+		Stream:        stream,
+		DryRun:        dryRun,
+		Moderation:    o.Moderation,
+		SessionPolicy: o.SessionPolicy,
+		Hooks:         o.Hooks,
+		Spend:         o.Spend,
 	}
 
 	defaultModel := o.Defaults.Model.Value
@@ -497,11 +575,25 @@ func (o *PluginRegistry) GetChatter(model string, modelContextLength int, vendor
 	}
 
 	if dryRun {
-		ret.vendor = dryrun.NewClient()
+		client := dryrun.NewClient()
 		ret.model = model
 		if ret.model == "" {
 			ret.model = defaultModel
 		}
+		// The vendor is looked up without listing the models, which would call it
+		switch {
+		case vendorName != "":
+			client.Vendor = vendorManager.FindByName(vendorName)
+		case ret.model == defaultModel:
+			client.Vendor = vendorManager.FindByName(defaultVendor)
+		default:
+			if idx := strings.Index(ret.model, "/"); idx > 0 {
+				if client.Vendor = vendorManager.FindByName(ret.model[:idx]); client.Vendor != nil {
+					ret.model = ret.model[idx+1:]
+				}
+			}
+		}
+		ret.vendor = client
 	} else if model == "" {
 		if vendorName != "" {
 			ret.vendor = vendorManager.FindByName(vendorName)
@@ -42,6 +42,7 @@ func (m *testVendor) IsConfigured() bool                           { return true
 func (m *testVendor) Configure() error                             { return nil }
 func (m *testVendor) Setup() error                                 { return nil }
 func (m *testVendor) SetupFillEnvFileContent(*bytes.Buffer)        {}
+func (m *testVendor) GetSettings() plugins.Settings                { return nil }
 func (m *testVendor) ListModels(context.Context) ([]string, error) { return m.models, nil }
 func (m *testVendor) SendStream(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions, chan domain.StreamUpdate) error {
 	return nil
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+)
+
+const (
+	critiquePrompt = "You review the answer to a request. List the concrete problems of the answer, such as " +
+		"mistakes, omissions, unclear parts or a format other than the one the request asks for, and how to fix " +
+		"each of them. End with a line \"Score: N/10\" rating the answer, where 10 means it needs no change."
+	revisePrompt = "Revise your answer using the critique below. Reply with the revised answer only, " +
+		"without mentioning the critique.\n\nCritique:\n\n%s"
+)
+
+// critiqueScore matches the "Score: N/10" line ending a critique
+var critiqueScore = regexp.MustCompile(`(?i)score\s*:\s*(\d+(?:\.\d+)?)\s*/\s*10`)
+
+// refine critiques the response and revises it with the critique, up to
+// request.Refine times or until a critique scores the response at least
+// request.RefineThreshold out of 10.
+func (o *Chatter) refine(ctx context.Context, request *domain.ChatRequest, messages []*chat.ChatCompletionMessage, message string, opts *domain.ChatOptions) (ret string, err error) {
+	ret = message
+	for i := range request.Refine {
+		answer := strings.TrimSpace(domain.StripThinkBlocks(ret, opts.ThinkStartTag, opts.ThinkEndTag))
+		input := fmt.Sprintf("Request:\n\n%sAnswer:\n\n%s", transcript(messages), answer)
+
+		var critiqueMessages []*chat.ChatCompletionMessage
+		if critiqueMessages, err = o.reviewMessages(critiquePrompt, request.RefinePattern, input); err != nil {
+			return
+		}
+		var critique string
+		if critique, err = o.vendor.Send(ctx, critiqueMessages, opts); err != nil {
+			return
+		}
+		critique = strings.TrimSpace(domain.StripThinkBlocks(critique, opts.ThinkStartTag, opts.ThinkEndTag))
+
+		score := "-"
+		if match := critiqueScore.FindStringSubmatch(critique); match != nil {
+			score = match[1]
+			if value, _ := strconv.ParseFloat(match[1], 64); value >= float64(request.RefineThreshold) {
+				debuglog.Log(i18n.T("chatter_log_refine_accepted"), i+1, score)
+				return
+			}
+		}
+		debuglog.Log(i18n.T("chatter_log_refine_revising"), i+1, score)
+
+		revise := append(slices.Clone(messages),
+			&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: answer},
+			&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: fmt.Sprintf(revisePrompt, critique)})
+		if ret, err = o.vendor.Send(ctx, revise, opts); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// reviewMessages asks for a review of the input, a critique or a judgement,
+// with the built-in prompt, or with the pattern when one is given.
+func (o *Chatter) reviewMessages(prompt, patternName, input string) (ret []*chat.ChatCompletionMessage, err error) {
+	if patternName == "" {
+		return []*chat.ChatCompletionMessage{
+			{Role: chat.ChatMessageRoleSystem, Content: prompt},
+			{Role: chat.ChatMessageRoleUser, Content: input},
+		}, nil
+	}
+	pattern, err := o.db.Patterns.GetWithoutVariables(patternName, input)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("chatter_error_get_pattern"), patternName, err)
+	}
+	return []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleSystem, Content: pattern.Pattern}}, nil
+}
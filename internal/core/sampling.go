@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/strategy"
+)
+
+const (
+	votePrompt = "Several independent answers to the same request follow. Reply with the answer most of them " +
+		"agree on, in the format the request asks for, without mentioning the other answers."
+	mergePrompt = "Several independent answers to the same request follow. Merge them into a single best answer " +
+		"that keeps what they agree on and the correct details only some of them give, in the format the " +
+		"request asks for, without mentioning that there were several answers."
+	judgePrompt = "Several independent answers to the same request follow. Judge which one answers the request " +
+		"best, for correctness, completeness and the format the request asks for. Reply with the number of " +
+		"the best answer only."
+)
+
+// judgeChoice matches the number of the answer the judge picks
+var judgeChoice = regexp.MustCompile(`\d+`)
+
+// sendSamples sends the messages once per sample of the plan, concurrently,
+// then combines the responses as the aggregate of the plan says: all of them,
+// the one most agree on, the one a judge prefers, or a merge of them.
+func (o *Chatter) sendSamples(ctx context.Context, request *domain.ChatRequest, messages []*chat.ChatCompletionMessage, opts *domain.ChatOptions, plan *strategy.Plan) (ret string, err error) {
+	samples := make([]string, plan.Samples)
+	errs := make([]error, plan.Samples)
+	var wg sync.WaitGroup
+	for i := range plan.Samples {
+		sampleOpts := *opts
+		if sampleOpts.Seed != 0 {
+			// The same seed would sample the same response
+			sampleOpts.Seed += i
+		}
+		wg.Go(func() {
+			samples[i], errs[i] = o.vendor.Send(ctx, messages, &sampleOpts)
+		})
+	}
+	wg.Wait()
+	for _, sampleErr := range errs {
+		if sampleErr != nil {
+			return "", sampleErr
+		}
+	}
+	for i, sample := range samples {
+		samples[i] = strings.TrimSpace(domain.StripThinkBlocks(sample, opts.ThinkStartTag, opts.ThinkEndTag))
+	}
+	debuglog.Log(i18n.T("chatter_log_samples_aggregated"), plan.Samples, plan.Aggregate)
+
+	switch plan.Aggregate {
+	case strategy.AggregateAll:
+		return joinSamples(samples), nil
+	case strategy.AggregateVote:
+		// Identical answers need no model to count them
+		if answer, count := majority(samples); count > 1 {
+			debuglog.Log(i18n.T("chatter_log_samples_majority"), count, len(samples))
+			return answer, nil
+		}
+	}
+
+	var candidates strings.Builder
+	fmt.Fprintf(&candidates, "Request:\n\n%s", transcript(messages))
+	for i, sample := range samples {
+		fmt.Fprintf(&candidates, "Answer %d:\n\n%s\n\n", i+1, sample)
+	}
+
+	prompt := votePrompt
+	switch plan.Aggregate {
+	case strategy.AggregateBest:
+		return o.judge(ctx, request.JudgePattern, candidates.String(), samples, opts)
+	case strategy.AggregateMerge:
+		prompt = mergePrompt
+	}
+	aggregate := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: prompt},
+		{Role: chat.ChatMessageRoleUser, Content: candidates.String()},
+	}
+	return o.vendor.Send(ctx, aggregate, opts)
+}
+
+// judge asks the built-in judge prompt, or the pattern when one is given,
+// for the number of the best of the samples, and returns that sample as is.
+// The first sample is kept when the reply names none.
+func (o *Chatter) judge(ctx context.Context, patternName, candidates string, samples []string, opts *domain.ChatOptions) (ret string, err error) {
+	var messages []*chat.ChatCompletionMessage
+	if messages, err = o.reviewMessages(judgePrompt, patternName, candidates); err != nil {
+		return
+	}
+	var reply string
+	if reply, err = o.vendor.Send(ctx, messages, opts); err != nil {
+		return
+	}
+	reply = strings.TrimSpace(domain.StripThinkBlocks(reply, opts.ThinkStartTag, opts.ThinkEndTag))
+	if match := judgeChoice.FindString(reply); match != "" {
+		if choice, convErr := strconv.Atoi(match); convErr == nil && choice >= 1 && choice <= len(samples) {
+			return samples[choice-1], nil
+		}
+	}
+	debuglog.Log(i18n.T("chatter_log_judge_unparsed"), reply)
+	return samples[0], nil
+}
+
+// majority returns the answer given by more samples than any other, and how
+// many gave it, or a count of 0 when several answers tie.
+func majority(samples []string) (ret string, count int) {
+	counts := map[string]int{}
+	first := map[string]string{}
+	tied := false
+	for _, sample := range samples {
+		key := voteKey(sample)
+		counts[key]++
+		if _, ok := first[key]; !ok {
+			first[key] = sample
+		}
+		switch {
+		case counts[key] > count:
+			ret, count, tied = first[key], counts[key], false
+		case counts[key] == count && first[key] != ret:
+			tied = true
+		}
+	}
+	if tied {
+		return "", 0
+	}
+	return
+}
+
+// voteKey normalizes an answer for the vote: JSON answers compare by value,
+// fenced or not, and other answers ignore differences of whitespace.
+func voteKey(sample string) string {
+	unfenced := strings.TrimSpace(sample)
+	if strings.HasPrefix(unfenced, "```") && strings.HasSuffix(unfenced, "```") {
+		unfenced = strings.TrimSuffix(unfenced, "```")
+		// Drops the language of the fence along with it
+		_, unfenced, _ = strings.Cut(unfenced, "\n")
+	}
+	var value any
+	if json.Unmarshal([]byte(unfenced), &value) == nil {
+		// Maps marshal with sorted keys
+		if canonical, err := json.Marshal(value); err == nil {
+			return string(canonical)
+		}
+	}
+	return strings.Join(strings.Fields(sample), " ")
+}
+
+// joinSamples lists every sample under a heading with its number
+func joinSamples(samples []string) string {
+	parts := make([]string, len(samples))
+	for i, sample := range samples {
+		parts[i] = fmt.Sprintf("## Response %d\n\n%s", i+1, sample)
+	}
+	return strings.Join(parts, "\n\n")
+}
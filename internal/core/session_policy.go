@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+const (
+	summarizePrompt = "Summarize the following conversation in a few paragraphs, keeping the facts, " +
+		"decisions, names and open questions needed to continue it. Reply with the summary only."
+	// summaryPrefix starts the message holding the summary of the earlier turns
+	summaryPrefix = "Summary of the earlier conversation:\n\n"
+)
+
+// SessionPolicy limits the size and the age of named sessions. The oldest turns
+// dropped to respect the limits are either discarded or, with Summarize,
+// compressed into a summary message, which also happens when the session no
+// longer fits the context window of the model.
+type SessionPolicy struct {
+	// MaxMessages is the number of user and assistant messages kept
+	MaxMessages int
+	// MaxTokens is the estimated size kept, including the new request
+	MaxTokens int
+	// TTL is the time after which an unused session starts over
+	TTL       time.Duration
+	Summarize bool
+}
+
+// applySessionPolicy enforces the policy on the history of the session, the
+// messages preceding those of the current request.
+func (o *Chatter) applySessionPolicy(ctx context.Context, session *fsdb.Session, opts *domain.ChatOptions) (err error) {
+	policy := o.SessionPolicy
+	if policy == nil || session.Name == "" || o.DryRun {
+		return
+	}
+
+	split := historyLength(session.Messages)
+	history, current := session.Messages[:split], session.Messages[split:]
+	if len(history) == 0 {
+		return
+	}
+
+	if policy.TTL > 0 {
+		if modTime, statErr := o.db.Sessions.ModTime(session.Name); statErr == nil && time.Since(modTime) > policy.TTL {
+			debuglog.Log(i18n.T("session_policy_expired"), session.Name, policy.TTL)
+			session.SetMessages(slices.Clone(current))
+			return
+		}
+	}
+
+	maxTokens := policy.MaxTokens
+	if policy.Summarize {
+		if window := o.contextWindow(ctx, opts); window > 0 {
+			budget := window - min(maxResponseReserve, window/4)
+			if maxTokens == 0 || budget < maxTokens {
+				maxTokens = budget
+			}
+		}
+	}
+	tokenBudget := -1
+	if maxTokens > 0 {
+		tokenBudget = max(maxTokens-messagesTokens(current), 0)
+	}
+
+	drop := 0
+	for drop < len(history) && exceedsPolicy(history[drop:], policy.MaxMessages, tokenBudget) {
+		drop++
+	}
+	// Never start the kept history with the answer to a dropped question
+	for drop < len(history) && history[drop].Role != chat.ChatMessageRoleUser {
+		drop++
+	}
+	if drop == 0 {
+		return
+	}
+
+	kept := slices.Clone(history[drop:])
+	if policy.Summarize {
+		var summary string
+		if summary, err = o.summarize(ctx, history[:drop], opts); err != nil {
+			return
+		}
+		kept = append([]*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleSystem, Content: summaryPrefix + summary}}, kept...)
+		debuglog.Log(i18n.T("session_policy_summarized"), drop, session.Name)
+	} else {
+		debuglog.Log(i18n.T("session_policy_dropped"), drop, session.Name)
+	}
+	session.SetMessages(append(kept, current...))
+	return
+}
+
+// summarize asks the model for a summary of the messages.
+func (o *Chatter) summarize(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+	request := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: summarizePrompt},
+		{Role: chat.ChatMessageRoleUser, Content: transcript(msgs)},
+	}
+	summaryOpts := &domain.ChatOptions{Model: o.model, ModelContextLength: opts.ModelContextLength, Quiet: true}
+	if ret, err = o.vendor.Send(ctx, request, summaryOpts); err != nil {
+		return "", fmt.Errorf(i18n.T("session_policy_error_summarize"), err)
+	}
+	ret = strings.TrimSpace(ret)
+	return
+}
+
+// transcript writes the texts of the messages as "role: text" paragraphs.
+func transcript(msgs []*chat.ChatCompletionMessage) string {
+	var ret strings.Builder
+	for _, msg := range msgs {
+		if msg.Role == domain.ChatMessageRoleMeta {
+			continue
+		}
+		for _, text := range messageTexts(msg) {
+			fmt.Fprintf(&ret, "%s: %s\n\n", msg.Role, *text)
+		}
+	}
+	return ret.String()
+}
+
+// historyLength returns the number of messages preceding the current request:
+// those up to the last assistant message.
+func historyLength(msgs []*chat.ChatCompletionMessage) int {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == chat.ChatMessageRoleAssistant {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// exceedsPolicy reports whether the history holds more user and assistant
+// messages than maxMessages, when positive, or more estimated tokens than
+// tokenBudget, when not negative.
+func exceedsPolicy(history []*chat.ChatCompletionMessage, maxMessages, tokenBudget int) bool {
+	if maxMessages > 0 {
+		count := 0
+		for _, msg := range history {
+			if msg.Role == chat.ChatMessageRoleUser || msg.Role == chat.ChatMessageRoleAssistant {
+				count++
+			}
+		}
+		if count > maxMessages {
+			return true
+		}
+	}
+	return tokenBudget >= 0 && messagesTokens(history) > tokenBudget
+}
+
+func messagesTokens(msgs []*chat.ChatCompletionMessage) (ret int) {
+	for _, msg := range msgs {
+		if msg.Role == domain.ChatMessageRoleMeta {
+			continue
+		}
+		for _, text := range messageTexts(msg) {
+			ret += ai.EstimateTokens(*text)
+		}
+	}
+	return
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// saveConversation stores a session with the given alternating user and
+// assistant turns.
+func saveConversation(t *testing.T, db *fsdb.Db, name string, turns ...string) {
+	t.Helper()
+	if err := os.MkdirAll(db.Sessions.Dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	session := &fsdb.Session{Name: name}
+	for i, turn := range turns {
+		role := chat.ChatMessageRoleUser
+		if i%2 == 1 {
+			role = chat.ChatMessageRoleAssistant
+		}
+		session.Append(&chat.ChatCompletionMessage{Role: role, Content: turn})
+	}
+	if err := db.Sessions.SaveSession(session); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sentContents records the contents of the messages of the last request that
+// is not a summary request.
+func sentContents(sent *[]string) func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+	return func(_ context.Context, messages []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+		if messages[0].Content == summarizePrompt {
+			return "they talked about q1 and q2", nil
+		}
+		*sent = (*sent)[:0]
+		for _, message := range messages {
+			*sent = append(*sent, message.Content)
+		}
+		return "a4", nil
+	}
+}
+
+func TestChatter_Send_SessionPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy SessionPolicy
+		age    time.Duration
+		want   []string
+	}{
+		{
+			name:   "no limits",
+			policy: SessionPolicy{},
+			want:   []string{"q1", "a1", "q2", "a2", "q3", "a3", "q4"},
+		},
+		{
+			name:   "max messages",
+			policy: SessionPolicy{MaxMessages: 3},
+			want:   []string{"q3", "a3", "q4"},
+		},
+		{
+			name:   "summarize",
+			policy: SessionPolicy{MaxMessages: 2, Summarize: true},
+			want:   []string{summaryPrefix + "they talked about q1 and q2", "q3", "a3", "q4"},
+		},
+		{
+			name:   "max tokens",
+			policy: SessionPolicy{MaxTokens: 4},
+			want:   []string{"q3", "a3", "q4"},
+		},
+		{
+			name:   "expired",
+			policy: SessionPolicy{TTL: time.Hour},
+			age:    2 * time.Hour,
+			want:   []string{"q4"},
+		},
+		{
+			name:   "not expired",
+			policy: SessionPolicy{TTL: time.Hour},
+			age:    time.Minute,
+			want:   []string{"q1", "a1", "q2", "a2", "q3", "a3", "q4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := fsdb.NewDb(t.TempDir())
+			saveConversation(t, db, "chat", "q1", "a1", "q2", "a2", "q3", "a3")
+			if tt.age > 0 {
+				old := time.Now().Add(-tt.age)
+				if err := os.Chtimes(db.Sessions.BuildFilePathByName("chat"), old, old); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var sent []string
+			policy := tt.policy
+			chatter := &Chatter{db: db, vendor: &mockVendor{sendFunc: sentContents(&sent)}, model: "test-model", SessionPolicy: &policy}
+			request := &domain.ChatRequest{
+				SessionName: "chat",
+				Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "q4"},
+			}
+			if _, err := chatter.Send(context.Background(), request, &domain.ChatOptions{Model: "test-model"}); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if !slices.Equal(sent, tt.want) {
+				t.Errorf("sent %q, want %q", sent, tt.want)
+			}
+
+			session, err := db.Sessions.Get("chat")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if last := session.GetLastMessage().Content; last != "a4" || !strings.Contains(session.String(), tt.want[0]) {
+				t.Errorf("saved session %q does not hold the kept history and the response", session.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// SpendLimitTotal is the key of the soft limit of all the vendors together
+const SpendLimitTotal = "total"
+
+// SpendTracking records the spend of every request in the spend log and warns
+// when the spend of the month is over a soft limit
+type SpendTracking struct {
+	// Limits are the monthly soft limits in USD by vendor, SpendLimitTotal
+	// being that of all the vendors
+	Limits map[string]float64
+	// Pricing returns the configured price of the model of a vendor, nil when
+	// there is none
+	Pricing func(vendor, model string) *ai.ModelPricing
+}
+
+// recordSpend adds the spend of the requests to the spend log: the tokens and
+// the cost reported by the vendor, else estimated from the messages and the
+// response. Failing to record it does not fail the chat.
+func (o *Chatter) recordSpend(ctx context.Context, msgs []*chat.ChatCompletionMessage, response string, usage *domain.UsageMetadata, requests int) {
+	vendor := o.ServingVendorName()
+	entry := fsdb.SpendEntry{Time: time.Now().UTC(), Vendor: vendor, Model: o.model}
+	if usage != nil && (usage.InputTokens > 0 || usage.OutputTokens > 0) {
+		entry.InputTokens, entry.OutputTokens = usage.InputTokens, usage.OutputTokens
+	} else {
+		entry.Estimated = true
+		entry.InputTokens = estimateInputTokens(msgs) * requests
+		entry.OutputTokens = ai.EstimateTokens(response)
+	}
+
+	if usage != nil && usage.Cost > 0 {
+		entry.Cost = usage.Cost
+	} else {
+		var configured *ai.ModelPricing
+		if o.Spend.Pricing != nil {
+			configured = o.Spend.Pricing(vendor, o.model)
+		}
+		if pricing, err := o.modelPricing(ctx, configured); err == nil {
+			entry.Cost = pricing.Cost(entry.InputTokens, entry.OutputTokens)
+		} else {
+			debuglog.Debug(debuglog.Detailed, "Spend of %s recorded without cost: %v\n", o.model, err)
+		}
+	}
+
+	if err := o.db.Spend.Add(entry); err != nil {
+		debuglog.Log(i18n.T("spend_warning_record"), err)
+		return
+	}
+	o.warnSpendLimits(vendor)
+}
+
+// warnSpendLimits warns when the spend of the month of the vendor, or of all
+// the vendors, is over its soft limit
+func (o *Chatter) warnSpendLimits(vendor string) {
+	if len(o.Spend.Limits) == 0 {
+		return
+	}
+	entries, err := o.db.Spend.Since(fsdb.SpendPeriodStart(time.Now(), fsdb.SpendPeriodMonth))
+	if err != nil {
+		debuglog.Log(i18n.T("spend_warning_record"), err)
+		return
+	}
+	spent := map[string]float64{}
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Vendor, vendor) {
+			spent[vendor] += entry.Cost
+		}
+		spent[SpendLimitTotal] += entry.Cost
+	}
+	for name, limit := range o.Spend.Limits {
+		for key, cost := range spent {
+			if strings.EqualFold(name, key) && limit > 0 && cost > limit {
+				debuglog.Log(i18n.T("spend_warning_limit"), key, cost, limit)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+func sendForSpend(t *testing.T, chatter *Chatter) []fsdb.SpendEntry {
+	t.Helper()
+	if _, err := chatter.Send(context.Background(), &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: strings.Repeat("a", 400)},
+	}, &domain.ChatOptions{Model: "test-model"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	entries, err := chatter.db.Spend.Since(time.Time{})
+	if err != nil {
+		t.Fatalf("Since() error = %v", err)
+	}
+	return entries
+}
+
+func TestChatter_Send_RecordsSpend(t *testing.T) {
+	pricing := func(vendor, model string) *ai.ModelPricing {
+		if vendor == "mock" && model == "test-model" {
+			return &ai.ModelPricing{Input: 1000, Output: 2000}
+		}
+		return nil
+	}
+
+	t.Run("usage reported by the vendor", func(t *testing.T) {
+		chatter := &Chatter{
+			db:     fsdb.NewDb(t.TempDir()),
+			Stream: true,
+			vendor: &mockVendor{streamChunks: []domain.StreamUpdate{
+				{Type: domain.StreamTypeContent, Content: "response"},
+				{Type: domain.StreamTypeUsage, Usage: &domain.UsageMetadata{InputTokens: 120, OutputTokens: 30, TotalTokens: 150}},
+			}},
+			model: "test-model",
+			Spend: &SpendTracking{Pricing: pricing},
+		}
+		entries := sendForSpend(t, chatter)
+		if len(entries) != 1 {
+			t.Fatalf("recorded %d entries, want 1", len(entries))
+		}
+		got := entries[0]
+		if got.Vendor != "mock" || got.Model != "test-model" || got.InputTokens != 120 || got.OutputTokens != 30 || got.Estimated {
+			t.Errorf("entry = %+v", got)
+		}
+		if want := 0.18; got.Cost < want-1e-9 || got.Cost > want+1e-9 {
+			t.Errorf("Cost = %v, want %v", got.Cost, want)
+		}
+	})
+
+	t.Run("estimated without usage", func(t *testing.T) {
+		chatter := &Chatter{
+			db: fsdb.NewDb(t.TempDir()),
+			vendor: &mockVendor{sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+				return strings.Repeat("b", 40), nil
+			}},
+			model: "test-model",
+			Spend: &SpendTracking{Pricing: pricing},
+		}
+		entries := sendForSpend(t, chatter)
+		if len(entries) != 1 {
+			t.Fatalf("recorded %d entries, want 1", len(entries))
+		}
+		got := entries[0]
+		if !got.Estimated || got.InputTokens != 100 || got.OutputTokens != 10 {
+			t.Errorf("entry = %+v, want 100 input and 10 output estimated tokens", got)
+		}
+		if want := 0.12; got.Cost < want-1e-9 || got.Cost > want+1e-9 {
+			t.Errorf("Cost = %v, want %v", got.Cost, want)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), DryRun: true, vendor: &mockVendor{}, model: "test-model", Spend: &SpendTracking{}}
+		if entries := sendForSpend(t, chatter); len(entries) != 0 {
+			t.Errorf("a dry run recorded %d entries", len(entries))
+		}
+	})
+}
+
+func TestChatter_Send_WarnsOverSpendLimit(t *testing.T) {
+	var logs bytes.Buffer
+	debuglog.SetOutput(&logs)
+	defer debuglog.SetOutput(os.Stderr)
+
+	chatter := &Chatter{
+		db:     fsdb.NewDb(t.TempDir()),
+		vendor: &mockVendor{},
+		model:  "test-model",
+		Spend: &SpendTracking{
+			Limits:  map[string]float64{"Mock": 0.15, SpendLimitTotal: 1},
+			Pricing: func(string, string) *ai.ModelPricing { return &ai.ModelPricing{Input: 1000} },
+		},
+	}
+	// $0.10 each
+	sendForSpend(t, chatter)
+	if strings.Contains(logs.String(), "limit") {
+		t.Fatalf("warned under the limit: %s", logs.String())
+	}
+	sendForSpend(t, chatter)
+	if !strings.Contains(logs.String(), "the spend of mock this month, $0.20, is over its limit of $0.15") {
+		t.Errorf("no warning over the limit of the vendor: %q", logs.String())
+	}
+	if strings.Contains(logs.String(), SpendLimitTotal) {
+		t.Errorf("warned under the total limit: %q", logs.String())
+	}
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	ansiDim   = "\033[2m"
+	ansiReset = "\033[0m"
+)
+
+// thinkPrinter prints a response stream, separating the thinking from the answer:
+// reasoning updates sent by the vendor as well as blocks enclosed in think tags.
+// When thinking and answer share a writer, the thinking is printed dimmed along
+// with its tags; otherwise the answer is printed clean and the thinking goes to
+// the think writer, or nowhere when it is nil.
+type thinkPrinter struct {
+	out, think         io.Writer
+	color              bool
+	startTag, endTag   string
+	inThink, reasoning bool
+	// pending holds the end of a chunk that may be the start of a split tag
+	pending string
+	// trimAnswer drops the whitespace separating a removed think block from the answer
+	trimAnswer bool
+}
+
+func newThinkPrinter(out, think io.Writer, startTag, endTag string) *thinkPrinter {
+	return &thinkPrinter{
+		out:      out,
+		think:    think,
+		color:    think == out && isTerminal(out),
+		startTag: startTag,
+		endTag:   endTag,
+	}
+}
+
+// Reasoning prints a reasoning update.
+func (o *thinkPrinter) Reasoning(text string) {
+	o.reasoning = true
+	o.writeThink(text)
+}
+
+// Content prints a content update.
+func (o *thinkPrinter) Content(text string) {
+	if o.reasoning {
+		o.reasoning = false
+		if o.inline() {
+			// Separate the reasoning from the answer
+			_, _ = io.WriteString(o.out, "\n\n")
+		} else {
+			o.endThink()
+		}
+	}
+
+	text = o.pending + text
+	o.pending = ""
+	for text != "" {
+		tag := o.startTag
+		if o.inThink {
+			tag = o.endTag
+		}
+		if tag == "" {
+			o.write(text)
+			return
+		}
+
+		if idx := strings.Index(text, tag); idx >= 0 {
+			o.write(text[:idx])
+			if o.inline() {
+				o.writeThink(tag)
+			} else if o.inThink {
+				o.endThink()
+			}
+			o.inThink = !o.inThink
+			text = text[idx+len(tag):]
+			continue
+		}
+
+		keep := partialTagSuffix(text, tag)
+		o.write(text[:len(text)-keep])
+		o.pending = text[len(text)-keep:]
+		return
+	}
+}
+
+// Flush prints any text held back while waiting for the rest of a tag.
+func (o *thinkPrinter) Flush() {
+	o.write(o.pending)
+	o.pending = ""
+}
+
+// inline reports whether the thinking is printed along with the answer.
+func (o *thinkPrinter) inline() bool {
+	return o.think == o.out
+}
+
+// endThink ends a thinking section printed apart from the answer.
+func (o *thinkPrinter) endThink() {
+	if o.think != nil {
+		_, _ = io.WriteString(o.think, "\n")
+	}
+	o.trimAnswer = true
+}
+
+func (o *thinkPrinter) write(text string) {
+	if o.inThink {
+		o.writeThink(text)
+		return
+	}
+	if o.trimAnswer && !o.inline() {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		o.trimAnswer = text == ""
+	}
+	if text != "" {
+		_, _ = io.WriteString(o.out, text)
+	}
+}
+
+func (o *thinkPrinter) writeThink(text string) {
+	if text == "" || o.think == nil {
+		return
+	}
+	if o.color {
+		text = ansiDim + text + ansiReset
+	}
+	_, _ = io.WriteString(o.think, text)
+}
+
+// separateThinking saves the thinking of a response, made of the vendor reasoning
+// and the think blocks of the message, to the --think-output file. When the
+// response was not streamed through a thinkPrinter, it is also printed to stderr
+// for --show-think=stderr.
+func (o *Chatter) separateThinking(message, reasoning string, opts *domain.ChatOptions) (err error) {
+	toStderr := opts.ShowThink == domain.ShowThinkStderr && (!o.Stream || opts.SuppressThink) && !opts.Quiet
+	if opts.ThinkOutput == "" && !toStderr {
+		return
+	}
+
+	var blocks []string
+	if reasoning = strings.TrimSpace(reasoning); reasoning != "" {
+		blocks = append(blocks, reasoning)
+	}
+	blocks = append(blocks, domain.ExtractThinkBlocks(message, opts.ThinkStartTag, opts.ThinkEndTag)...)
+	thinking := strings.Join(blocks, "\n\n")
+
+	if toStderr && thinking != "" {
+		fmt.Fprintln(os.Stderr, thinking)
+	}
+	if opts.ThinkOutput != "" {
+		if thinking != "" {
+			thinking += "\n"
+		}
+		if err = os.WriteFile(opts.ThinkOutput, []byte(thinking), 0644); err != nil {
+			err = fmt.Errorf(i18n.T("chatter_error_write_think_output"), opts.ThinkOutput, err)
+		}
+	}
+	return
+}
+
+// partialTagSuffix returns the length of the longest suffix of text that is a
+// proper prefix of tag.
+func partialTagSuffix(text, tag string) int {
+	for n := min(len(tag)-1, len(text)); n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+func isTerminal(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThinkPrinter(t *testing.T) {
+	var out bytes.Buffer
+	printer := newThinkPrinter(&out, &out, "<think>", "</think>")
+	printer.color = true
+
+	for _, chunk := range []string{"<thi", "nk>hmm</th", "ink>Answer <", "b>"} {
+		printer.Content(chunk)
+	}
+	printer.Flush()
+
+	assert.Equal(t, ansiDim+"<think>"+ansiReset+ansiDim+"hmm"+ansiReset+ansiDim+"</think>"+ansiReset+"Answer <b>", out.String())
+}
+
+func TestThinkPrinterReasoning(t *testing.T) {
+	var out bytes.Buffer
+	printer := newThinkPrinter(&out, &out, "<think>", "</think>")
+
+	printer.Reasoning("step one. ")
+	printer.Reasoning("step two.")
+	printer.Content("Answer")
+	printer.Flush()
+
+	// Colors are disabled when not writing to a terminal
+	assert.Equal(t, "step one. step two.\n\nAnswer", out.String())
+}
+
+func TestThinkPrinterSeparateOutput(t *testing.T) {
+	var out, think bytes.Buffer
+	printer := newThinkPrinter(&out, &think, "<think>", "</think>")
+
+	for _, chunk := range []string{"<think>\nhmm", "</think>\n", "\nAnswer"} {
+		printer.Content(chunk)
+	}
+	printer.Flush()
+
+	assert.Equal(t, "Answer", out.String())
+	assert.Equal(t, "\nhmm\n", think.String())
+
+	// Thinking is dropped without a think writer
+	out.Reset()
+	printer = newThinkPrinter(&out, nil, "<think>", "</think>")
+	printer.Reasoning("hmm")
+	printer.Content("Answer")
+	printer.Flush()
+	assert.Equal(t, "Answer", out.String())
+}
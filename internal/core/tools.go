@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+// maxToolRounds is how many times a model can call tools before it answers
+const maxToolRounds = 20
+
+// Toolbox holds the tools offered to the model, like those of MCP servers
+type Toolbox interface {
+	Tools() []domain.Tool
+	// Call runs the named tool with its arguments, a JSON object, and returns
+	// its result
+	Call(ctx context.Context, name string, arguments string) (string, error)
+}
+
+// sendWithTools sends the messages with the tools of the toolbox, runs the
+// tools the model calls and sends their results back, until the model answers.
+// The error of a tool is its result, for the model to recover from it.
+func (o *Chatter) sendWithTools(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+	sender, ok := o.vendor.(ai.ToolSender)
+	if !ok {
+		return "", fmt.Errorf(i18n.T("chatter_error_vendor_no_tools"), o.VendorName())
+	}
+	tools := o.Tools.Tools()
+	messages := slices.Clone(msgs)
+	for range maxToolRounds {
+		var reply *chat.ChatCompletionMessage
+		if reply, err = sender.SendWithTools(ctx, messages, opts, tools); err != nil {
+			return
+		}
+		if len(reply.ToolCalls) == 0 {
+			return reply.Content, nil
+		}
+		messages = append(messages, reply)
+		for _, call := range reply.ToolCalls {
+			debuglog.Log(i18n.T("chatter_log_tool_call"), call.Function.Name, call.Function.Arguments)
+			result, callErr := o.Tools.Call(ctx, call.Function.Name, call.Function.Arguments)
+			if callErr != nil {
+				if ctx.Err() != nil {
+					return "", ctx.Err()
+				}
+				result = fmt.Sprintf(i18n.T("chatter_tool_error_result"), callErr)
+			}
+			messages = append(messages, &chat.ChatCompletionMessage{
+				Role: chat.ChatMessageRoleTool, Content: result, ToolCallID: call.ID, Name: call.Function.Name})
+		}
+	}
+	return "", fmt.Errorf(i18n.T("chatter_error_tool_rounds"), maxToolRounds)
+}
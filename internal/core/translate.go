@@ -0,0 +1,29 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+)
+
+const translatePrompt = "You translate the text you are given into the %s language. Keep its formatting, " +
+	"Markdown, code blocks, URLs and names as they are. Reply with the translation only."
+
+// translateOutput translates the response into the language with a second
+// request, after the pattern wrote it in its own language.
+func (o *Chatter) translateOutput(ctx context.Context, message, language string, opts *domain.ChatOptions) (ret string, err error) {
+	answer := strings.TrimSpace(domain.StripThinkBlocks(message, opts.ThinkStartTag, opts.ThinkEndTag))
+	if answer == "" {
+		return message, nil
+	}
+	debuglog.Log(i18n.T("chatter_log_translating_output"), language)
+	return o.vendor.Send(ctx, []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: fmt.Sprintf(translatePrompt, language)},
+		{Role: chat.ChatMessageRoleUser, Content: answer},
+	}, opts)
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/strategy"
+)
+
+// Suffixes of the environment variables set by SetupOptions.APIKey and
+// SetupOptions.BaseURL, most specific first
+var (
+	apiKeySuffixes  = []string{"_API_KEY", "_KEY", "_TOKEN"}
+	baseURLSuffixes = []string{"_API_BASE_URL", "_BASE_URL", "_API_URL", "_URL"}
+)
+
+// SetupOptions configures fabric without asking questions, for containers and
+// CI. Empty options are left as they are.
+type SetupOptions struct {
+	Vendor  string
+	APIKey  string
+	BaseURL string
+	// Settings holds other settings of the vendor, by environment variable
+	// name with or without the prefix of the vendor
+	Settings     map[string]string
+	DefaultModel string
+}
+
+// SetupUnattended downloads the patterns and strategies when missing, sets up
+// the vendor and the default model of the options and saves the .env file.
+func (o *PluginRegistry) SetupUnattended(options *SetupOptions) (err error) {
+	if options.Vendor == "" && (options.APIKey != "" || options.BaseURL != "" || len(options.Settings) > 0) {
+		return errors.New(i18n.T("setup_unattended_vendor_required"))
+	}
+
+	// The repositories are not asked for, so the configured or default ones are used
+	if !o.PatternsLoader.IsConfigured() {
+		if err = o.PatternsLoader.PopulateDB(); err != nil {
+			return fmt.Errorf(i18n.T("setup_failed_download_patterns"), err)
+		}
+	}
+	if !o.Strategies.IsConfigured() {
+		if err = o.Strategies.PopulateDB(); err != nil {
+			return fmt.Errorf(i18n.T("setup_failed_download_strategies"), err)
+		}
+		o.Strategies.Strategies, _ = strategy.LoadAllFiles()
+	}
+
+	if options.Vendor != "" {
+		if err = o.setupVendorUnattended(options); err != nil {
+			return
+		}
+	}
+
+	if options.DefaultModel != "" {
+		vendorName := options.Vendor
+		if vendorName == "" {
+			var models *ai.VendorsModels
+			if models, err = o.GetModels(); err != nil {
+				return
+			}
+			if vendorName = models.FindGroupsByItemFirst(options.DefaultModel); vendorName == "" {
+				return fmt.Errorf(i18n.T("setup_unattended_unknown_model"), options.DefaultModel)
+			}
+		}
+		o.Defaults.Vendor.Value = o.VendorManager.FindByName(vendorName).GetName()
+		o.Defaults.Model.Value = options.DefaultModel
+	}
+
+	if err = o.SaveEnvFile(); err != nil {
+		return
+	}
+	o.validateSetup()
+	return
+}
+
+// setupVendorUnattended sets the settings of the vendor of the options and
+// checks they configure it.
+func (o *PluginRegistry) setupVendorUnattended(options *SetupOptions) (err error) {
+	vendor := o.VendorsAll.FindByName(options.Vendor)
+	if vendor == nil {
+		names := make([]string, 0, len(o.VendorsAll.Vendors))
+		for _, candidate := range o.VendorsAll.Vendors {
+			names = append(names, candidate.GetName())
+		}
+		return fmt.Errorf(i18n.T("setup_unattended_unknown_vendor"), options.Vendor, strings.Join(names, ", "))
+	}
+
+	settings := vendor.GetSettings()
+	values := map[*plugins.Setting]string{}
+	if options.APIKey != "" {
+		setting := findSettingBySuffix(settings, apiKeySuffixes)
+		if setting == nil {
+			return fmt.Errorf(i18n.T("setup_unattended_no_setting"), vendor.GetName(), "*"+apiKeySuffixes[0])
+		}
+		values[setting] = options.APIKey
+	}
+	if options.BaseURL != "" {
+		setting := findSettingBySuffix(settings, baseURLSuffixes)
+		if setting == nil {
+			return fmt.Errorf(i18n.T("setup_unattended_no_setting"), vendor.GetName(), "*"+baseURLSuffixes[0])
+		}
+		values[setting] = options.BaseURL
+	}
+	for name, value := range options.Settings {
+		name = plugins.BuildEnvVariable(name)
+		index := slices.IndexFunc(settings, func(setting *plugins.Setting) bool {
+			return setting.EnvVariable == name || strings.HasSuffix(setting.EnvVariable, "_"+name)
+		})
+		if index < 0 {
+			return fmt.Errorf(i18n.T("setup_unattended_no_setting"), vendor.GetName(), name)
+		}
+		values[settings[index]] = value
+	}
+
+	for setting, value := range values {
+		setting.Value = value
+		if err = os.Setenv(setting.EnvVariable, value); err != nil {
+			return
+		}
+	}
+	if err = vendor.Configure(); err != nil {
+		return fmt.Errorf(i18n.T("setup_unattended_vendor_invalid"), vendor.GetName(), err)
+	}
+	if o.VendorManager.FindByName(vendor.GetName()) == nil {
+		o.VendorManager.AddVendors(vendor)
+	}
+	return
+}
+
+// findSettingBySuffix returns the first setting whose environment variable
+// ends with one of the suffixes, trying them in order.
+func findSettingBySuffix(settings plugins.Settings, suffixes []string) *plugins.Setting {
+	for _, suffix := range suffixes {
+		for _, setting := range settings {
+			if strings.HasSuffix(setting.EnvVariable, suffix) {
+				return setting
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+// settingsVendor is a testVendor with the settings of a real plugin
+type settingsVendor struct {
+	testVendor
+	base              *plugins.PluginBase
+	key, url, version *plugins.SetupQuestion
+}
+
+func newSettingsVendor(name string) *settingsVendor {
+	ret := &settingsVendor{testVendor: testVendor{name: name}, base: plugins.NewVendorPluginBase(name, nil)}
+	ret.key = ret.base.AddSetupQuestion("API Key", true)
+	ret.url = ret.base.AddSetupQuestion("API Base URL", false)
+	ret.version = ret.base.AddSetupQuestion("API Version", false)
+	return ret
+}
+
+func (o *settingsVendor) GetSettings() plugins.Settings { return o.base.GetSettings() }
+func (o *settingsVendor) IsConfigured() bool            { return o.base.IsConfigured() }
+func (o *settingsVendor) Configure() error              { return o.base.Configure() }
+
+func TestSetupVendorUnattended(t *testing.T) {
+	for _, name := range []string{"ACME_API_KEY", "ACME_API_BASE_URL", "ACME_API_VERSION"} {
+		t.Setenv(name, "")
+	}
+	vendor := newSettingsVendor("Acme")
+	all := ai.NewVendorsManager()
+	all.AddVendors(vendor)
+	registry := &PluginRegistry{VendorsAll: all, VendorManager: ai.NewVendorsManager()}
+
+	err := registry.setupVendorUnattended(&SetupOptions{Vendor: "acme", APIKey: "secret",
+		BaseURL: "https://acme.example/v1", Settings: map[string]string{"api_version": "2024-10-21"}})
+	if err != nil {
+		t.Fatalf("setupVendorUnattended() error = %v", err)
+	}
+	if vendor.key.Value != "secret" || vendor.url.Value != "https://acme.example/v1" || vendor.version.Value != "2024-10-21" {
+		t.Errorf("settings = %q, %q, %q", vendor.key.Value, vendor.url.Value, vendor.version.Value)
+	}
+	if registry.VendorManager.FindByName("Acme") == nil {
+		t.Error("the vendor was not added to the configured vendors")
+	}
+
+	if err = registry.setupVendorUnattended(&SetupOptions{Vendor: "unknown"}); err == nil {
+		t.Error("expected an error for an unknown vendor")
+	}
+	if err = registry.setupVendorUnattended(&SetupOptions{Vendor: "acme", Settings: map[string]string{"region": "eu"}}); err == nil {
+		t.Error("expected an error for an unknown setting")
+	}
+}
+
+func TestSetupVendorUnattended_RequiredSettingMissing(t *testing.T) {
+	t.Setenv("ACME_API_KEY", "")
+	all := ai.NewVendorsManager()
+	all.AddVendors(newSettingsVendor("Acme"))
+	registry := &PluginRegistry{VendorsAll: all, VendorManager: ai.NewVendorsManager()}
+
+	if err := registry.setupVendorUnattended(&SetupOptions{Vendor: "Acme"}); err == nil {
+		t.Error("expected an error for the missing API key")
+	}
+	if registry.VendorManager.FindByName("Acme") != nil {
+		t.Error("the vendor was added although it is not configured")
+	}
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// codeFence matches the opening or closing line of a fenced code block
+var codeFence = regexp.MustCompile("^\\s*(`{3,}|~{3,})\\s*(.*)$")
+
+// pathHeading strips the Markdown around a path written alone on the line
+// before a code block, like **src/main.go**, ### `main.go` or File: main.go
+var pathHeading = regexp.MustCompile("(?i)^(?:#+|[-*]|\\d+\\.)?\\s*(?:(?:file(?:name)?|path)\\s*:\\s*)?[*_`]*([^\\s*_`]+?)[*_`]*\\s*:?$")
+
+// plainFileNames are the file names without an extension that are paths too
+var plainFileNames = []string{"Makefile", "Dockerfile", "Containerfile", "Procfile", "Gemfile", "Rakefile", "Jenkinsfile", "LICENSE"}
+
+// ParseCodeBlocks returns the files of the code blocks annotated with their
+// path, in the info string (```go main.go, ```go:main.go, ```go title="main.go")
+// or alone on the line before the block (**main.go**, `main.go`, File: main.go).
+// Blocks without a path are skipped, and a later block for the same path
+// replaces the earlier one. The paths are relative and cleaned, those leaving
+// the directory being refused.
+func ParseCodeBlocks(output string) (ret []FileChange, err error) {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	index := map[string]int{}
+	lastText := ""
+	for i := 0; i < len(lines); i++ {
+		open := codeFence.FindStringSubmatch(lines[i])
+		if open == nil {
+			if text := strings.TrimSpace(lines[i]); text != "" {
+				lastText = text
+			}
+			continue
+		}
+
+		var content []string
+		for i++; i < len(lines); i++ {
+			// A closing fence is at least as long as the opening one
+			if closing := codeFence.FindStringSubmatch(lines[i]); closing != nil && closing[2] == "" &&
+				closing[1][0] == open[1][0] && len(closing[1]) >= len(open[1]) {
+				break
+			}
+			content = append(content, lines[i])
+		}
+
+		filePath := infoPath(open[2])
+		if filePath == "" {
+			if match := pathHeading.FindStringSubmatch(lastText); match != nil && looksLikePath(match[1]) {
+				filePath = match[1]
+			}
+		}
+		lastText = ""
+		if filePath == "" {
+			continue
+		}
+
+		n := len(ret)
+		cleaned := path.Clean(strings.TrimPrefix(strings.ReplaceAll(filePath, "\\", "/"), "./"))
+		if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return nil, fmt.Errorf(i18n.T("file_manager_suspicious_path"), n, filePath)
+		}
+		change := FileChange{Path: cleaned, Content: strings.Join(content, "\n") + "\n"}
+		if len(change.Content) > MaxFileSize {
+			return nil, fmt.Errorf(i18n.T("file_manager_file_content_too_large"), n, len(change.Content))
+		}
+		if previous, ok := index[cleaned]; ok {
+			ret[previous] = change
+			continue
+		}
+		index[cleaned] = n
+		ret = append(ret, change)
+	}
+	return
+}
+
+// infoPath returns the path of the info string of a code block
+func infoPath(info string) string {
+	for _, field := range strings.Fields(info) {
+		if name, value, ok := strings.Cut(field, "="); ok {
+			switch strings.ToLower(name) {
+			case "title", "file", "filename", "path":
+				field = strings.Trim(value, `"'`)
+			default:
+				continue
+			}
+		} else if _, value, ok := strings.Cut(field, ":"); ok {
+			field = value
+		}
+		if looksLikePath(field) {
+			return field
+		}
+	}
+	return ""
+}
+
+// looksLikePath reports whether the word is a file path rather than a language
+// or a sentence
+func looksLikePath(word string) bool {
+	if word == "" || strings.ContainsAny(word, " \t\"'<>|*?") || strings.Contains(word, "://") {
+		return false
+	}
+	base := path.Base(strings.ReplaceAll(word, "\\", "/"))
+	for _, name := range plainFileNames {
+		if base == name {
+			return true
+		}
+	}
+	// A file name has an extension, or at least a directory
+	return strings.Contains(strings.TrimPrefix(base, "."), ".") || strings.HasPrefix(base, ".") && len(base) > 1 ||
+		strings.Contains(word, "/") && !strings.HasSuffix(word, "/")
+}
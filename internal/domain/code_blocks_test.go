@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestParseCodeBlocks(t *testing.T) {
+	output := "Here is the project.\n\n" +
+		"```go main.go\npackage main\n```\n\n" +
+		"**internal/app/app.go**\n\n```go\npackage app\n```\n\n" +
+		"### `README.md`\n````markdown\n# App\n\n```bash\ngo run .\n```\n````\n\n" +
+		"```toml title=\"config/app.toml\"\nname = \"app\"\n```\n\n" +
+		"File: Dockerfile\n```\nFROM golang\n```\n\n" +
+		"Run it with:\n\n```bash\ngo run .\n```\n\n" +
+		"```go:main.go\npackage main\n\nfunc main() {}\n```\n"
+
+	got, err := ParseCodeBlocks(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileChange{
+		{Path: "main.go", Content: "package main\n\nfunc main() {}\n"},
+		{Path: "internal/app/app.go", Content: "package app\n"},
+		{Path: "README.md", Content: "# App\n\n```bash\ngo run .\n```\n"},
+		{Path: "config/app.toml", Content: "name = \"app\"\n"},
+		{Path: "Dockerfile", Content: "FROM golang\n"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseCodeBlocks() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("file %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseCodeBlocks_SuspiciousPath(t *testing.T) {
+	for _, path := range []string{"../outside.go", "/etc/passwd.conf", "src/../../outside.go"} {
+		if _, err := ParseCodeBlocks("```go " + path + "\npackage main\n```\n"); err == nil {
+			t.Errorf("ParseCodeBlocks() accepted %s", path)
+		}
+	}
+}
+
+func TestLooksLikePath(t *testing.T) {
+	tests := map[string]bool{
+		"main.go":            true,
+		"src/app":            true,
+		".env":               true,
+		"Makefile":           true,
+		"go":                 false,
+		"bash":               false,
+		"https://example.io": false,
+		"Run it:":            false,
+	}
+	for word, want := range tests {
+		if got := looksLikePath(word); got != want {
+			t.Errorf("looksLikePath(%q) = %v, want %v", word, got, want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "github.com/danielmiessler/fabric/internal/chat"
+import (
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+)
 
 const ChatMessageRoleMeta = "meta"
 
@@ -10,11 +14,20 @@ const (
 	DefaultTopP             = 0.9
 	DefaultPresencePenalty  = 0.0
 	DefaultFrequencyPenalty = 0.0
+	// DeterministicSeed is the seed of deterministic requests without --seed
+	DeterministicSeed = 42
 )
 
 type ChatRequest struct {
-	ContextName           string
-	SessionName           string
+	// ContextName names one or more comma separated contexts, joined in order
+	ContextName      string
+	ContextVariables map[string]string
+	// EphemeralContext follows the named contexts without being stored
+	EphemeralContext string
+	SessionName      string
+	// SessionTitle and SessionTags replace the title and tags of the session
+	SessionTitle          string
+	SessionTags           []string
 	PatternName           string
 	PatternVariables      map[string]string
 	Message               *chat.ChatCompletionMessage
@@ -23,6 +36,24 @@ type ChatRequest struct {
 	InputHasVars          bool
 	NoVariableReplacement bool
 	StrategyName          string
+	// Refine critiques and revises the response up to this many times, stopping
+	// once a critique scores it RefineThreshold out of 10 or higher
+	Refine          int
+	RefineThreshold int
+	// RefinePattern critiques the response instead of the built-in critique prompt
+	RefinePattern string
+	// Samples sends the request this many times, combining the responses as
+	// Select says, instead of the samples of the strategy
+	Samples int
+	// Select is how the samples are combined, one of the strategy aggregates,
+	// the aggregate of the strategy when empty
+	Select string
+	// JudgePattern picks the best sample instead of the built-in judge prompt
+	JudgePattern string
+	// TranslateOutput translates the response into this language once complete
+	TranslateOutput string
+	// Resume continues the last response of the session instead of sending a message
+	Resume bool
 }
 
 type ChatOptions struct {
@@ -36,8 +67,12 @@ type ChatOptions struct {
 	Thinking            ThinkingLevel
 	ModelContextLength  int
 	MaxTokens           int
+	Truncate            string
 	Search              bool
 	SearchLocation      string
+	ProviderOrder       []string
+	ProviderSort        string
+	NoProviderFallbacks bool
 	ImageFile           string
 	ImageSize           string
 	ImageQuality        string
@@ -46,6 +81,8 @@ type ChatOptions struct {
 	SuppressThink       bool
 	ThinkStartTag       string
 	ThinkEndTag         string
+	ShowThink           string
+	ThinkOutput         string
 	AudioOutput         bool
 	AudioFormat         string
 	Voice               string
@@ -53,7 +90,43 @@ type ChatOptions struct {
 	NotificationCommand string
 	ShowMetadata        bool
 	Quiet               bool
-	UpdateChan          chan StreamUpdate `json:"-"`
+	RenderMarkdown      bool
+	// Logprobs asks for the log probability of each token of the response, and
+	// TopLogprobs for that many alternatives at each position
+	Logprobs    bool
+	TopLogprobs int
+	// ModelParams are merged into the JSON body of the vendor requests, the dots
+	// of a key naming nested fields
+	ModelParams map[string]any
+	// KeepAlive is how long Ollama keeps the model loaded after the request,
+	// forever when negative, the server default when nil
+	KeepAlive *time.Duration
+	// NumGPU is the number of layers Ollama offloads to the GPUs, its own
+	// choice when nil, and NumThread, NumBatch and Mirostat its options of the
+	// same name when not 0
+	NumGPU    *int
+	NumThread int
+	NumBatch  int
+	Mirostat  int
+	// Deterministic asks for reproducible responses: the vendors accepting
+	// only one of the temperature and top P send the temperature, and those
+	// whose thinking requires sampling leave thinking off
+	Deterministic bool
+	UpdateChan    chan StreamUpdate `json:"-"`
+}
+
+// SetDeterministic sets the options for reproducible responses: no
+// temperature, no penalties, a top P of 1 and a fixed seed, the given one or
+// DeterministicSeed.
+func (o *ChatOptions) SetDeterministic() {
+	o.Deterministic = true
+	o.Temperature, o.TopP = 0, 1
+	o.PresencePenalty, o.FrequencyPenalty = 0, 0
+	if o.Seed == 0 {
+		o.Seed = DeterministicSeed
+	}
+	// Raw would leave the sampling to the defaults of the model
+	o.Raw = false
 }
 
 // NormalizeMessages remove empty messages and ensure messages order user-assist-user
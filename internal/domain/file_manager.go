@@ -167,21 +167,30 @@ func fixInvalidEscapes(jsonStr string) string {
 	return result.String()
 }
 
-// ApplyFileChanges applies the parsed file changes to the file system
+// ApplyFileChanges applies the parsed file changes to the file system. The
+// files are written within the project root, which a symlink cannot escape.
 func ApplyFileChanges(projectRoot string, changes []FileChange) error {
+	if err := os.MkdirAll(projectRoot, 0755); err != nil {
+		return fmt.Errorf(i18n.T("file_manager_failed_open_directory"), projectRoot, err)
+	}
+	root, err := os.OpenRoot(projectRoot)
+	if err != nil {
+		return fmt.Errorf(i18n.T("file_manager_failed_open_directory"), projectRoot, err)
+	}
+	defer root.Close()
+
 	for i, change := range changes {
-		// Get the absolute path
-		absPath := filepath.Join(projectRoot, change.Path)
+		path := filepath.FromSlash(change.Path)
 
 		// Create directories if necessary
-		dir := filepath.Dir(absPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf(i18n.T("file_manager_failed_create_directory"), dir, i, err)
+		dir := filepath.Dir(path)
+		if err := root.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf(i18n.T("file_manager_failed_create_directory"), filepath.Join(projectRoot, dir), i, err)
 		}
 
 		// Write the file
-		if err := os.WriteFile(absPath, []byte(change.Content), 0644); err != nil {
-			return fmt.Errorf(i18n.T("file_manager_failed_write_file"), absPath, i, err)
+		if err := root.WriteFile(path, []byte(change.Content), 0644); err != nil {
+			return fmt.Errorf(i18n.T("file_manager_failed_write_file"), filepath.Join(projectRoot, path), i, err)
 		}
 
 		fmt.Printf(i18n.T("file_manager_applied_operation")+"\n", change.Operation, change.Path)
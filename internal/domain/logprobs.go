@@ -0,0 +1,9 @@
+package domain
+
+// TokenLogprob is the log probability of a token of the response, with the
+// most likely tokens at its position when alternatives were requested.
+type TokenLogprob struct {
+	Token       string         `json:"token"`
+	Logprob     float64        `json:"logprob"`
+	TopLogprobs []TokenLogprob `json:"top_logprobs,omitempty"`
+}
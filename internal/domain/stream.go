@@ -4,21 +4,23 @@ package domain
 type StreamType string
 
 const (
-	StreamTypeContent StreamType = "content"
-	StreamTypeUsage   StreamType = "usage"
-	StreamTypeError   StreamType = "error"
+	StreamTypeContent   StreamType = "content"
+	StreamTypeReasoning StreamType = "reasoning"
+	StreamTypeUsage     StreamType = "usage"
+	StreamTypeError     StreamType = "error"
 )
 
 // StreamUpdate is the unified payload sent through the internal channels.
 type StreamUpdate struct {
 	Type    StreamType     `json:"type"`
-	Content string         `json:"content,omitempty"` // For text deltas
+	Content string         `json:"content,omitempty"` // For text and reasoning deltas
 	Usage   *UsageMetadata `json:"usage,omitempty"`   // For token counts
 }
 
 // UsageMetadata normalizes token counts across different providers.
 type UsageMetadata struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
-	TotalTokens  int `json:"total_tokens"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TotalTokens  int     `json:"total_tokens"`
+	Cost         float64 `json:"cost,omitempty"` // In USD, when reported by the provider
 }
@@ -2,31 +2,50 @@ package domain
 
 import (
 	"regexp"
+	"strings"
 	"sync"
 )
 
-// StripThinkBlocks removes any content between the provided start and end tags
-// from the input string. Whitespace following the end tag is also removed so
-// output resumes at the next non-empty line.
 var (
 	regexCache = make(map[string]*regexp.Regexp)
 	cacheMutex sync.Mutex
 )
 
-func StripThinkBlocks(input, startTag, endTag string) string {
-	if startTag == "" || endTag == "" {
-		return input
-	}
-
+// thinkBlockRegex returns the cached expression matching a think block, with its
+// content captured and the whitespace following the end tag included.
+func thinkBlockRegex(startTag, endTag string) *regexp.Regexp {
 	cacheKey := startTag + "|" + endTag
 	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
 	re, exists := regexCache[cacheKey]
 	if !exists {
-		pattern := "(?s)" + regexp.QuoteMeta(startTag) + ".*?" + regexp.QuoteMeta(endTag) + "\\s*"
+		pattern := "(?s)" + regexp.QuoteMeta(startTag) + "(.*?)" + regexp.QuoteMeta(endTag) + "\\s*"
 		re = regexp.MustCompile(pattern)
 		regexCache[cacheKey] = re
 	}
-	cacheMutex.Unlock()
+	return re
+}
 
-	return re.ReplaceAllString(input, "")
+// StripThinkBlocks removes any content between the provided start and end tags
+// from the input string. Whitespace following the end tag is also removed so
+// output resumes at the next non-empty line.
+func StripThinkBlocks(input, startTag, endTag string) string {
+	if startTag == "" || endTag == "" {
+		return input
+	}
+	return thinkBlockRegex(startTag, endTag).ReplaceAllString(input, "")
+}
+
+// ExtractThinkBlocks returns the trimmed content of each block enclosed in the
+// provided start and end tags.
+func ExtractThinkBlocks(input, startTag, endTag string) (ret []string) {
+	if startTag == "" || endTag == "" {
+		return
+	}
+	for _, match := range thinkBlockRegex(startTag, endTag).FindAllStringSubmatch(input, -1) {
+		if block := strings.TrimSpace(match[1]); block != "" {
+			ret = append(ret, block)
+		}
+	}
+	return
 }
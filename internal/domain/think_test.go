@@ -17,3 +17,14 @@ func TestStripThinkBlocksCustomTags(t *testing.T) {
 		t.Errorf("expected %q, got %q", "visible", got)
 	}
 }
+
+func TestExtractThinkBlocks(t *testing.T) {
+	input := "<think>\nfirst\n</think>\n\nresult <think></think> and <think>second</think>"
+	got := ExtractThinkBlocks(input, "<think>", "</think>")
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("unexpected think blocks %q", got)
+	}
+	if got := ExtractThinkBlocks(input, "", ""); got != nil {
+		t.Errorf("expected no blocks without tags, got %q", got)
+	}
+}
@@ -32,3 +32,24 @@ var ThinkingBudgets = map[ThinkingLevel]int64{
 	ThinkingMedium: TokenBudgetMedium,
 	ThinkingHigh:   TokenBudgetHigh,
 }
+
+// ThinkingLevelForBudget returns the level whose budget best matches a token budget,
+// for vendors that only accept effort levels.
+func ThinkingLevelForBudget(tokens int64) ThinkingLevel {
+	switch {
+	case tokens <= TokenBudgetLow:
+		return ThinkingLow
+	case tokens <= TokenBudgetMedium:
+		return ThinkingMedium
+	default:
+		return ThinkingHigh
+	}
+}
+
+// Values of --show-think.
+const (
+	// ShowThinkDim prints the streamed thinking dimmed along with the answer.
+	ShowThinkDim = "dim"
+	// ShowThinkStderr prints the thinking to stderr, keeping the answer clean.
+	ShowThinkStderr = "stderr"
+)
@@ -0,0 +1,18 @@
+package domain
+
+import "testing"
+
+func TestThinkingLevelForBudget(t *testing.T) {
+	tests := map[int64]ThinkingLevel{
+		500:   ThinkingLow,
+		1024:  ThinkingLow,
+		2000:  ThinkingMedium,
+		4096:  ThinkingHigh,
+		32000: ThinkingHigh,
+	}
+	for tokens, want := range tests {
+		if got := ThinkingLevelForBudget(tokens); got != want {
+			t.Errorf("ThinkingLevelForBudget(%d) = %q, want %q", tokens, got, want)
+		}
+	}
+}
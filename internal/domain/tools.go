@@ -0,0 +1,9 @@
+package domain
+
+// Tool is a function a model can call before it answers: its name, what it
+// does, and the JSON schema of the object of its arguments.
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema,omitempty"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+// Truncation modes for input that does not fit the model context window. Each
+// names the part of the input that is dropped.
+const (
+	TruncateHead   = "head"
+	TruncateTail   = "tail"
+	TruncateMiddle = "middle"
+)
+
+// TruncationMarker replaces the dropped part of truncated input.
+const TruncationMarker = "\n[...truncated...]\n"
+
+// TruncateText shortens input to at most maxRunes runes (marker included) by dropping
+// its head, tail or middle according to mode.
+func TruncateText(input string, maxRunes int, mode string) string {
+	runes := []rune(input)
+	if len(runes) <= maxRunes {
+		return input
+	}
+	keep := max(maxRunes-len([]rune(TruncationMarker)), 0)
+
+	switch mode {
+	case TruncateHead:
+		return TruncationMarker + string(runes[len(runes)-keep:])
+	case TruncateMiddle:
+		head := keep / 2
+		return string(runes[:head]) + TruncationMarker + string(runes[len(runes)-(keep-head):])
+	default:
+		return string(runes[:keep]) + TruncationMarker
+	}
+}
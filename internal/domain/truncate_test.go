@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestTruncateText(t *testing.T) {
+	input := "0123456789abcdefghijklmnopqrstuvwxyz"
+	maxRunes := len(TruncationMarker) + 10
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{TruncateHead, TruncationMarker + "qrstuvwxyz"},
+		{TruncateTail, "0123456789" + TruncationMarker},
+		{TruncateMiddle, "01234" + TruncationMarker + "vwxyz"},
+	}
+	for _, tt := range tests {
+		if got := TruncateText(input, maxRunes, tt.mode); got != tt.want {
+			t.Errorf("TruncateText(%s) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+
+	if got := TruncateText("short", 100, TruncateHead); got != "short" {
+		t.Errorf("expected input that fits to be unchanged, got %q", got)
+	}
+	if got := TruncateText("日本語のテキスト", 3, TruncateTail); got != TruncationMarker {
+		t.Errorf("expected only the marker when nothing fits, got %q", got)
+	}
+}
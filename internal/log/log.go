@@ -3,8 +3,12 @@ package log
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"strings"
 	"sync"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
 )
 
 // Level represents the debug verbosity.
@@ -23,10 +27,18 @@ const (
 	Wire
 )
 
+// Formats of the structured logs
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 var (
 	mu     sync.RWMutex
 	level  Level     = Off
 	output io.Writer = os.Stderr
+	// logger receives the messages once Configure was called, instead of output
+	logger *slog.Logger
 )
 
 // SetLevel sets the global debug level.
@@ -54,26 +66,82 @@ func LevelFromInt(i int) Level {
 	}
 }
 
+// ParseLevel returns the slog level of debug, info, warn or error
+func ParseLevel(name string) (ret slog.Level, err error) {
+	if err = ret.UnmarshalText([]byte(name)); err != nil {
+		err = fmt.Errorf(i18n.T("log_invalid_level"), name)
+	}
+	return
+}
+
+// Configure sends the messages to a slog handler writing to w in the format,
+// text or json, from the level. The debug messages are logged at the debug
+// level with their verbosity, and the messages of the standard log package at
+// the info level.
+func Configure(minLevel slog.Level, format string, w io.Writer) (err error) {
+	options := &slog.HandlerOptions{Level: minLevel}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(w, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf(i18n.T("log_invalid_format"), format)
+	}
+	configured := slog.New(handler)
+	slog.SetDefault(configured)
+
+	mu.Lock()
+	logger = configured
+	mu.Unlock()
+	return
+}
+
+// Reset restores the plain output of the messages
+func Reset() {
+	mu.Lock()
+	logger = nil
+	mu.Unlock()
+}
+
 // Debug writes a debug message if the global level permits.
 func Debug(l Level, format string, a ...any) {
 	mu.RLock()
 	current := level
 	w := output
+	structured := logger
 	mu.RUnlock()
-	if current >= l {
-		fmt.Fprintf(w, "DEBUG: "+format, a...)
+	if current < l {
+		return
+	}
+	if structured != nil {
+		structured.Debug(message(format, a...), "verbosity", int(l))
+		return
 	}
+	fmt.Fprintf(w, "DEBUG: "+format, a...)
 }
 
 // Log writes a message unconditionally to stderr.
 // This is for important messages that should always be shown regardless of debug level.
+// Once Configure was called, it is logged at the info level.
 func Log(format string, a ...any) {
 	mu.RLock()
 	w := output
+	structured := logger
 	mu.RUnlock()
+	if structured != nil {
+		structured.Info(message(format, a...))
+		return
+	}
 	fmt.Fprintf(w, format, a...)
 }
 
+// message formats a message for slog, which ends the records itself
+func message(format string, a ...any) string {
+	return strings.TrimSpace(fmt.Sprintf(format, a...))
+}
+
 // SetOutput allows overriding the output destination for debug logs.
 func SetOutput(w io.Writer) {
 	mu.Lock()
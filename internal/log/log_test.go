@@ -1,6 +1,13 @@
 package log
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	stdlog "log"
+	"log/slog"
+	"strings"
+	"testing"
+)
 
 func TestLevelFromInt(t *testing.T) {
 	tests := []struct {
@@ -22,3 +29,72 @@ func TestLevelFromInt(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigureJSON(t *testing.T) {
+	previous := slog.Default()
+	defer func() {
+		Reset()
+		slog.SetDefault(previous)
+		SetLevel(Off)
+	}()
+
+	var buf bytes.Buffer
+	if err := Configure(slog.LevelDebug, FormatJSON, &buf); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	SetLevel(Detailed)
+	Debug(Detailed, "loaded %d patterns\n", 3)
+	Debug(Trace, "too verbose\n")
+	Log("Warning: %s\n", "check")
+	stdlog.Printf("from the log package")
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d: %s", len(records), buf.String())
+	}
+	if records[0]["level"] != "DEBUG" || records[0]["msg"] != "loaded 3 patterns" || records[0]["verbosity"] != float64(Detailed) {
+		t.Errorf("unexpected debug record %v", records[0])
+	}
+	if records[1]["level"] != "INFO" || records[1]["msg"] != "Warning: check" {
+		t.Errorf("unexpected log record %v", records[1])
+	}
+	if records[2]["msg"] != "from the log package" {
+		t.Errorf("unexpected record of the log package %v", records[2])
+	}
+}
+
+func TestConfigureLevel(t *testing.T) {
+	previous := slog.Default()
+	defer func() {
+		Reset()
+		slog.SetDefault(previous)
+	}()
+
+	level, err := ParseLevel("warn")
+	if err != nil {
+		t.Fatalf("ParseLevel() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err = Configure(level, FormatText, &buf); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	Log("hidden\n")
+	slog.Warn("shown", "job", "daily")
+	if got := buf.String(); strings.Contains(got, "hidden") || !strings.Contains(got, "msg=shown job=daily") {
+		t.Errorf("unexpected logs %q", got)
+	}
+
+	if _, err = ParseLevel("loud"); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+	if err = Configure(slog.LevelInfo, "xml", &buf); err == nil {
+		t.Error("expected an error for an invalid format")
+	}
+}
@@ -2,6 +2,7 @@ package anthropic
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	neturl "net/url"
 	"os"
@@ -123,12 +124,7 @@ type Client struct {
 }
 
 func (an *Client) Setup() (err error) {
-	if err = an.PluginBase.Ask(an.Name); err != nil {
-		return
-	}
-
-	err = an.configure()
-	return
+	return an.PluginBase.Setup()
 }
 
 func (an *Client) configure() (err error) {
@@ -138,7 +134,7 @@ func (an *Client) configure() (err error) {
 		opts = append(opts, option.WithBaseURL(an.ApiBaseURL.Value))
 	}
 
-	opts = append(opts, option.WithAPIKey(an.ApiKey.Value))
+	opts = append(opts, option.WithAPIKey(an.ApiKey.Value), option.WithHTTPClient(an.HTTPClient(0)))
 
 	an.client = anthropic.NewClient(opts...)
 	return
@@ -148,6 +144,9 @@ func (an *Client) ListModels(context.Context) (ret []string, err error) {
 	return an.models, nil
 }
 
+// minThinkingBudget is the smallest extended thinking budget Anthropic accepts.
+const minThinkingBudget = 1024
+
 func parseThinking(level domain.ThinkingLevel) (anthropic.ThinkingConfigParamUnion, bool) {
 	lower := strings.ToLower(string(level))
 	switch domain.ThinkingLevel(lower) {
@@ -160,8 +159,9 @@ func parseThinking(level domain.ThinkingLevel) (anthropic.ThinkingConfigParamUni
 		}
 	default:
 		if tokens, err := strconv.ParseInt(lower, 10, 64); err == nil {
-			if tokens >= 1 && tokens <= 10000 {
-				return anthropic.ThinkingConfigParamOfEnabled(tokens), true
+			if tokens >= 1 {
+				// Anthropic rejects budgets below its minimum
+				return anthropic.ThinkingConfigParamOfEnabled(max(tokens, minThinkingBudget)), true
 			}
 		}
 	}
@@ -200,6 +200,12 @@ func (an *Client) SendStream(
 				Content: event.Delta.Text,
 			}
 		}
+		if event.Delta.Thinking != "" {
+			channel <- domain.StreamUpdate{
+				Type:    domain.StreamTypeReasoning,
+				Content: event.Delta.Thinking,
+			}
+		}
 
 		// Handle Usage
 		if event.Message.Usage.InputTokens != 0 || event.Message.Usage.OutputTokens != 0 {
@@ -230,6 +236,11 @@ func (an *Client) SendStream(
 	return
 }
 
+// BuildRequest returns the Messages API request Send would make, for --dry-run
+func (an *Client) BuildRequest(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (any, error) {
+	return an.buildMessageParams(an.toMessages(msgs), opts), nil
+}
+
 func (an *Client) buildMessageParams(msgs []anthropic.MessageParam, opts *domain.ChatOptions) (
 	params anthropic.MessageNewParams) {
 
@@ -244,10 +255,19 @@ func (an *Client) buildMessageParams(msgs []anthropic.MessageParam, opts *domain
 		Messages:  msgs,
 	}
 
+	thinking, thinkingOK := parseThinking(opts.Thinking)
+	thinkingEnabled := thinkingOK && thinking.OfEnabled != nil
+	if thinkingEnabled && opts.Deterministic && !modelDisallowsSamplingParams(opts.Model) {
+		// Extended thinking samples at temperature 1 whatever the request says
+		debuglog.Debug(debuglog.Basic, "Anthropic extended thinking is not deterministic, ignoring it\n")
+		thinking, thinkingOK, thinkingEnabled = anthropic.ThinkingConfigParamUnion{}, false, false
+	}
+
 	// Claude Opus 4.7 disallows sampling params; omit both temperature and top_p.
-	if modelDisallowsSamplingParams(opts.Model) {
+	// Extended thinking is not compatible with temperature or top_p changes either.
+	if modelDisallowsSamplingParams(opts.Model) || thinkingEnabled {
 		// Intentionally omit both fields.
-	} else if opts.TopP != domain.DefaultTopP {
+	} else if opts.TopP != domain.DefaultTopP && !opts.Deterministic {
 		// User explicitly set TopP, so use that instead of temperature
 		params.TopP = anthropic.Opt(opts.TopP)
 	} else {
@@ -274,8 +294,12 @@ func (an *Client) buildMessageParams(msgs []anthropic.MessageParam, opts *domain
 		}
 	}
 
-	if t, ok := parseThinking(opts.Thinking); ok {
-		params.Thinking = t
+	if thinkingOK {
+		params.Thinking = thinking
+		// The thinking budget counts towards max_tokens and must stay below it
+		if thinkingEnabled && thinking.OfEnabled.BudgetTokens >= params.MaxTokens {
+			params.MaxTokens = thinking.OfEnabled.BudgetTokens + int64(maxTokens)
+		}
 	}
 
 	return
@@ -291,21 +315,8 @@ func (an *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage,
 	}
 
 	var message *anthropic.Message
-	params := an.buildMessageParams(messages, opts)
-	betas := an.modelBetas[opts.Model]
-	var reqOpts []option.RequestOption
-	if len(betas) > 0 {
-		reqOpts = append(reqOpts, option.WithHeader("anthropic-beta", strings.Join(betas, ",")))
-	}
-	if message, err = an.client.Messages.New(ctx, params, reqOpts...); err != nil {
-		if len(betas) > 0 {
-			debuglog.Debug(debuglog.Basic, "Anthropic beta feature %s failed: %v\n", strings.Join(betas, ","), err)
-			if message, err = an.client.Messages.New(ctx, params); err != nil {
-				return
-			}
-		} else {
-			return
-		}
+	if message, err = an.newMessage(ctx, an.buildMessageParams(messages, opts)); err != nil {
+		return
 	}
 
 	var textParts []string
@@ -348,6 +359,82 @@ func (an *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage,
 	return
 }
 
+// newMessage sends the request with the beta features of the model, and
+// without them when they fail
+func (an *Client) newMessage(ctx context.Context, params anthropic.MessageNewParams) (message *anthropic.Message, err error) {
+	betas := an.modelBetas[string(params.Model)]
+	if len(betas) == 0 {
+		return an.client.Messages.New(ctx, params)
+	}
+	if message, err = an.client.Messages.New(ctx, params, option.WithHeader("anthropic-beta", strings.Join(betas, ","))); err != nil {
+		debuglog.Debug(debuglog.Basic, "Anthropic beta feature %s failed: %v\n", strings.Join(betas, ","), err)
+		return an.client.Messages.New(ctx, params)
+	}
+	return
+}
+
+// SendWithTools sends the messages offering the tools to the model, and
+// returns its reply with the tools it calls
+func (an *Client) SendWithTools(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, tools []domain.Tool) (
+	ret *chat.ChatCompletionMessage, err error) {
+
+	if thinking, ok := parseThinking(opts.Thinking); ok && thinking.OfEnabled != nil {
+		// The thinking blocks would have to be sent back with the tool results
+		debuglog.Debug(debuglog.Basic, "Anthropic extended thinking is not used with tools, ignoring it\n")
+		withoutThinking := *opts
+		withoutThinking.Thinking = ""
+		opts = &withoutThinking
+	}
+	params := an.buildMessageParams(an.toMessages(msgs), opts)
+	for _, tool := range tools {
+		params.Tools = append(params.Tools, anthropic.ToolUnionParam{OfTool: toolParam(tool)})
+	}
+
+	var message *anthropic.Message
+	if message, err = an.newMessage(ctx, params); err != nil {
+		return
+	}
+	ret = &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant}
+	for _, block := range message.Content {
+		switch block.Type {
+		case "text":
+			ret.Content += block.Text
+		case "tool_use":
+			ret.ToolCalls = append(ret.ToolCalls, chat.ToolCall{ID: block.ID, Type: chat.ToolTypeFunction,
+				Function: chat.FunctionCall{Name: block.Name, Arguments: string(block.Input)}})
+		}
+	}
+	return
+}
+
+// toolParam describes the tool to the model, its input schema being split
+// into the properties and the required ones the SDK has fields for
+func toolParam(tool domain.Tool) *anthropic.ToolParam {
+	schema := anthropic.ToolInputSchemaParam{ExtraFields: map[string]any{}}
+	for key, value := range tool.InputSchema {
+		switch key {
+		case "type":
+		case "properties":
+			schema.Properties = value
+		case "required":
+			if required, ok := value.([]any); ok {
+				for _, name := range required {
+					if name, ok := name.(string); ok {
+						schema.Required = append(schema.Required, name)
+					}
+				}
+			}
+		default:
+			schema.ExtraFields[key] = value
+		}
+	}
+	ret := &anthropic.ToolParam{Name: tool.Name, InputSchema: schema}
+	if tool.Description != "" {
+		ret.Description = anthropic.String(tool.Description)
+	}
+	return ret
+}
+
 func (an *Client) toMessages(msgs []*chat.ChatCompletionMessage) (ret []anthropic.MessageParam) {
 	// Custom normalization for Anthropic:
 	// - System messages become the first part of the first user message.
@@ -363,7 +450,8 @@ func (an *Client) toMessages(msgs []*chat.ChatCompletionMessage) (ret []anthropi
 	lastRoleWasUser := false
 
 	for _, msg := range msgs {
-		if strings.TrimSpace(msg.Content) == "" && len(msg.MultiContent) == 0 {
+		if strings.TrimSpace(msg.Content) == "" && len(msg.MultiContent) == 0 && len(msg.ToolCalls) == 0 &&
+			msg.Role != chat.ChatMessageRoleTool {
 			continue // Skip empty messages
 		}
 
@@ -408,8 +496,28 @@ func (an *Client) toMessages(msgs []*chat.ChatCompletionMessage) (ret []anthropi
 				anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(anthropic.NewTextBlock(an.defaultRequiredUserMessage)))
 				lastRoleWasUser = true
 			}
-			anthropicMessages = append(anthropicMessages, anthropic.NewAssistantMessage(anthropic.NewTextBlock(msg.Content)))
+			var blocks []anthropic.ContentBlockParamUnion
+			if strings.TrimSpace(msg.Content) != "" {
+				blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
+			}
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if len(input) == 0 {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropic.NewToolUseBlock(call.ID, input, call.Function.Name))
+			}
+			anthropicMessages = append(anthropicMessages, anthropic.NewAssistantMessage(blocks...))
 			lastRoleWasUser = false
+		case chat.ChatMessageRoleTool:
+			// The results of the tools called together go in one user message
+			result := anthropic.NewToolResultBlock(msg.ToolCallID, msg.Content, false)
+			if last := len(anthropicMessages) - 1; lastRoleWasUser && last >= 0 && anthropicMessages[last].Content[0].OfToolResult != nil {
+				anthropicMessages[last].Content = append(anthropicMessages[last].Content, result)
+			} else {
+				anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(result))
+			}
+			lastRoleWasUser = true
 		default:
 			// Other roles (like 'meta') are ignored for Anthropic's message structure.
 			continue
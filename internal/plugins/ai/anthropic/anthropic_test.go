@@ -2,6 +2,7 @@ package anthropic
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -136,6 +137,35 @@ func TestBuildMessageParams_UsesChatOptionsMaxTokens(t *testing.T) {
 	}
 }
 
+func TestBuildRequest_MarshalsMessagesAPIBody(t *testing.T) {
+	client := NewClient()
+	opts := &domain.ChatOptions{
+		Model:       "claude-3-5-sonnet-latest",
+		Temperature: domain.DefaultTemperature,
+		TopP:        domain.DefaultTopP,
+		MaxTokens:   1000,
+	}
+	msgs := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "Be brief."},
+		{Role: chat.ChatMessageRoleUser, Content: "Hello"},
+	}
+
+	request, err := client.BuildRequest(context.Background(), msgs, opts)
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("request does not marshal: %v", err)
+	}
+	body := string(data)
+	for _, want := range []string{`"model":"claude-3-5-sonnet-latest"`, `"max_tokens":1000`, `"role":"user"`, "Be brief.", "Hello"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("request %s does not contain %s", body, want)
+		}
+	}
+}
+
 func TestBuildMessageParams_WithSearch(t *testing.T) {
 	client := NewClient()
 	opts := &domain.ChatOptions{
@@ -424,3 +454,123 @@ func TestToMessages_MultiContentPDFAttachment(t *testing.T) {
 		t.Fatalf("Expected document data to match base64 payload, got %s", document.Source.OfBase64.Data)
 	}
 }
+
+func TestBuildMessageParams_ThinkingBudget(t *testing.T) {
+	client := NewClient()
+	opts := &domain.ChatOptions{
+		Model:       "claude-sonnet-4-5",
+		Temperature: 0.5,
+		TopP:        0.8,
+		MaxTokens:   4096,
+		Thinking:    domain.ThinkingLevel("16000"),
+	}
+	messages := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock("Hello")),
+	}
+
+	params := client.buildMessageParams(messages, opts)
+
+	if params.Thinking.OfEnabled == nil || params.Thinking.OfEnabled.BudgetTokens != 16000 {
+		t.Fatalf("expected thinking budget 16000, got %+v", params.Thinking)
+	}
+	if params.MaxTokens != 16000+4096 {
+		t.Errorf("expected max_tokens raised above the thinking budget, got %d", params.MaxTokens)
+	}
+	if params.Temperature.Value != 0 || params.TopP.Value != 0 {
+		t.Errorf("expected sampling params to be omitted with thinking, got temperature %f top_p %f", params.Temperature.Value, params.TopP.Value)
+	}
+}
+
+func TestBuildMessageParams_Deterministic(t *testing.T) {
+	client := NewClient()
+	opts := &domain.ChatOptions{
+		Model:    "claude-sonnet-4-5",
+		Thinking: domain.ThinkingHigh,
+	}
+	opts.SetDeterministic()
+	messages := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock("Hello")),
+	}
+
+	params := client.buildMessageParams(messages, opts)
+
+	if !params.Temperature.Valid() || params.Temperature.Value != 0 {
+		t.Errorf("expected temperature 0, got %+v", params.Temperature)
+	}
+	if params.TopP.Valid() {
+		t.Errorf("expected top_p to be omitted, got %f", params.TopP.Value)
+	}
+	if params.Thinking.OfEnabled != nil {
+		t.Errorf("expected thinking to be left off, got %+v", params.Thinking)
+	}
+}
+
+func TestParseThinking_ClampsSmallBudgets(t *testing.T) {
+	thinking, ok := parseThinking(domain.ThinkingLevel("100"))
+	if !ok || thinking.OfEnabled == nil {
+		t.Fatalf("expected thinking to be enabled")
+	}
+	if thinking.OfEnabled.BudgetTokens != minThinkingBudget {
+		t.Errorf("expected budget %d, got %d", minThinkingBudget, thinking.OfEnabled.BudgetTokens)
+	}
+}
+
+func TestToMessages_ToolCalls(t *testing.T) {
+	client := NewClient()
+	messages := client.toMessages([]*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleUser, Content: "Weather in Paris and Rome?"},
+		{Role: chat.ChatMessageRoleAssistant, Content: "Let me check.", ToolCalls: []chat.ToolCall{
+			{ID: "toolu_1", Function: chat.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}},
+			{ID: "toolu_2", Function: chat.FunctionCall{Name: "weather", Arguments: `{"city":"Rome"}`}},
+		}},
+		{Role: chat.ChatMessageRoleTool, ToolCallID: "toolu_1", Content: "sunny"},
+		{Role: chat.ChatMessageRoleTool, ToolCallID: "toolu_2", Content: ""},
+	})
+
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+	assistant := messages[1].Content
+	if len(assistant) != 3 || assistant[0].OfText == nil || assistant[1].OfToolUse == nil {
+		t.Fatalf("Expected the text and the tool uses of the assistant, got %#v", assistant)
+	}
+	if assistant[1].OfToolUse.ID != "toolu_1" || assistant[1].OfToolUse.Name != "weather" {
+		t.Errorf("Unexpected tool use %#v", assistant[1].OfToolUse)
+	}
+	results := messages[2].Content
+	if messages[2].Role != anthropic.MessageParamRoleUser || len(results) != 2 {
+		t.Fatalf("Expected the results of both tools in one user message, got %#v", messages[2])
+	}
+	if results[0].OfToolResult == nil || results[0].OfToolResult.ToolUseID != "toolu_1" ||
+		results[1].OfToolResult == nil || results[1].OfToolResult.ToolUseID != "toolu_2" {
+		t.Errorf("Unexpected tool results %#v", results)
+	}
+}
+
+func TestToolParam(t *testing.T) {
+	tool := toolParam(domain.Tool{Name: "weather", Description: "Forecast of a city", InputSchema: map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{"city": map[string]any{"type": "string"}},
+		"required":             []any{"city"},
+		"additionalProperties": false,
+	}})
+
+	data, err := json.Marshal(tool)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded map[string]any
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	schema := decoded["input_schema"].(map[string]any)
+	if schema["type"] != "object" || schema["additionalProperties"] != false {
+		t.Errorf("Unexpected input schema %s", data)
+	}
+	if required, _ := schema["required"].([]any); len(required) != 1 || required[0] != "city" {
+		t.Errorf("Expected city to be required, got %s", data)
+	}
+	if decoded["description"] != "Forecast of a city" {
+		t.Errorf("Unexpected description in %s", data)
+	}
+}
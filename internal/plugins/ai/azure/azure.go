@@ -62,6 +62,7 @@ func (oi *Client) configure() error {
 		option.WithBaseURL(endpoint),
 		option.WithQueryAdd("api-version", apiVersion),
 		option.WithMiddleware(azurecommon.AzureDeploymentMiddleware),
+		option.WithHTTPClient(oi.HTTPClient(0)),
 	)
 	oi.ApiClient = &client
 	return nil
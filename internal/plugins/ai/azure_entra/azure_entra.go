@@ -67,6 +67,7 @@ func (c *Client) configure() error {
 		option.WithBaseURL(endpoint),
 		option.WithQueryAdd("api-version", apiVersion),
 		option.WithMiddleware(azurecommon.AzureDeploymentMiddleware),
+		option.WithHTTPClient(c.HTTPClient(0)),
 	)
 	c.ApiClient = &client
 	return nil
@@ -110,7 +110,7 @@ func (c *Client) configure() error {
 		c.BackendType.Value = backendType
 	}
 
-	c.httpClient = &http.Client{Timeout: gatewayTimeout}
+	c.httpClient = c.HTTPClient(gatewayTimeout)
 
 	switch backendType {
 	case "bedrock":
@@ -88,6 +88,9 @@ func (b *AzureOpenAIBackend) PrepareRequest(msgs []*chat.ChatCompletionMessage,
 	if opts.Temperature != domain.DefaultTemperature {
 		body["temperature"] = opts.Temperature
 	}
+	if opts.MaxTokens > 0 {
+		body["max_tokens"] = opts.MaxTokens
+	}
 
 	return json.Marshal(body)
 }
@@ -102,6 +102,9 @@ func (b *VertexAIBackend) PrepareRequest(msgs []*chat.ChatCompletionMessage, opt
 	if opts.Temperature != domain.DefaultTemperature {
 		generationConfig["temperature"] = opts.Temperature
 	}
+	if opts.MaxTokens > 0 {
+		generationConfig["maxOutputTokens"] = opts.MaxTokens
+	}
 	if len(generationConfig) > 0 {
 		body["generationConfig"] = generationConfig
 	}
@@ -403,7 +403,7 @@ func (c *BedrockClient) configure() error {
 			config.WithHTTPClient(&http.Client{
 				Transport: &bearerTokenTransport{
 					token:   c.bedrockAPIKey.Value,
-					wrapped: http.DefaultTransport,
+					wrapped: c.HTTPTransport(nil),
 				},
 			}),
 			config.WithSharedConfigFiles([]string{}),
@@ -488,7 +488,7 @@ func (c *BedrockClient) listModelsFromAPI() ([]string, error) {
 }
 
 // SendStream sends the messages to the Bedrock ConverseStream API
-func (c *BedrockClient) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
+func (c *BedrockClient) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
 	// Ensure channel is closed on all exit paths to prevent goroutine leaks
 	defer func() {
 		if r := recover(); r != nil {
@@ -512,8 +512,11 @@ func (c *BedrockClient) SendStream(_ context.Context, msgs []*chat.ChatCompletio
 			Temperature: aws.Float32(float32(opts.Temperature)),
 		},
 	}
+	if opts.MaxTokens > 0 {
+		converseInput.InferenceConfig.MaxTokens = aws.Int32(int32(opts.MaxTokens))
+	}
 
-	response, err := c.runtimeClient.ConverseStream(context.Background(), &converseInput)
+	response, err := c.runtimeClient.ConverseStream(ctx, &converseInput)
 	if err != nil {
 		return fmt.Errorf(i18n.T("bedrock_conversestream_failed"), opts.Model, err)
 	}
@@ -576,6 +579,9 @@ func (c *BedrockClient) Send(ctx context.Context, msgs []*chat.ChatCompletionMes
 		ModelId:  aws.String(opts.Model),
 		Messages: messages,
 	}
+	if opts.MaxTokens > 0 {
+		converseInput.InferenceConfig = &types.InferenceConfiguration{MaxTokens: aws.Int32(int32(opts.MaxTokens))}
+	}
 	response, err := c.runtimeClient.Converse(ctx, &converseInput)
 	if err != nil {
 		return "", fmt.Errorf(i18n.T("bedrock_converse_failed"), opts.Model, err)
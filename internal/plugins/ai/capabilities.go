@@ -0,0 +1,201 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// ContextWindowProvider is implemented by vendors able to report the context window
+// of a model from their API.
+type ContextWindowProvider interface {
+	ContextWindow(ctx context.Context, model string) (int, error)
+}
+
+// ModelPricing is the price of a model in USD per million tokens.
+type ModelPricing struct {
+	Input  float64 `yaml:"input" json:"input"`
+	Output float64 `yaml:"output" json:"output"`
+}
+
+// Cost returns the price in USD of a request with the given token counts.
+func (o ModelPricing) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*o.Input + float64(outputTokens)*o.Output) / 1e6
+}
+
+// ModelPricingProvider is implemented by vendors able to report model prices from their API.
+type ModelPricingProvider interface {
+	ModelPricing(ctx context.Context, model string) (ModelPricing, error)
+}
+
+// defaultImageMaxDim is the longest side in pixels of the images sent to the
+// vendors missing from vendorImageMaxDims.
+const defaultImageMaxDim = 2048
+
+// vendorImageMaxDims maps the lower-case vendor names to the longest side in
+// pixels of the images they take as they are; they downscale larger ones.
+var vendorImageMaxDims = map[string]int{
+	"anthropic": 1568,
+	"bedrock":   1568,
+	"openai":    2048,
+	"azure":     2048,
+	"gemini":    3072,
+}
+
+// LookupImageMaxDim returns the longest side in pixels of the images sent to
+// the vendor, matched ignoring case.
+func LookupImageMaxDim(vendor string) int {
+	if maxDim, ok := vendorImageMaxDims[strings.ToLower(vendor)]; ok {
+		return maxDim
+	}
+	return defaultImageMaxDim
+}
+
+// ModelCapabilities describes what a model can do.
+type ModelCapabilities struct {
+	// Vision reports whether the model accepts image input.
+	Vision bool `yaml:"vision" json:"vision"`
+	// Reasoning reports whether the model is a reasoning/thinking model.
+	Reasoning bool `yaml:"reasoning" json:"reasoning"`
+	// ContextWindow is the maximum context size in tokens; 0 means unknown.
+	ContextWindow int `yaml:"contextWindow" json:"contextWindow"`
+}
+
+// modelCapabilities maps model name prefixes to their capabilities. Entries are
+// matched in order, so more specific prefixes must come before general ones.
+var modelCapabilities = []struct {
+	prefix string
+	caps   ModelCapabilities
+}{
+	// OpenAI
+	{"gpt-5", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 400000}},
+	{"gpt-4.1", ModelCapabilities{Vision: true, ContextWindow: 1047576}},
+	{"gpt-4o", ModelCapabilities{Vision: true, ContextWindow: 128000}},
+	{"gpt-4-turbo", ModelCapabilities{Vision: true, ContextWindow: 128000}},
+	{"gpt-4", ModelCapabilities{ContextWindow: 8192}},
+	{"gpt-3.5-turbo", ModelCapabilities{ContextWindow: 16385}},
+	{"gpt-oss", ModelCapabilities{Reasoning: true, ContextWindow: 131072}},
+	{"o1-mini", ModelCapabilities{Reasoning: true, ContextWindow: 128000}},
+	{"o3-mini", ModelCapabilities{Reasoning: true, ContextWindow: 200000}},
+	{"o1", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 200000}},
+	{"o3", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 200000}},
+	{"o4-mini", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 200000}},
+
+	// Anthropic
+	{"claude-opus-4", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 200000}},
+	{"claude-sonnet-4", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 200000}},
+	{"claude-haiku-4", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 200000}},
+	{"claude-3-7-sonnet", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 200000}},
+	{"claude-3", ModelCapabilities{Vision: true, ContextWindow: 200000}},
+
+	// Google
+	{"gemini-2.5", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 1048576}},
+	{"gemini-2.0", ModelCapabilities{Vision: true, ContextWindow: 1048576}},
+	{"gemini-1.5-pro", ModelCapabilities{Vision: true, ContextWindow: 2097152}},
+	{"gemini-1.5", ModelCapabilities{Vision: true, ContextWindow: 1048576}},
+	{"gemma3", ModelCapabilities{Vision: true, ContextWindow: 131072}},
+
+	// Others
+	{"grok-4", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 256000}},
+	{"grok-3", ModelCapabilities{ContextWindow: 131072}},
+	{"deepseek-reasoner", ModelCapabilities{Reasoning: true, ContextWindow: 128000}},
+	{"deepseek-r1", ModelCapabilities{Reasoning: true, ContextWindow: 128000}},
+	{"deepseek-chat", ModelCapabilities{ContextWindow: 128000}},
+	{"qwq", ModelCapabilities{Reasoning: true, ContextWindow: 131072}},
+	{"qwen3", ModelCapabilities{Reasoning: true, ContextWindow: 131072}},
+	{"llama4", ModelCapabilities{Vision: true, ContextWindow: 1048576}},
+	{"llama-4", ModelCapabilities{Vision: true, ContextWindow: 1048576}},
+	{"llama3.2-vision", ModelCapabilities{Vision: true, ContextWindow: 131072}},
+	{"llama3", ModelCapabilities{ContextWindow: 131072}},
+	{"llama-3", ModelCapabilities{ContextWindow: 131072}},
+	{"pixtral", ModelCapabilities{Vision: true, ContextWindow: 131072}},
+	{"mistral-large", ModelCapabilities{ContextWindow: 131072}},
+	{"llava", ModelCapabilities{Vision: true, ContextWindow: 4096}},
+}
+
+// modelPrices maps model name prefixes to their list prices, which change over
+// time: the modelPricing entries of the config file override them. Entries are
+// matched in order like modelCapabilities.
+var modelPrices = []struct {
+	prefix  string
+	pricing ModelPricing
+}{
+	// OpenAI
+	{"gpt-5-nano", ModelPricing{Input: 0.05, Output: 0.4}},
+	{"gpt-5-mini", ModelPricing{Input: 0.25, Output: 2}},
+	{"gpt-5", ModelPricing{Input: 1.25, Output: 10}},
+	{"gpt-4.1-nano", ModelPricing{Input: 0.1, Output: 0.4}},
+	{"gpt-4.1-mini", ModelPricing{Input: 0.4, Output: 1.6}},
+	{"gpt-4.1", ModelPricing{Input: 2, Output: 8}},
+	{"gpt-4o-mini", ModelPricing{Input: 0.15, Output: 0.6}},
+	{"gpt-4o", ModelPricing{Input: 2.5, Output: 10}},
+	{"o4-mini", ModelPricing{Input: 1.1, Output: 4.4}},
+	{"o3-mini", ModelPricing{Input: 1.1, Output: 4.4}},
+	{"o3", ModelPricing{Input: 2, Output: 8}},
+
+	// Anthropic
+	{"claude-opus-4", ModelPricing{Input: 15, Output: 75}},
+	{"claude-sonnet-4", ModelPricing{Input: 3, Output: 15}},
+	{"claude-haiku-4", ModelPricing{Input: 1, Output: 5}},
+	{"claude-3-7-sonnet", ModelPricing{Input: 3, Output: 15}},
+	{"claude-3-5-haiku", ModelPricing{Input: 0.8, Output: 4}},
+
+	// Google
+	{"gemini-2.5-pro", ModelPricing{Input: 1.25, Output: 10}},
+	{"gemini-2.5-flash-lite", ModelPricing{Input: 0.1, Output: 0.4}},
+	{"gemini-2.5-flash", ModelPricing{Input: 0.3, Output: 2.5}},
+	{"gemini-2.0-flash", ModelPricing{Input: 0.1, Output: 0.4}},
+
+	// Others
+	{"grok-4", ModelPricing{Input: 3, Output: 15}},
+	{"deepseek-chat", ModelPricing{Input: 0.27, Output: 1.1}},
+	{"deepseek-reasoner", ModelPricing{Input: 0.55, Output: 2.19}},
+}
+
+// LookupModelCapabilities returns the known capabilities of a model. Vendor prefixes
+// such as "openai/gpt-4o" (OpenRouter) or "us.anthropic.claude-..." (Bedrock) are
+// ignored when matching.
+func LookupModelCapabilities(model string) (ModelCapabilities, bool) {
+	for _, entry := range modelCapabilities {
+		if matchesModel(model, entry.prefix) {
+			return entry.caps, true
+		}
+	}
+	return ModelCapabilities{}, false
+}
+
+// LookupModelPricing returns the list price of a model, matched like
+// LookupModelCapabilities.
+func LookupModelPricing(model string) (ModelPricing, bool) {
+	for _, entry := range modelPrices {
+		if matchesModel(model, entry.prefix) {
+			return entry.pricing, true
+		}
+	}
+	return ModelPricing{}, false
+}
+
+// matchesModel reports whether the model name, without its vendor prefixes,
+// starts with the prefix
+func matchesModel(model, prefix string) bool {
+	name := strings.ToLower(model)
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	// Try the full name first, then each suffix following a dot
+	if strings.HasPrefix(name, prefix) {
+		return true
+	}
+	for i, r := range name {
+		if r == '.' && strings.HasPrefix(name[i+1:], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// EstimateTokens gives a rough token count, assuming about four characters per token.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
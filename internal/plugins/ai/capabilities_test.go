@@ -0,0 +1,59 @@
+package ai
+
+import "testing"
+
+func TestLookupModelCapabilities(t *testing.T) {
+	tests := []struct {
+		model string
+		want  ModelCapabilities
+		found bool
+	}{
+		{"gpt-4o-mini", ModelCapabilities{Vision: true, ContextWindow: 128000}, true},
+		{"GPT-4.1", ModelCapabilities{Vision: true, ContextWindow: 1047576}, true},
+		{"o3-mini", ModelCapabilities{Reasoning: true, ContextWindow: 200000}, true},
+		{"anthropic/claude-sonnet-4.5", ModelCapabilities{Vision: true, Reasoning: true, ContextWindow: 200000}, true},
+		{"us.anthropic.claude-3-5-haiku-20241022-v1:0", ModelCapabilities{Vision: true, ContextWindow: 200000}, true},
+		{"llama3.2-vision:11b", ModelCapabilities{Vision: true, ContextWindow: 131072}, true},
+		{"my-custom-model", ModelCapabilities{}, false},
+	}
+
+	for _, tt := range tests {
+		got, found := LookupModelCapabilities(tt.model)
+		if got != tt.want || found != tt.found {
+			t.Errorf("LookupModelCapabilities(%q) = %+v, %v; want %+v, %v", tt.model, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestLookupImageMaxDim(t *testing.T) {
+	for vendor, want := range map[string]int{"Anthropic": 1568, "openai": 2048, "Gemini": 3072, "Ollama": defaultImageMaxDim} {
+		if got := LookupImageMaxDim(vendor); got != want {
+			t.Errorf("LookupImageMaxDim(%q) = %d, want %d", vendor, got, want)
+		}
+	}
+}
+
+func TestLookupModelPricing(t *testing.T) {
+	tests := []struct {
+		model string
+		want  ModelPricing
+		found bool
+	}{
+		{"gpt-4o-mini-2024-07-18", ModelPricing{Input: 0.15, Output: 0.6}, true},
+		{"gpt-4o", ModelPricing{Input: 2.5, Output: 10}, true},
+		{"anthropic/claude-sonnet-4.5", ModelPricing{Input: 3, Output: 15}, true},
+		{"us.anthropic.claude-3-5-haiku-20241022-v1:0", ModelPricing{Input: 0.8, Output: 4}, true},
+		{"llama3.2", ModelPricing{}, false},
+	}
+
+	for _, tt := range tests {
+		got, found := LookupModelPricing(tt.model)
+		if got != tt.want || found != tt.found {
+			t.Errorf("LookupModelPricing(%q) = %+v, %v; want %+v, %v", tt.model, got, found, tt.want, tt.found)
+		}
+	}
+
+	if cost := (ModelPricing{Input: 2.5, Output: 10}).Cost(1000, 500); cost != 0.0075 {
+		t.Errorf("Cost() = %v, want 0.0075", cost)
+	}
+}
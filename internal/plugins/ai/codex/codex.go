@@ -144,7 +144,7 @@ func (c *Client) configure() error {
 
 	transport := &authTransport{
 		client:  c,
-		wrapped: http.DefaultTransport,
+		wrapped: c.HTTPTransport(nil),
 	}
 	c.apiHTTPClient = &http.Client{Transport: transport}
 
@@ -306,6 +306,11 @@ func sendStreamUpdate(ctx context.Context, channel chan domain.StreamUpdate, upd
 	}
 }
 
+// BuildRequest returns the Responses API request Send would make, for --dry-run
+func (c *Client) BuildRequest(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (any, error) {
+	return c.buildCodexResponseParams(msgs, opts), nil
+}
+
 func (c *Client) buildCodexResponseParams(
 	msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions,
 ) responses.ResponseNewParams {
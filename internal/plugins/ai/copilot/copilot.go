@@ -55,11 +55,7 @@ const (
 func NewClient() *Client {
 	c := &Client{}
 
-	c.PluginBase = &plugins.PluginBase{
-		Name:            vendorName,
-		EnvNamePrefix:   plugins.BuildEnvVariablePrefix(vendorName),
-		ConfigureCustom: c.configure,
-	}
+	c.PluginBase = plugins.NewVendorPluginBase(vendorName, c.configure)
 
 	// Setup questions for configuration
 	c.TenantID = c.AddSetupQuestion("Tenant ID", true)
@@ -140,9 +136,10 @@ func (c *Client) configure() error {
 	if c.token != nil {
 		tokenSource := c.oauth2Config.TokenSource(context.Background(), c.token)
 		c.httpClient = oauth2.NewClient(context.Background(), tokenSource)
+		c.httpClient.Transport = c.HTTPTransport(c.httpClient.Transport)
 	} else {
 		// No tokens available - will need device code flow or manual token
-		c.httpClient = &http.Client{Timeout: 120 * time.Second}
+		c.httpClient = c.HTTPClient(120 * time.Second)
 	}
 
 	return nil
@@ -187,11 +184,9 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 }
 
 // SendStream sends a message to Copilot and streams the response.
-func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
+func (c *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
 	defer close(channel)
 
-	ctx := context.Background()
-
 	// Create a conversation
 	conversationID, err := c.createConversation(ctx)
 	if err != nil {
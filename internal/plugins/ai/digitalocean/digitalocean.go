@@ -86,7 +86,7 @@ func (c *Client) fetchModelsFromControlPlane(ctx context.Context) ([]string, err
 
 	client := c.httpClient
 	if client == nil {
-		client = &http.Client{Timeout: 10 * time.Second}
+		client = c.HTTPClient(10 * time.Second)
 	}
 
 	resp, err := client.Do(req)
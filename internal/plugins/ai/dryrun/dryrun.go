@@ -3,142 +3,171 @@ package dryrun
 import (
 	"bytes"
 	"context"
-	"fmt"
-	"strings"
+	"encoding/json"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 )
 
-const DryRunResponse = "Dry run: Fake response sent by DryRun plugin\n"
-
 type Client struct {
 	*plugins.PluginBase
+	// Vendor is the vendor the request would be sent to. Its request is shown
+	// when it implements ai.RequestBuilder, a Chat Completions request otherwise.
+	Vendor ai.Vendor
 }
 
 func NewClient() *Client {
 	return &Client{PluginBase: &plugins.PluginBase{Name: "DryRun"}}
 }
 
+// Request is what a dry run prints: the body of the request the vendor would
+// receive and the estimated token counts of its messages
+type Request struct {
+	Vendor  string `json:"vendor,omitempty"`
+	Model   string `json:"model"`
+	Request any    `json:"request"`
+	Tokens  Tokens `json:"tokens"`
+}
+
+// Tokens are the token counts of a dry run, estimated at four characters per token
+type Tokens struct {
+	// Messages are the counts of the messages, in their order
+	Messages []int `json:"messages"`
+	Input    int   `json:"input"`
+	// ContextLength is the context window of the model when known
+	ContextLength int `json:"context_length,omitempty"`
+}
+
 func (c *Client) ListModels(_ context.Context) ([]string, error) {
 	return []string{"dry-run-model"}, nil
 }
 
-func (c *Client) formatMultiContentMessage(msg *chat.ChatCompletionMessage) string {
-	var builder strings.Builder
-
-	if len(msg.MultiContent) > 0 {
-		builder.WriteString(fmt.Sprintf("%s:\n", msg.Role))
-		for _, part := range msg.MultiContent {
-			builder.WriteString(fmt.Sprintf("  - Type: %s\n", part.Type))
-			if part.Type == chat.ChatMessagePartTypeImageURL {
-				builder.WriteString(fmt.Sprintf("    Image URL: %s\n", part.ImageURL.URL))
-			} else {
-				builder.WriteString(fmt.Sprintf("    Text: %s\n", part.Text))
-			}
+func (c *Client) constructRequest(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret *Request, err error) {
+	ret = &Request{Model: opts.Model, Tokens: Tokens{Messages: []int{}, ContextLength: opts.ModelContextLength}}
+	if builder, ok := c.Vendor.(ai.RequestBuilder); ok {
+		ret.Vendor = c.Vendor.GetName()
+		if ret.Request, err = builder.BuildRequest(ctx, msgs, opts); err != nil {
+			return
 		}
-		builder.WriteString("\n")
 	} else {
-		builder.WriteString(fmt.Sprintf("%s:\n%s\n\n", msg.Role, msg.Content))
+		if c.Vendor != nil {
+			ret.Vendor = c.Vendor.GetName()
+		}
+		ret.Request = chatCompletionRequest(msgs, opts)
 	}
-
-	return builder.String()
+	for _, msg := range msgs {
+		tokens := ai.EstimateTokens(messageText(msg))
+		ret.Tokens.Messages = append(ret.Tokens.Messages, tokens)
+		ret.Tokens.Input += tokens
+	}
+	return
 }
 
-func (c *Client) formatMessages(msgs []*chat.ChatCompletionMessage) string {
-	var builder strings.Builder
-
+// chatCompletionRequest returns the request in the format of the OpenAI Chat
+// Completions API, followed by most vendors
+func chatCompletionRequest(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) map[string]any {
+	messages := make([]map[string]any, 0, len(msgs))
 	for _, msg := range msgs {
-		switch msg.Role {
-		case chat.ChatMessageRoleSystem:
-			builder.WriteString(fmt.Sprintf("System:\n%s\n\n", msg.Content))
-		case chat.ChatMessageRoleAssistant:
-			builder.WriteString(c.formatMultiContentMessage(msg))
-		case chat.ChatMessageRoleUser:
-			builder.WriteString(c.formatMultiContentMessage(msg))
-		default:
-			builder.WriteString(fmt.Sprintf("%s:\n%s\n\n", msg.Role, msg.Content))
+		message := map[string]any{"role": msg.Role}
+		if len(msg.MultiContent) > 0 {
+			parts := make([]map[string]any, 0, len(msg.MultiContent))
+			for _, part := range msg.MultiContent {
+				if part.Type == chat.ChatMessagePartTypeImageURL && part.ImageURL != nil {
+					parts = append(parts, map[string]any{"type": part.Type, "image_url": map[string]any{"url": part.ImageURL.URL}})
+				} else {
+					parts = append(parts, map[string]any{"type": part.Type, "text": part.Text})
+				}
+			}
+			message["content"] = parts
+		} else {
+			message["content"] = msg.Content
 		}
+		messages = append(messages, message)
 	}
 
-	return builder.String()
-}
-
-func (c *Client) formatOptions(opts *domain.ChatOptions) string {
-	var builder strings.Builder
-
-	builder.WriteString("Options:\n")
-	builder.WriteString(fmt.Sprintf("Model: %s\n", opts.Model))
-	builder.WriteString(fmt.Sprintf("Temperature: %f\n", opts.Temperature))
-	builder.WriteString(fmt.Sprintf("TopP: %f\n", opts.TopP))
-	builder.WriteString(fmt.Sprintf("PresencePenalty: %f\n", opts.PresencePenalty))
-	builder.WriteString(fmt.Sprintf("FrequencyPenalty: %f\n", opts.FrequencyPenalty))
-	if opts.ModelContextLength != 0 {
-		builder.WriteString(fmt.Sprintf("ModelContextLength: %d\n", opts.ModelContextLength))
+	ret := map[string]any{"model": opts.Model, "messages": messages}
+	if !opts.Raw {
+		ret["temperature"] = opts.Temperature
+		setNonZero(ret, "top_p", opts.TopP)
+		setNonZero(ret, "presence_penalty", opts.PresencePenalty)
+		setNonZero(ret, "frequency_penalty", opts.FrequencyPenalty)
+		setNonZero(ret, "max_tokens", opts.MaxTokens)
+		setNonZero(ret, "seed", opts.Seed)
+	}
+	if opts.Thinking != "" {
+		ret["reasoning_effort"] = opts.Thinking
 	}
 	if opts.Search {
-		builder.WriteString("Search: enabled\n")
+		search := map[string]any{}
 		if opts.SearchLocation != "" {
-			builder.WriteString(fmt.Sprintf("SearchLocation: %s\n", opts.SearchLocation))
+			search["user_location"] = map[string]any{"type": "approximate", "approximate": map[string]any{"timezone": opts.SearchLocation}}
 		}
+		ret["web_search_options"] = search
 	}
-	if opts.ImageFile != "" {
-		builder.WriteString(fmt.Sprintf("ImageFile: %s\n", opts.ImageFile))
+	return ret
+}
+
+func setNonZero[T int | float64](request map[string]any, key string, value T) {
+	if value != 0 {
+		request[key] = value
 	}
-	if opts.Thinking != "" {
-		builder.WriteString(fmt.Sprintf("Thinking: %s\n", string(opts.Thinking)))
+}
+
+func messageText(msg *chat.ChatCompletionMessage) string {
+	if len(msg.MultiContent) == 0 {
+		return msg.Content
 	}
-	if opts.SuppressThink {
-		builder.WriteString("SuppressThink: enabled\n")
-		builder.WriteString(fmt.Sprintf("Thinking Start Tag: %s\n", opts.ThinkStartTag))
-		builder.WriteString(fmt.Sprintf("Thinking End Tag: %s\n", opts.ThinkEndTag))
+	var text bytes.Buffer
+	for _, part := range msg.MultiContent {
+		text.WriteString(part.Text)
 	}
-
-	return builder.String()
+	return text.String()
 }
 
-func (c *Client) constructRequest(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) string {
-	var builder strings.Builder
-	builder.WriteString("Dry run: Would send the following request:\n\n")
-	builder.WriteString(c.formatMessages(msgs))
-	builder.WriteString(c.formatOptions(opts))
-
-	return builder.String()
+// render returns the request of the messages as indented JSON
+func (c *Client) render(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, tokens int, err error) {
+	var request *Request
+	if request, err = c.constructRequest(ctx, msgs, opts); err != nil {
+		return
+	}
+	var data []byte
+	if data, err = json.MarshalIndent(request, "", "  "); err != nil {
+		return
+	}
+	return string(data), request.Tokens.Input, nil
 }
 
-func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
+func (c *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
 	defer close(channel)
-	request := c.constructRequest(msgs, opts)
-	channel <- domain.StreamUpdate{
-		Type:    domain.StreamTypeContent,
-		Content: request,
-	}
-	channel <- domain.StreamUpdate{
-		Type:    domain.StreamTypeContent,
-		Content: "\n",
+	request, tokens, err := c.render(ctx, msgs, opts)
+	if err != nil {
+		return err
 	}
 	channel <- domain.StreamUpdate{
 		Type:    domain.StreamTypeContent,
-		Content: DryRunResponse,
+		Content: request,
 	}
-	// Simulated usage
 	channel <- domain.StreamUpdate{
 		Type: domain.StreamTypeUsage,
 		Usage: &domain.UsageMetadata{
-			InputTokens:  100,
-			OutputTokens: 50,
-			TotalTokens:  150,
+			InputTokens: tokens,
+			TotalTokens: tokens,
 		},
 	}
 	return nil
 }
 
-func (c *Client) Send(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
-	request := c.constructRequest(msgs, opts)
+func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+	request, _, err := c.render(ctx, msgs, opts)
+	return request, err
+}
 
-	return request + "\n" + DryRunResponse, nil
+// NeedsRawMode follows the vendor, so that the request is built as it would be
+func (c *Client) NeedsRawMode(modelName string) bool {
+	return c.Vendor != nil && c.Vendor.NeedsRawMode(modelName)
 }
 
 func (c *Client) Setup() error {
@@ -2,11 +2,13 @@ package dryrun
 
 import (
 	"context"
+	"encoding/json"
 	"reflect"
 	"testing"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins"
 )
 
 // Test generated using Keploy
@@ -55,3 +57,74 @@ func TestSendStream_SendsMessages(t *testing.T) {
 		t.Errorf("Expected to receive messages, but got none")
 	}
 }
+
+func TestSend_RendersChatCompletionRequest(t *testing.T) {
+	client := NewClient()
+	msgs := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "You summarize."},
+		{Role: chat.ChatMessageRoleUser, MultiContent: []chat.ChatMessagePart{
+			{Type: chat.ChatMessagePartTypeText, Text: "What is this?"},
+			{Type: chat.ChatMessagePartTypeImageURL, ImageURL: &chat.ChatMessageImageURL{URL: "https://example.com/a.png"}},
+		}},
+	}
+	opts := &domain.ChatOptions{Model: "gpt-4o", Temperature: 0.7, Seed: 42, ModelContextLength: 8192}
+
+	output, err := client.Send(context.Background(), msgs, opts)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	var got struct {
+		Model   string `json:"model"`
+		Request struct {
+			Model    string           `json:"model"`
+			Messages []map[string]any `json:"messages"`
+			Seed     int              `json:"seed"`
+			TopP     *float64         `json:"top_p"`
+		} `json:"request"`
+		Tokens Tokens `json:"tokens"`
+	}
+	if err = json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, output)
+	}
+	if got.Model != "gpt-4o" || got.Request.Model != "gpt-4o" || got.Request.Seed != 42 || got.Request.TopP != nil {
+		t.Errorf("unexpected request: %s", output)
+	}
+	if len(got.Request.Messages) != 2 || got.Request.Messages[0]["content"] != "You summarize." {
+		t.Fatalf("unexpected messages: %s", output)
+	}
+	parts, ok := got.Request.Messages[1]["content"].([]any)
+	if !ok || len(parts) != 2 {
+		t.Fatalf("expected the parts of the user message, got %v", got.Request.Messages[1]["content"])
+	}
+	want := Tokens{Messages: []int{4, 4}, Input: 8, ContextLength: 8192}
+	if !reflect.DeepEqual(got.Tokens, want) {
+		t.Errorf("tokens = %+v, want %+v", got.Tokens, want)
+	}
+}
+
+type builderVendor struct {
+	*Client
+}
+
+func (o *builderVendor) BuildRequest(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (any, error) {
+	return map[string]any{"system": msgs[0].Content, "max_tokens": opts.MaxTokens}, nil
+}
+
+func TestSend_UsesVendorRequest(t *testing.T) {
+	client := NewClient()
+	client.Vendor = &builderVendor{&Client{PluginBase: &plugins.PluginBase{Name: "Builder"}}}
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleSystem, Content: "Be brief."}}
+
+	output, err := client.Send(context.Background(), msgs, &domain.ChatOptions{Model: "m", MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	var got Request
+	if err = json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	want := map[string]any{"system": "Be brief.", "max_tokens": float64(100)}
+	if got.Vendor != "Builder" || !reflect.DeepEqual(got.Request, want) {
+		t.Errorf("got vendor %q and request %v, want the request of the vendor", got.Vendor, got.Request)
+	}
+}
@@ -0,0 +1,18 @@
+package ai
+
+import "context"
+
+// Embedder is implemented by vendors able to compute text embeddings. The
+// returned vectors are in the order of the inputs.
+type Embedder interface {
+	Embeddings(ctx context.Context, inputs []string, model string) ([][]float64, error)
+}
+
+// Float64s converts an embedding returned as float32 values.
+func Float64s(values []float32) []float64 {
+	ret := make([]float64, len(values))
+	for i, value := range values {
+		ret[i] = float64(value)
+	}
+	return ret
+}
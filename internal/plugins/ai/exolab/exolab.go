@@ -34,7 +34,7 @@ type Client struct {
 func (oi *Client) configure() (err error) {
 	oi.apiModels = strings.Split(oi.ApiModels.Value, ",")
 
-	opts := []option.RequestOption{option.WithAPIKey(oi.ApiKey.Value)}
+	opts := []option.RequestOption{option.WithAPIKey(oi.ApiKey.Value), option.WithHTTPClient(oi.HTTPClient(0))}
 	if oi.ApiBaseURL.Value != "" {
 		opts = append(opts, option.WithBaseURL(oi.ApiBaseURL.Value))
 	}
@@ -0,0 +1,298 @@
+// Package fastinference provides clients for the Groq, Cerebras and SambaNova
+// fast-inference APIs. They are OpenAI-compatible, but each has its own model
+// listing format, rate-limit headers and unsupported parameters.
+package fastinference
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
+	openaiapi "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+const errorResponseLimit = 1024
+
+// Provider describes a fast-inference API.
+type Provider struct {
+	Name    string
+	BaseURL string
+	// ExcludedModelPrefixes filters models that cannot be used for chat (e.g. speech models).
+	ExcludedModelPrefixes []string
+	// Unsupported lists chat parameters rejected or ignored by the API, which are
+	// cleared before sending.
+	Unsupported []Param
+}
+
+// Param is a chat parameter a provider may not support.
+type Param string
+
+const (
+	ParamPresencePenalty  Param = "presence_penalty"
+	ParamFrequencyPenalty Param = "frequency_penalty"
+	ParamSeed             Param = "seed"
+)
+
+// Client is an OpenAI-compatible client tuned for a fast-inference provider.
+type Client struct {
+	*openai.Client
+	provider   Provider
+	httpClient *http.Client
+
+	mu             sync.Mutex
+	contextWindows map[string]int
+	rateLimit      RateLimit
+}
+
+// RateLimit is the rate-limit state reported by the headers of the last response.
+type RateLimit struct {
+	RemainingRequests int
+	RemainingTokens   int
+	// Reset is the time until the token limit resets.
+	Reset time.Duration
+}
+
+// NewClient creates a client for a fast-inference provider.
+func NewClient(provider Provider) (ret *Client) {
+	ret = &Client{provider: provider}
+	ret.Client = openai.NewClientCompatibleNoSetupQuestions(provider.Name, ret.configure)
+	ret.ApiKey = ret.AddSetupQuestion("API Key", true)
+	ret.ApiBaseURL = ret.AddSetupQuestion("API Base URL", false)
+	ret.ApiBaseURL.Value = provider.BaseURL
+	ret.ImplementsResponses = false
+	return
+}
+
+func (o *Client) configure() (err error) {
+	opts := []option.RequestOption{
+		option.WithAPIKey(o.ApiKey.Value),
+		option.WithHTTPClient(o.HTTPClient(0)),
+		option.WithMiddleware(o.trackRateLimit),
+	}
+	if o.ApiBaseURL.Value != "" {
+		opts = append(opts, option.WithBaseURL(o.ApiBaseURL.Value))
+	}
+	client := openaiapi.NewClient(opts...)
+	o.ApiClient = &client
+	o.httpClient = o.HTTPClient(10 * time.Second)
+	return
+}
+
+// trackRateLimit records the rate-limit headers of each response and reports
+// when the limit is hit; the SDK then retries after the advertised delay.
+func (o *Client) trackRateLimit(req *http.Request, next option.MiddlewareNext) (resp *http.Response, err error) {
+	if resp, err = next(req); err != nil {
+		return
+	}
+
+	limit, ok := parseRateLimit(resp.Header)
+	if ok {
+		o.mu.Lock()
+		o.rateLimit = limit
+		o.mu.Unlock()
+		debuglog.Debug(debuglog.Detailed, "%s rate limit: %d requests and %d tokens remaining, reset in %s\n",
+			o.GetName(), limit.RemainingRequests, limit.RemainingTokens, limit.Reset)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := resp.Header.Get("retry-after")
+		if retryAfter == "" {
+			retryAfter = "?"
+		}
+		debuglog.Log(i18n.T("fastinference_rate_limited"), o.GetName(), retryAfter)
+	}
+	return
+}
+
+// RateLimit returns the rate-limit state reported by the last response.
+func (o *Client) RateLimit() RateLimit {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.rateLimit
+}
+
+// parseRateLimit reads the x-ratelimit-* headers shared by Groq, Cerebras and SambaNova.
+// Cerebras reports per-day request and per-minute token limits under its own names.
+func parseRateLimit(header http.Header) (ret RateLimit, ok bool) {
+	requests := firstHeader(header, "x-ratelimit-remaining-requests", "x-ratelimit-remaining-requests-day")
+	tokens := firstHeader(header, "x-ratelimit-remaining-tokens", "x-ratelimit-remaining-tokens-minute")
+	if requests == "" && tokens == "" {
+		return
+	}
+	ret.RemainingRequests, _ = strconv.Atoi(requests)
+	ret.RemainingTokens, _ = strconv.Atoi(tokens)
+	ret.Reset = parseReset(firstHeader(header, "x-ratelimit-reset-tokens", "x-ratelimit-reset-tokens-minute"))
+	return ret, true
+}
+
+func firstHeader(header http.Header, names ...string) string {
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseReset parses a reset delay given either as a duration ("7.66s") or in seconds ("60.5").
+func parseReset(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return 0
+}
+
+// modelsResponse is the /models payload; context_window is set by Groq and
+// context_length by SambaNova.
+type modelsResponse struct {
+	Data []struct {
+		ID            string `json:"id"`
+		Active        *bool  `json:"active"`
+		ContextWindow int    `json:"context_window"`
+		ContextLength int    `json:"context_length"`
+	} `json:"data"`
+}
+
+// ListModels returns the active chat models of the provider.
+func (o *Client) ListModels(ctx context.Context) (ret []string, err error) {
+	var payload *modelsResponse
+	if payload, err = o.fetchModels(ctx); err != nil {
+		return
+	}
+
+	windows := make(map[string]int, len(payload.Data))
+	for _, model := range payload.Data {
+		if model.ID == "" || (model.Active != nil && !*model.Active) || o.excluded(model.ID) {
+			continue
+		}
+		ret = append(ret, model.ID)
+		if window := max(model.ContextWindow, model.ContextLength); window > 0 {
+			windows[model.ID] = window
+		}
+	}
+
+	o.mu.Lock()
+	o.contextWindows = windows
+	o.mu.Unlock()
+	return
+}
+
+// ContextWindow returns the context window reported by the model listing.
+func (o *Client) ContextWindow(ctx context.Context, model string) (ret int, err error) {
+	o.mu.Lock()
+	loaded := o.contextWindows != nil
+	o.mu.Unlock()
+	if !loaded {
+		if _, err = o.ListModels(ctx); err != nil {
+			return
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.contextWindows[model], nil
+}
+
+func (o *Client) fetchModels(ctx context.Context) (ret *modelsResponse, err error) {
+	var modelsURL string
+	if modelsURL, err = url.JoinPath(o.ApiBaseURL.Value, "models"); err != nil {
+		return nil, fmt.Errorf(i18n.T("openai_failed_to_create_models_url"), err)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+o.ApiKey.Value)
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = o.HTTPClient(10 * time.Second)
+	}
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, errorResponseLimit))
+		return nil, fmt.Errorf(i18n.T("fastinference_models_request_failed"), o.GetName(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	ret = &modelsResponse{}
+	err = json.NewDecoder(resp.Body).Decode(ret)
+	return
+}
+
+func (o *Client) excluded(model string) bool {
+	lower := strings.ToLower(model)
+	for _, prefix := range o.provider.ExcludedModelPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Send clears the parameters unsupported by the provider before sending.
+func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+	return o.Client.Send(ctx, msgs, o.supportedOptions(opts))
+}
+
+// SendStream clears the parameters unsupported by the provider before streaming.
+func (o *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
+	return o.Client.SendStream(ctx, msgs, o.supportedOptions(opts), channel)
+}
+
+// BuildRequest returns the request Send would make, without the parameters
+// unsupported by the provider, for --dry-run
+func (o *Client) BuildRequest(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (any, error) {
+	return o.Client.BuildRequest(ctx, msgs, o.supportedOptions(opts))
+}
+
+func (o *Client) supportedOptions(opts *domain.ChatOptions) *domain.ChatOptions {
+	if len(o.provider.Unsupported) == 0 {
+		return opts
+	}
+
+	ret := *opts
+	for _, param := range o.provider.Unsupported {
+		var set bool
+		switch param {
+		case ParamPresencePenalty:
+			set, ret.PresencePenalty = ret.PresencePenalty != 0, 0
+		case ParamFrequencyPenalty:
+			set, ret.FrequencyPenalty = ret.FrequencyPenalty != 0, 0
+		case ParamSeed:
+			set, ret.Seed = ret.Seed != 0, 0
+		}
+		if set {
+			debuglog.Debug(debuglog.Basic, "%s does not support %s, ignoring it\n", o.GetName(), param)
+		}
+	}
+	return &ret
+}
+
+func (o *Client) NeedsRawMode(modelName string) bool {
+	return false
+}
@@ -0,0 +1,76 @@
+package fastinference
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"object":"list","data":[
+			{"id":"llama-3.3-70b-versatile","active":true,"context_window":131072},
+			{"id":"whisper-large-v3","active":true,"context_window":448},
+			{"id":"retired-model","active":false,"context_window":8192},
+			{"id":"Meta-Llama-3.1-8B-Instruct","context_length":16384}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Groq)
+	client.ApiKey.Value = "test-key"
+	client.ApiBaseURL.Value = server.URL
+	require.NoError(t, client.configure())
+
+	models, err := client.ListModels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"llama-3.3-70b-versatile", "Meta-Llama-3.1-8B-Instruct"}, models)
+
+	window, err := client.ContextWindow(context.Background(), "Meta-Llama-3.1-8B-Instruct")
+	require.NoError(t, err)
+	assert.Equal(t, 16384, window)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-ratelimit-remaining-requests", "14370")
+	header.Set("x-ratelimit-remaining-tokens", "5997")
+	header.Set("x-ratelimit-reset-tokens", "7.66s")
+
+	limit, ok := parseRateLimit(header)
+	require.True(t, ok)
+	assert.Equal(t, RateLimit{RemainingRequests: 14370, RemainingTokens: 5997, Reset: 7660 * time.Millisecond}, limit)
+
+	// Cerebras names its limits by period and gives resets in seconds
+	header = http.Header{}
+	header.Set("x-ratelimit-remaining-requests-day", "14399")
+	header.Set("x-ratelimit-remaining-tokens-minute", "59000")
+	header.Set("x-ratelimit-reset-tokens-minute", "30.5")
+
+	limit, ok = parseRateLimit(header)
+	require.True(t, ok)
+	assert.Equal(t, RateLimit{RemainingRequests: 14399, RemainingTokens: 59000, Reset: 30500 * time.Millisecond}, limit)
+
+	_, ok = parseRateLimit(http.Header{})
+	assert.False(t, ok)
+}
+
+func TestSupportedOptions(t *testing.T) {
+	opts := &domain.ChatOptions{Model: "llama3.1-8b", PresencePenalty: 0.5, FrequencyPenalty: 0.3, Seed: 42}
+
+	cleared := NewClient(Cerebras).supportedOptions(opts)
+	assert.Zero(t, cleared.PresencePenalty)
+	assert.Zero(t, cleared.FrequencyPenalty)
+	assert.Equal(t, 42, cleared.Seed)
+	assert.Equal(t, 0.5, opts.PresencePenalty, "the caller's options must not change")
+
+	assert.Same(t, opts, NewClient(Groq).supportedOptions(opts))
+}
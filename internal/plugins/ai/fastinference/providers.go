@@ -0,0 +1,37 @@
+package fastinference
+
+// Groq serves open models on its LPU hardware. Its model listing also includes
+// speech-to-text, text-to-speech and safety models which cannot be chatted with.
+var Groq = Provider{
+	Name:                  "Groq",
+	BaseURL:               "https://api.groq.com/openai/v1",
+	ExcludedModelPrefixes: []string{"whisper-", "distil-whisper", "playai-tts"},
+}
+
+// Cerebras serves open models on wafer-scale hardware; its API rejects the
+// presence and frequency penalties.
+var Cerebras = Provider{
+	Name:        "Cerebras",
+	BaseURL:     "https://api.cerebras.ai/v1",
+	Unsupported: []Param{ParamPresencePenalty, ParamFrequencyPenalty},
+}
+
+// SambaNova serves open models on its RDU hardware; penalties and seed are not supported.
+var SambaNova = Provider{
+	Name:                  "SambaNova",
+	BaseURL:               "https://api.sambanova.ai/v1",
+	ExcludedModelPrefixes: []string{"whisper-"},
+	Unsupported:           []Param{ParamPresencePenalty, ParamFrequencyPenalty, ParamSeed},
+}
+
+func NewGroqClient() *Client {
+	return NewClient(Groq)
+}
+
+func NewCerebrasClient() *Client {
+	return NewClient(Cerebras)
+}
+
+func NewSambaNovaClient() *Client {
+	return NewClient(SambaNova)
+}
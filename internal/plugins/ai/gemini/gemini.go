@@ -15,6 +15,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/geminicommon"
 	"google.golang.org/genai"
 )
@@ -65,8 +66,9 @@ func (o *Client) ListModels(_ context.Context) (ret []string, err error) {
 	ctx := context.Background()
 	var client *genai.Client
 	if client, err = genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  o.ApiKey.Value,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     o.ApiKey.Value,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: o.HTTPClient(0),
 	}); err != nil {
 		return
 	}
@@ -85,6 +87,46 @@ func (o *Client) ListModels(_ context.Context) (ret []string, err error) {
 	return
 }
 
+// ContextWindow returns the input token limit of the model reported by the Gemini API.
+func (o *Client) ContextWindow(ctx context.Context, model string) (ret int, err error) {
+	var client *genai.Client
+	if client, err = o.createGenaiClient(ctx); err != nil {
+		return
+	}
+
+	var info *genai.Model
+	if info, err = client.Models.Get(ctx, o.buildModelNameFull(model), nil); err != nil {
+		return
+	}
+	ret = int(info.InputTokenLimit)
+	return
+}
+
+// Embeddings returns the embeddings of the inputs computed by a Gemini embedding model.
+func (o *Client) Embeddings(ctx context.Context, inputs []string, model string) (ret [][]float64, err error) {
+	var client *genai.Client
+	if client, err = o.createGenaiClient(ctx); err != nil {
+		return
+	}
+
+	contents := make([]*genai.Content, len(inputs))
+	for i, input := range inputs {
+		contents[i] = genai.NewContentFromText(input, genai.RoleUser)
+	}
+
+	var resp *genai.EmbedContentResponse
+	if resp, err = client.Models.EmbedContent(ctx, model, contents, nil); err != nil {
+		return
+	}
+	if len(resp.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf(i18n.T("embeddings_count_mismatch"), len(resp.Embeddings), len(inputs))
+	}
+	for _, embedding := range resp.Embeddings {
+		ret = append(ret, ai.Float64s(embedding.Values))
+	}
+	return
+}
+
 func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
 	// Check if this is a TTS model request
 	if o.isTTSModel(opts.Model) {
@@ -100,14 +142,18 @@ func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	// Regular text generation
 	var client *genai.Client
 	if client, err = genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  o.ApiKey.Value,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     o.ApiKey.Value,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: o.HTTPClient(0),
 	}); err != nil {
 		return
 	}
 
 	// Convert messages to new SDK format
-	contents := geminicommon.ConvertMessages(msgs)
+	var contents []*genai.Content
+	if contents, err = geminicommon.ConvertMessages(ctx, o.HTTPClient(0), msgs); err != nil {
+		return
+	}
 
 	cfg, err := o.buildGenerateContentConfig(opts)
 	if err != nil {
@@ -125,20 +171,23 @@ func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	return
 }
 
-func (o *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
-	ctx := context.Background()
+func (o *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
 	defer close(channel)
 
 	var client *genai.Client
 	if client, err = genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  o.ApiKey.Value,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     o.ApiKey.Value,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: o.HTTPClient(0),
 	}); err != nil {
 		return
 	}
 
 	// Convert messages to new SDK format
-	contents := geminicommon.ConvertMessages(msgs)
+	var contents []*genai.Content
+	if contents, err = geminicommon.ConvertMessages(ctx, o.HTTPClient(0), msgs); err != nil {
+		return
+	}
 
 	cfg, err := o.buildGenerateContentConfig(opts)
 	if err != nil {
@@ -157,6 +206,13 @@ func (o *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 			return err
 		}
 
+		if thoughts := geminicommon.ExtractThoughts(response); thoughts != "" {
+			channel <- domain.StreamUpdate{
+				Type:    domain.StreamTypeReasoning,
+				Content: thoughts,
+			}
+		}
+
 		text := geminicommon.ExtractTextWithCitations(response)
 		if text != "" {
 			channel <- domain.StreamUpdate{
@@ -180,6 +236,37 @@ func (o *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 	return
 }
 
+// BuildRequest returns the generateContent request Send would make, for
+// --dry-run. The SDK sends the tools and system instruction of the config at the
+// top of the request and the rest as its generationConfig.
+func (o *Client) BuildRequest(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret any, err error) {
+	var contents []*genai.Content
+	if contents, err = geminicommon.ConvertMessages(ctx, o.HTTPClient(0), msgs); err != nil {
+		return
+	}
+	var cfg *genai.GenerateContentConfig
+	if cfg, err = o.buildGenerateContentConfig(opts); err != nil {
+		return
+	}
+	request := map[string]any{
+		"model":    o.buildModelNameFull(opts.Model),
+		"contents": contents,
+		"generationConfig": &genai.GenerationConfig{
+			Temperature:     cfg.Temperature,
+			TopP:            cfg.TopP,
+			MaxOutputTokens: cfg.MaxOutputTokens,
+			ThinkingConfig:  cfg.ThinkingConfig,
+		},
+	}
+	if cfg.Tools != nil {
+		request["tools"] = cfg.Tools
+	}
+	if cfg.ToolConfig != nil {
+		request["toolConfig"] = cfg.ToolConfig
+	}
+	return request, nil
+}
+
 func parseThinkingConfig(level domain.ThinkingLevel) (*genai.ThinkingConfig, bool) {
 	lower := strings.ToLower(strings.TrimSpace(string(level)))
 	switch domain.ThinkingLevel(lower) {
@@ -219,6 +306,10 @@ func (o *Client) buildGenerateContentConfig(opts *domain.ChatOptions) (*genai.Ge
 		TopP:            &topP,
 		MaxOutputTokens: maxTokens,
 	}
+	if opts.Seed != 0 {
+		seed := int32(opts.Seed)
+		cfg.Seed = &seed
+	}
 
 	if opts.Search {
 		cfg.Tools = []*genai.Tool{{GoogleSearch: &genai.GoogleSearch{}}}
@@ -302,8 +393,9 @@ func (o *Client) extractTextForTTS(msgs []*chat.ChatCompletionMessage) (string,
 // createGenaiClient creates a new GenAI client for TTS operations
 func (o *Client) createGenaiClient(ctx context.Context) (*genai.Client, error) {
 	return genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  o.ApiKey.Value,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     o.ApiKey.Value,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: o.HTTPClient(0),
 	})
 }
 
@@ -328,6 +420,24 @@ func (o *Client) generateTTSAudio(ctx context.Context, msgs []*chat.ChatCompleti
 	return o.performTTSGeneration(ctx, client, textToSpeak, opts)
 }
 
+// SynthesizeSpeech converts text to speech with a Gemini TTS model and returns WAV data.
+func (o *Client) SynthesizeSpeech(ctx context.Context, text, model, voice string) ([]byte, error) {
+	if voice != "" && !IsValidGeminiVoice(voice) {
+		return nil, fmt.Errorf(i18n.T("gemini_invalid_voice"), voice, GetGeminiVoiceNames())
+	}
+
+	client, err := o.createGenaiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	audio, err := o.performTTSGeneration(ctx, client, text, &domain.ChatOptions{Model: model, Voice: voice})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimPrefix(audio, AudioDataPrefix)), nil
+}
+
 // performTTSGeneration performs the actual TTS generation and audio processing
 func (o *Client) performTTSGeneration(ctx context.Context, client *genai.Client, textToSpeak string, opts *domain.ChatOptions) (string, error) {
 
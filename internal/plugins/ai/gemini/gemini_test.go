@@ -1,6 +1,9 @@
 package gemini
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -126,6 +129,22 @@ func TestBuildGenerateContentConfig_LanguageCodeNormalization(t *testing.T) {
 	}
 }
 
+func TestBuildGenerateContentConfig_Seed(t *testing.T) {
+	client := &Client{}
+
+	cfg, err := client.buildGenerateContentConfig(&domain.ChatOptions{Seed: 42})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Seed == nil || *cfg.Seed != 42 {
+		t.Errorf("expected seed 42, got %v", cfg.Seed)
+	}
+
+	if cfg, _ = client.buildGenerateContentConfig(&domain.ChatOptions{}); cfg.Seed != nil {
+		t.Errorf("expected no seed, got %d", *cfg.Seed)
+	}
+}
+
 func TestBuildGenerateContentConfig_Thinking(t *testing.T) {
 	client := &Client{}
 	opts := &domain.ChatOptions{Thinking: domain.ThinkingLow}
@@ -191,7 +210,10 @@ func TestConvertMessagesRoles(t *testing.T) {
 		{Role: chat.ChatMessageRoleSystem, Content: "system"},
 	}
 
-	contents := geminicommon.ConvertMessages(msgs)
+	contents, err := geminicommon.ConvertMessages(context.Background(), http.DefaultClient, msgs)
+	if err != nil {
+		t.Fatalf("ConvertMessages() error = %v", err)
+	}
 
 	expected := []string{"user", "model", "user"}
 
@@ -206,6 +228,59 @@ func TestConvertMessagesRoles(t *testing.T) {
 	}
 }
 
+// Test convertMessages sends image attachments as inline data
+func TestConvertMessagesImages(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nimage")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Gateway") != "fabric" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(png)
+	}))
+	defer server.Close()
+
+	msgs := []*chat.ChatCompletionMessage{{
+		Role: chat.ChatMessageRoleUser,
+		MultiContent: []chat.ChatMessagePart{
+			{Type: chat.ChatMessagePartTypeText, Text: "describe"},
+			{Type: chat.ChatMessagePartTypeImageURL, ImageURL: &chat.ChatMessageImageURL{URL: "data:image/jpeg;base64,aGVsbG8="}},
+			{Type: chat.ChatMessagePartTypeImageURL, ImageURL: &chat.ChatMessageImageURL{URL: server.URL + "/photo.png"}},
+		},
+	}}
+
+	// The vendor client, here adding a gateway header, downloads the images
+	client := &http.Client{Transport: gatewayTransport{}}
+	contents, err := geminicommon.ConvertMessages(context.Background(), client, msgs)
+	if err != nil {
+		t.Fatalf("ConvertMessages() error = %v", err)
+	}
+	parts := contents[0].Parts
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got %d", len(parts))
+	}
+	if parts[1].InlineData == nil || parts[1].InlineData.MIMEType != "image/jpeg" || string(parts[1].InlineData.Data) != "hello" {
+		t.Errorf("unexpected inline data for data URL: %+v", parts[1].InlineData)
+	}
+	if parts[2].InlineData == nil || parts[2].InlineData.MIMEType != "image/png" || string(parts[2].InlineData.Data) != string(png) {
+		t.Errorf("unexpected inline data for remote URL: %+v", parts[2].InlineData)
+	}
+
+	msgs[0].MultiContent[1].ImageURL.URL = "data:image/jpeg,not-base64"
+	if _, err := geminicommon.ConvertMessages(context.Background(), client, msgs); err == nil {
+		t.Error("expected error for data URL without base64 encoding")
+	}
+}
+
+type gatewayTransport struct{}
+
+func (gatewayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Gateway", "fabric")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 // Test isTTSModel method
 func TestIsTTSModel(t *testing.T) {
 	client := &Client{}
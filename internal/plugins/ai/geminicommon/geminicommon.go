@@ -3,7 +3,9 @@
 package geminicommon
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/danielmiessler/fabric/internal/chat"
@@ -19,8 +21,9 @@ const (
 
 // ConvertMessages converts fabric chat messages to genai Content format.
 // Gemini's API only accepts "user" and "model" roles, so other roles are mapped to "user".
-func ConvertMessages(msgs []*chat.ChatCompletionMessage) []*genai.Content {
-	var contents []*genai.Content
+// Image parts are sent as inline data; remote image URLs are downloaded first
+// with the HTTP client of the vendor.
+func ConvertMessages(ctx context.Context, client *http.Client, msgs []*chat.ChatCompletionMessage) (contents []*genai.Content, err error) {
 
 	for _, msg := range msgs {
 		content := &genai.Content{Parts: []*genai.Part{}}
@@ -48,19 +51,37 @@ func ConvertMessages(msgs []*chat.ChatCompletionMessage) []*genai.Content {
 			case chat.ChatMessagePartTypeText:
 				content.Parts = append(content.Parts, &genai.Part{Text: part.Text})
 			case chat.ChatMessagePartTypeImageURL:
-				// TODO: Handle image URLs if needed
-				// This would require downloading and converting to inline data
+				if part.ImageURL == nil || strings.TrimSpace(part.ImageURL.URL) == "" {
+					continue
+				}
+				var data []byte
+				var mimeType string
+				if data, mimeType, err = chat.LoadImage(ctx, client, part.ImageURL.URL); err != nil {
+					return nil, err
+				}
+				content.Parts = append(content.Parts, &genai.Part{
+					InlineData: &genai.Blob{MIMEType: mimeType, Data: data},
+				})
 			}
 		}
 
 		contents = append(contents, content)
 	}
 
-	return contents
+	return contents, nil
 }
 
-// ExtractText extracts just the text parts from a Gemini response.
+// ExtractText extracts just the text parts from a Gemini response, leaving out thoughts.
 func ExtractText(response *genai.GenerateContentResponse) string {
+	return extractParts(response, false)
+}
+
+// ExtractThoughts extracts the thought summaries included when thinking is enabled.
+func ExtractThoughts(response *genai.GenerateContentResponse) string {
+	return extractParts(response, true)
+}
+
+func extractParts(response *genai.GenerateContentResponse, thoughts bool) string {
 	if response == nil {
 		return ""
 	}
@@ -71,7 +92,7 @@ func ExtractText(response *genai.GenerateContentResponse) string {
 			continue
 		}
 		for _, part := range candidate.Content.Parts {
-			if part != nil && part.Text != "" {
+			if part != nil && part.Text != "" && part.Thought == thoughts {
 				builder.WriteString(part.Text)
 			}
 		}
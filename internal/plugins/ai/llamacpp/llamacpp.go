@@ -0,0 +1,26 @@
+// Package llamacpp provides the client for llama.cpp's server (llama-server),
+// which exposes an OpenAI-compatible API along with a /health endpoint.
+package llamacpp
+
+import (
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/plugins/ai/lmstudio"
+)
+
+const defaultBaseURL = "http://localhost:8080/v1"
+
+// NewClient creates a llama.cpp server client. The API is the one LM Studio
+// serves, so the LM Studio client is reused with llama.cpp's health endpoint.
+func NewClient() (ret *lmstudio.Client) {
+	ret = lmstudio.NewClientCompatible("LlamaCpp", defaultBaseURL, nil)
+	ret.SetupDescription = "llama.cpp server"
+	ret.HealthURL = healthURL
+	return
+}
+
+// healthURL returns the /health endpoint, which sits at the server root rather
+// than under the /v1 API prefix.
+func healthURL(apiURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(apiURL, "/"), "/v1") + "/health"
+}
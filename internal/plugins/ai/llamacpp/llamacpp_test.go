@@ -0,0 +1,34 @@
+package llamacpp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:8080/health", healthURL("http://localhost:8080/v1"))
+	assert.Equal(t, "http://localhost:8080/health", healthURL("http://localhost:8080/v1/"))
+	assert.Equal(t, "http://gpu-box:9000/health", healthURL("http://gpu-box:9000"))
+}
+
+func TestHealthCheck(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/health", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.ApiUrl.Value = server.URL + "/v1"
+
+	assert.Error(t, client.HealthCheck(context.Background()), "the model is still loading")
+
+	status = http.StatusOK
+	require.NoError(t, client.HealthCheck(context.Background()))
+}
@@ -8,8 +8,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 
@@ -18,6 +20,9 @@ import (
 	"github.com/danielmiessler/fabric/internal/plugins"
 )
 
+// detectTimeout bounds the lookup of a local server during setup.
+const detectTimeout = 2 * time.Second
+
 // NewClient creates a new LM Studio client with default configuration.
 func NewClient() (ret *Client) {
 	return NewClientCompatible("LM Studio", "http://localhost:1234/v1", nil)
@@ -34,6 +39,7 @@ func NewClientCompatible(vendorName string, defaultBaseUrl string, configureCust
 	ret.ApiUrl = ret.AddSetupQuestionCustom("API URL", true,
 		fmt.Sprintf(i18n.T("lmstudio_api_url_question"), vendorName, defaultBaseUrl))
 	ret.ApiKey = ret.AddSetupQuestion("API key", false)
+	ret.DefaultBaseURL = defaultBaseUrl
 	return
 }
 
@@ -43,14 +49,86 @@ type Client struct {
 	ApiUrl     *plugins.SetupQuestion
 	ApiKey     *plugins.SetupQuestion
 	HttpClient *http.Client
+	// DefaultBaseURL is where a locally running server is looked for during setup.
+	DefaultBaseURL string
+	// HealthURL returns the health endpoint for an API URL. When nil, the
+	// models endpoint is used to check that the server is up.
+	HealthURL func(apiURL string) string
 }
 
 // configure sets up the HTTP client.
 func (c *Client) configure() error {
-	c.HttpClient = &http.Client{}
+	c.HttpClient = c.HTTPClient(0)
 	return nil
 }
 
+// Setup detects a server running at the default URL before asking the setup
+// questions, so a local instance is offered as the default answer.
+func (c *Client) Setup() error {
+	if c.ApiUrl.Value == "" && c.DefaultBaseURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), detectTimeout)
+		defer cancel()
+		if err := c.checkHealth(ctx, c.DefaultBaseURL); err == nil {
+			fmt.Printf(i18n.T("lmstudio_server_detected"), c.GetName(), c.DefaultBaseURL)
+			c.ApiUrl.Value = c.DefaultBaseURL
+		} else {
+			fmt.Printf(i18n.T("lmstudio_server_not_detected"), c.GetName(), c.DefaultBaseURL)
+		}
+	}
+	return c.PluginBase.Setup()
+}
+
+// HealthCheck reports whether the server is up and ready to answer requests.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	return c.checkHealth(ctx, c.ApiUrl.Value)
+}
+
+func (c *Client) checkHealth(ctx context.Context, apiURL string) (err error) {
+	url := fmt.Sprintf("%s/models", apiURL)
+	if c.HealthURL != nil {
+		url = c.HealthURL(apiURL)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
+		return fmt.Errorf(i18n.T("lmstudio_failed_create_request"), err)
+	}
+	c.addAuthorizationHeader(req)
+
+	var resp *http.Response
+	if resp, err = c.httpClient().Do(req); err != nil {
+		return c.requestError(apiURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusServiceUnavailable:
+		// llama.cpp answers 503 while it is still loading the model
+		return fmt.Errorf(i18n.T("lmstudio_server_not_ready"), c.GetName(), apiURL)
+	default:
+		return fmt.Errorf(i18n.T("lmstudio_unexpected_status_code"), resp.StatusCode)
+	}
+}
+
+// requestError turns a failed connection into an error explaining that the
+// server is not running.
+func (c *Client) requestError(apiURL string, err error) error {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return fmt.Errorf(i18n.T("lmstudio_server_unreachable"), c.GetName(), apiURL)
+	}
+	return fmt.Errorf(i18n.T("lmstudio_failed_send_request"), err)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HttpClient == nil {
+		return &http.Client{}
+	}
+	return c.HttpClient
+}
+
 // ListModels returns a list of available models.
 func (c *Client) ListModels(_ context.Context) ([]string, error) {
 	url := fmt.Sprintf("%s/models", c.ApiUrl.Value)
@@ -61,9 +139,9 @@ func (c *Client) ListModels(_ context.Context) ([]string, error) {
 	}
 	c.addAuthorizationHeader(req)
 
-	resp, err := c.HttpClient.Do(req)
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
-		return nil, fmt.Errorf(i18n.T("lmstudio_failed_send_request"), err)
+		return nil, c.requestError(c.ApiUrl.Value, err)
 	}
 	defer resp.Body.Close()
 
@@ -89,7 +167,10 @@ func (c *Client) ListModels(_ context.Context) ([]string, error) {
 	return models, nil
 }
 
-func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
+func (c *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
+	// Close the channel on every path so callers are not left waiting when the server is down
+	defer close(channel)
+
 	url := fmt.Sprintf("%s/chat/completions", c.ApiUrl.Value)
 
 	payload := map[string]any{
@@ -108,7 +189,7 @@ func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 	}
 
 	var req *http.Request
-	if req, err = http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload)); err != nil {
+	if req, err = http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonPayload)); err != nil {
 		err = fmt.Errorf(i18n.T("lmstudio_failed_create_request"), err)
 		return
 	}
@@ -117,8 +198,8 @@ func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 	c.addAuthorizationHeader(req)
 
 	var resp *http.Response
-	if resp, err = c.HttpClient.Do(req); err != nil {
-		err = fmt.Errorf(i18n.T("lmstudio_failed_send_request"), err)
+	if resp, err = c.httpClient().Do(req); err != nil {
+		err = c.requestError(c.ApiUrl.Value, err)
 		return
 	}
 	defer resp.Body.Close()
@@ -128,8 +209,6 @@ func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 		return
 	}
 
-	defer close(channel)
-
 	reader := bufio.NewReader(resp.Body)
 	for {
 		var line []byte
@@ -225,8 +304,8 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	c.addAuthorizationHeader(req)
 
 	var resp *http.Response
-	if resp, err = c.HttpClient.Do(req); err != nil {
-		err = fmt.Errorf(i18n.T("lmstudio_failed_send_request"), err)
+	if resp, err = c.httpClient().Do(req); err != nil {
+		err = c.requestError(c.ApiUrl.Value, err)
 		return
 	}
 	defer resp.Body.Close()
@@ -288,8 +367,8 @@ func (c *Client) Complete(ctx context.Context, prompt string, opts *domain.ChatO
 	c.addAuthorizationHeader(req)
 
 	var resp *http.Response
-	if resp, err = c.HttpClient.Do(req); err != nil {
-		err = fmt.Errorf(i18n.T("lmstudio_failed_send_request"), err)
+	if resp, err = c.httpClient().Do(req); err != nil {
+		err = c.requestError(c.ApiUrl.Value, err)
 		return
 	}
 	defer resp.Body.Close()
@@ -345,8 +424,8 @@ func (c *Client) GetEmbeddings(ctx context.Context, input string, opts *domain.C
 	c.addAuthorizationHeader(req)
 
 	var resp *http.Response
-	if resp, err = c.HttpClient.Do(req); err != nil {
-		err = fmt.Errorf(i18n.T("lmstudio_failed_send_request"), err)
+	if resp, err = c.httpClient().Do(req); err != nil {
+		err = c.requestError(c.ApiUrl.Value, err)
 		return
 	}
 	defer resp.Body.Close()
@@ -90,3 +90,30 @@ func TestListModelsDoesNotSendBearerForWhitespaceOnlyKey(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []string{"model-1"}, models)
 }
+
+func TestServerDownErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client := NewClient()
+	client.ApiUrl.Value = url
+
+	_, err := client.ListModels(context.Background())
+	require.ErrorContains(t, err, url)
+	require.Error(t, client.HealthCheck(context.Background()))
+
+	// The stream channel must be closed even though the request failed
+	channel := make(chan domain.StreamUpdate)
+	done := make(chan struct{})
+	go func() {
+		for range channel {
+		}
+		close(done)
+	}()
+	err = client.SendStream(context.Background(), []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hi"}}, &domain.ChatOptions{Model: "m"}, channel)
+	require.ErrorContains(t, err, url)
+	<-done
+}
@@ -0,0 +1,14 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+// LogprobsSender is implemented by vendors able to return the log probability
+// of each token of the response, with opts.TopLogprobs alternatives.
+type LogprobsSender interface {
+	SendWithLogprobs(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, []domain.TokenLogprob, error)
+}
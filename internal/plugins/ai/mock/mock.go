@@ -0,0 +1,160 @@
+// Package mock provides the Mock vendor, answering without any API with a
+// canned or templated response, or with the prompt itself, so that patterns
+// and pipelines can be developed without spending tokens.
+package mock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+const (
+	vendorName = "Mock"
+	// ModelMock answers with the response template
+	ModelMock = "mock"
+	// ModelEcho answers with the prompt, the messages as they would be sent
+	ModelEcho = "echo"
+
+	defaultResponse = "This is a mock response of {{.Model}} to an input of {{len .Input}} characters.\n"
+	defaultLatency  = "30ms"
+)
+
+type Client struct {
+	*plugins.PluginBase
+	Response *plugins.SetupQuestion
+	Latency  *plugins.SetupQuestion
+
+	template *template.Template
+	latency  time.Duration
+}
+
+func NewClient() (ret *Client) {
+	ret = &Client{}
+	ret.PluginBase = plugins.NewVendorPluginBase(vendorName, ret.configure)
+	ret.SetupDescription = i18n.T("mock_setup_description")
+	ret.Response = ret.AddSetupQuestionCustom("Response", false, i18n.T("mock_response_question"))
+	ret.Latency = ret.AddSetupQuestionCustom("Latency", false, i18n.T("mock_latency_question"))
+	return
+}
+
+func (o *Client) configure() (err error) {
+	response := o.Response.Value
+	if response == "" {
+		response = defaultResponse
+	}
+	if o.template, err = template.New(vendorName).Parse(response); err != nil {
+		return fmt.Errorf(i18n.T("mock_invalid_response"), err)
+	}
+
+	latency := o.Latency.Value
+	if latency == "" {
+		latency = defaultLatency
+	}
+	if o.latency, err = time.ParseDuration(latency); err != nil || o.latency < 0 {
+		return fmt.Errorf(i18n.T("mock_invalid_latency"), latency)
+	}
+	return
+}
+
+func (o *Client) ListModels(_ context.Context) ([]string, error) {
+	return []string{ModelMock, ModelEcho}, nil
+}
+
+// Data is what the response template is executed with
+type Data struct {
+	Model string
+	// System is the system message, the pattern and its context
+	System string
+	// Input is the last user message
+	Input    string
+	Messages []*chat.ChatCompletionMessage
+	// Prompt is the text of every message, preceded by its role
+	Prompt string
+}
+
+func (o *Client) response(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+	if o.template == nil {
+		if err = o.configure(); err != nil {
+			return
+		}
+	}
+	data := Data{Model: opts.Model, Messages: msgs}
+	var prompt []string
+	for _, msg := range msgs {
+		text := messageText(msg)
+		switch msg.Role {
+		case chat.ChatMessageRoleSystem:
+			data.System = text
+		case chat.ChatMessageRoleUser:
+			data.Input = text
+		}
+		prompt = append(prompt, fmt.Sprintf("%s:\n%s", msg.Role, text))
+	}
+	data.Prompt = strings.Join(prompt, "\n\n")
+	if opts.Model == ModelEcho {
+		return data.Prompt + "\n", nil
+	}
+
+	var builder strings.Builder
+	if err = o.template.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf(i18n.T("mock_invalid_response"), err)
+	}
+	return builder.String(), nil
+}
+
+func messageText(msg *chat.ChatCompletionMessage) string {
+	if len(msg.MultiContent) == 0 {
+		return msg.Content
+	}
+	var texts []string
+	for _, part := range msg.MultiContent {
+		if part.Type == chat.ChatMessagePartTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (o *Client) Send(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+	return o.response(msgs, opts)
+}
+
+// SendStream sends the response a word at a time, waiting the latency before
+// each, as a model would
+func (o *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
+	defer close(channel)
+	var response string
+	if response, err = o.response(msgs, opts); err != nil {
+		return
+	}
+	for _, word := range strings.SplitAfter(response, " ") {
+		if o.latency > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(o.latency):
+			}
+		}
+		channel <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: word}
+	}
+
+	input := 0
+	for _, msg := range msgs {
+		input += ai.EstimateTokens(messageText(msg))
+	}
+	output := ai.EstimateTokens(response)
+	channel <- domain.StreamUpdate{
+		Type:  domain.StreamTypeUsage,
+		Usage: &domain.UsageMetadata{InputTokens: input, OutputTokens: output, TotalTokens: input + output},
+	}
+	return
+}
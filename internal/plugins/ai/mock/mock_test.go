@@ -0,0 +1,93 @@
+package mock
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+var messages = []*chat.ChatCompletionMessage{
+	{Role: chat.ChatMessageRoleSystem, Content: "Summarize the input."},
+	{Role: chat.ChatMessageRoleUser, Content: "A long text"},
+}
+
+func newClient(t *testing.T, response, latency string) *Client {
+	t.Helper()
+	client := NewClient()
+	client.Response.Value = response
+	client.Latency.Value = latency
+	if err := client.configure(); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	return client
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		model    string
+		want     string
+	}{
+		{"default", "", ModelMock, "This is a mock response of mock to an input of 11 characters.\n"},
+		{"template", "{{.System}} | {{.Input}}", ModelMock, "Summarize the input. | A long text"},
+		{"echo", "ignored", ModelEcho, "system:\nSummarize the input.\n\nuser:\nA long text\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClient(t, tt.response, "0")
+			got, err := client.Send(context.Background(), messages, &domain.ChatOptions{Model: tt.model})
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Send() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendStream(t *testing.T) {
+	client := newClient(t, "one two three", "1ms")
+	channel := make(chan domain.StreamUpdate)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- client.SendStream(context.Background(), messages, &domain.ChatOptions{Model: ModelMock}, channel)
+	}()
+
+	var words []string
+	var usage *domain.UsageMetadata
+	for update := range channel {
+		switch update.Type {
+		case domain.StreamTypeContent:
+			words = append(words, update.Content)
+		case domain.StreamTypeUsage:
+			usage = update.Usage
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("SendStream() error = %v", err)
+	}
+	if strings.Join(words, "|") != "one |two |three" {
+		t.Errorf("streamed %q, want the words one at a time", words)
+	}
+	if usage == nil || usage.InputTokens == 0 || usage.OutputTokens != 4 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+}
+
+func TestConfigureInvalid(t *testing.T) {
+	client := NewClient()
+	client.Latency.Value = "soon"
+	if err := client.configure(); err == nil {
+		t.Error("expected an error for an invalid latency")
+	}
+	client = NewClient()
+	client.Response.Value = "{{.Input"
+	if err := client.configure(); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
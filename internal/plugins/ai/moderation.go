@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"context"
+	"regexp"
+	"sort"
+)
+
+// ModerationResult is the classification of a text by a moderator.
+type ModerationResult struct {
+	// Flagged holds the categories the moderator flagged on its own thresholds
+	Flagged map[string]bool
+	// Scores holds the score, from 0 to 1, of each category
+	Scores map[string]float64
+}
+
+// Moderator is implemented by services able to classify texts as harmful. The
+// results are in the order of the inputs.
+type Moderator interface {
+	Moderate(ctx context.Context, inputs []string) ([]ModerationResult, error)
+}
+
+// FlaggedCategories returns the sorted categories of the result flagged with the
+// thresholds. Categories without a threshold keep the moderator's decision.
+func (o ModerationResult) FlaggedCategories(thresholds map[string]float64) (ret []string) {
+	categories := map[string]bool{}
+	for category, flagged := range o.Flagged {
+		if _, ok := thresholds[category]; !ok && flagged {
+			categories[category] = true
+		}
+	}
+	for category, score := range o.Scores {
+		if threshold, ok := thresholds[category]; ok && score >= threshold {
+			categories[category] = true
+		}
+	}
+	for category := range categories {
+		ret = append(ret, category)
+	}
+	sort.Strings(ret)
+	return
+}
+
+// TermModerator is a local moderator flagging the categories whose terms appear
+// in the text, as whole words and regardless of case.
+type TermModerator struct {
+	terms map[string][]*regexp.Regexp
+}
+
+// NewTermModerator creates a TermModerator from terms by category. Terms are
+// regular expressions.
+func NewTermModerator(terms map[string][]string) (ret *TermModerator, err error) {
+	ret = &TermModerator{terms: map[string][]*regexp.Regexp{}}
+	for category, expressions := range terms {
+		for _, expression := range expressions {
+			var re *regexp.Regexp
+			if re, err = regexp.Compile(`(?i)\b(?:` + expression + `)\b`); err != nil {
+				return nil, err
+			}
+			ret.terms[category] = append(ret.terms[category], re)
+		}
+	}
+	return
+}
+
+func (o *TermModerator) Moderate(_ context.Context, inputs []string) (ret []ModerationResult, err error) {
+	for _, input := range inputs {
+		result := ModerationResult{Flagged: map[string]bool{}, Scores: map[string]float64{}}
+		for category, expressions := range o.terms {
+			result.Scores[category] = 0
+			for _, re := range expressions {
+				if re.MatchString(input) {
+					result.Flagged[category] = true
+					result.Scores[category] = 1
+					break
+				}
+			}
+		}
+		ret = append(ret, result)
+	}
+	return
+}
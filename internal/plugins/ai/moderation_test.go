@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationResult_FlaggedCategories(t *testing.T) {
+	result := ModerationResult{
+		Flagged: map[string]bool{"violence": true, "hate": true, "harassment": false},
+		Scores:  map[string]float64{"violence": 0.6, "hate": 0.9, "harassment": 0.4},
+	}
+
+	assert.Equal(t, []string{"hate", "violence"}, result.FlaggedCategories(nil))
+	assert.Equal(t, []string{"harassment", "hate"}, result.FlaggedCategories(map[string]float64{"violence": 0.8, "harassment": 0.3}))
+}
+
+func TestTermModerator(t *testing.T) {
+	moderator, err := NewTermModerator(map[string][]string{
+		"secrets":      {"password", "api[ _-]?key"},
+		"confidential": {"internal only"},
+	})
+	require.NoError(t, err)
+
+	results, err := moderator.Moderate(context.Background(), []string{"Here is my API key", "passwords are fine", "Internal only!"})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, []string{"secrets"}, results[0].FlaggedCategories(nil))
+	assert.Empty(t, results[1].FlaggedCategories(nil))
+	assert.Equal(t, []string{"confidential"}, results[2].FlaggedCategories(nil))
+}
+
+func TestNewTermModeratorInvalidTerm(t *testing.T) {
+	_, err := NewTermModerator(map[string][]string{"broken": {"("}})
+	assert.Error(t, err)
+}
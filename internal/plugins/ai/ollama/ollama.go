@@ -17,6 +17,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	ollamaapi "github.com/ollama/ollama/api"
 )
 
@@ -84,7 +85,7 @@ func (o *Client) configure() (err error) {
 		}
 	}
 
-	o.httpClient = &http.Client{Timeout: timeout, Transport: &transport_sec{underlyingTransport: http.DefaultTransport, ApiKey: o.ApiKey}}
+	o.httpClient = &http.Client{Timeout: timeout, Transport: &transport_sec{underlyingTransport: o.HTTPTransport(nil), ApiKey: o.ApiKey}}
 	o.client = ollamaapi.NewClient(o.apiUrl, o.httpClient)
 
 	return
@@ -104,8 +105,43 @@ func (o *Client) ListModels(_ context.Context) (ret []string, err error) {
 	return
 }
 
-func (o *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
-	ctx := context.Background()
+// ContextWindow returns the context length the model was trained with, as reported
+// by "ollama show". The window actually used is num_ctx (--modelContextLength).
+func (o *Client) ContextWindow(ctx context.Context, model string) (ret int, err error) {
+	var resp *ollamaapi.ShowResponse
+	if resp, err = o.client.Show(ctx, &ollamaapi.ShowRequest{Model: model}); err != nil {
+		return
+	}
+	for key, value := range resp.ModelInfo {
+		if strings.HasSuffix(key, ".context_length") {
+			if length, ok := value.(float64); ok {
+				ret = int(length)
+			}
+			return
+		}
+	}
+	return
+}
+
+// Embeddings returns the embeddings of the inputs computed by a local embedding
+// model such as nomic-embed-text.
+func (o *Client) Embeddings(ctx context.Context, inputs []string, model string) (ret [][]float64, err error) {
+	var resp *ollamaapi.EmbedResponse
+	if resp, err = o.client.Embed(ctx, &ollamaapi.EmbedRequest{Model: model, Input: inputs}); err != nil {
+		return
+	}
+	if len(resp.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf(i18n.T("embeddings_count_mismatch"), len(resp.Embeddings), len(inputs))
+	}
+	for _, embedding := range resp.Embeddings {
+		ret = append(ret, ai.Float64s(embedding))
+	}
+	return
+}
+
+func (o *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) (err error) {
+	// The channel is closed on errors too, which the chatter waits for
+	defer close(channel)
 
 	var req ollamaapi.ChatRequest
 	if req, err = o.createChatRequest(ctx, msgs, opts); err != nil {
@@ -131,11 +167,7 @@ func (o *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 		return
 	}
 
-	if err = o.client.Chat(ctx, &req, respFunc); err != nil {
-		return
-	}
-
-	close(channel)
+	err = o.client.Chat(ctx, &req, respFunc)
 	return
 }
 
@@ -159,6 +191,11 @@ func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	return
 }
 
+// BuildRequest returns the chat request Send would make, for --dry-run
+func (o *Client) BuildRequest(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (any, error) {
+	return o.createChatRequest(ctx, msgs, opts)
+}
+
 func (o *Client) createChatRequest(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret ollamaapi.ChatRequest, err error) {
 	// Some models (e.g. qwen3-coder, deepseek) return empty responses when
 	// the only message has role=system. Convert to role=user in that case.
@@ -185,12 +222,33 @@ func (o *Client) createChatRequest(ctx context.Context, msgs []*chat.ChatComplet
 	if opts.ModelContextLength != 0 {
 		options["num_ctx"] = opts.ModelContextLength
 	}
+	if opts.Seed != 0 {
+		options["seed"] = opts.Seed
+	}
+	if opts.MaxTokens > 0 {
+		options["num_predict"] = opts.MaxTokens
+	}
+	if opts.NumGPU != nil {
+		options["num_gpu"] = *opts.NumGPU
+	}
+	if opts.NumThread != 0 {
+		options["num_thread"] = opts.NumThread
+	}
+	if opts.NumBatch != 0 {
+		options["num_batch"] = opts.NumBatch
+	}
+	if opts.Mirostat != 0 {
+		options["mirostat"] = opts.Mirostat
+	}
 
 	ret = ollamaapi.ChatRequest{
 		Model:    opts.Model,
 		Messages: messages,
 		Options:  options,
 	}
+	if opts.KeepAlive != nil {
+		ret.KeepAlive = &ollamaapi.Duration{Duration: *opts.KeepAlive}
+	}
 
 	// Map Fabric's ThinkingLevel to Ollama's Think field
 	switch opts.Thinking {
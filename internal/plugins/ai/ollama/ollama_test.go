@@ -8,7 +8,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -60,3 +63,38 @@ func TestLoadImageBytes_DataURLSuccess(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expected, got)
 }
+
+func TestCreateChatRequest_KeepAlive(t *testing.T) {
+	client := &Client{}
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hi"}}
+
+	req, err := client.createChatRequest(context.Background(), msgs, &domain.ChatOptions{Model: "llama3"})
+	require.NoError(t, err)
+	assert.Nil(t, req.KeepAlive)
+
+	keepAlive := 10 * time.Minute
+	req, err = client.createChatRequest(context.Background(), msgs, &domain.ChatOptions{Model: "llama3", KeepAlive: &keepAlive})
+	require.NoError(t, err)
+	require.NotNil(t, req.KeepAlive)
+	assert.Equal(t, keepAlive, req.KeepAlive.Duration)
+}
+
+func TestCreateChatRequest_RuntimeOptions(t *testing.T) {
+	client := &Client{}
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hi"}}
+
+	req, err := client.createChatRequest(context.Background(), msgs, &domain.ChatOptions{Model: "llama3"})
+	require.NoError(t, err)
+	for _, option := range []string{"num_gpu", "num_thread", "num_batch", "mirostat"} {
+		assert.NotContains(t, req.Options, option)
+	}
+
+	cpuOnly := 0
+	req, err = client.createChatRequest(context.Background(), msgs, &domain.ChatOptions{Model: "llama3",
+		NumGPU: &cpuOnly, NumThread: 8, NumBatch: 256, Mirostat: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 0, req.Options["num_gpu"])
+	assert.Equal(t, 8, req.Options["num_thread"])
+	assert.Equal(t, 256, req.Options["num_batch"])
+	assert.Equal(t, 2, req.Options["mirostat"])
+}
@@ -6,6 +6,7 @@ package openai
 
 import (
 	"context"
+	"strconv"
 	"strings"
 
 	"github.com/danielmiessler/fabric/internal/chat"
@@ -28,6 +29,61 @@ func (o *Client) sendChatCompletions(ctx context.Context, msgs []*chat.ChatCompl
 	return
 }
 
+// SendWithLogprobs sends the request with the Chat Completions API, which has
+// the log probabilities of the tokens for OpenAI and the compatible providers
+func (o *Client) SendWithLogprobs(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, logprobs []domain.TokenLogprob, err error) {
+	req := o.buildChatCompletionParams(msgs, opts)
+
+	var resp *openai.ChatCompletion
+	if resp, err = o.ApiClient.Chat.Completions.New(ctx, req); err != nil {
+		return
+	}
+	if len(resp.Choices) > 0 {
+		ret = resp.Choices[0].Message.Content
+		logprobs = convertLogprobs(resp.Choices[0].Logprobs.Content)
+	}
+	return
+}
+
+// SendWithTools sends the request with the Chat Completions API offering the
+// tools to the model, and returns its reply with the tools it calls
+func (o *Client) SendWithTools(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, tools []domain.Tool) (ret *chat.ChatCompletionMessage, err error) {
+	req := o.buildChatCompletionParams(msgs, opts)
+	for _, tool := range tools {
+		function := shared.FunctionDefinitionParam{Name: tool.Name, Parameters: shared.FunctionParameters(tool.InputSchema)}
+		if tool.Description != "" {
+			function.Description = openai.String(tool.Description)
+		}
+		req.Tools = append(req.Tools, openai.ChatCompletionToolParam{Function: function})
+	}
+
+	var resp *openai.ChatCompletion
+	if resp, err = o.ApiClient.Chat.Completions.New(ctx, req); err != nil {
+		return
+	}
+	ret = &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant}
+	if len(resp.Choices) > 0 {
+		ret.Content = resp.Choices[0].Message.Content
+		for _, call := range resp.Choices[0].Message.ToolCalls {
+			ret.ToolCalls = append(ret.ToolCalls, chat.ToolCall{ID: call.ID, Type: chat.ToolTypeFunction,
+				Function: chat.FunctionCall{Name: call.Function.Name, Arguments: call.Function.Arguments}})
+		}
+	}
+	return
+}
+
+// convertLogprobs converts the log probabilities of the response tokens
+func convertLogprobs(tokens []openai.ChatCompletionTokenLogprob) (ret []domain.TokenLogprob) {
+	ret = make([]domain.TokenLogprob, len(tokens))
+	for i, token := range tokens {
+		ret[i] = domain.TokenLogprob{Token: token.Token, Logprob: token.Logprob}
+		for _, top := range token.TopLogprobs {
+			ret[i].TopLogprobs = append(ret[i].TopLogprobs, domain.TokenLogprob{Token: top.Token, Logprob: top.Logprob})
+		}
+	}
+	return
+}
+
 // sendStreamChatCompletions sends a streaming request using the Chat Completions API
 func (o *Client) sendStreamChatCompletions(
 	ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate,
@@ -56,6 +112,7 @@ func (o *Client) sendStreamChatCompletions(
 					InputTokens:  int(chunk.Usage.PromptTokens),
 					OutputTokens: int(chunk.Usage.CompletionTokens),
 					TotalTokens:  int(chunk.Usage.TotalTokens),
+					Cost:         usageCost(chunk.Usage),
 				},
 			}
 		}
@@ -106,9 +163,33 @@ func (o *Client) buildChatCompletionParams(
 			ret.Seed = openai.Int(int64(opts.Seed))
 		}
 	}
+	if opts.Raw && opts.MaxTokens != 0 {
+		// Reasoning models only take max_completion_tokens
+		ret.MaxCompletionTokens = openai.Int(int64(opts.MaxTokens))
+	}
 	if eff, ok := parseReasoningEffort(opts.Thinking); ok {
 		ret.ReasoningEffort = eff
 	}
+	if opts.Logprobs {
+		ret.Logprobs = openai.Bool(true)
+		if opts.TopLogprobs > 0 {
+			ret.TopLogprobs = openai.Int(int64(opts.TopLogprobs))
+		}
+	}
+	if o.chatCompletionExtras != nil {
+		if extras := o.chatCompletionExtras(opts); len(extras) > 0 {
+			ret.SetExtraFields(extras)
+		}
+	}
+	return
+}
+
+// usageCost returns the generation cost, in USD, that some providers such as
+// OpenRouter add to the usage statistics.
+func usageCost(usage openai.CompletionUsage) (ret float64) {
+	if field, ok := usage.JSON.ExtraFields["cost"]; ok {
+		ret, _ = strconv.ParseFloat(field.Raw(), 64)
+	}
 	return
 }
 
@@ -135,7 +216,20 @@ func (o *Client) convertChatMessage(msg chat.ChatCompletionMessage) openai.ChatC
 		}
 		return openai.UserMessage(result.Content)
 	case chat.ChatMessageRoleAssistant:
-		return openai.AssistantMessage(result.Content)
+		if len(msg.ToolCalls) == 0 {
+			return openai.AssistantMessage(result.Content)
+		}
+		assistant := openai.ChatCompletionAssistantMessageParam{}
+		if result.Content != "" {
+			assistant.Content.OfString = openai.String(result.Content)
+		}
+		for _, call := range msg.ToolCalls {
+			assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallParam{ID: call.ID,
+				Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: call.Function.Name, Arguments: call.Function.Arguments}})
+		}
+		return openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant}
+	case chat.ChatMessageRoleTool:
+		return openai.ToolMessage(result.Content, msg.ToolCallID)
 	default:
 		return openai.UserMessage(result.Content)
 	}
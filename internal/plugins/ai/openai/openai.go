@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -75,6 +76,9 @@ type Client struct {
 	// entry alongside the web search tool when Search is enabled.
 	// This is an xAI-specific live search grounding tool.
 	enableXSearch bool
+	// chatCompletionExtras, when set, returns vendor-specific fields added
+	// to Chat Completions requests.
+	chatCompletionExtras func(opts *domain.ChatOptions) map[string]any
 }
 
 // SetResponsesAPIEnabled configures whether to use the Responses API
@@ -97,6 +101,13 @@ func (o *Client) SetEnableXSearch(enabled bool) {
 	o.enableXSearch = enabled
 }
 
+// SetChatCompletionExtras registers a function returning vendor-specific
+// fields added to each Chat Completions request, such as OpenRouter's
+// provider routing preferences.
+func (o *Client) SetChatCompletionExtras(extras func(opts *domain.ChatOptions) map[string]any) {
+	o.chatCompletionExtras = extras
+}
+
 // checkImageGenerationCompatibility warns if the model doesn't support image generation
 func checkImageGenerationCompatibility(model string) {
 	if !supportsImageGeneration(model) {
@@ -106,7 +117,7 @@ func checkImageGenerationCompatibility(model string) {
 }
 
 func (o *Client) configure() (ret error) {
-	opts := []option.RequestOption{option.WithAPIKey(o.ApiKey.Value)}
+	opts := []option.RequestOption{option.WithAPIKey(o.ApiKey.Value), option.WithHTTPClient(o.HTTPClient(0))}
 	if o.ApiBaseURL.Value != "" {
 		opts = append(opts, option.WithBaseURL(o.ApiBaseURL.Value))
 	}
@@ -114,9 +125,7 @@ func (o *Client) configure() (ret error) {
 	o.ApiClient = &client
 
 	// Initialize HTTP client for direct API calls (reused across requests)
-	o.httpClient = &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	o.httpClient = o.HTTPClient(10 * time.Second)
 	return
 }
 
@@ -214,6 +223,15 @@ func (o *Client) sendResponses(ctx context.Context, msgs []*chat.ChatCompletionM
 	return
 }
 
+// BuildRequest returns the Responses or Chat Completions API request Send would
+// make, for --dry-run
+func (o *Client) BuildRequest(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (any, error) {
+	if o.supportsResponsesAPI() {
+		return o.buildResponseParams(msgs, opts), nil
+	}
+	return o.buildChatCompletionParams(msgs, opts), nil
+}
+
 // supportsResponsesAPI determines if the provider supports the new Responses API
 func (o *Client) supportsResponsesAPI() bool {
 	return o.ImplementsResponses
@@ -250,6 +268,10 @@ func parseReasoningEffort(level domain.ThinkingLevel) (shared.ReasoningEffort, b
 	case domain.ThinkingHigh:
 		return shared.ReasoningEffortHigh, true
 	default:
+		// Token budgets are mapped to the closest effort level
+		if tokens, err := strconv.ParseInt(string(level), 10, 64); err == nil && tokens > 0 {
+			return parseReasoningEffort(domain.ThinkingLevelForBudget(tokens))
+		}
 		return "", false
 	}
 }
@@ -324,14 +346,14 @@ func (o *Client) buildResponseParams(
 		ret.Reasoning = shared.ReasoningParam{Effort: eff}
 	}
 
+	if opts.MaxTokens != 0 {
+		ret.MaxOutputTokens = openai.Int(int64(opts.MaxTokens))
+	}
 	if !opts.Raw {
 		ret.Temperature = openai.Float(opts.Temperature)
 		if opts.TopP != 0 {
 			ret.TopP = openai.Float(opts.TopP)
 		}
-		if opts.MaxTokens != 0 {
-			ret.MaxOutputTokens = openai.Int(int64(opts.MaxTokens))
-		}
 
 		// Add parameters not officially supported by Responses API as extra fields
 		extraFields := make(map[string]any)
@@ -0,0 +1,33 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	openai "github.com/openai/openai-go"
+)
+
+// Embeddings returns the embeddings of the inputs computed by an embedding model
+// such as text-embedding-3-small.
+func (o *Client) Embeddings(ctx context.Context, inputs []string, model string) (ret [][]float64, err error) {
+	var resp *openai.CreateEmbeddingResponse
+	if resp, err = o.ApiClient.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: inputs},
+		Model: openai.EmbeddingModel(model),
+	}); err != nil {
+		return
+	}
+
+	if len(resp.Data) != len(inputs) {
+		return nil, fmt.Errorf(i18n.T("embeddings_count_mismatch"), len(resp.Data), len(inputs))
+	}
+	ret = make([][]float64, len(inputs))
+	for _, item := range resp.Data {
+		if item.Index < 0 || int(item.Index) >= len(inputs) {
+			return nil, fmt.Errorf(i18n.T("embeddings_count_mismatch"), len(resp.Data), len(inputs))
+		}
+		ret[item.Index] = item.Embedding
+	}
+	return
+}
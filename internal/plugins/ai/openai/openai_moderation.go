@@ -0,0 +1,37 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	openai "github.com/openai/openai-go"
+)
+
+// Moderate classifies the inputs with the omni-moderation-latest model.
+func (o *Client) Moderate(ctx context.Context, inputs []string) (ret []ai.ModerationResult, err error) {
+	var resp *openai.ModerationNewResponse
+	if resp, err = o.ApiClient.Moderations.New(ctx, openai.ModerationNewParams{
+		Input: openai.ModerationNewParamsInputUnion{OfStringArray: inputs},
+		Model: openai.ModerationModelOmniModerationLatest,
+	}); err != nil {
+		return
+	}
+	if len(resp.Results) != len(inputs) {
+		return nil, fmt.Errorf(i18n.T("moderation_count_mismatch"), len(resp.Results), len(inputs))
+	}
+
+	for _, item := range resp.Results {
+		result := ai.ModerationResult{}
+		if err = json.Unmarshal([]byte(item.Categories.RawJSON()), &result.Flagged); err != nil {
+			return
+		}
+		if err = json.Unmarshal([]byte(item.CategoryScores.RawJSON()), &result.Scores); err != nil {
+			return
+		}
+		ret = append(ret, result)
+	}
+	return
+}
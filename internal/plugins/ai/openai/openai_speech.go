@@ -0,0 +1,46 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+
+	openai "github.com/openai/openai-go"
+)
+
+// DefaultSpeechVoice is used when no (or a non-OpenAI) voice is requested.
+const DefaultSpeechVoice = "alloy"
+
+// SpeechVoices lists the voices supported by the OpenAI speech API.
+var SpeechVoices = []string{"alloy", "ash", "ballad", "coral", "echo", "fable", "onyx", "nova", "sage", "shimmer", "verse"}
+
+// SynthesizeSpeech converts text to speech with the given model and voice and
+// returns the audio as WAV data.
+func (o *Client) SynthesizeSpeech(ctx context.Context, text, model, voice string) (ret []byte, err error) {
+	// --voice defaults to a Gemini voice, so fall back instead of failing the request
+	if voice = strings.ToLower(voice); !slices.Contains(SpeechVoices, voice) {
+		debuglog.Debug(debuglog.Detailed, "Unsupported OpenAI voice %q, using %s\n", voice, DefaultSpeechVoice)
+		voice = DefaultSpeechVoice
+	}
+
+	resp, err := o.ApiClient.Audio.Speech.New(ctx, openai.AudioSpeechNewParams{
+		Input:          text,
+		Model:          openai.SpeechModel(model),
+		Voice:          openai.AudioSpeechNewParamsVoice(voice),
+		ResponseFormat: openai.AudioSpeechNewParamsResponseFormatWAV,
+	})
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("openai_speech_failed"), err)
+	}
+	defer resp.Body.Close()
+
+	if ret, err = io.ReadAll(resp.Body); err != nil {
+		return nil, fmt.Errorf(i18n.T("openai_speech_failed"), err)
+	}
+	return
+}
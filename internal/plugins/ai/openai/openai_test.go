@@ -257,3 +257,92 @@ func TestCitationFormatting(t *testing.T) {
 	citationCount := strings.Count(result, "- [")
 	assert.Equal(t, 2, citationCount, "Expected 2 unique citations")
 }
+
+func TestParseReasoningEffort(t *testing.T) {
+	tests := []struct {
+		level  domain.ThinkingLevel
+		want   shared.ReasoningEffort
+		wantOK bool
+	}{
+		{domain.ThinkingLow, shared.ReasoningEffortLow, true},
+		{domain.ThinkingLevel("HIGH"), shared.ReasoningEffortHigh, true},
+		{domain.ThinkingLevel("2048"), shared.ReasoningEffortMedium, true},
+		{domain.ThinkingLevel("20000"), shared.ReasoningEffortHigh, true},
+		{domain.ThinkingOff, "", false},
+		{domain.ThinkingLevel("lots"), "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseReasoningEffort(tt.level)
+		assert.Equal(t, tt.wantOK, ok, tt.level)
+		assert.Equal(t, tt.want, got, tt.level)
+	}
+}
+
+func TestBuildChatCompletionParams_WithExtras(t *testing.T) {
+	client := NewClient()
+	client.SetChatCompletionExtras(func(opts *domain.ChatOptions) map[string]any {
+		return map[string]any{"provider": map[string]any{"sort": opts.ProviderSort}}
+	})
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+
+	params := client.buildChatCompletionParams(msgs, &domain.ChatOptions{Model: "m", ProviderSort: "price"})
+
+	body, err := params.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"provider":{"sort":"price"}`)
+}
+
+func TestUsageCost(t *testing.T) {
+	var usage openai.CompletionUsage
+	assert.NoError(t, usage.UnmarshalJSON([]byte(`{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15,"cost":0.00042}`)))
+	assert.InDelta(t, 0.00042, usageCost(usage), 1e-12)
+
+	var noCost openai.CompletionUsage
+	assert.NoError(t, noCost.UnmarshalJSON([]byte(`{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}`)))
+	assert.Zero(t, usageCost(noCost))
+}
+
+func TestBuildChatCompletionParams_WithLogprobs(t *testing.T) {
+	client := NewClient()
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello"}}
+
+	params := client.buildChatCompletionParams(msgs, &domain.ChatOptions{Model: "m", Logprobs: true, TopLogprobs: 3})
+
+	body, err := params.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"logprobs":true`)
+	assert.Contains(t, string(body), `"top_logprobs":3`)
+}
+
+func TestConvertLogprobs(t *testing.T) {
+	var choice openai.ChatCompletionChoiceLogprobs
+	assert.NoError(t, choice.UnmarshalJSON([]byte(`{"content":[{"token":"Hi","bytes":[72,105],"logprob":-0.01,`+
+		`"top_logprobs":[{"token":"Hi","bytes":[72,105],"logprob":-0.01},{"token":"Hello","bytes":[],"logprob":-4.6}]}],"refusal":null}`)))
+
+	assert.Equal(t, []domain.TokenLogprob{{
+		Token:   "Hi",
+		Logprob: -0.01,
+		TopLogprobs: []domain.TokenLogprob{
+			{Token: "Hi", Logprob: -0.01},
+			{Token: "Hello", Logprob: -4.6},
+		},
+	}}, convertLogprobs(choice.Content))
+}
+
+func TestBuildChatCompletionParams_WithToolCalls(t *testing.T) {
+	client := NewClient()
+	msgs := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleUser, Content: "Weather in Paris?"},
+		{Role: chat.ChatMessageRoleAssistant, ToolCalls: []chat.ToolCall{
+			{ID: "call_1", Type: chat.ToolTypeFunction, Function: chat.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}},
+		}},
+		{Role: chat.ChatMessageRoleTool, ToolCallID: "call_1", Name: "weather", Content: "sunny"},
+	}
+
+	params := client.buildChatCompletionParams(msgs, &domain.ChatOptions{Model: "m"})
+
+	body, err := params.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"tool_calls":[{"id":"call_1","function":{"arguments":"{\"city\":\"Paris\"}","name":"weather"},"type":"function"}]`)
+	assert.Contains(t, string(body), `{"content":"sunny","tool_call_id":"call_1","role":"tool"}`)
+}
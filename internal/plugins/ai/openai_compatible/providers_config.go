@@ -222,11 +222,6 @@ var ProviderMap = map[string]ProviderConfig{
 		BaseURL:             "https://api.aimlapi.com/v1",
 		ImplementsResponses: false,
 	},
-	"Cerebras": {
-		Name:                "Cerebras",
-		BaseURL:             "https://api.cerebras.ai/v1",
-		ImplementsResponses: false,
-	},
 	"DeepSeek": {
 		Name:                "DeepSeek",
 		BaseURL:             "https://api.deepseek.com",
@@ -243,21 +238,6 @@ var ProviderMap = map[string]ProviderConfig{
 		BaseURL:             "https://api.totalgpt.ai/v1",
 		ImplementsResponses: false,
 	},
-	"GrokAI": {
-		Name:                "GrokAI",
-		BaseURL:             "https://api.x.ai/v1",
-		ImplementsResponses: true,
-		// xAI's Responses API expects the "web_search" tool type, not
-		// OpenAI's "web_search_preview", and additionally accepts an
-		// "x_search" tool entry for live search grounding.
-		WebSearchToolName: "web_search",
-		EnableXSearch:     true,
-	},
-	"Groq": {
-		Name:                "Groq",
-		BaseURL:             "https://api.groq.com/openai/v1",
-		ImplementsResponses: false,
-	},
 	"Langdock": {
 		Name:                "Langdock",
 		BaseURL:             "https://api.langdock.com/openai/{{REGION=us}}/v1",
@@ -284,11 +264,6 @@ var ProviderMap = map[string]ProviderConfig{
 		BaseURL:             "https://api.novita.ai/openai/v1",
 		ImplementsResponses: false,
 	},
-	"OpenRouter": {
-		Name:                "OpenRouter",
-		BaseURL:             "https://openrouter.ai/api/v1",
-		ImplementsResponses: false,
-	},
 	"SiliconCloud": {
 		Name:                "SiliconCloud",
 		BaseURL:             "https://api.siliconflow.cn/v1",
@@ -16,10 +16,15 @@ func TestCreateClient(t *testing.T) {
 			exists:   true,
 		},
 		{
-			name:     "Existing provider - Groq",
-			provider: "Groq",
+			name:     "Existing provider - Together",
+			provider: "Together",
 			exists:   true,
 		},
+		{
+			name:     "Native provider - Groq",
+			provider: "Groq",
+			exists:   false,
+		},
 		{
 			name:     "Existing provider - Z AI",
 			provider: "Z AI",
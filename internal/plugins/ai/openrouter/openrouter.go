@@ -0,0 +1,209 @@
+// Package openrouter provides the OpenRouter client. OpenRouter routes requests
+// to many upstream providers; its catalog carries context and pricing metadata
+// for every model, requests accept provider routing preferences, and responses
+// report the generation cost.
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
+)
+
+const (
+	defaultBaseURL     = "https://openrouter.ai/api/v1"
+	errorResponseLimit = 1024
+)
+
+// Model is an entry of the OpenRouter catalog.
+type Model struct {
+	ID            string
+	ContextWindow int
+	Pricing       ai.ModelPricing
+}
+
+type Client struct {
+	*openai.Client
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	models map[string]Model
+}
+
+func NewClient() (ret *Client) {
+	ret = &Client{}
+	ret.Client = openai.NewClientCompatibleWithResponses("OpenRouter", defaultBaseURL, false, nil)
+	ret.SetChatCompletionExtras(requestExtras)
+	return
+}
+
+// requestExtras adds the provider routing preferences and asks for the cost to
+// be included in the usage statistics.
+func requestExtras(opts *domain.ChatOptions) map[string]any {
+	ret := map[string]any{"usage": map[string]any{"include": true}}
+
+	provider := map[string]any{}
+	if len(opts.ProviderOrder) > 0 {
+		provider["order"] = opts.ProviderOrder
+	}
+	if opts.ProviderSort != "" {
+		provider["sort"] = opts.ProviderSort
+	}
+	if opts.NoProviderFallbacks {
+		provider["allow_fallbacks"] = false
+	}
+	if len(provider) > 0 {
+		ret["provider"] = provider
+	}
+	return ret
+}
+
+// modelsResponse is the /models payload; prices are USD per token, as strings.
+type modelsResponse struct {
+	Data []struct {
+		ID            string `json:"id"`
+		ContextLength int    `json:"context_length"`
+		Pricing       struct {
+			Prompt     string `json:"prompt"`
+			Completion string `json:"completion"`
+		} `json:"pricing"`
+	} `json:"data"`
+}
+
+// ListModels returns the full OpenRouter catalog.
+func (o *Client) ListModels(ctx context.Context) (ret []string, err error) {
+	var models []Model
+	if models, err = o.Models(ctx); err != nil {
+		return
+	}
+	for _, model := range models {
+		ret = append(ret, model.ID)
+	}
+	return
+}
+
+// Models fetches the catalog with its metadata and keeps it for ContextWindow and ModelPricing.
+func (o *Client) Models(ctx context.Context) (ret []Model, err error) {
+	var payload *modelsResponse
+	if payload, err = o.fetchModels(ctx); err != nil {
+		return
+	}
+
+	byID := make(map[string]Model, len(payload.Data))
+	for _, item := range payload.Data {
+		if item.ID == "" {
+			continue
+		}
+		model := Model{
+			ID:            item.ID,
+			ContextWindow: item.ContextLength,
+			Pricing: ai.ModelPricing{
+				Input:  perMillion(item.Pricing.Prompt),
+				Output: perMillion(item.Pricing.Completion),
+			},
+		}
+		ret = append(ret, model)
+		byID[model.ID] = model
+	}
+
+	o.mu.Lock()
+	o.models = byID
+	o.mu.Unlock()
+	return
+}
+
+// ContextWindow returns the context length of a model from the catalog.
+func (o *Client) ContextWindow(ctx context.Context, model string) (ret int, err error) {
+	var info Model
+	if info, err = o.model(ctx, model); err == nil {
+		ret = info.ContextWindow
+	}
+	return
+}
+
+// ModelPricing returns the price of a model from the catalog.
+func (o *Client) ModelPricing(ctx context.Context, model string) (ret ai.ModelPricing, err error) {
+	var info Model
+	if info, err = o.model(ctx, model); err == nil {
+		ret = info.Pricing
+	}
+	return
+}
+
+func (o *Client) model(ctx context.Context, model string) (ret Model, err error) {
+	o.mu.Lock()
+	loaded := o.models != nil
+	o.mu.Unlock()
+	if !loaded {
+		if _, err = o.Models(ctx); err != nil {
+			return
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var ok bool
+	if ret, ok = o.models[model]; !ok {
+		err = fmt.Errorf(i18n.T("openrouter_unknown_model"), model)
+	}
+	return
+}
+
+func (o *Client) fetchModels(ctx context.Context) (ret *modelsResponse, err error) {
+	var modelsURL string
+	if modelsURL, err = url.JoinPath(o.ApiBaseURL.Value, "models"); err != nil {
+		return nil, fmt.Errorf(i18n.T("openai_failed_to_create_models_url"), err)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+o.ApiKey.Value)
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = o.HTTPClient(30 * time.Second)
+	}
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, errorResponseLimit))
+		return nil, fmt.Errorf(i18n.T("openrouter_models_request_failed"), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	ret = &modelsResponse{}
+	err = json.NewDecoder(resp.Body).Decode(ret)
+	return
+}
+
+// perMillion converts a USD per token price string to USD per million tokens.
+func perMillion(price string) float64 {
+	value, err := strconv.ParseFloat(price, 64)
+	if err != nil || value < 0 {
+		// OpenRouter uses -1 for variable prices, such as its auto router
+		return 0
+	}
+	return value * 1e6
+}
+
+func (o *Client) NeedsRawMode(modelName string) bool {
+	return false
+}
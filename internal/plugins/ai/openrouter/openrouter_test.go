@@ -0,0 +1,63 @@
+package openrouter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModels(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/models", r.URL.Path)
+		_, _ = w.Write([]byte(`{"data":[
+			{"id":"anthropic/claude-sonnet-4","context_length":200000,"pricing":{"prompt":"0.000003","completion":"0.000015"}},
+			{"id":"openrouter/auto","context_length":2000000,"pricing":{"prompt":"-1","completion":"-1"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.ApiBaseURL.Value = server.URL
+
+	pricing, err := client.ModelPricing(context.Background(), "anthropic/claude-sonnet-4")
+	require.NoError(t, err)
+	assert.InDelta(t, 3.0, pricing.Input, 1e-9)
+	assert.InDelta(t, 15.0, pricing.Output, 1e-9)
+	assert.InDelta(t, 0.0105, pricing.Cost(1000, 500), 1e-9)
+
+	window, err := client.ContextWindow(context.Background(), "anthropic/claude-sonnet-4")
+	require.NoError(t, err)
+	assert.Equal(t, 200000, window)
+
+	pricing, err = client.ModelPricing(context.Background(), "openrouter/auto")
+	require.NoError(t, err)
+	assert.Equal(t, ai.ModelPricing{}, pricing, "variable prices are unknown")
+
+	_, err = client.ContextWindow(context.Background(), "unknown/model")
+	assert.Error(t, err)
+	assert.Equal(t, 1, requests, "the catalog is fetched once")
+}
+
+func TestRequestExtras(t *testing.T) {
+	extras := requestExtras(&domain.ChatOptions{})
+	assert.Equal(t, map[string]any{"usage": map[string]any{"include": true}}, extras)
+
+	extras = requestExtras(&domain.ChatOptions{
+		ProviderOrder:       []string{"anthropic", "amazon-bedrock"},
+		ProviderSort:        "price",
+		NoProviderFallbacks: true,
+	})
+	assert.Equal(t, map[string]any{
+		"order":           []string{"anthropic", "amazon-bedrock"},
+		"sort":            "price",
+		"allow_fallbacks": false,
+	}, extras["provider"])
+}
@@ -50,6 +50,7 @@ func (c *Client) Configure() error {
 		}
 	}
 	c.client = perplexity.NewClient(c.APIKey.Value)
+	c.client.SetHTTPClient(c.HTTPClient(perplexity.DefaultTimeout))
 	return nil
 }
 
@@ -99,7 +100,7 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	request := perplexity.NewCompletionRequest(requestOptions...)
 
 	// Corrected: Use SendCompletionRequest method from perplexity-go library
-	resp, err := c.client.SendCompletionRequest(request) // Pass request directly
+	resp, err := c.client.SendCompletionRequestWithContext(ctx, request)
 	if err != nil {
 		return "", fmt.Errorf(i18n.T("perplexity_api_request_failed"), err)
 	}
@@ -119,7 +120,7 @@ func (c *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, o
 	return content.String(), nil
 }
 
-func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
+func (c *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
 	if c.client == nil {
 		if err := c.Configure(); err != nil {
 			close(channel) // Ensure channel is closed on error
@@ -166,7 +167,7 @@ func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessag
 	wg.Add(1)
 
 	go func() {
-		err := c.client.SendSSEHTTPRequest(&wg, request, responseChan)
+		err := c.client.SendSSEHTTPRequestWithContext(ctx, &wg, request, responseChan)
 		if err != nil {
 			// Log error, can't send to string channel directly.
 			// Consider a mechanism to propagate this error if needed.
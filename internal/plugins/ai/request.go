@@ -0,0 +1,15 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+// RequestBuilder is implemented by vendors able to build the request they would
+// send for the messages, without sending it. The returned value marshals to the
+// JSON body of the request, and is shown by --dry-run.
+type RequestBuilder interface {
+	BuildRequest(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (any, error)
+}
@@ -0,0 +1,31 @@
+package ai
+
+import (
+	"context"
+	"sort"
+)
+
+// RerankResult is the relevance of a document to a rerank query.
+type RerankResult struct {
+	// Index of the document in the reranked documents
+	Index int
+	Score float64
+}
+
+// Reranker is implemented by services able to order documents by relevance to
+// a query. The results are sorted by decreasing score; topN limits their number
+// when positive.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, documents []string, model string, topN int) ([]RerankResult, error)
+}
+
+// SortRerankResults sorts results by decreasing score, keeping the original
+// order of documents with the same score.
+func SortRerankResults(results []RerankResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Index < results[j].Index
+	})
+}
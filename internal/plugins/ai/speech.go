@@ -0,0 +1,9 @@
+package ai
+
+import "context"
+
+// Speaker is implemented by vendors able to synthesize speech. The returned
+// audio is WAV data.
+type Speaker interface {
+	SynthesizeSpeech(ctx context.Context, text, model, voice string) ([]byte, error)
+}
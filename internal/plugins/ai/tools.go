@@ -0,0 +1,15 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+// ToolSender is implemented by vendors whose models can call tools. The reply
+// is the assistant message, with the tool calls of the model when it wants
+// their results before answering.
+type ToolSender interface {
+	SendWithTools(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, tools []domain.Tool) (*chat.ChatCompletionMessage, error)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins"
 )
 
 type stubVendor struct {
@@ -19,6 +20,7 @@ func (v *stubVendor) IsConfigured() bool                           { return true
 func (v *stubVendor) Configure() error                             { return nil }
 func (v *stubVendor) Setup() error                                 { return nil }
 func (v *stubVendor) SetupFillEnvFileContent(*bytes.Buffer)        {}
+func (v *stubVendor) GetSettings() plugins.Settings                { return nil }
 func (v *stubVendor) ListModels(context.Context) ([]string, error) { return nil, nil }
 func (v *stubVendor) SendStream(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions, chan domain.StreamUpdate) error {
 	return nil
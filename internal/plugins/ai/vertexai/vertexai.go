@@ -153,7 +153,7 @@ func (c *Client) sendClaude(ctx context.Context, msgs []*chat.ChatCompletionMess
 
 	// Only set one of Temperature or TopP as some models don't allow both
 	// (following anthropic.go pattern)
-	if opts.TopP != domain.DefaultTopP {
+	if opts.TopP != domain.DefaultTopP && !opts.Deterministic {
 		params.TopP = anthropic.Opt(opts.TopP)
 	} else {
 		params.Temperature = anthropic.Opt(opts.Temperature)
@@ -179,21 +179,20 @@ func (c *Client) sendClaude(ctx context.Context, msgs []*chat.ChatCompletionMess
 	return strings.Join(textParts, ""), nil
 }
 
-func (c *Client) SendStream(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
+func (c *Client) SendStream(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
 	if isGeminiModel(opts.Model) {
-		return c.sendStreamGemini(msgs, opts, channel)
+		return c.sendStreamGemini(ctx, msgs, opts, channel)
 	}
-	return c.sendStreamClaude(msgs, opts, channel)
+	return c.sendStreamClaude(ctx, msgs, opts, channel)
 }
 
-func (c *Client) sendStreamClaude(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
+func (c *Client) sendStreamClaude(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
 	if c.client == nil {
 		close(channel)
 		return errors.New(i18n.T("vertexai_client_not_initialized"))
 	}
 
 	defer close(channel)
-	ctx := context.Background()
 
 	// Convert chat messages to Anthropic format
 	anthropicMessages := c.toMessages(msgs)
@@ -209,7 +208,7 @@ func (c *Client) sendStreamClaude(msgs []*chat.ChatCompletionMessage, opts *doma
 	}
 
 	// Only set one of Temperature or TopP as some models don't allow both
-	if opts.TopP != domain.DefaultTopP {
+	if opts.TopP != domain.DefaultTopP && !opts.Deterministic {
 		params.TopP = anthropic.Opt(opts.TopP)
 	} else {
 		params.Temperature = anthropic.Opt(opts.Temperature)
@@ -276,7 +275,10 @@ func (c *Client) sendGemini(ctx context.Context, msgs []*chat.ChatCompletionMess
 		return "", fmt.Errorf(i18n.T("vertexai_failed_gemini_client"), err)
 	}
 
-	contents := geminicommon.ConvertMessages(msgs)
+	contents, err := geminicommon.ConvertMessages(ctx, c.HTTPClient(0), msgs)
+	if err != nil {
+		return "", err
+	}
 	if len(contents) == 0 {
 		return "", errors.New(i18n.T("vertexai_no_valid_messages"))
 	}
@@ -301,6 +303,10 @@ func (c *Client) buildGeminiConfig(opts *domain.ChatOptions) *genai.GenerateCont
 		TopP:            &topP,
 		MaxOutputTokens: int32(getMaxTokens(opts)),
 	}
+	if opts.Seed != 0 {
+		seed := int32(opts.Seed)
+		config.Seed = &seed
+	}
 
 	// Add web search support
 	if opts.Search {
@@ -337,9 +343,8 @@ func parseGeminiThinking(level domain.ThinkingLevel) *genai.ThinkingConfig {
 	return nil
 }
 
-func (c *Client) sendStreamGemini(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
+func (c *Client) sendStreamGemini(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamUpdate) error {
 	defer close(channel)
-	ctx := context.Background()
 
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
 		Project:  c.ProjectID.Value,
@@ -350,7 +355,10 @@ func (c *Client) sendStreamGemini(msgs []*chat.ChatCompletionMessage, opts *doma
 		return fmt.Errorf(i18n.T("vertexai_failed_gemini_client"), err)
 	}
 
-	contents := geminicommon.ConvertMessages(msgs)
+	contents, err := geminicommon.ConvertMessages(ctx, c.HTTPClient(0), msgs)
+	if err != nil {
+		return err
+	}
 	if len(contents) == 0 {
 		return errors.New(i18n.T("vertexai_no_valid_messages"))
 	}
@@ -368,6 +376,13 @@ func (c *Client) sendStreamGemini(msgs []*chat.ChatCompletionMessage, opts *doma
 			return err
 		}
 
+		if thoughts := geminicommon.ExtractThoughts(response); thoughts != "" {
+			channel <- domain.StreamUpdate{
+				Type:    domain.StreamTypeReasoning,
+				Content: thoughts,
+			}
+		}
+
 		text := geminicommon.ExtractText(response)
 		if text != "" {
 			channel <- domain.StreamUpdate{
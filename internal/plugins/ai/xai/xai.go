@@ -0,0 +1,106 @@
+// Package xai provides the client for xAI's Grok models. Requests go through
+// the Responses API, where --search enables xAI's live search tools: web search
+// (honoring --search-location) and X search.
+package xai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
+)
+
+const (
+	// vendorName is kept from the former OpenAI-compatible entry so existing
+	// GROKAI_* settings keep working.
+	vendorName         = "GrokAI"
+	defaultBaseURL     = "https://api.x.ai/v1"
+	errorResponseLimit = 1024
+)
+
+type Client struct {
+	*openai.Client
+	httpClient *http.Client
+}
+
+func NewClient() (ret *Client) {
+	ret = &Client{}
+	ret.Client = openai.NewClientCompatibleWithResponses(vendorName, defaultBaseURL, true, nil)
+	// xAI's Responses API expects the "web_search" tool type rather than OpenAI's
+	// "web_search_preview", and accepts an "x_search" tool for searching X posts.
+	ret.SetWebSearchToolName("web_search")
+	ret.SetEnableXSearch(true)
+	return
+}
+
+// languageModelsResponse is the payload of /language-models, which unlike /models
+// only lists models usable for chat, along with their modalities.
+type languageModelsResponse struct {
+	Models []struct {
+		ID               string   `json:"id"`
+		OutputModalities []string `json:"output_modalities"`
+	} `json:"models"`
+}
+
+// ListModels returns the Grok language models, leaving out the image and video
+// generation models also listed by /models.
+func (o *Client) ListModels(ctx context.Context) (ret []string, err error) {
+	if ret, err = o.listLanguageModels(ctx); err == nil {
+		return
+	}
+	debuglog.Debug(debuglog.Basic, "Listing xAI language models failed: %v, falling back to /models\n", err)
+	return o.Client.ListModels(ctx)
+}
+
+func (o *Client) listLanguageModels(ctx context.Context) (ret []string, err error) {
+	var modelsURL string
+	if modelsURL, err = url.JoinPath(o.ApiBaseURL.Value, "language-models"); err != nil {
+		return nil, fmt.Errorf(i18n.T("openai_failed_to_create_models_url"), err)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+o.ApiKey.Value)
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = o.HTTPClient(10 * time.Second)
+	}
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, errorResponseLimit))
+		return nil, fmt.Errorf(i18n.T("xai_models_request_failed"), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload languageModelsResponse
+	if err = json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return
+	}
+	for _, model := range payload.Models {
+		if model.ID != "" && (len(model.OutputModalities) == 0 || slices.Contains(model.OutputModalities, "text")) {
+			ret = append(ret, model.ID)
+		}
+	}
+	return
+}
+
+func (o *Client) NeedsRawMode(modelName string) bool {
+	return false
+}
@@ -0,0 +1,52 @@
+package xai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/language-models", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"models":[
+			{"id":"grok-4","input_modalities":["text","image"],"output_modalities":["text"]},
+			{"id":"grok-3-mini","input_modalities":["text"],"output_modalities":["text"]},
+			{"id":"grok-2-image","input_modalities":["text"],"output_modalities":["image"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.ApiKey.Value = "test-key"
+	client.ApiBaseURL.Value = server.URL
+
+	models, err := client.ListModels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"grok-4", "grok-3-mini"}, models)
+}
+
+func TestBuildResponseParams_LiveSearch(t *testing.T) {
+	client := NewClient()
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "What happened today?"}}
+	opts := &domain.ChatOptions{Model: "grok-4", Search: true, SearchLocation: "America/Los_Angeles"}
+
+	params := client.BuildResponseParams(msgs, opts)
+
+	require.Len(t, params.Tools, 2)
+	webSearch := params.Tools[0].OfWebSearchPreview
+	require.NotNil(t, webSearch)
+	assert.Equal(t, "web_search", string(webSearch.Type))
+	assert.Equal(t, "America/Los_Angeles", webSearch.UserLocation.Timezone.Value)
+	assert.Equal(t, "x_search", string(params.Tools[1].OfWebSearchPreview.Type))
+
+	opts.Search = false
+	assert.Empty(t, client.BuildResponseParams(msgs, opts).Tools)
+}
@@ -30,6 +30,10 @@ func NewDb(dir string) (db *Db) {
 	db.Contexts = &ContextsEntity{
 		&StorageEntity{Label: "Contexts", Dir: db.FilePath("contexts")}}
 
+	db.Spend = &SpendEntity{FilePath: db.FilePath("spend.jsonl")}
+
+	db.Images = &ImagesEntity{Dir: db.FilePath("images")}
+
 	return
 }
 
@@ -39,6 +43,8 @@ type Db struct {
 	Patterns *PatternsEntity
 	Sessions *SessionsEntity
 	Contexts *ContextsEntity
+	Spend    *SpendEntity
+	Images   *ImagesEntity
 
 	EnvFilePath string
 }
@@ -48,8 +54,12 @@ func (o *Db) Configure() (err error) {
 		return
 	}
 
-	if err = o.LoadEnvFile(); err != nil {
-		return
+	// Without a .env file, fabric can be configured by the environment alone,
+	// as in containers and CI
+	if o.IsEnvFileExists() || os.Getenv("DEFAULT_MODEL") == "" {
+		if err = o.LoadEnvFile(); err != nil {
+			return
+		}
 	}
 
 	// Set custom patterns directory after loading .env file
@@ -86,6 +96,15 @@ func (o *Db) LoadEnvFile() (err error) {
 	return
 }
 
+// ReloadEnvFile loads the .env file again, its values replacing those already
+// in the environment.
+func (o *Db) ReloadEnvFile() (err error) {
+	if err = godotenv.Overload(o.EnvFilePath); err != nil {
+		err = fmt.Errorf(i18n.T("db_error_loading_env_file"), err)
+	}
+	return
+}
+
 func (o *Db) IsEnvFileExists() (ret bool) {
 	_, err := os.Stat(o.EnvFilePath)
 	ret = !os.IsNotExist(err)
@@ -93,7 +112,11 @@ func (o *Db) IsEnvFileExists() (ret bool) {
 }
 
 func (o *Db) SaveEnv(content string) (err error) {
-	err = os.WriteFile(o.EnvFilePath, []byte(content), 0644)
+	// The .env file holds API keys and passwords, readable by the user only
+	if err = os.WriteFile(o.EnvFilePath, []byte(content), 0600); err != nil {
+		return
+	}
+	err = os.Chmod(o.EnvFilePath, 0600)
 	return
 }
 
@@ -2,6 +2,7 @@ package fsdb
 
 import (
 	"os"
+	"runtime"
 	"testing"
 )
 
@@ -27,6 +28,17 @@ func TestDb_Configure(t *testing.T) {
 	}
 }
 
+func TestDb_Configure_EnvironmentOnly(t *testing.T) {
+	t.Setenv("DEFAULT_MODEL", "gpt-4o")
+	db := NewDb(t.TempDir())
+	if err := db.Configure(); err != nil {
+		t.Fatalf("db is not configured without a .env file, but shall be with DEFAULT_MODEL set: %v", err)
+	}
+	if db.IsEnvFileExists() {
+		t.Fatalf("db file exists, but must not be created by Configure")
+	}
+}
+
 func TestDb_LoadEnvFile(t *testing.T) {
 	dir := t.TempDir()
 	db := NewDb(dir)
@@ -53,3 +65,23 @@ func TestDb_SaveEnv(t *testing.T) {
 		t.Errorf("expected .env file to be saved")
 	}
 }
+
+func TestDb_SaveEnv_RestrictsPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	db := NewDb(t.TempDir())
+	if err := os.WriteFile(db.EnvFilePath, []byte("KEY=VALUE\n"), 0644); err != nil {
+		t.Fatalf("failed to write .env file: %v", err)
+	}
+	if err := db.SaveEnv("EMAIL_PASSWORD=secret\n"); err != nil {
+		t.Fatalf("failed to save .env file: %v", err)
+	}
+	info, err := os.Stat(db.EnvFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,75 @@
+package fsdb
+
+import (
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ImageExtensions are the extensions of the generated images kept
+var ImageExtensions = []string{".png", ".jpeg", ".jpg", ".webp"}
+
+// ImagesEntity keeps the images generated by the server, in a directory
+// listed as their gallery.
+type ImagesEntity struct {
+	Dir string
+}
+
+// ImageInfo describes an image of the gallery
+type ImageInfo struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Size    int64     `json:"size"`
+}
+
+// NewPath returns the name and the path of a new image with the extension,
+// named after the current time so that the names sort as the images were made
+func (o *ImagesEntity) NewPath(extension string) (name, path string, err error) {
+	if err = os.MkdirAll(o.Dir, 0o755); err != nil {
+		return
+	}
+	name = time.Now().Format("20060102-150405") + "-" + rand.Text()[:8] + extension
+	path = filepath.Join(o.Dir, name)
+	return
+}
+
+// List returns the images, the newest first
+func (o *ImagesEntity) List() (ret []ImageInfo, err error) {
+	ret = []ImageInfo{}
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(o.Dir); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.Contains(ImageExtensions, strings.ToLower(filepath.Ext(entry.Name()))) {
+			continue
+		}
+		var info os.FileInfo
+		if info, err = entry.Info(); err != nil {
+			return
+		}
+		ret = append(ret, ImageInfo{Name: entry.Name(), Created: info.ModTime(), Size: info.Size()})
+	}
+	slices.SortFunc(ret, func(a, b ImageInfo) int { return b.Created.Compare(a.Created) })
+	return
+}
+
+// Path returns the path of the image of the gallery, os.ErrNotExist if there is
+// none of the name, which cannot lead out of the gallery
+func (o *ImagesEntity) Path(name string) (ret string, err error) {
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") ||
+		!slices.Contains(ImageExtensions, strings.ToLower(filepath.Ext(name))) {
+		return "", os.ErrNotExist
+	}
+	ret = filepath.Join(o.Dir, name)
+	if _, err = os.Stat(ret); err != nil {
+		return "", os.ErrNotExist
+	}
+	return
+}
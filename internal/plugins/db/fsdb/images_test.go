@@ -0,0 +1,46 @@
+package fsdb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImages(t *testing.T) {
+	images := &ImagesEntity{Dir: filepath.Join(t.TempDir(), "images")}
+	if list, err := images.List(); err != nil || len(list) != 0 {
+		t.Fatalf("List() without a gallery = %v, %v; want no images", list, err)
+	}
+
+	var names []string
+	for i, extension := range []string{".png", ".webp"} {
+		name, path, err := images.NewPath(extension)
+		if err != nil || !strings.HasSuffix(name, extension) || path != filepath.Join(images.Dir, name) {
+			t.Fatalf("NewPath(%s) = %s, %s, %v", extension, name, path, err)
+		}
+		if err = os.WriteFile(path, []byte("image"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modified := time.Now().Add(time.Duration(i-2) * time.Minute)
+		_ = os.Chtimes(path, modified, modified)
+		names = append(names, name)
+	}
+	_ = os.WriteFile(filepath.Join(images.Dir, "notes.txt"), []byte("not an image"), 0o644)
+
+	list, err := images.List()
+	if err != nil || len(list) != 2 || list[0].Name != names[1] || list[1].Name != names[0] || list[0].Size != 5 {
+		t.Errorf("List() = %+v, %v; want the two images, the newest first", list, err)
+	}
+
+	if path, err := images.Path(names[0]); err != nil || path != filepath.Join(images.Dir, names[0]) {
+		t.Errorf("Path(%s) = %s, %v", names[0], path, err)
+	}
+	for _, name := range []string{"missing.png", "notes.txt", "../images/" + names[0], ".hidden.png"} {
+		if _, err = images.Path(name); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Path(%s) error = %v, want os.ErrNotExist", name, err)
+		}
+	}
+}
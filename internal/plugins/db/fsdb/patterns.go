@@ -4,12 +4,27 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/danielmiessler/fabric/internal/plugins/template"
 	"github.com/danielmiessler/fabric/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// PatternMetadataFile is the optional file next to system.md describing a pattern.
+const PatternMetadataFile = "pattern.yaml"
+
+// PatternReadmeFile is the optional file next to system.md documenting a pattern.
+const PatternReadmeFile = "README.md"
+
+// Pattern capability hints understood in PatternMetadata.Requires.
+const (
+	RequiresVision      = "vision"
+	RequiresLongContext = "long_context"
+	RequiresReasoning   = "reasoning"
 )
 
 type PatternsEntity struct {
@@ -24,13 +39,60 @@ type Pattern struct {
 	Name        string
 	Description string
 	Pattern     string
+	// Language is the language of the localized variant loaded, like fr for
+	// system.fr.md, empty for the pattern itself
+	Language string
+}
+
+// PatternMetadata holds the optional settings of a pattern read from PatternMetadataFile.
+type PatternMetadata struct {
+	// Name is the name of the pattern, not part of the file.
+	Name string `yaml:"-" json:"name"`
+	// Description says in one line what the pattern does.
+	Description string `yaml:"description,omitempty" json:"description"`
+	// Tags are the categories of the pattern, e.g. ANALYSIS or WRITING.
+	Tags []string `yaml:"tags,omitempty" json:"tags"`
+	// Variables lists the variables the pattern needs, given with -v=#name:value.
+	Variables []string `yaml:"variables,omitempty" json:"variables"`
+	// Requires lists the model capabilities the pattern needs: vision, long_context or reasoning.
+	Requires []string `yaml:"requires,omitempty" json:"requires,omitempty"`
+}
+
+// HasTags reports whether the pattern has all the tags, ignoring case.
+func (o *PatternMetadata) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(o.Tags, func(own string) bool { return strings.EqualFold(own, tag) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether the keyword is in the name, description or tags of the
+// pattern, ignoring case.
+func (o *PatternMetadata) Matches(keyword string) bool {
+	keyword = strings.ToLower(keyword)
+	for _, text := range append([]string{o.Name, o.Description}, o.Tags...) {
+		if strings.Contains(strings.ToLower(text), keyword) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetApplyVariables main entry point for getting patterns from any source
 func (o *PatternsEntity) GetApplyVariables(
 	source string, variables map[string]string, input string) (pattern *Pattern, err error) {
 
-	if pattern, err = o.loadPattern(source); err != nil {
+	return o.GetLocalizedApplyVariables(source, "", variables, input)
+}
+
+// GetLocalizedApplyVariables is GetApplyVariables loading the variant of the
+// pattern in the language, e.g. system.fr.md, when the pattern has one
+func (o *PatternsEntity) GetLocalizedApplyVariables(
+	source, language string, variables map[string]string, input string) (pattern *Pattern, err error) {
+
+	if pattern, err = o.loadPattern(source, language); err != nil {
 		return
 	}
 
@@ -41,8 +103,14 @@ func (o *PatternsEntity) GetApplyVariables(
 // GetWithoutVariables returns a pattern with only the {{input}} placeholder processed
 // and skips template variable replacement
 func (o *PatternsEntity) GetWithoutVariables(source, input string) (pattern *Pattern, err error) {
+	return o.GetLocalizedWithoutVariables(source, "", input)
+}
 
-	if pattern, err = o.loadPattern(source); err != nil {
+// GetLocalizedWithoutVariables is GetWithoutVariables loading the variant of the
+// pattern in the language when the pattern has one
+func (o *PatternsEntity) GetLocalizedWithoutVariables(source, language, input string) (pattern *Pattern, err error) {
+
+	if pattern, err = o.loadPattern(source, language); err != nil {
 		return
 	}
 
@@ -55,7 +123,7 @@ func (o *PatternsEntity) GetRaw(name string) (*Pattern, error) {
 	return o.getFromDB(name)
 }
 
-func (o *PatternsEntity) loadPattern(source string) (pattern *Pattern, err error) {
+func (o *PatternsEntity) loadPattern(source, language string) (pattern *Pattern, err error) {
 	// Determine if this is a file path
 	isFilePath := strings.HasPrefix(source, "\\") ||
 		strings.HasPrefix(source, "/") ||
@@ -75,14 +143,39 @@ func (o *PatternsEntity) loadPattern(source string) (pattern *Pattern, err error
 		}
 	} else {
 		// Otherwise, get the pattern from the database
-		pattern, err = o.getFromDB(source)
+		if pattern = o.getLocalized(source, language); pattern == nil {
+			pattern, err = o.getFromDB(source)
+		}
 	}
 
 	return
 }
 
+// getLocalized returns the variant of the pattern in the language, looking for
+// system.pt-BR.md then system.pt.md for pt-BR, or nil when there is none
+func (o *PatternsEntity) getLocalized(name, language string) *Pattern {
+	if language == "" {
+		return nil
+	}
+	ext := filepath.Ext(o.SystemPatternFile)
+	base := strings.TrimSuffix(o.SystemPatternFile, ext)
+	dir := o.patternDir(name)
+	candidates := []string{language}
+	if primary, _, found := strings.Cut(language, "-"); found {
+		candidates = append(candidates, primary)
+	}
+	for _, candidate := range candidates {
+		if content, err := os.ReadFile(filepath.Join(dir, base+"."+candidate+ext)); err == nil {
+			return &Pattern{Name: name, Pattern: string(content), Language: candidate}
+		}
+	}
+	return nil
+}
+
+// ensureInput appends {{input}} to the patterns using their input neither
+// directly nor piped to an extension
 func (o *PatternsEntity) ensureInput(pattern *Pattern) {
-	if !strings.Contains(pattern.Pattern, "{{input}}") {
+	if !strings.Contains(pattern.Pattern, "{{input}}") && !strings.Contains(pattern.Pattern, "{{input | ") {
 		if !strings.HasSuffix(pattern.Pattern, "\n") {
 			pattern.Pattern += "\n"
 		}
@@ -289,3 +382,65 @@ func (o *PatternsEntity) Save(name string, content []byte) (err error) {
 	}
 	return nil
 }
+
+// GetMetadata returns the metadata of the named pattern, read from the same directory
+// the pattern is loaded from. Patterns without a metadata file get empty metadata.
+func (o *PatternsEntity) GetMetadata(name string) (ret *PatternMetadata, err error) {
+	ret = &PatternMetadata{Name: name}
+	var data []byte
+	if data, err = os.ReadFile(filepath.Join(o.patternDir(name), PatternMetadataFile)); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	if err = yaml.Unmarshal(data, ret); err != nil {
+		err = fmt.Errorf(i18n.T("patterns_error_parse_metadata"), name, err)
+	}
+	return
+}
+
+// FindMetadata returns the metadata of the patterns having all the tags and the
+// keyword in their name, description or tags, in the order of GetNames. Empty
+// tags and keyword match every pattern.
+func (o *PatternsEntity) FindMetadata(tags []string, keyword string) (ret []*PatternMetadata, err error) {
+	var names []string
+	if names, err = o.GetNames(); err != nil {
+		return
+	}
+	for _, name := range names {
+		var metadata *PatternMetadata
+		if metadata, err = o.GetMetadata(name); err != nil {
+			return nil, err
+		}
+		if metadata.HasTags(tags) && (keyword == "" || metadata.Matches(keyword)) {
+			ret = append(ret, metadata)
+		}
+	}
+	return
+}
+
+// GetReadme returns the README.md of the named pattern, or an empty string for
+// the patterns without one.
+func (o *PatternsEntity) GetReadme(name string) (ret string, err error) {
+	var data []byte
+	if data, err = os.ReadFile(filepath.Join(o.patternDir(name), PatternReadmeFile)); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	return string(data), nil
+}
+
+// patternDir returns the directory the named pattern is loaded from, the custom
+// patterns directory overriding the main one.
+func (o *PatternsEntity) patternDir(name string) string {
+	if o.CustomPatternsDir != "" {
+		customDir := filepath.Join(o.CustomPatternsDir, name)
+		if _, statErr := os.Stat(filepath.Join(customDir, o.SystemPatternFile)); statErr == nil {
+			return customDir
+		}
+	}
+	return filepath.Join(o.Dir, name)
+}
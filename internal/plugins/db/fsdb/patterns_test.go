@@ -74,6 +74,14 @@ func TestApplyVariables(t *testing.T) {
 			input: "Review this PR",
 			want:  "You are a code reviewer.\nPlease analyze.\nReview this PR",
 		},
+		{
+			name: "pattern piping input does not get input appended",
+			pattern: &Pattern{
+				Pattern: "Summarize {{input | json:title}}.",
+			},
+			input: `{"title": "the release notes"}`,
+			want:  "Summarize the release notes.",
+		},
 		// ... previous test cases ...
 	}
 
@@ -368,3 +376,138 @@ func TestPatternsEntity_CustomPatternsEmpty(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "Main pattern content", pattern.Pattern)
 }
+
+func TestGetMetadata(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "plain", "You are a helper.")
+	createTestPattern(t, entity, "vision", "Describe the image.")
+	require.NoError(t, os.WriteFile(filepath.Join(entity.Dir, "vision", PatternMetadataFile),
+		[]byte("requires:\n  - vision\n  - reasoning\n"), 0644))
+
+	metadata, err := entity.GetMetadata("vision")
+	require.NoError(t, err)
+	assert.Equal(t, []string{RequiresVision, RequiresReasoning}, metadata.Requires)
+
+	metadata, err = entity.GetMetadata("plain")
+	require.NoError(t, err)
+	assert.Empty(t, metadata.Requires)
+
+	// A custom pattern overrides the built-in one together with its metadata
+	entity.CustomPatternsDir = t.TempDir()
+	customDir := filepath.Join(entity.CustomPatternsDir, "vision")
+	require.NoError(t, os.MkdirAll(customDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(customDir, entity.SystemPatternFile), []byte("Custom."), 0644))
+
+	metadata, err = entity.GetMetadata("vision")
+	require.NoError(t, err)
+	assert.Empty(t, metadata.Requires)
+
+	require.NoError(t, os.WriteFile(filepath.Join(entity.Dir, "plain", PatternMetadataFile), []byte("requires: [\n"), 0644))
+	_, err = entity.GetMetadata("plain")
+	assert.Error(t, err)
+}
+
+func TestGetReadme(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "plain", "You are a helper.")
+	createTestPattern(t, entity, "documented", "You summarize.")
+	require.NoError(t, os.WriteFile(filepath.Join(entity.Dir, "documented", PatternReadmeFile),
+		[]byte("# documented\n\nSummarizes the input.\n"), 0644))
+
+	readme, err := entity.GetReadme("documented")
+	require.NoError(t, err)
+	assert.Equal(t, "# documented\n\nSummarizes the input.\n", readme)
+
+	readme, err = entity.GetReadme("plain")
+	require.NoError(t, err)
+	assert.Empty(t, readme)
+}
+
+func TestFindMetadata(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	metadata := map[string]string{
+		"summarize":      "description: Summarize content.\ntags: [SUMMARIZE, WRITING]\n",
+		"translate":      "description: Translate the input.\ntags: [CONVERSION]\nvariables: [lang_code]\n",
+		"write_essay":    "description: Write an essay.\ntags: [WRITING]\n",
+		"no_description": "",
+	}
+	for name, content := range metadata {
+		createTestPattern(t, entity, name, "You are a helper.")
+		if content != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(entity.Dir, name, PatternMetadataFile), []byte(content), 0644))
+		}
+	}
+
+	names := func(found []*PatternMetadata) (ret []string) {
+		for _, metadata := range found {
+			ret = append(ret, metadata.Name)
+		}
+		return
+	}
+
+	found, err := entity.FindMetadata(nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"no_description", "summarize", "translate", "write_essay"}, names(found))
+
+	found, err = entity.FindMetadata([]string{"writing"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"summarize", "write_essay"}, names(found))
+
+	found, err = entity.FindMetadata([]string{"writing", "summarize"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"summarize"}, names(found))
+
+	// The keyword is searched in the name, description and tags
+	found, err = entity.FindMetadata(nil, "ESSAY")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"write_essay"}, names(found))
+	found, err = entity.FindMetadata(nil, "conversion")
+	require.NoError(t, err)
+	require.Equal(t, []string{"translate"}, names(found))
+	assert.Equal(t, "Translate the input.", found[0].Description)
+	assert.Equal(t, []string{"lang_code"}, found[0].Variables)
+}
+
+func TestGetLocalizedApplyVariables(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "greet", "Greet {{name}}.")
+	require.NoError(t, os.WriteFile(filepath.Join(entity.Dir, "greet", "system.pt.md"), []byte("Cumprimente {{name}}."), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(entity.Dir, "greet", "system.pt-BR.md"), []byte("Saúde {{name}}."), 0644))
+	variables := map[string]string{"name": "Ana"}
+
+	tests := []struct {
+		language     string
+		wantPattern  string
+		wantLanguage string
+	}{
+		{language: "pt-BR", wantPattern: "Saúde Ana.\nhi", wantLanguage: "pt-BR"},
+		{language: "pt-PT", wantPattern: "Cumprimente Ana.\nhi", wantLanguage: "pt"},
+		{language: "fr", wantPattern: "Greet Ana.\nhi"},
+		{language: "", wantPattern: "Greet Ana.\nhi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			pattern, err := entity.GetLocalizedApplyVariables("greet", tt.language, variables, "hi")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPattern, pattern.Pattern)
+			assert.Equal(t, tt.wantLanguage, pattern.Language)
+		})
+	}
+
+	pattern, err := entity.GetLocalizedWithoutVariables("greet", "pt-BR", "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "Saúde {{name}}.\nhi", pattern.Pattern)
+
+	// Localized variants are not patterns of their own
+	names, err := entity.GetNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"greet"}, names)
+}
@@ -0,0 +1,190 @@
+package fsdb
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	// sessionMetaDir is the directory of the sessions directory holding the
+	// metadata of the sessions, hidden from their names
+	sessionMetaDir = ".meta"
+	// maxTitleLength is the length in characters of the titles generated from the
+	// first message of the session
+	maxTitleLength = 60
+
+	// Orders of FindSessions
+	SessionSortName    = "name"
+	SessionSortTitle   = "title"
+	SessionSortCreated = "created"
+	SessionSortUpdated = "updated"
+)
+
+// SessionMeta describes a session: its title and tags, when it was created and
+// last updated, and the vendor, model and pattern of its last exchange.
+type SessionMeta struct {
+	Title   string    `json:"title,omitempty"`
+	Tags    []string  `json:"tags,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+	Vendor  string    `json:"vendor,omitempty"`
+	Model   string    `json:"model,omitempty"`
+	Pattern string    `json:"pattern,omitempty"`
+}
+
+// SessionInfo is the metadata of a session with its name and number of
+// messages, as FindSessions lists it.
+type SessionInfo struct {
+	Name string `json:"name"`
+	SessionMeta
+	Messages int `json:"messages"`
+}
+
+// HasTags reports whether the session has all the tags, ignoring case.
+func (o *SessionMeta) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(o.Tags, func(own string) bool { return strings.EqualFold(own, tag) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetMeta returns the metadata of the named session. Sessions saved without
+// metadata, by older versions or through the REST API, get the time of their
+// file as creation and update time.
+func (o *SessionsEntity) GetMeta(name string) (ret *SessionMeta, err error) {
+	ret = &SessionMeta{}
+	var data []byte
+	if data, err = os.ReadFile(o.metaPath(name)); err == nil {
+		if err = json.Unmarshal(data, ret); err != nil {
+			return nil, fmt.Errorf(i18n.T("sessions_error_parse_metadata"), name, err)
+		}
+		return
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var modTime time.Time
+	if modTime, err = o.ModTime(name); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	ret.Created, ret.Updated = modTime, modTime
+	return ret, nil
+}
+
+// FindSessions returns the sessions having all the tags and the keyword in
+// their name, title or tags, in the order of sortBy: by name, by title, or
+// the most recently created or updated first. Empty tags and keyword match
+// every session.
+func (o *SessionsEntity) FindSessions(tags []string, keyword string, sortBy string) (ret []*SessionInfo, err error) {
+	var names []string
+	if names, err = o.GetNames(); err != nil {
+		return
+	}
+	keyword = strings.ToLower(keyword)
+	for _, name := range names {
+		var meta *SessionMeta
+		if meta, err = o.GetMeta(name); err != nil {
+			return nil, err
+		}
+		if !meta.HasTags(tags) || !matchesAny(keyword, append([]string{name, meta.Title}, meta.Tags...)) {
+			continue
+		}
+		var messages []*chat.ChatCompletionMessage
+		if err = o.LoadAsJson(name, &messages); err != nil {
+			return nil, err
+		}
+		ret = append(ret, &SessionInfo{Name: name, SessionMeta: *meta, Messages: len(messages)})
+	}
+
+	slices.SortStableFunc(ret, func(a, b *SessionInfo) int {
+		switch sortBy {
+		case SessionSortTitle:
+			return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		case SessionSortCreated:
+			return b.Created.Compare(a.Created)
+		case SessionSortUpdated:
+			return b.Updated.Compare(a.Updated)
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return
+}
+
+// saveMeta writes the metadata of the named session
+func (o *SessionsEntity) saveMeta(name string, meta *SessionMeta) (err error) {
+	path := o.metaPath(name)
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return
+	}
+	var data []byte
+	if data, err = json.MarshalIndent(meta, "", "  "); err != nil {
+		return
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+func (o *SessionsEntity) metaPath(name string) string {
+	return filepath.Join(o.Dir, sessionMetaDir, o.buildFileName(name))
+}
+
+// generateTitle returns the first line of the first user message of the
+// session, shortened to maxTitleLength characters at a word boundary.
+func (o *Session) generateTitle() string {
+	for _, message := range o.Messages {
+		if message.Role != chat.ChatMessageRoleUser {
+			continue
+		}
+		content := message.Content
+		for _, part := range message.MultiContent {
+			if part.Type == chat.ChatMessagePartTypeText {
+				content += "\n" + part.Text
+			}
+		}
+		for line := range strings.Lines(content) {
+			if line = strings.Join(strings.Fields(line), " "); line != "" {
+				return shortenTitle(line)
+			}
+		}
+	}
+	return ""
+}
+
+func shortenTitle(line string) string {
+	runes := []rune(line)
+	if len(runes) <= maxTitleLength {
+		return line
+	}
+	title := string(runes[:maxTitleLength])
+	if space := strings.LastIndex(title, " "); space > maxTitleLength/2 {
+		title = title[:space]
+	}
+	return strings.TrimRight(title, " ,.;:") + "…"
+}
+
+// matchesAny reports whether the lower case keyword is in one of the texts,
+// ignoring case. An empty keyword matches.
+func matchesAny(keyword string, texts []string) bool {
+	if keyword == "" {
+		return true
+	}
+	for _, text := range texts {
+		if strings.Contains(strings.ToLower(text), keyword) {
+			return true
+		}
+	}
+	return false
+}
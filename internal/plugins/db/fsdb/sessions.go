@@ -2,12 +2,18 @@ package fsdb
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/i18n"
 )
 
+// InterruptedSessionName is the session in which an interrupted response is
+// saved when the chat has no session, to be continued with --resume.
+const InterruptedSessionName = "last-interrupted"
+
 type SessionsEntity struct {
 	*StorageEntity
 }
@@ -16,7 +22,13 @@ func (o *SessionsEntity) Get(name string) (session *Session, err error) {
 	session = &Session{Name: name}
 
 	if o.Exists(name) {
-		err = o.LoadAsJson(name, &session.Messages)
+		if err = o.LoadAsJson(name, &session.Messages); err != nil {
+			return
+		}
+		var meta *SessionMeta
+		if meta, err = o.GetMeta(name); err == nil {
+			session.Meta = *meta
+		}
 	} else {
 		fmt.Printf(i18n.T("sessions_creating_new"), name)
 	}
@@ -33,13 +45,46 @@ func (o *SessionsEntity) PrintSession(name string) (err error) {
 	return
 }
 
+// SaveSession saves the messages of the session and its metadata, updated
+// now, and titled after its first message unless it has a title.
 func (o *SessionsEntity) SaveSession(session *Session) (err error) {
-	return o.SaveAsJson(session.Name, session.Messages)
+	if err = o.SaveAsJson(session.Name, session.Messages); err != nil {
+		return
+	}
+	now := time.Now()
+	if session.Meta.Created.IsZero() {
+		session.Meta.Created = now
+	}
+	session.Meta.Updated = now
+	if session.Meta.Title == "" {
+		session.Meta.Title = session.generateTitle()
+	}
+	return o.saveMeta(session.Name, &session.Meta)
+}
+
+// Delete deletes the session and its metadata.
+func (o *SessionsEntity) Delete(name string) (err error) {
+	if err = o.StorageEntity.Delete(name); err != nil {
+		return
+	}
+	return os.RemoveAll(o.metaPath(name))
+}
+
+// Rename renames the session and its metadata.
+func (o *SessionsEntity) Rename(oldName, newName string) (err error) {
+	if err = o.StorageEntity.Rename(oldName, newName); err != nil {
+		return
+	}
+	if err = os.Rename(o.metaPath(oldName), o.metaPath(newName)); os.IsNotExist(err) {
+		err = nil
+	}
+	return
 }
 
 type Session struct {
 	Name     string
 	Messages []*chat.ChatCompletionMessage
+	Meta     SessionMeta
 
 	vendorMessages []*chat.ChatCompletionMessage
 }
@@ -59,6 +104,12 @@ func (o *Session) Append(messages ...*chat.ChatCompletionMessage) {
 	}
 }
 
+// SetMessages replaces the messages of the session.
+func (o *Session) SetMessages(messages []*chat.ChatCompletionMessage) {
+	o.Messages = messages
+	o.vendorMessages = nil
+}
+
 func (o *Session) GetVendorMessages() (ret []*chat.ChatCompletionMessage) {
 	if len(o.vendorMessages) == 0 {
 		for _, message := range o.Messages {
@@ -1,7 +1,11 @@
 package fsdb
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 )
@@ -36,3 +40,120 @@ func TestSessions_SaveSession(t *testing.T) {
 		t.Errorf("expected session to be saved")
 	}
 }
+
+func TestSessions_Metadata(t *testing.T) {
+	sessions := &SessionsEntity{
+		StorageEntity: &StorageEntity{Dir: t.TempDir(), FileExtension: ".json"},
+	}
+	session := &Session{Name: "chat", Messages: []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "You are a helpful assistant."},
+		{Role: chat.ChatMessageRoleUser, Content: "\nHow do I   configure the\nproxy of the build server?"},
+	}}
+	session.Meta.Model = "gpt-4o"
+	if err := sessions.SaveSession(session); err != nil {
+		t.Fatalf("failed to save session: %v", err)
+	}
+	created := session.Meta.Created
+
+	names, err := sessions.GetNames()
+	if err != nil || len(names) != 1 || names[0] != "chat" {
+		t.Fatalf("GetNames() = %v, %v, want the session only", names, err)
+	}
+
+	loaded, err := sessions.Get("chat")
+	if err != nil {
+		t.Fatalf("failed to get session: %v", err)
+	}
+	if loaded.Meta.Title != "How do I configure the" || loaded.Meta.Model != "gpt-4o" || created.IsZero() {
+		t.Errorf("unexpected metadata %+v", loaded.Meta)
+	}
+
+	loaded.Meta.Title = "Proxy"
+	if err = sessions.SaveSession(loaded); err != nil {
+		t.Fatalf("failed to save session: %v", err)
+	}
+	if err = sessions.Rename("chat", "proxy"); err != nil {
+		t.Fatalf("failed to rename session: %v", err)
+	}
+	meta, err := sessions.GetMeta("proxy")
+	if err != nil || meta.Title != "Proxy" || !meta.Created.Equal(created) || meta.Updated.Before(created) {
+		t.Errorf("GetMeta() = %+v, %v, want the title kept and the creation time unchanged", meta, err)
+	}
+
+	if err = sessions.Delete("proxy"); err != nil {
+		t.Fatalf("failed to delete session: %v", err)
+	}
+	if _, err = os.Stat(sessions.metaPath("proxy")); !os.IsNotExist(err) {
+		t.Errorf("expected the metadata to be deleted, got %v", err)
+	}
+}
+
+func TestSessions_GetMetaWithoutMetadata(t *testing.T) {
+	sessions := &SessionsEntity{
+		StorageEntity: &StorageEntity{Dir: t.TempDir(), FileExtension: ".json"},
+	}
+	if err := os.WriteFile(filepath.Join(sessions.Dir, "old.json"), []byte(`[{"role":"user","content":"hi"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(sessions.Dir, "old.json"), updated, updated); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := sessions.GetMeta("old")
+	if err != nil || !meta.Created.Equal(updated) || !meta.Updated.Equal(updated) || meta.Title != "" {
+		t.Errorf("GetMeta() = %+v, %v, want the file time", meta, err)
+	}
+}
+
+func TestSessions_FindSessions(t *testing.T) {
+	sessions := &SessionsEntity{
+		StorageEntity: &StorageEntity{Dir: t.TempDir(), FileExtension: ".json"},
+	}
+	for i, session := range []*Session{
+		{Name: "b", Meta: SessionMeta{Title: "Alpha", Tags: []string{"work"}}},
+		{Name: "a", Meta: SessionMeta{Title: "Zulu", Tags: []string{"Work", "go"}}},
+		{Name: "c", Meta: SessionMeta{Title: "Mike"}},
+	} {
+		session.Messages = []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "hi"}}
+		if err := sessions.SaveSession(session); err != nil {
+			t.Fatalf("failed to save session: %v", err)
+		}
+		session.Meta.Updated = time.Date(2025, 1, 1+i, 0, 0, 0, 0, time.UTC)
+		if err := sessions.saveMeta(session.Name, &session.Meta); err != nil {
+			t.Fatalf("failed to save metadata: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tags    []string
+		keyword string
+		sortBy  string
+		want    []string
+	}{
+		{name: "by name", sortBy: SessionSortName, want: []string{"a", "b", "c"}},
+		{name: "by title", sortBy: SessionSortTitle, want: []string{"b", "c", "a"}},
+		{name: "newest first", sortBy: SessionSortUpdated, want: []string{"c", "a", "b"}},
+		{name: "tags", tags: []string{"work"}, want: []string{"a", "b"}},
+		{name: "keyword", keyword: "ZUL", want: []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := sessions.FindSessions(tt.tags, tt.keyword, tt.sortBy)
+			if err != nil {
+				t.Fatalf("FindSessions() error = %v", err)
+			}
+			var names []string
+			for _, info := range found {
+				names = append(names, info.Name)
+				if info.Messages != 1 {
+					t.Errorf("session %s has %d messages, want 1", info.Name, info.Messages)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FindSessions() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package fsdb
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// Periods of the spend report
+const (
+	SpendPeriodDay   = "day"
+	SpendPeriodWeek  = "week"
+	SpendPeriodMonth = "month"
+)
+
+// SpendEntry is the spend of a request, as the vendor reported it or as
+// estimated from the length of the messages and the price of the model.
+type SpendEntry struct {
+	Time         time.Time `json:"time"`
+	Vendor       string    `json:"vendor"`
+	Model        string    `json:"model"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	// Cost is in USD, 0 when the price of the model is unknown
+	Cost float64 `json:"cost"`
+	// Estimated tells that the tokens were counted by fabric rather than
+	// reported by the vendor
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// SpendTotal is the spend of the requests to a model of a vendor.
+type SpendTotal struct {
+	Vendor       string  `json:"vendor"`
+	Model        string  `json:"model"`
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// SpendEntity keeps the spend of the requests in a log of one JSON entry a
+// line.
+type SpendEntity struct {
+	FilePath string
+
+	// mu appends one entry at a time
+	mu sync.Mutex
+}
+
+// Add appends the entry to the spend log.
+func (o *SpendEntity) Add(entry SpendEntry) (err error) {
+	var line []byte
+	if line, err = json.Marshal(entry); err != nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var file *os.File
+	if file, err = os.OpenFile(o.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err != nil {
+		return fmt.Errorf(i18n.T("spend_error_write"), o.FilePath, err)
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf(i18n.T("spend_error_write"), o.FilePath, err)
+	}
+	return file.Close()
+}
+
+// Since returns the entries of the requests made from the time on. Lines that
+// cannot be parsed, such as one cut short by a crash, are skipped.
+func (o *SpendEntity) Since(since time.Time) (ret []SpendEntry, err error) {
+	var file *os.File
+	if file, err = os.Open(o.FilePath); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		} else {
+			err = fmt.Errorf(i18n.T("spend_error_read"), o.FilePath, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry SpendEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil || entry.Time.Before(since) {
+			continue
+		}
+		ret = append(ret, entry)
+	}
+	if err = scanner.Err(); err != nil {
+		err = fmt.Errorf(i18n.T("spend_error_read"), o.FilePath, err)
+	}
+	return
+}
+
+// SummarizeSpend returns the totals of the entries by vendor and model, the
+// most expensive first.
+func SummarizeSpend(entries []SpendEntry) (ret []*SpendTotal) {
+	totals := map[[2]string]*SpendTotal{}
+	for _, entry := range entries {
+		key := [2]string{entry.Vendor, entry.Model}
+		total := totals[key]
+		if total == nil {
+			total = &SpendTotal{Vendor: entry.Vendor, Model: entry.Model}
+			totals[key] = total
+			ret = append(ret, total)
+		}
+		total.Requests++
+		total.InputTokens += entry.InputTokens
+		total.OutputTokens += entry.OutputTokens
+		total.Cost += entry.Cost
+	}
+	slices.SortStableFunc(ret, func(a, b *SpendTotal) int {
+		if c := cmp.Compare(b.Cost, a.Cost); c != 0 {
+			return c
+		}
+		return cmp.Or(cmp.Compare(a.Vendor, b.Vendor), cmp.Compare(a.Model, b.Model))
+	})
+	return
+}
+
+// SpendPeriodStart returns the start of the day, the week (on Monday) or the
+// month of the time, in its location.
+func SpendPeriodStart(now time.Time, period string) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case SpendPeriodWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case SpendPeriodMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
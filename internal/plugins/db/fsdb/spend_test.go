@@ -0,0 +1,77 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSpend_AddAndSince(t *testing.T) {
+	spend := &SpendEntity{FilePath: filepath.Join(t.TempDir(), "spend.jsonl")}
+	if entries, err := spend.Since(time.Time{}); err != nil || len(entries) != 0 {
+		t.Fatalf("Since() without a log = %v, %v; want no entries", entries, err)
+	}
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for _, entry := range []SpendEntry{
+		{Time: now.AddDate(0, -1, 0), Vendor: "OpenAI", Model: "gpt-4o", InputTokens: 10, Cost: 1},
+		{Time: now, Vendor: "OpenAI", Model: "gpt-4o", InputTokens: 100, OutputTokens: 50, Cost: 0.5},
+		{Time: now, Vendor: "Anthropic", Model: "claude-sonnet-4", InputTokens: 200, OutputTokens: 100, Cost: 2},
+		{Time: now, Vendor: "OpenAI", Model: "gpt-4o", InputTokens: 100, OutputTokens: 50, Cost: 0.5, Estimated: true},
+	} {
+		if err := spend.Add(entry); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	// A line cut short is skipped
+	file, err := os.OpenFile(spend.FilePath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(`{"time":"2026-10-16T`)
+	file.Close()
+
+	entries, err := spend.Since(now.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("Since() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Since() = %d entries, want 3", len(entries))
+	}
+
+	totals := SummarizeSpend(entries)
+	if len(totals) != 2 {
+		t.Fatalf("SummarizeSpend() = %d totals, want 2", len(totals))
+	}
+	if totals[0].Vendor != "Anthropic" || totals[0].Cost != 2 {
+		t.Errorf("first total = %+v, want the most expensive Anthropic", totals[0])
+	}
+	if got := *totals[1]; got != (SpendTotal{Vendor: "OpenAI", Model: "gpt-4o", Requests: 2, InputTokens: 200, OutputTokens: 100, Cost: 1}) {
+		t.Errorf("second total = %+v", got)
+	}
+}
+
+func TestSpendPeriodStart(t *testing.T) {
+	// A Friday
+	now := time.Date(2026, 10, 16, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		period string
+		want   time.Time
+	}{
+		{SpendPeriodDay, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{SpendPeriodWeek, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+		{SpendPeriodMonth, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := SpendPeriodStart(now, tt.period); !got.Equal(tt.want) {
+			t.Errorf("SpendPeriodStart(%s) = %v, want %v", tt.period, got, tt.want)
+		}
+	}
+
+	// On a Sunday the week started 6 days earlier, in the previous month
+	sunday := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	if got, want := SpendPeriodStart(sunday, SpendPeriodWeek), time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("SpendPeriodStart(week) on a Sunday = %v, want %v", got, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/danielmiessler/fabric/internal/util"
@@ -80,6 +81,15 @@ func (o *StorageEntity) Exists(name string) (ret bool) {
 	return
 }
 
+// ModTime returns the time the item was last saved.
+func (o *StorageEntity) ModTime(name string) (ret time.Time, err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(o.BuildFilePathByName(name)); err == nil {
+		ret = info.ModTime()
+	}
+	return
+}
+
 func (o *StorageEntity) Rename(oldName, newName string) (err error) {
 	if err = os.Rename(o.BuildFilePathByName(oldName), o.BuildFilePathByName(newName)); err != nil {
 		err = fmt.Errorf(i18n.T("storage_error_rename"), oldName, newName, err)
@@ -87,13 +97,39 @@ func (o *StorageEntity) Rename(oldName, newName string) (err error) {
 	return
 }
 
+// Save writes the content to a temporary file renamed over the item once
+// complete, so that an interrupted save never leaves a truncated item behind.
 func (o *StorageEntity) Save(name string, content []byte) (err error) {
-	if err = os.WriteFile(o.BuildFilePathByName(name), content, 0644); err != nil {
+	if err = writeFileAtomic(o.BuildFilePathByName(name), content, 0644); err != nil {
 		err = fmt.Errorf(i18n.T("storage_error_save"), name, err)
 	}
 	return
 }
 
+func writeFileAtomic(path string, content []byte, perm os.FileMode) (err error) {
+	var file *os.File
+	if file, err = os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp"); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(file.Name())
+		}
+	}()
+	if _, err = file.Write(content); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	if err = os.Chmod(file.Name(), perm); err != nil {
+		return
+	}
+	err = os.Rename(file.Name(), path)
+	return
+}
+
 func (o *StorageEntity) Load(name string) (ret []byte, err error) {
 	if ret, err = os.ReadFile(o.BuildFilePathByName(name)); err != nil {
 		err = fmt.Errorf(i18n.T("storage_error_load"), name, err)
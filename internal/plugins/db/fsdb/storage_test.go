@@ -1,6 +1,7 @@
 package fsdb
 
 import (
+	"os"
 	"testing"
 )
 
@@ -21,6 +22,34 @@ func TestStorage_SaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestStorage_SaveReplacesAtomically(t *testing.T) {
+	dir := t.TempDir()
+	storage := &StorageEntity{Dir: dir, FileExtension: ".json"}
+	for _, content := range []string{"first version", "second"} {
+		if err := storage.Save("test", []byte(content)); err != nil {
+			t.Fatalf("failed to save content: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "test.json" {
+		t.Errorf("expected only test.json in the directory, got %v", entries)
+	}
+	info, err := os.Stat(storage.BuildFilePathByName("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("expected mode 0644, got %v", info.Mode().Perm())
+	}
+	if loaded, _ := storage.Load("test"); string(loaded) != "second" {
+		t.Errorf("expected %q, got %q", "second", loaded)
+	}
+}
+
 func TestStorage_Exists(t *testing.T) {
 	dir := t.TempDir()
 	storage := &StorageEntity{Dir: dir}
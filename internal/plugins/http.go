@@ -0,0 +1,188 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// configureHTTPExtras parses the extra headers, "Name: value" pairs separated
+// by semicolons, and the extra query parameters, a URL query, of the vendor
+func (o *PluginBase) configureHTTPExtras() (err error) {
+	o.extraHeader, o.extraQuery = nil, nil
+	if o.ExtraHeaders != nil && o.ExtraHeaders.Value != "" {
+		if o.extraHeader, err = ParseHeaders(o.ExtraHeaders.Value); err != nil {
+			return fmt.Errorf(i18n.T("plugin_invalid_extra_headers"), o.ExtraHeaders.EnvVariable, err)
+		}
+	}
+	if o.ExtraQuery != nil && o.ExtraQuery.Value != "" {
+		if o.extraQuery, err = url.ParseQuery(o.ExtraQuery.Value); err != nil {
+			return fmt.Errorf(i18n.T("plugin_invalid_extra_query"), o.ExtraQuery.EnvVariable, err)
+		}
+	}
+	return
+}
+
+// ParseHeaders parses "Name: value" pairs separated by semicolons
+func ParseHeaders(value string) (ret http.Header, err error) {
+	ret = http.Header{}
+	for pair := range strings.SplitSeq(value, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, headerValue, found := strings.Cut(pair, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf(i18n.T("plugin_invalid_header"), strings.TrimSpace(pair))
+		}
+		ret.Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(headerValue))
+	}
+	return
+}
+
+// HTTPTransport returns the transport of the vendor requests, adding the extra
+// headers and query parameters of the vendor settings, e.g. for an LLM gateway
+// or an observability proxy, and the headers and body params of the request
+// context. A nil next stands for http.DefaultTransport at the time of each
+// request, so that --proxy and --record apply.
+func (o *PluginBase) HTTPTransport(next http.RoundTripper) http.RoundTripper {
+	return &extrasTransport{next: next, header: o.extraHeader, query: o.extraQuery}
+}
+
+// HTTPClient returns an HTTP client with the timeout, 0 for none, using the
+// transport of the vendor requests
+func (o *PluginBase) HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: o.HTTPTransport(nil)}
+}
+
+type headersKey struct{}
+
+// WithHeaders returns a context whose requests to the vendors, made with
+// HTTPTransport, get the headers besides the extra headers of the vendor
+func WithHeaders(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, headersKey{}, header)
+}
+
+type bodyParamsKey struct{}
+
+// WithBodyParams returns a context whose JSON requests to the vendors, made
+// with HTTPTransport, get the params merged into their body
+func WithBodyParams(ctx context.Context, params map[string]any) context.Context {
+	return context.WithValue(ctx, bodyParamsKey{}, params)
+}
+
+// ParseBodyParams parses "key=value" params, whose value is JSON, e.g. 5, true
+// or {"type": "json_object"}, or else a string
+func ParseBodyParams(values []string) (ret map[string]any, err error) {
+	ret = map[string]any{}
+	for _, param := range values {
+		key, value, found := strings.Cut(param, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.HasPrefix(key, ".") || strings.HasSuffix(key, ".") || strings.Contains(key, "..") {
+			return nil, fmt.Errorf(i18n.T("plugin_invalid_body_param"), param)
+		}
+		var parsed any
+		if json.Unmarshal([]byte(value), &parsed) != nil {
+			parsed = value
+		}
+		ret[key] = parsed
+	}
+	return
+}
+
+// MergeBodyParams sets the params in the JSON object of body, the dots of a key
+// naming the fields of nested objects, e.g. "generationConfig.topK"
+func MergeBodyParams(body []byte, params map[string]any) (ret []byte, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// Integers keep their precision
+	decoder.UseNumber()
+	var object map[string]any
+	if err = decoder.Decode(&object); err != nil {
+		return
+	}
+	if object == nil {
+		object = map[string]any{}
+	}
+	// A key is set before the keys of its fields
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		parent := object
+		path := strings.Split(key, ".")
+		for _, name := range path[:len(path)-1] {
+			child, ok := parent[name].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				parent[name] = child
+			}
+			parent = child
+		}
+		parent[path[len(path)-1]] = params[key]
+	}
+	return json.Marshal(object)
+}
+
+type extrasTransport struct {
+	next   http.RoundTripper
+	header http.Header
+	query  url.Values
+}
+
+func (t *extrasTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	contextHeader, _ := req.Context().Value(headersKey{}).(http.Header)
+	bodyParams, _ := req.Context().Value(bodyParamsKey{}).(map[string]any)
+	if len(t.header) == 0 && len(t.query) == 0 && len(contextHeader) == 0 && len(bodyParams) == 0 {
+		return next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	if len(bodyParams) > 0 && req.Body != nil && req.Method == http.MethodPost &&
+		strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		if err := mergeRequestBody(req, bodyParams); err != nil {
+			return nil, err
+		}
+	}
+	for _, header := range []http.Header{t.header, contextHeader} {
+		for name, values := range header {
+			req.Header[name] = values
+		}
+	}
+	if len(t.query) > 0 {
+		query := req.URL.Query()
+		for name, values := range t.query {
+			query[name] = values
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+	return next.RoundTrip(req)
+}
+
+// mergeRequestBody merges the params into the JSON body of the request, which
+// is left as is when it is not an object
+func mergeRequestBody(req *http.Request, params map[string]any) (err error) {
+	var body []byte
+	if body, err = io.ReadAll(req.Body); err != nil {
+		return
+	}
+	req.Body.Close()
+	if merged, mergeErr := MergeBodyParams(body, params); mergeErr == nil {
+		body = merged
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	req.ContentLength = int64(len(body))
+	req.Header.Del("Content-Length")
+	return
+}
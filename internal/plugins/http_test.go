@@ -0,0 +1,126 @@
+package plugins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeaders(t *testing.T) {
+	header, err := ParseHeaders("Helicone-Auth: Bearer sk-1; x-portkey-provider: openai;")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer sk-1", header.Get("Helicone-Auth"))
+	assert.Equal(t, "openai", header.Get("X-Portkey-Provider"))
+
+	for _, value := range []string{"Helicone-Auth", ": value", "Bad Name: value"} {
+		_, err = ParseHeaders(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestHTTPTransportAddsExtras(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+	defer server.Close()
+
+	t.Setenv("TESTVENDOR_EXTRA_HEADERS", "Helicone-Auth: Bearer sk-1")
+	t.Setenv("TESTVENDOR_EXTRA_QUERY", "tenant=acme")
+	plugin := NewVendorPluginBase("TestVendor", nil)
+	require.NoError(t, plugin.Configure())
+
+	resp, err := plugin.HTTPClient(0).Get(server.URL + "/v1/models?limit=5")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "Bearer sk-1", got.Header.Get("Helicone-Auth"))
+	assert.Equal(t, "acme", got.URL.Query().Get("tenant"))
+	assert.Equal(t, "5", got.URL.Query().Get("limit"))
+}
+
+func TestConfigureRejectsInvalidExtras(t *testing.T) {
+	t.Setenv("TESTVENDOR_EXTRA_HEADERS", "no separator")
+	plugin := NewVendorPluginBase("TestVendor", nil)
+	assert.Error(t, plugin.Configure())
+
+	t.Setenv("TESTVENDOR_EXTRA_HEADERS", "")
+	t.Setenv("TESTVENDOR_EXTRA_QUERY", "a=%zz")
+	plugin = NewVendorPluginBase("TestVendor", nil)
+	assert.Error(t, plugin.Configure())
+}
+
+func TestHTTPTransportAddsContextHeaders(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+	defer server.Close()
+
+	plugin := NewVendorPluginBase("TestVendor", nil)
+	require.NoError(t, plugin.Configure())
+	ctx := WithHeaders(context.Background(), http.Header{"X-Request-Tag": {"docs"}})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := plugin.HTTPClient(0).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "docs", got.Header.Get("X-Request-Tag"))
+}
+
+func TestParseBodyParams(t *testing.T) {
+	params, err := ParseBodyParams([]string{"top_k=40", "logprobs=true", "response_format={\"type\":\"json_object\"}", "user=alice", "stop="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"top_k":           float64(40),
+		"logprobs":        true,
+		"response_format": map[string]any{"type": "json_object"},
+		"user":            "alice",
+		"stop":            "",
+	}, params)
+
+	for _, value := range []string{"top_k", "=5", "a..b=1", ".a=1"} {
+		_, err = ParseBodyParams([]string{value})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestMergeBodyParams(t *testing.T) {
+	body, err := MergeBodyParams([]byte(`{"model":"m","seed":9007199254740993,"generationConfig":{"temperature":0.7}}`), map[string]any{
+		"generationConfig.topK": 40,
+		"response_format.type":  "json_object",
+		"model":                 "other",
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model":"other","seed":9007199254740993,"generationConfig":{"temperature":0.7,"topK":40},"response_format":{"type":"json_object"}}`, string(body))
+
+	_, err = MergeBodyParams([]byte(`[1, 2]`), map[string]any{"a": 1})
+	assert.Error(t, err)
+}
+
+func TestHTTPTransportMergesContextBodyParams(t *testing.T) {
+	var got []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	plugin := NewVendorPluginBase("TestVendor", nil)
+	require.NoError(t, plugin.Configure())
+	ctx := WithBodyParams(context.Background(), map[string]any{"top_k": 40})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(`{"model":"m"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := plugin.HTTPClient(0).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.JSONEq(t, `{"model":"m","top_k":40}`, string(got))
+}
@@ -3,6 +3,8 @@ package plugins
 import (
 	"bytes"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
 
@@ -19,6 +21,7 @@ type Plugin interface {
 	Configure() error
 	Setup() error
 	SetupFillEnvFileContent(*bytes.Buffer)
+	GetSettings() Settings
 }
 
 type PluginBase struct {
@@ -29,6 +32,13 @@ type PluginBase struct {
 	SetupDescription string
 	EnvNamePrefix    string
 
+	// ExtraHeaders and ExtraQuery are added to the HTTP requests of the vendors
+	// using HTTPTransport, for LLM gateways and observability proxies
+	ExtraHeaders *Setting
+	ExtraQuery   *Setting
+	extraHeader  http.Header
+	extraQuery   url.Values
+
 	ConfigureCustom func() error
 }
 
@@ -38,12 +48,20 @@ func (o *PluginBase) GetName() string {
 
 // NewVendorPluginBase creates a standardized PluginBase for AI vendor plugins.
 // This centralizes the common initialization pattern used by all vendors.
-func NewVendorPluginBase(name string, configure func() error) *PluginBase {
-	return &PluginBase{
+func NewVendorPluginBase(name string, configure func() error) (ret *PluginBase) {
+	ret = &PluginBase{
 		Name:            name,
 		EnvNamePrefix:   BuildEnvVariablePrefix(name),
 		ConfigureCustom: configure,
 	}
+	ret.ExtraHeaders = ret.AddSetting("Extra Headers", false)
+	ret.ExtraQuery = ret.AddSetting("Extra Query", false)
+	return
+}
+
+// GetSettings returns the settings of the plugin, read from the environment
+func (o *PluginBase) GetSettings() Settings {
+	return o.Settings
 }
 
 func (o *PluginBase) GetSetupDescription() (ret string) {
@@ -102,6 +120,9 @@ func (o *PluginBase) Configure() (err error) {
 	if err = o.Settings.Configure(); err != nil {
 		return
 	}
+	if err = o.configureHTTPExtras(); err != nil {
+		return
+	}
 
 	if o.ConfigureCustom != nil {
 		err = o.ConfigureCustom()
@@ -114,6 +135,10 @@ func (o *PluginBase) Setup() (err error) {
 		return
 	}
 
+	if err = o.configureHTTPExtras(); err != nil {
+		return
+	}
+
 	// After Setup, run ConfigureCustom if present, but skip re-validation
 	// since Ask() already validated user input (or allowed explicit reset)
 	if o.ConfigureCustom != nil {
@@ -0,0 +1,128 @@
+package strategy
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	// AggregateVote answers with the response most samples agree on
+	AggregateVote = "vote"
+	// AggregateMerge combines the samples into a single response
+	AggregateMerge = "merge"
+	// AggregateBest answers with the sample a judge prefers
+	AggregateBest = "best"
+	// AggregateAll answers with every sample
+	AggregateAll = "all"
+
+	// MaxSamples bounds the number of responses a strategy may sample
+	MaxSamples = 10
+)
+
+// IsAggregate reports whether the value is one of the ways to aggregate samples
+func IsAggregate(value string) bool {
+	switch value {
+	case AggregateVote, AggregateMerge, AggregateBest, AggregateAll:
+		return true
+	}
+	return false
+}
+
+// Step is one strategy of a composed --strategy value with its parameters.
+type Step struct {
+	Name   string
+	Params map[string]string
+}
+
+// Plan is the combination of the steps of a --strategy value: the joined
+// prompts, and how many responses are sampled and aggregated.
+type Plan struct {
+	Prompt    string
+	Samples   int
+	Aggregate string
+}
+
+// ParseSpec parses strategies joined with "+", each optionally followed by
+// comma separated parameters, e.g. "cot+self-consistent:n=5,aggregate=merge".
+func ParseSpec(spec string) (ret []Step, err error) {
+	for part := range strings.SplitSeq(spec, "+") {
+		name, rawParams, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if name == "" {
+			return nil, fmt.Errorf(i18n.T("strategy_error_invalid_spec"), spec)
+		}
+		step := Step{Name: name, Params: map[string]string{}}
+		if rawParams != "" {
+			for param := range strings.SplitSeq(rawParams, ",") {
+				key, value, found := strings.Cut(param, "=")
+				if key = strings.TrimSpace(key); !found || key == "" {
+					return nil, fmt.Errorf(i18n.T("strategy_error_invalid_parameter"), param, name)
+				}
+				step.Params[key] = strings.TrimSpace(value)
+			}
+		}
+		ret = append(ret, step)
+	}
+	return
+}
+
+// Compose loads the strategies of the spec and combines them into a plan.
+// Prompts are joined in order, with {{parameters}} replaced by the values of
+// the spec or the defaults of the strategy. The n and aggregate parameters
+// override the samples and aggregate settings of the strategy.
+func Compose(spec string) (ret *Plan, err error) {
+	ret = &Plan{Samples: 1, Aggregate: AggregateVote}
+	if spec == "" {
+		return
+	}
+
+	var steps []Step
+	if steps, err = ParseSpec(spec); err != nil {
+		return nil, err
+	}
+	var prompts []string
+	for _, step := range steps {
+		var strategy *Strategy
+		if strategy, err = LoadStrategy(step.Name); err != nil {
+			return nil, err
+		}
+
+		params := maps.Clone(strategy.Parameters)
+		if params == nil {
+			params = map[string]string{}
+		}
+		maps.Copy(params, step.Params)
+
+		samples := strategy.Samples
+		if n, ok := params["n"]; ok {
+			if samples, err = strconv.Atoi(n); err != nil {
+				return nil, fmt.Errorf(i18n.T("strategy_error_invalid_samples"), n, step.Name)
+			}
+		}
+		if samples < 0 || samples > MaxSamples {
+			return nil, fmt.Errorf(i18n.T("strategy_error_invalid_samples"), strconv.Itoa(samples), step.Name)
+		}
+		ret.Samples = max(ret.Samples, samples)
+
+		if aggregate := cmp.Or(params["aggregate"], strategy.Aggregate); aggregate != "" {
+			if !IsAggregate(aggregate) {
+				return nil, fmt.Errorf(i18n.T("strategy_error_invalid_aggregate"), aggregate, step.Name)
+			}
+			ret.Aggregate = aggregate
+		}
+
+		prompt := strategy.Prompt
+		for key, value := range params {
+			prompt = strings.ReplaceAll(prompt, "{{"+key+"}}", value)
+		}
+		if prompt = strings.TrimSpace(prompt); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	ret.Prompt = strings.Join(prompts, "\n")
+	return
+}
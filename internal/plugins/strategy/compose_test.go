@@ -0,0 +1,94 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeStrategies(t *testing.T, strategies map[string]string) {
+	t.Helper()
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+
+	strategyDir := filepath.Join(homeDir, ".config", "fabric", "strategies")
+	if err := os.MkdirAll(strategyDir, 0o755); err != nil {
+		t.Fatalf("failed to create strategy dir: %v", err)
+	}
+	for name, content := range strategies {
+		if err := os.WriteFile(filepath.Join(strategyDir, name+".json"), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write strategy: %v", err)
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	steps, err := ParseSpec("cot + self-consistent:n=5, aggregate=merge")
+	if err != nil {
+		t.Fatalf("ParseSpec returned error: %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(steps))
+	}
+	if steps[0].Name != "cot" || len(steps[0].Params) != 0 {
+		t.Errorf("unexpected first step %+v", steps[0])
+	}
+	if steps[1].Name != "self-consistent" || steps[1].Params["n"] != "5" || steps[1].Params["aggregate"] != "merge" {
+		t.Errorf("unexpected second step %+v", steps[1])
+	}
+
+	for _, spec := range []string{"cot+", "+cot", "cot:n", "cot:=5"} {
+		if _, err := ParseSpec(spec); err == nil {
+			t.Errorf("expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestCompose(t *testing.T) {
+	writeStrategies(t, map[string]string{
+		"cod":  `{"prompt":"Drafts of {{words}} words.","parameters":{"words":"5"}}`,
+		"cot":  `{"prompt":"Think step by step."}`,
+		"self": `{"prompt":"Give a final answer.","samples":3,"aggregate":"vote"}`,
+	})
+
+	tests := []struct {
+		spec      string
+		prompt    string
+		samples   int
+		aggregate string
+		wantErr   bool
+	}{
+		{spec: "", prompt: "", samples: 1, aggregate: AggregateVote},
+		{spec: "cod", prompt: "Drafts of 5 words.", samples: 1, aggregate: AggregateVote},
+		{spec: "cod:words=3+cot", prompt: "Drafts of 3 words.\nThink step by step.", samples: 1, aggregate: AggregateVote},
+		{spec: "cot+self", prompt: "Think step by step.\nGive a final answer.", samples: 3, aggregate: AggregateVote},
+		{spec: "self:n=5,aggregate=merge", prompt: "Give a final answer.", samples: 5, aggregate: AggregateMerge},
+		{spec: "self:n=many", wantErr: true},
+		{spec: "self:n=11", wantErr: true},
+		{spec: "self:aggregate=average", wantErr: true},
+		{spec: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			plan, err := Compose(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got plan %+v", plan)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compose returned error: %v", err)
+			}
+			if plan.Prompt != tt.prompt {
+				t.Errorf("expected prompt %q, got %q", tt.prompt, plan.Prompt)
+			}
+			if plan.Samples != tt.samples {
+				t.Errorf("expected %d samples, got %d", tt.samples, plan.Samples)
+			}
+			if plan.Aggregate != tt.aggregate {
+				t.Errorf("expected aggregate %q, got %q", tt.aggregate, plan.Aggregate)
+			}
+		})
+	}
+}
@@ -57,6 +57,13 @@ type Strategy struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Prompt      string `json:"prompt"`
+	// Parameters holds the default values of the {{parameters}} of the prompt
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Samples is the number of responses sampled and aggregated, overridden by
+	// the n parameter
+	Samples int `json:"samples,omitempty"`
+	// Aggregate combines the samples: vote (default) or merge
+	Aggregate string `json:"aggregate,omitempty"`
 }
 
 func LoadAllFiles() (strategies map[string]Strategy, err error) {
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -20,6 +21,9 @@ type ExtensionExecutor struct {
 	registry *ExtensionRegistry
 }
 
+// argumentName matches the names of the named arguments of extensions
+var argumentName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // NewExtensionExecutor creates a new executor instance
 // It requires a registry to verify extensions
 func NewExtensionExecutor(registry *ExtensionRegistry) *ExtensionExecutor {
@@ -34,6 +38,12 @@ func NewExtensionExecutor(registry *ExtensionRegistry) *ExtensionExecutor {
 // value: the input value(s) for the operation
 // In extension_executor.go
 func (e *ExtensionExecutor) Execute(name, operation, value string) (string, error) {
+	return e.ExecuteInput(name, operation, value, "")
+}
+
+// ExecuteInput runs an extension as Execute does, writing the input, when not
+// empty, to its standard input
+func (e *ExtensionExecutor) ExecuteInput(name, operation, value, input string) (string, error) {
 	// Get and verify extension from registry
 	ext, err := e.registry.GetExtension(name)
 	if err != nil {
@@ -52,6 +62,10 @@ func (e *ExtensionExecutor) Execute(name, operation, value string) (string, erro
 		return "", errors.New(i18n.T("extension_empty_command"))
 	}
 
+	if ext.isWASM() {
+		return e.executeWASM(ext, cmdStr, input)
+	}
+
 	// Create command with the Executable and formatted arguments
 	cmd := exec.Command("sh", "-c", cmdStr)
 	//cmd := exec.Command(cmdParts[0], cmdParts[1:]...)
@@ -60,6 +74,9 @@ func (e *ExtensionExecutor) Execute(name, operation, value string) (string, erro
 	if len(ext.Env) > 0 {
 		cmd.Env = append(os.Environ(), ext.Env...)
 	}
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
 
 	// Execute based on output method
 	outputMethod := ext.GetOutputMethod()
@@ -83,10 +100,15 @@ func (e *ExtensionExecutor) formatCommand(ext *ExtensionDefinition, operation st
 	vars["operation"] = operation
 	vars["value"] = value
 
-	// Split on pipe for numbered variables
+	// Split on pipe for numbered variables, and named ones given as name=value
 	values := strings.Split(value, "|")
 	for i, val := range values {
 		vars[fmt.Sprintf("%d", i+1)] = val
+		if argName, argValue, ok := strings.Cut(val, "="); ok && argumentName.MatchString(argName) {
+			if _, reserved := vars[argName]; !reserved {
+				vars[argName] = argValue
+			}
+		}
 	}
 
 	return ApplyTemplate(opConfig.CmdTemplate, vars, "")
@@ -122,10 +144,12 @@ func (e *ExtensionExecutor) executeWithFile(cmd *exec.Cmd, ext *ExtensionDefinit
 	defer cancel()
 	// Store the original environment
 	originalEnv := cmd.Env
+	originalStdin := cmd.Stdin
 	// Create a new command with context. This might reset Env, depending on the Go version.
 	cmd = exec.CommandContext(ctx, cmd.Path, cmd.Args[1:]...)
 	// Restore the environment variables explicitly
 	cmd.Env = originalEnv
+	cmd.Stdin = originalStdin
 
 	fileConfig := ext.GetFileConfig()
 	if fileConfig == nil {
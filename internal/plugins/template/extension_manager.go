@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/danielmiessler/fabric/internal/i18n"
@@ -49,6 +50,7 @@ func (em *ExtensionManager) ListExtensions() error {
 		fmt.Printf("%s", i18n.T("extension_status_enabled"))
 		fmt.Printf(i18n.T("extension_executable_label"), ext.Executable)
 		fmt.Printf(i18n.T("extension_type_label"), ext.Type)
+		printRuntime(ext)
 		fmt.Printf(i18n.T("extension_timeout_label"), ext.Timeout)
 		fmt.Printf(i18n.T("extension_description_label"), ext.Description)
 		fmt.Printf(i18n.T("extension_version_label"), ext.Version)
@@ -102,6 +104,7 @@ func (em *ExtensionManager) RegisterExtension(configPath string) error {
 	fmt.Printf(i18n.T("extension_name_detail_label"), ext.Name)
 	fmt.Printf(i18n.T("extension_executable_label"), ext.Executable)
 	fmt.Printf(i18n.T("extension_type_label"), ext.Type)
+	printRuntime(&ext)
 	fmt.Printf(i18n.T("extension_timeout_label"), ext.Timeout)
 	fmt.Printf(i18n.T("extension_description_label"), ext.Description)
 	fmt.Printf(i18n.T("extension_version_label"), ext.Version)
@@ -122,6 +125,16 @@ func (em *ExtensionManager) RegisterExtension(configPath string) error {
 	return nil
 }
 
+// printRuntime prints the runtime of a WASM extension with its permissions
+func printRuntime(ext *ExtensionDefinition) {
+	if !ext.isWASM() {
+		return
+	}
+	fmt.Printf(i18n.T("extension_runtime_label"), RuntimeWASM)
+	fmt.Printf(i18n.T("extension_network_label"), strings.Join(ext.Permissions.Network, ", "))
+	fmt.Printf(i18n.T("extension_filesystem_label"), strings.Join(ext.Permissions.Filesystem, ", "))
+}
+
 // RemoveExtension handles the rmextension flag action
 func (em *ExtensionManager) RemoveExtension(name string) error {
 	if err := em.registry.Remove(name); err != nil {
@@ -135,3 +148,9 @@ func (em *ExtensionManager) RemoveExtension(name string) error {
 func (em *ExtensionManager) ProcessExtension(name, operation, value string) (string, error) {
 	return em.executor.Execute(name, operation, value)
 }
+
+// ProcessExtensionInput handles the extensions of pipelines, which read the
+// input on their standard input
+func (em *ExtensionManager) ProcessExtensionInput(name, operation, value, input string) (string, error) {
+	return em.executor.ExecuteInput(name, operation, value, input)
+}
@@ -27,6 +27,9 @@ type ExtensionDefinition struct {
 	Description string   `yaml:"description"`
 	Version     string   `yaml:"version"`
 	Env         []string `yaml:"env"`
+	// Runtime is exec, the default, or wasm for a WASI module run in a sandbox
+	Runtime     string               `yaml:"runtime"`
+	Permissions ExtensionPermissions `yaml:"permissions"`
 
 	// Operation-specific commands
 	Operations map[string]OperationConfig `yaml:"operations"`
@@ -186,7 +189,7 @@ func (r *ExtensionRegistry) validateExtensionDefinition(ext *ExtensionDefinition
 		}
 	}
 
-	return nil
+	return validateRuntime(ext)
 }
 
 func (r *ExtensionRegistry) Remove(name string) error {
@@ -0,0 +1,285 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	// RuntimeExec runs the executable of an extension as a local command
+	RuntimeExec = "exec"
+	// RuntimeWASM runs the executable of an extension, a WASI module, in a
+	// sandbox with only the permissions of its definition
+	RuntimeWASM = "wasm"
+
+	// wasmHostModule is the module of the host functions given to WASM extensions
+	wasmHostModule = "fabric"
+	// wasmDefaultTimeout bounds the WASM extensions without a timeout
+	wasmDefaultTimeout = 30 * time.Second
+	// wasmMaxResponse bounds the bodies fetched with http_get
+	wasmMaxResponse = 16 << 20
+)
+
+// Results of http_get besides the length of the body
+const (
+	wasmHTTPDenied = -1
+	wasmHTTPFailed = -2
+)
+
+// errRedirectDenied stops the redirects of http_get to hosts not allowed
+var errRedirectDenied = errors.New("redirect to a host not allowed")
+
+// ExtensionPermissions are the capabilities of a WASM extension, which has
+// none by default: no network, no file, no environment variable of fabric.
+type ExtensionPermissions struct {
+	// Network lists the hosts the module may fetch with fabric.http_get:
+	// "api.example.com", "*.example.com" for its subdomains, or "*" for any
+	Network []string `yaml:"network"`
+	// Filesystem lists the directories mounted in the module at the same
+	// path, read-only unless followed by ":rw"
+	Filesystem []string `yaml:"filesystem"`
+}
+
+// isWASM reports whether the extension runs in the WASM sandbox
+func (e *ExtensionDefinition) isWASM() bool {
+	return strings.EqualFold(e.Runtime, RuntimeWASM)
+}
+
+// validateRuntime checks the runtime of the extension and its permissions
+func validateRuntime(ext *ExtensionDefinition) error {
+	switch strings.ToLower(ext.Runtime) {
+	case "", RuntimeExec:
+		if len(ext.Permissions.Network) > 0 || len(ext.Permissions.Filesystem) > 0 {
+			return errors.New(i18n.T("extension_permissions_wasm_only"))
+		}
+	case RuntimeWASM:
+		if ext.GetOutputMethod() != "stdout" {
+			return errors.New(i18n.T("extension_wasm_stdout_only"))
+		}
+		for _, mount := range ext.Permissions.Filesystem {
+			if _, _, err := parseMount(mount); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf(i18n.T("extension_invalid_runtime"), ext.Runtime)
+	}
+	return nil
+}
+
+// parseMount returns the absolute directory of a filesystem permission and
+// whether the module may write to it
+func parseMount(mount string) (dir string, writable bool, err error) {
+	dir = mount
+	if trimmed, ok := strings.CutSuffix(mount, ":rw"); ok {
+		dir, writable = trimmed, true
+	} else if trimmed, ok := strings.CutSuffix(mount, ":ro"); ok {
+		dir = trimmed
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return
+	}
+	var info os.FileInfo
+	if info, err = os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false, fmt.Errorf(i18n.T("extension_invalid_mount"), mount)
+	}
+	return
+}
+
+// executeWASM runs the module of the extension with the arguments of the
+// command and the input on its standard input, returning what it writes to its
+// standard output
+func (e *ExtensionExecutor) executeWASM(ext *ExtensionDefinition, cmdStr, input string) (ret string, err error) {
+	if err = validateRuntime(ext); err != nil {
+		return
+	}
+	var module []byte
+	if module, err = os.ReadFile(ext.Executable); err != nil {
+		return "", fmt.Errorf(i18n.T("extension_executable_not_found"), err)
+	}
+
+	timeout := wasmDefaultTimeout
+	if ext.Timeout != "" {
+		if timeout, err = time.ParseDuration(ext.Timeout); err != nil {
+			return "", fmt.Errorf(i18n.T("extension_invalid_timeout_format"), err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	defer runtime.Close(context.Background())
+	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+	if _, err = runtime.NewHostModuleBuilder(wasmHostModule).
+		NewFunctionBuilder().WithFunc(httpGet(ext.Permissions.Network)).Export("http_get").
+		Instantiate(ctx); err != nil {
+		return
+	}
+
+	fsConfig := wazero.NewFSConfig()
+	for _, mount := range ext.Permissions.Filesystem {
+		dir, writable, _ := parseMount(mount)
+		if writable {
+			fsConfig = fsConfig.WithDirMount(dir, filepath.ToSlash(dir))
+		} else {
+			fsConfig = fsConfig.WithReadOnlyDirMount(dir, filepath.ToSlash(dir))
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	config := wazero.NewModuleConfig().
+		WithName(ext.Name).
+		WithArgs(splitArgs(cmdStr)...).
+		WithStdin(strings.NewReader(input)).
+		WithStdout(&stdout).
+		WithStderr(&stderr).
+		WithFSConfig(fsConfig).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+	for _, variable := range ext.Env {
+		if name, value, ok := strings.Cut(variable, "="); ok {
+			config = config.WithEnv(name, value)
+		}
+	}
+
+	if _, err = runtime.InstantiateWithConfig(ctx, module, config); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("%s", fmt.Sprintf(i18n.T("extension_execution_timed_out"), timeout))
+		}
+		var exitErr *sys.ExitError
+		if errors.As(err, &exitErr) {
+			err = fmt.Errorf(i18n.T("extension_wasm_exit_code"), exitErr.ExitCode())
+		}
+		return "", fmt.Errorf(i18n.T("extension_execution_failed_stderr"), err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// httpGet returns the host function fetching the URL of the string at
+// urlPtr, when its host is allowed, into the buffer at outPtr. It returns the
+// length of the body, only its first outCap bytes being written, or
+// wasmHTTPDenied or wasmHTTPFailed.
+func httpGet(allowed []string) func(ctx context.Context, module api.Module, urlPtr, urlLen, outPtr, outCap uint32) int32 {
+	client := networkClient(allowed)
+	return func(ctx context.Context, module api.Module, urlPtr, urlLen, outPtr, outCap uint32) int32 {
+		rawURL, ok := module.Memory().Read(urlPtr, urlLen)
+		if !ok {
+			return wasmHTTPFailed
+		}
+		target, err := url.Parse(string(rawURL))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			return wasmHTTPFailed
+		}
+		if !hostAllowed(target.Hostname(), allowed) {
+			return wasmHTTPDenied
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+		if err != nil {
+			return wasmHTTPFailed
+		}
+		resp, err := client.Do(req)
+		if errors.Is(err, errRedirectDenied) {
+			return wasmHTTPDenied
+		}
+		if err != nil {
+			return wasmHTTPFailed
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return wasmHTTPFailed
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, wasmMaxResponse))
+		if err != nil {
+			return wasmHTTPFailed
+		}
+		written := body
+		if uint32(len(written)) > outCap {
+			written = written[:outCap]
+		}
+		if !module.Memory().Write(outPtr, written) {
+			return wasmHTTPFailed
+		}
+		return int32(len(body))
+	}
+}
+
+// networkClient returns the client of http_get, which checks the host of
+// every redirect against the network permissions, so that an allowed host
+// cannot send the module elsewhere
+func networkClient(allowed []string) *http.Client {
+	return &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if (req.URL.Scheme != "http" && req.URL.Scheme != "https") || !hostAllowed(req.URL.Hostname(), allowed) {
+				return errRedirectDenied
+			}
+			return nil
+		},
+	}
+}
+
+// hostAllowed reports whether the network permissions allow the host
+func hostAllowed(host string, allowed []string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "*", pattern == host:
+			return true
+		case strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]):
+			return true
+		}
+	}
+	return false
+}
+
+// splitArgs splits a command into arguments at the spaces which are not
+// quoted, as a shell would, without expanding anything
+func splitArgs(command string) (ret []string) {
+	var current strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				ret = append(ret, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		ret = append(ret, current.String())
+	}
+	return
+}
@@ -0,0 +1,175 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtensionExecutorWASM(t *testing.T) {
+	tmpDir := t.TempDir()
+	module, err := filepath.Abs(filepath.Join("testdata", "fetch.wasm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "fetched "+r.URL.Path)
+	}))
+	defer server.Close()
+
+	registry := NewExtensionRegistry(tmpDir)
+	executor := NewExtensionExecutor(registry)
+	register := func(t *testing.T, name, network string) {
+		t.Helper()
+		configPath := filepath.Join(tmpDir, name+".yaml")
+		configContent := `name: ` + name + `
+executable: ` + module + `
+type: executable
+runtime: wasm
+permissions:
+  network: [` + network + `]
+operations:
+  echo:
+    cmd_template: "{{executable}} echo {{value}}"
+  fetch:
+    cmd_template: "{{executable}} fetch {{value}}"
+config:
+  output:
+    method: stdout`
+		if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+			t.Fatalf("Failed to create config: %v", err)
+		}
+		if err := registry.Register(configPath); err != nil {
+			t.Fatalf("Failed to register extension: %v", err)
+		}
+	}
+	register(t, "wasm-allowed", `"127.0.0.1"`)
+	register(t, "wasm-denied", "")
+
+	t.Run("Arguments", func(t *testing.T) {
+		output, err := executor.Execute("wasm-allowed", "echo", "hello")
+		if err != nil {
+			t.Fatalf("Failed to execute: %v", err)
+		}
+		if output != "hello" {
+			t.Errorf("Expected output %q, got %q", "hello", output)
+		}
+	})
+
+	t.Run("AllowedHost", func(t *testing.T) {
+		output, err := executor.Execute("wasm-allowed", "fetch", server.URL+"/page")
+		if err != nil {
+			t.Fatalf("Failed to execute: %v", err)
+		}
+		if output != "fetched /page" {
+			t.Errorf("Expected output %q, got %q", "fetched /page", output)
+		}
+	})
+
+	t.Run("DeniedHost", func(t *testing.T) {
+		_, err := executor.Execute("wasm-denied", "fetch", server.URL+"/page")
+		if err == nil || !strings.Contains(err.Error(), "code 1") {
+			t.Errorf("Expected the module to exit with code 1, got %v", err)
+		}
+	})
+}
+
+func TestValidateRuntime(t *testing.T) {
+	dir := t.TempDir()
+	stdout := map[string]any{"output": map[string]any{"method": "stdout"}}
+	file := map[string]any{"output": map[string]any{"method": "file"}}
+
+	tests := []struct {
+		name    string
+		ext     ExtensionDefinition
+		wantErr bool
+	}{
+		{"exec", ExtensionDefinition{Config: stdout}, false},
+		{"exec with permissions", ExtensionDefinition{Permissions: ExtensionPermissions{Network: []string{"*"}}}, true},
+		{"wasm", ExtensionDefinition{Runtime: RuntimeWASM, Config: stdout, Permissions: ExtensionPermissions{Filesystem: []string{dir + ":rw"}}}, false},
+		{"wasm with file output", ExtensionDefinition{Runtime: RuntimeWASM, Config: file}, true},
+		{"wasm with missing directory", ExtensionDefinition{Runtime: RuntimeWASM, Config: stdout, Permissions: ExtensionPermissions{Filesystem: []string{filepath.Join(dir, "missing")}}}, true},
+		{"unknown runtime", ExtensionDefinition{Runtime: "docker"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRuntime(&tt.ext); (err != nil) != tt.wantErr {
+				t.Errorf("validateRuntime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHostAllowed(t *testing.T) {
+	tests := []struct {
+		host    string
+		allowed []string
+		want    bool
+	}{
+		{"api.example.com", []string{"api.example.com"}, true},
+		{"API.example.com", []string{"api.example.com"}, true},
+		{"api.example.com", []string{"*.example.com"}, true},
+		{"example.com", []string{"*.example.com"}, false},
+		{"evil-example.com", []string{"*.example.com"}, false},
+		{"anything.org", []string{"*"}, true},
+		{"api.example.com", nil, false},
+	}
+	for _, tt := range tests {
+		if got := hostAllowed(tt.host, tt.allowed); got != tt.want {
+			t.Errorf("hostAllowed(%q, %v) = %v, want %v", tt.host, tt.allowed, got, tt.want)
+		}
+	}
+}
+
+func TestNetworkClientChecksRedirects(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "secret")
+	}))
+	defer internal.Close()
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/away" {
+			// The same server, under a host name not allowed
+			http.Redirect(w, r, strings.Replace(internal.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/here" {
+			http.Redirect(w, r, "/done", http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, "done")
+	}))
+	defer allowed.Close()
+
+	client := networkClient([]string{"127.0.0.1"})
+	resp, err := client.Get(allowed.URL + "/here")
+	if err != nil {
+		t.Fatalf("redirect to the same host: %v", err)
+	}
+	resp.Body.Close()
+	if _, err = client.Get(allowed.URL + "/away"); !errors.Is(err, errRedirectDenied) {
+		t.Errorf("redirect to another host error = %v, want errRedirectDenied", err)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"module.wasm echo hello", []string{"module.wasm", "echo", "hello"}},
+		{`module.wasm echo "hello world"`, []string{"module.wasm", "echo", "hello world"}},
+		{`module.wasm  'it''s' ""`, []string{"module.wasm", "its", ""}},
+	}
+	for _, tt := range tests {
+		if got := splitArgs(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
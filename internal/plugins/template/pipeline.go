@@ -0,0 +1,121 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// pipelineSeparator separates the stages of a pipeline. It is surrounded by
+// spaces so that the pipes separating the arguments of an extension are kept.
+const pipelineSeparator = " | "
+
+// pipelineStage is a stage of a pipeline, {{input | ext:name:operation:value | json:path}}
+type pipelineStage struct {
+	// kind is input, ext or json
+	kind string
+	// name, operation and value of an extension, or path of a JSON field
+	name, operation, value string
+}
+
+// parsePipeline returns the stages of the token when it is a pipeline: the
+// input or an extension, followed by extensions, which read the previous
+// stage on their standard input, and by JSON fields of the previous stage
+func parsePipeline(raw string) (ret []pipelineStage, ok bool) {
+	parts := strings.Split(raw, pipelineSeparator)
+	if len(parts) < 2 {
+		return nil, false
+	}
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		switch {
+		case i == 0 && (part == "input" || part == InputSentinel):
+			ret = append(ret, pipelineStage{kind: "input"})
+		case strings.HasPrefix(part, "ext:"):
+			name, operation, value, matched := matchTriple(extensionPattern, "{{"+part+"}}")
+			if !matched {
+				return nil, false
+			}
+			ret = append(ret, pipelineStage{kind: "ext", name: name, operation: operation, value: value})
+		case i > 0 && strings.HasPrefix(part, "json:"):
+			ret = append(ret, pipelineStage{kind: "json", name: strings.TrimPrefix(part, "json:")})
+		default:
+			return nil, false
+		}
+	}
+	return ret, true
+}
+
+// runPipeline runs the stages in turn and returns the output of the last one
+func runPipeline(stages []pipelineStage, input string) (ret string, err error) {
+	piped := false
+	for _, stage := range stages {
+		switch stage.kind {
+		case "input":
+			ret, piped = input, true
+		case "ext":
+			value := strings.ReplaceAll(stage.value, InputSentinel, input)
+			stdin := ""
+			if piped {
+				stdin = ret
+			}
+			debugf("Extension call: name=%s operation=%s value=%s stdin=%d bytes\n", stage.name, stage.operation, value, len(stdin))
+			if ret, err = extensionManager.ProcessExtensionInput(stage.name, stage.operation, value, stdin); err != nil {
+				return "", fmt.Errorf(i18n.T("template_extension_error"), stage.name, err)
+			}
+			piped = true
+		case "json":
+			if ret, err = jsonField(ret, stage.name); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// jsonField returns the field of the JSON document at the path, its keys and
+// array indexes separated by dots, e.g. items.0.title. Strings are returned as
+// they are, other values as JSON.
+func jsonField(document, path string) (ret string, err error) {
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(document))
+	decoder.UseNumber()
+	if err = decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf(i18n.T("template_invalid_json"), err)
+	}
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			continue
+		}
+		switch current := value.(type) {
+		case map[string]any:
+			var found bool
+			if value, found = current[key]; !found {
+				return "", fmt.Errorf(i18n.T("template_json_field_not_found"), path)
+			}
+		case []any:
+			index, convErr := strconv.Atoi(key)
+			if convErr != nil || index < 0 || index >= len(current) {
+				return "", fmt.Errorf(i18n.T("template_json_field_not_found"), path)
+			}
+			value = current[index]
+		default:
+			return "", fmt.Errorf(i18n.T("template_json_field_not_found"), path)
+		}
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	}
+	var data []byte
+	if data, err = json.Marshal(value); err != nil {
+		return
+	}
+	return string(data), nil
+}
@@ -0,0 +1,93 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyTemplatePipeline(t *testing.T) {
+	tmp := t.TempDir()
+	configDir := filepath.Join(tmp, ".config", "fabric")
+	extsDir := filepath.Join(configDir, "extensions")
+	if err := os.MkdirAll(extsDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	// The script answers with its standard input and its arguments as JSON
+	scriptPath := filepath.Join(extsDir, "wrap.sh")
+	script := "#!/bin/sh\nprintf '{\"stdin\":\"%s\",\"args\":[\"%s\",\"%s\"],\"count\":1000000}' \"$(cat)\" \"$1\" \"$2\"\n"
+	if err := os.WriteFile(scriptPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	configPath := filepath.Join(extsDir, "wrap.yaml")
+	configYAML := "" +
+		"name: wrap\n" +
+		"type: executable\n" +
+		"executable: " + scriptPath + "\n" +
+		"timeout: 5s\n" +
+		"operations:\n" +
+		"  json:\n" +
+		"    cmd_template: '{{executable}} {{lang}} {{2}}'\n" +
+		"config:\n" +
+		"  output:\n" +
+		"    method: stdout\n"
+	if err := os.WriteFile(configPath, []byte(configYAML), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	mgr := NewExtensionManager(configDir)
+	if err := mgr.RegisterExtension(configPath); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	prevMgr := extensionManager
+	extensionManager = mgr
+	defer func() { extensionManager = prevMgr }()
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{"input piped to stdin", "{{input | ext:wrap:json:lang=fr|second | json:stdin}}", "hello", false},
+		{"named and numbered arguments", "{{input | ext:wrap:json:lang=fr|second | json:args}}", `["fr","second"]`, false},
+		{"array index", "{{ext:wrap:json:lang=de|x | json:args.0}}", "de", false},
+		{"numbers kept", "{{ext:wrap:json:lang=de|x | json:count}}", "1000000", false},
+		{"input not JSON", "{{input | json:missing}}", "", true},
+		{"missing field", "{{ext:wrap:json:lang=de|x | json:nothing}}", "", true},
+		{"missing named argument", "{{input | ext:wrap:json:fr}}", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyTemplate(tt.template, nil, "hello")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ApplyTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePipeline(t *testing.T) {
+	tests := []struct {
+		raw    string
+		stages int
+		ok     bool
+	}{
+		{"input | ext:a:op:v | json:x", 3, true},
+		{"ext:a:op | json:x.0", 2, true},
+		{"ext:a:op:one | two", 0, false},
+		{"input", 0, false},
+		{"json:x | ext:a:op", 0, false},
+		{"name | json:x", 0, false},
+	}
+	for _, tt := range tests {
+		stages, ok := parsePipeline(tt.raw)
+		if ok != tt.ok || len(stages) != tt.stages {
+			t.Errorf("parsePipeline(%q) = %d stages, %v, want %d, %v", tt.raw, len(stages), ok, tt.stages, tt.ok)
+		}
+	}
+}
@@ -72,6 +72,17 @@ func ApplyTemplate(content string, variables map[string]string, input string) (s
 			full := m[0]
 			raw := m[1]
 
+			// Pipeline of the input, extensions and JSON fields
+			if stages, ok := parsePipeline(raw); ok {
+				result, err := runPipeline(stages, input)
+				if err != nil {
+					return "", err
+				}
+				content = strings.ReplaceAll(content, full, result)
+				progress = true
+				continue
+			}
+
 			// Extension call
 			if strings.HasPrefix(raw, "ext:") {
 				if name, operation, value, ok := matchTriple(extensionPattern, full); ok {
@@ -0,0 +1,31 @@
+;; fetch.wasm, the WASI module of the WASM extension tests, assembled from
+;; this source. Run as "fetch.wasm echo <text>" it writes the text; run as
+;; "fetch.wasm fetch <url>" it writes the body of the URL fetched with
+;; fabric.http_get, or exits with the negated error code.
+(module
+  (import "wasi_snapshot_preview1" "args_sizes_get" (func $args_sizes_get (param i32 i32) (result i32)))
+  (import "wasi_snapshot_preview1" "args_get" (func $args_get (param i32 i32) (result i32)))
+  (import "wasi_snapshot_preview1" "fd_write" (func $fd_write (param i32 i32 i32 i32) (result i32)))
+  (import "wasi_snapshot_preview1" "proc_exit" (func $proc_exit (param i32)))
+  (import "fabric" "http_get" (func $http_get (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  (func $start (export "_start")
+    (local $arg i32) (local $len i32) (local $n i32)
+    ;; argc at 0, size of the arguments at 4, argv at 16, arguments at 1024
+    (drop (call $args_sizes_get (i32.const 0) (i32.const 4)))
+    (drop (call $args_get (i32.const 16) (i32.const 1024)))
+    ;; the third argument is the last one, its length ends the buffer
+    (local.set $arg (i32.load offset=24 (i32.const 0)))
+    (local.set $len (i32.sub (i32.sub (i32.add (i32.load offset=4 (i32.const 0)) (i32.const 1024)) (local.get $arg)) (i32.const 1)))
+    (if (i32.eq (i32.load8_u (i32.load offset=20 (i32.const 0))) (i32.const 102))
+      (then
+        ;; the body is written at 9000, up to 4096 bytes
+        (local.set $n (call $http_get (local.get $arg) (local.get $len) (i32.const 9000) (i32.const 4096)))
+        (if (i32.lt_s (local.get $n) (i32.const 0))
+          (then (call $proc_exit (i32.sub (i32.const 0) (local.get $n)))))
+        (i32.store (i32.const 8192) (i32.const 9000))
+        (i32.store (i32.const 8196) (local.get $n)))
+      (else
+        (i32.store (i32.const 8192) (local.get $arg))
+        (i32.store (i32.const 8196) (local.get $len))))
+    (drop (call $fd_write (i32.const 1) (i32.const 8192) (i32.const 1) (i32.const 8200)))))
@@ -11,17 +11,18 @@ const APIKeyHeader = "X-API-Key"
 
 // APIKeyMiddleware validates API key for protected endpoints.
 // Swagger documentation endpoints (/swagger/*) are exempt from authentication
-// to allow users to browse and test the API documentation freely.
+// to allow users to browse and test the API documentation freely, and so are
+// the static files of the chat UI (/ui/*), which sends the key itself.
 func APIKeyMiddleware(apiKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip authentication for Swagger documentation endpoints
 		// This allows public access to API docs even when authentication is enabled
-		if strings.HasPrefix(c.Request.URL.Path, "/swagger/") {
+		if strings.HasPrefix(c.Request.URL.Path, "/swagger/") || isUIPath(c.Request.URL.Path) {
 			c.Next()
 			return
 		}
 
-		headerApiKey := c.GetHeader(APIKeyHeader)
+		headerApiKey := requestAPIKey(c)
 
 		if headerApiKey == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing API Key"})
@@ -36,3 +37,21 @@ func APIKeyMiddleware(apiKey string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// isUIPath tells whether the path is that of the chat UI
+func isUIPath(path string) bool {
+	return path == "/ui" || strings.HasPrefix(path, "/ui/")
+}
+
+// requestAPIKey returns the API key of the request, from X-API-Key or else
+// from the bearer token of the Authorization header that OpenAI clients send,
+// "" for the other schemes
+func requestAPIKey(c *gin.Context) string {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return key
+	}
+	if key, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); found {
+		return key
+	}
+	return ""
+}
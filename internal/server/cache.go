@@ -0,0 +1,117 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/redis/go-redis/v9"
+)
+
+// ResponseCache keeps the responses of identical requests for a while.
+type ResponseCache interface {
+	// Get returns the value of the key, found false once it expired
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// Set keeps the value of the key for the ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// NewResponseCache returns the cache of the --serve-cache value: memory for
+// one of the server process, or a redis:// or rediss:// URL of a Redis server
+// shared between servers.
+func NewResponseCache(spec string) (ResponseCache, error) {
+	if spec == "memory" {
+		return NewMemoryCache(maxMemoryCacheEntries), nil
+	}
+	if isRedisURL(spec) {
+		return NewRedisCache(spec)
+	}
+	return nil, fmt.Errorf(i18n.T("server_cache_invalid"), spec)
+}
+
+// maxMemoryCacheEntries bounds the memory of the responses kept in memory
+const maxMemoryCacheEntries = 1000
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// MemoryCache keeps the responses in memory, at most maxEntries of them, the
+// ones expiring first being dropped to make room.
+type MemoryCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryCache returns an empty cache of maxEntries responses.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{maxEntries: maxEntries, entries: map[string]memoryEntry{}}
+}
+
+func (o *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entry, found := o.entries[key]
+	if !found || time.Now().After(entry.expires) {
+		delete(o.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (o *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now()
+	if _, found := o.entries[key]; !found && len(o.entries) >= o.maxEntries {
+		for name, entry := range o.entries {
+			if now.After(entry.expires) {
+				delete(o.entries, name)
+			}
+		}
+		for len(o.entries) >= o.maxEntries {
+			first := ""
+			for name, entry := range o.entries {
+				if first == "" || entry.expires.Before(o.entries[first].expires) {
+					first = name
+				}
+			}
+			delete(o.entries, first)
+		}
+	}
+	o.entries[key] = memoryEntry{value: value, expires: now.Add(ttl)}
+	return nil
+}
+
+// RedisCache keeps the responses in a Redis server.
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache returns the cache of the Redis server of the URL,
+// redis://[user:password@]host[:port][/db], rediss:// connecting with TLS.
+func NewRedisCache(rawURL string) (*RedisCache, error) {
+	client, err := newRedisClient(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("server_cache_invalid"), rawURL)
+	}
+	return &RedisCache{client: client}, nil
+}
+
+func (o *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := o.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	return value, err == nil, err
+}
+
+func (o *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return o.client.Set(ctx, key, value, ttl).Err()
+}
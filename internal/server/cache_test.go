@@ -0,0 +1,160 @@
+package restapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(2)
+	_ = cache.Set(ctx, "expired", []byte("old"), -time.Second)
+	if _, found, _ := cache.Get(ctx, "expired"); found {
+		t.Error("an expired entry was found")
+	}
+
+	_ = cache.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = cache.Set(ctx, "b", []byte("2"), time.Hour)
+	// The entry expiring first makes room
+	_ = cache.Set(ctx, "c", []byte("3"), time.Hour)
+	if _, found, _ := cache.Get(ctx, "a"); found {
+		t.Error("the entry expiring first was kept")
+	}
+	for key, want := range map[string]string{"b": "2", "c": "3"} {
+		if value, found, _ := cache.Get(ctx, key); !found || string(value) != want {
+			t.Errorf("Get(%s) = %q, %v; want %q", key, value, found, want)
+		}
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+
+	cache, err := NewRedisCache("redis://:secret@" + server.Addr() + "/2")
+	if err != nil {
+		t.Fatalf("NewRedisCache() error = %v", err)
+	}
+	if _, found, err := cache.Get(ctx, "missing"); found || err != nil {
+		t.Errorf("Get(missing) = %v, %v; want not found", found, err)
+	}
+	if err = cache.Set(ctx, "key", []byte("line 1\r\nline 2"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if value, found, err := cache.Get(ctx, "key"); !found || err != nil || string(value) != "line 1\r\nline 2" {
+		t.Errorf("Get(key) = %q, %v, %v", value, found, err)
+	}
+	// The value is kept in the database of the URL, expiring with the ttl
+	server.Select(2)
+	if !server.Exists("key") || server.TTL("key") != time.Minute {
+		t.Errorf("the value is not in the database 2 with its ttl: %v", server.Keys())
+	}
+
+	cache, _ = NewRedisCache("redis://:wrong@" + server.Addr())
+	if _, _, err = cache.Get(ctx, "key"); err == nil {
+		t.Error("expected an error with the wrong password")
+	}
+
+	for _, spec := range []string{"redis://", "redis://localhost/db", "memcached://localhost", "disk"} {
+		if _, err = NewResponseCache(spec); err == nil {
+			t.Errorf("NewResponseCache(%q) expected an error", spec)
+		}
+	}
+}
+
+func TestChatHandlerCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("MOCK_LATENCY", "0")
+	db := fsdb.NewDb(t.TempDir())
+	registry, err := core.NewPluginRegistry(db)
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	registry.ConfigureVendors()
+	r := gin.New()
+	NewChatHandler(r, registry, db, NewRequestQueue(0), &ChatCache{Cache: NewMemoryCache(10), TTL: time.Hour}, nil)
+
+	// The chat handler needs a connection telling when the client is gone
+	server := httptest.NewServer(r)
+	defer server.Close()
+	post := func(body string, headers map[string]string) *httptest.ResponseRecorder {
+		request, _ := http.NewRequest(http.MethodPost, server.URL+"/chat", strings.NewReader(body))
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("POST /chat error = %v", err)
+		}
+		defer response.Body.Close()
+		w := httptest.NewRecorder()
+		w.Code = response.StatusCode
+		for name, values := range response.Header {
+			w.Header()[name] = values
+		}
+		_, _ = io.Copy(w.Body, response.Body)
+		return w
+	}
+	body := `{"prompts":[{"userInput":"hello","vendor":"Mock","model":"echo"}]}`
+
+	first := post(body, nil)
+	if first.Header().Get("X-Cache") != "MISS" || !strings.Contains(first.Body.String(), "hello") {
+		t.Fatalf("first response = %v %s", first.Header(), first.Body.String())
+	}
+
+	second := post(body, nil)
+	etag := second.Header().Get("ETag")
+	if second.Header().Get("X-Cache") != "HIT" || etag == "" || sseData(second.Body.String()) != sseData(first.Body.String()) {
+		t.Fatalf("second response = %v %s, want the cached first one", second.Header(), second.Body.String())
+	}
+	if got := second.Header().Get("Cache-Control"); !strings.HasPrefix(got, "private, max-age=") {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	if w := post(body, map[string]string{"If-None-Match": etag}); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("If-None-Match = %d %s, want 304 without a body", w.Code, w.Body.String())
+	}
+	if w := post(body, map[string]string{"Cache-Control": "no-cache"}); w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("Cache-Control: no-cache was answered from the cache")
+	}
+	if w := post(body, map[string]string{"Cache-Control": "no-store"}); w.Header().Get("X-Cache") != "" {
+		t.Errorf("Cache-Control: no-store used the cache")
+	}
+
+	// Other payloads, sessions and errors are not answered from the cache
+	if w := post(`{"prompts":[{"userInput":"bye","vendor":"Mock","model":"echo"}]}`, nil); w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("another payload was answered from the cache")
+	}
+	session := `{"prompts":[{"userInput":"hello","vendor":"Mock","model":"echo","sessionName":"s"}]}`
+	post(session, nil)
+	if w := post(session, nil); w.Header().Get("X-Cache") != "" || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("a session was cached: %v", w.Header())
+	}
+	failing := `{"prompts":[{"userInput":"hello","vendor":"Nope","model":"nope"}]}`
+	post(failing, nil)
+	if w := post(failing, nil); w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("an error was cached")
+	}
+}
+
+// sseData returns the data of the events, without their IDs
+func sseData(body string) string {
+	var data []string
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "data: ") {
+			data = append(data, line)
+		}
+	}
+	return strings.Join(data, "\n")
+}
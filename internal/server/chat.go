@@ -1,11 +1,13 @@
 package restapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 
@@ -19,6 +21,17 @@ import (
 type ChatHandler struct {
 	registry *core.PluginRegistry
 	db       *fsdb.Db
+	queue    *RequestQueue
+	cache    *ChatCache
+	// sessions, when not nil, are shared by the replicas of the server, and
+	// copied to the local files for the chatter
+	sessions SessionStore
+	// streams are the chats clients can resume
+	streams *streamStore
+	// heartbeat is how often streams get a comment while the model is silent,
+	// and resumeGrace how long chats wait for a client resuming them
+	heartbeat   time.Duration
+	resumeGrace time.Duration
 }
 
 type PromptRequest struct {
@@ -30,6 +43,8 @@ type PromptRequest struct {
 	StrategyName string            `json:"strategyName"`        // Optional strategy name
 	SessionName  string            `json:"sessionName"`         // Session name for multi-turn conversations
 	Variables    map[string]string `json:"variables,omitempty"` // Pattern variables
+	// Context variables, with comma separated context names joined in order
+	ContextVariables map[string]string `json:"contextVariables,omitempty"`
 }
 
 type ChatRequest struct {
@@ -46,13 +61,22 @@ type StreamResponse struct {
 	Usage   *domain.UsageMetadata `json:"usage,omitempty"`
 }
 
-func NewChatHandler(r *gin.Engine, registry *core.PluginRegistry, db *fsdb.Db) *ChatHandler {
+// NewChatHandler serves /chat, answering identical requests from the cache
+// when it is not nil, and keeping the sessions in the store when it is not nil.
+func NewChatHandler(r *gin.Engine, registry *core.PluginRegistry, db *fsdb.Db, queue *RequestQueue, cache *ChatCache, sessions SessionStore) *ChatHandler {
 	handler := &ChatHandler{
-		registry: registry,
-		db:       db,
+		registry:    registry,
+		db:          db,
+		queue:       queue,
+		cache:       cache,
+		sessions:    sessions,
+		streams:     newStreamStore(),
+		heartbeat:   sseHeartbeat,
+		resumeGrace: streamResumeGrace,
 	}
 
 	r.POST("/chat", handler.HandleChat)
+	r.GET("/chat/streams/:id", handler.ResumeChat)
 
 	return handler
 }
@@ -64,7 +88,10 @@ func NewChatHandler(r *gin.Engine, registry *core.PluginRegistry, db *fsdb.Db) *
 // @Accept json
 // @Produce text/event-stream
 // @Param request body ChatRequest true "Chat request with prompts and options"
+// @Param If-None-Match header string false "ETag of a cached response the client has"
+// @Param Cache-Control header string false "no-cache to skip the cached response, no-store to bypass the cache"
 // @Success 200 {object} StreamResponse "Streaming response"
+// @Success 304 "The cached response has the ETag of If-None-Match"
 // @Failure 400 {object} map[string]string
 // @Security ApiKeyAuth
 // @Router /chat [post]
@@ -72,118 +99,152 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 	var request ChatRequest
 
 	if err := c.BindJSON(&request); err != nil {
-		log.Printf("Error binding JSON: %v", err)
+		slog.Warn("Invalid chat request", "error", err)
 		c.Writer.Header().Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_invalid_request_format"), err)})
 		return
 	}
 
-	// Add log to check received language field
-	log.Printf("Received chat request - Language: '%s', Prompts: %d", request.Language, len(request.Prompts))
+	slog.Info("Received chat request", "language", request.Language, "prompts", len(request.Prompts))
 
-	// Set headers for SSE
-	c.Writer.Header().Set("Content-Type", "text/readystream")
-	c.Writer.Header().Set("Cache-Control", "no-cache")
-	c.Writer.Header().Set("Connection", "keep-alive")
-	c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
-	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	setSSEHeaders(c)
+
+	// Identical requests without sessions are answered from the cache
+	cacheKey := h.cacheKey(c, &request)
+	if cacheKey != "" && h.serveCached(c, cacheKey) {
+		return
+	}
+
+	// The chat outlives the request, so that clients losing the stream can
+	// resume it from GET /chat/streams/:id
+	ctx, cancel := context.WithCancel(context.Background())
+	client := requestClient(c)
+	stream := newChatStream(client, cancel)
+	h.streams.add(stream)
+	c.Writer.Header().Set("X-Stream-ID", stream.ID)
+
+	go h.produce(ctx, client, &request, stream, cacheKey)
+	h.follow(c, stream, 0)
+}
 
-	clientGone := c.Writer.CloseNotify()
+// produce runs the prompts of the request, adding their responses to the
+// stream, and caches them under the key when it is not empty
+func (h *ChatHandler) produce(ctx context.Context, client string, request *ChatRequest, stream *chatStream, cacheKey string) {
+	defer h.streams.expire(stream)
+	defer stream.finish()
+	defer stream.cancel()
 
 	for i, prompt := range request.Prompts {
-		select {
-		case <-clientGone:
-			log.Printf("Client disconnected")
+		if ctx.Err() != nil {
+			slog.Info("Client disconnected")
 			return
-		default:
-			log.Printf("Processing prompt %d: Model=%s Pattern=%s Context=%s",
-				i+1, prompt.Model, prompt.PatternName, prompt.ContextName)
-
-			streamChan := make(chan domain.StreamUpdate)
-
-			go func(p PromptRequest) {
-				defer close(streamChan)
-
-				chatter, err := h.registry.GetChatter(
-					p.Model,
-					request.ModelContextLength,
-					p.Vendor,
-					true,
-					false,
-				)
-				if err != nil {
-					log.Printf("Error creating chatter: %v", err)
-					streamChan <- domain.StreamUpdate{Type: domain.StreamTypeError, Content: fmt.Sprintf(i18n.T("server_chat_error"), err)}
-					return
-				}
-
-				chatReq := buildPromptChatRequest(p, request.Language)
-
-				opts := &domain.ChatOptions{
-					Model:            p.Model,
-					Temperature:      request.Temperature,
-					TopP:             request.TopP,
-					FrequencyPenalty: request.FrequencyPenalty,
-					PresencePenalty:  request.PresencePenalty,
-					Thinking:         request.Thinking,
-					Search:           request.Search,
-					SearchLocation:   request.SearchLocation,
-					UpdateChan:       streamChan,
-					Quiet:            true,
-				}
-
-				_, err = chatter.Send(c.Request.Context(), chatReq, opts)
-				if err != nil {
-					log.Printf("Error from chatter.Send: %v", err)
-					// Error already sent to streamChan via domain.StreamTypeError if occurred in Send loop
-					return
-				}
-			}(prompt)
-
-			for update := range streamChan {
-				select {
-				case <-clientGone:
-					return
-				default:
-					var response StreamResponse
-					switch update.Type {
-					case domain.StreamTypeContent:
-						response = StreamResponse{
-							Type:    "content",
-							Format:  detectFormat(update.Content),
-							Content: update.Content,
-						}
-					case domain.StreamTypeUsage:
-						response = StreamResponse{
-							Type:  "usage",
-							Usage: update.Usage,
-						}
-					case domain.StreamTypeError:
-						response = StreamResponse{
-							Type:    "error",
-							Format:  "plain",
-							Content: update.Content,
-						}
-					}
-
-					if err := writeSSEResponse(c.Writer, response); err != nil {
-						log.Printf("Error writing response: %v", err)
-						return
-					}
-				}
+		}
+		slog.Info("Processing prompt", "prompt", i+1, "model", prompt.Model,
+			"pattern", prompt.PatternName, "context", prompt.ContextName)
+
+		streamChan := make(chan domain.StreamUpdate)
+
+		go func(p PromptRequest) {
+			defer close(streamChan)
+			if _, err := h.sendPrompt(ctx, client, request, p, streamChan, nil); err != nil {
+				slog.Error("Chat failed", "error", err)
 			}
+		}(prompt)
 
-			completeResponse := StreamResponse{
-				Type:    "complete",
-				Format:  "plain",
-				Content: "",
+		// The updates are read to the end, so that the sender never blocks
+		for update := range streamChan {
+			switch update.Type {
+			case domain.StreamTypeContent:
+				stream.add(StreamResponse{
+					Type:    "content",
+					Format:  detectFormat(update.Content),
+					Content: update.Content,
+				})
+			case domain.StreamTypeUsage:
+				stream.add(StreamResponse{
+					Type:  "usage",
+					Usage: update.Usage,
+				})
+			case domain.StreamTypeError:
+				stream.add(StreamResponse{
+					Type:    "error",
+					Format:  "plain",
+					Content: update.Content,
+				})
 			}
-			if err := writeSSEResponse(c.Writer, completeResponse); err != nil {
-				log.Printf("Error writing completion response: %v", err)
-				return
+		}
+
+		stream.add(StreamResponse{
+			Type:    "complete",
+			Format:  "plain",
+			Content: "",
+		})
+	}
+	if cacheKey != "" && ctx.Err() == nil {
+		events, _, _ := stream.since(0)
+		h.storeCached(ctx, cacheKey, events)
+	}
+}
+
+// sendPrompt sends the prompt once the queue lets the client run a request,
+// calling started if not nil, and returns the response. The response is
+// streamed to updates unless it is nil, errors creating the chatter included.
+func (h *ChatHandler) sendPrompt(ctx context.Context, client string, request *ChatRequest, p PromptRequest, updates chan domain.StreamUpdate, started func()) (message string, err error) {
+	var release func()
+	if release, err = h.queue.Acquire(ctx, client); err != nil {
+		return
+	}
+	defer release()
+	if started != nil {
+		started()
+	}
+
+	chatter, err := h.registry.GetChatter(p.Model, request.ModelContextLength, p.Vendor, updates != nil, false)
+	if err != nil {
+		slog.Error("Creating the chatter failed", "model", p.Model, "vendor", p.Vendor, "error", err)
+		if updates != nil {
+			updates <- domain.StreamUpdate{Type: domain.StreamTypeError, Content: fmt.Sprintf(i18n.T("server_chat_error"), err)}
+		}
+		return
+	}
+
+	opts := &domain.ChatOptions{
+		Model:            p.Model,
+		Temperature:      request.Temperature,
+		TopP:             request.TopP,
+		FrequencyPenalty: request.FrequencyPenalty,
+		PresencePenalty:  request.PresencePenalty,
+		Thinking:         request.Thinking,
+		Search:           request.Search,
+		SearchLocation:   request.SearchLocation,
+		UpdateChan:       updates,
+		Quiet:            true,
+	}
+
+	shared := h.sessions != nil && p.SessionName != ""
+	if shared {
+		if err = pullSession(ctx, h.sessions, h.db.Sessions, p.SessionName); err != nil {
+			slog.Error("Loading the session failed", "session", p.SessionName, "error", err)
+			if updates != nil {
+				updates <- domain.StreamUpdate{Type: domain.StreamTypeError, Content: fmt.Sprintf(i18n.T("server_chat_error"), err)}
 			}
+			return
+		}
+	}
+
+	// Errors of the Send loop are streamed to updates by Send itself
+	session, err := chatter.Send(ctx, buildPromptChatRequest(p, request.Language), opts)
+	if err == nil && session != nil {
+		if last := session.GetLastMessage(); last != nil {
+			message = last.Content
+		}
+	}
+	if shared && err == nil {
+		if err = pushSession(ctx, h.sessions, h.db.Sessions, p.SessionName); err != nil {
+			slog.Error("Saving the session failed", "session", p.SessionName, "error", err)
 		}
 	}
+	return
 }
 
 func buildPromptChatRequest(p PromptRequest, language string) *domain.ChatRequest {
@@ -194,6 +255,7 @@ func buildPromptChatRequest(p PromptRequest, language string) *domain.ChatReques
 		},
 		PatternName:      p.PatternName,
 		ContextName:      p.ContextName,
+		ContextVariables: p.ContextVariables,
 		SessionName:      p.SessionName,
 		PatternVariables: p.Variables,
 		StrategyName:     p.StrategyName,
@@ -201,12 +263,35 @@ func buildPromptChatRequest(p PromptRequest, language string) *domain.ChatReques
 	}
 }
 
+// setSSEHeaders sets the headers of the event streams
+func setSSEHeaders(c *gin.Context) {
+	c.Writer.Header().Set("Content-Type", "text/readystream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	// The development server of the web UI, unless --cors-origin chose the origins
+	if c.Writer.Header().Get("Access-Control-Allow-Origin") == "" {
+		c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
+	}
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+}
+
 func writeSSEResponse(w gin.ResponseWriter, response StreamResponse) error {
+	return writeSSEEvent(w, "", response)
+}
+
+// writeSSEEvent writes the response as an event with the ID, if not empty, for
+// the Last-Event-ID of clients resuming the stream
+func writeSSEEvent(w gin.ResponseWriter, id string, response StreamResponse) error {
 	data, err := json.Marshal(response)
 	if err != nil {
 		return fmt.Errorf("%s", fmt.Sprintf(i18n.T("server_error_marshaling_response"), err))
 	}
 
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return fmt.Errorf("%s", fmt.Sprintf(i18n.T("server_error_writing_response"), err))
+		}
+	}
 	if _, err := fmt.Fprintf(w, "data: %s\n\n", string(data)); err != nil {
 		return fmt.Errorf("%s", fmt.Sprintf(i18n.T("server_error_writing_response"), err))
 	}
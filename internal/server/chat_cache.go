@@ -0,0 +1,138 @@
+package restapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// chatCachePrefix namespaces the keys of the chat responses in a shared cache
+const chatCachePrefix = "fabric:chat:"
+
+// ChatCache answers the chat requests identical to an earlier one with its
+// response, until the TTL is over.
+type ChatCache struct {
+	Cache ResponseCache
+	TTL   time.Duration
+}
+
+// cachedChat is the response of a chat request kept in the cache
+type cachedChat struct {
+	ETag      string           `json:"etag"`
+	Created   time.Time        `json:"created"`
+	Responses []StreamResponse `json:"responses"`
+}
+
+// cacheable tells whether the response of the request only depends on its
+// payload, prompts of sessions depending on the messages before them
+func (o *ChatRequest) cacheable() bool {
+	return len(o.Prompts) > 0 && !slices.ContainsFunc(o.Prompts, func(p PromptRequest) bool { return p.SessionName != "" })
+}
+
+// cacheKey returns the cache key of the request, the hash of its payload, or
+// "" when it is not cached, the client asking it with Cache-Control: no-store
+func (h *ChatHandler) cacheKey(c *gin.Context, request *ChatRequest) string {
+	if h.cache == nil || !request.cacheable() || cacheDirective(c.Request, "no-store") {
+		return ""
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(payload)
+	return chatCachePrefix + hex.EncodeToString(sum[:])
+}
+
+// cacheDirective tells whether the Cache-Control header of the request has
+// the directive
+func cacheDirective(r *http.Request, directive string) bool {
+	for _, value := range r.Header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serveCached writes the cached response of the key, or 304 Not Modified when
+// it has the ETag of If-None-Match, and tells whether it did. With
+// Cache-Control: no-cache the request is sent again, its response replacing
+// the cached one.
+func (h *ChatHandler) serveCached(c *gin.Context, key string) bool {
+	c.Writer.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(h.cache.TTL.Seconds())))
+	c.Writer.Header().Set("X-Cache", "MISS")
+	if cacheDirective(c.Request, "no-cache") || cacheDirective(c.Request, "max-age=0") {
+		return false
+	}
+
+	value, found, err := h.cache.Cache.Get(c.Request.Context(), key)
+	if err != nil {
+		slog.Warn("Reading the response cache failed", "error", err)
+		return false
+	}
+	var cached cachedChat
+	if !found || json.Unmarshal(value, &cached) != nil {
+		return false
+	}
+
+	age := max(time.Since(cached.Created), 0)
+	c.Writer.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", max(int((h.cache.TTL-age).Seconds()), 0)))
+	c.Writer.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+	c.Writer.Header().Set("ETag", cached.ETag)
+	c.Writer.Header().Set("X-Cache", "HIT")
+	if matchesETag(c.GetHeader("If-None-Match"), cached.ETag) {
+		c.Writer.Header().Del("Content-Type")
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	for _, response := range cached.Responses {
+		if err = writeSSEResponse(c.Writer, response); err != nil {
+			slog.Error("Writing the cached response failed", "error", err)
+			break
+		}
+	}
+	return true
+}
+
+// matchesETag tells whether the If-None-Match header lists the ETag
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// storeCached keeps the responses of the key, unless one of them is an error
+func (h *ChatHandler) storeCached(ctx context.Context, key string, responses []StreamResponse) {
+	if len(responses) == 0 || slices.ContainsFunc(responses, func(r StreamResponse) bool { return r.Type == "error" }) {
+		return
+	}
+	body, err := json.Marshal(responses)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(body)
+	cached := cachedChat{ETag: `"` + hex.EncodeToString(sum[:16]) + `"`, Created: time.Now(), Responses: responses}
+	var value []byte
+	if value, err = json.Marshal(cached); err != nil {
+		return
+	}
+	if err = h.cache.Cache.Set(ctx, key, value, h.cache.TTL); err != nil {
+		slog.Warn("Writing the response cache failed", "error", err)
+	}
+}
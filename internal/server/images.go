@@ -0,0 +1,203 @@
+package restapi
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// imageVendor generates the images, with its image_generation tool as for
+	// --image-file
+	imageVendor = "OpenAI"
+	// defaultImageModel runs the image_generation tool when the request names
+	// an image model, such as gpt-image-1, rather than one of the chat models
+	// supporting the tool
+	defaultImageModel = "gpt-5-nano"
+	// maxImages bounds the images of a request, each one being a model call
+	maxImages = 4
+)
+
+// ImagesHandler generates images with the OpenAI-compatible endpoint, keeping
+// them in a gallery listed by /images.
+type ImagesHandler struct {
+	registry *core.PluginRegistry
+	images   *fsdb.ImagesEntity
+}
+
+// ImageRequest is the body of POST /v1/images/generations, as sent by OpenAI
+// clients
+type ImageRequest struct {
+	Prompt         string `json:"prompt" binding:"required"`                    // Description of the image
+	Model          string `json:"model,omitempty" example:"gpt-5-nano"`         // Chat model running the image_generation tool
+	N              int    `json:"n,omitempty" example:"1"`                      // Number of images, 1 to 4
+	Size           string `json:"size,omitempty" example:"1024x1024"`           // 1024x1024, 1536x1024, 1024x1536 or auto
+	Quality        string `json:"quality,omitempty" example:"auto"`             // low, medium, high or auto
+	Background     string `json:"background,omitempty" example:"opaque"`        // opaque or transparent
+	OutputFormat   string `json:"output_format,omitempty" example:"png"`        // png (default), jpeg or webp
+	ResponseFormat string `json:"response_format,omitempty" example:"b64_json"` // b64_json (default) or url
+}
+
+// ImageData is a generated image, with the URL of the gallery to get it from
+type ImageData struct {
+	B64JSON string `json:"b64_json,omitempty"`
+	URL     string `json:"url,omitempty"`
+	// Name is the name of the image in the gallery
+	Name string `json:"name"`
+}
+
+// ImageResponse is the response of POST /v1/images/generations
+type ImageResponse struct {
+	Created int64       `json:"created"`
+	Data    []ImageData `json:"data"`
+}
+
+func NewImagesHandler(r *gin.Engine, registry *core.PluginRegistry, images *fsdb.ImagesEntity) *ImagesHandler {
+	handler := &ImagesHandler{registry: registry, images: images}
+	r.POST("/v1/images/generations", handler.Generate)
+	r.GET("/images", handler.List)
+	r.GET("/images/:name", handler.Get)
+	return handler
+}
+
+// Generate godoc
+// @Summary Generate images
+// @Description Generate images with the image_generation tool of OpenAI, as --image-file does, OpenAI-compatible. The images are kept in the gallery of /images.
+// @Tags images
+// @Accept json
+// @Produce json
+// @Param request body ImageRequest true "Prompt and image options"
+// @Success 200 {object} ImageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /v1/images/generations [post]
+func (h *ImagesHandler) Generate(c *gin.Context) {
+	var request ImageRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_invalid_request_format"), err)})
+		return
+	}
+	if request.N == 0 {
+		request.N = 1
+	}
+	if request.N < 0 || request.N > maxImages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_images_invalid_n"), maxImages)})
+		return
+	}
+	extension, ok := map[string]string{"": ".png", "png": ".png", "jpeg": ".jpeg", "webp": ".webp"}[request.OutputFormat]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_images_invalid_format"), request.OutputFormat)})
+		return
+	}
+	if request.ResponseFormat != "" && request.ResponseFormat != "b64_json" && request.ResponseFormat != "url" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_images_invalid_response_format"), request.ResponseFormat)})
+		return
+	}
+	model := request.Model
+	if !slices.Contains(openai.ImageGenerationSupportedModels, model) {
+		model = defaultImageModel
+	}
+	vendor := h.registry.VendorManager.FindByName(imageVendor)
+	if vendor == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("vendor_not_configured"), imageVendor)})
+		return
+	}
+
+	response := ImageResponse{Created: time.Now().Unix(), Data: []ImageData{}}
+	for range request.N {
+		name, path, err := h.images.NewPath(extension)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		var text string
+		if text, err = vendor.Send(c.Request.Context(), []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: request.Prompt}},
+			&domain.ChatOptions{Model: model, ImageFile: path, ImageSize: request.Size, ImageQuality: request.Quality,
+				ImageBackground: request.Background, Quiet: true}); err != nil {
+			slog.Error("Generating the image failed", "model", model, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		var image []byte
+		if image, err = os.ReadFile(path); err != nil {
+			// The model answered without calling the image_generation tool
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf(i18n.T("server_images_none_generated"), text)})
+			return
+		}
+		data := ImageData{Name: name}
+		if request.ResponseFormat == "url" {
+			data.URL = h.imageURL(c, name)
+		} else {
+			data.B64JSON = base64.StdEncoding.EncodeToString(image)
+		}
+		response.Data = append(response.Data, data)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// imageURL returns the URL of the image in the gallery, under the base path
+// of the request
+func (h *ImagesHandler) imageURL(c *gin.Context, name string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	// The request URI keeps the --base-path the router does not see
+	path, _, _ := strings.Cut(c.Request.RequestURI, "?")
+	base := strings.TrimSuffix(path, "/v1/images/generations")
+	return scheme + "://" + c.Request.Host + base + "/images/" + name
+}
+
+// List godoc
+// @Summary List the generated images
+// @Description List the images of the gallery, the newest first
+// @Tags images
+// @Produce json
+// @Success 200 {array} fsdb.ImageInfo
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /images [get]
+func (h *ImagesHandler) List(c *gin.Context) {
+	images, err := h.images.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, images)
+}
+
+// Get godoc
+// @Summary Get a generated image
+// @Description Get an image of the gallery
+// @Tags images
+// @Produce image/png
+// @Produce image/jpeg
+// @Produce image/webp
+// @Param name path string true "Image name"
+// @Success 200 {file} binary "Image"
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /images/{name} [get]
+func (h *ImagesHandler) Get(c *gin.Context) {
+	path, err := h.images.Path(c.Param("name"))
+	if errors.Is(err, os.ErrNotExist) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf(i18n.T("server_image_not_found"), c.Param("name"))})
+		return
+	}
+	c.File(path)
+}
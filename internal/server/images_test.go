@@ -0,0 +1,99 @@
+package restapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/mock"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeImager is an OpenAI vendor saving the prompt and the model as the image
+// of --image-file, unless the prompt asks for none
+type fakeImager struct {
+	*mock.Client
+}
+
+func (o *fakeImager) GetName() string {
+	return "OpenAI"
+}
+
+func (o *fakeImager) Send(_ context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (string, error) {
+	if msgs[0].Content == "no image" {
+		return "I cannot draw that", nil
+	}
+	return "", os.WriteFile(opts.ImageFile, []byte(msgs[0].Content+"|"+opts.Model+"|"+opts.ImageSize), 0o644)
+}
+
+func TestImagesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := fsdb.NewDb(t.TempDir())
+	registry, err := core.NewPluginRegistry(db)
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	registry.VendorManager.AddVendors(&fakeImager{Client: mock.NewClient()})
+	r := gin.New()
+	NewImagesHandler(r, registry, db.Images)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := serve(http.MethodPost, "/v1/images/generations", `{"prompt":"a cat","model":"gpt-image-1","size":"1024x1024","n":2}`)
+	var response ImageResponse
+	if err = json.Unmarshal(w.Body.Bytes(), &response); w.Code != http.StatusOK || err != nil || len(response.Data) != 2 {
+		t.Fatalf("POST /v1/images/generations = %d %s", w.Code, w.Body.String())
+	}
+	// gpt-image-1 is drawn through the default model of the tool
+	image, _ := base64.StdEncoding.DecodeString(response.Data[0].B64JSON)
+	if string(image) != "a cat|"+defaultImageModel+"|1024x1024" || !strings.HasSuffix(response.Data[0].Name, ".png") {
+		t.Errorf("image = %q named %s", image, response.Data[0].Name)
+	}
+
+	w = serve(http.MethodPost, "/v1/images/generations", `{"prompt":"a dog","model":"gpt-5","output_format":"webp","response_format":"url"}`)
+	if err = json.Unmarshal(w.Body.Bytes(), &response); err != nil || len(response.Data) != 1 ||
+		response.Data[0].URL != "http://example.com/images/"+response.Data[0].Name || !strings.HasSuffix(response.Data[0].Name, ".webp") {
+		t.Errorf("POST with the url response format = %d %s", w.Code, w.Body.String())
+	}
+
+	// The gallery lists the images, and serves them
+	var images []fsdb.ImageInfo
+	if w = serve(http.MethodGet, "/images", ""); json.Unmarshal(w.Body.Bytes(), &images) != nil || len(images) != 3 {
+		t.Errorf("GET /images = %d %s, want the 3 images", w.Code, w.Body.String())
+	}
+	if w = serve(http.MethodGet, "/images/"+response.Data[0].Name, ""); w.Code != http.StatusOK || w.Body.String() != "a dog|gpt-5|" {
+		t.Errorf("GET /images/%s = %d %q", response.Data[0].Name, w.Code, w.Body.String())
+	}
+	_ = os.WriteFile(filepath.Join(db.Dir, "secret.png"), []byte("secret"), 0o644)
+	for _, path := range []string{"/images/missing.png", "/images/..%2Fsecret.png"} {
+		if w = serve(http.MethodGet, path, ""); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, w.Code)
+		}
+	}
+
+	for body, want := range map[string]int{
+		`{"prompt":"no image"}`:                      http.StatusInternalServerError,
+		`{"prompt":"a cat","n":5}`:                   http.StatusBadRequest,
+		`{"prompt":"a cat","output_format":"gif"}`:   http.StatusBadRequest,
+		`{"prompt":"a cat","response_format":"svg"}`: http.StatusBadRequest,
+		`{"n":1}`: http.StatusBadRequest,
+	} {
+		if w = serve(http.MethodPost, "/v1/images/generations", body); w.Code != want {
+			t.Errorf("POST %s = %d, want %d", body, w.Code, want)
+		}
+	}
+}
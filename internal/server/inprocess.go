@@ -0,0 +1,75 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// handlerTransport serves the requests of an HTTP client with a handler of the
+// same process, streaming the response as it is written. The Ollama endpoints
+// call the chat endpoint through it, which works whether the server listens
+// on HTTPS, requires client certificates or not.
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (o handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reader, writer := io.Pipe()
+	w := &pipeResponseWriter{header: http.Header{}, body: writer, started: make(chan struct{})}
+	go func() {
+		defer func() {
+			w.WriteHeader(http.StatusOK)
+			_ = writer.Close()
+		}()
+		o.handler.ServeHTTP(w, req)
+	}()
+
+	select {
+	case <-w.started:
+	case <-req.Context().Done():
+		_ = reader.CloseWithError(req.Context().Err())
+		return nil, req.Context().Err()
+	}
+	return &http.Response{
+		Status:     http.StatusText(w.status),
+		StatusCode: w.status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     w.sentHeader,
+		Body:       reader,
+		Request:    req,
+	}, nil
+}
+
+// pipeResponseWriter writes the response of a handler to a pipe, its status and
+// headers being ready once started is closed
+type pipeResponseWriter struct {
+	header     http.Header
+	sentHeader http.Header
+	body       *io.PipeWriter
+	status     int
+	once       sync.Once
+	started    chan struct{}
+}
+
+func (o *pipeResponseWriter) Header() http.Header {
+	return o.header
+}
+
+func (o *pipeResponseWriter) WriteHeader(status int) {
+	o.once.Do(func() {
+		o.status = status
+		o.sentHeader = o.header.Clone()
+		close(o.started)
+	})
+}
+
+func (o *pipeResponseWriter) Write(data []byte) (int, error) {
+	o.WriteHeader(http.StatusOK)
+	return o.body.Write(data)
+}
+
+// Flush does nothing, every write reaching the reader at once
+func (o *pipeResponseWriter) Flush() {}
@@ -0,0 +1,41 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerTransport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/chat", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusAccepted)
+		for _, word := range strings.Fields(string(body)) {
+			_, _ = c.Writer.WriteString("data: " + word + "\n")
+			c.Writer.Flush()
+		}
+	})
+	client := &http.Client{Transport: handlerTransport{handler: r}}
+
+	res, err := client.Post("https://127.0.0.1:8080/chat", "text/plain", strings.NewReader("hello world"))
+	require.NoError(t, err, "no connection is made, whatever the scheme")
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "data: hello\ndata: world\n", string(body))
+
+	res, err = client.Get("http://127.0.0.1:8080/missing")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
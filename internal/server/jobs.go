@@ -0,0 +1,208 @@
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// jobRetention is how long the result of a finished job can be polled
+	jobRetention = time.Hour
+	// maxJobs bounds the jobs kept by the server, finished or not
+	maxJobs = 1000
+	// maxPendingJobs bounds the jobs of a client queued or running at once
+	maxPendingJobs = 20
+)
+
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Job is a chat request run in the background, whose status and results are
+// polled with GET /jobs/{id}
+type Job struct {
+	ID       string     `json:"id"`
+	Status   string     `json:"status"`             // "queued", "running", "done" or "failed"
+	Results  []string   `json:"results,omitempty"`  // Responses of the prompts, in order
+	Error    string     `json:"error,omitempty"`    // Why the job failed
+	Created  time.Time  `json:"created"`            // When the job was submitted
+	Finished *time.Time `json:"finished,omitempty"` // When the job finished or failed
+
+	// client is the hash of the client that submitted the job, and is the
+	// only one to see it
+	client string
+}
+
+// JobRequest is a chat request run as a job, whose result is posted to the
+// webhook when one is given. The webhook must be one of the --job-webhook URLs
+// of the server, which signs it with its secret.
+type JobRequest struct {
+	ChatRequest
+	Webhook string `json:"webhook,omitempty"`
+}
+
+// JobsHandler runs chat requests in the background for clients that cannot
+// keep a stream open until long requests finish. Each client, told by its API
+// key or else its address, only sees its own jobs.
+type JobsHandler struct {
+	chat *ChatHandler
+	// jobs keeps the jobs, in a store shared by the replicas of the server
+	// when it is not the memory of this one
+	jobs          JobStore
+	webhookSecret string
+	// webhooks are the URLs the jobs can post their result to, so that clients
+	// cannot make the server call other hosts or sign their content
+	webhooks []string
+}
+
+// NewJobsHandler serves /jobs, keeping the jobs in the store, or in memory
+// when it is nil.
+func NewJobsHandler(r *gin.Engine, chat *ChatHandler, jobs JobStore, webhookSecret string, webhooks []string) *JobsHandler {
+	if jobs == nil {
+		jobs = newMemoryJobs()
+	}
+	handler := &JobsHandler{chat: chat, jobs: jobs, webhookSecret: webhookSecret, webhooks: webhooks}
+
+	r.POST("/jobs", handler.Submit)
+	r.GET("/jobs/:id", handler.Get)
+
+	return handler
+}
+
+// Submit godoc
+// @Summary Submit a chat job
+// @Description Queue a chat request to run in the background and return its job, to poll with GET /jobs/{id}. The result is also posted to the webhook of the request, if any, which must be one of the --job-webhook URLs of the server.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param request body JobRequest true "Chat request with prompts and options, and an optional webhook"
+// @Success 202 {object} Job
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /jobs [post]
+func (h *JobsHandler) Submit(c *gin.Context) {
+	var request JobRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_invalid_request_format"), err)})
+		return
+	}
+	if request.Webhook != "" && !slices.Contains(h.webhooks, request.Webhook) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_webhook_not_allowed"), request.Webhook)})
+		return
+	}
+
+	client := requestClient(c)
+	job := &Job{ID: rand.Text(), Status: JobQueued, Created: time.Now(), client: clientHash(client)}
+	if err := h.jobs.Add(c.Request.Context(), job); errors.Is(err, errTooManyJobs) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": i18n.T("server_too_many_jobs")})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// run updates the job from now on
+	snapshot := *job
+
+	go h.run(job, client, &request)
+	c.JSON(http.StatusAccepted, snapshot)
+}
+
+// Get godoc
+// @Summary Get a chat job
+// @Description Get the status of a job and, once done, the responses of its prompts
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} Job
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /jobs/{id} [get]
+func (h *JobsHandler) Get(c *gin.Context) {
+	job, err := h.jobs.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// The jobs of other clients are not found either
+	if job == nil || job.client != clientHash(requestClient(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": i18n.T("server_job_not_found")})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// clientHash keeps the API keys the clients are told by out of the stores of
+// the jobs
+func clientHash(client string) string {
+	sum := sha256.Sum256([]byte(client))
+	return hex.EncodeToString(sum[:16])
+}
+
+// run sends the prompts of the job in order, the client waiting its turn in
+// the queue for each of them, then posts the result to the webhook.
+func (h *JobsHandler) run(job *Job, client string, request *JobRequest) {
+	var results []string
+	var err error
+	for _, prompt := range request.Prompts {
+		var message string
+		if message, err = h.chat.sendPrompt(context.Background(), client, &request.ChatRequest, prompt, nil, func() {
+			job.Status = JobRunning
+			h.save(job)
+		}); err != nil {
+			slog.Error("Job failed", "job", job.ID, "error", err)
+			break
+		}
+		results = append(results, message)
+	}
+
+	finished := time.Now()
+	job.Results, job.Finished = results, &finished
+	if err != nil {
+		job.Status, job.Error = JobFailed, err.Error()
+	} else {
+		job.Status = JobDone
+	}
+	payload := &webhook.Payload{Event: webhook.EventCompleted, Output: strings.Join(results, "\n\n"), Results: results,
+		Error: job.Error, JobID: job.ID, Finished: finished}
+	h.save(job)
+
+	if request.Webhook == "" {
+		return
+	}
+	if err != nil {
+		payload.Event = webhook.EventFailed
+	}
+	if len(request.Prompts) > 0 {
+		first := request.Prompts[0]
+		payload.Pattern, payload.Strategy, payload.Context, payload.Session = first.PatternName, first.StrategyName, first.ContextName, first.SessionName
+		payload.Vendor, payload.Model = first.Vendor, first.Model
+	}
+	if err = webhook.Send(context.Background(), request.Webhook, h.webhookSecret, payload); err != nil {
+		slog.Error("Sending the webhook failed", "job", job.ID, "error", err)
+	}
+}
+
+// save updates the job in the store, where it is left as last saved when
+// this fails
+func (h *JobsHandler) save(job *Job) {
+	if err := h.jobs.Save(context.Background(), job); err != nil {
+		slog.Error("Saving the job failed", "job", job.ID, "error", err)
+	}
+}
@@ -0,0 +1,111 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+func TestJobsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := fsdb.NewDb(t.TempDir())
+	registry, err := core.NewPluginRegistry(db)
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	r := gin.New()
+	NewJobsHandler(r, NewChatHandler(r, registry, db, NewRequestQueue(1), nil, nil), nil, "", []string{"https://example.com/hook"})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/jobs",
+		strings.NewReader(`{"prompts":[{"userInput":"hi","vendor":"Nope","model":"nope"}]}`)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("POST /jobs = %d %s", w.Code, w.Body.String())
+	}
+	var job Job
+	if err = json.Unmarshal(w.Body.Bytes(), &job); err != nil || job.ID == "" {
+		t.Fatalf("POST /jobs returned %s: %v", w.Body.String(), err)
+	}
+
+	// No vendor is configured, so the job fails
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != JobFailed && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil))
+		if err = json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+			t.Fatalf("GET /jobs/%s returned %s: %v", job.ID, w.Body.String(), err)
+		}
+	}
+	if job.Status != JobFailed || job.Error == "" || job.Finished == nil {
+		t.Errorf("job = %+v, want it failed", job)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /jobs/unknown = %d, want 404", w.Code)
+	}
+
+	// Only the webhooks of the server are posted to
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/jobs",
+		strings.NewReader(`{"prompts":[{"userInput":"hi"}],"webhook":"http://169.254.169.254/latest"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST /jobs with another webhook = %d, want 400", w.Code)
+	}
+}
+
+func TestJobsHandlerScopesJobsToClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := fsdb.NewDb(t.TempDir())
+	registry, err := core.NewPluginRegistry(db)
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	r := gin.New()
+	handler := NewJobsHandler(r, NewChatHandler(r, registry, db, NewRequestQueue(1), nil, nil), nil, "", []string{"https://example.com/hook"})
+
+	submit := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{"prompts":[{"userInput":"hi","vendor":"Nope","model":"nope"}]}`))
+		req.Header.Set(APIKeyHeader, key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w := submit("alice")
+	var job Job
+	if err = json.Unmarshal(w.Body.Bytes(), &job); err != nil || job.ID == "" {
+		t.Fatalf("POST /jobs returned %s: %v", w.Body.String(), err)
+	}
+	for key, want := range map[string]int{"alice": http.StatusOK, "bob": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil)
+		req.Header.Set(APIKeyHeader, key)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("GET /jobs/%s by %s = %d, want %d", job.ID, key, w.Code, want)
+		}
+	}
+
+	// A client cannot queue more than maxPendingJobs
+	for i := range maxPendingJobs {
+		id := "pending" + strconv.Itoa(i)
+		_ = handler.jobs.Save(context.Background(), &Job{ID: id, Status: JobQueued, client: clientHash("key:carol")})
+	}
+	if w = submit("carol"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("POST /jobs past the pending limit = %d, want 429", w.Code)
+	}
+	if w = submit("bob"); w.Code != http.StatusAccepted {
+		t.Errorf("POST /jobs by another client = %d, want 202", w.Code)
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"math"
 	"net/http"
 	"net/url"
@@ -45,6 +45,8 @@ type APIConvert struct {
 	registry *core.PluginRegistry
 	r        *gin.Engine
 	addr     *string
+	// client calls the chat endpoint of r
+	client *http.Client
 }
 
 type OllamaRequestBody struct {
@@ -188,7 +190,11 @@ func parseOllamaNumCtx(options map[string]any) (int, error) {
 	return contextLength, nil
 }
 
-func ServeOllama(registry *core.PluginRegistry, address string, version string) (err error) {
+// ServeOllama serves the REST API with the Ollama endpoints on the address of
+// the options, with HTTPS when their TLS options are enabled. The API key,
+// CORS, trusted proxies and base path of the options are those of --serve
+// only.
+func ServeOllama(registry *core.PluginRegistry, options *ServeOptions, version string) (err error) {
 	r := gin.New()
 
 	// Middleware
@@ -200,14 +206,15 @@ func ServeOllama(registry *core.PluginRegistry, address string, version string)
 	NewPatternsHandler(r, fabricDb.Patterns)
 	NewContextsHandler(r, fabricDb.Contexts)
 	NewSessionsHandler(r, fabricDb.Sessions)
-	NewChatHandler(r, registry, fabricDb)
+	NewChatHandler(r, registry, fabricDb, NewRequestQueue(0), nil, nil)
 	NewConfigHandler(r, fabricDb)
 	NewModelsHandler(r, registry.VendorManager)
 
 	typeConversion := APIConvert{
 		registry: registry,
 		r:        r,
-		addr:     &address,
+		addr:     &options.Address,
+		client:   &http.Client{Transport: handlerTransport{handler: r}},
 	}
 	// Ollama Endpoints
 	r.GET("/api/tags", typeConversion.ollamaTags)
@@ -217,7 +224,7 @@ func ServeOllama(registry *core.PluginRegistry, address string, version string)
 	r.POST("/api/chat", typeConversion.ollamaChat)
 
 	// Start server
-	err = r.Run(address)
+	err = listen(r, &ServeOptions{Address: options.Address, TLS: options.TLS})
 	if err != nil {
 		return err
 	}
@@ -259,14 +266,14 @@ func (f APIConvert) ollamaTags(c *gin.Context) {
 func (f APIConvert) ollamaChat(c *gin.Context) {
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		log.Printf(i18n.T("ollama_error_reading_body"), err)
+		slog.Error(fmt.Sprintf(i18n.T("ollama_error_reading_body"), err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("ollama_error_endpoint")})
 		return
 	}
 	var prompt OllamaRequestBody
 	err = json.Unmarshal(body, &prompt)
 	if err != nil {
-		log.Printf(i18n.T("ollama_error_unmarshalling_body"), err)
+		slog.Error(fmt.Sprintf(i18n.T("ollama_error_unmarshalling_body"), err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("ollama_error_endpoint")})
 		return
 	}
@@ -274,7 +281,7 @@ func (f APIConvert) ollamaChat(c *gin.Context) {
 	// Extract and validate num_ctx from options
 	numCtx, err := parseOllamaNumCtx(prompt.Options)
 	if err != nil {
-		log.Printf(i18n.T("ollama_invalid_num_ctx_in_request"), err)
+		slog.Warn(fmt.Sprintf(i18n.T("ollama_invalid_num_ctx_in_request"), err))
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -291,7 +298,7 @@ func (f APIConvert) ollamaChat(c *gin.Context) {
 			case string:
 				// Parse JSON string into map
 				if err := json.Unmarshal([]byte(v), &variables); err != nil {
-					log.Printf(i18n.T("ollama_warning_parse_variables"), err)
+					slog.Warn(fmt.Sprintf(i18n.T("ollama_warning_parse_variables"), err))
 				}
 			case map[string]any:
 				// Convert map[string]any to map[string]string
@@ -334,29 +341,29 @@ func (f APIConvert) ollamaChat(c *gin.Context) {
 
 	fabricChatReq, err := json.Marshal(chat)
 	if err != nil {
-		log.Printf(i18n.T("ollama_error_marshalling_body"), err)
+		slog.Error(fmt.Sprintf(i18n.T("ollama_error_marshalling_body"), err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	var req *http.Request
 	baseURL, err := buildFabricChatURL(*f.addr)
 	if err != nil {
-		log.Printf(i18n.T("ollama_error_building_chat_url"), err)
+		slog.Error(fmt.Sprintf(i18n.T("ollama_error_building_chat_url"), err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	req, err = http.NewRequest("POST", fmt.Sprintf("%s/chat", baseURL), bytes.NewBuffer(fabricChatReq))
 	if err != nil {
-		log.Printf(i18n.T("ollama_error_creating_chat_request"), err)
+		slog.Error(fmt.Sprintf(i18n.T("ollama_error_creating_chat_request"), err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("ollama_failed_create_request")})
 		return
 	}
 
 	req = req.WithContext(c.Request.Context())
 
-	fabricRes, err := http.DefaultClient.Do(req)
+	fabricRes, err := f.client.Do(req)
 	if err != nil {
-		log.Printf(i18n.T("ollama_error_getting_chat_body"), err)
+		slog.Error(fmt.Sprintf(i18n.T("ollama_error_getting_chat_body"), err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -365,9 +372,9 @@ func (f APIConvert) ollamaChat(c *gin.Context) {
 	if fabricRes.StatusCode < http.StatusOK || fabricRes.StatusCode >= http.StatusMultipleChoices {
 		bodyBytes, readErr := io.ReadAll(fabricRes.Body)
 		if readErr != nil {
-			log.Printf(i18n.T("ollama_upstream_non_2xx_body_unreadable"), fabricRes.StatusCode, readErr)
+			slog.Error(fmt.Sprintf(i18n.T("ollama_upstream_non_2xx_body_unreadable"), fabricRes.StatusCode, readErr))
 		} else {
-			log.Printf(i18n.T("ollama_upstream_non_2xx"), fabricRes.StatusCode, string(bodyBytes))
+			slog.Error(fmt.Sprintf(i18n.T("ollama_upstream_non_2xx"), fabricRes.StatusCode, string(bodyBytes)))
 		}
 
 		errorMessage := fmt.Sprintf(i18n.T("ollama_upstream_returned_status"), fabricRes.StatusCode)
@@ -394,7 +401,7 @@ func (f APIConvert) ollamaChat(c *gin.Context) {
 		payload := strings.TrimPrefix(line, "data: ")
 		var fabricResponse FabricResponseFormat
 		if err := json.Unmarshal([]byte(payload), &fabricResponse); err != nil {
-			log.Printf(i18n.T("ollama_error_unmarshalling_body"), err)
+			slog.Error(fmt.Sprintf(i18n.T("ollama_error_unmarshalling_body"), err))
 			if prompt.Stream {
 				// In streaming mode, send the error in the same streaming format
 				_ = writeOllamaResponse(c, prompt.Model, i18n.T("ollama_error_parse_upstream_response"), true)
@@ -418,13 +425,13 @@ func (f APIConvert) ollamaChat(c *gin.Context) {
 		contentBuilder.WriteString(fabricResponse.Content)
 		if prompt.Stream {
 			if err := writeOllamaResponse(c, prompt.Model, fabricResponse.Content, false); err != nil {
-				log.Printf(i18n.T("ollama_error_writing_response"), err)
+				slog.Error(fmt.Sprintf(i18n.T("ollama_error_writing_response"), err))
 				return
 			}
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		log.Printf(i18n.T("ollama_error_scanning_body"), err)
+		slog.Error(fmt.Sprintf(i18n.T("ollama_error_scanning_body"), err))
 		errorMsg := fmt.Sprintf(i18n.T("ollama_failed_scan_sse_stream"), err)
 		// Check for buffer size exceeded error
 		if strings.Contains(err.Error(), "token too long") {
@@ -444,7 +451,7 @@ func (f APIConvert) ollamaChat(c *gin.Context) {
 
 	// Check if we received any content from upstream
 	if contentBuilder.Len() == 0 {
-		log.Printf("%s", i18n.T("ollama_warning_no_content"))
+		slog.Warn(i18n.T("ollama_warning_no_content"))
 		// In non-streaming mode, treat absence of content as an error
 		if !prompt.Stream {
 			c.JSON(http.StatusBadGateway, gin.H{"error": i18n.T("ollama_no_content_from_upstream")})
@@ -460,7 +467,7 @@ func (f APIConvert) ollamaChat(c *gin.Context) {
 
 	finalResponse := buildFinalOllamaResponse(prompt.Model, "", duration)
 	if err := writeOllamaResponseStruct(c, finalResponse); err != nil {
-		log.Printf(i18n.T("ollama_error_writing_response"), err)
+		slog.Error(fmt.Sprintf(i18n.T("ollama_error_writing_response"), err))
 	}
 }
 
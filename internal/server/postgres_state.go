@@ -0,0 +1,218 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresSchema creates the tables of the state, when the first replica
+// uses the database
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS fabric_jobs (
+	id       text PRIMARY KEY,
+	client   text NOT NULL,
+	finished boolean NOT NULL,
+	expires  timestamptz NOT NULL,
+	data     bytea NOT NULL
+);
+CREATE INDEX IF NOT EXISTS fabric_jobs_pending ON fabric_jobs (client) WHERE NOT finished;
+CREATE TABLE IF NOT EXISTS fabric_sessions (
+	name text PRIMARY KEY,
+	data bytea NOT NULL
+);`
+
+// postgresTimeout bounds the connection to the PostgreSQL server
+const postgresTimeout = 5 * time.Second
+
+// errInvalidPostgresURL tells that the URL is not that of a PostgreSQL server
+var errInvalidPostgresURL = errors.New("invalid PostgreSQL URL")
+
+// isPostgresURL tells whether the value is a postgres:// or postgresql:// URL
+func isPostgresURL(value string) bool {
+	return strings.HasPrefix(value, "postgres://") || strings.HasPrefix(value, "postgresql://")
+}
+
+// postgresDB is the PostgreSQL database shared by the replicas of the server,
+// whose tables are created on its first use
+type postgresDB struct {
+	pool *pgxpool.Pool
+
+	mu    sync.Mutex
+	ready bool
+}
+
+// newPostgresDB returns the database of the URL, connecting on the first
+// command
+func newPostgresDB(rawURL string) (*postgresDB, error) {
+	if parsed, err := url.Parse(rawURL); err != nil || !isPostgresURL(rawURL) || parsed.Hostname() == "" {
+		return nil, errInvalidPostgresURL
+	}
+	config, err := pgxpool.ParseConfig(rawURL)
+	if err != nil {
+		return nil, errInvalidPostgresURL
+	}
+	config.ConnConfig.ConnectTimeout = postgresTimeout
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresDB{pool: pool}, nil
+}
+
+// conn returns the pool once the tables exist
+func (o *postgresDB) conn(ctx context.Context) (*pgxpool.Pool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.ready {
+		if _, err := o.pool.Exec(ctx, postgresSchema); err != nil {
+			return nil, err
+		}
+		o.ready = true
+	}
+	return o.pool, nil
+}
+
+// postgresJobs keeps the jobs in a PostgreSQL database shared by the replicas
+// of the server. As with Redis, maxJobs is not enforced, the jobs expiring.
+type postgresJobs struct {
+	db *postgresDB
+}
+
+func (o *postgresJobs) Add(ctx context.Context, job *Job) (err error) {
+	var pool *pgxpool.Pool
+	if pool, err = o.db.conn(ctx); err != nil {
+		return
+	}
+	return pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) (err error) {
+		// The lock serializes the jobs of the client submitted to the replicas
+		// at once, until the transaction ends
+		if _, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", job.client); err != nil {
+			return
+		}
+		if _, err = tx.Exec(ctx, "DELETE FROM fabric_jobs WHERE expires < now()"); err != nil {
+			return
+		}
+		var pending int
+		if err = tx.QueryRow(ctx, "SELECT count(*) FROM fabric_jobs WHERE client = $1 AND NOT finished",
+			job.client).Scan(&pending); err != nil {
+			return
+		}
+		if pending >= maxPendingJobs {
+			return errTooManyJobs
+		}
+		return saveJob(ctx, tx, job)
+	})
+}
+
+func (o *postgresJobs) Save(ctx context.Context, job *Job) (err error) {
+	var pool *pgxpool.Pool
+	if pool, err = o.db.conn(ctx); err != nil {
+		return
+	}
+	return saveJob(ctx, pool, job)
+}
+
+// postgresExecer runs the commands of the pool or of a transaction
+type postgresExecer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// saveJob inserts or updates the job, expiring pendingJobTTL after it was
+// saved while it is queued or running and jobRetention after it finished
+func saveJob(ctx context.Context, db postgresExecer, job *Job) (err error) {
+	var value []byte
+	if value, err = json.Marshal(job); err != nil {
+		return
+	}
+	ttl := pendingJobTTL
+	if job.Finished != nil {
+		ttl = jobRetention
+	}
+	_, err = db.Exec(ctx, `INSERT INTO fabric_jobs (id, client, finished, expires, data)
+		VALUES ($1, $2, $3, now() + make_interval(secs => $4), $5)
+		ON CONFLICT (id) DO UPDATE SET finished = $3, expires = now() + make_interval(secs => $4), data = $5`,
+		job.ID, job.client, job.Finished != nil, ttl.Seconds(), value)
+	return
+}
+
+func (o *postgresJobs) Get(ctx context.Context, id string) (ret *Job, err error) {
+	var pool *pgxpool.Pool
+	if pool, err = o.db.conn(ctx); err != nil {
+		return
+	}
+	var client string
+	var value []byte
+	if err = pool.QueryRow(ctx, "SELECT client, data FROM fabric_jobs WHERE id = $1 AND expires >= now()",
+		id).Scan(&client, &value); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+		return
+	}
+	ret = &Job{}
+	if err = json.Unmarshal(value, ret); err != nil {
+		return nil, err
+	}
+	ret.client = client
+	return
+}
+
+// postgresSessions keeps the sessions in a PostgreSQL database shared by the
+// replicas of the server.
+type postgresSessions struct {
+	db *postgresDB
+}
+
+func (o *postgresSessions) Load(ctx context.Context, name string) (data []byte, found bool, err error) {
+	var pool *pgxpool.Pool
+	if pool, err = o.db.conn(ctx); err != nil {
+		return
+	}
+	if err = pool.QueryRow(ctx, "SELECT data FROM fabric_sessions WHERE name = $1", name).Scan(&data); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (o *postgresSessions) Save(ctx context.Context, name string, data []byte) (err error) {
+	var pool *pgxpool.Pool
+	if pool, err = o.db.conn(ctx); err != nil {
+		return
+	}
+	_, err = pool.Exec(ctx, `INSERT INTO fabric_sessions (name, data) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET data = $2`, name, data)
+	return
+}
+
+func (o *postgresSessions) Delete(ctx context.Context, name string) (err error) {
+	var pool *pgxpool.Pool
+	if pool, err = o.db.conn(ctx); err != nil {
+		return
+	}
+	_, err = pool.Exec(ctx, "DELETE FROM fabric_sessions WHERE name = $1", name)
+	return
+}
+
+func (o *postgresSessions) Names(ctx context.Context) (ret []string, err error) {
+	var pool *pgxpool.Pool
+	if pool, err = o.db.conn(ctx); err != nil {
+		return
+	}
+	var rows pgx.Rows
+	if rows, err = pool.Query(ctx, "SELECT name FROM fabric_sessions ORDER BY name"); err != nil {
+		return
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
@@ -0,0 +1,82 @@
+package restapi
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	corsAllowMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	corsAllowHeaders = "Content-Type, Authorization, " + APIKeyHeader + ", Last-Event-ID, If-None-Match, Cache-Control"
+	// corsExposeHeaders are the response headers the frontends read: the
+	// stream to resume and the caching of the response
+	corsExposeHeaders = "X-Stream-ID, ETag, Age, X-Cache"
+	corsMaxAge        = "600"
+)
+
+// CORSMiddleware lets the browser frontends of the origins call the API, "*"
+// allowing any origin, and answers their preflight requests.
+func CORSMiddleware(origins []string) gin.HandlerFunc {
+	allowed := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		allowed = append(allowed, strings.ToLower(strings.TrimSuffix(origin, "/")))
+	}
+	allowAny := slices.Contains(allowed, "*")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		if !allowAny && !slices.Contains(allowed, strings.ToLower(origin)) {
+			c.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", corsAllowMethods)
+			header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			header.Set("Access-Control-Max-Age", corsMaxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// normalizeBasePath returns the base path with a leading and without a
+// trailing slash, or "" when the API is served at the root.
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// withBasePath serves the handler under the base path, as a reverse proxy
+// forwarding a subpath does, and answers 404 outside of it.
+func withBasePath(handler http.Handler, basePath string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path, ok := strings.CutPrefix(req.URL.Path, basePath)
+		if !ok || (path != "" && path[0] != '/') {
+			http.NotFound(w, req)
+			return
+		}
+		if path == "" {
+			path = "/"
+		}
+		stripped := req.Clone(req.Context())
+		stripped.URL.Path = path
+		stripped.URL.RawPath = strings.TrimPrefix(req.URL.RawPath, basePath)
+		handler.ServeHTTP(w, stripped)
+	})
+}
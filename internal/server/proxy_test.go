@@ -0,0 +1,100 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newProxyTestEngine(origins []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORSMiddleware(origins))
+	r.Use(APIKeyMiddleware("secret"))
+	r.GET("/patterns/names", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return r
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	r := newProxyTestEngine([]string{"https://app.example.com/"})
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/patterns/names", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, preflight)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("preflight = %d %v, want 204 allowing the origin without an API key", w.Code, w.Header())
+	}
+	// The frontends send bearer tokens, resume the streams and revalidate the
+	// cached responses
+	for _, name := range []string{"Authorization", "Last-Event-ID", "If-None-Match", "Cache-Control"} {
+		if allowed := w.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(allowed, name) {
+			t.Errorf("preflight allows %q, want %s", allowed, name)
+		}
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/patterns/names", nil)
+	request.Header.Set("Origin", "https://evil.example.com")
+	request.Header.Set(APIKeyHeader, "secret")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("other origin = %d %v, want 200 without CORS headers", w.Code, w.Header())
+	}
+
+	r = newProxyTestEngine([]string{"*"})
+	request.Header.Set("Origin", "https://any.example.com")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://any.example.com" {
+		t.Errorf("any origin = %v, want the origin allowed", w.Header())
+	}
+	for _, name := range []string{"X-Stream-ID", "ETag", "Age", "X-Cache"} {
+		if exposed := w.Header().Get("Access-Control-Expose-Headers"); !strings.Contains(exposed, name) {
+			t.Errorf("any origin exposes %q, want %s", exposed, name)
+		}
+	}
+}
+
+func TestRequestAPIKey(t *testing.T) {
+	r := newProxyTestEngine(nil)
+	for authorization, code := range map[string]int{"Bearer secret": http.StatusOK, "Basic secret": http.StatusUnauthorized, "secret": http.StatusUnauthorized} {
+		request := httptest.NewRequest(http.MethodGet, "/patterns/names", nil)
+		request.Header.Set("Authorization", authorization)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+		if w.Code != code {
+			t.Errorf("Authorization %q = %d, want %d", authorization, w.Code, code)
+		}
+	}
+}
+
+func TestWithBasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, "root") })
+	r.GET("/patterns/names", func(c *gin.Context) { c.String(http.StatusOK, "names") })
+	handler := withBasePath(r, normalizeBasePath("fabric/"))
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{path: "/fabric/patterns/names", code: http.StatusOK, body: "names"},
+		{path: "/fabric", code: http.StatusOK, body: "root"},
+		{path: "/patterns/names", code: http.StatusNotFound},
+		{path: "/fabricator/patterns/names", code: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+}
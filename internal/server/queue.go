@@ -0,0 +1,107 @@
+package restapi
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestQueue limits the vendor requests running at once. Clients waiting
+// for a slot are served in turn, so that a burst of one client does not
+// starve the others.
+type RequestQueue struct {
+	limit int
+
+	mu      sync.Mutex
+	running int
+	waiting map[string][]chan struct{}
+	// turns holds the clients with waiting requests, the next one first
+	turns []string
+}
+
+// NewRequestQueue returns a queue running at most limit requests at once, or
+// any number of them when limit is 0.
+func NewRequestQueue(limit int) *RequestQueue {
+	return &RequestQueue{limit: limit, waiting: map[string][]chan struct{}{}}
+}
+
+// Acquire waits until a request of the client may run, or the context is done.
+// The returned function must be called once the request is finished.
+func (o *RequestQueue) Acquire(ctx context.Context, client string) (release func(), err error) {
+	if o.limit <= 0 {
+		return func() {}, nil
+	}
+
+	o.mu.Lock()
+	if o.running < o.limit && len(o.turns) == 0 {
+		o.running++
+		o.mu.Unlock()
+		return sync.OnceFunc(o.release), nil
+	}
+	ready := make(chan struct{})
+	if len(o.waiting[client]) == 0 {
+		o.turns = append(o.turns, client)
+	}
+	o.waiting[client] = append(o.waiting[client], ready)
+	o.mu.Unlock()
+
+	select {
+	case <-ready:
+		return sync.OnceFunc(o.release), nil
+	case <-ctx.Done():
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	select {
+	case <-ready:
+		// The slot was given meanwhile, so it is handed on
+		o.running--
+		o.dispatch()
+	default:
+		queue := slices.DeleteFunc(o.waiting[client], func(waiting chan struct{}) bool { return waiting == ready })
+		if len(queue) == 0 {
+			delete(o.waiting, client)
+			o.turns = slices.DeleteFunc(o.turns, func(turn string) bool { return turn == client })
+		} else {
+			o.waiting[client] = queue
+		}
+	}
+	return nil, ctx.Err()
+}
+
+func (o *RequestQueue) release() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.running--
+	o.dispatch()
+}
+
+// dispatch starts the next waiting requests while slots are free, one of each
+// client in turn. The lock must be held.
+func (o *RequestQueue) dispatch() {
+	for o.running < o.limit && len(o.turns) > 0 {
+		client := o.turns[0]
+		o.turns = o.turns[1:]
+		queue := o.waiting[client]
+		close(queue[0])
+		if len(queue) > 1 {
+			o.waiting[client] = queue[1:]
+			o.turns = append(o.turns, client)
+		} else {
+			delete(o.waiting, client)
+		}
+		o.running++
+	}
+}
+
+// requestClient identifies the client of the request for the fairness of the
+// queue: by its API key, or by its address without one.
+func requestClient(c *gin.Context) string {
+	if key := requestAPIKey(c); key != "" {
+		return "key:" + key
+	}
+	return "ip:" + c.ClientIP()
+}
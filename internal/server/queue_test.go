@@ -0,0 +1,102 @@
+package restapi
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// acquireAsync starts waiting for a slot and reports the client once it got one
+func acquireAsync(t *testing.T, ctx context.Context, queue *RequestQueue, client string, started chan<- string, releases chan<- func()) {
+	t.Helper()
+	go func() {
+		release, err := queue.Acquire(ctx, client)
+		if err != nil {
+			started <- "cancelled " + client
+			return
+		}
+		releases <- release
+		started <- client
+	}()
+}
+
+// waitForWaiting waits until n requests are queued
+func waitForWaiting(t *testing.T, queue *RequestQueue, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		queue.mu.Lock()
+		waiting := 0
+		for _, requests := range queue.waiting {
+			waiting += len(requests)
+		}
+		queue.mu.Unlock()
+		if waiting == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d requests did not get queued", n)
+}
+
+func TestRequestQueue_TakesTurnsBetweenClients(t *testing.T) {
+	queue := NewRequestQueue(1)
+	release, err := queue.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	started := make(chan string, 4)
+	releases := make(chan func(), 4)
+	for i, client := range []string{"a", "a", "b"} {
+		acquireAsync(t, context.Background(), queue, client, started, releases)
+		waitForWaiting(t, queue, i+1)
+	}
+
+	var order []string
+	for range 3 {
+		release()
+		order = append(order, <-started)
+		release = <-releases
+	}
+	release()
+	if want := []string{"a", "b", "a"}; !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestRequestQueue_CancelledWhileWaiting(t *testing.T) {
+	queue := NewRequestQueue(1)
+	release, err := queue.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan string, 2)
+	releases := make(chan func(), 2)
+	acquireAsync(t, ctx, queue, "b", started, releases)
+	waitForWaiting(t, queue, 1)
+	cancel()
+	if got := <-started; got != "cancelled b" {
+		t.Fatalf("got %q, want the request cancelled", got)
+	}
+	waitForWaiting(t, queue, 0)
+
+	// The cancelled request does not hold the slot given back
+	release()
+	if release, err = queue.Acquire(context.Background(), "c"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	release()
+}
+
+func TestRequestQueue_Unlimited(t *testing.T) {
+	queue := NewRequestQueue(0)
+	for range 3 {
+		if _, err := queue.Acquire(context.Background(), "a"); err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+	}
+}
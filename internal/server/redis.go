@@ -0,0 +1,38 @@
+package restapi
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds a command to the Redis server, so that a server gone
+// slow does not hold the requests
+const redisTimeout = 5 * time.Second
+
+// errInvalidRedisURL tells that the URL is not that of a Redis server
+var errInvalidRedisURL = errors.New("invalid Redis URL")
+
+// isRedisURL tells whether the value is a redis:// or rediss:// URL
+func isRedisURL(value string) bool {
+	return strings.HasPrefix(value, "redis://") || strings.HasPrefix(value, "rediss://")
+}
+
+// newRedisClient returns the client of the Redis server of the URL,
+// redis://[user:password@]host[:port][/db], rediss:// connecting with TLS.
+func newRedisClient(rawURL string) (*redis.Client, error) {
+	if parsed, err := url.Parse(rawURL); err != nil || !isRedisURL(rawURL) || parsed.Hostname() == "" {
+		return nil, errInvalidRedisURL
+	}
+	options, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, errInvalidRedisURL
+	}
+	options.DialTimeout = redisTimeout
+	options.ReadTimeout = redisTimeout
+	options.WriteTimeout = redisTimeout
+	return redis.NewClient(options), nil
+}
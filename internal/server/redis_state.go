@@ -0,0 +1,146 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisJobPrefix         = "fabric:job:"
+	redisPendingJobsPrefix = "fabric:jobs:pending:"
+	redisSessionPrefix     = "fabric:session:"
+	redisSessionNames      = "fabric:sessions"
+
+	// pendingJobTTL forgets the jobs left queued or running by a replica that
+	// stopped
+	pendingJobTTL = 24 * time.Hour
+)
+
+// storedJob is a job as kept in Redis, with its client
+type storedJob struct {
+	Job
+	Client string `json:"client"`
+}
+
+// redisJobs keeps the jobs in a Redis server shared by the replicas of the
+// server. Unlike in memory, maxJobs is not enforced, Redis expiring the jobs.
+type redisJobs struct {
+	client *redis.Client
+}
+
+func (o *redisJobs) Add(ctx context.Context, job *Job) (err error) {
+	pendingKey := redisPendingJobsPrefix + job.client
+	var pending int
+	if pending, err = o.pending(ctx, pendingKey); err != nil {
+		return
+	}
+	if pending >= maxPendingJobs {
+		return errTooManyJobs
+	}
+	if err = o.Save(ctx, job); err != nil {
+		return
+	}
+	if err = o.client.SAdd(ctx, pendingKey, job.ID).Err(); err != nil {
+		return
+	}
+	return o.client.PExpire(ctx, pendingKey, pendingJobTTL).Err()
+}
+
+// pending returns how many jobs of the pending set are queued or running,
+// removing those expired
+func (o *redisJobs) pending(ctx context.Context, pendingKey string) (ret int, err error) {
+	var ids []string
+	if ids, err = o.client.SMembers(ctx, pendingKey).Result(); err != nil {
+		return
+	}
+	for _, id := range ids {
+		var exists int64
+		if exists, err = o.client.Exists(ctx, redisJobPrefix+id).Result(); err != nil {
+			return
+		}
+		if exists == 1 {
+			ret++
+		} else if err = o.client.SRem(ctx, pendingKey, id).Err(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (o *redisJobs) Save(ctx context.Context, job *Job) (err error) {
+	var value []byte
+	if value, err = json.Marshal(storedJob{Job: *job, Client: job.client}); err != nil {
+		return
+	}
+	ttl := pendingJobTTL
+	if job.Finished != nil {
+		ttl = jobRetention
+	}
+	if err = o.client.Set(ctx, redisJobPrefix+job.ID, value, ttl).Err(); err != nil {
+		return
+	}
+	if job.Finished != nil {
+		err = o.client.SRem(ctx, redisPendingJobsPrefix+job.client, job.ID).Err()
+	}
+	return
+}
+
+func (o *redisJobs) Get(ctx context.Context, id string) (ret *Job, err error) {
+	var value []byte
+	if value, err = o.client.Get(ctx, redisJobPrefix+id).Bytes(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = nil
+		}
+		return
+	}
+	var stored storedJob
+	if err = json.Unmarshal(value, &stored); err != nil {
+		return
+	}
+	ret = &stored.Job
+	ret.client = stored.Client
+	return
+}
+
+// redisSessions keeps the sessions in a Redis server shared by the replicas of
+// the server, with the set of their names.
+type redisSessions struct {
+	client *redis.Client
+}
+
+func (o *redisSessions) Load(ctx context.Context, name string) (data []byte, found bool, err error) {
+	if data, err = o.client.Get(ctx, redisSessionPrefix+name).Bytes(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = nil
+		}
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (o *redisSessions) Save(ctx context.Context, name string, data []byte) (err error) {
+	if err = o.client.Set(ctx, redisSessionPrefix+name, data, 0).Err(); err != nil {
+		return
+	}
+	return o.client.SAdd(ctx, redisSessionNames, name).Err()
+}
+
+func (o *redisSessions) Delete(ctx context.Context, name string) (err error) {
+	if err = o.client.Del(ctx, redisSessionPrefix+name).Err(); err != nil {
+		return
+	}
+	return o.client.SRem(ctx, redisSessionNames, name).Err()
+}
+
+func (o *redisSessions) Names(ctx context.Context) (ret []string, err error) {
+	if ret, err = o.client.SMembers(ctx, redisSessionNames).Result(); err != nil {
+		return
+	}
+	slices.Sort(ret)
+	return
+}
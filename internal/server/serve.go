@@ -1,12 +1,14 @@
 package restapi
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 
 	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -14,6 +16,39 @@ import (
 	_ "github.com/danielmiessler/fabric/docs" // swagger docs
 )
 
+// ServeOptions configures the REST API server
+type ServeOptions struct {
+	Address string
+	APIKey  string
+	TLS     *TLSOptions
+	// CORSOrigins are the origins of the browser frontends allowed to call
+	// the API, "*" allowing any
+	CORSOrigins []string
+	// TrustedProxies are the addresses or CIDR ranges of the reverse proxies
+	// whose X-Forwarded-For header gives the address of the client
+	TrustedProxies []string
+	// MaxConcurrent limits the vendor requests running at once, 0 meaning no
+	// limit
+	MaxConcurrent int
+	// WebhookSecret signs the webhooks of the jobs when not empty
+	WebhookSecret string
+	// JobWebhooks are the only URLs the jobs can post their result to
+	JobWebhooks []string
+	// BasePath is the path the API is served under, e.g. /fabric behind a
+	// reverse proxy
+	BasePath string
+	// Cache, when set, answers identical chat requests with the response of
+	// the first one
+	Cache *ChatCache
+	// State keeps the jobs and the sessions, in memory and the local files
+	// when nil
+	State *ServerState
+	// TTSModel and Voice are used by /v1/audio/speech when the request has
+	// no model or voice
+	TTSModel string
+	Voice    string
+}
+
 // @title Fabric REST API
 // @version 1.0
 // @description REST API for Fabric AI augmentation framework. Provides endpoints for chat completions, pattern management, contexts, sessions, and more.
@@ -26,15 +61,23 @@ import (
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name X-API-Key
-func Serve(registry *core.PluginRegistry, address string, apiKey string) (err error) {
+func Serve(registry *core.PluginRegistry, options *ServeOptions) (err error) {
 	r := gin.New()
+	if err = r.SetTrustedProxies(options.TrustedProxies); err != nil {
+		return fmt.Errorf(i18n.T("server_invalid_trusted_proxies"), err)
+	}
 
 	// Middleware
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 
-	if apiKey != "" {
-		r.Use(APIKeyMiddleware(apiKey))
+	// Preflight requests carry no API key, so CORS is handled first
+	if len(options.CORSOrigins) > 0 {
+		r.Use(CORSMiddleware(options.CORSOrigins))
+	}
+
+	if options.APIKey != "" {
+		r.Use(APIKeyMiddleware(options.APIKey))
 	} else {
 		slog.Warn("Starting REST API server without API key authentication. This may pose security risks.")
 	}
@@ -65,22 +108,57 @@ func Serve(registry *core.PluginRegistry, address string, apiKey string) (err er
 		ginSwagger.WrapHandler(swaggerFiles.Handler)(c)
 	})
 
+	state := options.State
+	if state == nil {
+		state = &ServerState{Jobs: newMemoryJobs()}
+	}
+
 	// Register routes
 	fabricDb := registry.Db
 	NewPatternsHandler(r, fabricDb.Patterns)
 	NewContextsHandler(r, fabricDb.Contexts)
-	NewSessionsHandler(r, fabricDb.Sessions)
-	NewChatHandler(r, registry, fabricDb)
+	if state.Sessions != nil {
+		NewSessionsHandler(r, &sharedSessions{store: state.Sessions})
+	} else {
+		NewSessionsHandler(r, fabricDb.Sessions)
+	}
+	chatHandler := NewChatHandler(r, registry, fabricDb, NewRequestQueue(options.MaxConcurrent), options.Cache, state.Sessions)
+	NewJobsHandler(r, chatHandler, state.Jobs, options.WebhookSecret, options.JobWebhooks)
 	NewYouTubeHandler(r, registry)
+	NewSpeechHandler(r, registry, options.TTSModel, options.Voice)
+	NewImagesHandler(r, registry, fabricDb.Images)
 	NewConfigHandler(r, fabricDb)
 	NewModelsHandler(r, registry.VendorManager)
 	NewStrategiesHandler(r)
+	NewUIHandler(r)
 
 	// Start server
-	err = r.Run(address)
+	err = listen(r, options)
 	if err != nil {
 		return err
 	}
 
 	return
 }
+
+// listen serves the engine on the address and under the base path of the
+// options, with HTTPS when their TLS options are enabled.
+func listen(r *gin.Engine, options *ServeOptions) (err error) {
+	basePath := normalizeBasePath(options.BasePath)
+	if basePath == "" && !options.TLS.Enabled() {
+		return r.Run(options.Address)
+	}
+
+	server := &http.Server{Addr: options.Address, Handler: r}
+	if basePath != "" {
+		server.Handler = withBasePath(r, basePath)
+	}
+	if !options.TLS.Enabled() {
+		return server.ListenAndServe()
+	}
+	if server.TLSConfig, err = newTLSConfig(options.TLS); err != nil {
+		return
+	}
+	// The certificate comes from the config, so that it is reloaded
+	return server.ListenAndServeTLS("", "")
+}
@@ -1,6 +1,12 @@
 package restapi
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/db"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 	"github.com/gin-gonic/gin"
 )
@@ -8,12 +14,114 @@ import (
 // SessionsHandler defines the handler for sessions-related operations
 type SessionsHandler struct {
 	*StorageHandler[fsdb.Session]
-	sessions *fsdb.SessionsEntity
+	sessions db.Storage[fsdb.Session]
 }
 
 // NewSessionsHandler creates a new SessionsHandler
-func NewSessionsHandler(r *gin.Engine, sessions *fsdb.SessionsEntity) (ret *SessionsHandler) {
+func NewSessionsHandler(r *gin.Engine, sessions db.Storage[fsdb.Session]) (ret *SessionsHandler) {
 	ret = &SessionsHandler{
 		StorageHandler: NewStorageHandler(r, "sessions", sessions), sessions: sessions}
 	return ret
 }
+
+// sharedSessions serves the sessions of a SessionStore shared by the replicas
+// of the server, rather than those of the local files.
+type sharedSessions struct {
+	store SessionStore
+}
+
+func (o *sharedSessions) Configure() error {
+	return nil
+}
+
+func (o *sharedSessions) Get(name string) (ret *fsdb.Session, err error) {
+	ret = &fsdb.Session{Name: name}
+	var data []byte
+	var found bool
+	if data, found, err = o.store.Load(context.Background(), name); err != nil || !found {
+		return
+	}
+	err = json.Unmarshal(data, &ret.Messages)
+	return
+}
+
+func (o *sharedSessions) GetNames() ([]string, error) {
+	return o.store.Names(context.Background())
+}
+
+func (o *sharedSessions) Delete(name string) error {
+	return o.store.Delete(context.Background(), name)
+}
+
+func (o *sharedSessions) Exists(name string) bool {
+	_, found, _ := o.store.Load(context.Background(), name)
+	return found
+}
+
+func (o *sharedSessions) Rename(oldName, newName string) (err error) {
+	var data []byte
+	var found bool
+	if data, found, err = o.store.Load(context.Background(), oldName); err != nil {
+		return
+	}
+	if !found {
+		return fmt.Errorf(i18n.T("server_session_not_found"), oldName)
+	}
+	if err = o.store.Save(context.Background(), newName, data); err != nil {
+		return
+	}
+	return o.store.Delete(context.Background(), oldName)
+}
+
+func (o *sharedSessions) Save(name string, content []byte) error {
+	return o.store.Save(context.Background(), name, content)
+}
+
+func (o *sharedSessions) Load(name string) (ret []byte, err error) {
+	var found bool
+	if ret, found, err = o.store.Load(context.Background(), name); err == nil && !found {
+		err = fmt.Errorf(i18n.T("server_session_not_found"), name)
+	}
+	return
+}
+
+func (o *sharedSessions) ListNames(bool) (err error) {
+	var names []string
+	if names, err = o.GetNames(); err != nil {
+		return
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return
+}
+
+// pullSession copies the session of the store to the local files the chatter
+// reads it from, removing the local one the store no longer has
+func pullSession(ctx context.Context, store SessionStore, sessions *fsdb.SessionsEntity, name string) (err error) {
+	var data []byte
+	var found bool
+	if data, found, err = store.Load(ctx, name); err != nil {
+		return
+	}
+	if found {
+		return sessions.Save(name, data)
+	}
+	if sessions.Exists(name) {
+		err = sessions.Delete(name)
+	}
+	return
+}
+
+// pushSession copies the session the chatter saved to the local files back to
+// the store, the last replica to save a session winning
+func pushSession(ctx context.Context, store SessionStore, sessions *fsdb.SessionsEntity, name string) (err error) {
+	if !sessions.Exists(name) {
+		return
+	}
+	var data []byte
+	if data, err = sessions.Load(name); err != nil {
+		return
+	}
+	return store.Save(ctx, name, data)
+}
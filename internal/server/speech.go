@@ -0,0 +1,106 @@
+package restapi
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/gin-gonic/gin"
+)
+
+// wavHeaderSize is the size of the header of the WAV data of the vendors,
+// followed by the raw PCM samples
+const wavHeaderSize = 44
+
+// SpeechHandler serves the OpenAI-compatible speech endpoint with the
+// vendors able to synthesize speech, so that other tools can use fabric as
+// their text-to-speech gateway.
+type SpeechHandler struct {
+	registry *core.PluginRegistry
+	// model and voice are used when the request has none
+	model string
+	voice string
+}
+
+// SpeechRequest is the body of POST /v1/audio/speech, as sent by OpenAI
+// clients
+type SpeechRequest struct {
+	Model          string `json:"model" example:"gpt-4o-mini-tts"`         // TTS model, --tts-model of the server by default
+	Input          string `json:"input" binding:"required"`                // Text to speak
+	Voice          string `json:"voice,omitempty" example:"alloy"`         // Voice of the vendor, --voice of the server by default
+	ResponseFormat string `json:"response_format,omitempty" example:"wav"` // wav (default) or pcm
+}
+
+func NewSpeechHandler(r *gin.Engine, registry *core.PluginRegistry, model, voice string) *SpeechHandler {
+	handler := &SpeechHandler{registry: registry, model: model, voice: voice}
+	r.POST("/v1/audio/speech", handler.Speech)
+	return handler
+}
+
+// Speech godoc
+// @Summary Synthesize speech
+// @Description Convert text to speech with Gemini for gemini-* models and OpenAI for the others, OpenAI-compatible. The audio is sent once the vendor synthesized it.
+// @Tags audio
+// @Accept json
+// @Produce audio/wav
+// @Produce audio/pcm
+// @Param request body SpeechRequest true "Text, model and voice"
+// @Success 200 {file} binary "Audio"
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /v1/audio/speech [post]
+func (h *SpeechHandler) Speech(c *gin.Context) {
+	var request SpeechRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_invalid_request_format"), err)})
+		return
+	}
+	if request.Model == "" {
+		request.Model = h.model
+	}
+	if request.Voice == "" {
+		request.Voice = h.voice
+	}
+	if request.Model == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("server_speech_model_required")})
+		return
+	}
+	format := strings.ToLower(request.ResponseFormat)
+	if format != "" && format != "wav" && format != "pcm" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("server_speech_unsupported_format"), request.ResponseFormat)})
+		return
+	}
+
+	vendorName := speechVendor(request.Model)
+	speaker, ok := h.registry.VendorManager.FindByName(vendorName).(ai.Speaker)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf(i18n.T("vendor_not_configured"), vendorName)})
+		return
+	}
+	audio, err := speaker.SynthesizeSpeech(c.Request.Context(), request.Input, request.Model, request.Voice)
+	if err != nil {
+		slog.Error("Synthesizing speech failed", "model", request.Model, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "pcm" {
+		c.Data(http.StatusOK, "audio/pcm", audio[min(wavHeaderSize, len(audio)):])
+		return
+	}
+	c.Data(http.StatusOK, "audio/wav", audio)
+}
+
+// speechVendor returns the vendor of the TTS model: Gemini for gemini-*
+// models, as --listen does, and OpenAI for the others
+func speechVendor(model string) string {
+	if strings.HasPrefix(strings.ToLower(model), "gemini") {
+		return "Gemini"
+	}
+	return "OpenAI"
+}
@@ -0,0 +1,67 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/mock"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeSpeaker is an OpenAI vendor returning the request as its audio
+type fakeSpeaker struct {
+	*mock.Client
+}
+
+func (o *fakeSpeaker) GetName() string {
+	return "OpenAI"
+}
+
+func (o *fakeSpeaker) SynthesizeSpeech(_ context.Context, text, model, voice string) ([]byte, error) {
+	return []byte(strings.Repeat("h", wavHeaderSize) + text + "|" + model + "|" + voice), nil
+}
+
+func TestSpeechHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry, err := core.NewPluginRegistry(fsdb.NewDb(t.TempDir()))
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	registry.VendorManager.AddVendors(&fakeSpeaker{Client: mock.NewClient()})
+	r := gin.New()
+	r.Use(APIKeyMiddleware("secret"))
+	NewSpeechHandler(r, registry, "gpt-4o-mini-tts", "alloy")
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/audio/speech", strings.NewReader(body))
+		// OpenAI clients send the key as a bearer token
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"input":"hello"}`)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "audio/wav" || !strings.HasSuffix(w.Body.String(), "hello|gpt-4o-mini-tts|alloy") {
+		t.Errorf("POST with the defaults = %d %s %q", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	w = post(`{"input":"hi","model":"tts-1","voice":"nova","response_format":"pcm"}`)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "audio/pcm" || w.Body.String() != "hi|tts-1|nova" {
+		t.Errorf("POST as pcm = %d %s %q, want the audio without its WAV header", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+
+	for body, want := range map[string]int{
+		`{"model":"tts-1"}`:                                     http.StatusBadRequest,
+		`{"input":"hi","response_format":"mp3"}`:                http.StatusBadRequest,
+		`{"input":"hi","model":"gemini-2.5-flash-preview-tts"}`: http.StatusBadRequest, // Gemini is not configured
+	} {
+		if w = post(body); w.Code != want {
+			t.Errorf("POST %s = %d, want %d", body, w.Code, want)
+		}
+	}
+}
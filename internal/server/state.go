@@ -0,0 +1,147 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// errTooManyJobs tells that a job cannot be kept, its client having
+// maxPendingJobs queued or running or the store being full
+var errTooManyJobs = errors.New("too many jobs")
+
+// JobStore keeps the jobs until jobRetention after they finished.
+type JobStore interface {
+	// Add keeps the new job, failing with errTooManyJobs when there is no room
+	// for it
+	Add(ctx context.Context, job *Job) error
+	// Save updates the status and the results of the job
+	Save(ctx context.Context, job *Job) error
+	// Get returns the job of the ID, nil if there is none
+	Get(ctx context.Context, id string) (*Job, error)
+}
+
+// SessionStore keeps the sessions, as the JSON of their messages, in a store
+// shared by the replicas of the server.
+type SessionStore interface {
+	// Load returns the session, found false if there is none
+	Load(ctx context.Context, name string) (data []byte, found bool, err error)
+	Save(ctx context.Context, name string, data []byte) error
+	Delete(ctx context.Context, name string) error
+	Names(ctx context.Context) ([]string, error)
+}
+
+// ServerState is the state of the server: the jobs, and the sessions when
+// they are shared by its replicas rather than kept in the local files.
+type ServerState struct {
+	Jobs JobStore
+	// Sessions is nil for the sessions of the local files
+	Sessions SessionStore
+}
+
+// NewServerState returns the state of the --serve-state value: empty to keep
+// it in the server process, a redis:// or rediss:// URL of the Redis server
+// shared by the replicas, or a postgres:// URL of their PostgreSQL database.
+func NewServerState(spec string) (*ServerState, error) {
+	switch {
+	case spec == "":
+		return &ServerState{Jobs: newMemoryJobs()}, nil
+	case isPostgresURL(spec):
+		db, err := newPostgresDB(spec)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("server_state_invalid"), spec)
+		}
+		return &ServerState{Jobs: &postgresJobs{db: db}, Sessions: &postgresSessions{db: db}}, nil
+	}
+	client, err := newRedisClient(spec)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("server_state_invalid"), spec)
+	}
+	return &ServerState{Jobs: &redisJobs{client: client}, Sessions: &redisSessions{client: client}}, nil
+}
+
+// memoryJobs keeps the jobs in the server process, up to maxJobs of them.
+type memoryJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+func newMemoryJobs() *memoryJobs {
+	return &memoryJobs{jobs: map[string]*Job{}}
+}
+
+func (o *memoryJobs) Add(_ context.Context, job *Job) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.removeExpired()
+	if o.pending(job.client) >= maxPendingJobs || !o.makeRoom() {
+		return errTooManyJobs
+	}
+	kept := *job
+	o.jobs[job.ID] = &kept
+	return nil
+}
+
+func (o *memoryJobs) Save(_ context.Context, job *Job) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	kept := *job
+	o.jobs[job.ID] = &kept
+	return nil
+}
+
+func (o *memoryJobs) Get(_ context.Context, id string) (*Job, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.removeExpired()
+	job, ok := o.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// removeExpired forgets the jobs finished for longer than jobRetention. The
+// lock must be held.
+func (o *memoryJobs) removeExpired() {
+	for id, job := range o.jobs {
+		if job.Finished != nil && time.Since(*job.Finished) > jobRetention {
+			delete(o.jobs, id)
+		}
+	}
+}
+
+// pending returns how many jobs of the client are queued or running. The lock
+// must be held.
+func (o *memoryJobs) pending(client string) (ret int) {
+	for _, job := range o.jobs {
+		if job.client == client && job.Finished == nil {
+			ret++
+		}
+	}
+	return
+}
+
+// makeRoom forgets the oldest finished job when maxJobs are kept, and reports
+// whether a new job can be kept. The lock must be held.
+func (o *memoryJobs) makeRoom() bool {
+	if len(o.jobs) < maxJobs {
+		return true
+	}
+	var oldest *Job
+	for _, job := range o.jobs {
+		if job.Finished != nil && (oldest == nil || job.Finished.Before(*oldest.Finished)) {
+			oldest = job
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	delete(o.jobs, oldest.ID)
+	return true
+}
@@ -0,0 +1,198 @@
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// sseHeartbeat is how often a comment is sent while the model is silent,
+	// so that proxies do not close a stream they think idle
+	sseHeartbeat = 15 * time.Second
+	// streamResumeGrace is how long a chat goes on without clients, waiting
+	// for one to resume its stream before it is cancelled
+	streamResumeGrace = time.Minute
+	// streamRetention is how long the events of a finished chat can be resumed
+	streamRetention = 5 * time.Minute
+)
+
+// chatStream is the events of a chat, kept so that clients losing the stream
+// can resume it with the ID of the last event they got. The chat goes on while
+// a client follows it, or during streamResumeGrace after the last one left.
+type chatStream struct {
+	ID string
+	// client started the chat, and is the only one to resume it
+	client string
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	events   []StreamResponse
+	done     bool
+	attached int
+	// changed is closed, then replaced, when an event is added or the chat
+	// finishes
+	changed chan struct{}
+}
+
+func newChatStream(client string, cancel context.CancelFunc) *chatStream {
+	return &chatStream{ID: rand.Text(), client: client, cancel: cancel, changed: make(chan struct{})}
+}
+
+// add adds the event and wakes the clients up
+func (o *chatStream) add(event StreamResponse) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+	close(o.changed)
+	o.changed = make(chan struct{})
+}
+
+// finish tells the clients there are no more events
+func (o *chatStream) finish() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.done = true
+	close(o.changed)
+	o.changed = make(chan struct{})
+}
+
+// since returns the events after the first ones, whether the chat finished,
+// and the channel closed when this changes
+func (o *chatStream) since(from int) (events []StreamResponse, done bool, changed <-chan struct{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if from < len(o.events) {
+		events = append(events, o.events[from:]...)
+	}
+	return events, o.done, o.changed
+}
+
+func (o *chatStream) attach() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.attached++
+}
+
+// detach cancels the chat when no client resumed it within the grace period
+func (o *chatStream) detach(grace time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.attached--; o.attached > 0 || o.done {
+		return
+	}
+	time.AfterFunc(grace, func() {
+		o.mu.Lock()
+		abandoned := o.attached == 0 && !o.done
+		o.mu.Unlock()
+		if abandoned {
+			o.cancel()
+		}
+	})
+}
+
+// eventID returns the ID of the event at the position, counted from 1
+func (o *chatStream) eventID(position int) string {
+	return o.ID + ":" + strconv.Itoa(position)
+}
+
+// streamStore keeps the chat streams until streamRetention after they
+// finished.
+type streamStore struct {
+	mu      sync.Mutex
+	streams map[string]*chatStream
+}
+
+func newStreamStore() *streamStore {
+	return &streamStore{streams: map[string]*chatStream{}}
+}
+
+func (o *streamStore) add(stream *chatStream) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.streams[stream.ID] = stream
+}
+
+func (o *streamStore) get(id string) *chatStream {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.streams[id]
+}
+
+// expire forgets the finished stream after streamRetention
+func (o *streamStore) expire(stream *chatStream) {
+	time.AfterFunc(streamRetention, func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		delete(o.streams, stream.ID)
+	})
+}
+
+// follow writes the events of the stream after the first ones, then those
+// added until the chat finishes or the client leaves, with a comment every
+// heartbeat while there are none
+func (h *ChatHandler) follow(c *gin.Context, stream *chatStream, from int) {
+	stream.attach()
+	defer stream.detach(h.resumeGrace)
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	clientGone := c.Request.Context().Done()
+	for {
+		events, done, changed := stream.since(from)
+		for _, event := range events {
+			from++
+			if err := writeSSEEvent(c.Writer, stream.eventID(from), event); err != nil {
+				return
+			}
+		}
+		if done {
+			return
+		}
+		select {
+		case <-changed:
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-clientGone:
+			return
+		}
+	}
+}
+
+// ResumeChat godoc
+// @Summary Resume a chat stream
+// @Description Stream the events of a chat after the one of the Last-Event-ID header, then the next ones until it finishes. The chat goes on for a minute after its client left, and can be resumed for five minutes after it finished.
+// @Tags chat
+// @Produce text/event-stream
+// @Param id path string true "Stream ID, from the X-Stream-ID header of POST /chat"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {object} StreamResponse "Streaming response"
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /chat/streams/{id} [get]
+func (h *ChatHandler) ResumeChat(c *gin.Context) {
+	stream := h.streams.get(c.Param("id"))
+	// The streams of other clients are not found either
+	if stream == nil || stream.client != requestClient(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": i18n.T("server_stream_not_found")})
+		return
+	}
+	from := 0
+	if id, position, found := strings.Cut(c.GetHeader("Last-Event-ID"), ":"); found && id == stream.ID {
+		from, _ = strconv.Atoi(position)
+	}
+	setSSEHeaders(c)
+	c.Writer.Header().Set("X-Stream-ID", stream.ID)
+	h.follow(c, stream, max(from, 0))
+}
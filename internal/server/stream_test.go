@@ -0,0 +1,110 @@
+package restapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+func TestChatStreamResume(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("MOCK_LATENCY", "20ms")
+	db := fsdb.NewDb(t.TempDir())
+	registry, err := core.NewPluginRegistry(db)
+	if err != nil {
+		t.Fatalf("NewPluginRegistry() error = %v", err)
+	}
+	registry.ConfigureVendors()
+	r := gin.New()
+	handler := NewChatHandler(r, registry, db, NewRequestQueue(0), nil, nil)
+	handler.heartbeat = 5 * time.Millisecond
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	do := func(method, path, body string, headers map[string]string) (*http.Response, string) {
+		request, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		defer response.Body.Close()
+		data, _ := io.ReadAll(response.Body)
+		return response, string(data)
+	}
+
+	response, body := do(http.MethodPost, "/chat", `{"prompts":[{"userInput":"one two three","vendor":"Mock","model":"echo"}]}`, nil)
+	streamID := response.Header.Get("X-Stream-ID")
+	if streamID == "" {
+		t.Fatalf("POST /chat has no X-Stream-ID header")
+	}
+	if !strings.Contains(body, ": keep-alive\n\n") {
+		t.Errorf("POST /chat has no heartbeat while the model is slow: %s", body)
+	}
+	var ids []string
+	for _, line := range strings.Split(body, "\n") {
+		if id, found := strings.CutPrefix(line, "id: "); found {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < 3 || ids[0] != streamID+":1" {
+		t.Fatalf("event ids = %v, want %s:1 and the next ones", ids, streamID)
+	}
+
+	// Resuming after the second event streams the next ones only
+	response, resumed := do(http.MethodGet, "/chat/streams/"+streamID, "", map[string]string{"Last-Event-ID": ids[1]})
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("GET /chat/streams = %d %s", response.StatusCode, resumed)
+	}
+	_, rest, _ := strings.Cut(body, "id: "+ids[2]+"\n")
+	if sseData(resumed) != sseData("id: "+ids[2]+"\n"+rest) || !strings.HasPrefix(resumed, "id: "+ids[2]+"\n") {
+		t.Errorf("resumed stream = %q, want the events after %s", resumed, ids[1])
+	}
+
+	if response, _ = do(http.MethodGet, "/chat/streams/unknown", "", nil); response.StatusCode != http.StatusNotFound {
+		t.Errorf("GET an unknown stream = %d, want 404", response.StatusCode)
+	}
+	if response, _ = do(http.MethodGet, "/chat/streams/"+streamID, "", map[string]string{"X-API-Key": "other"}); response.StatusCode != http.StatusNotFound {
+		t.Errorf("GET the stream of another client = %d, want 404", response.StatusCode)
+	}
+}
+
+func TestChatStreamCancelledWithoutClients(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := newChatStream("client", cancel)
+
+	stream.attach()
+	stream.attach()
+	stream.detach(0)
+	time.Sleep(10 * time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatalf("the chat was cancelled while a client follows it")
+	}
+
+	stream.detach(0)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Errorf("the chat goes on without clients")
+	}
+
+	finished, cancelFinished := context.WithCancel(context.Background())
+	stream = newChatStream("client", cancelFinished)
+	stream.attach()
+	stream.finish()
+	stream.detach(0)
+	time.Sleep(10 * time.Millisecond)
+	if finished.Err() != nil {
+		t.Errorf("a finished chat was cancelled")
+	}
+}
@@ -0,0 +1,86 @@
+package restapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// TLSOptions enables HTTPS on the server when CertFile and KeyFile are set,
+// and requires client certificates signed by ClientCAFile when it is set.
+type TLSOptions struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// Enabled tells whether the server must use HTTPS
+func (o *TLSOptions) Enabled() bool {
+	return o != nil && (o.CertFile != "" || o.KeyFile != "" || o.ClientCAFile != "")
+}
+
+func newTLSConfig(options *TLSOptions) (ret *tls.Config, err error) {
+	if options.CertFile == "" || options.KeyFile == "" {
+		return nil, errors.New(i18n.T("server_tls_cert_and_key_required"))
+	}
+	reloader := &certReloader{certFile: options.CertFile, keyFile: options.KeyFile}
+	if _, err = reloader.GetCertificate(nil); err != nil {
+		return
+	}
+	ret = &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: reloader.GetCertificate}
+
+	if options.ClientCAFile != "" {
+		var pem []byte
+		if pem, err = os.ReadFile(options.ClientCAFile); err != nil {
+			return nil, fmt.Errorf(i18n.T("server_tls_client_ca_failed"), options.ClientCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf(i18n.T("server_tls_client_ca_failed"), options.ClientCAFile, errors.New(i18n.T("server_tls_no_certificates")))
+		}
+		ret.ClientCAs = pool
+		ret.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return
+}
+
+// certReloader loads the certificate again when its files change, so that
+// renewed certificates are used without a restart.
+type certReloader struct {
+	certFile, keyFile string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modTimes [2]time.Time
+}
+
+func (o *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	var modTimes [2]time.Time
+	for i, path := range []string{o.certFile, o.keyFile} {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[i] = info.ModTime()
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.cert != nil && modTimes == o.modTimes {
+		return o.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(o.certFile, o.keyFile)
+	if err != nil {
+		if o.cert != nil {
+			// Keep serving the previous certificate while the files are being replaced
+			return o.cert, nil
+		}
+		return nil, fmt.Errorf(i18n.T("server_tls_cert_failed"), o.certFile, err)
+	}
+	o.cert, o.modTimes = &cert, modTimes
+	return o.cert, nil
+}
@@ -0,0 +1,31 @@
+package restapi
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uiFiles is the chat UI, served with the binary so that --serve is usable
+// without the web project
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// NewUIHandler serves the chat UI at /ui/. Its files are public, the API key
+// being entered in the page and sent with its API requests.
+func NewUIHandler(r *gin.Engine) {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	r.StaticFS("/ui", http.FS(files))
+	// Relative, so that it works under --base-path, which http.Redirect would
+	// make absolute
+	r.GET("/ui", func(c *gin.Context) {
+		c.Header("Location", "ui/")
+		c.Status(http.StatusMovedPermanently)
+	})
+}
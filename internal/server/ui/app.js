@@ -0,0 +1,227 @@
+// The chat UI of fabric --serve, calling the REST API it is served with.
+"use strict";
+
+// The API is one level up from /ui/, under the --base-path if any
+const api = new URL("..", location.href);
+
+const $ = (id) => document.getElementById(id);
+const apiKey = $("api-key");
+const messages = $("messages");
+const sessionInput = $("session");
+const status = $("status");
+let controller = null;
+
+apiKey.value = localStorage.getItem("fabric-api-key") || "";
+apiKey.addEventListener("change", () => {
+  localStorage.setItem("fabric-api-key", apiKey.value);
+  loadAll();
+});
+
+async function request(path, options = {}) {
+  const headers = { ...(options.headers || {}) };
+  if (apiKey.value) {
+    headers["X-API-Key"] = apiKey.value;
+  }
+  const response = await fetch(new URL(path, api), { ...options, headers });
+  if (!response.ok) {
+    let message = response.statusText;
+    try {
+      const body = await response.json();
+      message = body.error || message;
+    } catch {
+      // Not a JSON error
+    }
+    throw new Error(`${response.status} ${message}`);
+  }
+  return response;
+}
+
+async function getJSON(path) {
+  return (await request(path)).json();
+}
+
+function addMessage(role, content = "") {
+  const div = document.createElement("div");
+  div.className = `message ${role}`;
+  const label = document.createElement("span");
+  label.className = "role";
+  label.textContent = role;
+  const text = document.createElement("span");
+  text.textContent = content;
+  div.append(label, text);
+  messages.append(div);
+  messages.scrollTop = messages.scrollHeight;
+  return text;
+}
+
+async function loadPatterns() {
+  const select = $("pattern");
+  const names = await getJSON("patterns/names");
+  select.length = 1;
+  for (const name of names.sort()) {
+    select.add(new Option(name, name));
+  }
+}
+
+async function loadModels() {
+  const select = $("model");
+  const { vendors } = await getJSON("models/names");
+  select.length = 1;
+  for (const vendor of Object.keys(vendors).sort()) {
+    const group = document.createElement("optgroup");
+    group.label = vendor;
+    for (const model of vendors[vendor]) {
+      group.append(new Option(model, `${vendor}|${model}`));
+    }
+    select.append(group);
+  }
+}
+
+async function loadSessions() {
+  const list = $("sessions");
+  const names = await getJSON("sessions/names");
+  list.replaceChildren();
+  for (const name of names) {
+    const item = document.createElement("li");
+    item.textContent = name;
+    item.title = name;
+    item.classList.toggle("active", name === sessionInput.value);
+    item.addEventListener("click", () => openSession(name));
+    list.append(item);
+  }
+}
+
+async function openSession(name) {
+  sessionInput.value = name;
+  messages.replaceChildren();
+  try {
+    const session = await getJSON(`sessions/${encodeURIComponent(name)}`);
+    for (const message of session.Messages || []) {
+      if (message.role !== "meta" && message.content) {
+        addMessage(message.role, message.content);
+      }
+    }
+  } catch (error) {
+    addMessage("error", error.message);
+  }
+  for (const item of $("sessions").children) {
+    item.classList.toggle("active", item.textContent === name);
+  }
+}
+
+async function loadAll() {
+  status.textContent = "";
+  const results = await Promise.allSettled([loadPatterns(), loadModels(), loadSessions()]);
+  const failed = results.find((result) => result.status === "rejected");
+  if (failed) {
+    status.textContent = failed.reason.message;
+  }
+}
+
+// send posts the prompt to /chat and streams the Server-Sent Events of the
+// response into a new message
+async function send(userInput) {
+  const [vendor, model] = $("model").value ? $("model").value.split("|") : ["", ""];
+  const prompt = {
+    userInput,
+    vendor,
+    model,
+    patternName: $("pattern").value,
+    sessionName: sessionInput.value.trim(),
+  };
+  addMessage("user", userInput);
+  const output = addMessage("assistant");
+
+  controller = new AbortController();
+  $("send").disabled = true;
+  $("stop").hidden = false;
+  status.textContent = "Waiting for the model…";
+  try {
+    const response = await request("chat", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ prompts: [prompt] }),
+      signal: controller.signal,
+    });
+    const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+    let buffer = "";
+    for (;;) {
+      const { value, done } = await reader.read();
+      if (done) {
+        break;
+      }
+      buffer += value;
+      let end;
+      while ((end = buffer.indexOf("\n\n")) >= 0) {
+        const event = buffer.slice(0, end);
+        buffer = buffer.slice(end + 2);
+        for (const line of event.split("\n")) {
+          if (line.startsWith("data: ")) {
+            handleEvent(JSON.parse(line.slice(6)), output);
+          }
+        }
+      }
+    }
+    status.textContent = "";
+  } catch (error) {
+    status.textContent = error.name === "AbortError" ? "Stopped" : "";
+    if (error.name !== "AbortError") {
+      output.parentElement.classList.add("error");
+      output.textContent += error.message;
+    }
+  } finally {
+    controller = null;
+    $("send").disabled = false;
+    $("stop").hidden = true;
+  }
+  if (prompt.sessionName) {
+    loadSessions().catch(() => {});
+  }
+}
+
+function handleEvent(event, output) {
+  switch (event.type) {
+    case "content":
+      status.textContent = "";
+      output.textContent += event.content;
+      messages.scrollTop = messages.scrollHeight;
+      break;
+    case "error":
+      output.parentElement.classList.add("error");
+      output.textContent += event.content;
+      break;
+    case "usage":
+      if (event.usage) {
+        status.textContent = `${event.usage.input_tokens} input, ${event.usage.output_tokens} output tokens`;
+      }
+      break;
+  }
+}
+
+$("prompt").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const input = $("input");
+  if (controller || !input.value.trim()) {
+    return;
+  }
+  send(input.value);
+  input.value = "";
+});
+
+$("input").addEventListener("keydown", (event) => {
+  if (event.key === "Enter" && (event.ctrlKey || event.metaKey)) {
+    $("prompt").requestSubmit();
+  }
+});
+
+$("stop").addEventListener("click", () => controller?.abort());
+
+$("new-session").addEventListener("click", () => {
+  sessionInput.value = "";
+  messages.replaceChildren();
+  for (const item of $("sessions").children) {
+    item.classList.remove("active");
+  }
+});
+
+loadAll();
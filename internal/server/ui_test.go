@@ -0,0 +1,40 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUIHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(APIKeyMiddleware("secret"))
+	NewUIHandler(r)
+	r.GET("/patterns/names", func(c *gin.Context) { c.JSON(http.StatusOK, []string{}) })
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// The UI is served without the API key, which it sends itself
+	for path, want := range map[string]string{"/ui/": "<title>Fabric</title>", "/ui/app.js": "X-API-Key", "/ui/style.css": "#messages"} {
+		if w := get(path); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("GET %s = %d, want the file with %q", path, w.Code, want)
+		}
+	}
+	if w := get("/ui"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "ui/" {
+		t.Errorf("GET /ui = %d to %q, want a relative redirect to ui/", w.Code, w.Header().Get("Location"))
+	}
+	if w := get("/ui/missing.js"); w.Code != http.StatusNotFound {
+		t.Errorf("GET /ui/missing.js = %d, want 404", w.Code)
+	}
+	if w := get("/patterns/names"); w.Code != http.StatusUnauthorized {
+		t.Errorf("GET /patterns/names without the key = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,460 @@
+// Package anki turns the question and answer pairs of the responses, such as
+// those of to_flashcards, into Anki decks: packages (.apkg) or the text files
+// of the Anki import (.csv, .txt).
+package anki
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// Formats are the extensions of the files the decks are written to
+var Formats = []string{".apkg", ".csv", ".txt"}
+
+// Card is a note of the deck, a question with its answer, as plain text
+type Card struct {
+	Front string   `json:"front"`
+	Back  string   `json:"back"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+var (
+	fenceRegex    = regexp.MustCompile("(?m)^[ \t]*(```+|~~~+).*$")
+	questionRegex = regexp.MustCompile(`(?i)^\s*(?:[-*]\s+|\d+[.)]\s+)?(?:Q|Question)\s*:\s*(.*)$`)
+	answerRegex   = regexp.MustCompile(`(?i)^\s*(?:A|Answer)\s*:\s*(.*)$`)
+	// The answers following their question on the same line, as in
+	// "Q: Where is the Dead Sea? A: between Israel and Jordan"
+	inlineAnswerRegex = regexp.MustCompile(`^(.*?)\s+(?:A|Answer):\s*(.*)$`)
+	tableRowRegex     = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	tableRuleRegex    = regexp.MustCompile(`^\s*\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*$`)
+)
+
+// ParseCards returns the cards of the response, in the first format found:
+// a JSON array of cards, "Q: ... A: ..." lines, a Markdown table, or else CSV
+// rows of questions and answers as to_flashcards outputs
+func ParseCards(text string) ([]Card, error) {
+	text = strings.TrimSpace(fenceRegex.ReplaceAllString(text, ""))
+	switch {
+	case strings.HasPrefix(text, "["):
+		return parseJSON(text)
+	case slices.ContainsFunc(strings.Split(text, "\n"), questionRegex.MatchString):
+		return parseQuestions(text), nil
+	case tableRowRegex.MatchString(strings.SplitN(text, "\n", 2)[0]):
+		return parseTable(text), nil
+	}
+	return parseCSV(text), nil
+}
+
+func parseJSON(text string) (ret []Card, err error) {
+	var cards []struct {
+		Card
+		// The cards can also name their sides question and answer
+		Question string `json:"question"`
+		Answer   string `json:"answer"`
+	}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&cards); err != nil {
+		return nil, fmt.Errorf(i18n.T("anki_error_invalid_json"), err)
+	}
+	for _, card := range cards {
+		ret = append(ret, Card{
+			Front: strings.TrimSpace(card.Front + card.Question),
+			Back:  strings.TrimSpace(card.Back + card.Answer),
+			Tags:  card.Tags,
+		})
+	}
+	return
+}
+
+// parseQuestions parses the "Q:" lines and their "A:" answers, on the same
+// line or the next ones
+func parseQuestions(text string) (ret []Card) {
+	var card *Card
+	inAnswer := false
+	flush := func() {
+		if card != nil {
+			ret = append(ret, *card)
+		}
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		line = strings.TrimSpace(line)
+		if match := questionRegex.FindStringSubmatch(line); match != nil {
+			flush()
+			card, inAnswer = &Card{Front: match[1]}, false
+			if answer := inlineAnswerRegex.FindStringSubmatch(match[1]); answer != nil {
+				card.Front, card.Back, inAnswer = answer[1], answer[2], true
+			}
+			continue
+		}
+		if card == nil || line == "" {
+			continue
+		}
+		if match := answerRegex.FindStringSubmatch(line); match != nil && !inAnswer {
+			card.Back, inAnswer = match[1], true
+		} else if inAnswer {
+			card.Back += "\n" + line
+		} else {
+			card.Front += "\n" + line
+		}
+	}
+	flush()
+	return
+}
+
+// parseTable parses the rows of a Markdown table, skipping its header
+func parseTable(text string) (ret []Card) {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if !tableRowRegex.MatchString(line) || tableRuleRegex.MatchString(line) ||
+			i+1 < len(lines) && tableRuleRegex.MatchString(lines[i+1]) {
+			continue
+		}
+		cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+		if len(cells) >= 2 {
+			ret = append(ret, Card{Front: strings.TrimSpace(cells[0]), Back: strings.TrimSpace(cells[1])})
+		}
+	}
+	return
+}
+
+// parseCSV parses the rows of questions and answers, the commas of answers
+// models forgot to quote being kept in them
+func parseCSV(text string) (ret []Card) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	for i := 0; ; i++ {
+		record, err := reader.Read()
+		if err != nil {
+			// Lines that are not CSV, like comments of the model, are skipped
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				continue
+			}
+			return
+		}
+		if len(record) < 2 || i == 0 && isHeader(record[0], record[1]) {
+			continue
+		}
+		ret = append(ret, Card{Front: strings.TrimSpace(record[0]), Back: strings.TrimSpace(strings.Join(record[1:], ","))})
+	}
+}
+
+func isHeader(front, back string) bool {
+	front, back = strings.ToLower(strings.TrimSpace(front)), strings.ToLower(strings.TrimSpace(back))
+	return front == "question" && back == "answer" || front == "front" && back == "back"
+}
+
+// Validate checks the cards against the schema of the notes: at least one
+// card, each with a question and an answer, questions being unique as Anki
+// tells notes apart by their first field, and tags without spaces
+func Validate(cards []Card) error {
+	if len(cards) == 0 {
+		return errors.New(i18n.T("anki_error_no_cards"))
+	}
+	var errs []error
+	questions := map[string]int{}
+	for i, card := range cards {
+		number := i + 1
+		if card.Front == "" {
+			errs = append(errs, fmt.Errorf(i18n.T("anki_error_empty_question"), number))
+		} else if first, ok := questions[card.Front]; ok {
+			errs = append(errs, fmt.Errorf(i18n.T("anki_error_duplicate_question"), number, first))
+		} else {
+			questions[card.Front] = number
+		}
+		if card.Back == "" {
+			errs = append(errs, fmt.Errorf(i18n.T("anki_error_empty_answer"), number))
+		}
+		for _, tag := range card.Tags {
+			if tag == "" || strings.ContainsFunc(tag, isSpace) {
+				errs = append(errs, fmt.Errorf(i18n.T("anki_error_invalid_tag"), number, tag))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// CheckOutput returns an error when the deck cannot be written to the file,
+// by its extension
+func CheckOutput(output string) error {
+	if !slices.Contains(Formats, strings.ToLower(filepath.Ext(output))) {
+		return fmt.Errorf(i18n.T("anki_error_format"), output, strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+// DeckName returns the name of the deck written to the file, its base name
+func DeckName(output string) string {
+	return strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+}
+
+// Write writes the cards as a deck named after the file: an Anki package, or
+// a text file to import, comma separated for .csv and tab separated for .txt
+func Write(cards []Card, output string) (err error) {
+	if err = CheckOutput(output); err != nil {
+		return
+	}
+	var data []byte
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".apkg":
+		data, err = Package(cards, DeckName(output), time.Now())
+	case ".csv":
+		data, err = ImportText(cards, DeckName(output), ',')
+	default:
+		data, err = ImportText(cards, DeckName(output), '\t')
+	}
+	if err != nil {
+		return
+	}
+	return os.WriteFile(output, data, 0o644)
+}
+
+// ImportText returns the cards as an Anki import file, with the headers
+// telling Anki the separator, the deck and the columns of the fields
+func ImportText(cards []Card, deck string, separator rune) ([]byte, error) {
+	var buf bytes.Buffer
+	name := "Comma"
+	if separator == '\t' {
+		name = "Tab"
+	}
+	columns := strings.Join([]string{"Front", "Back", "Tags"}, string(separator))
+	fmt.Fprintf(&buf, "#separator:%s\n#html:true\n#notetype:Basic\n#deck:%s\n#columns:%s\n#tags column:3\n",
+		name, deck, columns)
+	writer := csv.NewWriter(&buf)
+	writer.Comma = separator
+	for _, card := range cards {
+		if err := writer.Write([]string{fieldHTML(card.Front), fieldHTML(card.Back), strings.Join(card.Tags, " ")}); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// fieldHTML returns the text as the HTML of a field
+func fieldHTML(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+// modelID is the id of the note type of the packages, the same for every
+// deck so that importing them does not add note types
+const modelID int64 = 1729000000000
+
+// Package returns the cards as an Anki package: a zip of the collection, an
+// SQLite database of the schema Anki imports from every version, without
+// media
+func Package(cards []Card, deck string, now time.Time) (ret []byte, err error) {
+	var collection []byte
+	if collection, err = collectionDB(cards, deck, now); err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{{"collection.anki2", collection}, {"media", []byte("{}")}} {
+		var w io.Writer
+		if w, err = archive.Create(file.name); err != nil {
+			return
+		}
+		if _, err = w.Write(file.data); err != nil {
+			return
+		}
+	}
+	if err = archive.Close(); err != nil {
+		return
+	}
+	return buf.Bytes(), nil
+}
+
+// collectionDB returns the collection database of the deck, of schema 11
+func collectionDB(cards []Card, deck string, now time.Time) (ret []byte, err error) {
+	deckID := deckID(deck)
+	millis, seconds := now.UnixMilli(), now.Unix()
+	var conf, models, decks, dconf []byte
+	if conf, err = json.Marshal(map[string]any{
+		"activeDecks": []int64{deckID}, "curDeck": deckID, "newSpread": 0, "collapseTime": 1200,
+		"timeLim": 0, "estTimes": true, "dueCounts": true, "curModel": strconv.FormatInt(modelID, 10),
+		"nextPos": len(cards) + 1, "sortType": "noteFld", "sortBackwards": false, "addToCur": true,
+	}); err != nil {
+		return
+	}
+	if models, err = json.Marshal(map[string]any{strconv.FormatInt(modelID, 10): noteType(deckID, seconds)}); err != nil {
+		return
+	}
+	if decks, err = json.Marshal(map[string]any{
+		"1":                           deckJSON(1, "Default", seconds),
+		strconv.FormatInt(deckID, 10): deckJSON(deckID, deck, seconds),
+	}); err != nil {
+		return
+	}
+	if dconf, err = json.Marshal(map[string]any{"1": deckConfig()}); err != nil {
+		return
+	}
+
+	db := newSQLiteDB()
+	db.createTable("col", collectionSchema[0], []sqliteRow{{1, []any{
+		nil, startOfDay(now), millis, millis, int64(11), int64(0), int64(0), int64(0),
+		string(conf), string(models), string(decks), string(dconf), "{}",
+	}}})
+
+	var notes, cardRows []sqliteRow
+	var notesUsn, notesCsum, cardsUsn, cardsNid, cardsSched [][]int64
+	for i, card := range cards {
+		id := millis + int64(i)
+		tags := ""
+		if len(card.Tags) > 0 {
+			tags = " " + strings.Join(card.Tags, " ") + " "
+		}
+		sortField := strings.Join(strings.Fields(card.Front), " ")
+		csum := checksum(sortField)
+		notes = append(notes, sqliteRow{id, []any{
+			nil, guid(deck, card.Front), modelID, seconds, int64(-1), tags,
+			fieldHTML(card.Front) + "\x1f" + fieldHTML(card.Back), sortField, csum, int64(0), "",
+		}})
+		notesUsn = append(notesUsn, []int64{-1, id})
+		notesCsum = append(notesCsum, []int64{csum, id})
+		// New cards are due in the order of the response
+		due := int64(i + 1)
+		cardRows = append(cardRows, sqliteRow{id, []any{
+			nil, id, deckID, int64(0), seconds, int64(-1), int64(0), int64(0), due,
+			int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), "",
+		}})
+		cardsUsn = append(cardsUsn, []int64{-1, id})
+		cardsNid = append(cardsNid, []int64{id, id})
+		cardsSched = append(cardsSched, []int64{deckID, 0, due, id})
+	}
+	db.createTable("notes", collectionSchema[1], notes)
+	db.createTable("cards", collectionSchema[2], cardRows)
+	db.createTable("revlog", collectionSchema[3], nil)
+	db.createTable("graves", collectionSchema[4], nil)
+	db.createIndex("ix_notes_usn", "notes", collectionSchema[5], notesUsn)
+	db.createIndex("ix_cards_usn", "cards", collectionSchema[6], cardsUsn)
+	db.createIndex("ix_revlog_usn", "revlog", collectionSchema[7], nil)
+	db.createIndex("ix_cards_nid", "cards", collectionSchema[8], cardsNid)
+	db.createIndex("ix_cards_sched", "cards", collectionSchema[9], cardsSched)
+	db.createIndex("ix_revlog_cid", "revlog", collectionSchema[10], nil)
+	db.createIndex("ix_notes_csum", "notes", collectionSchema[11], notesCsum)
+	return db.bytes(), nil
+}
+
+// collectionSchema are the tables and indexes of the collections of schema 11
+var collectionSchema = []string{
+	"CREATE TABLE col (id integer primary key, crt integer not null, mod integer not null, scm integer not null, " +
+		"ver integer not null, dty integer not null, usn integer not null, ls integer not null, conf text not null, " +
+		"models text not null, decks text not null, dconf text not null, tags text not null)",
+	"CREATE TABLE notes (id integer primary key, guid text not null, mid integer not null, mod integer not null, " +
+		"usn integer not null, tags text not null, flds text not null, sfld integer not null, csum integer not null, " +
+		"flags integer not null, data text not null)",
+	"CREATE TABLE cards (id integer primary key, nid integer not null, did integer not null, ord integer not null, " +
+		"mod integer not null, usn integer not null, type integer not null, queue integer not null, due integer not null, " +
+		"ivl integer not null, factor integer not null, reps integer not null, lapses integer not null, left integer not null, " +
+		"odue integer not null, odid integer not null, flags integer not null, data text not null)",
+	"CREATE TABLE revlog (id integer primary key, cid integer not null, usn integer not null, ease integer not null, " +
+		"ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null, type integer not null)",
+	"CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null)",
+	"CREATE INDEX ix_notes_usn on notes (usn)",
+	"CREATE INDEX ix_cards_usn on cards (usn)",
+	"CREATE INDEX ix_revlog_usn on revlog (usn)",
+	"CREATE INDEX ix_cards_nid on cards (nid)",
+	"CREATE INDEX ix_cards_sched on cards (did, queue, due)",
+	"CREATE INDEX ix_revlog_cid on revlog (cid)",
+	"CREATE INDEX ix_notes_csum on notes (csum)",
+}
+
+// noteType is the Basic note type of the packages: a card showing the front,
+// then the back below it
+func noteType(deckID, mod int64) map[string]any {
+	field := func(name string, ord int) map[string]any {
+		return map[string]any{"name": name, "ord": ord, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []any{}}
+	}
+	return map[string]any{
+		"id": modelID, "name": "Basic (Fabric)", "type": 0, "mod": mod, "usn": -1, "sortf": 0, "did": deckID,
+		"tmpls": []any{map[string]any{
+			"name": "Card 1", "ord": 0, "qfmt": "{{Front}}", "afmt": "{{FrontSide}}\n\n<hr id=answer>\n\n{{Back}}",
+			"did": nil, "bqfmt": "", "bafmt": "",
+		}},
+		"flds":      []any{field("Front", 0), field("Back", 1)},
+		"css":       ".card {\n font-family: arial;\n font-size: 20px;\n text-align: center;\n color: black;\n background-color: white;\n}\n",
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"tags":      []any{}, "vers": []any{},
+		"req": []any{[]any{0, "any", []int{0}}},
+	}
+}
+
+func deckJSON(id int64, name string, mod int64) map[string]any {
+	return map[string]any{
+		"id": id, "name": name, "desc": "", "mod": mod, "usn": -1, "collapsed": false, "conf": 1, "dyn": 0,
+		"extendNew": 10, "extendRev": 50, "newToday": []int{0, 0}, "revToday": []int{0, 0},
+		"lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+	}
+}
+
+// deckConfig is the default options group of the decks
+func deckConfig() map[string]any {
+	return map[string]any{
+		"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true, "timer": 0, "replayq": true,
+		"new": map[string]any{
+			"bury": true, "delays": []int{1, 10}, "initialFactor": 2500, "ints": []int{1, 4, 7},
+			"order": 1, "perDay": 20, "separate": true,
+		},
+		"lapse": map[string]any{"delays": []int{10}, "leechAction": 0, "leechFails": 8, "minInt": 1, "mult": 0},
+		"rev": map[string]any{
+			"bury": true, "ease4": 1.3, "fuzz": 0.05, "ivlFct": 1, "maxIvl": 36500, "minSpace": 1, "perDay": 100,
+		},
+	}
+}
+
+// deckID returns the id of the deck, derived from its name so that the
+// packages of a deck are imported into the same deck
+func deckID(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return 1<<40 | int64(h.Sum64()>>24)
+}
+
+// guid returns the global id of the note of the question, derived from it so
+// that importing a deck again updates its notes instead of duplicating them
+func guid(deck, front string) string {
+	sum := sha256.Sum256([]byte(deck + "\x1f" + front))
+	return strconv.FormatUint(binary.BigEndian.Uint64(sum[:8]), 36)
+}
+
+// checksum returns the checksum Anki finds duplicates with: the first 8
+// hexadecimal digits of the SHA-1 of the sort field
+func checksum(field string) int64 {
+	sum := sha1.Sum([]byte(field))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+func startOfDay(now time.Time) int64 {
+	year, month, day := now.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, now.Location()).Unix()
+}
@@ -0,0 +1,169 @@
+package anki
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestParseCards(t *testing.T) {
+	deadSea := []Card{
+		{Front: "Where is the Dead Sea located?", Back: "on the border between Israel and Jordan"},
+		{Front: "How long is the Dead Sea?", Back: "70 km, or 43 miles"},
+	}
+	tests := []struct {
+		name string
+		text string
+		want []Card
+	}{
+		{
+			name: "CSV rows with unquoted commas",
+			text: "Where is the Dead Sea located?,on the border between Israel and Jordan\nHow long is the Dead Sea?,\"70 km, or 43 miles\"",
+			want: deadSea,
+		},
+		{
+			name: "CSV with a header in a code block",
+			text: "```csv\nQuestion,Answer\nWhere is the Dead Sea located?,on the border between Israel and Jordan\nHow long is the Dead Sea?,70 km, or 43 miles\n```",
+			want: deadSea,
+		},
+		{
+			name: "question and answer lines",
+			text: "Here are the cards:\n\nQ: Where is the Dead Sea located? A: on the border between Israel and Jordan\n" +
+				"1. Question: How long is the Dead Sea?\nAnswer: 70 km,\nor 43 miles",
+			want: []Card{deadSea[0], {Front: "How long is the Dead Sea?", Back: "70 km,\nor 43 miles"}},
+		},
+		{
+			name: "Markdown table",
+			text: "| Question | Answer |\n|---|:---|\n| Where is the Dead Sea located? | on the border between Israel and Jordan |\n| How long is the Dead Sea? | 70 km, or 43 miles |",
+			want: deadSea,
+		},
+		{
+			name: "JSON",
+			text: `[{"front": "Where is the Dead Sea located?", "back": "on the border between Israel and Jordan"},` +
+				`{"question": "How long is the Dead Sea?", "answer": "70 km, or 43 miles", "tags": ["geography"]}]`,
+			want: []Card{deadSea[0], {Front: deadSea[1].Front, Back: deadSea[1].Back, Tags: []string{"geography"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCards(tt.text)
+			if err != nil {
+				t.Fatalf("ParseCards() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCards() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseCards(`[{"front": "Q", "hint": "H"}]`); err == nil {
+		t.Errorf("ParseCards() of cards with unknown fields expected an error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate([]Card{{Front: "Q", Back: "A", Tags: []string{"a::b"}}}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := Validate(nil); err == nil {
+		t.Errorf("Validate() without cards expected an error")
+	}
+	err := Validate([]Card{{Front: "Q", Back: "A"}, {Front: "Q"}, {Back: "A", Tags: []string{"two words"}}})
+	if err == nil {
+		t.Fatalf("Validate() of invalid cards expected an error")
+	}
+	for _, want := range []string{"card 2 repeats the question of card 1", "card 2 has no answer", "card 3 has no question", `"two words"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestWriteImportText(t *testing.T) {
+	if err := CheckOutput("deck.zip"); err == nil {
+		t.Errorf("CheckOutput(deck.zip) expected an error")
+	}
+	output := filepath.Join(t.TempDir(), "Dead Sea.txt")
+	cards := []Card{{Front: "<b>Where</b>\tis it?", Back: "Israel\nJordan", Tags: []string{"geo", "sea"}}}
+	if err := Write(cards, output); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got, _ := os.ReadFile(output)
+	want := "#separator:Tab\n#html:true\n#notetype:Basic\n#deck:Dead Sea\n#columns:Front\tBack\tTags\n#tags column:3\n" +
+		"\"&lt;b&gt;Where&lt;/b&gt;\tis it?\"\tIsrael<br>Jordan\tgeo sea\n"
+	if string(got) != want {
+		t.Errorf("Write() wrote %q, want %q", got, want)
+	}
+}
+
+func TestPackage(t *testing.T) {
+	// Enough cards for the tables and indexes to span several levels of pages
+	var cards []Card
+	for i := range 20000 {
+		cards = append(cards, Card{Front: fmt.Sprintf("Question %d?", i), Back: strings.Repeat("answer ", i%900)})
+	}
+	data, err := Package(cards, "Trivia", time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Package() error = %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Package() is not a zip: %v", err)
+	}
+	file, err := archive.Open("collection.anki2")
+	if err != nil {
+		t.Fatalf("Package() has no collection: %v", err)
+	}
+	collection, _ := io.ReadAll(file)
+	path := filepath.Join(t.TempDir(), "collection.anki2")
+	if err = os.WriteFile(path, collection, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var check string
+	if err = db.QueryRow("PRAGMA integrity_check").Scan(&check); err != nil {
+		if strings.Contains(err.Error(), "cgo") {
+			t.Skip("reading the collection needs SQLite with cgo")
+		}
+		t.Fatalf("reading the collection: %v", err)
+	}
+	if check != "ok" {
+		t.Fatalf("integrity_check = %s", check)
+	}
+
+	var notes, newCards int
+	var front, back string
+	if err = db.QueryRow("SELECT count(*) FROM notes").Scan(&notes); err != nil || notes != len(cards) {
+		t.Errorf("notes = %d, %v; want %d", notes, err, len(cards))
+	}
+	if err = db.QueryRow("SELECT count(*) FROM cards WHERE did = ? AND queue = 0", deckID("Trivia")).Scan(&newCards); err != nil || newCards != len(cards) {
+		t.Errorf("new cards of the deck = %d, %v; want %d", newCards, err, len(cards))
+	}
+	var flds string
+	if err = db.QueryRow("SELECT flds FROM notes WHERE csum = ?", checksum("Question 901?")).Scan(&flds); err != nil {
+		t.Fatalf("finding a note by checksum: %v", err)
+	}
+	front, back, _ = strings.Cut(flds, "\x1f")
+	if front != "Question 901?" || back != "answer " {
+		t.Errorf("note fields = %q, %q", front, back)
+	}
+	var decks string
+	if err = db.QueryRow("SELECT decks FROM col WHERE ver = 11").Scan(&decks); err != nil || !strings.Contains(decks, `"name":"Trivia"`) {
+		t.Errorf("decks = %s, %v", decks, err)
+	}
+}
@@ -0,0 +1,362 @@
+package anki
+
+import (
+	"cmp"
+	"encoding/binary"
+	"slices"
+)
+
+// The collections of the packages are SQLite databases. Fabric is built
+// without cgo, so they are written directly in the SQLite file format
+// (https://www.sqlite.org/fileformat2.html): the tables and indexes are
+// written at once as b-trees, without free pages nor journal.
+
+const (
+	sqlitePageSize = 4096
+	// sqliteVersion is the SQLite version recorded as having written the file
+	sqliteVersion = 3045000
+
+	tableLeafPage     = 0x0d
+	tableInteriorPage = 0x05
+	indexLeafPage     = 0x0a
+	indexInteriorPage = 0x02
+)
+
+// sqliteRow is a row of a table, its INTEGER PRIMARY KEY column being the
+// rowid and nil in the values
+type sqliteRow struct {
+	rowid  int64
+	values []any
+}
+
+// sqliteDB is a database being written, page 1 being kept for its schema
+type sqliteDB struct {
+	pages  [][]byte
+	schema []sqliteRow
+}
+
+func newSQLiteDB() *sqliteDB {
+	return &sqliteDB{pages: [][]byte{make([]byte, sqlitePageSize)}}
+}
+
+// createTable writes the table with its rows, ordered by rowid
+func (db *sqliteDB) createTable(name, sql string, rows []sqliteRow) {
+	rows = slices.SortedFunc(slices.Values(rows), func(a, b sqliteRow) int { return cmp.Compare(a.rowid, b.rowid) })
+	root := db.tableBTree(rows, 0)
+	db.addSchema("table", name, name, root, sql)
+}
+
+// createIndex writes the index of the integer columns of the table: each key
+// is the values of the columns followed by the rowid of the row
+func (db *sqliteDB) createIndex(name, table, sql string, keys [][]int64) {
+	keys = slices.SortedFunc(slices.Values(keys), slices.Compare)
+	entries := make([][]byte, len(keys))
+	for i, key := range keys {
+		values := make([]any, len(key))
+		for j, v := range key {
+			values[j] = v
+		}
+		entries[i] = sqliteRecord(values)
+	}
+	root := db.indexBTree(entries)
+	db.addSchema("index", name, table, root, sql)
+}
+
+func (db *sqliteDB) addSchema(kind, name, table string, root int, sql string) {
+	db.schema = append(db.schema, sqliteRow{
+		rowid:  int64(len(db.schema) + 1),
+		values: []any{kind, name, table, int64(root), sql},
+	})
+}
+
+// bytes returns the database file, writing its schema to page 1
+func (db *sqliteDB) bytes() []byte {
+	db.tableBTree(db.schema, 1)
+
+	header := db.pages[0][:100]
+	copy(header, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(header[16:], sqlitePageSize)
+	header[18], header[19] = 1, 1                   // legacy journal mode
+	header[21], header[22], header[23] = 64, 32, 32 // fixed payload fractions
+	binary.BigEndian.PutUint32(header[24:], 1)      // file change counter
+	binary.BigEndian.PutUint32(header[28:], uint32(len(db.pages)))
+	binary.BigEndian.PutUint32(header[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(header[44:], 4) // schema format
+	binary.BigEndian.PutUint32(header[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(header[92:], 1) // version-valid-for, the change counter
+	binary.BigEndian.PutUint32(header[96:], sqliteVersion)
+	return slices.Concat(db.pages...)
+}
+
+func (db *sqliteDB) newPage() int {
+	db.pages = append(db.pages, make([]byte, sqlitePageSize))
+	return len(db.pages)
+}
+
+// pageCapacity returns the room for the cells and their pointers of the page
+// with the header, page 1 starting with the database header
+func pageCapacity(page int, headerSize int) int {
+	if page == 1 {
+		return sqlitePageSize - 100 - headerSize
+	}
+	return sqlitePageSize - headerSize
+}
+
+// writePage writes the b-tree page with its cells, and the right-most child
+// of interior pages
+func (db *sqliteDB) writePage(page int, kind byte, cells [][]byte, right int) {
+	data := db.pages[page-1]
+	start := 0
+	if page == 1 {
+		start = 100
+	}
+	data[start] = kind
+	binary.BigEndian.PutUint16(data[start+3:], uint16(len(cells)))
+	pointers := start + 8
+	if kind == tableInteriorPage || kind == indexInteriorPage {
+		binary.BigEndian.PutUint32(data[start+8:], uint32(right))
+		pointers = start + 12
+	}
+	end := sqlitePageSize
+	for i, cell := range cells {
+		end -= len(cell)
+		copy(data[end:], cell)
+		binary.BigEndian.PutUint16(data[pointers+2*i:], uint16(end))
+	}
+	binary.BigEndian.PutUint16(data[start+5:], uint16(end))
+}
+
+// tableBTree writes the rows as a table b-tree and returns its root page, the
+// given one or else a new one
+func (db *sqliteDB) tableBTree(rows []sqliteRow, root int) int {
+	capacity := pageCapacity(max(root, 2), 12)
+	cells := make([][]byte, len(rows))
+	for i, row := range rows {
+		record := sqliteRecord(row.values)
+		cell := appendVarint(nil, uint64(len(record)))
+		cell = appendVarint(cell, uint64(row.rowid))
+		cells[i] = db.appendPayload(cell, record, sqlitePageSize-35)
+	}
+
+	type child struct {
+		page   int
+		maxKey int64
+	}
+	var children []child
+	for first := 0; first < len(cells) || first == 0; {
+		last, size := first, 0
+		for last < len(cells) && size+len(cells[last])+2 <= capacity {
+			size += len(cells[last]) + 2
+			last++
+		}
+		if first == 0 && last == len(cells) {
+			if root == 0 {
+				root = db.newPage()
+			}
+			db.writePage(root, tableLeafPage, cells, 0)
+			return root
+		}
+		page := db.newPage()
+		db.writePage(page, tableLeafPage, cells[first:last], 0)
+		children = append(children, child{page, rows[last-1].rowid})
+		first = last
+	}
+
+	for {
+		var parents []child
+		for first := 0; first < len(children); {
+			var cells [][]byte
+			last, size := first, 0
+			// The last child of the page is its right-most pointer
+			for last+1 < len(children) {
+				cell := binary.BigEndian.AppendUint32(nil, uint32(children[last].page))
+				cell = appendVarint(cell, uint64(children[last].maxKey))
+				if size+len(cell)+2 > capacity {
+					break
+				}
+				cells = append(cells, cell)
+				size += len(cell) + 2
+				last++
+			}
+			// A page cannot be left with its right-most pointer only
+			if last+2 == len(children) {
+				last--
+				cells = cells[:len(cells)-1]
+			}
+			if first == 0 && last == len(children)-1 {
+				if root == 0 {
+					root = db.newPage()
+				}
+				db.writePage(root, tableInteriorPage, cells, children[last].page)
+				return root
+			}
+			page := db.newPage()
+			db.writePage(page, tableInteriorPage, cells, children[last].page)
+			parents = append(parents, child{page, children[last].maxKey})
+			first = last + 1
+		}
+		children = parents
+	}
+}
+
+// indexBTree writes the index entries, in order, as an index b-tree and
+// returns its root page. Unlike tables, the entries separating the children
+// of interior pages are not in the leaves.
+func (db *sqliteDB) indexBTree(entries [][]byte) int {
+	capacity := pageCapacity(2, 12)
+	maxLocal := (sqlitePageSize-12)*64/255 - 23
+	payloads := make([][]byte, len(entries))
+	for i, entry := range entries {
+		payloads[i] = db.appendPayload(appendVarint(nil, uint64(len(entry))), entry, maxLocal)
+	}
+
+	// Each level is the cells of its pages, with the child page preceding
+	// each cell for interior pages, and the separator cells between its pages
+	// make the next level
+	kind := byte(indexLeafPage)
+	var children []int
+	for {
+		cell := func(i int) []byte {
+			if children == nil {
+				return payloads[i]
+			}
+			return slices.Concat(binary.BigEndian.AppendUint32(nil, uint32(children[i])), payloads[i])
+		}
+		var pages []int
+		var separators [][]byte
+		for first := 0; first < len(payloads) || first == 0; {
+			var cells [][]byte
+			last, size := first, 0
+			for last < len(payloads) && size+len(cell(last))+2 <= capacity {
+				size += len(cell(last)) + 2
+				cells = append(cells, cell(last))
+				last++
+			}
+			// The separator cannot be the last cell, which would leave an
+			// empty page after it
+			if last == len(payloads)-1 {
+				last--
+				cells = cells[:len(cells)-1]
+			}
+			page := db.newPage()
+			right := 0
+			if children != nil {
+				right = children[last]
+			}
+			db.writePage(page, kind, cells, right)
+			if first == 0 && last == len(payloads) {
+				return page
+			}
+			pages = append(pages, page)
+			if last < len(payloads) {
+				separators = append(separators, payloads[last])
+			}
+			first = last + 1
+		}
+		kind = indexInteriorPage
+		children, payloads = pages, separators
+	}
+}
+
+// appendPayload appends the payload to the cell, spilling what exceeds the
+// maximum local size of the page kind to overflow pages
+func (db *sqliteDB) appendPayload(cell, payload []byte, maxLocal int) []byte {
+	if len(payload) <= maxLocal {
+		return append(cell, payload...)
+	}
+	usable := sqlitePageSize
+	minLocal := (usable-12)*32/255 - 23
+	local := minLocal + (len(payload)-minLocal)%(usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	cell = append(cell, payload[:local]...)
+	rest := payload[local:]
+	first := db.newPage()
+	cell = binary.BigEndian.AppendUint32(cell, uint32(first))
+	for page := first; ; {
+		data := db.pages[page-1]
+		n := copy(data[4:], rest)
+		rest = rest[n:]
+		if len(rest) == 0 {
+			return cell
+		}
+		next := db.newPage()
+		binary.BigEndian.PutUint32(data, uint32(next))
+		page = next
+	}
+}
+
+// sqliteRecord returns the record of the values: nil, int64 or string
+func sqliteRecord(values []any) []byte {
+	var types, body []byte
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			types = appendVarint(types, 0)
+		case int64:
+			switch {
+			case v == 0:
+				types = appendVarint(types, 8)
+			case v == 1:
+				types = appendVarint(types, 9)
+			default:
+				serialType, size := integerSerialType(v)
+				types = appendVarint(types, serialType)
+				body = binary.BigEndian.AppendUint64(body, uint64(v))
+				body = slices.Delete(body, len(body)-8, len(body)-size)
+			}
+		case string:
+			types = appendVarint(types, uint64(13+2*len(v)))
+			body = append(body, v...)
+		default:
+			panic("unsupported SQLite value")
+		}
+	}
+	// The header size counts its own varint
+	size := len(types) + 1
+	for len(appendVarint(nil, uint64(size)))+len(types) != size {
+		size = len(appendVarint(nil, uint64(size))) + len(types)
+	}
+	return slices.Concat(appendVarint(nil, uint64(size)), types, body)
+}
+
+// integerSerialType returns the serial type of the integer and its size
+func integerSerialType(v int64) (uint64, int) {
+	switch {
+	case v >= -1<<7 && v < 1<<7:
+		return 1, 1
+	case v >= -1<<15 && v < 1<<15:
+		return 2, 2
+	case v >= -1<<23 && v < 1<<23:
+		return 3, 3
+	case v >= -1<<31 && v < 1<<31:
+		return 4, 4
+	case v >= -1<<47 && v < 1<<47:
+		return 5, 6
+	}
+	return 6, 8
+}
+
+// appendVarint appends the SQLite variable-length integer: big-endian groups
+// of 7 bits, the ninth byte of the largest ones holding 8 bits
+func appendVarint(b []byte, v uint64) []byte {
+	if v > 1<<56-1 {
+		var buf [9]byte
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return append(b, buf[:]...)
+	}
+	var buf [8]byte
+	i := len(buf) - 1
+	buf[i] = byte(v & 0x7f)
+	for v >>= 7; v > 0; v >>= 7 {
+		i--
+		buf[i] = byte(v&0x7f) | 0x80
+	}
+	return append(b, buf[i:]...)
+}
@@ -0,0 +1,149 @@
+// Package audio captures microphone input and plays back audio files.
+//
+// There is no native audio stack; recording and playback are delegated to whichever
+// OS tool is available on PATH:
+//
+//   - Recording: arecord (ALSA), rec (SoX) or ffmpeg
+//   - Playback: afplay (macOS), paplay/aplay (Linux), play (SoX), ffplay or PowerShell (Windows)
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// SampleRate is the capture sample rate; 16 kHz mono is what speech-to-text models expect.
+const SampleRate = 16000
+
+// backend is an external command able to record or play audio.
+type backend struct {
+	name string
+	args func(path string) []string
+	// stopWithQ stops the recording by writing "q" to stdin (ffmpeg) instead of
+	// sending an interrupt signal.
+	stopWithQ bool
+	goos      []string
+}
+
+var recorders = []backend{
+	{name: "arecord", goos: []string{"linux"}, args: func(path string) []string {
+		return []string{"-q", "-f", "S16_LE", "-r", fmt.Sprint(SampleRate), "-c", "1", "-t", "wav", path}
+	}},
+	{name: "rec", args: func(path string) []string {
+		return []string{"-q", "-r", fmt.Sprint(SampleRate), "-c", "1", "-b", "16", path}
+	}},
+	{name: "ffmpeg", goos: []string{"linux"}, stopWithQ: true, args: func(path string) []string {
+		return []string{"-loglevel", "error", "-f", "alsa", "-i", "default", "-ac", "1", "-ar", fmt.Sprint(SampleRate), "-y", path}
+	}},
+	{name: "ffmpeg", goos: []string{"darwin"}, stopWithQ: true, args: func(path string) []string {
+		return []string{"-loglevel", "error", "-f", "avfoundation", "-i", ":0", "-ac", "1", "-ar", fmt.Sprint(SampleRate), "-y", path}
+	}},
+}
+
+var players = []backend{
+	{name: "afplay", goos: []string{"darwin"}, args: func(path string) []string { return []string{path} }},
+	{name: "paplay", goos: []string{"linux"}, args: func(path string) []string { return []string{path} }},
+	{name: "aplay", goos: []string{"linux"}, args: func(path string) []string { return []string{"-q", path} }},
+	{name: "play", args: func(path string) []string { return []string{"-q", path} }},
+	{name: "ffplay", args: func(path string) []string { return []string{"-nodisp", "-autoexit", "-loglevel", "error", path} }},
+	{name: "powershell", goos: []string{"windows"}, args: func(path string) []string {
+		return []string{"-NoProfile", "-Command", fmt.Sprintf("(New-Object Media.SoundPlayer '%s').PlaySync()", strings.ReplaceAll(path, "'", "''"))}
+	}},
+}
+
+// Record captures microphone audio into a 16 kHz mono WAV file at path until stop is
+// closed or ctx is cancelled.
+func Record(ctx context.Context, path string, stop <-chan struct{}) (err error) {
+	rec, bin, ok := findBackend(recorders)
+	if !ok {
+		return fmt.Errorf(i18n.T("audio_no_recorder_found"), backendNames(recorders))
+	}
+
+	cmd := exec.CommandContext(ctx, bin, rec.args(path)...)
+	var stdin io.WriteCloser
+	if rec.stopWithQ {
+		if stdin, err = cmd.StdinPipe(); err != nil {
+			return
+		}
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf(i18n.T("audio_recording_failed"), rec.name, err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err = <-done:
+		// The recorder exited on its own before being asked to stop.
+		if err != nil {
+			return fmt.Errorf(i18n.T("audio_recording_failed"), rec.name, strings.TrimSpace(stderr.String()))
+		}
+		return
+	case <-stop:
+	}
+
+	if stdin != nil {
+		_, _ = io.WriteString(stdin, "q")
+		_ = stdin.Close()
+	} else if runtime.GOOS == "windows" || cmd.Process.Signal(os.Interrupt) != nil {
+		_ = cmd.Process.Kill()
+	}
+	// Recorders exit with a non-zero status when interrupted; the file is still valid.
+	<-done
+
+	var info os.FileInfo
+	if info, err = os.Stat(path); err != nil || info.Size() == 0 {
+		return fmt.Errorf(i18n.T("audio_recording_failed"), rec.name, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Play plays the audio file at path and blocks until playback finishes.
+func Play(ctx context.Context, path string) (err error) {
+	player, bin, ok := findBackend(players)
+	if !ok {
+		return fmt.Errorf(i18n.T("audio_no_player_found"), backendNames(players))
+	}
+
+	var output []byte
+	if output, err = exec.CommandContext(ctx, bin, player.args(path)...).CombinedOutput(); err != nil {
+		return fmt.Errorf(i18n.T("audio_playback_failed"), player.name, strings.TrimSpace(string(output)))
+	}
+	return
+}
+
+// findBackend returns the first backend supported on this OS that is installed.
+func findBackend(backends []backend) (*backend, string, bool) {
+	for i := range backends {
+		b := &backends[i]
+		if len(b.goos) > 0 && !slices.Contains(b.goos, runtime.GOOS) {
+			continue
+		}
+		if bin, err := exec.LookPath(b.name); err == nil {
+			return b, bin, true
+		}
+	}
+	return nil, "", false
+}
+
+func backendNames(backends []backend) string {
+	var names []string
+	for _, b := range backends {
+		if (len(b.goos) == 0 || slices.Contains(b.goos, runtime.GOOS)) && !slices.Contains(names, b.name) {
+			names = append(names, b.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
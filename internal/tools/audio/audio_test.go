@@ -0,0 +1,78 @@
+package audio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// fakeTool installs an executable shell script named name on a temporary PATH.
+func fakeTool(t *testing.T, name, script string) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("fake audio tools are only set up on linux")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRecordStopsOnSignal(t *testing.T) {
+	// The last argument is the output path; write to it and wait to be interrupted.
+	fakeTool(t, "arecord", `for last; do :; done
+trap 'exit 1' INT
+echo RIFF > "$last"
+while :; do sleep 0.01; done
+`)
+
+	path := filepath.Join(t.TempDir(), "input.wav")
+	stop := make(chan struct{})
+	go func() {
+		// Stop once the recorder has started writing, as a user would
+		for {
+			if _, err := os.Stat(path); err == nil {
+				close(stop)
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+	if err := Record(context.Background(), path, stop); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || len(data) == 0 {
+		t.Errorf("expected recorded file, got %q, %v", data, err)
+	}
+}
+
+func TestRecordReportsEarlyExit(t *testing.T) {
+	fakeTool(t, "arecord", "echo 'no such device' >&2\nexit 1\n")
+
+	if err := Record(context.Background(), filepath.Join(t.TempDir(), "input.wav"), make(chan struct{})); err == nil {
+		t.Error("expected error when the recorder fails")
+	}
+}
+
+func TestPlay(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "played")
+	fakeTool(t, "paplay", "echo \"$1\" > "+marker+"\n")
+
+	if err := Play(context.Background(), "/tmp/reply.wav"); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if data, _ := os.ReadFile(marker); string(data) != "/tmp/reply.wav\n" {
+		t.Errorf("player received %q", data)
+	}
+}
+
+func TestNoBackend(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if err := Play(context.Background(), "reply.wav"); err == nil {
+		t.Error("expected error without any player installed")
+	}
+}
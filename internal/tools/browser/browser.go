@@ -0,0 +1,146 @@
+// Package browser lets the models browse the web during a chat: they navigate,
+// read and fill pages of a headless browser driven by the Playwright MCP server,
+// and extract their main content as Markdown like the scraper does.
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/converter"
+	"github.com/danielmiessler/fabric/internal/tools/mcp"
+	"github.com/danielmiessler/fabric/internal/tools/scraper"
+)
+
+const (
+	// ServerName is the name of the browser server, which the mcpServers of the
+	// config can define to run another Playwright MCP server
+	ServerName = "browser"
+
+	extractTool  = "browser_extract"
+	evaluateTool = "browser_evaluate"
+	// pageFunction returns the URL and the HTML of the current page as JSON
+	pageFunction = "() => JSON.stringify({url: location.href, html: document.documentElement.outerHTML})"
+)
+
+// PlaywrightMCPVersion is the version of the Playwright MCP server run by
+// default, pinned so that a new release is not fetched and run unreviewed
+const PlaywrightMCPVersion = "0.0.40"
+
+// DefaultServer runs the Playwright MCP server with a headless browser keeping
+// no profile between chats
+var DefaultServer = mcp.Server{Command: "npx", Args: []string{"-y", "@playwright/mcp@" + PlaywrightMCPVersion, "--headless", "--isolated"}}
+
+// allowedTools are the Playwright tools offered to the models: those to browse
+// and read pages, but not to run scripts, upload files or install browsers
+var allowedTools = []string{
+	"browser_navigate",
+	"browser_navigate_back",
+	"browser_snapshot",
+	"browser_click",
+	"browser_type",
+	"browser_press_key",
+	"browser_select_option",
+	"browser_hover",
+	"browser_wait_for",
+	"browser_tabs",
+}
+
+// Toolbox offers the tools of the browser to a model
+type Toolbox struct {
+	client *mcp.Client
+	tools  []domain.Tool
+	// Markdown controls whether the extracted content keeps links and images
+	Markdown converter.MarkdownOptions
+}
+
+// Open starts the browser server and lists its tools
+func Open(ctx context.Context, server *mcp.Server) (ret *Toolbox, err error) {
+	var client *mcp.Client
+	if client, err = mcp.Connect(ctx, ServerName, server); err != nil {
+		return
+	}
+	var tools []mcp.Tool
+	if tools, err = client.ListTools(ctx); err != nil {
+		_ = client.Close()
+		return
+	}
+
+	ret = &Toolbox{client: client}
+	canEvaluate := false
+	for _, tool := range tools {
+		canEvaluate = canEvaluate || tool.Name == evaluateTool
+		if slices.Contains(allowedTools, tool.Name) {
+			ret.tools = append(ret.tools, domain.Tool{Name: tool.Name, Description: tool.Description, InputSchema: tool.InputSchema})
+		}
+	}
+	if canEvaluate {
+		ret.tools = append(ret.tools, domain.Tool{
+			Name:        extractTool,
+			Description: "Extract the main content of the current page as Markdown, without the navigation, ads and other clutter",
+			InputSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+		})
+	}
+	return
+}
+
+// Tools returns the tools of the browser
+func (o *Toolbox) Tools() []domain.Tool {
+	return o.tools
+}
+
+// Call calls the tool of the browser named name with the JSON arguments
+func (o *Toolbox) Call(ctx context.Context, name string, arguments string) (ret string, err error) {
+	if !slices.ContainsFunc(o.tools, func(tool domain.Tool) bool { return tool.Name == name }) {
+		return "", fmt.Errorf(i18n.T("mcp_error_tool_not_found"), name)
+	}
+	if name == extractTool {
+		return o.extract(ctx)
+	}
+	return o.client.CallTool(ctx, name, arguments)
+}
+
+// Close stops the browser
+func (o *Toolbox) Close() error {
+	return o.client.Close()
+}
+
+// extract reads the HTML of the current page from the browser and returns its
+// main content as Markdown
+func (o *Toolbox) extract(ctx context.Context) (ret string, err error) {
+	arguments, _ := json.Marshal(map[string]string{"function": pageFunction})
+	var result string
+	if result, err = o.client.CallTool(ctx, evaluateTool, string(arguments)); err != nil {
+		return
+	}
+	var page struct {
+		URL  string `json:"url"`
+		HTML string `json:"html"`
+	}
+	if err = json.Unmarshal([]byte(evaluatedString(result)), &page); err != nil || page.HTML == "" {
+		return "", errors.New(i18n.T("browser_error_read_page"))
+	}
+	pageURL, _ := url.Parse(page.URL)
+	return scraper.Extract(page.HTML, pageURL, o.Markdown)
+}
+
+// evaluatedString returns the string a script evaluated to, from the result of
+// the evaluate tool, which shows it as JSON among its other sections
+func evaluatedString(result string) string {
+	for line := range strings.Lines(result) {
+		if start := strings.Index(line, `"`); start >= 0 {
+			var value string
+			if json.Unmarshal([]byte(strings.TrimSpace(line[start:])), &value) == nil {
+				return value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/tools/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const articlePage = `<html><head><title>Release Notes</title></head><body>
+<nav><a href="/">Home</a></nav>
+<article><h1>Release Notes</h1>
+<p>This release brings a faster parser, which reads large documents in half the time it used to, and a
+new cache that keeps the results of the slowest queries between runs.</p>
+<p>It also fixes the crash on empty files that several users reported, along with a few smaller bugs found
+while testing the parser on the documents of the test suite.</p></article>
+</body></html>`
+
+// playwrightServer mimics the Playwright MCP server over Streamable HTTP,
+// recording the tools called
+func playwrightServer(t *testing.T, called *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			return
+		}
+		var request struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if request.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		var result any
+		switch request.Method {
+		case "initialize":
+			result = map[string]any{"protocolVersion": "2025-06-18"}
+		case "tools/list":
+			result = map[string]any{"tools": []map[string]any{
+				{"name": "browser_navigate", "description": "Navigate to a URL"},
+				{"name": "browser_evaluate", "description": "Evaluate JavaScript"},
+				{"name": "browser_file_upload", "description": "Upload files"},
+			}}
+		case "tools/call":
+			*called = append(*called, request.Params.Name)
+			text := "Navigated"
+			if request.Params.Name == "browser_evaluate" {
+				page, _ := json.Marshal(map[string]string{"url": "https://example.com/notes", "html": articlePage})
+				quoted, _ := json.Marshal(string(page))
+				text = "### Result\n" + string(quoted) + "\n\n### Ran Playwright code\n```js\nawait page.evaluate()\n```"
+			}
+			result = map[string]any{"content": []map[string]any{{"type": "text", "text": text}}}
+		}
+		data, _ := json.Marshal(result)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(request.ID) + `,"result":` + string(data) + `}`))
+	}))
+}
+
+func TestToolbox(t *testing.T) {
+	var called []string
+	server := playwrightServer(t, &called)
+	defer server.Close()
+
+	toolbox, err := Open(context.Background(), &mcp.Server{URL: server.URL})
+	require.NoError(t, err)
+	defer toolbox.Close()
+
+	var names []string
+	for _, tool := range toolbox.Tools() {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"browser_navigate", "browser_extract"}, names, "only the allowed tools and the extraction")
+
+	result, err := toolbox.Call(context.Background(), "browser_navigate", `{"url":"https://example.com/notes"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Navigated", result)
+
+	markdown, err := toolbox.Call(context.Background(), "browser_extract", "{}")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(markdown, "# Release Notes\n\nURL Source: https://example.com/notes"), markdown)
+	assert.Contains(t, markdown, "a faster parser")
+	assert.NotContains(t, markdown, "Home")
+
+	_, err = toolbox.Call(context.Background(), "browser_file_upload", `{"paths":["/etc/passwd"]}`)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"browser_navigate", "browser_evaluate"}, called)
+}
+
+func TestEvaluatedString(t *testing.T) {
+	assert.Equal(t, `{"a":1}`, evaluatedString("### Result\n\"{\\\"a\\\":1}\"\n\n### Ran Playwright code\n"))
+	assert.Equal(t, "page", evaluatedString(`- Result: "page"`))
+	assert.Empty(t, evaluatedString("### Result\nundefined\n"))
+}
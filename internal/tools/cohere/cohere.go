@@ -0,0 +1,102 @@
+package cohere
+
+// see https://docs.cohere.com/reference/rerank for more information
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+const defaultBaseURL = "https://api.cohere.com/v2"
+
+type Client struct {
+	*plugins.PluginBase
+	ApiKey *plugins.SetupQuestion
+
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient() (ret *Client) {
+
+	label := "Cohere"
+
+	ret = &Client{
+		PluginBase: &plugins.PluginBase{
+			Name:             i18n.T("cohere_label"),
+			SetupDescription: i18n.T("cohere_setup_description") + " " + i18n.T("optional_marker"),
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+		baseURL:    defaultBaseURL,
+		httpClient: &http.Client{},
+	}
+
+	ret.ApiKey = ret.AddSetupQuestion("API Key", false)
+
+	return
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+	Message string `json:"message"`
+}
+
+// Rerank orders the documents by relevance to the query with a Cohere reranker
+// such as rerank-v3.5.
+func (o *Client) Rerank(ctx context.Context, query string, documents []string, model string, topN int) (ret []ai.RerankResult, err error) {
+	var body []byte
+	if body, err = json.Marshal(rerankRequest{Model: model, Query: query, Documents: documents, TopN: topN}); err != nil {
+		return
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/rerank", bytes.NewReader(body)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+o.ApiKey.Value)
+
+	var resp *http.Response
+	if resp, err = o.httpClient.Do(req); err != nil {
+		return nil, fmt.Errorf(i18n.T("cohere_error_sending_request"), err)
+	}
+	defer resp.Body.Close()
+
+	if body, err = io.ReadAll(resp.Body); err != nil {
+		return
+	}
+	var result rerankResponse
+	if resp.StatusCode != http.StatusOK {
+		if json.Unmarshal(body, &result) != nil || result.Message == "" {
+			result.Message = string(bytes.TrimSpace(body))
+		}
+		return nil, fmt.Errorf(i18n.T("cohere_error_status"), resp.StatusCode, result.Message)
+	}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf(i18n.T("cohere_error_parsing_response"), err)
+	}
+
+	for _, item := range result.Results {
+		ret = append(ret, ai.RerankResult{Index: item.Index, Score: item.RelevanceScore})
+	}
+	ai.SortRerankResults(ret)
+	return
+}
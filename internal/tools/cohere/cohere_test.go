@@ -0,0 +1,53 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient()
+	client.ApiKey.Value = "test-key"
+	client.baseURL = server.URL
+	return client
+}
+
+func TestRerank(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rerank", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var req rerankRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "rerank-v3.5", req.Model)
+		assert.Equal(t, 1, req.TopN)
+
+		_, _ = w.Write([]byte(`{"results":[{"index":1,"relevance_score":0.7}]}`))
+	})
+
+	results, err := client.Rerank(context.Background(), "question", []string{"a", "b"}, "rerank-v3.5", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []ai.RerankResult{{Index: 1, Score: 0.7}}, results)
+}
+
+func TestRerankError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"invalid model"}`))
+	})
+
+	_, err := client.Rerank(context.Background(), "question", []string{"a"}, "unknown", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+	assert.Contains(t, err.Error(), "invalid model")
+}
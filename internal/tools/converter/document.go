@@ -0,0 +1,31 @@
+package converter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// IsDocument reports whether the file is an EPUB book or a DOCX document,
+// converted to Markdown by DocumentToMarkdown
+func IsDocument(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".epub", ".docx":
+		return true
+	}
+	return false
+}
+
+// DocumentToMarkdown converts the content of the EPUB or DOCX file to
+// Markdown, by the extension of its name
+func DocumentToMarkdown(name string, data []byte, opts MarkdownOptions) (string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".epub":
+		return EpubToMarkdown(data, opts)
+	case ".docx":
+		return DocxToMarkdown(data, opts)
+	}
+	return "", fmt.Errorf(i18n.T("converter_error_unsupported_document"), name)
+}
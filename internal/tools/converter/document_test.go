@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestEpubToMarkdown(t *testing.T) {
+	data := zipFiles(t, map[string]string{
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<metadata><dc:title>Tides</dc:title><dc:creator>A. Writer</dc:creator></metadata>
+<manifest>
+  <item id="nav" href="nav.xhtml" properties="nav" media-type="application/xhtml+xml"/>
+  <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+  <item id="c1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+  <item id="c2" href="text/c2.xhtml" media-type="application/xhtml+xml"/>
+  <item id="notes" href="text/notes.xhtml" media-type="application/xhtml+xml"/>
+</manifest>
+<spine><itemref idref="cover"/><itemref idref="c1"/><itemref idref="c2"/><itemref idref="notes" linear="no"/></spine>
+</package>`,
+		"OEBPS/nav.xhtml":            `<html><body><nav><ol><li><a href="text/chapter%201.xhtml#start">The Moon</a></li></ol></nav></body></html>`,
+		"OEBPS/cover.xhtml":          `<html><body><img src="cover.jpg"/></body></html>`,
+		"OEBPS/text/chapter 1.xhtml": `<html><head><title>ignored</title></head><body><h1>One</h1><p>The <b>moon</b> pulls.</p></body></html>`,
+		"OEBPS/text/c2.xhtml":        `<html><body><h2>Two</h2><p>The sea answers.</p></body></html>`,
+		"OEBPS/text/notes.xhtml":     `<html><body><p>Notes</p></body></html>`,
+	})
+	got, err := EpubToMarkdown(data, MarkdownOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "# Tides\n\nby A. Writer\n\n"+
+		"<!-- chapter 1: The Moon -->\n\n# One\n\nThe **moon** pulls.\n\n"+
+		"<!-- chapter 2: Two -->\n\n## Two\n\nThe sea answers.", got)
+
+	_, err = EpubToMarkdown([]byte("not a zip"), MarkdownOptions{})
+	assert.Error(t, err)
+}
+
+func TestDocxToMarkdown(t *testing.T) {
+	const w = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	data := zipFiles(t, map[string]string{
+		"word/document.xml": `<w:document ` + w + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Titre1"/><w:tabs><w:tab w:val="left" w:pos="720"/></w:tabs></w:pPr><w:r><w:t>Report</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Sales </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>grew</w:t></w:r><w:r><w:t xml:space="preserve"> by </w:t></w:r><w:hyperlink r:id="rId1"><w:r><w:t>a lot</w:t></w:r></w:hyperlink></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>first</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>nested</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>bullet</w:t></w:r></w:p>
+<w:p><w:r><w:br w:type="page"/></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Region</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Total</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>North</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>4|2</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+<w:p><w:r><w:lastRenderedPageBreak/><w:t>End</w:t><w:tab/><w:t>note</w:t></w:r></w:p>
+</w:body></w:document>`,
+		"word/styles.xml": `<w:styles ` + w + `><w:style w:styleId="Titre1"><w:name w:val="heading 1"/></w:style></w:styles>`,
+		"word/numbering.xml": `<w:numbering ` + w + `>
+<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num><w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num>
+</w:numbering>`,
+		"word/_rels/document.xml.rels": `<Relationships><Relationship Id="rId1" Target="https://example.com" TargetMode="External"/></Relationships>`,
+	})
+	got, err := DocxToMarkdown(data, MarkdownOptions{KeepLinks: true})
+	require.NoError(t, err)
+	assert.Equal(t, "# Report\n\nSales **grew** by [a lot](https://example.com)\n\n"+
+		"1. first\n  - nested\n\n- bullet\n\n<!-- page 2 -->\n\n"+
+		"| Region | Total |\n| --- | --- |\n| North | 4\\|2 |\n\n<!-- page 3 -->\n\nEnd\tnote", got)
+
+	_, err = DocxToMarkdown(zipFiles(t, map[string]string{"other.xml": ""}), MarkdownOptions{})
+	assert.Error(t, err)
+}
+
+func TestDocumentToMarkdown(t *testing.T) {
+	assert.True(t, IsDocument("Book.EPUB"))
+	assert.True(t, IsDocument("report.docx"))
+	assert.False(t, IsDocument("notes.md"))
+	_, err := DocumentToMarkdown("notes.md", nil, MarkdownOptions{})
+	assert.Error(t, err)
+}
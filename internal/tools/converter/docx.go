@@ -0,0 +1,395 @@
+package converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// docxStyles are the heading levels of the paragraph styles of a DOCX
+// document, by style ID
+type docxStyles map[string]int
+
+// docxNumbering tells the numbered list levels from the bulleted ones, by
+// numbering ID then level
+type docxNumbering map[string]map[string]bool
+
+// DocxToMarkdown converts a Word document into Markdown: headings from the
+// heading styles, bulleted and numbered lists, tables, bold and italic runs,
+// and links when kept. Page breaks, the explicit ones and those Word recorded
+// when laying the document out, start a "<!-- page N -->" marker so that the
+// pages can be told apart.
+func DocxToMarkdown(data []byte, opts MarkdownOptions) (ret string, err error) {
+	var archive *zip.Reader
+	if archive, err = zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		return "", fmt.Errorf(i18n.T("converter_error_invalid_document"), "DOCX", err)
+	}
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+	var document []byte
+	if document, err = readZipFile(files, "word/document.xml"); err != nil {
+		return
+	}
+
+	w := &docxWriter{
+		opts:      opts,
+		styles:    docxReadStyles(files),
+		numbering: docxReadNumbering(files),
+		links:     docxReadLinks(files),
+		page:      1,
+	}
+	if err = w.convert(document); err != nil {
+		return "", fmt.Errorf(i18n.T("converter_error_invalid_document"), "DOCX", err)
+	}
+	ret = blankLinesRegex.ReplaceAllString(w.sb.String(), "\n\n")
+	return strings.TrimSpace(ret), nil
+}
+
+type docxWriter struct {
+	opts      MarkdownOptions
+	styles    docxStyles
+	numbering docxNumbering
+	links     map[string]string
+	sb        strings.Builder
+
+	// The paragraph being read, with its style, list level and number
+	paragraph strings.Builder
+	heading   int
+	numID     string
+	level     int
+	listed    bool
+	// list is the numbering ID of the last list item written
+	list string
+	// The formatting of the run being read and the target of its link
+	bold, italic bool
+	link         string
+	linkText     strings.Builder
+	// The rows of the table being read, its cell and nesting
+	rows   [][]string
+	cell   *strings.Builder
+	tables int
+
+	page int
+	// pageBreak is set when the next content starts a page
+	pageBreak bool
+	empty     bool
+}
+
+func (w *docxWriter) convert(document []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	w.empty = true
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			w.start(element, decoder)
+		case xml.EndElement:
+			w.end(element)
+		}
+	}
+}
+
+func (w *docxWriter) start(element xml.StartElement, decoder *xml.Decoder) {
+	value := docxAttr(element, "val")
+	switch element.Name.Local {
+	case "p":
+		w.paragraph.Reset()
+		w.heading, w.numID, w.level, w.listed = 0, "", 0, false
+	case "pStyle":
+		w.heading = w.styles[value]
+	case "numId":
+		w.numID, w.listed = value, value != "" && value != "0"
+	case "ilvl":
+		w.level, _ = strconv.Atoi(value)
+	case "r":
+		w.bold, w.italic = false, false
+	case "b":
+		w.bold = value == "" || value == "1" || value == "true"
+	case "i":
+		w.italic = value == "" || value == "1" || value == "true"
+	case "t":
+		var text string
+		if decoder.DecodeElement(&text, &element) == nil {
+			w.text(text)
+		}
+	case "tab":
+		// Tab stops have a position, tab characters none
+		if docxAttr(element, "pos") == "" {
+			w.text("\t")
+		}
+	case "br", "cr":
+		if docxAttr(element, "type") == "page" {
+			w.pageBreak = true
+		} else {
+			w.text("  \n")
+		}
+	case "lastRenderedPageBreak":
+		w.pageBreak = true
+	case "hyperlink":
+		w.link = w.links[docxAttr(element, "id")]
+		w.linkText.Reset()
+	case "tbl":
+		w.tables++
+		if w.tables == 1 {
+			w.flushPage()
+			w.rows = nil
+		}
+	case "tr":
+		if w.tables == 1 {
+			w.rows = append(w.rows, nil)
+		}
+	case "tc":
+		if w.tables == 1 {
+			w.cell = &strings.Builder{}
+		}
+	}
+}
+
+func (w *docxWriter) end(element xml.EndElement) {
+	switch element.Name.Local {
+	case "p":
+		w.endParagraph()
+	case "hyperlink":
+		text := w.linkText.String()
+		if w.link != "" && w.opts.KeepLinks && strings.TrimSpace(text) != "" {
+			w.paragraph.WriteString("[" + strings.TrimSpace(text) + "](" + w.link + ")")
+		} else {
+			w.paragraph.WriteString(text)
+		}
+		w.link = ""
+	case "tc":
+		if w.tables == 1 && w.cell != nil && len(w.rows) > 0 {
+			text := strings.ReplaceAll(strings.TrimSpace(w.cell.String()), "|", "\\|")
+			w.rows[len(w.rows)-1] = append(w.rows[len(w.rows)-1], text)
+			w.cell = nil
+		}
+	case "tbl":
+		w.tables--
+		if w.tables == 0 {
+			w.writeTable()
+		}
+	}
+}
+
+// text adds the text of a run to the paragraph, in bold or italic
+func (w *docxWriter) text(text string) {
+	if strings.TrimSpace(text) != "" {
+		marker := ""
+		if w.bold {
+			marker += "**"
+		}
+		if w.italic {
+			marker += "*"
+		}
+		if marker != "" {
+			trimmed := strings.TrimSpace(text)
+			lead := text[:strings.Index(text, trimmed)]
+			trail := text[len(lead)+len(trimmed):]
+			text = lead + marker + trimmed + marker + trail
+		}
+	}
+	if w.link != "" {
+		w.linkText.WriteString(text)
+		return
+	}
+	w.paragraph.WriteString(text)
+}
+
+func (w *docxWriter) endParagraph() {
+	text := strings.TrimSpace(strings.ReplaceAll(w.paragraph.String(), "****", ""))
+	w.paragraph.Reset()
+	if w.cell != nil {
+		if text != "" {
+			if w.cell.Len() > 0 {
+				w.cell.WriteString(" ")
+			}
+			w.cell.WriteString(strings.ReplaceAll(text, "  \n", " "))
+		}
+		return
+	}
+	if text == "" {
+		return
+	}
+	w.flushPage()
+	switch {
+	case w.heading > 0:
+		w.block()
+		w.sb.WriteString(strings.Repeat("#", w.heading) + " " + strings.ReplaceAll(text, "  \n", " "))
+		w.block()
+	case w.listed:
+		// A list other than the previous one starts its own block
+		if !strings.HasSuffix(w.sb.String(), "\n") || w.numID != w.list {
+			w.block()
+		}
+		w.list = w.numID
+		marker := "- "
+		if w.numbering[w.numID][strconv.Itoa(w.level)] {
+			marker = "1. "
+		}
+		w.sb.WriteString(strings.Repeat("  ", w.level) + marker + text + "\n")
+	default:
+		w.block()
+		w.sb.WriteString(text)
+		w.block()
+	}
+}
+
+// flushPage writes the marker of the page starting with the next content
+func (w *docxWriter) flushPage() {
+	if w.pageBreak && !w.empty {
+		w.page++
+		w.block()
+		w.sb.WriteString("<!-- page " + strconv.Itoa(w.page) + " -->")
+		w.block()
+	}
+	w.pageBreak, w.empty = false, false
+}
+
+func (w *docxWriter) block() {
+	s := w.sb.String()
+	if s == "" || strings.HasSuffix(s, "\n\n") {
+		return
+	}
+	if strings.HasSuffix(s, "\n") {
+		w.sb.WriteString("\n")
+		return
+	}
+	w.sb.WriteString("\n\n")
+}
+
+// writeTable writes the table read as a GitHub flavored Markdown table, the
+// first row being the header
+func (w *docxWriter) writeTable() {
+	width := 0
+	for _, row := range w.rows {
+		width = max(width, len(row))
+	}
+	if width == 0 {
+		return
+	}
+	w.block()
+	for i, row := range w.rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		w.sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			w.sb.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+	w.block()
+	w.rows = nil
+}
+
+// docxReadStyles returns the heading level of the paragraph styles, from
+// their outline level or from their name for the built-in ones
+func docxReadStyles(files map[string]*zip.File) docxStyles {
+	var styles struct {
+		Styles []struct {
+			ID      string  `xml:"styleId,attr"`
+			Name    docxVal `xml:"name"`
+			Outline docxVal `xml:"pPr>outlineLvl"`
+		} `xml:"style"`
+	}
+	ret := docxStyles{}
+	for level := 1; level <= 6; level++ {
+		ret["Heading"+strconv.Itoa(level)] = level
+	}
+	ret["Title"] = 1
+	if readZipXML(files, "word/styles.xml", &styles) != nil {
+		return ret
+	}
+	for _, style := range styles.Styles {
+		name := strings.ToLower(style.Name.Val)
+		if level, err := strconv.Atoi(strings.TrimPrefix(name, "heading ")); err == nil && strings.HasPrefix(name, "heading ") {
+			ret[style.ID] = min(level, 6)
+		} else if level, err := strconv.Atoi(style.Outline.Val); err == nil && level < 9 {
+			ret[style.ID] = min(level+1, 6)
+		} else if name == "title" {
+			ret[style.ID] = 1
+		}
+	}
+	return ret
+}
+
+// docxReadNumbering returns the levels of the lists numbered otherwise than
+// with bullets
+func docxReadNumbering(files map[string]*zip.File) docxNumbering {
+	type level struct {
+		Ilvl   string  `xml:"ilvl,attr"`
+		NumFmt docxVal `xml:"numFmt"`
+	}
+	var numbering struct {
+		Abstract []struct {
+			ID     string  `xml:"abstractNumId,attr"`
+			Levels []level `xml:"lvl"`
+		} `xml:"abstractNum"`
+		Nums []struct {
+			ID       string  `xml:"numId,attr"`
+			Abstract docxVal `xml:"abstractNumId"`
+		} `xml:"num"`
+	}
+	ret := docxNumbering{}
+	if readZipXML(files, "word/numbering.xml", &numbering) != nil {
+		return ret
+	}
+	ordered := map[string]map[string]bool{}
+	for _, abstract := range numbering.Abstract {
+		ordered[abstract.ID] = map[string]bool{}
+		for _, level := range abstract.Levels {
+			ordered[abstract.ID][level.Ilvl] = level.NumFmt.Val != "bullet" && level.NumFmt.Val != "none"
+		}
+	}
+	for _, num := range numbering.Nums {
+		ret[num.ID] = ordered[num.Abstract.Val]
+	}
+	return ret
+}
+
+// docxReadLinks returns the targets of the external links, by relationship
+func docxReadLinks(files map[string]*zip.File) map[string]string {
+	var rels struct {
+		Relationships []struct {
+			ID         string `xml:"Id,attr"`
+			Target     string `xml:"Target,attr"`
+			TargetMode string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	ret := map[string]string{}
+	if readZipXML(files, "word/_rels/document.xml.rels", &rels) != nil {
+		return ret
+	}
+	for _, rel := range rels.Relationships {
+		if rel.TargetMode == "External" {
+			ret[rel.ID] = rel.Target
+		}
+	}
+	return ret
+}
+
+// docxVal is an element whose value is its w:val attribute
+type docxVal struct {
+	Val string `xml:"val,attr"`
+}
+
+func docxAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,220 @@
+package converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// epubPackage is the OPF package document of an EPUB, listing its files and
+// their reading order
+type epubPackage struct {
+	Title    []string `xml:"metadata>title"`
+	Creators []string `xml:"metadata>creator"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		Toc      string `xml:"toc,attr"`
+		ItemRefs []struct {
+			IDRef  string `xml:"idref,attr"`
+			Linear string `xml:"linear,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+// epubNavPoint is an entry of the NCX table of contents of EPUB 2 books
+type epubNavPoint struct {
+	Label   string `xml:"navLabel>text"`
+	Content struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	Points []epubNavPoint `xml:"navPoint"`
+}
+
+// EpubToMarkdown converts an EPUB book into Markdown, its title and authors
+// first, then each chapter of the reading order after a
+// "<!-- chapter N: title -->" marker, the title coming from the table of
+// contents, so that the chapters can be told apart and split.
+func EpubToMarkdown(data []byte, opts MarkdownOptions) (ret string, err error) {
+	var archive *zip.Reader
+	if archive, err = zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		return "", fmt.Errorf(i18n.T("converter_error_invalid_document"), "EPUB", err)
+	}
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var container struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err = readZipXML(files, "META-INF/container.xml", &container); err != nil {
+		return
+	}
+	if len(container.Rootfiles) == 0 {
+		return "", fmt.Errorf(i18n.T("converter_error_missing_part"), "META-INF/container.xml rootfile")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err = readZipXML(files, opfPath, &pkg); err != nil {
+		return
+	}
+
+	hrefs := map[string]string{}
+	var navPath, ncxPath string
+	for _, item := range pkg.Manifest {
+		hrefs[item.ID] = zipHref(opfPath, item.Href)
+		if strings.Contains(" "+item.Properties+" ", " nav ") {
+			navPath = hrefs[item.ID]
+		}
+	}
+	if pkg.Spine.Toc != "" {
+		ncxPath = hrefs[pkg.Spine.Toc]
+	}
+	titles := epubTitles(files, navPath, ncxPath)
+
+	var sb strings.Builder
+	if len(pkg.Title) > 0 && strings.TrimSpace(pkg.Title[0]) != "" {
+		sb.WriteString("# " + strings.TrimSpace(pkg.Title[0]) + "\n\n")
+		if len(pkg.Creators) > 0 {
+			sb.WriteString("by " + strings.Join(pkg.Creators, ", ") + "\n\n")
+		}
+	}
+	chapter := 0
+	for _, ref := range pkg.Spine.ItemRefs {
+		href, ok := hrefs[ref.IDRef]
+		if !ok || ref.Linear == "no" {
+			continue
+		}
+		var content []byte
+		if content, err = readZipFile(files, href); err != nil {
+			return
+		}
+		var doc *html.Node
+		if doc, err = html.Parse(bytes.NewReader(content)); err != nil {
+			return
+		}
+		text := NodeToMarkdown(doc, opts)
+		// Covers and blank pages
+		if text == "" {
+			continue
+		}
+		chapter++
+		title := titles[href]
+		if title == "" {
+			title = firstHeading(doc)
+		}
+		marker := "<!-- chapter " + strconv.Itoa(chapter)
+		if title != "" {
+			marker += ": " + strings.ReplaceAll(title, "--", "-")
+		}
+		sb.WriteString(marker + " -->\n\n" + text + "\n\n")
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// epubTitles returns the titles of the chapter files from the navigation
+// document of EPUB 3 books, or else from the NCX of EPUB 2 books
+func epubTitles(files map[string]*zip.File, navPath, ncxPath string) map[string]string {
+	ret := map[string]string{}
+	add := func(base, href, title string) {
+		href, _, _ = strings.Cut(href, "#")
+		title = strings.TrimSpace(spacesRegex.ReplaceAllString(title, " "))
+		if file := zipHref(base, href); title != "" && ret[file] == "" {
+			ret[file] = title
+		}
+	}
+	if content, err := readZipFile(files, navPath); navPath != "" && err == nil {
+		if doc, parseErr := html.Parse(bytes.NewReader(content)); parseErr == nil {
+			var walk func(*html.Node)
+			walk = func(node *html.Node) {
+				if node.Type == html.ElementNode && node.DataAtom == atom.A {
+					add(navPath, attr(node, "href"), textContent(node))
+				}
+				for child := node.FirstChild; child != nil; child = child.NextSibling {
+					walk(child)
+				}
+			}
+			walk(doc)
+		}
+	}
+	var ncx struct {
+		Points []epubNavPoint `xml:"navMap>navPoint"`
+	}
+	if ncxPath != "" && readZipXML(files, ncxPath, &ncx) == nil {
+		var walk func([]epubNavPoint)
+		walk = func(points []epubNavPoint) {
+			for _, point := range points {
+				add(ncxPath, point.Content.Src, point.Label)
+				walk(point.Points)
+			}
+		}
+		walk(ncx.Points)
+	}
+	return ret
+}
+
+// firstHeading returns the text of the first heading of the document
+func firstHeading(node *html.Node) string {
+	if node.Type == html.ElementNode {
+		switch node.DataAtom {
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			return strings.TrimSpace(spacesRegex.ReplaceAllString(textContent(node), " "))
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if title := firstHeading(child); title != "" {
+			return title
+		}
+	}
+	return ""
+}
+
+// zipHref resolves the URL reference of a file of the archive from the file
+// referencing it
+func zipHref(base, href string) string {
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	return path.Join(path.Dir(base), href)
+}
+
+func readZipFile(files map[string]*zip.File, name string) (ret []byte, err error) {
+	file, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf(i18n.T("converter_error_missing_part"), name)
+	}
+	var reader io.ReadCloser
+	if reader, err = file.Open(); err != nil {
+		return
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func readZipXML(files map[string]*zip.File, name string, v any) (err error) {
+	var content []byte
+	if content, err = readZipFile(files, name); err != nil {
+		return
+	}
+	if err = xml.Unmarshal(content, v); err != nil {
+		return fmt.Errorf(i18n.T("converter_error_invalid_document"), name, err)
+	}
+	return
+}
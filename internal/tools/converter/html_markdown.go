@@ -0,0 +1,317 @@
+package converter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+	spacesRegex     = regexp.MustCompile(`[ \t\r\n]+`)
+)
+
+// MarkdownOptions controls which parts of the HTML survive the Markdown conversion.
+// Links are reduced to their text and images to their alt text unless kept explicitly.
+type MarkdownOptions struct {
+	KeepLinks  bool
+	KeepImages bool
+}
+
+// HtmlToMarkdown converts an HTML fragment or document into Markdown.
+func HtmlToMarkdown(input string, opts MarkdownOptions) (ret string, err error) {
+	var doc *html.Node
+	if doc, err = html.Parse(strings.NewReader(input)); err != nil {
+		return
+	}
+	ret = NodeToMarkdown(doc, opts)
+	return
+}
+
+// NodeToMarkdown converts an already parsed HTML node tree into Markdown.
+func NodeToMarkdown(node *html.Node, opts MarkdownOptions) string {
+	w := &markdownWriter{opts: opts}
+	w.writeNode(node)
+	ret := blankLinesRegex.ReplaceAllString(w.sb.String(), "\n\n")
+	return strings.TrimSpace(ret)
+}
+
+type markdownWriter struct {
+	sb        strings.Builder
+	opts      MarkdownOptions
+	listDepth int
+}
+
+// block makes sure the next content starts on its own paragraph.
+func (w *markdownWriter) block() {
+	s := w.sb.String()
+	if s == "" || strings.HasSuffix(s, "\n\n") {
+		return
+	}
+	if strings.HasSuffix(s, "\n") {
+		w.sb.WriteString("\n")
+		return
+	}
+	w.sb.WriteString("\n\n")
+}
+
+func (w *markdownWriter) newline() {
+	s := w.sb.String()
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		w.sb.WriteString("\n")
+	}
+}
+
+func (w *markdownWriter) writeChildren(node *html.Node) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		w.writeNode(child)
+	}
+}
+
+func (w *markdownWriter) writeNode(node *html.Node) {
+	switch node.Type {
+	case html.DocumentNode:
+		w.writeChildren(node)
+		return
+	case html.TextNode:
+		w.writeText(node.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch node.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Head, atom.Iframe, atom.Svg, atom.Form, atom.Button:
+		return
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(node.Data[1] - '0')
+		text := w.inlineText(node)
+		if text == "" {
+			return
+		}
+		w.block()
+		w.sb.WriteString(strings.Repeat("#", level) + " " + text)
+		w.block()
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer, atom.Figure, atom.Aside:
+		w.block()
+		w.writeChildren(node)
+		w.block()
+	case atom.Br:
+		w.sb.WriteString("  \n")
+	case atom.Hr:
+		w.block()
+		w.sb.WriteString("---")
+		w.block()
+	case atom.Strong, atom.B:
+		w.wrapInline(node, "**")
+	case atom.Em, atom.I:
+		w.wrapInline(node, "*")
+	case atom.Code:
+		if text := textContent(node); text != "" {
+			if strings.Contains(text, "`") {
+				w.sb.WriteString("`` " + text + " ``")
+			} else {
+				w.sb.WriteString("`" + text + "`")
+			}
+		}
+	case atom.Pre:
+		code := strings.Trim(textContent(node), "\n")
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		w.block()
+		w.sb.WriteString(fence + codeLanguage(node) + "\n")
+		w.sb.WriteString(code)
+		w.sb.WriteString("\n" + fence)
+		w.block()
+	case atom.A:
+		text := w.inlineText(node)
+		href := attr(node, "href")
+		if !w.opts.KeepLinks || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+			w.sb.WriteString(text)
+			return
+		}
+		if text == "" {
+			text = href
+		}
+		w.sb.WriteString("[" + text + "](" + href + ")")
+	case atom.Img:
+		alt := strings.TrimSpace(spacesRegex.ReplaceAllString(attr(node, "alt"), " "))
+		if src := attr(node, "src"); w.opts.KeepImages && src != "" {
+			w.sb.WriteString("![" + alt + "](" + src + ")")
+		} else if alt != "" {
+			w.writeText(alt)
+		}
+	case atom.Table:
+		w.writeTable(node)
+	case atom.Ul, atom.Ol:
+		if w.listDepth == 0 {
+			w.block()
+		} else {
+			w.newline()
+		}
+		w.listDepth++
+		index := 1
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+				continue
+			}
+			marker := "- "
+			if node.DataAtom == atom.Ol {
+				marker = strconv.Itoa(index) + ". "
+				index++
+			}
+			w.newline()
+			w.sb.WriteString(strings.Repeat("  ", w.listDepth-1) + marker)
+			w.writeChildren(child)
+		}
+		w.listDepth--
+		if w.listDepth == 0 {
+			w.block()
+		}
+	case atom.Blockquote:
+		inner := &markdownWriter{opts: w.opts}
+		inner.writeChildren(node)
+		text := strings.TrimSpace(blankLinesRegex.ReplaceAllString(inner.sb.String(), "\n\n"))
+		if text == "" {
+			return
+		}
+		w.block()
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		w.sb.WriteString(strings.Join(lines, "\n"))
+		w.block()
+	default:
+		w.writeChildren(node)
+	}
+}
+
+func (w *markdownWriter) writeText(text string) {
+	text = spacesRegex.ReplaceAllString(text, " ")
+	if text == " " {
+		s := w.sb.String()
+		if s == "" || strings.HasSuffix(s, " ") || strings.HasSuffix(s, "\n") {
+			return
+		}
+	} else if s := w.sb.String(); s == "" || strings.HasSuffix(s, "\n") {
+		text = strings.TrimLeft(text, " ")
+	}
+	w.sb.WriteString(text)
+}
+
+func (w *markdownWriter) wrapInline(node *html.Node, marker string) {
+	text := w.inlineText(node)
+	if text == "" {
+		return
+	}
+	w.sb.WriteString(marker + text + marker)
+}
+
+// writeTable renders a table as a GitHub flavored Markdown table. The first row is
+// used as the header; ragged rows are padded to the widest row.
+func (w *markdownWriter) writeTable(table *html.Node) {
+	var rows [][]string
+	width := 0
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				collect(child)
+			case atom.Tr:
+				var cells []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						text := strings.ReplaceAll(w.inlineText(cell), "|", "\\|")
+						cells = append(cells, text)
+					}
+				}
+				if len(cells) > 0 {
+					rows = append(rows, cells)
+					width = max(width, len(cells))
+				}
+			}
+		}
+	}
+	collect(table)
+	if len(rows) == 0 {
+		return
+	}
+
+	w.block()
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		w.sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			w.sb.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+	w.block()
+}
+
+// inlineText renders the children of node as a single line of Markdown.
+func (w *markdownWriter) inlineText(node *html.Node) string {
+	inner := &markdownWriter{opts: w.opts}
+	inner.writeChildren(node)
+	return strings.TrimSpace(spacesRegex.ReplaceAllString(inner.sb.String(), " "))
+}
+
+func textContent(node *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+	return sb.String()
+}
+
+// codeLanguage returns the language hint of a <pre> block, taken from a language-xxx
+// or lang-xxx class (or data-lang attribute) on the <pre> or its <code> child.
+func codeLanguage(pre *html.Node) string {
+	candidates := []*html.Node{pre}
+	for child := pre.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == atom.Code {
+			candidates = append(candidates, child)
+		}
+	}
+	for _, node := range candidates {
+		if lang := attr(node, "data-lang"); lang != "" {
+			return lang
+		}
+		for class := range strings.FieldsSeq(attr(node, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if lang, ok := strings.CutPrefix(class, prefix); ok && lang != "" {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtmlToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		opts     MarkdownOptions
+		expected string
+	}{
+		{
+			name:     "Empty HTML",
+			html:     "",
+			expected: "",
+		},
+		{
+			name:     "Headings and paragraphs",
+			html:     "<h1>Title</h1><p>Hello <b>bold</b> and <em>soft</em> world</p><h2>Next</h2><p>More</p>",
+			expected: "# Title\n\nHello **bold** and *soft* world\n\n## Next\n\nMore",
+		},
+		{
+			name:     "Links and images dropped by default",
+			html:     `<p>See <a href="https://example.com">the site</a> <img src="a.png" alt="pic"></p>`,
+			expected: "See the site pic",
+		},
+		{
+			name:     "Links and images kept",
+			html:     `<p>See <a href="https://example.com">the site</a> <img src="a.png" alt="pic"></p><p><a href="#top">Top</a></p>`,
+			opts:     MarkdownOptions{KeepLinks: true, KeepImages: true},
+			expected: "See [the site](https://example.com) ![pic](a.png)\n\nTop",
+		},
+		{
+			name:     "Nested lists",
+			html:     "<ul><li>One</li><li>Two<ol><li>A</li><li>B</li></ol></li></ul><p>After</p>",
+			expected: "- One\n- Two\n  1. A\n  2. B\n\nAfter",
+		},
+		{
+			name:     "Code",
+			html:     "<p>Run <code>go test</code></p><pre><code>func main() {\n\tprintln(1)\n}\n</code></pre>",
+			expected: "Run `go test`\n\n```\nfunc main() {\n\tprintln(1)\n}\n```",
+		},
+		{
+			name:     "Code block language hint",
+			html:     `<pre class="highlight"><code class="language-python">print("x")</code></pre><pre data-lang="sh">echo ` + "```" + `</pre>`,
+			expected: "```python\nprint(\"x\")\n```\n\n````sh\necho ```\n````",
+		},
+		{
+			name:     "Table",
+			html:     "<table><thead><tr><th>Key</th><th>Value</th></tr></thead><tbody><tr><td><code>a|b</code></td><td>1</td></tr><tr><td>only</td></tr></tbody></table>",
+			expected: "| Key | Value |\n| --- | --- |\n| `a\\|b` | 1 |\n| only |  |",
+		},
+		{
+			name:     "Blockquote",
+			html:     "<blockquote><p>Quoted</p><p>Twice</p></blockquote>",
+			expected: "> Quoted\n>\n> Twice",
+		},
+		{
+			name:     "Scripts are dropped",
+			html:     "<p>Visible</p><script>var x = 1;</script><style>p{}</style>",
+			expected: "Visible",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := HtmlToMarkdown(tc.html, tc.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -10,17 +10,22 @@ import (
 // args：
 //
 //	html (string): full data of web page
+//	opts (MarkdownOptions): whether links and images are kept in the output
 //
 // return：
 //
-//	viewContent (string): html main content
+//	viewContent (string): main content of the page as Markdown
 //	err (error): parser error
-func HtmlReadability(html string) (ret string, err error) {
+func HtmlReadability(html string, opts MarkdownOptions) (ret string, err error) {
 	buf := bytes.NewBufferString(html)
 	var article readability.Article
 	if article, err = readability.FromReader(buf, nil); err != nil {
 		return
 	}
-	ret = article.TextContent
+	if article.Node == nil {
+		ret = article.TextContent
+		return
+	}
+	ret = NodeToMarkdown(article.Node, opts)
 	return
 }
@@ -25,18 +25,23 @@ func TestHtmlReadability(t *testing.T) {
 		{
 			name:     "HTML with nested tags",
 			html:     "<div><p>Hello</p><p>World</p></div>",
-			expected: "HelloWorld",
+			expected: "Hello\n\nWorld",
 		},
 		{
 			name:     "HTML missing tags",
 			html:     "<div><p>Hello</p><p>World</div>",
-			expected: "HelloWorld",
+			expected: "Hello\n\nWorld",
+		},
+		{
+			name:     "HTML with table",
+			html:     "<table><tr><th>Name</th><th>Age</th></tr><tr><td>Ann</td><td>30</td></tr></table>",
+			expected: "| Name | Age |\n| --- | --- |\n| Ann | 30 |",
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := HtmlReadability(tc.html)
+			result, err := HtmlReadability(tc.html, MarkdownOptions{})
 
 			// 验证结果
 			assert.NoError(t, err)
@@ -0,0 +1,172 @@
+// Package cron parses the cron expressions of scheduled jobs and computes when
+// they run next.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday are set when the field is *, a day then matching
+	// when both fields do rather than either
+	anyDay, anyWeekday bool
+}
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// errInvalid reports a field that cannot be parsed
+var errInvalid = errors.New("invalid cron field")
+
+var (
+	monthNames   = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// Parse parses the five fields of a cron expression, minute, hour, day of the
+// month, month and day of the week, or one of the macros like @daily. Fields
+// accept *, lists, ranges and steps, months and days of the week their
+// three-letter names too, and Sunday is 0 or 7.
+func Parse(spec string) (ret *Schedule, err error) {
+	expression := strings.TrimSpace(spec)
+	if macro, ok := macros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf(i18n.T("cron_invalid_fields"), spec)
+	}
+
+	ret = &Schedule{anyDay: strings.HasPrefix(fields[2], "*"), anyWeekday: strings.HasPrefix(fields[4], "*")}
+	parsers := []struct {
+		name     string
+		target   *uint64
+		min, max int
+		names    []string
+	}{
+		{"minute", &ret.minutes, 0, 59, nil},
+		{"hour", &ret.hours, 0, 23, nil},
+		{"day", &ret.days, 1, 31, nil},
+		{"month", &ret.months, 1, 12, monthNames},
+		{"weekday", &ret.weekdays, 0, 7, weekdayNames},
+	}
+	for i, p := range parsers {
+		if *p.target, err = parseField(fields[i], p.min, p.max, p.names); err != nil {
+			return nil, fmt.Errorf(i18n.T("cron_invalid_field"), p.name, fields[i], spec)
+		}
+	}
+	// Sunday is both 0 and 7
+	if ret.weekdays&(1<<7) != 0 {
+		ret.weekdays |= 1
+	}
+	return
+}
+
+// parseField returns the bits of the values of a field
+func parseField(field string, min, max int, names []string) (ret uint64, err error) {
+	for part := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, errInvalid
+			}
+		}
+
+		var low, high int
+		if rangePart == "*" {
+			low, high = min, max
+		} else {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			if low, err = parseValue(lowPart, min, max, names); err != nil {
+				return
+			}
+			high = low
+			if isRange {
+				if high, err = parseValue(highPart, min, max, names); err != nil {
+					return
+				}
+			} else if hasStep {
+				// 5/15 runs from 5 to the end of the range
+				high = max
+			}
+			if high < low {
+				return 0, errInvalid
+			}
+		}
+		for value := low; value <= high; value += step {
+			ret |= 1 << value
+		}
+	}
+	return
+}
+
+func parseValue(value string, min, max int, names []string) (ret int, err error) {
+	for i, name := range names {
+		if strings.EqualFold(value, name) {
+			return i + min, nil
+		}
+	}
+	if ret, err = strconv.Atoi(value); err != nil {
+		return
+	}
+	if ret < min || ret > max {
+		return 0, errInvalid
+	}
+	return
+}
+
+// Next returns the first time after after when the schedule runs, in the
+// location of after, or the zero time when it never runs, e.g. on February 30.
+func (s *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + 5
+
+	for t.Year() <= limit {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay follows cron: when both the day of the month and the day of the
+// week are restricted, either matching is enough
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
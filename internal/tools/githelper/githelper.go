@@ -1,6 +1,7 @@
 package githelper
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +11,8 @@ import (
 
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/storage/memory"
 )
@@ -57,6 +60,29 @@ func FetchFilesFromRepo(opts FetchOptions) error {
 	return fmt.Errorf(i18n.T("githelper_failed_git_cli_fallback"), goGitErr, cliErr)
 }
 
+// RemoteHead returns the commit hash of the HEAD of a remote repository,
+// without cloning it.
+func RemoteHead(ctx context.Context, repoURL string) (ret string, err error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{repoURL}})
+	var refs []*plumbing.Reference
+	if refs, err = remote.ListContext(ctx, &git.ListOptions{}); err != nil {
+		return "", fmt.Errorf(i18n.T("githelper_failed_list_remote"), repoURL, err)
+	}
+
+	target := plumbing.HEAD
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			target = ref.Target()
+		}
+	}
+	for _, ref := range refs {
+		if ref.Name() == target && ref.Type() == plumbing.HashReference {
+			return ref.Hash().String(), nil
+		}
+	}
+	return "", fmt.Errorf(i18n.T("githelper_failed_get_head"), plumbing.ErrReferenceNotFound)
+}
+
 // fetchFilesViaGoGit clones a repo in memory using go-git and extracts files.
 func fetchFilesViaGoGit(opts FetchOptions) error {
 	r, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
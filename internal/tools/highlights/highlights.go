@@ -0,0 +1,133 @@
+// Package highlights pulls the highlights and bookmarks saved with read-it-later
+// services, Readwise and Raindrop.io, as Markdown input for the patterns.
+package highlights
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// DefaultSince is how far back the queries without since: go, for weekly digests
+const DefaultSince = 7 * 24 * time.Hour
+
+// Query selects the items to pull, parsed from words like
+// "since:7d category:books tag:ai productivity": the items saved or updated
+// since a time, of a Readwise category or a Raindrop collection, with tags,
+// and for Raindrop matching the other words
+type Query struct {
+	// Since is zero for since:all
+	Since      time.Time
+	Category   string
+	Collection string
+	Tags       []string
+	Search     string
+}
+
+// ParseQuery parses the query, relative times counting back from now
+func ParseQuery(query string, now time.Time) (ret Query, err error) {
+	ret.Since = now.Add(-DefaultSince)
+	var search []string
+	for _, word := range strings.Fields(query) {
+		key, value, ok := strings.Cut(word, ":")
+		if !ok || value == "" {
+			search = append(search, word)
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "since":
+			if ret.Since, err = parseSince(value, now); err != nil {
+				return
+			}
+		case "category":
+			ret.Category = strings.ToLower(value)
+		case "collection":
+			ret.Collection = value
+		case "tag":
+			ret.Tags = append(ret.Tags, value)
+		default:
+			search = append(search, word)
+		}
+	}
+	ret.Search = strings.Join(search, " ")
+	return
+}
+
+// parseSince parses "all", a duration in minutes, hours, days or weeks like
+// "7d", or a date like "2025-01-31"
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if strings.EqualFold(value, "all") {
+		return time.Time{}, nil
+	}
+	if date, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return date, nil
+	}
+	units := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n > 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(i18n.T("highlights_error_invalid_since"), value)
+}
+
+// header returns the title of the Markdown of the items, with the time they
+// are pulled since
+func header(title string, since time.Time) string {
+	if since.IsZero() {
+		return "# " + title + "\n"
+	}
+	return "# " + title + "\n\n" + fmt.Sprintf(i18n.T("highlights_since"), since.Format(time.DateOnly)) + "\n"
+}
+
+// quote returns the text as a Markdown block quote
+func quote(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hasTags reports whether the tags, compared case-insensitively, include all
+// the wanted ones
+func hasTags(tags, wanted []string) bool {
+	for _, want := range wanted {
+		if !slices.ContainsFunc(tags, func(tag string) bool { return strings.EqualFold(tag, want) }) {
+			return false
+		}
+	}
+	return true
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// getJSON decodes the response of the service to the GET request
+func getJSON(service, requestURL, authorization string, target any) (err error) {
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodGet, requestURL, nil); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Accept", "application/json")
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		return fmt.Errorf(i18n.T("highlights_error_request"), service, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf(i18n.T("highlights_error_status"), service, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err = json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf(i18n.T("highlights_error_request"), service, err)
+	}
+	return
+}
@@ -0,0 +1,133 @@
+package highlights
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	got, err := ParseQuery("since:2w category:Books tag:ai tag:go golang tips", now)
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+	if !got.Since.Equal(now.AddDate(0, 0, -14)) || got.Category != "books" || strings.Join(got.Tags, ",") != "ai,go" || got.Search != "golang tips" {
+		t.Errorf("ParseQuery() = %+v", got)
+	}
+	if got, _ = ParseQuery("collection:Reading", now); !got.Since.Equal(now.Add(-DefaultSince)) || got.Collection != "Reading" {
+		t.Errorf("ParseQuery() without since = %+v, want the last week", got)
+	}
+	if got, _ = ParseQuery("since:all", now); !got.Since.IsZero() {
+		t.Errorf("ParseQuery(since:all) since = %v", got.Since)
+	}
+	if got, _ = ParseQuery("since:2026-01-31", now); !got.Since.Equal(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseQuery(since:date) since = %v", got.Since)
+	}
+	if _, err = ParseQuery("since:yesterday", now); err == nil {
+		t.Errorf("ParseQuery(since:yesterday) expected an error")
+	}
+}
+
+func TestReadwiseHighlights(t *testing.T) {
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().AddDate(0, -1, 0).UTC().Format(time.RFC3339)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token secret" || r.URL.Query().Get("updatedAfter") == "" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		// The second page follows the cursor of the first one
+		if r.URL.Query().Get("pageCursor") == "" {
+			fmt.Fprintf(w, `{"nextPageCursor": "2", "results": [{"title": "Deep Work", "author": "Cal Newport", "category": "books",
+				"book_tags": [{"name": "AI"}], "highlights": [
+				{"text": "Focus is rare.", "note": "so true", "updated_at": %q},
+				{"text": "An old one.", "updated_at": %q},
+				{"text": "Discarded.", "updated_at": %q, "is_discard": true}]}]}`, recent, old, recent)
+			return
+		}
+		fmt.Fprintf(w, `{"nextPageCursor": null, "results": [
+			{"title": "A tweet", "category": "tweets", "highlights": [{"text": "Not a book.", "updated_at": %q}]},
+			{"title": "Untagged", "category": "books", "highlights": [{"text": "No tag.", "updated_at": %q}]}]}`, recent, recent)
+	}))
+	defer server.Close()
+	readwiseURL = server.URL
+
+	readwise := NewReadwise()
+	if _, err := readwise.Highlights("since:7d"); err == nil {
+		t.Errorf("Highlights() without access token expected an error")
+	}
+	readwise.AccessToken.Value = "secret"
+	got, err := readwise.Highlights("category:books tag:ai")
+	if err != nil {
+		t.Fatalf("Highlights() error = %v", err)
+	}
+	for _, want := range []string{"## Deep Work — Cal Newport", "> Focus is rare.", "Note: so true"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Highlights() = %q, want %q", got, want)
+		}
+	}
+	for _, unwanted := range []string{"old one", "Discarded", "Not a book", "No tag"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("Highlights() = %q, should not contain %q", got, unwanted)
+		}
+	}
+
+	readwise.AccessToken.Value = "wrong"
+	if _, err = readwise.Highlights("since:1d"); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Highlights() with a wrong token error = %v, want the status", err)
+	}
+}
+
+func TestRaindropBookmarks(t *testing.T) {
+	var searches []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/collections":
+			fmt.Fprint(w, `{"items": [{"_id": 1, "title": "Inbox"}]}`)
+		case "/collections/childrens":
+			fmt.Fprint(w, `{"items": [{"_id": 42, "title": "Reading"}]}`)
+		case "/raindrops/42":
+			searches = append(searches, r.URL.Query().Get("search"))
+			recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+			old := time.Now().AddDate(0, -1, 0).UTC().Format(time.RFC3339)
+			fmt.Fprintf(w, `{"items": [
+				{"title": "Go 1.26", "link": "https://go.dev/blog", "excerpt": "What is new.", "note": "read again",
+				 "created": %q, "tags": ["go", "ai"], "highlights": [{"text": "Generic methods", "note": "finally"}]},
+				{"title": "Old post", "link": "https://example.com", "created": %q}]}`, recent, old)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	raindropURL = server.URL
+
+	raindrop := NewRaindrop()
+	raindrop.AccessToken.Value = "secret"
+	got, err := raindrop.Bookmarks("since:7d collection:reading tag:ai golang")
+	if err != nil {
+		t.Fatalf("Bookmarks() error = %v", err)
+	}
+	for _, want := range []string{"## Go 1.26\n\nhttps://go.dev/blog", "Tags: go, ai", "What is new.", "Note: read again", "> Generic methods\n\nNote: finally"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Bookmarks() = %q, want %q", got, want)
+		}
+	}
+	if strings.Contains(got, "Old post") {
+		t.Errorf("Bookmarks() = %q, should stop at the bookmarks older than since", got)
+	}
+	if len(searches) != 1 || searches[0] != "golang #ai" {
+		t.Errorf("searches = %q, want one page searching golang #ai", searches)
+	}
+
+	if _, err = raindrop.Bookmarks("collection:Missing"); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("Bookmarks() of a missing collection error = %v", err)
+	}
+}
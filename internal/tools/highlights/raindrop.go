@@ -0,0 +1,169 @@
+package highlights
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+// see https://developer.raindrop.io for the REST API
+var raindropURL = "https://api.raindrop.io/rest/v1"
+
+// raindropPageSize is the largest page of bookmarks of the API
+const raindropPageSize = 50
+
+// Raindrop pulls the bookmarks saved with Raindrop.io, with their excerpts,
+// notes and highlights
+type Raindrop struct {
+	*plugins.PluginBase
+	AccessToken *plugins.SetupQuestion
+}
+
+func NewRaindrop() (ret *Raindrop) {
+	label := "Raindrop"
+
+	ret = &Raindrop{
+		PluginBase: &plugins.PluginBase{
+			Name:             i18n.T("raindrop_label"),
+			SetupDescription: i18n.T("raindrop_setup_description") + " " + i18n.T("optional_marker"),
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+	}
+
+	ret.AccessToken = ret.AddSetupQuestion("Access Token", false)
+
+	return
+}
+
+type raindropItem struct {
+	Title      string    `json:"title"`
+	Excerpt    string    `json:"excerpt"`
+	Note       string    `json:"note"`
+	Link       string    `json:"link"`
+	Created    time.Time `json:"created"`
+	Tags       []string  `json:"tags"`
+	Highlights []struct {
+		Text string `json:"text"`
+		Note string `json:"note"`
+	} `json:"highlights"`
+}
+
+// Bookmarks returns the bookmarks of the query as Markdown, newest first:
+// those saved since its time, in its collection, by name or id, with its tags
+// and matching its other words, searched by Raindrop.io
+func (o *Raindrop) Bookmarks(query string) (ret string, err error) {
+	if o.AccessToken.Value == "" {
+		return "", errors.New(i18n.T("raindrop_not_configured"))
+	}
+	var q Query
+	if q, err = ParseQuery(query, time.Now()); err != nil {
+		return
+	}
+	collection := "0" // all the collections but the trash
+	if q.Collection != "" {
+		if collection, err = o.collectionID(q.Collection); err != nil {
+			return
+		}
+	}
+	search := q.Search
+	for _, tag := range q.Tags {
+		search += " #" + tag
+	}
+
+	var sb strings.Builder
+	sb.WriteString(header(i18n.T("raindrop_header"), q.Since))
+	count := 0
+	for page := 0; ; page++ {
+		params := url.Values{
+			"sort":    {"-created"},
+			"perpage": {strconv.Itoa(raindropPageSize)},
+			"page":    {strconv.Itoa(page)},
+		}
+		if search = strings.TrimSpace(search); search != "" {
+			params.Set("search", search)
+		}
+		var resp struct {
+			Items []raindropItem `json:"items"`
+		}
+		if err = o.get("/raindrops/"+collection+"?"+params.Encode(), &resp); err != nil {
+			return
+		}
+		for _, item := range resp.Items {
+			// The bookmarks are newest first, so the older ones end the query
+			if !q.Since.IsZero() && item.Created.Before(q.Since) {
+				resp.Items = nil
+				break
+			}
+			writeRaindropItem(&sb, item)
+			count++
+		}
+		if len(resp.Items) < raindropPageSize {
+			break
+		}
+	}
+	if count == 0 {
+		return "", errors.New(i18n.T("highlights_error_nothing_found"))
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func writeRaindropItem(sb *strings.Builder, item raindropItem) {
+	title := strings.TrimSpace(item.Title)
+	if title == "" {
+		title = item.Link
+	}
+	sb.WriteString("\n## " + title + "\n\n" + item.Link + "\n")
+	if len(item.Tags) > 0 {
+		sb.WriteString("\n" + fmt.Sprintf(i18n.T("highlights_tags"), strings.Join(item.Tags, ", ")) + "\n")
+	}
+	if excerpt := strings.TrimSpace(item.Excerpt); excerpt != "" {
+		sb.WriteString("\n" + excerpt + "\n")
+	}
+	if note := strings.TrimSpace(item.Note); note != "" {
+		sb.WriteString("\n" + fmt.Sprintf(i18n.T("highlights_note"), note) + "\n")
+	}
+	for _, highlight := range item.Highlights {
+		if strings.TrimSpace(highlight.Text) == "" {
+			continue
+		}
+		sb.WriteString("\n" + quote(highlight.Text) + "\n")
+		if note := strings.TrimSpace(highlight.Note); note != "" {
+			sb.WriteString("\n" + fmt.Sprintf(i18n.T("highlights_note"), note) + "\n")
+		}
+	}
+}
+
+// collectionID returns the id of the collection, given by id or by title
+func (o *Raindrop) collectionID(collection string) (string, error) {
+	if _, err := strconv.ParseInt(collection, 10, 64); err == nil {
+		return collection, nil
+	}
+	// The root collections and their children are listed apart
+	for _, path := range []string{"/collections", "/collections/childrens"} {
+		var resp struct {
+			Items []struct {
+				ID    int64  `json:"_id"`
+				Title string `json:"title"`
+			} `json:"items"`
+		}
+		if err := o.get(path, &resp); err != nil {
+			return "", err
+		}
+		for _, item := range resp.Items {
+			if strings.EqualFold(item.Title, collection) {
+				return strconv.FormatInt(item.ID, 10), nil
+			}
+		}
+	}
+	return "", fmt.Errorf(i18n.T("raindrop_error_collection_not_found"), collection)
+}
+
+func (o *Raindrop) get(path string, target any) error {
+	return getJSON("Raindrop.io", raindropURL+path, "Bearer "+o.AccessToken.Value, target)
+}
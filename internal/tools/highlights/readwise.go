@@ -0,0 +1,149 @@
+package highlights
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+// see https://readwise.io/api_deets for the export of the highlights
+var readwiseURL = "https://readwise.io/api/v2/export/"
+
+// Readwise pulls the highlights of the books, articles and other documents
+// saved with Readwise
+type Readwise struct {
+	*plugins.PluginBase
+	AccessToken *plugins.SetupQuestion
+}
+
+func NewReadwise() (ret *Readwise) {
+	label := "Readwise"
+
+	ret = &Readwise{
+		PluginBase: &plugins.PluginBase{
+			Name:             i18n.T("readwise_label"),
+			SetupDescription: i18n.T("readwise_setup_description") + " " + i18n.T("optional_marker"),
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+	}
+
+	ret.AccessToken = ret.AddSetupQuestion("Access Token", false)
+
+	return
+}
+
+type readwiseTag struct {
+	Name string `json:"name"`
+}
+
+type readwiseBook struct {
+	Title      string        `json:"title"`
+	Author     string        `json:"author"`
+	Category   string        `json:"category"`
+	SourceURL  string        `json:"source_url"`
+	BookTags   []readwiseTag `json:"book_tags"`
+	Highlights []struct {
+		Text          string        `json:"text"`
+		Note          string        `json:"note"`
+		HighlightedAt *time.Time    `json:"highlighted_at"`
+		UpdatedAt     time.Time     `json:"updated_at"`
+		IsDiscard     bool          `json:"is_discard"`
+		Tags          []readwiseTag `json:"tags"`
+	} `json:"highlights"`
+}
+
+// Highlights returns the highlights of the query as Markdown, grouped by
+// document: those highlighted or updated since its time, of its category
+// (books, articles, tweets, podcasts, supplementals) and with its tags
+func (o *Readwise) Highlights(query string) (ret string, err error) {
+	if o.AccessToken.Value == "" {
+		return "", errors.New(i18n.T("readwise_not_configured"))
+	}
+	var q Query
+	if q, err = ParseQuery(query, time.Now()); err != nil {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(header(i18n.T("readwise_header"), q.Since))
+	count := 0
+	for cursor := ""; ; {
+		params := url.Values{}
+		if !q.Since.IsZero() {
+			params.Set("updatedAfter", q.Since.UTC().Format(time.RFC3339))
+		}
+		if cursor != "" {
+			params.Set("pageCursor", cursor)
+		}
+		var page struct {
+			NextPageCursor *string        `json:"nextPageCursor"`
+			Results        []readwiseBook `json:"results"`
+		}
+		if err = getJSON("Readwise", readwiseURL+"?"+params.Encode(), "Token "+o.AccessToken.Value, &page); err != nil {
+			return
+		}
+		for _, book := range page.Results {
+			count += writeReadwiseBook(&sb, book, q)
+		}
+		if page.NextPageCursor == nil || *page.NextPageCursor == "" {
+			break
+		}
+		cursor = *page.NextPageCursor
+	}
+	if count == 0 {
+		return "", errors.New(i18n.T("highlights_error_nothing_found"))
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// writeReadwiseBook writes the highlights of the book matching the query and
+// returns their count
+func writeReadwiseBook(sb *strings.Builder, book readwiseBook, q Query) (count int) {
+	if q.Category != "" && book.Category != q.Category {
+		return
+	}
+	var body strings.Builder
+	for _, highlight := range book.Highlights {
+		if highlight.IsDiscard || strings.TrimSpace(highlight.Text) == "" {
+			continue
+		}
+		updated := highlight.UpdatedAt
+		if highlight.HighlightedAt != nil && highlight.HighlightedAt.After(updated) {
+			updated = *highlight.HighlightedAt
+		}
+		if !q.Since.IsZero() && updated.Before(q.Since) {
+			continue
+		}
+		var tags []string
+		for _, tag := range slices.Concat(book.BookTags, highlight.Tags) {
+			tags = append(tags, tag.Name)
+		}
+		if !hasTags(tags, q.Tags) {
+			continue
+		}
+		body.WriteString("\n" + quote(highlight.Text) + "\n")
+		if note := strings.TrimSpace(highlight.Note); note != "" {
+			body.WriteString("\n" + fmt.Sprintf(i18n.T("highlights_note"), note) + "\n")
+		}
+		count++
+	}
+	if count == 0 {
+		return
+	}
+	title := book.Title
+	if book.Author != "" {
+		title += " — " + book.Author
+	}
+	sb.WriteString("\n## " + title + "\n")
+	if book.SourceURL != "" {
+		sb.WriteString("\n" + book.SourceURL + "\n")
+	}
+	sb.WriteString(body.String())
+	return
+}
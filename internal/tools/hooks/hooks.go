@@ -0,0 +1,121 @@
+// Package hooks runs the user's executables on the requests sent to the AI
+// vendors and on their responses, so that prompts can be rewritten, headers
+// injected and outputs scrubbed without changing fabric.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// Request is what a pre hook reads as JSON on its standard input. The hook
+// writes it back, changed, on its standard output, or writes nothing to leave
+// it as is.
+type Request struct {
+	Vendor   string                        `json:"vendor,omitempty"`
+	Model    string                        `json:"model"`
+	Pattern  string                        `json:"pattern,omitempty"`
+	Messages []*chat.ChatCompletionMessage `json:"messages"`
+	// Headers are added to the HTTP requests to the vendor
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Response is what a post hook reads as JSON on its standard input. The hook
+// writes it back, changed, on its standard output, or writes nothing to leave
+// it as is.
+type Response struct {
+	Vendor  string `json:"vendor,omitempty"`
+	Model   string `json:"model"`
+	Pattern string `json:"pattern,omitempty"`
+	Output  string `json:"output"`
+}
+
+// Hooks are the shell commands run in order on the requests, Pre, and on the
+// responses, Post
+type Hooks struct {
+	Pre  []string
+	Post []string
+}
+
+// RunPre passes the request through the pre hooks. The messages and the
+// headers a hook leaves out are kept.
+func (o *Hooks) RunPre(ctx context.Context, request *Request) (err error) {
+	for _, command := range o.Pre {
+		var output []byte
+		if output, err = run(ctx, command, request); err != nil {
+			return
+		}
+		if output == nil {
+			continue
+		}
+		var changed Request
+		if err = json.Unmarshal(output, &changed); err != nil {
+			return fmt.Errorf(i18n.T("hooks_invalid_output"), command, err)
+		}
+		if changed.Messages != nil {
+			request.Messages = changed.Messages
+		}
+		if changed.Headers != nil {
+			request.Headers = changed.Headers
+		}
+	}
+	return
+}
+
+// RunPost passes the response through the post hooks
+func (o *Hooks) RunPost(ctx context.Context, response *Response) (err error) {
+	for _, command := range o.Post {
+		var output []byte
+		if output, err = run(ctx, command, response); err != nil {
+			return
+		}
+		if output == nil {
+			continue
+		}
+		var changed struct {
+			Output *string `json:"output"`
+		}
+		if err = json.Unmarshal(output, &changed); err != nil {
+			return fmt.Errorf(i18n.T("hooks_invalid_output"), command, err)
+		}
+		if changed.Output != nil {
+			response.Output = *changed.Output
+		}
+	}
+	return
+}
+
+// run writes the value as JSON to the command run through the shell and
+// returns its output, nil when empty. Its standard error goes to the terminal.
+func run(ctx context.Context, command string, value any) (ret []byte, err error) {
+	var input []byte
+	if input, err = json.Marshal(value); err != nil {
+		return
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf(i18n.T("hooks_failed"), command, err)
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+package hooks
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks of the tests are sh commands")
+	}
+}
+
+func TestRunPre(t *testing.T) {
+	skipOnWindows(t)
+	hooks := &Hooks{Pre: []string{
+		// Rewrites the prompt
+		`sed 's/draft/final/'`,
+		// Leaves the request as is
+		"cat > /dev/null",
+		// Adds a header, keeping the messages
+		`printf '{"headers":{"Helicone-Property-Team":"docs"}}'`,
+	}}
+	request := &Request{Model: "gpt-4o", Messages: []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "the draft"}}}
+
+	require.NoError(t, hooks.RunPre(context.Background(), request))
+	require.Len(t, request.Messages, 1)
+	assert.Equal(t, "the final", request.Messages[0].Content)
+	assert.Equal(t, map[string]string{"Helicone-Property-Team": "docs"}, request.Headers)
+}
+
+func TestRunPost(t *testing.T) {
+	skipOnWindows(t)
+	hooks := &Hooks{Post: []string{`sed 's/hunter2/[scrubbed]/g'`, "true"}}
+	response := &Response{Model: "gpt-4o", Output: "the password is hunter2"}
+
+	require.NoError(t, hooks.RunPost(context.Background(), response))
+	assert.Equal(t, "the password is [scrubbed]", response.Output)
+}
+
+func TestRunErrors(t *testing.T) {
+	skipOnWindows(t)
+	tests := []struct {
+		name    string
+		command string
+	}{
+		{"failing command", "exit 3"},
+		{"invalid JSON", "echo nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooks := &Hooks{Post: []string{tt.command}}
+			assert.Error(t, hooks.RunPost(context.Background(), &Response{Output: "text"}))
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Package httprecord records the HTTP requests fabric makes, to the AI vendors
+// among others, with their responses in a directory, and replays them without
+// network, for --record and --replay.
+package httprecord
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// redacted replaces the secrets of the recorded URLs
+const redacted = "REDACTED"
+
+// secretParams are the query parameters holding API keys, as Gemini's key
+var secretParams = []string{"key", "api_key", "api-key", "apikey", "access_token", "token"}
+
+// Interaction is a request and its response, saved as one JSON file
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Its headers are not saved, as they hold the
+// API keys.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded response. A body which is not UTF-8 text, e.g. a
+// compressed one, is saved in base64.
+type Response struct {
+	Status     int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 bool        `json:"bodyBase64,omitempty"`
+}
+
+// Transport is an http.RoundTripper recording the interactions in its
+// directory, or replaying them when it has no next transport
+type Transport struct {
+	dir  string
+	next http.RoundTripper
+
+	mu sync.Mutex
+	// counts are the times each request was made, the same request being
+	// answered by its recordings in turn
+	counts map[string]int
+}
+
+// NewRecorder returns a transport sending the requests with next and saving
+// them with their responses in the directory, created when missing
+func NewRecorder(dir string, next http.RoundTripper) (ret *Transport, err error) {
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	return &Transport{dir: dir, next: next, counts: map[string]int{}}, nil
+}
+
+// NewReplayer returns a transport answering the requests with the responses
+// recorded in the directory, and failing those not recorded
+func NewReplayer(dir string) (ret *Transport, err error) {
+	if _, err = os.Stat(dir); err != nil {
+		return
+	}
+	return &Transport{dir: dir, counts: map[string]int{}}, nil
+}
+
+// Install makes the transport the default one of net/http, used by the vendor
+// SDKs, and returns the function restoring the previous one
+func Install(transport *Transport) (restore func()) {
+	previous := http.DefaultTransport
+	http.DefaultTransport = transport
+	return func() { http.DefaultTransport = previous }
+}
+
+// RoundTrip records or replays the request
+func (o *Transport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return
+		}
+		req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := Request{Method: req.Method, URL: RedactURL(req.URL), Body: string(body)}
+	key := Key(recorded)
+
+	o.mu.Lock()
+	o.counts[key]++
+	count := o.counts[key]
+	o.mu.Unlock()
+
+	if o.next == nil {
+		return o.replay(req, recorded, key, count)
+	}
+	if resp, err = o.next.RoundTrip(req); err != nil {
+		return
+	}
+	resp.Body = &recordingBody{
+		body:        resp.Body,
+		path:        o.path(key, count),
+		interaction: Interaction{Request: recorded, Response: Response{Status: resp.StatusCode, Header: savedHeader(resp.Header)}},
+	}
+	return
+}
+
+// replay answers the request with its recording of the count, or with its last
+// recording when it was made more times than recorded
+func (o *Transport) replay(req *http.Request, recorded Request, key string, count int) (resp *http.Response, err error) {
+	var data []byte
+	for ; count > 0; count-- {
+		if data, err = os.ReadFile(o.path(key, count)); err == nil || !os.IsNotExist(err) {
+			break
+		}
+	}
+	if count == 0 {
+		return nil, fmt.Errorf(i18n.T("httprecord_not_recorded"), recorded.Method, recorded.URL, o.dir)
+	}
+	if err != nil {
+		return
+	}
+	var interaction Interaction
+	if err = json.Unmarshal(data, &interaction); err != nil {
+		return nil, fmt.Errorf(i18n.T("httprecord_invalid_recording"), o.path(key, count), err)
+	}
+	body := []byte(interaction.Response.Body)
+	if interaction.Response.BodyBase64 {
+		if body, err = base64.StdEncoding.DecodeString(interaction.Response.Body); err != nil {
+			return nil, fmt.Errorf(i18n.T("httprecord_invalid_recording"), o.path(key, count), err)
+		}
+	}
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+		StatusCode:    interaction.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// path returns the file of the recording of the count, named after the host so
+// that the directory can be browsed
+func (o *Transport) path(key string, count int) string {
+	return filepath.Join(o.dir, fmt.Sprintf("%s-%03d.json", key, count))
+}
+
+// Key identifies a request by its host, method, URL and body, the same request
+// having the same key whatever its API key
+func Key(req Request) string {
+	host := "request"
+	if parsed, err := url.Parse(req.URL); err == nil && parsed.Host != "" {
+		host = strings.NewReplacer(":", "_", "/", "_").Replace(parsed.Host)
+	}
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL + "\n" + req.Body))
+	return host + "-" + hex.EncodeToString(sum[:6])
+}
+
+// RedactURL returns the URL with the values of its secret query parameters replaced
+func RedactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for name := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				query.Set(name, redacted)
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return copied.String()
+}
+
+// savedHeader returns the response headers without the cookies
+func savedHeader(header http.Header) http.Header {
+	ret := header.Clone()
+	ret.Del("Set-Cookie")
+	return ret
+}
+
+// recordingBody passes the response body to the caller, streamed responses
+// included, and saves the interaction once it was read or closed
+type recordingBody struct {
+	body        io.ReadCloser
+	path        string
+	interaction Interaction
+	read        bytes.Buffer
+	once        sync.Once
+	saveErr     error
+}
+
+func (o *recordingBody) Read(p []byte) (n int, err error) {
+	n, err = o.body.Read(p)
+	o.read.Write(p[:n])
+	if err == io.EOF {
+		o.save()
+	}
+	return
+}
+
+func (o *recordingBody) Close() error {
+	err := o.body.Close()
+	o.save()
+	if err == nil {
+		err = o.saveErr
+	}
+	return err
+}
+
+func (o *recordingBody) save() {
+	o.once.Do(func() {
+		body := o.read.Bytes()
+		if utf8.Valid(body) {
+			o.interaction.Response.Body = string(body)
+		} else {
+			o.interaction.Response.Body = base64.StdEncoding.EncodeToString(body)
+			o.interaction.Response.BodyBase64 = true
+		}
+		var data []byte
+		if data, o.saveErr = json.MarshalIndent(o.interaction, "", "  "); o.saveErr != nil {
+			return
+		}
+		o.saveErr = os.WriteFile(o.path, append(data, '\n'), 0o644)
+	})
+}
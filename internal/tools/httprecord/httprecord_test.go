@@ -0,0 +1,103 @@
+package httprecord
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func post(t *testing.T, client *http.Client, target, body string) (status int, got string) {
+	t.Helper()
+	resp, err := client.Post(target, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading the body: %v", err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "data: "+string(body)+" "+strings.Repeat("!", calls)+"\n\n")
+	}))
+	dir := t.TempDir()
+
+	recorder, err := NewRecorder(dir, http.DefaultTransport)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	client := &http.Client{Transport: recorder}
+	target := server.URL + "/v1/chat?key=secret-api-key"
+	recorded := []string{}
+	for _, body := range []string{"hello", "hello", "other"} {
+		status, got := post(t, client, target, body)
+		if status != http.StatusCreated {
+			t.Fatalf("status = %d", status)
+		}
+		recorded = append(recorded, got)
+	}
+	server.Close()
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 3 {
+		t.Fatalf("expected 3 recordings, got %d", len(files))
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(dir + "/" + file.Name())
+		if strings.Contains(string(data), "secret") {
+			t.Errorf("recording %s keeps a secret:\n%s", file.Name(), data)
+		}
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatalf("NewReplayer() error = %v", err)
+	}
+	client = &http.Client{Transport: replayer}
+	// The API key may differ, the same request made more times than recorded
+	// gets its last response
+	target = server.URL + "/v1/chat?key=another-key"
+	for i, body := range []string{"hello", "other", "hello", "hello"} {
+		want := map[int]string{0: recorded[0], 1: recorded[2], 2: recorded[1], 3: recorded[1]}[i]
+		status, got := post(t, client, target, body)
+		if status != http.StatusCreated || got != want {
+			t.Errorf("replay %d = %d %q, want %d %q", i, status, got, http.StatusCreated, want)
+		}
+	}
+
+	if _, err = client.Post(target, "application/json", strings.NewReader("unknown")); err == nil ||
+		!strings.Contains(err.Error(), "/v1/chat") {
+		t.Errorf("expected an error for a request not recorded, got %v", err)
+	}
+}
+
+func TestNewReplayerMissingDirectory(t *testing.T) {
+	if _, err := NewReplayer(t.TempDir() + "/missing"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse("https://example.com/v1beta/models?alt=sse&key=abc")
+	if got, want := RedactURL(u), "https://example.com/v1beta/models?alt=sse&key=REDACTED"; got != want {
+		t.Errorf("RedactURL() = %q, want %q", got, want)
+	}
+	u, _ = url.Parse("https://example.com/v1/models?b=2&a=1")
+	if got, want := RedactURL(u), "https://example.com/v1/models?b=2&a=1"; got != want {
+		t.Errorf("RedactURL() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package imageproc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// heicConverters are tried in order; each builds the command line converting in to out.
+var heicConverters = []struct {
+	name string
+	args func(in, out string) []string
+}{
+	{"heif-convert", func(in, out string) []string { return []string{in, out} }},
+	{"magick", func(in, out string) []string { return []string{in, out} }},
+	{"convert", func(in, out string) []string { return []string{in, out} }},
+	{"sips", func(in, out string) []string { return []string{"-s", "format", "jpeg", in, "--out", out} }},
+}
+
+// convertHEIC converts HEIC/HEIF data to JPEG using the first available external tool.
+func convertHEIC(data []byte) (ret []byte, err error) {
+	var tempDir string
+	if tempDir, err = os.MkdirTemp("", "fabric-heic-"); err != nil {
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	in := filepath.Join(tempDir, "input.heic")
+	out := filepath.Join(tempDir, "output.jpg")
+	if err = os.WriteFile(in, data, 0600); err != nil {
+		return
+	}
+
+	var names []string
+	for _, converter := range heicConverters {
+		names = append(names, converter.name)
+		path, lookErr := exec.LookPath(converter.name)
+		if lookErr != nil {
+			continue
+		}
+		var stderr bytes.Buffer
+		cmd := exec.Command(path, converter.args(in, out)...)
+		cmd.Stderr = &stderr
+		if err = cmd.Run(); err != nil {
+			return nil, fmt.Errorf(i18n.T("imageproc_error_converting_heic"), converter.name, err, strings.TrimSpace(stderr.String()))
+		}
+		return os.ReadFile(out)
+	}
+	return nil, fmt.Errorf(i18n.T("imageproc_error_heic_converter_not_found"), strings.Join(names, ", "))
+}
@@ -0,0 +1,200 @@
+// Package imageproc prepares image attachments before they are sent to a vendor.
+//
+// Images can be downscaled so their longest side fits a maximum dimension and have
+// their EXIF/XMP metadata removed. JPEG orientation from EXIF is applied to the
+// pixels before the metadata is dropped, so photos keep facing the right way.
+// HEIC/HEIF images, which most vendors reject, can be converted to JPEG using an
+// external tool (heif-convert, ImageMagick or sips) when one is available.
+//
+// JPEG and PNG are processed natively; other formats are passed through unchanged.
+package imageproc
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const jpegQuality = 90
+
+// Options selects the preprocessing steps applied to an image.
+type Options struct {
+	// MaxDim is the maximum size in pixels of the longest side; 0 disables resizing.
+	MaxDim int
+	// StripEXIF removes EXIF, XMP and textual metadata from the image.
+	StripEXIF bool
+	// ConvertHEIC converts HEIC/HEIF images to JPEG.
+	ConvertHEIC bool
+}
+
+// Enabled reports whether any preprocessing step is requested.
+func (o Options) Enabled() bool {
+	return o.MaxDim > 0 || o.StripEXIF || o.ConvertHEIC
+}
+
+// IsHEIC reports whether mimeType denotes a HEIC/HEIF image.
+func IsHEIC(mimeType string) bool {
+	switch baseMimeType(mimeType) {
+	case "image/heic", "image/heif", "image/heic-sequence", "image/heif-sequence":
+		return true
+	}
+	return false
+}
+
+// Process applies opts to the image in data and returns the resulting bytes and MIME
+// type. HEIC/HEIF input is converted to JPEG with opts.ConvertHEIC and passed through
+// otherwise. Resizing re-encodes the image, which drops its metadata as a side effect.
+func Process(data []byte, mimeType string, opts Options) (ret []byte, retMimeType string, err error) {
+	ret, retMimeType = data, baseMimeType(mimeType)
+
+	if opts.ConvertHEIC && IsHEIC(retMimeType) {
+		if ret, err = convertHEIC(data); err != nil {
+			return
+		}
+		retMimeType = "image/jpeg"
+	}
+
+	if (opts.MaxDim <= 0 && !opts.StripEXIF) || (retMimeType != "image/jpeg" && retMimeType != "image/png") {
+		return
+	}
+
+	var config image.Config
+	if config, _, err = image.DecodeConfig(bytes.NewReader(ret)); err != nil {
+		return nil, "", fmt.Errorf(i18n.T("imageproc_error_decoding_image"), err)
+	}
+
+	orientation := 1
+	if retMimeType == "image/jpeg" {
+		orientation = jpegOrientation(ret)
+	}
+
+	needsResize := opts.MaxDim > 0 && max(config.Width, config.Height) > opts.MaxDim
+	if !needsResize && orientation == 1 {
+		if opts.StripEXIF {
+			ret, err = stripMetadata(ret, retMimeType)
+		}
+		return
+	}
+
+	var img image.Image
+	if img, _, err = image.Decode(bytes.NewReader(ret)); err != nil {
+		return nil, "", fmt.Errorf(i18n.T("imageproc_error_decoding_image"), err)
+	}
+
+	rgba := orient(toNRGBA(img), orientation)
+	if needsResize {
+		rgba = resize(rgba, opts.MaxDim)
+	}
+
+	var buf bytes.Buffer
+	if retMimeType == "image/png" {
+		err = png.Encode(&buf, rgba)
+	} else {
+		err = jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf(i18n.T("imageproc_error_encoding_image"), err)
+	}
+	ret = buf.Bytes()
+	return
+}
+
+func baseMimeType(mimeType string) string {
+	base, _, _ := strings.Cut(mimeType, ";")
+	return strings.ToLower(strings.TrimSpace(base))
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok && nrgba.Rect.Min == (image.Point{}) {
+		return nrgba
+	}
+	bounds := img.Bounds()
+	ret := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(ret, ret.Bounds(), img, bounds.Min, draw.Src)
+	return ret
+}
+
+// orient applies the EXIF orientation (1-8) to the image.
+func orient(src *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		for x := range dw {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			si := src.PixOffset(sx, sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// resize downscales the image so its longest side is maxDim, averaging the source
+// pixels covered by each destination pixel.
+func resize(src *image.NRGBA, maxDim int) *image.NRGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := maxDim, maxDim
+	if w >= h {
+		dh = max(1, h*maxDim/w)
+	} else {
+		dw = max(1, w*maxDim/h)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := range dw {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					pa := uint64(src.Pix[i+3])
+					r += uint64(src.Pix[i]) * pa
+					g += uint64(src.Pix[i+1]) * pa
+					b += uint64(src.Pix[i+2]) * pa
+					a += pa
+					n++
+					i += 4
+				}
+			}
+			di := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[di] = uint8(r / a)
+				dst.Pix[di+1] = uint8(g / a)
+				dst.Pix[di+2] = uint8(b / a)
+			}
+			dst.Pix[di+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
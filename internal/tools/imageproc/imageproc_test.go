@@ -0,0 +1,177 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF APP1 segment carrying the given orientation.
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], exifTagOrientation)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	payload := append(append([]byte{}, exifHeader...), tiff...)
+	segment := []byte{0xFF, jpegMarkerAPP1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func decodeSize(t *testing.T, data []byte) (int, int) {
+	t.Helper()
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("DecodeConfig() error = %v", err)
+	}
+	return config.Width, config.Height
+}
+
+func TestProcessResize(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxDim        int
+		wantW, wantH  int
+	}{
+		{name: "landscape", width: 400, height: 200, maxDim: 100, wantW: 100, wantH: 50},
+		{name: "portrait", width: 90, height: 300, maxDim: 150, wantW: 45, wantH: 150},
+		{name: "already small", width: 80, height: 60, maxDim: 100, wantW: 80, wantH: 60},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data := encodeJPEG(t, testImage(tc.width, tc.height))
+			out, mimeType, err := Process(data, "image/jpeg", Options{MaxDim: tc.maxDim})
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if mimeType != "image/jpeg" {
+				t.Errorf("mimeType = %q", mimeType)
+			}
+			if w, h := decodeSize(t, out); w != tc.wantW || h != tc.wantH {
+				t.Errorf("size = %dx%d, want %dx%d", w, h, tc.wantW, tc.wantH)
+			}
+		})
+	}
+}
+
+func TestProcessStripEXIF(t *testing.T) {
+	data := withOrientation(encodeJPEG(t, testImage(40, 20)), 1)
+	if jpegOrientation(data) != 1 || !bytes.Contains(data, exifHeader) {
+		t.Fatal("test image should carry EXIF data")
+	}
+
+	out, _, err := Process(data, "image/jpeg", Options{StripEXIF: true})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if bytes.Contains(out, exifHeader) {
+		t.Error("EXIF segment was not stripped")
+	}
+	if w, h := decodeSize(t, out); w != 40 || h != 20 {
+		t.Errorf("size = %dx%d, want 40x20", w, h)
+	}
+}
+
+func TestProcessAppliesOrientation(t *testing.T) {
+	data := withOrientation(encodeJPEG(t, testImage(40, 20)), 6)
+	if got := jpegOrientation(data); got != 6 {
+		t.Fatalf("jpegOrientation() = %d, want 6", got)
+	}
+
+	out, _, err := Process(data, "image/jpeg", Options{StripEXIF: true})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if bytes.Contains(out, exifHeader) {
+		t.Error("EXIF segment was not stripped")
+	}
+	if w, h := decodeSize(t, out); w != 20 || h != 40 {
+		t.Errorf("size = %dx%d, want rotated 20x40", w, h)
+	}
+}
+
+func TestProcessStripPNGMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(10, 10)); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	// Insert a tEXt chunk right after IHDR (signature + 25 byte IHDR chunk).
+	text := []byte("Comment\x00secret")
+	chunk := make([]byte, 8, 12+len(text))
+	binary.BigEndian.PutUint32(chunk, uint32(len(text)))
+	copy(chunk[4:], "tEXt")
+	chunk = append(chunk, text...)
+	chunk = append(chunk, 0, 0, 0, 0)
+	withText := append(append(append([]byte{}, data[:33]...), chunk...), data[33:]...)
+
+	out, mimeType, err := Process(withText, "image/png", Options{StripEXIF: true})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if mimeType != "image/png" || bytes.Contains(out, []byte("secret")) {
+		t.Errorf("tEXt chunk was not stripped (mimeType %q)", mimeType)
+	}
+	if !bytes.Equal(out, data) {
+		t.Error("stripping should leave the remaining chunks untouched")
+	}
+}
+
+func TestProcessPassThrough(t *testing.T) {
+	data := []byte("RIFF....WEBP")
+	out, mimeType, err := Process(data, "image/webp", Options{MaxDim: 10, StripEXIF: true})
+	if err != nil || !bytes.Equal(out, data) || mimeType != "image/webp" {
+		t.Errorf("Process() = %q, %q, %v; want unchanged", out, mimeType, err)
+	}
+}
+
+func TestProcessHEICWithoutConvert(t *testing.T) {
+	data := []byte("....ftypheic")
+	out, mimeType, err := Process(data, "image/heic", Options{MaxDim: 10})
+	if err != nil || !bytes.Equal(out, data) || mimeType != "image/heic" {
+		t.Errorf("Process() = %q, %q, %v; want unchanged without ConvertHEIC", out, mimeType, err)
+	}
+}
+
+func TestIsHEIC(t *testing.T) {
+	for mimeType, want := range map[string]bool{"image/heic": true, "IMAGE/HEIF; foo=bar": true, "image/jpeg": false} {
+		if got := IsHEIC(mimeType); got != want {
+			t.Errorf("IsHEIC(%q) = %v, want %v", mimeType, got, want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Package issues pulls the issues of Jira and Linear with their comments as
+// Markdown input for the patterns, and posts the outputs back as comments.
+package issues
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// Issue is an issue of a tracker, with its comments oldest first
+type Issue struct {
+	Key         string
+	Title       string
+	URL         string
+	Type        string
+	Status      string
+	Priority    string
+	Assignee    string
+	Reporter    string
+	Labels      []string
+	Created     time.Time
+	Updated     time.Time
+	Description string
+	Comments    []Comment
+}
+
+type Comment struct {
+	Author  string
+	Created time.Time
+	Body    string
+}
+
+// Markdown returns the issue as Markdown: its key and title as first
+// heading, its fields as a list, then its description and comments
+func (o *Issue) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# " + o.Key + ": " + o.Title + "\n\n")
+	for _, field := range []struct{ key, value string }{
+		{"issues_type", o.Type},
+		{"issues_status", o.Status},
+		{"issues_priority", o.Priority},
+		{"issues_assignee", o.Assignee},
+		{"issues_reporter", o.Reporter},
+		{"issues_labels", strings.Join(o.Labels, ", ")},
+		{"issues_created", formatTime(o.Created)},
+		{"issues_updated", formatTime(o.Updated)},
+		{"issues_url", o.URL},
+	} {
+		if field.value != "" {
+			sb.WriteString("- " + i18n.T(field.key) + ": " + field.value + "\n")
+		}
+	}
+	if description := strings.TrimSpace(o.Description); description != "" {
+		sb.WriteString("\n## " + i18n.T("issues_description") + "\n\n" + description + "\n")
+	}
+	if len(o.Comments) > 0 {
+		sb.WriteString("\n## " + i18n.T("issues_comments") + "\n")
+		for _, comment := range o.Comments {
+			sb.WriteString("\n### " + cmp.Or(comment.Author, i18n.T("issues_unknown_author")))
+			if !comment.Created.IsZero() {
+				sb.WriteString(" — " + formatTime(comment.Created))
+			}
+			sb.WriteString("\n\n" + strings.TrimSpace(comment.Body) + "\n")
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// keyRegex matches the keys of the issues of Jira and Linear, like PROJ-123
+var keyRegex = regexp.MustCompile(`[A-Z][A-Z0-9_]*-[0-9]+`)
+
+// issueKey returns the key of the issue, given by key or by URL
+func issueKey(service, issue string) (string, error) {
+	issue = strings.TrimSpace(issue)
+	if !strings.Contains(issue, "/") {
+		issue = strings.ToUpper(issue)
+	}
+	// The key comes after the host and the project of the URL
+	keys := keyRegex.FindAllString(issue, -1)
+	if len(keys) == 0 {
+		return "", fmt.Errorf(i18n.T("issues_error_invalid_key"), issue, service)
+	}
+	return keys[len(keys)-1], nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04 MST")
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// doJSON sends the request of the service with the body as JSON, if any, and
+// decodes its response to the target, if any
+func doJSON(service, method, requestURL, authorization string, body, target any) (err error) {
+	var reader io.Reader
+	if body != nil {
+		var data []byte
+		if data, err = json.Marshal(body); err != nil {
+			return
+		}
+		reader = bytes.NewReader(data)
+	}
+	var req *http.Request
+	if req, err = http.NewRequest(method, requestURL, reader); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		return fmt.Errorf(i18n.T("issues_error_request"), service, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf(i18n.T("issues_error_status"), service, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if target == nil {
+		return
+	}
+	if err = json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf(i18n.T("issues_error_request"), service, err)
+	}
+	return
+}
@@ -0,0 +1,151 @@
+package issues
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIssueKey(t *testing.T) {
+	for issue, want := range map[string]string{
+		"PROJ-123": "PROJ-123",
+		"proj-123": "PROJ-123",
+		"https://example.atlassian.net/browse/PROJ-123":                                           "PROJ-123",
+		"https://example.atlassian.net/jira/software/projects/PROJ/boards/1?selectedIssue=PROJ-7": "PROJ-7",
+		"https://linear.app/acme/issue/ENG-42/fix-the-login-page":                                 "ENG-42",
+	} {
+		if got, err := issueKey("Jira", issue); err != nil || got != want {
+			t.Errorf("issueKey(%q) = %q, %v, want %q", issue, got, err, want)
+		}
+	}
+	if _, err := issueKey("Jira", "https://example.atlassian.net/browse/"); err == nil {
+		t.Errorf("issueKey() of a URL without key expected an error")
+	}
+}
+
+func TestWikiMarkup(t *testing.T) {
+	markdown := "## Summary\n\nThe **login** fails with `nil` on [staging](https://staging.example.com) and *mobile*.\n\n" +
+		"- first\n  - nested\n1. step\n\n> quoted\n\n```go\nx := *y\n```\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n---"
+	want := "h2. Summary\n\nThe *login* fails with {{nil}} on [staging|https://staging.example.com] and _mobile_.\n\n" +
+		"* first\n** nested\n# step\n\nbq. quoted\n\n{code:go}\nx := *y\n{code}\n\n||a||b||\n|1|2|\n\n----"
+	if got := WikiMarkup(markdown); got != want {
+		t.Errorf("WikiMarkup() = %q, want %q", got, want)
+	}
+}
+
+func TestJira(t *testing.T) {
+	var posted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "me@example.com" || token != "secret" {
+			http.Error(w, `{"errorMessages": ["unauthorized"]}`, http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/rest/api/2/issue/PROJ-1":
+			fmt.Fprint(w, `{"key": "PROJ-1", "fields": {"summary": "Login fails", "description": "It *fails*.",
+				"issuetype": {"name": "Bug"}, "status": {"name": "Open"}, "priority": null, "assignee": null,
+				"reporter": {"displayName": "Ada"}, "labels": ["auth"], "created": "2026-10-01T09:30:00.000+0200"}}`)
+		case r.URL.Path == "/rest/api/2/issue/PROJ-1/comment" && r.Method == http.MethodGet:
+			// The second page starts after the first one
+			if r.URL.Query().Get("startAt") == "0" {
+				fmt.Fprint(w, `{"total": 2, "comments": [{"author": {"displayName": "Bob"}, "body": "Cannot reproduce.", "created": "2026-10-02T10:00:00.000+0000"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"total": 2, "comments": [{"author": {"displayName": "Ada"}, "body": "On mobile only.", "created": "2026-10-03T10:00:00.000+0000"}]}`)
+		case r.URL.Path == "/rest/api/2/issue/PROJ-1/comment" && r.Method == http.MethodPost:
+			var body struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			posted = body.Body
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": "10"}`)
+		default:
+			http.Error(w, `{"errorMessages": ["Issue does not exist"]}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	jira := NewJira()
+	if _, err := jira.Issue("PROJ-1"); err == nil {
+		t.Errorf("Issue() without configuration expected an error")
+	}
+	jira.URL.Value, jira.Email.Value, jira.APIToken.Value = server.URL+"/", "me@example.com", "secret"
+	issue, err := jira.Issue(server.URL + "/browse/PROJ-1")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	got := issue.Markdown()
+	for _, want := range []string{"# PROJ-1: Login fails", "- Type: Bug", "- Reporter: Ada", "- Labels: auth",
+		"- Created: 2026-10-01 07:30 UTC", "- URL: " + server.URL + "/browse/PROJ-1", "## Description\n\nIt *fails*.",
+		"### Bob — 2026-10-02 10:00 UTC\n\nCannot reproduce.", "### Ada — 2026-10-03 10:00 UTC\n\nOn mobile only."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown() = %q, want %q", got, want)
+		}
+	}
+	if strings.Contains(got, "Priority") || strings.Contains(got, "Assignee") {
+		t.Errorf("Markdown() = %q, should skip the empty fields", got)
+	}
+
+	if err = jira.PostComment("PROJ-1", "**Triage**: duplicate"); err != nil || posted != "*Triage*: duplicate" {
+		t.Errorf("PostComment() = %v, posted %q", err, posted)
+	}
+	if _, err = jira.Issue("PROJ-2"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Issue() of a missing issue error = %v, want the status", err)
+	}
+}
+
+func TestLinear(t *testing.T) {
+	var posted map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var request struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		switch {
+		case strings.Contains(request.Query, "commentCreate"):
+			posted = request.Variables
+			fmt.Fprint(w, `{"data": {"commentCreate": {"success": true}}}`)
+		case request.Variables["id"] == "ENG-42":
+			fmt.Fprint(w, `{"data": {"issue": {"id": "uuid-42", "identifier": "ENG-42", "title": "Slow search",
+				"description": "Search takes **5s**.", "url": "https://linear.app/acme/issue/ENG-42", "priorityLabel": "High",
+				"state": {"name": "In Progress"}, "assignee": {"name": "Ada"}, "labels": {"nodes": [{"name": "perf"}, {"name": "search"}]},
+				"comments": {"nodes": [
+					{"body": "Fixed in #12.", "createdAt": "2026-10-05T08:00:00Z", "user": null},
+					{"body": "Profiling now.", "createdAt": "2026-10-04T08:00:00Z", "user": {"name": "Ada"}}]}}}}`)
+		default:
+			fmt.Fprint(w, `{"data": null, "errors": [{"message": "Entity not found: Issue"}]}`)
+		}
+	}))
+	defer server.Close()
+	linearURL = server.URL
+
+	linear := NewLinear()
+	linear.APIKey.Value = "lin_api_secret"
+	issue, err := linear.Issue("https://linear.app/acme/issue/ENG-42/slow-search")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	got := issue.Markdown()
+	for _, want := range []string{"# ENG-42: Slow search", "- Status: In Progress", "- Priority: High", "- Labels: perf, search",
+		"Search takes **5s**.", "### Ada — 2026-10-04 08:00 UTC\n\nProfiling now.\n\n### Unknown author — 2026-10-05 08:00 UTC"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown() = %q, want %q", got, want)
+		}
+	}
+
+	if err = linear.PostComment("eng-42", "Duplicate of ENG-7"); err != nil || posted["issueId"] != "uuid-42" || posted["body"] != "Duplicate of ENG-7" {
+		t.Errorf("PostComment() = %v, posted %v", err, posted)
+	}
+	if _, err = linear.Issue("ENG-1"); err == nil || !strings.Contains(err.Error(), "Entity not found") {
+		t.Errorf("Issue() of a missing issue error = %v, want the GraphQL error", err)
+	}
+}
@@ -0,0 +1,264 @@
+package issues
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+// jiraTimeLayout is the layout of the times of the REST API
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// Jira pulls the issues of Jira Cloud, with an email and API token, or of
+// Jira Data Center, with a personal access token and no email
+type Jira struct {
+	*plugins.PluginBase
+	URL      *plugins.SetupQuestion
+	Email    *plugins.SetupQuestion
+	APIToken *plugins.SetupQuestion
+}
+
+func NewJira() (ret *Jira) {
+	label := "Jira"
+
+	ret = &Jira{
+		PluginBase: &plugins.PluginBase{
+			Name:             i18n.T("jira_label"),
+			SetupDescription: i18n.T("jira_setup_description") + " " + i18n.T("optional_marker"),
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+	}
+
+	ret.URL = ret.AddSetupQuestion("URL", false)
+	ret.Email = ret.AddSetupQuestion("Email", false)
+	ret.APIToken = ret.AddSetupQuestion("API Token", false)
+
+	return
+}
+
+type jiraUser struct {
+	DisplayName string `json:"displayName"`
+}
+
+type jiraName struct {
+	Name string `json:"name"`
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string    `json:"summary"`
+		Description string    `json:"description"`
+		IssueType   *jiraName `json:"issuetype"`
+		Status      *jiraName `json:"status"`
+		Priority    *jiraName `json:"priority"`
+		Assignee    *jiraUser `json:"assignee"`
+		Reporter    *jiraUser `json:"reporter"`
+		Labels      []string  `json:"labels"`
+		Created     string    `json:"created"`
+		Updated     string    `json:"updated"`
+	} `json:"fields"`
+}
+
+type jiraComment struct {
+	Author  *jiraUser `json:"author"`
+	Body    string    `json:"body"`
+	Created string    `json:"created"`
+}
+
+// Issue returns the issue of the key or URL with all its comments, the
+// description and comments in the wiki markup of Jira
+func (o *Jira) Issue(issue string) (ret *Issue, err error) {
+	var key string
+	if key, err = o.key(issue); err != nil {
+		return
+	}
+	var resp jiraIssue
+	fields := "summary,description,issuetype,status,priority,assignee,reporter,labels,created,updated"
+	if err = o.do(http.MethodGet, "/issue/"+key+"?fields="+fields, nil, &resp); err != nil {
+		return
+	}
+	f := resp.Fields
+	ret = &Issue{
+		Key:         resp.Key,
+		Title:       f.Summary,
+		URL:         strings.TrimRight(o.URL.Value, "/") + "/browse/" + resp.Key,
+		Type:        jiraNameOf(f.IssueType),
+		Status:      jiraNameOf(f.Status),
+		Priority:    jiraNameOf(f.Priority),
+		Assignee:    jiraDisplayName(f.Assignee),
+		Reporter:    jiraDisplayName(f.Reporter),
+		Labels:      f.Labels,
+		Created:     jiraTime(f.Created),
+		Updated:     jiraTime(f.Updated),
+		Description: f.Description,
+	}
+	for start := 0; ; {
+		var page struct {
+			Comments []jiraComment `json:"comments"`
+			Total    int           `json:"total"`
+		}
+		params := url.Values{"startAt": {strconv.Itoa(start)}, "maxResults": {"100"}, "orderBy": {"created"}}
+		if err = o.do(http.MethodGet, "/issue/"+key+"/comment?"+params.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		for _, comment := range page.Comments {
+			ret.Comments = append(ret.Comments, Comment{Author: jiraDisplayName(comment.Author), Created: jiraTime(comment.Created), Body: comment.Body})
+		}
+		start += len(page.Comments)
+		if len(page.Comments) == 0 || start >= page.Total {
+			return
+		}
+	}
+}
+
+// PostComment posts the Markdown as a comment of the issue, converted to the
+// wiki markup of Jira
+func (o *Jira) PostComment(issue, markdown string) (err error) {
+	var key string
+	if key, err = o.key(issue); err != nil {
+		return
+	}
+	return o.do(http.MethodPost, "/issue/"+key+"/comment", map[string]string{"body": WikiMarkup(markdown)}, nil)
+}
+
+func (o *Jira) key(issue string) (string, error) {
+	if o.URL.Value == "" || o.APIToken.Value == "" {
+		return "", errors.New(i18n.T("jira_not_configured"))
+	}
+	return issueKey("Jira", issue)
+}
+
+// do sends the request to the version 2 of the REST API, which takes and
+// returns the texts as wiki markup rather than as documents
+func (o *Jira) do(method, path string, body, target any) error {
+	authorization := "Bearer " + o.APIToken.Value
+	if o.Email.Value != "" {
+		authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(o.Email.Value+":"+o.APIToken.Value))
+	}
+	return doJSON("Jira", method, strings.TrimRight(o.URL.Value, "/")+"/rest/api/2"+path, authorization, body, target)
+}
+
+func jiraNameOf(field *jiraName) string {
+	if field == nil {
+		return ""
+	}
+	return field.Name
+}
+
+func jiraDisplayName(user *jiraUser) string {
+	if user == nil {
+		return ""
+	}
+	return user.DisplayName
+}
+
+func jiraTime(value string) time.Time {
+	t, _ := time.Parse(jiraTimeLayout, value)
+	return t
+}
+
+var (
+	wikiFenceRegex   = regexp.MustCompile("^\\s*```+\\s*([\\w+#-]*)\\s*$")
+	wikiHeadingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	wikiListRegex    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	wikiQuoteRegex   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	wikiRuleRegex    = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	wikiTableRegex   = regexp.MustCompile(`^\s*\|(.*)\|\s*$`)
+	wikiTableRule    = regexp.MustCompile(`^\s*\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*\|?\s*$`)
+	// wikiInlineRegex matches the code spans, links and emphasis of a line,
+	// in the order they take precedence
+	wikiInlineRegex = regexp.MustCompile("`([^`]+)`" +
+		`|\[([^\]]+)\]\(([^)\s]+)[^)]*\)` +
+		`|\*\*([^*]+)\*\*|__([^_]+)__` +
+		`|~~([^~]+)~~` +
+		`|\*([^*\s][^*]*?)\*|\b_([^_\s][^_]*?)_\b`)
+)
+
+// WikiMarkup converts Markdown to the wiki markup of Jira: headings, lists,
+// quotes, code blocks, tables, rules, code spans, links and emphasis
+func WikiMarkup(markdown string) string {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	var ret []string
+	tableHeader := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if match := wikiFenceRegex.FindStringSubmatch(line); match != nil {
+			tag := "{noformat}"
+			open := tag
+			if match[1] != "" {
+				tag, open = "{code}", "{code:"+match[1]+"}"
+			}
+			ret = append(ret, open)
+			for i++; i < len(lines) && !wikiFenceRegex.MatchString(lines[i]); i++ {
+				ret = append(ret, lines[i])
+			}
+			ret = append(ret, tag)
+			continue
+		}
+		if wikiTableRegex.MatchString(line) {
+			if wikiTableRule.MatchString(line) {
+				continue
+			}
+			// The first row is the header, when a rule follows it
+			separator := "|"
+			if !tableHeader && i+1 < len(lines) && wikiTableRule.MatchString(lines[i+1]) {
+				separator = "||"
+			}
+			tableHeader = true
+			var cells []string
+			for _, cell := range strings.Split(wikiTableRegex.FindStringSubmatch(line)[1], "|") {
+				cells = append(cells, wikiInline(strings.TrimSpace(cell)))
+			}
+			ret = append(ret, separator+strings.Join(cells, separator)+separator)
+			continue
+		}
+		tableHeader = false
+		switch {
+		case wikiHeadingRegex.MatchString(line):
+			match := wikiHeadingRegex.FindStringSubmatch(line)
+			ret = append(ret, "h"+strconv.Itoa(len(match[1]))+". "+wikiInline(strings.TrimRight(match[2], " #")))
+		case wikiRuleRegex.MatchString(line):
+			ret = append(ret, "----")
+		case wikiQuoteRegex.MatchString(line):
+			ret = append(ret, "bq. "+wikiInline(wikiQuoteRegex.FindStringSubmatch(line)[1]))
+		case wikiListRegex.MatchString(line):
+			match := wikiListRegex.FindStringSubmatch(line)
+			marker := "*"
+			if match[2][0] >= '0' && match[2][0] <= '9' {
+				marker = "#"
+			}
+			depth := len(strings.ReplaceAll(match[1], "\t", "  "))/2 + 1
+			ret = append(ret, strings.Repeat(marker, depth)+" "+wikiInline(match[3]))
+		default:
+			ret = append(ret, wikiInline(line))
+		}
+	}
+	return strings.Join(ret, "\n")
+}
+
+func wikiInline(line string) string {
+	return wikiInlineRegex.ReplaceAllStringFunc(line, func(span string) string {
+		match := wikiInlineRegex.FindStringSubmatch(span)
+		switch {
+		case match[1] != "":
+			return "{{" + match[1] + "}}"
+		case match[2] != "":
+			return "[" + wikiInline(match[2]) + "|" + match[3] + "]"
+		case match[4] != "" || match[5] != "":
+			return "*" + wikiInline(match[4]+match[5]) + "*"
+		case match[6] != "":
+			return "-" + wikiInline(match[6]) + "-"
+		default:
+			return "_" + wikiInline(match[7]+match[8]) + "_"
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package issues
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+// see https://developers.linear.app/docs/graphql/working-with-the-graphql-api
+var linearURL = "https://api.linear.app/graphql"
+
+// linearComments is the most comments of an issue pulled, the latest ones
+const linearComments = 250
+
+// Linear pulls the issues of Linear, with a personal API key
+type Linear struct {
+	*plugins.PluginBase
+	APIKey *plugins.SetupQuestion
+}
+
+func NewLinear() (ret *Linear) {
+	label := "Linear"
+
+	ret = &Linear{
+		PluginBase: &plugins.PluginBase{
+			Name:             i18n.T("linear_label"),
+			SetupDescription: i18n.T("linear_setup_description") + " " + i18n.T("optional_marker"),
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+	}
+
+	ret.APIKey = ret.AddSetupQuestion("API Key", false)
+
+	return
+}
+
+type linearName struct {
+	Name string `json:"name"`
+}
+
+type linearIssue struct {
+	ID            string      `json:"id"`
+	Identifier    string      `json:"identifier"`
+	Title         string      `json:"title"`
+	Description   string      `json:"description"`
+	URL           string      `json:"url"`
+	PriorityLabel string      `json:"priorityLabel"`
+	CreatedAt     time.Time   `json:"createdAt"`
+	UpdatedAt     time.Time   `json:"updatedAt"`
+	State         *linearName `json:"state"`
+	Assignee      *linearName `json:"assignee"`
+	Creator       *linearName `json:"creator"`
+	Labels        struct {
+		Nodes []linearName `json:"nodes"`
+	} `json:"labels"`
+	Comments struct {
+		Nodes []struct {
+			Body      string      `json:"body"`
+			CreatedAt time.Time   `json:"createdAt"`
+			User      *linearName `json:"user"`
+		} `json:"nodes"`
+	} `json:"comments"`
+}
+
+const linearIssueQuery = `query Issue($id: String!, $comments: Int!) {
+  issue(id: $id) {
+    id identifier title description url priorityLabel createdAt updatedAt
+    state { name } assignee { name } creator { name }
+    labels { nodes { name } }
+    comments(last: $comments) { nodes { body createdAt user { name } } }
+  }
+}`
+
+const linearCommentMutation = `mutation Comment($issueId: String!, $body: String!) {
+  commentCreate(input: { issueId: $issueId, body: $body }) { success }
+}`
+
+// Issue returns the issue of the identifier or URL with its comments, the
+// description and comments in Markdown
+func (o *Linear) Issue(issue string) (ret *Issue, err error) {
+	var resp *linearIssue
+	if resp, err = o.issue(issue); err != nil {
+		return
+	}
+	ret = &Issue{
+		Key:         resp.Identifier,
+		Title:       resp.Title,
+		URL:         resp.URL,
+		Status:      linearNameOf(resp.State),
+		Priority:    resp.PriorityLabel,
+		Assignee:    linearNameOf(resp.Assignee),
+		Reporter:    linearNameOf(resp.Creator),
+		Created:     resp.CreatedAt,
+		Updated:     resp.UpdatedAt,
+		Description: resp.Description,
+	}
+	for _, label := range resp.Labels.Nodes {
+		ret.Labels = append(ret.Labels, label.Name)
+	}
+	for _, comment := range resp.Comments.Nodes {
+		ret.Comments = append(ret.Comments, Comment{Author: linearNameOf(comment.User), Created: comment.CreatedAt, Body: comment.Body})
+	}
+	slices.SortStableFunc(ret.Comments, func(a, b Comment) int { return a.Created.Compare(b.Created) })
+	return
+}
+
+// PostComment posts the Markdown as a comment of the issue
+func (o *Linear) PostComment(issue, markdown string) (err error) {
+	var resp *linearIssue
+	if resp, err = o.issue(issue); err != nil {
+		return
+	}
+	var data struct {
+		CommentCreate struct {
+			Success bool `json:"success"`
+		} `json:"commentCreate"`
+	}
+	if err = o.query(linearCommentMutation, map[string]any{"issueId": resp.ID, "body": markdown}, &data); err != nil {
+		return
+	}
+	if !data.CommentCreate.Success {
+		return fmt.Errorf(i18n.T("issues_error_request"), "Linear", "commentCreate")
+	}
+	return
+}
+
+func (o *Linear) issue(issue string) (ret *linearIssue, err error) {
+	if o.APIKey.Value == "" {
+		return nil, errors.New(i18n.T("linear_not_configured"))
+	}
+	var key string
+	if key, err = issueKey("Linear", issue); err != nil {
+		return
+	}
+	var data struct {
+		Issue *linearIssue `json:"issue"`
+	}
+	if err = o.query(linearIssueQuery, map[string]any{"id": key, "comments": linearComments}, &data); err != nil {
+		return
+	}
+	if data.Issue == nil {
+		return nil, fmt.Errorf(i18n.T("issues_error_not_found"), key, "Linear")
+	}
+	return data.Issue, nil
+}
+
+// query runs the GraphQL query, its errors being returned with a success
+// status
+func (o *Linear) query(query string, variables map[string]any, target any) (err error) {
+	var resp struct {
+		Data   any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	resp.Data = target
+	if err = doJSON("Linear", http.MethodPost, linearURL, o.APIKey.Value, map[string]any{"query": query, "variables": variables}, &resp); err != nil {
+		return
+	}
+	if len(resp.Errors) > 0 {
+		var messages []string
+		for _, e := range resp.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf(i18n.T("issues_error_request"), "Linear", strings.Join(messages, "; "))
+	}
+	return
+}
+
+func linearNameOf(field *linearName) string {
+	if field == nil {
+		return ""
+	}
+	return field.Name
+}
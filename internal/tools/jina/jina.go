@@ -3,14 +3,20 @@ package jina
 // see https://jina.ai for more information
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/danielmiessler/fabric/internal/i18n"
 	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 )
 
+var rerankURL = "https://api.jina.ai/v1/rerank"
+
 type Client struct {
 	*plugins.PluginBase
 	ApiKey *plugins.SetupQuestion
@@ -70,3 +76,63 @@ func (jc *Client) request(requestURL string) (ret string, err error) {
 	ret = string(body)
 	return
 }
+
+type rerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+	Detail string `json:"detail"`
+}
+
+// Rerank orders the documents by relevance to the query with a Jina reranker such
+// as jina-reranker-v2-base-multilingual.
+func (jc *Client) Rerank(ctx context.Context, query string, documents []string, model string, topN int) (ret []ai.RerankResult, err error) {
+	var body []byte
+	if body, err = json.Marshal(rerankRequest{Model: model, Query: query, Documents: documents, TopN: topN}); err != nil {
+		return
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, rerankURL, bytes.NewReader(body)); err != nil {
+		err = fmt.Errorf("%s", fmt.Sprintf(i18n.T("jina_error_creating_request"), err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+jc.ApiKey.Value)
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		err = fmt.Errorf("%s", fmt.Sprintf(i18n.T("jina_error_sending_request"), err))
+		return
+	}
+	defer resp.Body.Close()
+
+	if body, err = io.ReadAll(resp.Body); err != nil {
+		err = fmt.Errorf("%s", fmt.Sprintf(i18n.T("jina_error_reading_response_body"), err))
+		return
+	}
+	var result rerankResponse
+	if resp.StatusCode != http.StatusOK {
+		if json.Unmarshal(body, &result) != nil || result.Detail == "" {
+			result.Detail = string(bytes.TrimSpace(body))
+		}
+		return nil, fmt.Errorf(i18n.T("jina_error_status"), resp.StatusCode, result.Detail)
+	}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf(i18n.T("jina_error_parsing_response"), err)
+	}
+
+	for _, item := range result.Results {
+		ret = append(ret, ai.RerankResult{Index: item.Index, Score: item.RelevanceScore})
+	}
+	ai.SortRerankResults(ret)
+	return
+}
@@ -0,0 +1,52 @@
+package jina
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withRerankServer(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	previous := rerankURL
+	rerankURL = server.URL
+	t.Cleanup(func() { rerankURL = previous })
+}
+
+func TestRerank(t *testing.T) {
+	withRerankServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var req rerankRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "question", req.Query)
+		assert.Equal(t, []string{"a", "b"}, req.Documents)
+
+		_, _ = w.Write([]byte(`{"results":[{"index":0,"relevance_score":0.1},{"index":1,"relevance_score":0.8}]}`))
+	})
+
+	client := NewClient()
+	client.ApiKey.Value = "test-key"
+	results, err := client.Rerank(context.Background(), "question", []string{"a", "b"}, "jina-reranker-v2-base-multilingual", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []ai.RerankResult{{Index: 1, Score: 0.8}, {Index: 0, Score: 0.1}}, results)
+}
+
+func TestRerankError(t *testing.T) {
+	withRerankServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"detail":"Invalid API key"}`))
+	})
+
+	_, err := NewClient().Rerank(context.Background(), "question", []string{"a"}, "jina-reranker-v2-base-multilingual", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid API key")
+}
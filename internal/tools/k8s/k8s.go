@@ -0,0 +1,382 @@
+// Package k8s collects the state of a Kubernetes namespace with kubectl, its
+// warning events, failing pods and their recent logs, as redacted Markdown
+// input for the SRE diagnosis patterns.
+package k8s
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/redact"
+)
+
+// AllNamespaces is the namespace collecting the state of all the namespaces
+const AllNamespaces = "all"
+
+const (
+	// MaxBytes is the default size limit of the collected state
+	MaxBytes = 64 * 1024
+	// maxEvents is the most warning events kept, the newest ones
+	maxEvents = 50
+	// maxLogPods is the most failing pods whose logs are collected
+	maxLogPods = 10
+	// logLines is the number of last log lines of each container
+	logLines = 50
+	// logBytes is the most bytes of the logs of each container
+	logBytes = 8 * 1024
+)
+
+// runKubectl runs kubectl with the arguments and returns its output
+var runKubectl = func(ctx context.Context, args ...string) (ret []byte, err error) {
+	var path string
+	if path, err = exec.LookPath("kubectl"); err != nil {
+		return nil, errors.New(i18n.T("k8s_error_kubectl_not_found"))
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf(i18n.T("k8s_error_kubectl"), strings.Join(args[:min(len(args), 3)], " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+var secretRules = []struct {
+	re      *regexp.Regexp
+	replace string
+}{
+	{regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`), "[REDACTED]"},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), "[REDACTED]"},
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]{8,}`), "$1 [REDACTED]"},
+	{regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`), "://[REDACTED]@"},
+	{regexp.MustCompile(`(?i)\b([\w.-]*(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|credentials?)["']?\s*[:=]\s*["']?)([^\s"',;&}]+)`), "${1}[REDACTED]"},
+}
+
+// Collector collects the state of a namespace of a cluster
+type Collector struct {
+	// Context is the kubeconfig context, the current one when empty
+	Context string
+	// Namespace is the namespace, or AllNamespaces
+	Namespace string
+	// MaxBytes is the size limit of the state, MaxBytes when zero
+	MaxBytes int
+}
+
+// CurrentContext returns the current context of the kubeconfig
+func CurrentContext(ctx context.Context) (ret string, err error) {
+	var out []byte
+	if out, err = runKubectl(ctx, "config", "current-context"); err != nil {
+		return
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Collect returns the state of the namespace as Markdown: its failing pods,
+// its newest warning events and the recent logs of the failing containers,
+// with the secrets and personal data masked
+func (o *Collector) Collect(ctx context.Context) (ret string, err error) {
+	var pods []pod
+	if pods, err = o.failingPods(ctx); err != nil {
+		return
+	}
+	var events []event
+	if events, err = o.warningEvents(ctx); err != nil {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# " + fmt.Sprintf(i18n.T("k8s_heading"), o.Describe()) + "\n")
+	sb.WriteString(fmt.Sprintf("\n- %s: %s\n", i18n.T("k8s_collected"), time.Now().UTC().Format("2006-01-02 15:04 MST")))
+
+	sb.WriteString("\n## " + i18n.T("k8s_failing_pods") + "\n\n")
+	if len(pods) == 0 {
+		sb.WriteString(i18n.T("k8s_none") + "\n")
+	} else {
+		sb.WriteString(i18n.T("k8s_pods_header") + "\n|---|---|---|---|\n")
+		for _, p := range pods {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %d | %s |\n", p.name(), p.Status.Phase, p.restarts(),
+				strings.ReplaceAll(strings.Join(p.problems(), "; "), "|", `\|`)))
+		}
+	}
+
+	sb.WriteString("\n## " + i18n.T("k8s_warning_events") + "\n\n")
+	if len(events) == 0 {
+		sb.WriteString(i18n.T("k8s_none") + "\n")
+	}
+	for _, e := range events {
+		object := e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name
+		if o.Namespace == AllNamespaces && e.InvolvedObject.Namespace != "" {
+			object = e.InvolvedObject.Namespace + "/" + object
+		}
+		count := ""
+		if e.Count > 1 {
+			count = fmt.Sprintf(" (x%d)", e.Count)
+		}
+		sb.WriteString(fmt.Sprintf("- %s %s %s%s: %s\n", e.time().UTC().Format("2006-01-02 15:04:05"), object, e.Reason, count,
+			strings.Join(strings.Fields(e.Message), " ")))
+	}
+
+	limit := cmp.Or(o.MaxBytes, MaxBytes)
+	truncated := false
+	if len(pods) > 0 {
+		sb.WriteString("\n## " + i18n.T("k8s_logs") + "\n")
+	}
+	for i, p := range pods {
+		if i == maxLogPods {
+			truncated = true
+			break
+		}
+		for _, c := range p.logContainers() {
+			if sb.Len() >= limit {
+				truncated = true
+				break
+			}
+			args := []string{"logs", p.Metadata.Name, "-n", p.Metadata.Namespace, "-c", c.Name,
+				"--tail=" + strconv.Itoa(logLines), "--limit-bytes=" + strconv.Itoa(logBytes)}
+			if c.LastState.Terminated != nil {
+				args = append(args, "--previous")
+			}
+			var logs []byte
+			// The logs of a container that never started are missing
+			if logs, err = runKubectl(ctx, o.contextArgs(args)...); err != nil {
+				logs, err = []byte(err.Error()), nil
+			}
+			title := p.name() + " / " + c.Name
+			if c.LastState.Terminated != nil {
+				title += " (" + i18n.T("k8s_previous") + ")"
+			}
+			sb.WriteString("\n### " + title + "\n\n```log\n" + strings.TrimRight(string(logs), "\n") + "\n```\n")
+		}
+	}
+
+	// Masked before the cut, which could leave a part of a private key
+	ret = redact.New().Redact(Redact(sb.String()))
+	if len(ret) > limit {
+		ret, truncated = ret[:strings.LastIndex(ret[:limit], "\n")+1], true
+		// The logs cut in the middle still end their code block
+		if strings.Count(ret, "```")%2 == 1 {
+			ret += "```\n"
+		}
+	}
+	if truncated {
+		ret += "\n" + fmt.Sprintf(i18n.T("k8s_truncated"), limit/1024) + "\n"
+	}
+	return
+}
+
+// Redact masks the secrets of the text: private keys, JWTs, bearer tokens,
+// the credentials of URLs and the values of password, secret, token and API
+// key settings
+func Redact(text string) string {
+	for _, rule := range secretRules {
+		text = rule.re.ReplaceAllString(text, rule.replace)
+	}
+	return text
+}
+
+func (o *Collector) namespaceLabel() string {
+	if o.Namespace == AllNamespaces {
+		return i18n.T("k8s_all_namespaces")
+	}
+	return fmt.Sprintf(i18n.T("k8s_namespace"), o.Namespace)
+}
+
+// Describe returns the namespace and context collected, as for the
+// confirmation
+func (o *Collector) Describe() string {
+	if o.Context == "" {
+		return o.namespaceLabel()
+	}
+	return fmt.Sprintf(i18n.T("k8s_scope"), o.namespaceLabel(), o.Context)
+}
+
+// contextArgs adds the context to the kubectl arguments
+func (o *Collector) contextArgs(args []string) []string {
+	if o.Context != "" {
+		args = append(args, "--context", o.Context)
+	}
+	return args
+}
+
+// listArgs returns the arguments listing the resources of the namespace
+func (o *Collector) listArgs(args ...string) []string {
+	if o.Namespace == AllNamespaces {
+		args = append(args, "--all-namespaces")
+	} else {
+		args = append(args, "-n", o.Namespace)
+	}
+	return o.contextArgs(append(args, "-o", "json"))
+}
+
+func (o *Collector) failingPods(ctx context.Context) (ret []pod, err error) {
+	var out []byte
+	if out, err = runKubectl(ctx, o.listArgs("get", "pods")...); err != nil {
+		return
+	}
+	var list struct {
+		Items []pod `json:"items"`
+	}
+	if err = json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf(i18n.T("k8s_error_output"), "get pods", err)
+	}
+	for _, p := range list.Items {
+		if len(p.problems()) > 0 {
+			ret = append(ret, p)
+		}
+	}
+	// The pods restarting the most come first
+	slices.SortStableFunc(ret, func(a, b pod) int { return b.restarts() - a.restarts() })
+	return
+}
+
+func (o *Collector) warningEvents(ctx context.Context) (ret []event, err error) {
+	var out []byte
+	if out, err = runKubectl(ctx, o.listArgs("get", "events", "--field-selector", "type=Warning")...); err != nil {
+		return
+	}
+	var list struct {
+		Items []event `json:"items"`
+	}
+	if err = json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf(i18n.T("k8s_error_output"), "get events", err)
+	}
+	ret = list.Items
+	slices.SortStableFunc(ret, func(a, b event) int { return b.time().Compare(a.time()) })
+	return ret[:min(len(ret), maxEvents)], nil
+}
+
+type pod struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Status struct {
+		Phase                 string            `json:"phase"`
+		Reason                string            `json:"reason"`
+		Message               string            `json:"message"`
+		InitContainerStatuses []containerStatus `json:"initContainerStatuses"`
+		ContainerStatuses     []containerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type containerStatus struct {
+	Name         string         `json:"name"`
+	Ready        bool           `json:"ready"`
+	RestartCount int            `json:"restartCount"`
+	State        containerState `json:"state"`
+	LastState    containerState `json:"lastState"`
+}
+
+type containerState struct {
+	Waiting *struct {
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	} `json:"waiting"`
+	Running    *struct{} `json:"running"`
+	Terminated *struct {
+		Reason   string `json:"reason"`
+		ExitCode int    `json:"exitCode"`
+	} `json:"terminated"`
+}
+
+func (o *pod) name() string {
+	return o.Metadata.Namespace + "/" + o.Metadata.Name
+}
+
+func (o *pod) restarts() (ret int) {
+	for _, c := range o.Status.ContainerStatuses {
+		ret += c.RestartCount
+	}
+	return
+}
+
+// problems returns what is wrong with the pod, nothing when it is healthy
+// or completed
+func (o *pod) problems() (ret []string) {
+	if o.Status.Phase == "Succeeded" {
+		return
+	}
+	if o.Status.Phase != "Running" {
+		ret = append(ret, strings.TrimSpace(o.Status.Phase+" "+o.Status.Reason+" "+o.Status.Message))
+	}
+	for _, c := range o.Status.InitContainerStatuses {
+		ret = append(ret, c.problems(true)...)
+	}
+	for _, c := range o.Status.ContainerStatuses {
+		ret = append(ret, c.problems(false)...)
+	}
+	return
+}
+
+// logContainers returns the containers whose logs explain the problems
+func (o *pod) logContainers() (ret []containerStatus) {
+	for _, c := range append(slices.Clone(o.Status.InitContainerStatuses), o.Status.ContainerStatuses...) {
+		if c.State.Running != nil && c.RestartCount == 0 && c.Ready {
+			continue
+		}
+		if c.State.Waiting != nil && c.LastState.Terminated == nil {
+			// Never started, without logs
+			continue
+		}
+		ret = append(ret, c)
+	}
+	return
+}
+
+func (o *containerStatus) problems(init bool) (ret []string) {
+	prefix := o.Name + ": "
+	if waiting := o.State.Waiting; waiting != nil && waiting.Reason != "" && !(init && waiting.Reason == "PodInitializing") {
+		ret = append(ret, strings.TrimSpace(prefix+waiting.Reason+" "+waiting.Message))
+	}
+	if terminated := o.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+		ret = append(ret, fmt.Sprintf("%s%s (exit %d)", prefix, terminated.Reason, terminated.ExitCode))
+	}
+	if !init && o.State.Running != nil && !o.Ready {
+		ret = append(ret, prefix+"not ready")
+	}
+	if o.RestartCount > 0 {
+		restarts := fmt.Sprintf("%s%d restarts", prefix, o.RestartCount)
+		if last := o.LastState.Terminated; last != nil {
+			restarts += fmt.Sprintf(", last %s (exit %d)", last.Reason, last.ExitCode)
+		}
+		ret = append(ret, restarts)
+	}
+	return
+}
+
+type event struct {
+	Metadata struct {
+		CreationTimestamp time.Time `json:"creationTimestamp"`
+	} `json:"metadata"`
+	InvolvedObject struct {
+		Kind      string `json:"kind"`
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"involvedObject"`
+	Reason        string     `json:"reason"`
+	Message       string     `json:"message"`
+	Count         int        `json:"count"`
+	LastTimestamp *time.Time `json:"lastTimestamp"`
+	EventTime     *time.Time `json:"eventTime"`
+}
+
+func (o *event) time() time.Time {
+	if o.LastTimestamp != nil && !o.LastTimestamp.IsZero() {
+		return *o.LastTimestamp
+	}
+	if o.EventTime != nil && !o.EventTime.IsZero() {
+		return *o.EventTime
+	}
+	return o.Metadata.CreationTimestamp
+}
@@ -0,0 +1,440 @@
+// Package logs condenses log files before they are sent to the patterns:
+// the timestamps are normalized to UTC, the entries filtered by time, the
+// repeated messages counted once and the rest sampled to a size limit,
+// errors and warnings first.
+package logs
+
+import (
+	"bufio"
+	"cmp"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	// MaxBytes is the default size limit of the summary
+	MaxBytes = 64 * 1024
+	// maxGroups is the most distinct messages kept, the later ones being
+	// only counted
+	maxGroups = 10000
+	// maxEntryLines is the most lines kept of an entry, as a stack trace
+	maxEntryLines = 20
+	// maxLineBytes is the most bytes kept of a line
+	maxLineBytes = 2000
+	// timeLayout is the layout of the normalized timestamps
+	timeLayout = "2006-01-02T15:04:05Z"
+)
+
+// timestamp is a timestamp format found in the log lines
+type timestamp struct {
+	re    *regexp.Regexp
+	parse func(match string, options *Options) (time.Time, bool)
+}
+
+var timestamps = []timestamp{
+	// ISO 8601, as in most structured logs
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`), func(match string, options *Options) (time.Time, bool) {
+		match = strings.Replace(strings.Replace(match, " ", "T", 1), ",", ".", 1)
+		for _, layout := range []string{"2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999Z0700"} {
+			if t, err := time.Parse(layout, match); err == nil {
+				return t, true
+			}
+		}
+		t, err := time.ParseInLocation("2006-01-02T15:04:05.999999999", match, options.location())
+		return t, err == nil
+	}},
+	// Common Log Format, as in the access logs of Apache and nginx
+	{regexp.MustCompile(`\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`), func(match string, _ *Options) (time.Time, bool) {
+		t, err := time.Parse("02/Jan/2006:15:04:05 -0700", match)
+		return t, err == nil
+	}},
+	// The standard log package of Go
+	{regexp.MustCompile(`\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?`), func(match string, options *Options) (time.Time, bool) {
+		t, err := time.ParseInLocation("2006/01/02 15:04:05.999999999", match, options.location())
+		return t, err == nil
+	}},
+	// syslog, without year
+	{regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}`), func(match string, options *Options) (time.Time, bool) {
+		t, err := time.ParseInLocation("Jan _2 15:04:05", match, options.location())
+		if err != nil {
+			return t, false
+		}
+		now := options.now()
+		t = t.AddDate(now.Year(), 0, 0)
+		// The entries of December read in January are of the last year
+		if t.After(now.Add(24 * time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return t, true
+	}},
+	// Unix times of the JSON logs, in seconds or milliseconds
+	{regexp.MustCompile(`"(?:ts|time|timestamp)"\s*:\s*(\d{10}(?:\.\d+)?|\d{13})\b`), func(match string, _ *Options) (time.Time, bool) {
+		value := match[strings.LastIndexAny(match, ": ")+1:]
+		if len(value) == 13 {
+			millis, err := strconv.ParseInt(value, 10, 64)
+			return time.UnixMilli(millis), err == nil
+		}
+		seconds, err := strconv.ParseFloat(value, 64)
+		return time.Unix(0, int64(seconds*float64(time.Second))), err == nil
+	}},
+}
+
+var (
+	levelRegex = regexp.MustCompile(`(?i)\b(fatal|panic|crit(?:ical)?|emerg|alert|err(?:or)?|warn(?:ing)?|info|notice|debug|trace)\b`)
+	// variableRegexes mask the parts of the messages that vary between
+	// repeats, so that they are counted once
+	variableRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+		regexp.MustCompile(`(?i)\b(?:0x)?[0-9a-f]*\d[0-9a-f]*[a-f][0-9a-f]*\b|\b(?:0x)?[0-9a-f]*[a-f][0-9a-f]*\d[0-9a-f]*\b`),
+		regexp.MustCompile(`\d+(?:\.\d+)*`),
+	}
+)
+
+// levels are the levels counted, most severe first
+var levels = []string{"ERROR", "WARN", "INFO", "DEBUG"}
+
+// Options are the options of the summaries
+type Options struct {
+	// Since drops the entries before it, none when zero
+	Since time.Time
+	// MaxBytes is the size limit of the summary, MaxBytes when zero
+	MaxBytes int
+	// Location is the time zone of the timestamps without one, the local
+	// one when nil
+	Location *time.Location
+	// Now is the time of the summary, the current one when zero
+	Now time.Time
+}
+
+func (o *Options) location() *time.Location {
+	if o.Location == nil {
+		return time.Local
+	}
+	return o.Location
+}
+
+func (o *Options) now() time.Time {
+	if o.Now.IsZero() {
+		return time.Now()
+	}
+	return o.Now
+}
+
+// ParseSince parses the time of --since: a duration like 90m, 1h or 7d, a
+// date like 2026-10-01 or a time like 2026-10-01T09:00:00Z
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n > 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{time.DateOnly, "2006-01-02T15:04:05", time.DateTime, "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(i18n.T("logs_error_invalid_since"), value)
+}
+
+// ReadFile returns the summary of the log file, read through gzip when it
+// ends with .gz
+func ReadFile(path string, options Options) (ret string, err error) {
+	var file *os.File
+	if file, err = os.Open(path); err != nil {
+		return
+	}
+	defer file.Close()
+	var reader io.Reader = file
+	if strings.EqualFold(filepath.Ext(path), ".gz") {
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(file); err != nil {
+			return
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	summary := NewSummary(options)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		summary.Add(scanner.Text())
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	return summary.Markdown(fmt.Sprintf(i18n.T("logs_heading"), filepath.Base(path))), nil
+}
+
+// entry is a log entry: a line with a timestamp and the lines following it
+// without one, as a stack trace
+type entry struct {
+	time  time.Time
+	level string
+	lines []string
+}
+
+// group is a message repeated in the entries
+type group struct {
+	first entry
+	last  time.Time
+	count int
+}
+
+// Summary condenses the lines added to it
+type Summary struct {
+	options Options
+	current *entry
+	groups  []*group
+	byKey   map[string]*group
+	levels  map[string]int
+	lines   int
+	entries int
+	dropped int
+	timed   bool
+	first   time.Time
+	last    time.Time
+}
+
+// NewSummary returns an empty summary
+func NewSummary(options Options) *Summary {
+	return &Summary{options: options, byKey: map[string]*group{}, levels: map[string]int{}}
+}
+
+// Add adds a line of the log
+func (o *Summary) Add(line string) {
+	o.lines++
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(line) > maxLineBytes {
+		line = strings.ToValidUTF8(line[:maxLineBytes], "") + "…"
+	}
+	normalized, t, ok := o.normalize(line)
+	if !ok && o.timed {
+		// The lines without timestamp continue the entry, as a stack trace
+		if o.current != nil && len(o.current.lines) < maxEntryLines {
+			o.current.lines = append(o.current.lines, line)
+		}
+		return
+	}
+	o.flush()
+	if ok {
+		o.timed = true
+		if !o.options.Since.IsZero() && t.Before(o.options.Since) {
+			return
+		}
+	}
+	o.current = &entry{time: t, level: level(normalized), lines: []string{normalized}}
+}
+
+// normalize returns the line with its timestamp in UTC, and the timestamp
+func (o *Summary) normalize(line string) (ret string, t time.Time, ok bool) {
+	head := line[:min(len(line), 200)]
+	for _, format := range timestamps {
+		loc := format.re.FindStringIndex(head)
+		if loc == nil {
+			continue
+		}
+		match := line[loc[0]:loc[1]]
+		if t, ok = format.parse(match, &o.options); !ok {
+			continue
+		}
+		t = t.UTC()
+		replacement := t.Format(timeLayout)
+		if strings.HasPrefix(match, `"`) {
+			// A Unix time of JSON becomes a string
+			key, _, _ := strings.Cut(match, ":")
+			replacement = key + `:"` + replacement + `"`
+		}
+		return line[:loc[0]] + replacement + line[loc[1]:], t, true
+	}
+	return line, t, false
+}
+
+// flush adds the current entry to its group
+func (o *Summary) flush() {
+	current := o.current
+	o.current = nil
+	if current == nil {
+		return
+	}
+	o.entries++
+	o.levels[current.level]++
+	if !current.time.IsZero() {
+		if o.first.IsZero() || current.time.Before(o.first) {
+			o.first = current.time
+		}
+		if current.time.After(o.last) {
+			o.last = current.time
+		}
+	}
+	key := messageKey(current.lines[0])
+	if g := o.byKey[key]; g != nil {
+		g.count++
+		if current.time.After(g.last) {
+			g.last = current.time
+		}
+		return
+	}
+	if len(o.groups) == maxGroups {
+		o.dropped++
+		return
+	}
+	g := &group{first: *current, last: current.time, count: 1}
+	o.groups = append(o.groups, g)
+	o.byKey[key] = g
+}
+
+// Markdown returns the summary as Markdown: the period, counts and levels
+// of the entries, then the distinct messages in order, sampled to the size
+// limit with the errors and warnings first
+func (o *Summary) Markdown(title string) string {
+	o.flush()
+	var sb strings.Builder
+	sb.WriteString("# " + title + "\n\n")
+	if !o.first.IsZero() {
+		fmt.Fprintf(&sb, "- %s: %s – %s\n", i18n.T("logs_period"), o.first.Format(timeLayout), o.last.Format(timeLayout))
+	}
+	if !o.options.Since.IsZero() {
+		fmt.Fprintf(&sb, "- %s: %s\n", i18n.T("logs_since"), o.options.Since.UTC().Format(timeLayout))
+	}
+	fmt.Fprintf(&sb, "- %s\n", fmt.Sprintf(i18n.T("logs_counts"), o.lines, o.entries, len(o.groups)+o.dropped))
+	var counts []string
+	for _, name := range levels {
+		if o.levels[name] > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d", name, o.levels[name]))
+		}
+	}
+	if len(counts) > 0 {
+		fmt.Fprintf(&sb, "- %s: %s\n", i18n.T("logs_levels"), strings.Join(counts, ", "))
+	}
+	if !o.timed && o.lines > 0 {
+		sb.WriteString("- " + i18n.T("logs_no_timestamps") + "\n")
+	}
+
+	texts := make([]string, len(o.groups))
+	for i, g := range o.groups {
+		texts[i] = g.text()
+	}
+	selected := sample(o.groups, texts, max(cmp.Or(o.options.MaxBytes, MaxBytes)-sb.Len()-200, 0))
+	if n := len(selected); n < len(o.groups)+o.dropped {
+		fmt.Fprintf(&sb, "- %s\n", fmt.Sprintf(i18n.T("logs_sampled"), n, len(o.groups)+o.dropped))
+	}
+	sb.WriteString("\n## " + i18n.T("logs_entries") + "\n\n")
+	if len(selected) == 0 {
+		sb.WriteString(i18n.T("logs_none") + "\n")
+		return sb.String()
+	}
+	sb.WriteString("```log\n")
+	for _, i := range selected {
+		sb.WriteString(texts[i] + "\n")
+	}
+	sb.WriteString("```\n")
+	return sb.String()
+}
+
+// text returns the first entry of the group, with the count and time of
+// the last one when repeated
+func (o *group) text() string {
+	lines := slices.Clone(o.first.lines)
+	if o.count > 1 {
+		repeat := fmt.Sprintf(" [x%d", o.count)
+		if !o.last.IsZero() && o.last.After(o.first.time) {
+			repeat += ", " + i18n.T("logs_last") + " " + o.last.Format(timeLayout)
+		}
+		lines[0] += repeat + "]"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sample returns the indexes of the groups fitting in the size, in order:
+// all of them when they fit, otherwise the most severe levels first, the
+// last level fitting in part being sampled evenly
+func sample(groups []*group, texts []string, size int) (ret []int) {
+	total := 0
+	for _, text := range texts {
+		total += len(text) + 1
+	}
+	if total <= size {
+		for i := range groups {
+			ret = append(ret, i)
+		}
+		return
+	}
+	for _, name := range append(levels, "") {
+		var indexes []int
+		bytes := 0
+		for i, g := range groups {
+			if g.first.level == name {
+				indexes = append(indexes, i)
+				bytes += len(texts[i]) + 1
+			}
+		}
+		if bytes <= size {
+			ret, size = append(ret, indexes...), size-bytes
+			continue
+		}
+		// Every few of them, spread over the period
+		n := len(indexes) * size / max(bytes, 1)
+		for k := 0; k < n; k++ {
+			i := indexes[k*len(indexes)/n]
+			if len(texts[i])+1 > size {
+				continue
+			}
+			ret, size = append(ret, i), size-len(texts[i])-1
+		}
+		break
+	}
+	slices.Sort(ret)
+	return
+}
+
+// level returns the level of the line, one of levels or empty
+func level(line string) string {
+	match := levelRegex.FindString(line)
+	switch strings.ToLower(match) {
+	case "fatal", "panic", "crit", "critical", "emerg", "alert", "err", "error":
+		return "ERROR"
+	case "warn", "warning":
+		return "WARN"
+	case "info", "notice":
+		return "INFO"
+	case "debug", "trace":
+		return "DEBUG"
+	}
+	return ""
+}
+
+// messageKey returns the line without its timestamp and what varies
+// between the repeats of a message
+func messageKey(line string) string {
+	// The timestamps are normalized to ISO 8601
+	line = timestamps[0].re.ReplaceAllString(line, "")
+	for _, re := range variableRegexes {
+		line = re.ReplaceAllString(line, "#")
+	}
+	return line
+}
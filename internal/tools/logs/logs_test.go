@@ -0,0 +1,149 @@
+package logs
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+
+func summarize(lines string, options Options) string {
+	options.Location, options.Now = time.UTC, now
+	summary := NewSummary(options)
+	for _, line := range strings.Split(lines, "\n") {
+		summary.Add(line)
+	}
+	return summary.Markdown("Logs of app.log")
+}
+
+func TestSummary(t *testing.T) {
+	lines := "2026-10-17 08:00:00 INFO starting\n" +
+		"2026-10-17T11:00:01.250+02:00 ERROR db timeout after 30s on 10.0.0.12\n" +
+		"2026-10-17T09:10:00Z INFO request 4f1c2a9e-51d2-4c83-9e57-4f2c5a0b7a10 took 12ms\n" +
+		"2026-10-17T09:10:05Z INFO request 0b8d5c7e-1234-4c83-9e57-4f2c5a0b7a11 took 250ms\n" +
+		"Oct 17 09:20:00 web1 app[123]: warning: disk at 91%\n" +
+		"2026/10/17 09:30:00 panic: nil map\ngoroutine 1 [running]:\nmain.main()\n" +
+		"10.1.2.3 - - [17/Oct/2026:11:40:00 +0200] \"GET /health HTTP/1.1\" 200 2\n" +
+		`{"level":"error","ts":1792230000,"msg":"db timeout after 31s on 10.0.0.13"}` + "\n" +
+		"2026-10-17T09:50:00Z ERROR db timeout after 29s on 10.0.0.12"
+	got := summarize(lines, Options{Since: now.Add(-90 * time.Minute)})
+	for _, want := range []string{
+		"# Logs of app.log\n\n- Period: 2026-10-17T09:00:01Z – 2026-10-17T09:50:00Z\n- Since: 2026-10-17T08:30:00Z\n",
+		"- Lines: 11, entries: 8, distinct messages: 6\n- Levels: ERROR 4, WARN 1, INFO 2\n",
+		"2026-10-17T09:00:01Z ERROR db timeout after 30s on 10.0.0.12 [x2, last 2026-10-17T09:50:00Z]",
+		"2026-10-17T09:10:00Z INFO request 4f1c2a9e-51d2-4c83-9e57-4f2c5a0b7a10 took 12ms [x2, last 2026-10-17T09:10:05Z]",
+		"2026-10-17T09:20:00Z web1 app[123]: warning: disk at 91%",
+		"2026-10-17T09:30:00Z panic: nil map\ngoroutine 1 [running]:\nmain.main()\n",
+		`10.1.2.3 - - [2026-10-17T09:40:00Z] "GET /health`,
+		`{"level":"error","ts":"2026-10-17T09:40:00Z","msg":"db timeout after 31s on 10.0.0.13"}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown() = %s\nwant %q", got, want)
+		}
+	}
+	if strings.Contains(got, "starting") {
+		t.Errorf("Markdown() = %s, should skip the entries before --since", got)
+	}
+
+	// Without timestamps, each line is an entry
+	if got = summarize("a 1\na 2\nb", Options{}); !strings.Contains(got, "```log\na 1 [x2]\nb\n```") ||
+		!strings.Contains(got, "No timestamps") {
+		t.Errorf("Markdown() without timestamps = %s", got)
+	}
+}
+
+func TestSummarySampled(t *testing.T) {
+	var sb strings.Builder
+	for i := range 200 {
+		level := "INFO"
+		if i%50 == 0 {
+			level = "ERROR"
+		}
+		fmt.Fprintf(&sb, "2026-10-17T09:%02d:%02dZ %s message %c%c\n", i/60, i%60, level, 'a'+i%26, 'a'+i/26)
+	}
+	got := summarize(sb.String(), Options{MaxBytes: 2000})
+	if len(got) > 2000 || !strings.Contains(got, "of 200 distinct messages") || strings.Count(got, "ERROR message") != 4 {
+		t.Errorf("Markdown() over the limit = %s", got)
+	}
+	// The sampled messages stay in order
+	if first, last := strings.Index(got, "ERROR message aa"), strings.Index(got, "ERROR message yb"); first < 0 || last < first {
+		t.Errorf("Markdown() samples out of order = %s", got)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	for value, want := range map[string]time.Time{
+		"1h":                   now.Add(-time.Hour),
+		"1h30m":                now.Add(-90 * time.Minute),
+		"2d":                   now.Add(-48 * time.Hour),
+		"2026-10-01":           time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		"2026-10-01T09:00:00Z": time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		"":                     {},
+	} {
+		if got, err := ParseSince(value, now); err != nil || !got.Equal(want) {
+			t.Errorf("ParseSince(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseSince("yesterday", now); err == nil {
+		t.Errorf("ParseSince() of an invalid value expected an error")
+	}
+}
+
+func TestReadFileGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	fmt.Fprint(gz, "2026-10-17T09:00:00Z ERROR boom\n")
+	_ = gz.Close()
+	_ = file.Close()
+
+	got, err := ReadFile(path, Options{})
+	if err != nil || !strings.Contains(got, "# Logs of app.log.gz") || !strings.Contains(got, "ERROR boom") {
+		t.Errorf("ReadFile() = %q, %v", got, err)
+	}
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tail, err := NewTail(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendLog := func(text string) {
+		file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		fmt.Fprint(file, text)
+		_ = file.Close()
+	}
+
+	// The partial last line waits for its end
+	appendLog("first\nsec")
+	if lines, err := tail.Lines(); err != nil || strings.Join(lines, "|") != "first" {
+		t.Errorf("Lines() = %q, %v, want [first]", lines, err)
+	}
+	appendLog("ond\n")
+	if lines, _ := tail.Lines(); strings.Join(lines, "|") != "second" {
+		t.Errorf("Lines() = %q, want [second]", lines)
+	}
+	if lines, _ := tail.Lines(); len(lines) != 0 {
+		t.Errorf("Lines() without new lines = %q", lines)
+	}
+
+	// A rotation starts over
+	if err = os.WriteFile(path, []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if lines, _ := tail.Lines(); strings.Join(lines, "|") != "new" {
+		t.Errorf("Lines() after truncation = %q, want [new]", lines)
+	}
+}
@@ -0,0 +1,68 @@
+package logs
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// maxTailBytes is the most bytes read at once by a tail, the rest being
+// read the next time
+const maxTailBytes = 16 * 1024 * 1024
+
+// Tail reads the lines appended to a log file, starting over when the file
+// is truncated or replaced by its rotation
+type Tail struct {
+	path    string
+	info    os.FileInfo
+	offset  int64
+	partial string
+}
+
+// NewTail returns the tail of the file, from its end
+func NewTail(path string) (ret *Tail, err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(path); err != nil {
+		return
+	}
+	return &Tail{path: path, info: info, offset: info.Size()}, nil
+}
+
+// Lines returns the complete lines appended since the last call
+func (o *Tail) Lines() (ret []string, err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(o.path); err != nil {
+		// The file is missing for a moment while rotated
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return
+	}
+	if !os.SameFile(info, o.info) || info.Size() < o.offset {
+		o.offset, o.partial = 0, ""
+	}
+	o.info = info
+	if info.Size() == o.offset {
+		return
+	}
+
+	var file *os.File
+	if file, err = os.Open(o.path); err != nil {
+		return
+	}
+	defer file.Close()
+	data := make([]byte, min(info.Size()-o.offset, maxTailBytes))
+	var n int
+	if n, err = file.ReadAt(data, o.offset); err != nil && err != io.EOF {
+		return
+	}
+	o.offset += int64(n)
+	text := o.partial + string(data[:n])
+	// The last line is complete once it ends
+	end := strings.LastIndexByte(text, '\n')
+	o.partial = text[end+1:]
+	if end < 0 {
+		return nil, nil
+	}
+	return strings.Split(text[:end], "\n"), nil
+}
@@ -0,0 +1,129 @@
+package mailbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+)
+
+// Session is a connection to the IMAP server, with a folder selected
+type Session struct {
+	client *client.Client
+}
+
+// Open logs in on the IMAP server and selects the folder, with implicit TLS
+// but on port 143 where the connection is upgraded with STARTTLS.
+func (m *Mailbox) Open(ctx context.Context, folder string) (ret *Session, err error) {
+	if !m.IsConfigured() {
+		return nil, errors.New(i18n.T("email_not_configured"))
+	}
+	var host, port string
+	if host, port, err = net.SplitHostPort(m.IMAPServer.Value); err != nil {
+		return
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if port == "143" {
+		conn, err = dialer.DialContext(ctx, "tcp", m.IMAPServer.Value)
+	} else {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: m.tlsFor(host)}).DialContext(ctx, "tcp", m.IMAPServer.Value)
+	}
+	if err != nil {
+		return
+	}
+	var c *client.Client
+	if c, err = client.New(conn); err != nil {
+		conn.Close()
+		return
+	}
+	c.Timeout = timeout
+	defer func() {
+		if err != nil {
+			_ = c.Logout()
+			ret = nil
+		}
+	}()
+
+	if port == "143" {
+		if err = c.StartTLS(m.tlsFor(host)); err != nil {
+			return
+		}
+	}
+	if err = c.Login(m.Username.Value, m.Password.Value); err != nil {
+		return
+	}
+	if _, err = c.Select(folder, false); err != nil {
+		return
+	}
+	return &Session{client: c}, nil
+}
+
+// Unseen returns the UIDs of the mails not read yet
+func (s *Session) Unseen() ([]uint32, error) {
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+	return s.client.UidSearch(criteria)
+}
+
+// Fetch returns the mail without marking it as read, only its header when
+// headerOnly is set.
+func (s *Session) Fetch(uid uint32, headerOnly bool) (ret []byte, err error) {
+	section := &imap.BodySectionName{Peek: true}
+	if headerOnly {
+		section.Specifier = imap.HeaderSpecifier
+	}
+	set := new(imap.SeqSet)
+	set.AddNum(uid)
+
+	messages := make(chan *imap.Message, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.client.UidFetch(set, []imap.FetchItem{section.FetchItem()}, messages)
+	}()
+	for msg := range messages {
+		if body := msg.GetBody(section); body != nil && ret == nil {
+			if ret, err = io.ReadAll(body); err != nil {
+				// The fetch is drained before failing
+				ret = nil
+			}
+		}
+	}
+	if fetchErr := <-done; fetchErr != nil {
+		return nil, fetchErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ret == nil {
+		return nil, fmt.Errorf(i18n.T("imap_command_failed"), "FETCH", strconv.FormatUint(uint64(uid), 10))
+	}
+	return
+}
+
+// MarkSeen marks the mail as read
+func (s *Session) MarkSeen(uid uint32) error {
+	set := new(imap.SeqSet)
+	set.AddNum(uid)
+	return s.client.UidStore(set, imap.FormatFlagsOp(imap.AddFlags, true), []any{imap.SeenFlag}, nil)
+}
+
+// AppendDraft saves the mail in the folder as a draft
+func (s *Session) AppendDraft(folder string, mail []byte) error {
+	return s.client.Append(folder, []string{imap.DraftFlag}, time.Now(), bytes.NewBuffer(mail))
+}
+
+// Close logs out and closes the connection
+func (s *Session) Close() error {
+	return s.client.Logout()
+}
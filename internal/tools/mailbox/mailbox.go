@@ -0,0 +1,136 @@
+// Package mailbox reads the mails of an IMAP mailbox and sends mails over
+// SMTP, with the account configured in the setup.
+package mailbox
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+// timeout bounds the connections and each command sent on them
+const timeout = time.Minute
+
+// NewMailbox creates the plugin holding the email account
+func NewMailbox() *Mailbox {
+	label := "Email"
+
+	ret := &Mailbox{}
+	ret.PluginBase = &plugins.PluginBase{
+		Name:             i18n.T("email_label"),
+		SetupDescription: i18n.T("email_setup_description") + " " + i18n.T("optional_marker"),
+		EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+	}
+
+	ret.IMAPServer = ret.AddSetupQuestionWithEnvName("IMAP Server", false, i18n.T("email_imap_server_question"))
+	ret.SMTPServer = ret.AddSetupQuestionWithEnvName("SMTP Server", false, i18n.T("email_smtp_server_question"))
+	ret.Username = ret.AddSetupQuestionWithEnvName("Username", false, i18n.T("email_username_question"))
+	ret.Password = ret.AddSetupQuestionWithEnvName("Password", false, i18n.T("email_password_question"))
+	ret.Address = ret.AddSetupQuestionWithEnvName("Address", false, i18n.T("email_address_question"))
+
+	return ret
+}
+
+// Mailbox is the email account, its password being kept in the .env file
+// with the other credentials
+type Mailbox struct {
+	*plugins.PluginBase
+	IMAPServer *plugins.SetupQuestion
+	SMTPServer *plugins.SetupQuestion
+	Username   *plugins.SetupQuestion
+	Password   *plugins.SetupQuestion
+	Address    *plugins.SetupQuestion
+
+	// tlsConfig, when set, replaces the default TLS configuration
+	tlsConfig *tls.Config
+}
+
+// IsConfigured reports whether the mailbox can be read
+func (m *Mailbox) IsConfigured() bool {
+	return m.IMAPServer.Value != "" && m.Username.Value != "" && m.Password.Value != ""
+}
+
+// From is the address the mails are sent from, the username by default
+func (m *Mailbox) From() string {
+	if m.Address.Value != "" {
+		return m.Address.Value
+	}
+	return m.Username.Value
+}
+
+// Send sends the mail to the recipients over SMTP, with implicit TLS on port
+// 465 and STARTTLS elsewhere when the server offers it.
+func (m *Mailbox) Send(to []string, mail []byte) (err error) {
+	if m.SMTPServer.Value == "" {
+		return errors.New(i18n.T("email_smtp_not_configured"))
+	}
+	var host, port string
+	if host, port, err = net.SplitHostPort(m.SMTPServer.Value); err != nil {
+		return
+	}
+
+	var conn net.Conn
+	if port == "465" {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", m.SMTPServer.Value, m.tlsFor(host))
+	} else {
+		conn, err = net.DialTimeout("tcp", m.SMTPServer.Value, timeout)
+	}
+	if err != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	var client *smtp.Client
+	if client, err = smtp.NewClient(conn, host); err != nil {
+		conn.Close()
+		return
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && port != "465" {
+		if err = client.StartTLS(m.tlsFor(host)); err != nil {
+			return
+		}
+	}
+	if m.Username.Value != "" {
+		// PlainAuth refuses to send the password unencrypted, but to localhost
+		if err = client.Auth(smtp.PlainAuth("", m.Username.Value, m.Password.Value, host)); err != nil {
+			return
+		}
+	}
+	if err = client.Mail(m.From()); err != nil {
+		return
+	}
+	for _, recipient := range to {
+		if err = client.Rcpt(recipient); err != nil {
+			return
+		}
+	}
+	var writer io.WriteCloser
+	if writer, err = client.Data(); err != nil {
+		return
+	}
+	if _, err = writer.Write(mail); err != nil {
+		return
+	}
+	if err = writer.Close(); err != nil {
+		return
+	}
+	return client.Quit()
+}
+
+// tlsFor returns the TLS configuration to connect to the host
+func (m *Mailbox) tlsFor(host string) *tls.Config {
+	if m.tlsConfig != nil {
+		config := m.tlsConfig.Clone()
+		config.ServerName = host
+		return config
+	}
+	return &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+}
@@ -0,0 +1,208 @@
+package mailbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/memory"
+	imapserver "github.com/emersion/go-imap/server"
+)
+
+const testMail = "From: news@example.com\r\nSubject: Weekly\r\n\r\nHello\r\n"
+
+// fakeIMAP serves the mailbox of the user "username" with the password
+// "password", its INBOX holding a mail already read and testMail, unread
+type fakeIMAP struct {
+	listener net.Listener
+	roots    *x509.CertPool
+	user     backend.User
+}
+
+func newFakeIMAP(t *testing.T) *fakeIMAP {
+	// The certificate of the test server is valid for 127.0.0.1
+	server := httptest.NewTLSServer(nil)
+	t.Cleanup(server.Close)
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: server.TLS.Certificates})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	be := memory.New()
+	user, err := be.Login(nil, "username", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbox, err := user.GetMailbox("INBOX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = inbox.CreateMessage(nil, time.Now(), bytes.NewBufferString(testMail)); err != nil {
+		t.Fatal(err)
+	}
+	if err = user.CreateMailbox("Drafts"); err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = imapserver.New(be).Serve(listener) }()
+	return &fakeIMAP{listener: listener, roots: roots, user: user}
+}
+
+func (f *fakeIMAP) mailbox(password string) *Mailbox {
+	m := NewMailbox()
+	m.IMAPServer.Value = f.listener.Addr().String()
+	m.Username.Value = "username"
+	m.Password.Value = password
+	m.tlsConfig = &tls.Config{RootCAs: f.roots}
+	return m
+}
+
+// flags returns the flags of the mails of the folder
+func (f *fakeIMAP) flags(t *testing.T, folder string) (ret [][]string) {
+	mailbox, err := f.user.GetMailbox(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := new(imap.SeqSet)
+	all.AddRange(1, 0)
+	messages := make(chan *imap.Message, 10)
+	if err = mailbox.ListMessages(true, all, []imap.FetchItem{imap.FetchFlags}, messages); err != nil {
+		t.Fatal(err)
+	}
+	for msg := range messages {
+		ret = append(ret, msg.Flags)
+	}
+	return
+}
+
+func TestSession(t *testing.T) {
+	server := newFakeIMAP(t)
+	session, err := server.mailbox("password").Open(context.Background(), "INBOX")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	uids, err := session.Unseen()
+	if err != nil || len(uids) != 1 || uids[0] != 7 {
+		t.Fatalf("Unseen() = %v, %v, want [7]", uids, err)
+	}
+	mail, err := session.Fetch(7, false)
+	if err != nil || string(mail) != testMail {
+		t.Fatalf("Fetch() = %q, %v", mail, err)
+	}
+	header, err := session.Fetch(7, true)
+	if err != nil || !strings.HasPrefix(string(header), "From: news@example.com") || strings.Contains(string(header), "Hello") {
+		t.Fatalf("Fetch(headerOnly) = %q, %v", header, err)
+	}
+	if flags := server.flags(t, "INBOX"); slices.Contains(flags[1], imap.SeenFlag) {
+		t.Errorf("fetched mail flags = %v, want it still unread", flags[1])
+	}
+	if err = session.AppendDraft("Drafts", []byte("Subject: Re: Weekly\r\n\r\nSummary")); err != nil {
+		t.Fatalf("AppendDraft() error = %v", err)
+	}
+	if err = session.MarkSeen(7); err != nil {
+		t.Fatalf("MarkSeen() error = %v", err)
+	}
+	if err = session.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if flags := server.flags(t, "INBOX"); !slices.Contains(flags[1], imap.SeenFlag) {
+		t.Errorf("marked mail flags = %v, want it read", flags[1])
+	}
+	if flags := server.flags(t, "Drafts"); len(flags) != 1 || !slices.Contains(flags[0], imap.DraftFlag) {
+		t.Errorf("drafts flags = %v, want one draft", flags)
+	}
+}
+
+func TestOpen_LoginRefused(t *testing.T) {
+	server := newFakeIMAP(t)
+	_, err := server.mailbox("wrong").Open(context.Background(), "INBOX")
+	if err == nil || !strings.Contains(err.Error(), "Bad username or password") {
+		t.Errorf("Open() error = %v, want the login refused", err)
+	}
+}
+
+func TestOpen_NotConfigured(t *testing.T) {
+	if _, err := NewMailbox().Open(context.Background(), "INBOX"); err == nil {
+		t.Error("Open() succeeded without an account")
+	}
+}
+
+func TestSend(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, acceptErr := listener.Accept()
+		if acceptErr != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		var lines []string
+		fmt.Fprint(conn, "220 localhost ESMTP\r\n")
+		for data := false; ; {
+			line, readErr := reader.ReadString('\n')
+			if readErr != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+			switch {
+			case data && line == ".":
+				data = false
+				fmt.Fprint(conn, "250 queued\r\n")
+			case data:
+			case strings.HasPrefix(line, "EHLO"):
+				fmt.Fprint(conn, "250-localhost\r\n250 AUTH PLAIN\r\n")
+			case strings.HasPrefix(line, "AUTH"):
+				fmt.Fprint(conn, "235 accepted\r\n")
+			case line == "DATA":
+				data = true
+				fmt.Fprint(conn, "354 go ahead\r\n")
+			case line == "QUIT":
+				fmt.Fprint(conn, "221 bye\r\n")
+				received <- lines
+				return
+			default:
+				fmt.Fprint(conn, "250 ok\r\n")
+			}
+		}
+	}()
+
+	m := NewMailbox()
+	// PlainAuth sends the password unencrypted to localhost only
+	m.SMTPServer.Value = listener.Addr().String()
+	m.Username.Value = "me@example.com"
+	m.Password.Value = "secret"
+	m.Address.Value = "fabric@example.com"
+	if err = m.Send([]string{"you@example.com"}, []byte("Subject: Hi\r\n\r\nHello\r\n")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	lines := strings.Join(<-received, "\n")
+	for _, want := range []string{"MAIL FROM:<fabric@example.com>", "RCPT TO:<you@example.com>", "Subject: Hi\n\nHello"} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("session %q lacks %q", lines, want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Package mcp connects fabric to Model Context Protocol servers as a client,
+// so that the models can call the tools of the servers before they answer.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	debuglog "github.com/danielmiessler/fabric/internal/log"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	clientName    = "fabric"
+	clientVersion = "1.0"
+
+	// AllServers names every server of the config in --mcp
+	AllServers = "all"
+	// maxToolNameLength is the longest tool name the vendors accept
+	maxToolNameLength = 64
+	// closeWait is how long a server run as a command has to exit once its
+	// input is closed
+	closeWait = 2 * time.Second
+)
+
+// invalidToolNameChars matches what the vendors do not accept in tool names
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// Server is an MCP server of the config: a command run with its arguments and
+// environment, talking over its standard input and output, or the URL of a
+// server using the Streamable HTTP transport, with the headers to send it.
+type Server struct {
+	Command string            `yaml:"command" json:"command,omitempty"`
+	Args    []string          `yaml:"args" json:"args,omitempty"`
+	Env     map[string]string `yaml:"env" json:"env,omitempty"`
+	URL     string            `yaml:"url" json:"url,omitempty"`
+	Headers map[string]string `yaml:"headers" json:"headers,omitempty"`
+}
+
+// Client is the session of the MCP SDK with a server
+type Client struct {
+	name    string
+	session *sdk.ClientSession
+}
+
+// Tool is a tool of an MCP server as it lists it
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]any
+}
+
+// Connect starts or reaches the named server and initializes the session
+func Connect(ctx context.Context, name string, server *Server) (ret *Client, err error) {
+	var transport sdk.Transport
+	switch {
+	case server.URL != "":
+		// The client only calls tools, so it needs no stream of the messages
+		// the server would send on its own
+		transport = &sdk.StreamableClientTransport{
+			Endpoint:             server.URL,
+			HTTPClient:           &http.Client{Transport: &headerTransport{headers: server.Headers}},
+			DisableStandaloneSSE: true,
+		}
+	case server.Command != "":
+		cmd := exec.Command(server.Command, server.Args...)
+		cmd.Env = os.Environ()
+		for name, value := range server.Env {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+		if debuglog.GetLevel() >= debuglog.Detailed {
+			cmd.Stderr = os.Stderr
+		}
+		transport = &sdk.CommandTransport{Command: cmd, TerminateDuration: closeWait}
+	default:
+		return nil, fmt.Errorf(i18n.T("mcp_error_server_not_configured"), name)
+	}
+
+	client := sdk.NewClient(&sdk.Implementation{Name: clientName, Version: clientVersion}, nil)
+	var session *sdk.ClientSession
+	if session, err = client.Connect(ctx, transport, nil); err != nil {
+		return nil, fmt.Errorf(i18n.T("mcp_error_initialize"), name, err)
+	}
+	return &Client{name: name, session: session}, nil
+}
+
+// ListTools returns every tool of the server
+func (o *Client) ListTools(ctx context.Context) (ret []Tool, err error) {
+	for tool, listErr := range o.session.Tools(ctx, nil) {
+		if listErr != nil {
+			return nil, fmt.Errorf(i18n.T("mcp_error_list_tools"), o.name, listErr)
+		}
+		// The SDK decodes the schema as it is, a JSON object
+		schema, _ := tool.InputSchema.(map[string]any)
+		ret = append(ret, Tool{Name: tool.Name, Description: tool.Description, InputSchema: schema})
+	}
+	return
+}
+
+// CallTool calls the tool with the arguments, a JSON object, and returns the
+// text of its result. A result the tool flags as an error is returned as the
+// error.
+func (o *Client) CallTool(ctx context.Context, name string, arguments string) (ret string, err error) {
+	args := map[string]any{}
+	if strings.TrimSpace(arguments) != "" {
+		if err = json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf(i18n.T("mcp_error_invalid_arguments"), name, err)
+		}
+	}
+	var result *sdk.CallToolResult
+	if result, err = o.session.CallTool(ctx, &sdk.CallToolParams{Name: name, Arguments: args}); err != nil {
+		return "", fmt.Errorf(i18n.T("mcp_error_call_tool"), o.name, name, err)
+	}
+
+	var parts []string
+	for _, content := range result.Content {
+		switch content := content.(type) {
+		case *sdk.TextContent:
+			parts = append(parts, content.Text)
+		case *sdk.EmbeddedResource:
+			if content.Resource != nil {
+				parts = append(parts, content.Resource.Text)
+			}
+		// Images and audio are named only, the vendors taking text results
+		case *sdk.ImageContent:
+			parts = append(parts, fmt.Sprintf("[image %s]", content.MIMEType))
+		case *sdk.AudioContent:
+			parts = append(parts, fmt.Sprintf("[audio %s]", content.MIMEType))
+		case *sdk.ResourceLink:
+			parts = append(parts, content.URI)
+		}
+	}
+	if len(parts) == 0 && result.StructuredContent != nil {
+		data, _ := json.Marshal(result.StructuredContent)
+		parts = append(parts, string(data))
+	}
+	ret = strings.Join(parts, "\n")
+	if result.IsError {
+		return "", errors.New(ret)
+	}
+	return
+}
+
+// Close ends the session, stopping the server run as a command
+func (o *Client) Close() error {
+	return o.session.Close()
+}
+
+// headerTransport sends the headers of the config, e.g. an API key, with the
+// requests to a server, through the default transport for its proxy and CA
+// settings
+type headerTransport struct {
+	headers map[string]string
+}
+
+func (o *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range o.headers {
+		req.Header.Set(name, value)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// Toolbox offers the tools of several servers to a model, each named after its
+// server and itself so that the names of different servers do not collide.
+type Toolbox struct {
+	clients []*Client
+	tools   []domain.Tool
+	routes  map[string]route
+}
+
+// route is the server and the name of a tool of the toolbox
+type route struct {
+	client *Client
+	name   string
+}
+
+// Open connects to the named servers of the config, or to all of them when a
+// name is AllServers, and lists their tools.
+func Open(ctx context.Context, servers map[string]Server, names []string) (ret *Toolbox, err error) {
+	if slices.Contains(names, AllServers) {
+		names = slices.Sorted(maps.Keys(servers))
+	}
+	ret = &Toolbox{routes: map[string]route{}}
+	for _, name := range names {
+		server, ok := servers[name]
+		if !ok {
+			_ = ret.Close()
+			return nil, fmt.Errorf(i18n.T("mcp_error_server_not_found"), name)
+		}
+		var client *Client
+		var tools []Tool
+		if client, err = Connect(ctx, name, &server); err == nil {
+			ret.clients = append(ret.clients, client)
+			tools, err = client.ListTools(ctx)
+		}
+		if err != nil {
+			_ = ret.Close()
+			return nil, err
+		}
+		for _, tool := range tools {
+			toolName := toolboxName(name, tool.Name)
+			ret.routes[toolName] = route{client: client, name: tool.Name}
+			ret.tools = append(ret.tools, domain.Tool{Name: toolName, Description: tool.Description, InputSchema: tool.InputSchema})
+		}
+		debuglog.Debug(debuglog.Basic, "MCP server %s offers %d tools\n", name, len(tools))
+	}
+	return
+}
+
+// Tools returns the tools of the servers
+func (o *Toolbox) Tools() []domain.Tool {
+	return o.tools
+}
+
+// Call calls the tool of the toolbox named name with the JSON arguments
+func (o *Toolbox) Call(ctx context.Context, name string, arguments string) (string, error) {
+	route, ok := o.routes[name]
+	if !ok {
+		return "", fmt.Errorf(i18n.T("mcp_error_tool_not_found"), name)
+	}
+	return route.client.CallTool(ctx, route.name, arguments)
+}
+
+// Close ends the sessions of the servers
+func (o *Toolbox) Close() (err error) {
+	for _, client := range o.clients {
+		err = errors.Join(err, client.Close())
+	}
+	return
+}
+
+// toolboxName returns the name of the tool of the server in a toolbox, made of
+// the characters the vendors accept
+func toolboxName(server, tool string) string {
+	name := invalidToolNameChars.ReplaceAllString(server+"__"+tool, "_")
+	return name[:min(len(name), maxToolNameLength)]
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServer returns an MCP server with an echo tool and a failing tool, the
+// greeting of the echo tool read from GREETING
+func newServer() *sdk.Server {
+	server := sdk.NewServer(&sdk.Implementation{Name: "test", Version: "1.0"}, nil)
+	schema := map[string]any{"type": "object"}
+	server.AddTool(&sdk.Tool{Name: "echo", Description: "Echo the text", InputSchema: schema},
+		func(ctx context.Context, req *sdk.CallToolRequest) (*sdk.CallToolResult, error) {
+			var args struct {
+				Text string `json:"text"`
+			}
+			_ = json.Unmarshal(req.Params.Arguments, &args)
+			return &sdk.CallToolResult{Content: []sdk.Content{
+				&sdk.TextContent{Text: os.Getenv("GREETING") + args.Text},
+				&sdk.ImageContent{MIMEType: "image/png"},
+			}}, nil
+		})
+	server.AddTool(&sdk.Tool{Name: "fail", InputSchema: schema},
+		func(ctx context.Context, req *sdk.CallToolRequest) (*sdk.CallToolResult, error) {
+			return &sdk.CallToolResult{IsError: true, Content: []sdk.Content{&sdk.TextContent{Text: "it failed"}}}, nil
+		})
+	return server
+}
+
+// TestHelperProcess is the MCP server run over standard input and output by
+// the stdio tests
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	_ = newServer().Run(context.Background(), &sdk.StdioTransport{})
+	os.Exit(0)
+}
+
+func helperServer() Server {
+	return Server{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestHelperProcess"},
+		Env:     map[string]string{"GO_WANT_HELPER_PROCESS": "1", "GREETING": "stdio: "},
+	}
+}
+
+func TestStdioClient(t *testing.T) {
+	server := helperServer()
+	client, err := Connect(context.Background(), "helper", &server)
+	require.NoError(t, err)
+	defer client.Close()
+
+	tools, err := client.ListTools(context.Background())
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	assert.Equal(t, "echo", tools[0].Name)
+	assert.Equal(t, "Echo the text", tools[0].Description)
+
+	result, err := client.CallTool(context.Background(), "echo", `{"text":"hello"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "stdio: hello\n[image image/png]", result)
+
+	_, err = client.CallTool(context.Background(), "fail", "")
+	assert.EqualError(t, err, "it failed")
+
+	_, err = client.CallTool(context.Background(), "echo", "not json")
+	assert.Error(t, err)
+}
+
+// streamableServer serves the MCP server over Streamable HTTP to the clients
+// sending the API key
+func streamableServer(t *testing.T) *httptest.Server {
+	handler := sdk.NewStreamableHTTPHandler(func(*http.Request) *sdk.Server { return newServer() }, nil)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+}
+
+func TestStreamableHTTPClient(t *testing.T) {
+	httpServer := streamableServer(t)
+	defer httpServer.Close()
+
+	server := Server{URL: httpServer.URL, Headers: map[string]string{"Authorization": "Bearer secret"}}
+	client, err := Connect(context.Background(), "remote", &server)
+	require.NoError(t, err)
+	defer client.Close()
+
+	tools, err := client.ListTools(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, tools, 2)
+
+	result, err := client.CallTool(context.Background(), "echo", `{"text":"hello"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n[image image/png]", result)
+
+	_, err = Connect(context.Background(), "remote", &Server{URL: httpServer.URL})
+	assert.ErrorContains(t, err, "Unauthorized")
+}
+
+func TestOpen(t *testing.T) {
+	httpServer := streamableServer(t)
+	defer httpServer.Close()
+	servers := map[string]Server{
+		"local":  helperServer(),
+		"remote": {URL: httpServer.URL, Headers: map[string]string{"Authorization": "Bearer secret"}},
+	}
+
+	toolbox, err := Open(context.Background(), servers, []string{AllServers})
+	require.NoError(t, err)
+	defer toolbox.Close()
+
+	var names []string
+	for _, tool := range toolbox.Tools() {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"local__echo", "local__fail", "remote__echo", "remote__fail"}, names)
+
+	result, err := toolbox.Call(context.Background(), "local__echo", `{"text":"hi"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "stdio: hi\n[image image/png]", result)
+
+	_, err = toolbox.Call(context.Background(), "local__missing", "{}")
+	assert.Error(t, err)
+
+	_, err = Open(context.Background(), servers, []string{"missing"})
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestToolboxName(t *testing.T) {
+	assert.Equal(t, "git_hub__search_code", toolboxName("git hub", "search.code"))
+	assert.Len(t, toolboxName("server", strings.Repeat("x", 100)), maxToolNameLength)
+}
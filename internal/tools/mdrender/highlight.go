@@ -0,0 +1,117 @@
+package mdrender
+
+import (
+	"strings"
+	"unicode"
+)
+
+// keywords are highlighted in code blocks of any language.
+var keywords = map[string]bool{
+	"and": true, "as": true, "async": true, "await": true, "break": true, "case": true,
+	"catch": true, "class": true, "const": true, "continue": true, "def": true, "default": true,
+	"defer": true, "del": true, "do": true, "elif": true, "else": true, "end": true,
+	"enum": true, "except": true, "export": true, "extends": true, "false": true, "fi": true,
+	"finally": true, "fn": true, "for": true, "from": true, "func": true, "function": true,
+	"go": true, "if": true, "impl": true, "import": true, "in": true, "interface": true,
+	"is": true, "lambda": true, "let": true, "map": true, "match": true, "mut": true,
+	"new": true, "nil": true, "none": true, "None": true, "not": true, "null": true,
+	"or": true, "package": true, "pass": true, "private": true, "pub": true, "public": true,
+	"raise": true, "range": true, "return": true, "select": true, "self": true, "static": true,
+	"struct": true, "switch": true, "then": true, "this": true, "throw": true, "true": true,
+	"True": true, "False": true, "try": true, "type": true, "use": true, "var": true,
+	"void": true, "while": true, "with": true, "yield": true,
+}
+
+// plainLanguages are the languages of code blocks printed without highlighting.
+var plainLanguages = map[string]bool{
+	"": true, "text": true, "plaintext": true, "txt": true, "markdown": true, "md": true,
+}
+
+// lineComments returns the markers starting a comment running to the end of
+// the line in the language.
+func lineComments(lang string) []string {
+	switch lang {
+	case "go", "golang", "js", "javascript", "ts", "typescript", "jsx", "tsx", "java", "c", "cpp", "c++",
+		"cs", "csharp", "rust", "rs", "swift", "kotlin", "kt", "scala", "php", "dart", "json5":
+		return []string{"//"}
+	case "python", "py", "sh", "bash", "zsh", "shell", "console", "ruby", "rb", "perl", "yaml", "yml",
+		"toml", "r", "dockerfile", "makefile", "make", "powershell", "ps1", "elixir", "ex", "nim":
+		return []string{"#"}
+	case "sql", "lua", "haskell", "hs", "elm":
+		return []string{"--"}
+	case "json":
+		return nil
+	default:
+		return []string{"//", "#"}
+	}
+}
+
+// highlightCode colors the comments, strings, numbers and keywords of a line
+// of code.
+func highlightCode(line, lang string) string {
+	if plainLanguages[lang] {
+		return line
+	}
+	comments := lineComments(lang)
+
+	var sb strings.Builder
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if (r == '/' || r == '#' || r == '-') && (i == 0 || !isWordRune(runes[i-1])) {
+			if rest := string(runes[i:]); isComment(rest, comments) {
+				sb.WriteString(colorGray + rest + colorOff)
+				break
+			}
+		}
+
+		switch {
+		case r == '"' || r == '\'' || r == '`':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(runes))
+			sb.WriteString(colorGreen + string(runes[i:end]) + colorOff)
+			i = end
+		case unicode.IsDigit(r) && (i == 0 || !isWordRune(runes[i-1])):
+			end := i
+			for end < len(runes) && (isWordRune(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			sb.WriteString(colorYellow + string(runes[i:end]) + colorOff)
+			i = end
+		case isWordRune(r):
+			end := i
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			if keywords[word] {
+				word = colorMagenta + word + colorOff
+			}
+			sb.WriteString(word)
+			i = end
+		default:
+			sb.WriteRune(r)
+			i++
+		}
+	}
+	return sb.String()
+}
+
+func isComment(text string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.HasPrefix(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
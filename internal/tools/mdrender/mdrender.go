@@ -0,0 +1,167 @@
+// Package mdrender renders Markdown as styled text for terminals, using ANSI
+// escape sequences for headings, emphasis, lists, quotes and highlighted code.
+package mdrender
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// DefaultWidth is the terminal width assumed when it is unknown.
+const DefaultWidth = 80
+
+const (
+	bold          = "\033[1m"
+	boldOff       = "\033[22m"
+	italic        = "\033[3m"
+	italicOff     = "\033[23m"
+	underline     = "\033[4m"
+	underlineOff  = "\033[24m"
+	strike        = "\033[9m"
+	strikeOff     = "\033[29m"
+	colorOff      = "\033[39m"
+	colorGreen    = "\033[32m"
+	colorYellow   = "\033[33m"
+	colorBlue     = "\033[34m"
+	colorMagenta  = "\033[35m"
+	colorCyan     = "\033[36m"
+	colorGray     = "\033[90m"
+	maxRuleLength = 80
+)
+
+var (
+	headingRegex   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	fenceRegex     = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([^\\s`]*)")
+	ruleRegex      = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	quoteRegex     = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	taskRegex      = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	bulletRegex    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedRegex   = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	tableRuleRegex = regexp.MustCompile(`^\s*\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*\|?\s*$`)
+
+	codeSpanRegex = regexp.MustCompile("`[^`]+`")
+	imageRegex    = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+	linkRegex     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	boldRegex     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	strikeRegex   = regexp.MustCompile(`~~([^~]+)~~`)
+	italicRegex   = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*?)\*|(^|[^\w])_([^_\s][^_]*?)_([^\w]|$)`)
+)
+
+// Render renders a whole Markdown document for a terminal of the given width.
+func Render(markdown string, width int) string {
+	renderer := NewRenderer(width)
+	lines := strings.Split(strings.TrimRight(markdown, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = renderer.Line(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Renderer renders a Markdown document line by line, keeping track of the code
+// blocks the lines belong to.
+type Renderer struct {
+	width    int
+	inCode   bool
+	fence    string
+	codeLang string
+}
+
+// NewRenderer creates a renderer for a terminal of the given width, or of
+// DefaultWidth when it is not positive.
+func NewRenderer(width int) *Renderer {
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	return &Renderer{width: width}
+}
+
+// Line renders a complete line, without its line break.
+func (o *Renderer) Line(line string) string {
+	if match := fenceRegex.FindStringSubmatch(line); match != nil {
+		if !o.inCode {
+			o.inCode, o.fence, o.codeLang = true, match[1][:3], strings.ToLower(match[2])
+			return colorGray + line + colorOff
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), o.fence) {
+			o.inCode, o.fence, o.codeLang = false, "", ""
+			return colorGray + line + colorOff
+		}
+	}
+	if o.inCode {
+		return highlightCode(line, o.codeLang)
+	}
+
+	if match := headingRegex.FindStringSubmatch(line); match != nil {
+		text := inline(match[2])
+		switch len(match[1]) {
+		case 1:
+			return bold + underline + colorMagenta + text + colorOff + underlineOff + boldOff
+		case 2:
+			return bold + colorCyan + text + colorOff + boldOff
+		default:
+			return bold + match[1] + " " + text + boldOff
+		}
+	}
+	if ruleRegex.MatchString(line) {
+		return colorGray + strings.Repeat("─", min(o.width, maxRuleLength)) + colorOff
+	}
+	if match := quoteRegex.FindStringSubmatch(line); match != nil {
+		return colorGray + "│ " + colorOff + italic + inline(match[1]) + italicOff
+	}
+	if match := taskRegex.FindStringSubmatch(line); match != nil {
+		box := "☐"
+		if match[2] != " " {
+			box = colorGreen + "☑" + colorOff
+		}
+		return match[1] + box + " " + inline(match[3])
+	}
+	if match := bulletRegex.FindStringSubmatch(line); match != nil {
+		return match[1] + colorYellow + "•" + colorOff + " " + inline(match[2])
+	}
+	if match := orderedRegex.FindStringSubmatch(line); match != nil {
+		return match[1] + colorYellow + match[2] + colorOff + " " + inline(match[3])
+	}
+	if strings.Contains(line, "|") && tableRuleRegex.MatchString(line) {
+		return colorGray + line + colorOff
+	}
+	return inline(line)
+}
+
+// inline renders the code spans, links, images and emphasis of a line.
+func inline(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, span := range codeSpanRegex.FindAllStringIndex(text, -1) {
+		sb.WriteString(emphasis(text[last:span[0]]))
+		sb.WriteString(colorCyan + text[span[0]+1:span[1]-1] + colorOff)
+		last = span[1]
+	}
+	sb.WriteString(emphasis(text[last:]))
+	return sb.String()
+}
+
+func emphasis(text string) string {
+	text = imageRegex.ReplaceAllString(text, underline+"$1"+underlineOff+" "+colorGray+"($2)"+colorOff)
+	text = linkRegex.ReplaceAllString(text, underline+colorBlue+"$1"+colorOff+underlineOff+" "+colorGray+"($2)"+colorOff)
+	text = boldRegex.ReplaceAllString(text, bold+"$1$2"+boldOff)
+	text = strikeRegex.ReplaceAllString(text, strike+"$1"+strikeOff)
+	text = italicRegex.ReplaceAllString(text, "$1$3"+italic+"$2$4"+italicOff+"$5")
+	return text
+}
+
+// IsTerminal reports whether the file is a terminal.
+func IsTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// TerminalWidth returns the width of the terminal, or DefaultWidth when it is
+// unknown.
+func TerminalWidth(file *os.File) int {
+	if width, _, err := term.GetSize(int(file.Fd())); err == nil && width > 0 {
+		return width
+	}
+	return DefaultWidth
+}
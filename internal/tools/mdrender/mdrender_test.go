@@ -0,0 +1,94 @@
+package mdrender
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRendererLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "plain", line: "just text", want: "just text"},
+		{name: "heading", line: "## Summary ##", want: bold + colorCyan + "Summary" + colorOff + boldOff},
+		{name: "deep heading", line: "### Notes", want: bold + "### Notes" + boldOff},
+		{name: "bold", line: "a **b** c", want: "a " + bold + "b" + boldOff + " c"},
+		{name: "italic", line: "an *idea* here", want: "an " + italic + "idea" + italicOff + " here"},
+		{name: "snake case", line: "use snake_case_names", want: "use snake_case_names"},
+		{name: "multiplication", line: "2 * 3 * 4", want: "2 * 3 * 4"},
+		{name: "strike", line: "~~old~~", want: strike + "old" + strikeOff},
+		{name: "code span", line: "run `**go**`", want: "run " + colorCyan + "**go**" + colorOff},
+		{name: "link", line: "[site](https://example.com)", want: underline + colorBlue + "site" + colorOff + underlineOff + " " + colorGray + "(https://example.com)" + colorOff},
+		{name: "bullet", line: "  - item", want: "  " + colorYellow + "•" + colorOff + " item"},
+		{name: "ordered", line: "1. first", want: colorYellow + "1." + colorOff + " first"},
+		{name: "task", line: "- [x] done", want: colorGreen + "☑" + colorOff + " done"},
+		{name: "quote", line: "> wise", want: colorGray + "│ " + colorOff + italic + "wise" + italicOff},
+		{name: "rule", line: "***", want: colorGray + strings.Repeat("─", 40) + colorOff},
+		{name: "table rule", line: "|---|:--:|", want: colorGray + "|---|:--:|" + colorOff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRenderer(40).Line(tt.line); got != tt.want {
+				t.Errorf("Line(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderCodeBlock(t *testing.T) {
+	got := Render("```go\nreturn \"x\" // done\n```\n# Title", 80)
+	want := strings.Join([]string{
+		colorGray + "```go" + colorOff,
+		colorMagenta + "return" + colorOff + " " + colorGreen + "\"x\"" + colorOff + " " + colorGray + "// done" + colorOff,
+		colorGray + "```" + colorOff,
+		bold + underline + colorMagenta + "Title" + colorOff + underlineOff + boldOff,
+	}, "\n")
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	// Markdown is not rendered inside code blocks
+	if got = Render("```\n# not a heading\n```", 80); !strings.Contains(got, "\n# not a heading\n") {
+		t.Errorf("Render() rendered a code block: %q", got)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewWriter(&out, 80)
+	for _, chunk := range []string{"# Ti", "tle\nsome **bo", "ld**"} {
+		if _, err := writer.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	want := "# Ti" + "\r\033[K" + Render("# Title", 80) + "\n" +
+		"some **bo" + "ld**" + "\r\033[K" + "some " + bold + "bold" + boldOff
+	if got := out.String(); got != want {
+		t.Errorf("Writer output = %q, want %q", got, want)
+	}
+}
+
+func TestWriterErasesWrappedLine(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewWriter(&out, 10)
+	_, _ = writer.Write([]byte(strings.Repeat("a", 25)))
+	_, _ = writer.Write([]byte("\n"))
+
+	want := strings.Repeat("a", 25) + "\r\033[K" + strings.Repeat("\033[1A\033[2K", 2) + strings.Repeat("a", 25) + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("Writer output = %q, want %q", got, want)
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	if got := displayWidth(bold + "abc" + boldOff + "日本"); got != 7 {
+		t.Errorf("displayWidth() = %d, want 7", got)
+	}
+}
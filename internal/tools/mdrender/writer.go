@@ -0,0 +1,93 @@
+package mdrender
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+var ansiRegex = regexp.MustCompile("\033\\[[0-9;]*[A-Za-z]")
+
+// Writer renders streamed Markdown progressively: the text of the current line
+// is printed as it arrives, then erased and printed again rendered once the
+// line is complete.
+type Writer struct {
+	out      io.Writer
+	renderer *Renderer
+	width    int
+	// line is the text of the current line printed so far
+	line string
+}
+
+// NewWriter creates a writer rendering Markdown to out, a terminal of the given
+// width.
+func NewWriter(out io.Writer, width int) *Writer {
+	renderer := NewRenderer(width)
+	return &Writer{out: out, renderer: renderer, width: renderer.width}
+}
+
+// Write prints the text, rendering the lines it completes.
+func (o *Writer) Write(p []byte) (n int, err error) {
+	text := string(p)
+	for {
+		idx := strings.IndexByte(text, '\n')
+		if idx < 0 {
+			break
+		}
+		if err = o.printLine(o.line+text[:idx], "\n"); err != nil {
+			return
+		}
+		o.line = ""
+		text = text[idx+1:]
+	}
+	if text != "" {
+		if _, err = io.WriteString(o.out, text); err != nil {
+			return
+		}
+		o.line += text
+	}
+	return len(p), nil
+}
+
+// Flush renders the last line when it is not complete.
+func (o *Writer) Flush() (err error) {
+	if o.line != "" {
+		err = o.printLine(o.line, "")
+		o.line = ""
+	}
+	return
+}
+
+// printLine replaces the text of the current line printed so far with the
+// rendered line.
+func (o *Writer) printLine(line, end string) (err error) {
+	var sb strings.Builder
+	if o.line != "" {
+		sb.WriteString("\r\033[K")
+		for rows := (displayWidth(o.line) - 1) / o.width; rows > 0; rows-- {
+			sb.WriteString("\033[1A\033[2K")
+		}
+	}
+	sb.WriteString(o.renderer.Line(line))
+	sb.WriteString(end)
+	_, err = io.WriteString(o.out, sb.String())
+	return
+}
+
+// displayWidth returns the number of terminal columns text takes, ignoring
+// escape sequences and counting wide characters twice.
+func displayWidth(text string) (ret int) {
+	for _, r := range ansiRegex.ReplaceAllString(text, "") {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			ret += 2
+		default:
+			if r >= ' ' {
+				ret++
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,124 @@
+package meetings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// see https://developers.google.com/drive/api/reference/rest/v3 for the API
+var driveURL = "https://www.googleapis.com/drive/v3"
+
+const driveScope = "https://www.googleapis.com/auth/drive.readonly"
+
+// driveClient returns the client of the Drive API, authorized with the
+// Application Default Credentials of Google Cloud
+var driveClient = func(ctx context.Context) (*http.Client, error) {
+	creds, err := google.FindDefaultCredentials(ctx, driveScope)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("gmeet_error_credentials"), err)
+	}
+	client := oauth2.NewClient(ctx, creds.TokenSource)
+	client.Timeout = httpClient.Timeout
+	return client, nil
+}
+
+var (
+	// driveIDRegex matches the file ids of the Google Docs and Drive links
+	driveIDRegex = regexp.MustCompile(`/d/([\w-]{20,})|[?&]id=([\w-]{20,})`)
+	fileIDRegex  = regexp.MustCompile(`^[\w-]{20,}$`)
+)
+
+// IsGoogleDoc reports whether the source is the link of a Google Docs or
+// Drive file
+func IsGoogleDoc(source string) bool {
+	return strings.HasPrefix(source, "https://docs.google.com/") || strings.HasPrefix(source, "https://drive.google.com/")
+}
+
+// GoogleMeet returns the transcript Google Meet saved to Drive, given by the
+// id or link of its Google Docs document, or of a .vtt or .srt file
+func GoogleMeet(ctx context.Context, doc string) (ret Transcript, err error) {
+	id := strings.TrimSpace(doc)
+	if match := driveIDRegex.FindStringSubmatch(id); match != nil {
+		id = match[1] + match[2]
+	}
+	if !fileIDRegex.MatchString(id) {
+		return ret, fmt.Errorf(i18n.T("gmeet_error_invalid_doc"), doc)
+	}
+	var client *http.Client
+	if client, err = driveClient(ctx); err != nil {
+		return
+	}
+	var file struct {
+		Name        string    `json:"name"`
+		MimeType    string    `json:"mimeType"`
+		CreatedTime time.Time `json:"createdTime"`
+	}
+	params := url.Values{"fields": {"name,mimeType,createdTime"}, "supportsAllDrives": {"true"}}
+	if err = driveGet(ctx, client, "/files/"+id+"?"+params.Encode(), &file); err != nil {
+		return
+	}
+
+	// The documents are exported as text, the other files downloaded
+	var content string
+	if strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") {
+		err = driveGet(ctx, client, "/files/"+id+"/export?"+url.Values{"mimeType": {"text/plain"}}.Encode(), &content)
+	} else {
+		err = driveGet(ctx, client, "/files/"+id+"?"+url.Values{"alt": {"media"}, "supportsAllDrives": {"true"}}.Encode(), &content)
+	}
+	if err != nil {
+		return
+	}
+	if ext := strings.ToLower(path.Ext(file.Name)); ext == ".vtt" || ext == ".srt" || strings.HasPrefix(strings.TrimPrefix(content, "\ufeff"), "WEBVTT") {
+		ret.Title = file.Name
+		ret.Utterances, err = ParseCaptions(content)
+	} else {
+		ret = ParseText(content)
+	}
+	if ret.Title == "" {
+		ret.Title = file.Name
+	}
+	ret.Start = file.CreatedTime
+	return
+}
+
+// driveGet decodes the JSON response to the target, or reads it to the
+// target string
+func driveGet(ctx context.Context, client *http.Client, requestPath string, target any) (err error) {
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, driveURL+requestPath, nil); err != nil {
+		return
+	}
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return fmt.Errorf(i18n.T("meetings_error_request"), "Google Drive", err)
+	}
+	defer resp.Body.Close()
+	var data []byte
+	if data, err = io.ReadAll(resp.Body); err != nil {
+		return fmt.Errorf(i18n.T("meetings_error_request"), "Google Drive", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(i18n.T("meetings_error_status"), "Google Drive", resp.StatusCode, strings.TrimSpace(string(data[:min(len(data), 1024)])))
+	}
+	if text, ok := target.(*string); ok {
+		*text = string(data)
+		return
+	}
+	if err = json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf(i18n.T("meetings_error_request"), "Google Drive", err)
+	}
+	return
+}
@@ -0,0 +1,187 @@
+package meetings
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const zoomVTT = `WEBVTT
+
+1
+00:00:01.500 --> 00:00:04.000
+Ada Lovelace: Good morning, let's start.
+
+2
+00:00:04.200 --> 00:00:06.000
+Ada Lovelace: First item is the launch.
+
+3
+00:00:07.000 --> 00:00:09.000
+Bob: It slips a week.
+`
+
+func TestParseCaptions(t *testing.T) {
+	got, err := ParseCaptions(zoomVTT)
+	if err != nil {
+		t.Fatalf("ParseCaptions() error = %v", err)
+	}
+	want := []Utterance{
+		{Start: 1500 * time.Millisecond, Speaker: "Ada Lovelace", Text: "Good morning, let's start. First item is the launch."},
+		{Start: 7 * time.Second, Speaker: "Bob", Text: "It slips a week."},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ParseCaptions() = %+v, want %+v", got, want)
+	}
+
+	// The voice tags of Teams and the rolling captions without speakers
+	voices := "WEBVTT\n\nNOTE exported\n\n00:01.000 --> 00:03.000\n<v Grace Hopper>Ship it <i>today</i>.</v>\n\n" +
+		"00:03.000 --> 00:05.000\n<v.loud Alan>Agreed.</v>\n\n01:10.000 --> 01:12.000\nno speaker\n\n01:12.000 --> 01:14.000\nno speaker\n"
+	if got, err = ParseCaptions(voices); err != nil {
+		t.Fatalf("ParseCaptions() error = %v", err)
+	}
+	if len(got) != 3 || got[0].Speaker != "Grace Hopper" || got[0].Text != "Ship it today." || got[1].Speaker != "Alan" ||
+		got[2].Speaker != "" || got[2].Text != "no speaker" || got[2].Start != 70*time.Second {
+		t.Errorf("ParseCaptions() of voice tags = %+v", got)
+	}
+
+	srt := "1\n00:00:02,000 --> 00:00:03,000\nAda: Hello\n\n2\n00:01:00,250 --> 00:01:02,000\nBob: Hi\nthere\n"
+	if got, err = ParseCaptions(srt); err != nil || len(got) != 2 || got[1].Start != 60250*time.Millisecond || got[1].Text != "Hi there" {
+		t.Errorf("ParseCaptions() of SRT = %+v, %v", got, err)
+	}
+
+	if _, err = ParseCaptions("just some text"); err == nil {
+		t.Errorf("ParseCaptions() of text expected an error")
+	}
+}
+
+func TestParseText(t *testing.T) {
+	text := "\ufeffWeekly sync - Transcript\r\nAttendees\r\nAda, Bob\r\n\r\n00:00:00\r\n\r\nAda: Welcome back.\r\n" +
+		"The numbers are up.\r\nBob: Great.\r\n\r\n00:05:00\r\n\r\nAda: Next topic.\r\n"
+	got := ParseText(text)
+	if got.Title != "Weekly sync - Transcript" || len(got.Utterances) != 3 {
+		t.Fatalf("ParseText() = %+v", got)
+	}
+	if got.Utterances[0].Text != "Welcome back. The numbers are up." || got.Utterances[2].Start != 5*time.Minute {
+		t.Errorf("ParseText() utterances = %+v", got.Utterances)
+	}
+
+	// Without times, the paragraphs after the title are the transcript
+	if got = ParseText("Standup\nAda: Done.\nBob: Blocked."); got.Title != "Standup" || len(got.Utterances) != 2 {
+		t.Errorf("ParseText() without times = %+v", got)
+	}
+}
+
+func TestTranscriptMarkdown(t *testing.T) {
+	utterances, _ := ParseCaptions(zoomVTT)
+	transcript := Transcript{Title: "Launch review", Start: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		Duration: 30 * time.Minute, Utterances: utterances}
+	want := "# Launch review\n\n- Date: 2026-10-01 09:00 UTC\n- Duration: 30m0s\n- Speakers: Ada Lovelace, Bob\n\n## Transcript\n\n" +
+		"[00:00:01] **Ada Lovelace:** Good morning, let's start. First item is the launch.\n\n[00:00:07] **Bob:** It slips a week."
+	if got := transcript.Markdown(); got != want {
+		t.Errorf("Markdown() = %q, want %q", got, want)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "launch review.vtt")
+	if err := os.WriteFile(path, []byte(zoomVTT), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(path)
+	if err != nil || got.Title != "launch review" || len(got.Utterances) != 2 {
+		t.Errorf("ReadFile() = %+v, %v", got, err)
+	}
+}
+
+func TestZoomTranscript(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/token" {
+			if user, secret, _ := r.BasicAuth(); user != "client" || secret != "secret" || r.URL.Query().Get("account_id") != "account" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"reason": "Invalid client_id or client_secret", "error": "invalid_client"}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token": "token"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		recording := `{"topic": "Launch review", "start_time": "2026-10-01T09:00:00Z", "duration": 30, "recording_files": [
+			{"file_type": "MP4", "download_url": "%[1]s/download/video"},
+			{"file_type": "TRANSCRIPT", "status": "completed", "download_url": "%[1]s/download/transcript"}]}`
+		switch r.URL.Path {
+		case "/v2/meetings/81234567890/recordings":
+			fmt.Fprintf(w, recording, server.URL)
+		case "/v2/users/me/recordings":
+			fmt.Fprintf(w, `{"meetings": [{"topic": "Old", "start_time": "2026-09-01T09:00:00Z"}, `+recording+`]}`, server.URL)
+		case "/download/transcript":
+			fmt.Fprint(w, zoomVTT)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code": 3301, "message": "This recording does not exist."}`)
+		}
+	}))
+	defer server.Close()
+	zoomURL, zoomTokenURL = server.URL+"/v2", server.URL+"/oauth/token"
+
+	zoom := NewZoom()
+	if _, err := zoom.Transcript("81234567890"); err == nil {
+		t.Errorf("Transcript() without configuration expected an error")
+	}
+	zoom.AccountID.Value, zoom.ClientID.Value, zoom.ClientSecret.Value = "account", "client", "secret"
+	for _, meeting := range []string{"812 3456 7890", "https://us02web.zoom.us/j/81234567890?pwd=abc", ""} {
+		got, err := zoom.Transcript(meeting)
+		if err != nil {
+			t.Fatalf("Transcript(%q) error = %v", meeting, err)
+		}
+		if got.Title != "Launch review" || got.Duration != 30*time.Minute || len(got.Utterances) != 2 {
+			t.Errorf("Transcript(%q) = %+v", meeting, got)
+		}
+	}
+	if _, err := zoom.Transcript("1"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Transcript() of a missing recording error = %v, want the message of the API", err)
+	}
+	zoom.ClientSecret.Value = "wrong"
+	if _, err := zoom.Transcript("81234567890"); err == nil || !strings.Contains(err.Error(), "Invalid client_id") {
+		t.Errorf("Transcript() with a wrong secret error = %v, want the reason of the API", err)
+	}
+}
+
+func TestGoogleMeet(t *testing.T) {
+	const id = "1AbCdEfGhIjKlMnOpQrStUvWxYz0123456789"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/files/"+id && r.URL.Query().Get("fields") != "":
+			fmt.Fprint(w, `{"name": "Weekly sync - Transcript", "mimeType": "application/vnd.google-apps.document", "createdTime": "2026-10-01T09:30:00Z"}`)
+		case r.URL.Path == "/files/"+id+"/export" && r.URL.Query().Get("mimeType") == "text/plain":
+			fmt.Fprint(w, "Weekly sync - Transcript\n00:00:00\nAda: Welcome back.\nBob: Great.\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	driveURL = server.URL
+	driveClient = func(context.Context) (*http.Client, error) { return server.Client(), nil }
+
+	got, err := GoogleMeet(context.Background(), "https://docs.google.com/document/d/"+id+"/edit?tab=t.0")
+	if err != nil {
+		t.Fatalf("GoogleMeet() error = %v", err)
+	}
+	if got.Title != "Weekly sync - Transcript" || !got.Start.Equal(time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)) ||
+		strings.Join(got.Speakers(), ",") != "Ada,Bob" {
+		t.Errorf("GoogleMeet() = %+v", got)
+	}
+	if _, err = GoogleMeet(context.Background(), "not an id"); err == nil {
+		t.Errorf("GoogleMeet() of an invalid id expected an error")
+	}
+}
@@ -0,0 +1,244 @@
+// Package meetings reads the transcripts of meetings, from WebVTT and SRT
+// files, Zoom cloud recordings and the Google Meet transcripts saved to
+// Drive, as Markdown input for the patterns, with the speakers kept.
+package meetings
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// unnamedSpan is the longest span of the cues without speaker merged together
+const unnamedSpan = time.Minute
+
+var (
+	cueTimeRegex = regexp.MustCompile(`^((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})\s+-->\s+((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})`)
+	voiceRegex   = regexp.MustCompile(`<v(?:\.[\w.-]+)?\s+([^>]+)>`)
+	tagRegex     = regexp.MustCompile(`<[^>]*>`)
+	// speakerRegex matches the "Name: text" lines of Zoom, Google Meet and
+	// the captions without voice tags
+	speakerRegex = regexp.MustCompile(`^([\p{L}][\p{L}\p{M}0-9 .'’()-]{0,59}?)\s*:\s+(.+)$`)
+	clockRegex   = regexp.MustCompile(`^\(?(\d{1,2}:\d{2}(?::\d{2})?)\)?$`)
+)
+
+// Utterance is what a speaker said, from a time of the meeting
+type Utterance struct {
+	Start   time.Duration
+	Speaker string
+	Text    string
+}
+
+// Transcript is the transcript of a meeting, with what is known of it
+type Transcript struct {
+	Title      string
+	Start      time.Time
+	Duration   time.Duration
+	Utterances []Utterance
+}
+
+// Markdown returns the transcript as Markdown: its title and details, then
+// the utterances with their time and speaker
+func (o *Transcript) Markdown() string {
+	var sb strings.Builder
+	if o.Title != "" {
+		sb.WriteString("# " + o.Title + "\n\n")
+	}
+	if !o.Start.IsZero() {
+		sb.WriteString("- " + i18n.T("meetings_date") + ": " + o.Start.UTC().Format("2006-01-02 15:04 MST") + "\n")
+	}
+	if o.Duration > 0 {
+		sb.WriteString("- " + i18n.T("meetings_duration") + ": " + o.Duration.Round(time.Minute).String() + "\n")
+	}
+	if speakers := o.Speakers(); len(speakers) > 0 {
+		sb.WriteString("- " + i18n.T("meetings_speakers") + ": " + strings.Join(speakers, ", ") + "\n")
+	}
+	sb.WriteString("\n## " + i18n.T("meetings_transcript") + "\n")
+	for _, utterance := range o.Utterances {
+		sb.WriteString("\n[" + clock(utterance.Start) + "] ")
+		if utterance.Speaker != "" {
+			sb.WriteString("**" + utterance.Speaker + ":** ")
+		}
+		sb.WriteString(utterance.Text + "\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// Speakers returns the speakers of the transcript, in the order they first
+// speak
+func (o *Transcript) Speakers() (ret []string) {
+	seen := map[string]bool{}
+	for _, utterance := range o.Utterances {
+		if utterance.Speaker != "" && !seen[utterance.Speaker] {
+			seen[utterance.Speaker] = true
+			ret = append(ret, utterance.Speaker)
+		}
+	}
+	return
+}
+
+// ReadFile reads the transcript of a .vtt or .srt captions file, or of a text
+// file like the Google Meet transcripts downloaded as text, named after the
+// file
+func ReadFile(path string) (ret Transcript, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".vtt", ".srt":
+		ret.Title = name
+		ret.Utterances, err = ParseCaptions(string(data))
+	default:
+		ret = ParseText(string(data))
+	}
+	return
+}
+
+// ParseCaptions parses WebVTT or SRT captions, the speakers being those of
+// the voice tags or of the "Name: text" cues. The consecutive cues of a
+// speaker are merged, as the repeated ones of rolling captions.
+func ParseCaptions(captions string) (ret []Utterance, err error) {
+	scanner := bufio.NewScanner(strings.NewReader(strings.TrimPrefix(captions, "\ufeff")))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var cue *Utterance
+	var lines []string
+	flush := func() {
+		if cue != nil {
+			cue.Speaker, cue.Text = cueText(lines)
+			ret = mergeUtterance(ret, *cue)
+		}
+		cue, lines = nil, nil
+	}
+	cues := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if match := cueTimeRegex.FindStringSubmatch(line); match != nil {
+			flush()
+			cues++
+			cue = &Utterance{Start: parseCueTime(match[1])}
+			continue
+		}
+		if line == "" {
+			flush()
+			continue
+		}
+		if cue != nil {
+			lines = append(lines, line)
+		}
+	}
+	flush()
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if cues == 0 {
+		return nil, errors.New(i18n.T("meetings_error_no_cues"))
+	}
+	return
+}
+
+// cueText returns the speaker and text of the lines of a cue
+func cueText(lines []string) (speaker, text string) {
+	joined := strings.Join(lines, " ")
+	if match := voiceRegex.FindStringSubmatch(joined); match != nil {
+		speaker = strings.TrimSpace(match[1])
+	}
+	text = strings.Join(strings.Fields(tagRegex.ReplaceAllString(joined, "")), " ")
+	if speaker == "" {
+		if match := speakerRegex.FindStringSubmatch(text); match != nil {
+			speaker, text = strings.TrimSpace(match[1]), match[2]
+		}
+	}
+	return
+}
+
+// mergeUtterance appends the utterance, or its text to the last one of the
+// same speaker
+func mergeUtterance(utterances []Utterance, utterance Utterance) []Utterance {
+	if utterance.Text == "" {
+		return utterances
+	}
+	if n := len(utterances); n > 0 {
+		last := &utterances[n-1]
+		if last.Speaker == utterance.Speaker && (last.Speaker != "" || utterance.Start-last.Start < unnamedSpan) {
+			// Rolling captions repeat the end of the previous cue
+			if !strings.HasSuffix(last.Text, utterance.Text) {
+				last.Text += " " + utterance.Text
+			}
+			return utterances
+		}
+	}
+	return append(utterances, utterance)
+}
+
+// ParseText parses the text of a transcript like those Google Meet saves: a
+// title, a time line now and then, and "Name: text" paragraphs. Without time
+// lines, the paragraphs after the title are the transcript.
+func ParseText(text string) (ret Transcript) {
+	if ret = parseText(text, false); len(ret.Utterances) == 0 {
+		ret = parseText(text, true)
+	}
+	return
+}
+
+func parseText(text string, inTranscript bool) (ret Transcript) {
+	var start time.Duration
+	timed := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+		if line == "" {
+			continue
+		}
+		if match := clockRegex.FindStringSubmatch(line); match != nil {
+			start, inTranscript, timed = parseClock(match[1]), true, true
+			continue
+		}
+		if ret.Title == "" && !timed && len(ret.Utterances) == 0 && !(inTranscript && speakerRegex.MatchString(line)) {
+			ret.Title = line
+			continue
+		}
+		if match := speakerRegex.FindStringSubmatch(line); match != nil && inTranscript {
+			ret.Utterances = mergeUtterance(ret.Utterances, Utterance{Start: start, Speaker: strings.TrimSpace(match[1]), Text: match[2]})
+			continue
+		}
+		// The lines before the first time are the details of the meeting, and
+		// the others continue the last utterance
+		if n := len(ret.Utterances); n > 0 {
+			ret.Utterances[n-1].Text += " " + line
+		} else if inTranscript {
+			ret.Utterances = append(ret.Utterances, Utterance{Start: start, Text: line})
+		}
+	}
+	return
+}
+
+func parseCueTime(value string) time.Duration {
+	value = strings.ReplaceAll(value, ",", ".")
+	hms, fraction, _ := strings.Cut(value, ".")
+	ret := parseClock(hms)
+	millis, _ := strconv.Atoi((fraction + "00")[:3])
+	return ret + time.Duration(millis)*time.Millisecond
+}
+
+// parseClock parses hh:mm:ss or mm:ss
+func parseClock(value string) (ret time.Duration) {
+	for _, part := range strings.Split(value, ":") {
+		n, _ := strconv.Atoi(part)
+		ret = ret*60 + time.Duration(n)
+	}
+	return ret * time.Second
+}
+
+func clock(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
@@ -0,0 +1,223 @@
+package meetings
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+// see https://developers.zoom.us/docs/api/cloud-recording for the API
+var (
+	zoomURL      = "https://api.zoom.us/v2"
+	zoomTokenURL = "https://zoom.us/oauth/token"
+)
+
+// zoomLatestDays is how far back the latest recording with a transcript is
+// looked for
+const zoomLatestDays = 30
+
+var (
+	httpClient = &http.Client{Timeout: 60 * time.Second}
+
+	// zoomJoinRegex matches the meeting ids of the join links of Zoom
+	zoomJoinRegex = regexp.MustCompile(`/j/(\d+)`)
+)
+
+// Zoom reads the transcripts of the cloud recordings of Zoom meetings, with
+// a Server-to-Server OAuth app of the account
+type Zoom struct {
+	*plugins.PluginBase
+	AccountID    *plugins.SetupQuestion
+	ClientID     *plugins.SetupQuestion
+	ClientSecret *plugins.SetupQuestion
+}
+
+func NewZoom() (ret *Zoom) {
+	label := "Zoom"
+
+	ret = &Zoom{
+		PluginBase: &plugins.PluginBase{
+			Name:             i18n.T("zoom_label"),
+			SetupDescription: i18n.T("zoom_setup_description") + " " + i18n.T("optional_marker"),
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+	}
+
+	ret.AccountID = ret.AddSetupQuestion("Account ID", false)
+	ret.ClientID = ret.AddSetupQuestion("Client ID", false)
+	ret.ClientSecret = ret.AddSetupQuestion("Client Secret", false)
+
+	return
+}
+
+type zoomMeeting struct {
+	Topic          string    `json:"topic"`
+	StartTime      time.Time `json:"start_time"`
+	Duration       int       `json:"duration"`
+	RecordingFiles []struct {
+		FileType    string `json:"file_type"`
+		Status      string `json:"status"`
+		DownloadURL string `json:"download_url"`
+	} `json:"recording_files"`
+}
+
+// transcriptURL returns the download URL of the transcript of the meeting,
+// empty while there is none
+func (o *zoomMeeting) transcriptURL() string {
+	for _, file := range o.RecordingFiles {
+		if file.FileType == "TRANSCRIPT" && (file.Status == "" || file.Status == "completed") {
+			return file.DownloadURL
+		}
+	}
+	return ""
+}
+
+// Transcript returns the transcript of the cloud recording of the meeting,
+// given by id, UUID or join link, or of the latest recording with a
+// transcript when the meeting is empty
+func (o *Zoom) Transcript(meeting string) (ret Transcript, err error) {
+	if o.AccountID.Value == "" || o.ClientID.Value == "" || o.ClientSecret.Value == "" {
+		return ret, errors.New(i18n.T("zoom_not_configured"))
+	}
+	var token string
+	if token, err = o.token(); err != nil {
+		return
+	}
+	var recording *zoomMeeting
+	if recording, err = o.recording(token, strings.TrimSpace(meeting)); err != nil {
+		return
+	}
+	var captions string
+	if captions, err = zoomGet(token, recording.transcriptURL()); err != nil {
+		return
+	}
+	ret = Transcript{
+		Title:    recording.Topic,
+		Start:    recording.StartTime,
+		Duration: time.Duration(recording.Duration) * time.Minute,
+	}
+	ret.Utterances, err = ParseCaptions(captions)
+	return
+}
+
+// recording returns the cloud recording of the meeting, which has a
+// transcript
+func (o *Zoom) recording(token, meeting string) (ret *zoomMeeting, err error) {
+	if meeting == "" {
+		var resp struct {
+			Meetings []zoomMeeting `json:"meetings"`
+		}
+		params := url.Values{
+			"from":      {time.Now().AddDate(0, 0, -zoomLatestDays).Format(time.DateOnly)},
+			"to":        {time.Now().Format(time.DateOnly)},
+			"page_size": {"100"},
+		}
+		if err = zoomJSON(token, zoomURL+"/users/me/recordings?"+params.Encode(), &resp); err != nil {
+			return
+		}
+		for i := range resp.Meetings {
+			if resp.Meetings[i].transcriptURL() != "" && (ret == nil || resp.Meetings[i].StartTime.After(ret.StartTime)) {
+				ret = &resp.Meetings[i]
+			}
+		}
+		if ret == nil {
+			return nil, fmt.Errorf(i18n.T("zoom_error_no_recent_transcript"), zoomLatestDays)
+		}
+		return
+	}
+
+	id := meeting
+	if match := zoomJoinRegex.FindStringSubmatch(meeting); match != nil {
+		id = match[1]
+	}
+	id = strings.ReplaceAll(id, " ", "")
+	// The UUIDs starting with / or containing // are encoded twice
+	if strings.HasPrefix(id, "/") || strings.Contains(id, "//") {
+		id = url.PathEscape(id)
+	}
+	ret = &zoomMeeting{}
+	if err = zoomJSON(token, zoomURL+"/meetings/"+url.PathEscape(id)+"/recordings", ret); err != nil {
+		return
+	}
+	if ret.transcriptURL() == "" {
+		return nil, fmt.Errorf(i18n.T("zoom_error_no_transcript"), meeting)
+	}
+	return
+}
+
+// token returns an access token of the Server-to-Server OAuth app
+func (o *Zoom) token() (ret string, err error) {
+	params := url.Values{"grant_type": {"account_credentials"}, "account_id": {o.AccountID.Value}}
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodPost, zoomTokenURL+"?"+params.Encode(), nil); err != nil {
+		return
+	}
+	req.SetBasicAuth(o.ClientID.Value, o.ClientSecret.Value)
+	var data []byte
+	if data, err = zoomDo(req); err != nil {
+		return
+	}
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf(i18n.T("meetings_error_request"), "Zoom", err)
+	}
+	return resp.AccessToken, nil
+}
+
+func zoomJSON(token, requestURL string, target any) (err error) {
+	var data string
+	if data, err = zoomGet(token, requestURL); err != nil {
+		return
+	}
+	if err = json.Unmarshal([]byte(data), target); err != nil {
+		return fmt.Errorf(i18n.T("meetings_error_request"), "Zoom", err)
+	}
+	return
+}
+
+func zoomGet(token, requestURL string) (ret string, err error) {
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodGet, requestURL, nil); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var data []byte
+	data, err = zoomDo(req)
+	return string(data), err
+}
+
+func zoomDo(req *http.Request) (ret []byte, err error) {
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		return nil, fmt.Errorf(i18n.T("meetings_error_request"), "Zoom", err)
+	}
+	defer resp.Body.Close()
+	if ret, err = io.ReadAll(resp.Body); err != nil {
+		return nil, fmt.Errorf(i18n.T("meetings_error_request"), "Zoom", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// The errors of the API explain themselves in their message
+		var apiError struct {
+			Message string `json:"message"`
+			Reason  string `json:"reason"`
+		}
+		message := strings.TrimSpace(string(ret[:min(len(ret), 1024)]))
+		if json.Unmarshal(ret, &apiError) == nil && (apiError.Message != "" || apiError.Reason != "") {
+			message = cmp.Or(apiError.Message, apiError.Reason)
+		}
+		return nil, fmt.Errorf(i18n.T("meetings_error_status"), "Zoom", resp.StatusCode, message)
+	}
+	return
+}
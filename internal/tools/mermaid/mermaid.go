@@ -0,0 +1,127 @@
+// Package mermaid finds the Mermaid diagrams of the responses and renders
+// them locally with the Mermaid CLI (mmdc).
+package mermaid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// Formats are the extensions of the files the diagrams are rendered to
+var Formats = []string{".svg", ".png", ".pdf"}
+
+// diagramTypes are the keywords starting Mermaid diagrams
+var diagramTypes = []string{
+	"graph", "flowchart", "sequenceDiagram", "classDiagram", "stateDiagram", "stateDiagram-v2",
+	"erDiagram", "journey", "gantt", "pie", "quadrantChart", "requirementDiagram", "gitGraph",
+	"C4Context", "C4Container", "C4Component", "C4Dynamic", "C4Deployment", "mindmap", "timeline",
+	"zenuml", "sankey-beta", "xychart-beta", "block-beta", "packet-beta", "kanban", "architecture-beta",
+}
+
+var (
+	fenceRegex = regexp.MustCompile("(?ms)^[ \t]*(```+|~~~+)[ \t]*mermaid[ \t]*\n(.*?)\n[ \t]*(```+|~~~+)[ \t]*$")
+	// The headings and upper case section titles ending the diagram of a
+	// response made of a raw diagram followed by explanations
+	sectionRegex = regexp.MustCompile(`^(#{1,6} |[A-Z][A-Z ]{3,}:?$)`)
+)
+
+// Diagrams returns the Mermaid diagrams of the text: its mermaid code
+// blocks, or else the diagram the text starts with, as patterns like
+// create_mermaid_visualization answer without code blocks
+func Diagrams(text string) (ret []string) {
+	for _, match := range fenceRegex.FindAllStringSubmatch(text, -1) {
+		if diagram := strings.TrimSpace(match[2]); diagram != "" {
+			ret = append(ret, diagram)
+		}
+	}
+	if len(ret) > 0 {
+		return
+	}
+
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	first := 0
+	// The diagram can start with its configuration and comments
+	for first < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[first]), "%%") {
+		first++
+	}
+	if first == len(lines) || !isDiagramStart(lines[first]) {
+		return nil
+	}
+	end := len(lines)
+	for i := first + 1; i < len(lines); i++ {
+		if sectionRegex.MatchString(lines[i]) {
+			end = i
+			break
+		}
+	}
+	return []string{strings.TrimSpace(strings.Join(lines[:end], "\n"))}
+}
+
+func isDiagramStart(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 0 && slices.Contains(diagramTypes, strings.TrimSuffix(fields[0], ":"))
+}
+
+// CheckOutput returns an error when the diagrams cannot be rendered to the
+// file, by its extension
+func CheckOutput(output string) error {
+	if !slices.Contains(Formats, strings.ToLower(filepath.Ext(output))) {
+		return fmt.Errorf(i18n.T("mermaid_error_format"), output, strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+// OutputPath returns the file the diagram at the index is rendered to: the
+// output file for the first one, then out-2.svg, out-3.svg and so on
+func OutputPath(output string, index int) string {
+	if index == 0 {
+		return output
+	}
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + "-" + strconv.Itoa(index+1) + ext
+}
+
+// Render renders the diagram to the SVG, PNG or PDF file with mmdc,
+// replacing the file of an earlier run
+func Render(diagram, output string) (err error) {
+	var path string
+	if path, err = exec.LookPath("mmdc"); err != nil {
+		return errors.New(i18n.T("mermaid_error_mmdc_not_found"))
+	}
+	var dir string
+	if dir, err = os.MkdirTemp("", "fabric-mermaid-"); err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "diagram.mmd")
+	if err = os.WriteFile(input, []byte(diagram+"\n"), 0o600); err != nil {
+		return
+	}
+
+	args := []string{"--quiet", "--input", input, "--output", output}
+	// Chrome refuses to start sandboxed as root, as in containers
+	if os.Geteuid() == 0 {
+		config := filepath.Join(dir, "puppeteer.json")
+		if err = os.WriteFile(config, []byte(`{"args":["--no-sandbox"]}`), 0o600); err != nil {
+			return
+		}
+		args = append(args, "--puppeteerConfigFile", config)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf(i18n.T("mermaid_error_rendering"), err, strings.TrimSpace(stderr.String()))
+	}
+	return
+}
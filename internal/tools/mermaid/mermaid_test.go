@@ -0,0 +1,90 @@
+package mermaid
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiagrams(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "code blocks",
+			text: "Here:\n\n```mermaid\ngraph TD\n  A --> B\n```\n\nand\n\n~~~ mermaid\npie\n  \"a\" : 1\n~~~\n\n```go\nfmt.Println()\n```",
+			want: []string{"graph TD\n  A --> B", "pie\n  \"a\" : 1"},
+		},
+		{
+			name: "raw diagram followed by an explanation",
+			text: "%%{init: {'theme': 'dark'}}%%\nflowchart LR\n  A[Input] --> B[Output]\n\n  B --> C\n\nVISUAL EXPLANATION\n\n- Input becomes output",
+			want: []string{"%%{init: {'theme': 'dark'}}%%\nflowchart LR\n  A[Input] --> B[Output]\n\n  B --> C"},
+		},
+		{
+			name: "raw diagram followed by a heading",
+			text: "sequenceDiagram\n  Alice->>Bob: Hi\n# Notes\nmore",
+			want: []string{"sequenceDiagram\n  Alice->>Bob: Hi"},
+		},
+		{
+			name: "no diagram",
+			text: "graphs are nice\n\n```\ngraph TD\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diagrams(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("Diagrams() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputPath(t *testing.T) {
+	if err := CheckOutput("out.SVG"); err != nil {
+		t.Errorf("CheckOutput(out.SVG) error = %v", err)
+	}
+	if err := CheckOutput("out.jpg"); err == nil {
+		t.Errorf("CheckOutput(out.jpg) expected an error")
+	}
+	if got := OutputPath("docs/flow.svg", 0); got != "docs/flow.svg" {
+		t.Errorf("OutputPath(0) = %q", got)
+	}
+	if got := OutputPath("docs/flow.svg", 2); got != "docs/flow-3.svg" {
+		t.Errorf("OutputPath(2) = %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake mmdc is a shell script")
+	}
+	dir := t.TempDir()
+	// The fake mmdc copies its input, given after --input, to its output
+	script := "#!/bin/sh\nwhile [ $# -gt 0 ]; do case \"$1\" in --input) in=$2;; --output) out=$2;; esac; shift; done\n" +
+		"case \"$(cat \"$in\")\" in *bad*) echo 'Parse error' >&2; exit 1;; esac\ncat \"$in\" > \"$out\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "mmdc"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	output := filepath.Join(dir, "flow.svg")
+	if err := Render("graph TD\n  A --> B", output); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got, _ := os.ReadFile(output); string(got) != "graph TD\n  A --> B\n" {
+		t.Errorf("rendered %q", got)
+	}
+	if err := Render("graph bad", output); err == nil || !strings.Contains(err.Error(), "Parse error") {
+		t.Errorf("Render() of an invalid diagram error = %v, want the mmdc error", err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if err := Render("graph TD", output); err == nil || !strings.Contains(err.Error(), "mermaid-cli") {
+		t.Errorf("Render() without mmdc error = %v, want how to install it", err)
+	}
+}
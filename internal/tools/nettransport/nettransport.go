@@ -0,0 +1,93 @@
+// Package nettransport sets up the default HTTP transport, used by the AI
+// vendor clients and the other HTTP requests of fabric, for corporate networks:
+// a proxy and the certificates of a custom certificate authority.
+package nettransport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Configure makes http.DefaultTransport send the requests through the proxy,
+// the hosts of NO_PROXY excepted, and trust the certificates of the PEM file
+// besides those of the system. The transport is changed in place, so that the
+// clients keeping or cloning it get the settings. Without a proxy, HTTPS_PROXY,
+// HTTP_PROXY and NO_PROXY are used.
+func Configure(proxy, caCertFile string) (err error) {
+	if proxy == "" && caCertFile == "" {
+		return
+	}
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return errors.New(i18n.T("nettransport_default_replaced"))
+	}
+
+	if proxy != "" {
+		var proxyFunc func(*url.URL) (*url.URL, error)
+		if proxyFunc, err = ProxyFunc(proxy, os.Getenv); err != nil {
+			return
+		}
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+
+	if caCertFile != "" {
+		var pool *x509.CertPool
+		if pool, err = CertPool(caCertFile); err != nil {
+			return
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	return
+}
+
+// proxySchemes are the schemes of the proxies net/http supports
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ProxyFunc returns the proxy of the requests: the proxy URL, a scheme-less
+// host being taken as http://, except for the hosts of the NO_PROXY variable
+func ProxyFunc(proxy string, getenv func(string) string) (ret func(*url.URL) (*url.URL, error), err error) {
+	target := proxy
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Hostname() == "" || !slices.Contains(proxySchemes, parsed.Scheme) {
+		return nil, fmt.Errorf(i18n.T("nettransport_invalid_proxy"), proxy)
+	}
+	noProxy := getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = getenv("no_proxy")
+	}
+	config := &httpproxy.Config{HTTPProxy: parsed.String(), HTTPSProxy: parsed.String(), NoProxy: noProxy}
+	return config.ProxyFunc(), nil
+}
+
+// CertPool returns the certificates of the system with those of the PEM file
+func CertPool(caCertFile string) (ret *x509.CertPool, err error) {
+	var pem []byte
+	if pem, err = os.ReadFile(caCertFile); err != nil {
+		return
+	}
+	if ret, err = x509.SystemCertPool(); err != nil || ret == nil {
+		ret, err = x509.NewCertPool(), nil
+	}
+	if !ret.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf(i18n.T("nettransport_no_certificates"), caCertFile)
+	}
+	return
+}
@@ -0,0 +1,91 @@
+package nettransport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProxyFunc(t *testing.T) {
+	env := map[string]string{"NO_PROXY": "localhost,.internal"}
+	proxyFunc, err := ProxyFunc("proxy.corp:3128", func(name string) string { return env[name] })
+	if err != nil {
+		t.Fatalf("ProxyFunc() error = %v", err)
+	}
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"https://api.openai.com/v1/responses", "http://proxy.corp:3128"},
+		{"http://example.com/", "http://proxy.corp:3128"},
+		{"http://ollama.internal:11434/api/chat", ""},
+		{"http://localhost:11434/api/chat", ""},
+	}
+	for _, tt := range tests {
+		target, _ := url.Parse(tt.target)
+		got, err := proxyFunc(target)
+		if err != nil {
+			t.Fatalf("proxy of %s: error = %v", tt.target, err)
+		}
+		if (got == nil && tt.want != "") || (got != nil && got.String() != tt.want) {
+			t.Errorf("proxy of %s = %v, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestProxyFuncInvalid(t *testing.T) {
+	for _, proxy := range []string{"http://", "ftp://proxy.corp", ":3128"} {
+		if _, err := ProxyFunc(proxy, func(string) string { return "" }); err == nil {
+			t.Errorf("ProxyFunc(%q) succeeded", proxy)
+		}
+	}
+}
+
+func TestCertPool(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	if err = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = CertPool(caFile); err != nil {
+		t.Errorf("CertPool() error = %v", err)
+	}
+
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err = os.WriteFile(notPEM, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = CertPool(notPEM); err == nil {
+		t.Error("CertPool() of a file without certificate succeeded")
+	}
+	if _, err = CertPool(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("CertPool() of a missing file succeeded")
+	}
+}
@@ -0,0 +1,113 @@
+package notion
+
+import (
+	"encoding/json"
+)
+
+// maxTextLength is the longest content of a rich text object
+const maxTextLength = 2000
+
+// RichText is a span of text with its annotations and link. Read pages also
+// have mentions and equations, of which the plain text is kept.
+type RichText struct {
+	Type        string       `json:"type"`
+	Text        *TextContent `json:"text,omitempty"`
+	Annotations Annotations  `json:"annotations"`
+	PlainText   string       `json:"plain_text,omitempty"`
+	Href        string       `json:"href,omitempty"`
+}
+
+type TextContent struct {
+	Content string `json:"content"`
+	Link    *Link  `json:"link,omitempty"`
+}
+
+type Link struct {
+	URL string `json:"url"`
+}
+
+type Annotations struct {
+	Bold          bool `json:"bold,omitempty"`
+	Italic        bool `json:"italic,omitempty"`
+	Strikethrough bool `json:"strikethrough,omitempty"`
+	Code          bool `json:"code,omitempty"`
+}
+
+// text returns the text of the span, as read or as written
+func (o RichText) text() string {
+	if o.Text != nil {
+		return o.Text.Content
+	}
+	return o.PlainText
+}
+
+// link returns the URL the span links to, if any
+func (o RichText) link() string {
+	if o.Text != nil && o.Text.Link != nil {
+		return o.Text.Link.URL
+	}
+	return o.Href
+}
+
+// Block is a block of a page, its content being under the key of its type
+// in the JSON of the API
+type Block struct {
+	ID          string
+	Type        string
+	HasChildren bool
+	Content     BlockContent
+}
+
+// BlockContent holds the fields of the block types converted from and to
+// Markdown
+type BlockContent struct {
+	RichText []RichText `json:"rich_text,omitempty"`
+	// Checked is set for the to-do blocks
+	Checked  *bool      `json:"checked,omitempty"`
+	Language string     `json:"language,omitempty"`
+	Caption  []RichText `json:"caption,omitempty"`
+	// URL is that of bookmarks, embeds and link previews
+	URL        string    `json:"url,omitempty"`
+	Expression string    `json:"expression,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	Icon       *Icon     `json:"icon,omitempty"`
+	External   *FileLink `json:"external,omitempty"`
+	File       *FileLink `json:"file,omitempty"`
+	// TableWidth, HasColumnHeader and Cells are those of tables and their rows
+	TableWidth      int          `json:"table_width,omitempty"`
+	HasColumnHeader bool         `json:"has_column_header,omitempty"`
+	Cells           [][]RichText `json:"cells,omitempty"`
+	Children        []Block      `json:"children,omitempty"`
+}
+
+type Icon struct {
+	Emoji string `json:"emoji,omitempty"`
+}
+
+type FileLink struct {
+	URL string `json:"url"`
+}
+
+func (o Block) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"object": "block", "type": o.Type, o.Type: o.Content})
+}
+
+func (o *Block) UnmarshalJSON(data []byte) (err error) {
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return
+	}
+	var header struct {
+		ID          string `json:"id"`
+		Type        string `json:"type"`
+		HasChildren bool   `json:"has_children"`
+	}
+	if err = json.Unmarshal(data, &header); err != nil {
+		return
+	}
+	o.ID, o.Type, o.HasChildren = header.ID, header.Type, header.HasChildren
+	if content, ok := fields[o.Type]; ok {
+		return json.Unmarshal(content, &o.Content)
+	}
+	return
+}
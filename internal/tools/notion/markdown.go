@@ -0,0 +1,425 @@
+package notion
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	fenceRegex     = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+#-]*)")
+	headingRegex   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ruleRegex      = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	quoteRegex     = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	taskRegex      = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	bulletRegex    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedRegex   = regexp.MustCompile(`^(\s*)\d+[.)]\s+(.*)$`)
+	imageLineRegex = regexp.MustCompile(`^\s*!\[([^\]]*)\]\((https?://[^)\s]+)[^)]*\)\s*$`)
+	tableRowRegex  = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	tableRuleRegex = regexp.MustCompile(`^\s*\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*\|?\s*$`)
+
+	// inlineRegex matches the code spans, links and emphasis of a line, in
+	// the order they take precedence
+	inlineRegex = regexp.MustCompile("`([^`]+)`" +
+		`|\[([^\]]+)\]\(([^)\s]+)[^)]*\)` +
+		`|\*\*([^*]+)\*\*|__([^_]+)__` +
+		`|~~([^~]+)~~` +
+		`|\*([^*\s][^*]*?)\*|\b_([^_\s][^_]*?)_\b`)
+)
+
+// languages are the languages of the code blocks of Notion, by the names
+// used in Markdown
+var languages = map[string]string{
+	"": "plain text", "text": "plain text", "txt": "plain text", "plaintext": "plain text",
+	"sh": "shell", "zsh": "shell", "console": "shell", "bash": "bash", "shell": "shell",
+	"js": "javascript", "jsx": "javascript", "ts": "typescript", "tsx": "typescript", "py": "python",
+	"golang": "go", "rb": "ruby", "rs": "rust", "yml": "yaml", "md": "markdown", "cpp": "c++",
+	"cs": "c#", "csharp": "c#", "dockerfile": "docker", "kt": "kotlin", "ps1": "powershell",
+}
+
+// notionLanguages are the other languages Notion knows under their Markdown name
+var notionLanguages = []string{
+	"c", "c++", "c#", "css", "dart", "diff", "docker", "elixir", "go", "graphql", "haskell", "html", "java",
+	"javascript", "json", "kotlin", "latex", "lua", "makefile", "markdown", "mermaid", "nix", "php",
+	"powershell", "protobuf", "python", "r", "ruby", "rust", "scala", "scss", "sql", "swift", "toml",
+	"typescript", "xml", "yaml",
+}
+
+// notionLanguage returns the language of a code block of the language of
+// Markdown, plain text for those Notion does not know
+func notionLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if notion, ok := languages[lang]; ok {
+		return notion
+	}
+	if slices.Contains(notionLanguages, lang) {
+		return lang
+	}
+	return "plain text"
+}
+
+// MarkdownToBlocks converts Markdown to the blocks of a page: headings,
+// paragraphs, lists with one level of nesting, to-dos, quotes, code blocks,
+// dividers, tables and images
+func MarkdownToBlocks(markdown string) (ret []Block) {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	// listIndent is the indentation of the last top level list item, the
+	// items indented more being its children
+	listIndent := -1
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			ret = append(ret, textBlock("paragraph", strings.Join(paragraph, "\n")))
+			paragraph = nil
+			listIndent = -1
+		}
+	}
+	add := func(indent int, block Block) {
+		if listIndent >= 0 && indent > listIndent && len(ret) > 0 {
+			parent := &ret[len(ret)-1]
+			parent.Content.Children = append(parent.Content.Children, block)
+			return
+		}
+		ret = append(ret, block)
+		listIndent = -1
+		if isListItem(block.Type) {
+			listIndent = indent
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if match := fenceRegex.FindStringSubmatch(line); match != nil {
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), match[1][:3]); i++ {
+				code = append(code, lines[i])
+			}
+			add(0, Block{Type: "code", Content: BlockContent{
+				RichText: splitText([]RichText{plainText(strings.Join(code, "\n"))}),
+				Language: notionLanguage(match[2]),
+			}})
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if tableRowRegex.MatchString(line) && i+1 < len(lines) && tableRuleRegex.MatchString(lines[i+1]) {
+			flush()
+			rows := [][]string{tableCells(line)}
+			for i += 2; i < len(lines) && tableRowRegex.MatchString(lines[i]); i++ {
+				rows = append(rows, tableCells(lines[i]))
+			}
+			i--
+			add(0, tableBlock(rows))
+			continue
+		}
+
+		var block Block
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		switch {
+		case headingRegex.MatchString(line):
+			match := headingRegex.FindStringSubmatch(line)
+			block = textBlock("heading_"+strconv.Itoa(min(len(match[1]), 3)), match[2])
+			indent = 0
+		case ruleRegex.MatchString(line):
+			block = Block{Type: "divider"}
+			indent = 0
+		case quoteRegex.MatchString(line):
+			quote := []string{quoteRegex.FindStringSubmatch(line)[1]}
+			for i+1 < len(lines) && quoteRegex.MatchString(lines[i+1]) {
+				i++
+				quote = append(quote, quoteRegex.FindStringSubmatch(lines[i])[1])
+			}
+			block = textBlock("quote", strings.Join(quote, "\n"))
+		case taskRegex.MatchString(line):
+			match := taskRegex.FindStringSubmatch(line)
+			block = textBlock("to_do", match[3])
+			checked := match[2] != " "
+			block.Content.Checked = &checked
+		case bulletRegex.MatchString(line):
+			block = textBlock("bulleted_list_item", bulletRegex.FindStringSubmatch(line)[2])
+		case orderedRegex.MatchString(line):
+			block = textBlock("numbered_list_item", orderedRegex.FindStringSubmatch(line)[2])
+		case imageLineRegex.MatchString(line):
+			match := imageLineRegex.FindStringSubmatch(line)
+			block = Block{Type: "image", Content: BlockContent{External: &FileLink{URL: match[2]}}}
+			if match[1] != "" {
+				block.Content.Caption = []RichText{plainText(match[1])}
+			}
+		default:
+			// The lines continuing a list item are part of its text
+			if len(paragraph) == 0 && listIndent >= 0 && indent > listIndent && len(ret) > 0 {
+				last := &ret[len(ret)-1]
+				if children := last.Content.Children; len(children) > 0 {
+					last = &children[len(children)-1]
+				}
+				last.Content.RichText = append(last.Content.RichText, RichTexts("\n"+strings.TrimSpace(line))...)
+				continue
+			}
+			paragraph = append(paragraph, strings.TrimSpace(line))
+			continue
+		}
+		flush()
+		add(indent, block)
+	}
+	flush()
+	return
+}
+
+func isListItem(blockType string) bool {
+	return blockType == "bulleted_list_item" || blockType == "numbered_list_item" || blockType == "to_do"
+}
+
+func textBlock(blockType, text string) Block {
+	return Block{Type: blockType, Content: BlockContent{RichText: RichTexts(text)}}
+}
+
+func tableCells(line string) []string {
+	cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+	return cells
+}
+
+// tableBlock returns the table of the rows, the first one being its header
+func tableBlock(rows [][]string) Block {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	table := Block{Type: "table", Content: BlockContent{TableWidth: width, HasColumnHeader: true}}
+	for _, row := range rows {
+		cells := make([][]RichText, width)
+		for i := range cells {
+			cells[i] = []RichText{}
+			if i < len(row) {
+				cells[i] = RichTexts(row[i])
+			}
+		}
+		table.Content.Children = append(table.Content.Children, Block{Type: "table_row", Content: BlockContent{Cells: cells}})
+	}
+	return table
+}
+
+func plainText(text string) RichText {
+	return RichText{Type: "text", Text: &TextContent{Content: text}}
+}
+
+// RichTexts converts the Markdown of a line to rich text: code spans, links,
+// bold, italic and strikethrough text
+func RichTexts(markdown string) []RichText {
+	return splitText(richTexts(markdown, Annotations{}, ""))
+}
+
+func richTexts(markdown string, annotations Annotations, link string) (ret []RichText) {
+	add := func(text string, annotations Annotations, link string) {
+		if text == "" {
+			return
+		}
+		span := RichText{Type: "text", Text: &TextContent{Content: text}, Annotations: annotations}
+		if link != "" {
+			span.Text.Link = &Link{URL: link}
+		}
+		ret = append(ret, span)
+	}
+	last := 0
+	for _, match := range inlineRegex.FindAllStringSubmatchIndex(markdown, -1) {
+		add(markdown[last:match[0]], annotations, link)
+		last = match[1]
+		group := func(n int) string { return markdown[match[2*n]:match[2*n+1]] }
+		nested := annotations
+		switch {
+		case match[2] >= 0:
+			nested.Code = true
+			add(group(1), nested, link)
+		case match[4] >= 0:
+			ret = append(ret, richTexts(group(2), annotations, group(3))...)
+		case match[8] >= 0 || match[10] >= 0:
+			nested.Bold = true
+			ret = append(ret, richTexts(group(map[bool]int{true: 4, false: 5}[match[8] >= 0]), nested, link)...)
+		case match[12] >= 0:
+			nested.Strikethrough = true
+			ret = append(ret, richTexts(group(6), nested, link)...)
+		default:
+			nested.Italic = true
+			ret = append(ret, richTexts(group(map[bool]int{true: 7, false: 8}[match[14] >= 0]), nested, link)...)
+		}
+	}
+	add(markdown[last:], annotations, link)
+	return
+}
+
+// splitText splits the spans longer than Notion accepts
+func splitText(spans []RichText) (ret []RichText) {
+	for _, span := range spans {
+		content := span.Text.Content
+		for len(content) > maxTextLength {
+			cut := maxTextLength
+			for !utf8.RuneStart(content[cut]) {
+				cut--
+			}
+			part := span
+			part.Text = &TextContent{Content: content[:cut], Link: span.Text.Link}
+			ret = append(ret, part)
+			content = content[cut:]
+		}
+		span.Text = &TextContent{Content: content, Link: span.Text.Link}
+		ret = append(ret, span)
+	}
+	return
+}
+
+// BlocksToMarkdown converts the blocks of a page, with their children, to
+// Markdown. The blocks without a Markdown equivalent keep their text.
+func BlocksToMarkdown(blocks []Block) string {
+	var sb strings.Builder
+	writeBlocks(&sb, blocks, "")
+	return strings.TrimSpace(sb.String())
+}
+
+func writeBlocks(sb *strings.Builder, blocks []Block, indent string) {
+	number := 0
+	for i, block := range blocks {
+		if block.Type == "numbered_list_item" {
+			number++
+		} else {
+			number = 0
+		}
+		// Blocks are separated by blank lines, but for the items of a list
+		if i > 0 && !(isListItem(block.Type) && isListItem(blocks[i-1].Type)) {
+			sb.WriteString("\n")
+		}
+		content := block.Content
+		text := markdownText(content.RichText)
+		childIndent := indent
+		switch block.Type {
+		case "heading_1", "heading_2", "heading_3":
+			sb.WriteString(indent + strings.Repeat("#", int(block.Type[len(block.Type)-1]-'0')) + " " + text + "\n")
+		case "bulleted_list_item", "toggle":
+			sb.WriteString(indent + "- " + indentLines(text, indent+"  ") + "\n")
+			childIndent += "  "
+		case "numbered_list_item":
+			prefix := strconv.Itoa(number) + ". "
+			sb.WriteString(indent + prefix + indentLines(text, indent+strings.Repeat(" ", len(prefix))) + "\n")
+			childIndent += strings.Repeat(" ", len(prefix))
+		case "to_do":
+			box := "[ ] "
+			if content.Checked != nil && *content.Checked {
+				box = "[x] "
+			}
+			sb.WriteString(indent + "- " + box + indentLines(text, indent+"  ") + "\n")
+			childIndent += "  "
+		case "quote", "callout":
+			if content.Icon != nil && content.Icon.Emoji != "" {
+				text = content.Icon.Emoji + " " + text
+			}
+			sb.WriteString(indentLines(indent+"> "+text, indent+"> ") + "\n")
+		case "code":
+			lang := content.Language
+			if lang == "plain text" {
+				lang = ""
+			}
+			sb.WriteString(indent + "```" + strings.ReplaceAll(lang, " ", "") + "\n" +
+				indent + indentLines(plainTexts(content.RichText), indent) + "\n" + indent + "```\n")
+		case "divider":
+			sb.WriteString(indent + "---\n")
+		case "equation":
+			sb.WriteString(indent + "$$" + content.Expression + "$$\n")
+		case "image", "video", "file", "pdf":
+			url := ""
+			if content.External != nil {
+				url = content.External.URL
+			} else if content.File != nil {
+				url = content.File.URL
+			}
+			caption := plainTexts(content.Caption)
+			if block.Type == "image" {
+				sb.WriteString(indent + "![" + caption + "](" + url + ")\n")
+			} else {
+				sb.WriteString(indent + "[" + cmp.Or(caption, url) + "](" + url + ")\n")
+			}
+		case "bookmark", "embed", "link_preview":
+			sb.WriteString(indent + content.URL + "\n")
+		case "child_page", "child_database":
+			sb.WriteString(indent + "**" + content.Title + "**\n")
+		case "table":
+			writeTable(sb, block, indent)
+			continue
+		default:
+			if text != "" {
+				sb.WriteString(indent + indentLines(text, indent) + "\n")
+			}
+		}
+		if len(content.Children) > 0 {
+			if !isListItem(block.Type) && block.Type != "toggle" {
+				sb.WriteString("\n")
+			}
+			writeBlocks(sb, content.Children, childIndent)
+		}
+	}
+}
+
+func writeTable(sb *strings.Builder, table Block, indent string) {
+	for i, row := range table.Content.Children {
+		cells := make([]string, len(row.Content.Cells))
+		for j, cell := range row.Content.Cells {
+			cells[j] = strings.ReplaceAll(markdownText(cell), "|", `\|`)
+		}
+		sb.WriteString(indent + "| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString(indent + "|" + strings.Repeat(" --- |", len(cells)) + "\n")
+		}
+	}
+}
+
+// markdownText converts rich text to Markdown
+func markdownText(spans []RichText) string {
+	var sb strings.Builder
+	for _, span := range spans {
+		text := span.text()
+		// The emphasis markers stay around the text, not its spaces
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			sb.WriteString(text)
+			continue
+		}
+		lead, trail := text[:strings.Index(text, trimmed)], text[strings.Index(text, trimmed)+len(trimmed):]
+		a := span.Annotations
+		if a.Code {
+			trimmed = "`" + trimmed + "`"
+		}
+		if a.Bold {
+			trimmed = "**" + trimmed + "**"
+		}
+		if a.Italic {
+			trimmed = "_" + trimmed + "_"
+		}
+		if a.Strikethrough {
+			trimmed = "~~" + trimmed + "~~"
+		}
+		if link := span.link(); link != "" {
+			trimmed = "[" + trimmed + "](" + link + ")"
+		}
+		sb.WriteString(lead + trimmed + trail)
+	}
+	return sb.String()
+}
+
+func plainTexts(spans []RichText) string {
+	var sb strings.Builder
+	for _, span := range spans {
+		sb.WriteString(span.text())
+	}
+	return sb.String()
+}
+
+// indentLines indents the lines of the text after the first one
+func indentLines(text, indent string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+indent)
+}
@@ -0,0 +1,214 @@
+// Package notion reads the pages of Notion as Markdown input for the patterns
+// and appends the output of the patterns to pages as blocks.
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+// see https://developers.notion.com/reference for the API
+var notionURL = "https://api.notion.com/v1"
+
+const (
+	notionVersion = "2022-06-28"
+	// maxBlocks is the most blocks read or appended by a request
+	maxBlocks = 100
+)
+
+var (
+	httpClient = &http.Client{Timeout: 30 * time.Second}
+
+	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// pageIDRegex matches the id ending the path of the URL of a page, after
+	// its title
+	pageIDRegex = regexp.MustCompile(`([0-9a-fA-F]{32})$`)
+)
+
+// Notion reads and appends to the pages shared with its integration
+type Notion struct {
+	*plugins.PluginBase
+	APIKey *plugins.SetupQuestion
+}
+
+func NewNotion() (ret *Notion) {
+	label := "Notion"
+
+	ret = &Notion{
+		PluginBase: &plugins.PluginBase{
+			Name:             i18n.T("notion_label"),
+			SetupDescription: i18n.T("notion_setup_description") + " " + i18n.T("optional_marker"),
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+	}
+
+	ret.APIKey = ret.AddSetupQuestion("API Key", false)
+
+	return
+}
+
+// PageID returns the id of the page, given by id or by URL
+func PageID(page string) (string, error) {
+	page = strings.TrimSpace(page)
+	if uuidRegex.MatchString(page) {
+		return strings.ToLower(page), nil
+	}
+	path := page
+	if u, err := url.Parse(page); err == nil && u.Host != "" {
+		path = strings.TrimRight(u.Path, "/")
+	}
+	if match := pageIDRegex.FindString(path); match != "" {
+		id := strings.ToLower(match)
+		return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:], nil
+	}
+	return "", fmt.Errorf(i18n.T("notion_error_invalid_page"), page)
+}
+
+// ReadPage returns the page as Markdown, its title as first heading. The
+// child pages and databases are kept as their titles.
+func (o *Notion) ReadPage(page string) (ret string, err error) {
+	var id string
+	if id, err = o.pageID(page); err != nil {
+		return
+	}
+	var properties struct {
+		Properties map[string]struct {
+			Type  string     `json:"type"`
+			Title []RichText `json:"title"`
+		} `json:"properties"`
+	}
+	if err = o.do(http.MethodGet, "/pages/"+id, nil, &properties); err != nil {
+		return
+	}
+	var blocks []Block
+	if blocks, err = o.children(id); err != nil {
+		return
+	}
+	var title string
+	for _, property := range properties.Properties {
+		if property.Type == "title" {
+			title = strings.TrimSpace(plainTexts(property.Title))
+		}
+	}
+	ret = BlocksToMarkdown(blocks)
+	if title != "" {
+		ret = "# " + title + "\n\n" + ret
+	}
+	return strings.TrimSpace(ret), nil
+}
+
+// children returns the blocks of the parent, with their own children
+func (o *Notion) children(parent string) (ret []Block, err error) {
+	for cursor := ""; ; {
+		params := url.Values{"page_size": {fmt.Sprint(maxBlocks)}}
+		if cursor != "" {
+			params.Set("start_cursor", cursor)
+		}
+		var page struct {
+			Results    []Block `json:"results"`
+			HasMore    bool    `json:"has_more"`
+			NextCursor string  `json:"next_cursor"`
+		}
+		if err = o.do(http.MethodGet, "/blocks/"+parent+"/children?"+params.Encode(), nil, &page); err != nil {
+			return
+		}
+		for _, block := range page.Results {
+			// The child pages and databases are not part of the page
+			if block.HasChildren && block.Type != "child_page" && block.Type != "child_database" {
+				if block.Content.Children, err = o.children(block.ID); err != nil {
+					return
+				}
+			}
+			ret = append(ret, block)
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			return
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// AppendMarkdown appends the Markdown to the end of the page as blocks and
+// returns their count
+func (o *Notion) AppendMarkdown(page, markdown string) (count int, err error) {
+	var id string
+	if id, err = o.pageID(page); err != nil {
+		return
+	}
+	blocks := MarkdownToBlocks(markdown)
+	for start := 0; start < len(blocks); start += maxBlocks {
+		batch := blocks[start:min(start+maxBlocks, len(blocks))]
+		if err = o.do(http.MethodPatch, "/blocks/"+id+"/children", map[string]any{"children": batch}, nil); err != nil {
+			return
+		}
+		count += len(batch)
+	}
+	return
+}
+
+// CheckPage checks that the integration is configured and the page is an id
+// or URL, before a chat appends its output to it
+func (o *Notion) CheckPage(page string) (err error) {
+	_, err = o.pageID(page)
+	return
+}
+
+func (o *Notion) pageID(page string) (string, error) {
+	if o.APIKey.Value == "" {
+		return "", errors.New(i18n.T("notion_not_configured"))
+	}
+	return PageID(page)
+}
+
+func (o *Notion) do(method, path string, body, target any) (err error) {
+	var reader io.Reader
+	if body != nil {
+		var data []byte
+		if data, err = json.Marshal(body); err != nil {
+			return
+		}
+		reader = bytes.NewReader(data)
+	}
+	var req *http.Request
+	if req, err = http.NewRequest(method, notionURL+path, reader); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+o.APIKey.Value)
+	req.Header.Set("Notion-Version", notionVersion)
+	req.Header.Set("Content-Type", "application/json")
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		return fmt.Errorf(i18n.T("notion_error_request"), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		// The errors of the API explain themselves in their message
+		var apiError struct {
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiError) == nil && apiError.Message != "" {
+			message = apiError.Message
+		}
+		return fmt.Errorf(i18n.T("notion_error_status"), resp.StatusCode, message)
+	}
+	if target == nil {
+		return
+	}
+	if err = json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf(i18n.T("notion_error_request"), err)
+	}
+	return
+}
@@ -0,0 +1,167 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPageID(t *testing.T) {
+	want := "1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d"
+	for _, page := range []string{
+		"1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d",
+		"1A2B3C4D-5E6F-7A8B-9C0D-1E2F3A4B5C6D",
+		"https://www.notion.so/team/Weekly-Notes-1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d?pvs=4",
+		"https://www.notion.so/1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d/",
+	} {
+		if got, err := PageID(page); err != nil || got != want {
+			t.Errorf("PageID(%q) = %q, %v, want %q", page, got, err, want)
+		}
+	}
+	if _, err := PageID("https://www.notion.so/team/Weekly-Notes"); err == nil {
+		t.Errorf("PageID() of a URL without id expected an error")
+	}
+}
+
+func TestMarkdownToBlocks(t *testing.T) {
+	markdown := "# Title\n\nSome **bold** and `code` with a [link](https://go.dev).\n\n" +
+		"- one\n  - nested\n- two\n\n1. first\n\n- [x] done\n\n> quoted\n\n---\n\n" +
+		"```golang\nfmt.Println()\n```\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n![chart](https://example.com/chart.png)\n\n#### Deep"
+	blocks := MarkdownToBlocks(markdown)
+	var types []string
+	for _, block := range blocks {
+		types = append(types, block.Type)
+	}
+	want := "heading_1 paragraph bulleted_list_item bulleted_list_item numbered_list_item to_do quote divider code table image heading_3"
+	if strings.Join(types, " ") != want {
+		t.Fatalf("MarkdownToBlocks() types = %v, want %v", types, want)
+	}
+
+	paragraph := blocks[1].Content.RichText
+	if len(paragraph) != 7 || !paragraph[1].Annotations.Bold || !paragraph[3].Annotations.Code ||
+		paragraph[5].Text.Link == nil || paragraph[5].Text.Link.URL != "https://go.dev" {
+		t.Errorf("paragraph rich text = %+v", paragraph)
+	}
+	if children := blocks[2].Content.Children; len(children) != 1 || children[0].Content.RichText[0].Text.Content != "nested" {
+		t.Errorf("list item children = %+v", children)
+	}
+	if checked := blocks[5].Content.Checked; checked == nil || !*checked {
+		t.Errorf("to-do checked = %v", checked)
+	}
+	if blocks[8].Content.Language != "go" {
+		t.Errorf("code language = %q, want go", blocks[8].Content.Language)
+	}
+	if table := blocks[9].Content; table.TableWidth != 2 || len(table.Children) != 2 || !table.HasColumnHeader {
+		t.Errorf("table = %+v", table)
+	}
+
+	data, err := json.Marshal(blocks[5])
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if got := string(data); !strings.Contains(got, `"type":"to_do"`) || !strings.Contains(got, `"to_do":{"rich_text":`) || !strings.Contains(got, `"checked":true`) {
+		t.Errorf("Marshal() = %s", got)
+	}
+}
+
+func TestMarkdownToBlocksSplitsLongText(t *testing.T) {
+	blocks := MarkdownToBlocks(strings.Repeat("é", maxTextLength))
+	spans := blocks[0].Content.RichText
+	if len(spans) != 2 || len(spans[0].Text.Content) > maxTextLength || spans[0].Text.Content+spans[1].Text.Content != strings.Repeat("é", maxTextLength) {
+		t.Errorf("MarkdownToBlocks() of a long paragraph = %d spans", len(spans))
+	}
+}
+
+func TestBlocksToMarkdown(t *testing.T) {
+	var blocks []Block
+	data := `[
+		{"type": "heading_2", "heading_2": {"rich_text": [{"plain_text": "Notes"}]}},
+		{"type": "paragraph", "paragraph": {"rich_text": [{"plain_text": "See "}, {"plain_text": "the docs", "href": "https://go.dev", "annotations": {"bold": true}}]}},
+		{"type": "numbered_list_item", "numbered_list_item": {"rich_text": [{"plain_text": "first"}]}},
+		{"type": "numbered_list_item", "has_children": true, "numbered_list_item": {"rich_text": [{"plain_text": "second"}],
+			"children": [{"type": "bulleted_list_item", "bulleted_list_item": {"rich_text": [{"plain_text": "detail"}]}}]}},
+		{"type": "to_do", "to_do": {"rich_text": [{"plain_text": "ship"}], "checked": false}},
+		{"type": "callout", "callout": {"rich_text": [{"plain_text": "Careful"}], "icon": {"emoji": "⚠️"}}},
+		{"type": "code", "code": {"rich_text": [{"plain_text": "go test ./..."}], "language": "shell"}},
+		{"type": "table", "table": {"table_width": 2, "children": [
+			{"type": "table_row", "table_row": {"cells": [[{"plain_text": "a"}], [{"plain_text": "b"}]]}},
+			{"type": "table_row", "table_row": {"cells": [[{"plain_text": "1"}], [{"plain_text": "2"}]]}}]}},
+		{"type": "child_page", "child_page": {"title": "Archive"}}
+	]`
+	if err := json.Unmarshal([]byte(data), &blocks); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := "## Notes\n\nSee [**the docs**](https://go.dev)\n\n1. first\n2. second\n   - detail\n- [ ] ship\n\n" +
+		"> ⚠️ Careful\n\n```shell\ngo test ./...\n```\n\n| a | b |\n| --- | --- |\n| 1 | 2 |\n\n**Archive**"
+	if got := BlocksToMarkdown(blocks); got != want {
+		t.Errorf("BlocksToMarkdown() = %q, want %q", got, want)
+	}
+}
+
+func TestNotionReadAndAppend(t *testing.T) {
+	const id = "1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d"
+	var appended [][]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Notion-Version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"object": "error", "status": 401, "message": "API token is invalid."}`)
+			return
+		}
+		switch {
+		case r.URL.Path == "/pages/"+id:
+			fmt.Fprint(w, `{"properties": {"Name": {"type": "title", "title": [{"plain_text": "Weekly Notes"}]}}}`)
+		case r.URL.Path == "/blocks/"+id+"/children" && r.Method == http.MethodGet:
+			// The second page follows the cursor of the first one
+			if r.URL.Query().Get("start_cursor") == "" {
+				fmt.Fprint(w, `{"has_more": true, "next_cursor": "c2", "results": [
+					{"id": "t1", "type": "toggle", "has_children": true, "toggle": {"rich_text": [{"plain_text": "More"}]}}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"has_more": false, "results": [{"type": "paragraph", "paragraph": {"rich_text": [{"plain_text": "The end."}]}}]}`)
+		case r.URL.Path == "/blocks/t1/children":
+			fmt.Fprint(w, `{"results": [{"type": "paragraph", "paragraph": {"rich_text": [{"plain_text": "Hidden"}]}}]}`)
+		case r.URL.Path == "/blocks/"+id+"/children" && r.Method == http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			var request struct {
+				Children []json.RawMessage `json:"children"`
+			}
+			_ = json.Unmarshal(body, &request)
+			appended = append(appended, request.Children)
+			fmt.Fprint(w, `{"results": []}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"object": "error", "status": 404, "message": "Could not find block."}`)
+		}
+	}))
+	defer server.Close()
+	notionURL = server.URL
+
+	client := NewNotion()
+	if _, err := client.ReadPage(id); err == nil {
+		t.Errorf("ReadPage() without API key expected an error")
+	}
+	client.APIKey.Value = "secret"
+	got, err := client.ReadPage("https://www.notion.so/Weekly-Notes-" + strings.ReplaceAll(id, "-", ""))
+	if err != nil {
+		t.Fatalf("ReadPage() error = %v", err)
+	}
+	if want := "# Weekly Notes\n\n- More\n  Hidden\n\nThe end."; got != want {
+		t.Errorf("ReadPage() = %q, want %q", got, want)
+	}
+
+	count, err := client.AppendMarkdown(id, strings.Repeat("- item\n", maxBlocks+5))
+	if err != nil {
+		t.Fatalf("AppendMarkdown() error = %v", err)
+	}
+	if count != maxBlocks+5 || len(appended) != 2 || len(appended[0]) != maxBlocks || len(appended[1]) != 5 {
+		t.Errorf("AppendMarkdown() = %d blocks in %d requests, want %d in 2", count, len(appended), maxBlocks+5)
+	}
+
+	if _, err = client.ReadPage("00000000000000000000000000000000"); err == nil || !strings.Contains(err.Error(), "Could not find block.") {
+		t.Errorf("ReadPage() of a missing page error = %v, want the message of the API", err)
+	}
+}
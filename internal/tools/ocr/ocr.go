@@ -0,0 +1,109 @@
+// Package ocr recognizes the text of scanned documents and images, with
+// Tesseract or with a vision model, rendering the pages of PDFs with Poppler.
+package ocr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// PDFResolution is the resolution in DPI the pages of PDFs are rendered at,
+// that of scanners and what Tesseract recognizes best
+const PDFResolution = 300
+
+// Engine returns the text recognized in the image file
+type Engine func(imagePath string) (string, error)
+
+// imageExtensions are the images Tesseract and the vision models read
+var imageExtensions = []string{".png", ".jpg", ".jpeg", ".tif", ".tiff", ".bmp", ".gif", ".webp"}
+
+// IsImage reports whether the file is an image that can be recognized
+func IsImage(path string) bool {
+	return slices.Contains(imageExtensions, strings.ToLower(filepath.Ext(path)))
+}
+
+// IsPDF reports whether the file is a PDF
+func IsPDF(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".pdf")
+}
+
+// Tesseract returns the engine recognizing the images with the tesseract
+// command, in the languages given as Tesseract codes, e.g. "eng+deu"
+func Tesseract(languages string) Engine {
+	return func(imagePath string) (string, error) {
+		args := []string{imagePath, "stdout"}
+		if languages != "" {
+			args = append(args, "-l", languages)
+		}
+		out, err := run("tesseract", "Tesseract", args...)
+		return strings.TrimSpace(string(out)), err
+	}
+}
+
+// PDFText returns the text layer of the PDF with pdftotext, empty for
+// scanned documents
+func PDFText(path string) (string, error) {
+	out, err := run("pdftotext", "Poppler", "-layout", path, "-")
+	return strings.TrimSpace(string(out)), err
+}
+
+// PDF renders the pages of the PDF with pdftoppm and recognizes them one by
+// one, each page after the first starting with a "<!-- page N -->" marker
+func PDF(path string, engine Engine) (ret string, err error) {
+	var dir string
+	if dir, err = os.MkdirTemp("", "fabric-ocr-"); err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+	if _, err = run("pdftoppm", "Poppler", "-r", strconv.Itoa(PDFResolution), "-png", path, filepath.Join(dir, "page")); err != nil {
+		return
+	}
+	var pages []string
+	if pages, err = filepath.Glob(filepath.Join(dir, "page-*.png")); err != nil {
+		return
+	}
+	// pdftoppm pads the page numbers to the width of the last one
+	slices.SortFunc(pages, func(a, b string) int { return pageNumber(a) - pageNumber(b) })
+
+	var sb strings.Builder
+	for i, page := range pages {
+		var text string
+		if text, err = engine(page); err != nil {
+			return
+		}
+		if i > 0 {
+			sb.WriteString("\n\n<!-- page " + strconv.Itoa(i+1) + " -->\n\n")
+		}
+		sb.WriteString(strings.TrimSpace(text))
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func pageNumber(path string) int {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	number, _ := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	return number
+}
+
+// run runs the command of the tool, part of the package given to install
+func run(command, pkg string, args ...string) (ret []byte, err error) {
+	var path string
+	if path, err = exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf(i18n.T("ocr_error_tool_not_found"), command, pkg)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf(i18n.T("ocr_error_running"), command, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
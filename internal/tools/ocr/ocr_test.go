@@ -0,0 +1,63 @@
+package ocr
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeTools puts scripts standing for the OCR tools first on the PATH
+func fakeTools(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake tools are shell scripts")
+	}
+	dir := t.TempDir()
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestIsImage(t *testing.T) {
+	if !IsImage("scan.PNG") || !IsImage("photo.jpeg") || IsImage("notes.md") || IsImage("scan.pdf") {
+		t.Errorf("IsImage() does not tell the images apart")
+	}
+	if !IsPDF("Scan.PDF") || IsPDF("scan.png") {
+		t.Errorf("IsPDF() does not tell the PDFs apart")
+	}
+}
+
+func TestPDF(t *testing.T) {
+	// The pages are rendered with padded numbers, and recognized in order
+	fakeTools(t, map[string]string{
+		"pdftoppm":  `for n in 01 02 10; do : > "$5-$n.png"; done`,
+		"tesseract": `echo "text of ${1##*/} in $4"`,
+		"pdftotext": `printf '\f\n'`,
+	})
+	got, err := PDF("scan.pdf", Tesseract("eng+deu"))
+	if err != nil {
+		t.Fatalf("PDF() error = %v", err)
+	}
+	want := "text of page-01.png in eng+deu\n\n<!-- page 2 -->\n\ntext of page-02.png in eng+deu\n\n<!-- page 3 -->\n\ntext of page-10.png in eng+deu"
+	if got != want {
+		t.Errorf("PDF() = %q, want %q", got, want)
+	}
+	if text, err := PDFText("scan.pdf"); err != nil || text != "" {
+		t.Errorf("PDFText() of a scan = %q, %v; want no text", text, err)
+	}
+}
+
+func TestToolNotFound(t *testing.T) {
+	fakeTools(t, map[string]string{"tesseract": "echo 'bad image' >&2; exit 1"})
+	if _, err := PDFText("scan.pdf"); err == nil || !strings.Contains(err.Error(), "pdftotext") {
+		t.Errorf("PDFText() without pdftotext error = %v", err)
+	}
+	if _, err := Tesseract("")("scan.png"); err == nil || !strings.Contains(err.Error(), "bad image") {
+		t.Errorf("Tesseract() of a failing run error = %v, want its output", err)
+	}
+}
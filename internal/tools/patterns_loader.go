@@ -1,11 +1,13 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/i18n"
 	debuglog "github.com/danielmiessler/fabric/internal/log"
@@ -55,6 +57,8 @@ type PatternsLoader struct {
 
 	pathPatternsPrefix string
 	tempPatternsFolder string
+	// commit is the HEAD of the repository the patterns are downloaded from
+	commit string
 }
 
 func (o *PatternsLoader) configure() (err error) {
@@ -79,6 +83,20 @@ func (o *PatternsLoader) IsConfigured() (ret bool) {
 	return
 }
 
+// LoadedVersion returns the commit of the repository the patterns were last
+// downloaded from, empty when it was not recorded, and the time of the download.
+func (o *PatternsLoader) LoadedVersion() (commit string, updated time.Time, err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(o.loadedFilePath); err != nil {
+		return
+	}
+	var content []byte
+	if content, err = os.ReadFile(o.loadedFilePath); err != nil {
+		return
+	}
+	return strings.TrimSpace(string(content)), info.ModTime(), nil
+}
+
 func (o *PatternsLoader) Setup() (err error) {
 	if err = o.PluginBase.Setup(); err != nil {
 		return
@@ -202,8 +220,8 @@ func (o *PatternsLoader) movePatterns() (err error) {
 		return
 	}
 
-	//create an empty file to indicate that the patterns have been updated if not exists
-	if _, err = os.Create(o.loadedFilePath); err != nil {
+	// The marker records the commit of the patterns to tell when they are outdated
+	if err = os.WriteFile(o.loadedFilePath, []byte(o.commit), 0o644); err != nil {
 		return fmt.Errorf(i18n.T("patterns_failed_loaded_marker"), o.loadedFilePath, err)
 	}
 
@@ -219,6 +237,9 @@ func (o *PatternsLoader) gitCloneAndCopy() (err error) {
 
 	fmt.Printf(i18n.T("patterns_cloning_repository"), o.DefaultGitRepoUrl.Value, o.DefaultFolder.Value)
 
+	// Best effort, patterns without a recorded commit are only reported as of unknown version
+	o.commit, _ = githelper.RemoteHead(context.Background(), o.DefaultGitRepoUrl.Value)
+
 	// Try to fetch files with the current path
 	err = githelper.FetchFilesFromRepo(githelper.FetchOptions{
 		RepoURL:    o.DefaultGitRepoUrl.Value,
@@ -0,0 +1,261 @@
+package postprocess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// jqQuery is a subset of jq: paths such as .items[0].name, .["key"] and .[],
+// the keys and length builtins, and pipes between them. Strings are printed
+// raw, as with jq -r.
+type jqQuery [][]jqStep
+
+type jqStep struct {
+	// field is the object key, index the array index when isIndex, and iterate
+	// is set for []; builtin is keys or length
+	field   string
+	index   int
+	isIndex bool
+	iterate bool
+	builtin string
+}
+
+func parseJQ(expression string) (ret jqQuery, err error) {
+	for _, stage := range splitPipes(expression) {
+		stage = strings.TrimSpace(stage)
+		var steps []jqStep
+		switch stage {
+		case "":
+			return nil, errors.New("empty expression")
+		case "keys", "length":
+			steps = []jqStep{{builtin: stage}}
+		default:
+			if steps, err = parseJQPath(stage); err != nil {
+				return
+			}
+		}
+		ret = append(ret, steps)
+	}
+	return
+}
+
+// splitPipes splits an expression on the pipes outside of quoted keys.
+func splitPipes(expression string) (ret []string) {
+	inQuote := false
+	start := 0
+	for i := 0; i < len(expression); i++ {
+		switch expression[i] {
+		case '\\':
+			i++
+		case '"':
+			inQuote = !inQuote
+		case '|':
+			if !inQuote {
+				ret = append(ret, expression[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(ret, expression[start:])
+}
+
+func parseJQPath(path string) (ret []jqStep, err error) {
+	if path[0] != '.' {
+		return nil, fmt.Errorf("unsupported expression %q", path)
+	}
+	for i := 0; i < len(path); {
+		switch {
+		case path[i] == '.' && i+1 < len(path) && isIdentChar(path[i+1], true):
+			end := i + 1
+			for end < len(path) && isIdentChar(path[end], false) {
+				end++
+			}
+			ret = append(ret, jqStep{field: path[i+1 : end]})
+			i = end
+		case path[i] == '.':
+			i++
+		case path[i] == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ] in %q", path)
+			}
+			inner := strings.TrimSpace(path[i+1 : i+end])
+			var step jqStep
+			if step, err = parseJQBracket(inner); err != nil {
+				return
+			}
+			ret = append(ret, step)
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", path[i], path)
+		}
+	}
+	return
+}
+
+func parseJQBracket(inner string) (ret jqStep, err error) {
+	switch {
+	case inner == "":
+		ret.iterate = true
+	case strings.HasPrefix(inner, `"`):
+		ret.field, err = strconv.Unquote(inner)
+	default:
+		ret.index, err = strconv.Atoi(inner)
+		ret.isIndex = true
+	}
+	return
+}
+
+func isIdentChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+func (o jqQuery) apply(text string) (ret string, err error) {
+	var input any
+	if err = json.Unmarshal([]byte(strings.TrimSpace(text)), &input); err != nil {
+		return "", fmt.Errorf(i18n.T("postprocess_invalid_json"), err)
+	}
+
+	values := []any{input}
+	for _, stage := range o {
+		for _, step := range stage {
+			var next []any
+			for _, value := range values {
+				var results []any
+				if results, err = step.apply(value); err != nil {
+					return "", fmt.Errorf(i18n.T("postprocess_jq_error"), err)
+				}
+				next = append(next, results...)
+			}
+			values = next
+		}
+	}
+
+	lines := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			lines = append(lines, str)
+			continue
+		}
+		var data []byte
+		if data, err = json.MarshalIndent(value, "", "  "); err != nil {
+			return
+		}
+		lines = append(lines, string(data))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (o jqStep) apply(value any) (ret []any, err error) {
+	switch {
+	case o.builtin == "keys":
+		return jqKeys(value)
+	case o.builtin == "length":
+		return jqLength(value)
+	case o.iterate:
+		switch typed := value.(type) {
+		case []any:
+			return typed, nil
+		case map[string]any:
+			keys := sortedKeys(typed)
+			for _, key := range keys {
+				ret = append(ret, typed[key])
+			}
+			return
+		}
+		return nil, fmt.Errorf("cannot iterate over %s", jqType(value))
+	case o.isIndex:
+		switch typed := value.(type) {
+		case nil:
+			return []any{nil}, nil
+		case []any:
+			index := o.index
+			if index < 0 {
+				index += len(typed)
+			}
+			if index < 0 || index >= len(typed) {
+				return []any{nil}, nil
+			}
+			return []any{typed[index]}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with a number", jqType(value))
+	default:
+		switch typed := value.(type) {
+		case nil:
+			return []any{nil}, nil
+		case map[string]any:
+			return []any{typed[o.field]}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with %q", jqType(value), o.field)
+	}
+}
+
+func jqKeys(value any) ([]any, error) {
+	switch typed := value.(type) {
+	case map[string]any:
+		keys := sortedKeys(typed)
+		ret := make([]any, len(keys))
+		for i, key := range keys {
+			ret[i] = key
+		}
+		return []any{ret}, nil
+	case []any:
+		ret := make([]any, len(typed))
+		for i := range typed {
+			ret[i] = float64(i)
+		}
+		return []any{ret}, nil
+	}
+	return nil, fmt.Errorf("%s has no keys", jqType(value))
+}
+
+func jqLength(value any) ([]any, error) {
+	switch typed := value.(type) {
+	case nil:
+		return []any{float64(0)}, nil
+	case string:
+		return []any{float64(len([]rune(typed)))}, nil
+	case []any:
+		return []any{float64(len(typed))}, nil
+	case map[string]any:
+		return []any{float64(len(typed))}, nil
+	case float64:
+		if typed < 0 {
+			typed = -typed
+		}
+		return []any{typed}, nil
+	}
+	return nil, fmt.Errorf("%s has no length", jqType(value))
+}
+
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func jqType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
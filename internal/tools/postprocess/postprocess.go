@@ -0,0 +1,118 @@
+// Package postprocess transforms the output of a chat before it is printed, so
+// that shell scripts get exactly the part of the answer they need.
+package postprocess
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	// Fences removes the markdown fence wrapping the whole output
+	Fences = "fences"
+	// CodeBlock keeps the content of the first fenced code block
+	CodeBlock = "codeblock"
+	// JQPrefix starts a jq expression applied to JSON output, e.g. "jq:.items[].name"
+	JQPrefix = "jq:"
+)
+
+var codeBlockRegex = regexp.MustCompile("(?s)```[^\n]*\n(.*?)\n?```")
+
+// Processor transforms an output.
+type Processor func(text string) (string, error)
+
+// Pipeline applies processors in order.
+type Pipeline []Processor
+
+// Parse builds a pipeline from processor specifications: fences, codeblock,
+// s/regex/replacement/[g] or jq:expression.
+func Parse(specs []string) (ret Pipeline, err error) {
+	for _, spec := range specs {
+		var processor Processor
+		if processor, err = parseProcessor(spec); err != nil {
+			return nil, err
+		}
+		ret = append(ret, processor)
+	}
+	return
+}
+
+// Apply runs the output through the pipeline.
+func (o Pipeline) Apply(text string) (ret string, err error) {
+	ret = text
+	for _, processor := range o {
+		if ret, err = processor(ret); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func parseProcessor(spec string) (Processor, error) {
+	switch {
+	case spec == Fences:
+		return stripFences, nil
+	case spec == CodeBlock:
+		return firstCodeBlock, nil
+	case strings.HasPrefix(spec, JQPrefix):
+		query, err := parseJQ(strings.TrimPrefix(spec, JQPrefix))
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("postprocess_invalid_jq"), spec, err)
+		}
+		return query.apply, nil
+	case len(spec) > 2 && spec[0] == 's' && !isIdentChar(spec[1], false) && spec[1] != ' ':
+		return parseReplace(spec)
+	default:
+		return nil, fmt.Errorf(i18n.T("postprocess_unknown_processor"), spec)
+	}
+}
+
+func stripFences(text string) (string, error) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return text, nil
+	}
+	lines := strings.Split(trimmed, "\n")
+	if len(lines) < 2 {
+		return text, nil
+	}
+	return strings.Join(lines[1:len(lines)-1], "\n"), nil
+}
+
+func firstCodeBlock(text string) (string, error) {
+	match := codeBlockRegex.FindStringSubmatch(text)
+	if match == nil {
+		return "", fmt.Errorf("%s", i18n.T("postprocess_no_code_block"))
+	}
+	return match[1], nil
+}
+
+// parseReplace parses a sed-like s/regex/replacement/ with any delimiter. The
+// replacement may reference groups as $1 and the g flag replaces every match.
+func parseReplace(spec string) (Processor, error) {
+	delimiter := spec[1:2]
+	parts := strings.Split(spec[2:], delimiter)
+	if len(parts) != 3 || (parts[2] != "" && parts[2] != "g") {
+		return nil, fmt.Errorf(i18n.T("postprocess_invalid_replace"), spec)
+	}
+	re, err := regexp.Compile(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("postprocess_invalid_regex"), spec, err)
+	}
+	replacement, global := parts[1], parts[2] == "g"
+
+	return func(text string) (string, error) {
+		if global {
+			return re.ReplaceAllString(text, replacement), nil
+		}
+		loc := re.FindStringSubmatchIndex(text)
+		if loc == nil {
+			return text, nil
+		}
+		expanded := re.ExpandString(nil, replacement, text, loc)
+		return text[:loc[0]] + string(expanded) + text[loc[1]:], nil
+	}, nil
+}
@@ -0,0 +1,62 @@
+package postprocess
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	tests := []struct {
+		name     string
+		specs    []string
+		input    string
+		expected string
+	}{
+		{"fences", []string{"fences"}, "```json\n{\"a\": 1}\n```\n", "{\"a\": 1}"},
+		{"fences without fence", []string{"fences"}, "plain answer", "plain answer"},
+		{"codeblock", []string{"codeblock"}, "Here:\n```bash\nls -la\n```\nand\n```\nother\n```", "ls -la"},
+		{"replace first", []string{"s/o/0/"}, "foo boo", "f0o boo"},
+		{"replace all with groups", []string{"s#(\\w+)@(\\w+)#$2 at $1#g"}, "a@b c@d", "b at a d at c"},
+		{"jq field", []string{"jq:.name"}, `{"name": "fabric"}`, "fabric"},
+		{"jq iterate", []string{"jq:.items[].id"}, `{"items": [{"id": 1}, {"id": 2}]}`, "1\n2"},
+		{"jq index and quoted key", []string{`jq:.["a b"][-1]`}, `{"a b": [1, 2, 3]}`, "3"},
+		{"jq pipe and builtins", []string{"jq:.items | length"}, `{"items": [1, 2]}`, "2"},
+		{"jq keys", []string{"jq:keys"}, `{"b": 1, "a": 2}`, "[\n  \"a\",\n  \"b\"\n]"},
+		{"jq object", []string{"jq:.a"}, `{"a": {"b": true}}`, "{\n  \"b\": true\n}"},
+		{"codeblock then jq", []string{"codeblock", "jq:.ok"}, "```json\n{\"ok\": true}\n```", "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, err := Parse(tt.specs)
+			require.NoError(t, err)
+
+			output, err := pipeline.Apply(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"unknown", "s/a/b", "s/a/b/x", "s/(/b/", "jq:items", "jq:.a[", "jq:"} {
+		_, err := Parse([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	for name, tt := range map[string]struct{ spec, input string }{
+		"no code block":      {"codeblock", "no code here"},
+		"invalid json":       {"jq:.a", "not json"},
+		"field of an array":  {"jq:.a", "[1]"},
+		"iterate over value": {"jq:.[]", "1"},
+	} {
+		pipeline, err := Parse([]string{tt.spec})
+		require.NoError(t, err, name)
+		_, err = pipeline.Apply(tt.input)
+		assert.Error(t, err, name)
+	}
+}
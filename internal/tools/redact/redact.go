@@ -0,0 +1,211 @@
+// Package redact masks personal data and secrets in text sent to cloud models
+// and restores them in the responses.
+//
+// Each distinct value is replaced by a placeholder such as [EMAIL_1]; the mapping
+// stays local so the placeholders echoed by the model can be re-hydrated.
+package redact
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+const (
+	KindAPIKey     = "API_KEY"
+	KindEmail      = "EMAIL"
+	KindCreditCard = "CREDIT_CARD"
+	KindPhone      = "PHONE"
+)
+
+type rule struct {
+	kind  string
+	re    *regexp.Regexp
+	valid func(match string) bool
+}
+
+// rules are applied in order, so that a secret is not mistaken for another kind
+var rules = []rule{
+	{
+		kind: KindAPIKey,
+		re: regexp.MustCompile(`\b(?:sk-(?:proj-|ant-)?[A-Za-z0-9_-]{20,}|gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}|` +
+			`AKIA[0-9A-Z]{16}|AIza[0-9A-Za-z_-]{35}|xox[abposr]-[A-Za-z0-9-]{10,}|glpat-[A-Za-z0-9_-]{20,})`),
+	},
+	{
+		kind: KindEmail,
+		re:   regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+	},
+	{
+		kind:  KindCreditCard,
+		re:    regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		valid: luhnValid,
+	},
+	{
+		kind: KindPhone,
+		re: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?(?:\(\d{1,4}\)[ .-]?)?\d{1,4}(?:[ .-]?\d{2,4}){1,4}|` +
+			`\(\d{3}\) ?\d{3}[ .-]\d{4}|\b\d{3}[.-]\d{3}[.-]\d{4})\b`),
+		valid: func(match string) bool {
+			digits := countDigits(match)
+			return digits >= 7 && digits <= 15
+		},
+	},
+}
+
+var placeholderRegex = regexp.MustCompile(`\[(?:` + KindAPIKey + `|` + KindEmail + `|` + KindCreditCard + `|` + KindPhone + `)_\d+\]`)
+
+// maxPlaceholderLength bounds the text held back while a placeholder may be
+// split across streamed chunks.
+const maxPlaceholderLength = 24
+
+// Redactor masks sensitive values and remembers them to restore them later.
+type Redactor struct {
+	mu           sync.Mutex
+	placeholders map[string]string
+	values       map[string]string
+	counts       map[string]int
+}
+
+func New() *Redactor {
+	return &Redactor{
+		placeholders: map[string]string{},
+		values:       map[string]string{},
+		counts:       map[string]int{},
+	}
+}
+
+// Redact replaces the sensitive values of text with placeholders. The same value
+// always gets the same placeholder.
+func (o *Redactor) Redact(text string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, rule := range rules {
+		text = rule.re.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			return o.placeholder(rule.kind, match)
+		})
+	}
+	return text
+}
+
+func (o *Redactor) placeholder(kind, value string) string {
+	if placeholder, ok := o.placeholders[value]; ok {
+		return placeholder
+	}
+	o.counts[kind]++
+	placeholder := "[" + kind + "_" + strconv.Itoa(o.counts[kind]) + "]"
+	o.placeholders[value] = placeholder
+	o.values[placeholder] = value
+	return placeholder
+}
+
+// Restore replaces the placeholders of text with the values they stand for.
+func (o *Redactor) Restore(text string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return placeholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if value, ok := o.values[placeholder]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// Mapping returns the values masked so far by placeholder.
+func (o *Redactor) Mapping() map[string]string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	ret := make(map[string]string, len(o.values))
+	for placeholder, value := range o.values {
+		ret[placeholder] = value
+	}
+	return ret
+}
+
+// RedactMessages returns copies of the messages with their text redacted, leaving
+// the messages themselves untouched.
+func (o *Redactor) RedactMessages(messages []*chat.ChatCompletionMessage) (ret []*chat.ChatCompletionMessage) {
+	for _, message := range messages {
+		redacted := *message
+		redacted.Content = o.Redact(message.Content)
+		if len(message.MultiContent) > 0 {
+			redacted.MultiContent = make([]chat.ChatMessagePart, len(message.MultiContent))
+			for i, part := range message.MultiContent {
+				part.Text = o.Redact(part.Text)
+				redacted.MultiContent[i] = part
+			}
+		}
+		ret = append(ret, &redacted)
+	}
+	return
+}
+
+// RestoreStream returns a channel whose content updates are forwarded to out
+// with their placeholders restored. A placeholder split across updates is held
+// back until complete. out is closed once the returned channel is.
+func (o *Redactor) RestoreStream(out chan<- domain.StreamUpdate) chan domain.StreamUpdate {
+	in := make(chan domain.StreamUpdate)
+	go func() {
+		defer close(out)
+		pending := ""
+		for update := range in {
+			if update.Type == domain.StreamTypeContent {
+				text := pending + update.Content
+				pending = ""
+				if start := strings.LastIndex(text, "["); start >= 0 && !strings.Contains(text[start:], "]") &&
+					len(text)-start < maxPlaceholderLength {
+					pending = text[start:]
+					text = text[:start]
+				}
+				if text == "" {
+					continue
+				}
+				update.Content = o.Restore(text)
+			}
+			out <- update
+		}
+		if pending != "" {
+			out <- domain.StreamUpdate{Type: domain.StreamTypeContent, Content: o.Restore(pending)}
+		}
+	}()
+	return in
+}
+
+func countDigits(text string) (ret int) {
+	for _, r := range text {
+		if r >= '0' && r <= '9' {
+			ret++
+		}
+	}
+	return
+}
+
+// luhnValid reports whether the digits of number pass the Luhn checksum used by
+// payment card numbers.
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
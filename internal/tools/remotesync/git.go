@@ -0,0 +1,115 @@
+package remotesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// gitBackend keeps a working copy of the sync repository, pulled when opened
+// and pushed on Commit.
+type gitBackend struct {
+	dir     string
+	auth    transport.AuthMethod
+	repo    *git.Repository
+	changed bool
+}
+
+func newGitBackend(ctx context.Context, url, dir, username, password string) (ret *gitBackend, err error) {
+	ret = &gitBackend{dir: dir}
+	if username != "" || password != "" {
+		ret.auth = &githttp.BasicAuth{Username: username, Password: password}
+	}
+
+	if ret.repo, err = git.PlainOpen(dir); errors.Is(err, git.ErrRepositoryNotExists) {
+		err = ret.clone(ctx, url)
+	} else if err == nil {
+		err = ret.pull(ctx)
+	}
+	if err != nil {
+		err = fmt.Errorf(i18n.T("sync_error_git"), url, err)
+	}
+	return
+}
+
+// clone clones the repository, or starts a new one when it is still empty.
+func (o *gitBackend) clone(ctx context.Context, url string) (err error) {
+	o.repo, err = git.PlainCloneContext(ctx, o.dir, false, &git.CloneOptions{URL: url, Auth: o.auth})
+	if !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return
+	}
+	if err = os.RemoveAll(o.dir); err != nil {
+		return
+	}
+	if o.repo, err = git.PlainInit(o.dir, false); err != nil {
+		return
+	}
+	_, err = o.repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}})
+	return
+}
+
+func (o *gitBackend) pull(ctx context.Context) (err error) {
+	var worktree *git.Worktree
+	if worktree, err = o.repo.Worktree(); err != nil {
+		return
+	}
+	err = worktree.PullContext(ctx, &git.PullOptions{RemoteName: git.DefaultRemoteName, Auth: o.auth})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) || errors.Is(err, transport.ErrEmptyRemoteRepository) ||
+		errors.Is(err, plumbing.ErrReferenceNotFound) {
+		err = nil
+	}
+	return
+}
+
+func (o *gitBackend) Get(_ context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(o.dir, filepath.FromSlash(name)))
+}
+
+func (o *gitBackend) Put(_ context.Context, name string, data []byte) (err error) {
+	file := filepath.Join(o.dir, filepath.FromSlash(name))
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return
+	}
+	if err = os.WriteFile(file, data, 0644); err != nil {
+		return
+	}
+	var worktree *git.Worktree
+	if worktree, err = o.repo.Worktree(); err != nil {
+		return
+	}
+	if _, err = worktree.Add(name); err == nil {
+		o.changed = true
+	}
+	return
+}
+
+func (o *gitBackend) Commit(ctx context.Context) (err error) {
+	if !o.changed {
+		return
+	}
+	var worktree *git.Worktree
+	if worktree, err = o.repo.Worktree(); err != nil {
+		return
+	}
+	hostname, _ := os.Hostname()
+	author := &object.Signature{Name: "fabric", Email: "fabric@" + hostname, When: time.Now()}
+	if _, err = worktree.Commit(fmt.Sprintf("Sync from %s", hostname), &git.CommitOptions{Author: author}); err != nil {
+		return
+	}
+	if err = o.repo.PushContext(ctx, &git.PushOptions{RemoteName: git.DefaultRemoteName, Auth: o.auth}); err != nil {
+		return fmt.Errorf(i18n.T("sync_error_git_push"), err)
+	}
+	o.changed = false
+	return
+}
@@ -0,0 +1,311 @@
+// Package remotesync keeps the custom patterns, sessions and contexts of
+// several fabric installs in step through a shared git repository, S3 bucket
+// or WebDAV folder.
+package remotesync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+const (
+	BackendGit    = "git"
+	BackendS3     = "s3"
+	BackendWebDAV = "webdav"
+
+	// manifestName is the remote file listing the hash of every synced file
+	manifestName = ".fabric-sync.json"
+	// ConflictSuffix is appended to the local copy of a remote file that could
+	// not be pulled over local changes
+	ConflictSuffix = ".conflict"
+)
+
+// Backend stores the synced files remotely. Names are slash separated paths.
+type Backend interface {
+	// Get returns the content of the file, or an error matching fs.ErrNotExist
+	Get(ctx context.Context, name string) ([]byte, error)
+	Put(ctx context.Context, name string, data []byte) error
+	// Commit publishes the files written by Put, for backends that batch them
+	Commit(ctx context.Context) error
+}
+
+func NewSync() (ret *Sync) {
+	label := "Sync"
+	ret = &Sync{}
+
+	ret.PluginBase = &plugins.PluginBase{
+		Name:             i18n.T("sync_label"),
+		SetupDescription: i18n.T("sync_setup_description") + " " + i18n.T("optional_marker"),
+		EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+	}
+
+	ret.Backend = ret.AddSetupQuestionWithEnvName("Backend", false, i18n.T("sync_backend_question"))
+	ret.URL = ret.AddSetupQuestionWithEnvName("URL", false, i18n.T("sync_url_question"))
+	ret.Username = ret.AddSetupQuestionWithEnvName("Username", false, i18n.T("sync_username_question"))
+	ret.Password = ret.AddSetupQuestionWithEnvName("Password", false, i18n.T("sync_password_question"))
+	ret.Region = ret.AddSetupQuestionWithEnvName("Region", false, i18n.T("sync_region_question"))
+	ret.Endpoint = ret.AddSetupQuestionWithEnvName("Endpoint", false, i18n.T("sync_endpoint_question"))
+
+	return
+}
+
+type Sync struct {
+	*plugins.PluginBase
+	Backend  *plugins.SetupQuestion
+	URL      *plugins.SetupQuestion
+	Username *plugins.SetupQuestion
+	Password *plugins.SetupQuestion
+	Region   *plugins.SetupQuestion
+	Endpoint *plugins.SetupQuestion
+}
+
+// IsConfigured returns true once a sync URL has been set
+func (o *Sync) IsConfigured() bool {
+	return o.URL.Value != ""
+}
+
+// BackendName returns the configured backend, or the one implied by the URL.
+func (o *Sync) BackendName() string {
+	if o.Backend.Value != "" {
+		return strings.ToLower(o.Backend.Value)
+	}
+	switch url := o.URL.Value; {
+	case strings.HasPrefix(url, "s3://"):
+		return BackendS3
+	case strings.HasPrefix(url, "git@"), strings.HasPrefix(url, "ssh://"), strings.HasSuffix(url, ".git"):
+		return BackendGit
+	case strings.HasPrefix(url, "http://"), strings.HasPrefix(url, "https://"):
+		return BackendWebDAV
+	}
+	return BackendGit
+}
+
+// NewBackend connects to the configured backend. The git backend keeps its
+// working copy in cacheDir.
+func (o *Sync) NewBackend(ctx context.Context, cacheDir string) (ret Backend, err error) {
+	switch name := o.BackendName(); name {
+	case BackendGit:
+		ret, err = newGitBackend(ctx, o.URL.Value, cacheDir, o.Username.Value, o.Password.Value)
+	case BackendS3:
+		ret, err = newS3Backend(ctx, o.URL.Value, o.Endpoint.Value, o.Region.Value, o.Username.Value, o.Password.Value)
+	case BackendWebDAV:
+		ret, err = newWebDAVBackend(o.URL.Value, o.Username.Value, o.Password.Value)
+	default:
+		err = fmt.Errorf(i18n.T("sync_error_unknown_backend"), name)
+	}
+	return
+}
+
+// Syncer pushes and pulls local directories to and from a backend. A file
+// changed on both sides since the last sync is a conflict and is never
+// overwritten.
+type Syncer struct {
+	Backend Backend
+	// Dirs maps the remote folder of each kind of file to its local directory
+	Dirs map[string]string
+	// StatePath stores the hashes of the files as of the last sync
+	StatePath string
+}
+
+// Result lists the files transferred by a sync and those in conflict.
+type Result struct {
+	Transferred []string
+	Conflicts   []string
+}
+
+// Push uploads the files changed locally since the last sync. Files changed
+// remotely in the meantime are reported as conflicts, to be pulled first.
+func (o *Syncer) Push(ctx context.Context) (ret *Result, err error) {
+	var local, remote, base map[string]string
+	if local, remote, base, err = o.load(ctx); err != nil {
+		return
+	}
+
+	ret = &Result{}
+	for _, name := range slices.Sorted(maps.Keys(local)) {
+		hash := local[name]
+		switch {
+		case remote[name] == hash:
+			base[name] = hash
+		case hash == base[name]:
+			// Unchanged locally, there is nothing to push
+		case remote[name] != base[name]:
+			ret.Conflicts = append(ret.Conflicts, name)
+		default:
+			var data []byte
+			if data, err = os.ReadFile(o.localPath(name)); err != nil {
+				return
+			}
+			if err = o.Backend.Put(ctx, name, data); err != nil {
+				return
+			}
+			remote[name], base[name] = hash, hash
+			ret.Transferred = append(ret.Transferred, name)
+		}
+	}
+
+	if len(ret.Transferred) > 0 {
+		var manifest []byte
+		if manifest, err = json.MarshalIndent(remote, "", "  "); err != nil {
+			return
+		}
+		if err = o.Backend.Put(ctx, manifestName, manifest); err != nil {
+			return
+		}
+		if err = o.Backend.Commit(ctx); err != nil {
+			return
+		}
+	}
+	err = o.saveState(base)
+	return
+}
+
+// Pull downloads the files changed remotely since the last sync. When the local
+// file changed too, the remote copy is saved next to it with ConflictSuffix,
+// and the next push goes through once the two have been merged.
+func (o *Syncer) Pull(ctx context.Context) (ret *Result, err error) {
+	var local, remote, base map[string]string
+	if local, remote, base, err = o.load(ctx); err != nil {
+		return
+	}
+
+	ret = &Result{}
+	for _, name := range slices.Sorted(maps.Keys(remote)) {
+		hash := remote[name]
+		if local[name] == hash {
+			base[name] = hash
+			continue
+		}
+		// Skip the files unchanged remotely, or not synced on this machine
+		if hash == base[name] || o.localPath(name) == "" {
+			continue
+		}
+
+		var data []byte
+		if data, err = o.Backend.Get(ctx, name); err != nil {
+			return
+		}
+		target := o.localPath(name)
+		if local[name] != base[name] {
+			target += ConflictSuffix
+			ret.Conflicts = append(ret.Conflicts, name)
+		} else {
+			ret.Transferred = append(ret.Transferred, name)
+		}
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return
+		}
+		if err = os.WriteFile(target, data, 0644); err != nil {
+			return
+		}
+		base[name] = hash
+	}
+	err = o.saveState(base)
+	return
+}
+
+// load returns the hashes of the local files, of the remote files and of the
+// files as of the last sync.
+func (o *Syncer) load(ctx context.Context) (local, remote, base map[string]string, err error) {
+	if local, err = o.localHashes(); err != nil {
+		return
+	}
+
+	remote = map[string]string{}
+	var data []byte
+	if data, err = o.Backend.Get(ctx, manifestName); err == nil {
+		if err = json.Unmarshal(data, &remote); err != nil {
+			err = fmt.Errorf(i18n.T("sync_error_reading_manifest"), err)
+			return
+		}
+	} else if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	} else {
+		return
+	}
+
+	base = map[string]string{}
+	if data, err = os.ReadFile(o.StatePath); err == nil {
+		err = json.Unmarshal(data, &base)
+	} else if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	return
+}
+
+func (o *Syncer) saveState(base map[string]string) (err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(base, "", "  "); err != nil {
+		return
+	}
+	return os.WriteFile(o.StatePath, data, 0644)
+}
+
+// localHashes hashes the files of the synced directories, skipping hidden
+// files and conflict copies.
+func (o *Syncer) localHashes() (ret map[string]string, err error) {
+	ret = map[string]string{}
+	for folder, dir := range o.Dirs {
+		err = filepath.WalkDir(dir, func(file string, entry fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				if errors.Is(walkErr, fs.ErrNotExist) {
+					return nil
+				}
+				return walkErr
+			}
+			if strings.HasPrefix(entry.Name(), ".") && file != dir {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.IsDir() || strings.HasSuffix(entry.Name(), ConflictSuffix) {
+				return nil
+			}
+			data, readErr := os.ReadFile(file)
+			if readErr != nil {
+				return readErr
+			}
+			rel, relErr := filepath.Rel(dir, file)
+			if relErr != nil {
+				return relErr
+			}
+			ret[path.Join(folder, filepath.ToSlash(rel))] = hashOf(data)
+			return nil
+		})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// localPath returns the local file of a synced name, or an empty string when
+// its folder is not synced on this machine.
+func (o *Syncer) localPath(name string) string {
+	folder, rel, found := strings.Cut(name, "/")
+	dir := o.Dirs[folder]
+	if !found || dir == "" || !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return ""
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel))
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,264 @@
+package remotesync
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+type memoryBackend struct {
+	files map[string][]byte
+}
+
+func (o *memoryBackend) Get(_ context.Context, name string) ([]byte, error) {
+	if data, ok := o.files[name]; ok {
+		return data, nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+func (o *memoryBackend) Put(_ context.Context, name string, data []byte) error {
+	o.files[name] = data
+	return nil
+}
+
+func (o *memoryBackend) Commit(context.Context) error {
+	return nil
+}
+
+// newMachine returns a syncer for a fabric install with its own sessions
+// directory.
+func newMachine(t *testing.T, backend Backend) (*Syncer, string) {
+	t.Helper()
+	home := t.TempDir()
+	sessions := filepath.Join(home, "sessions")
+	if err := os.MkdirAll(sessions, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return &Syncer{Backend: backend, Dirs: map[string]string{"sessions": sessions}, StatePath: filepath.Join(home, "sync_state.json")}, sessions
+}
+
+func writeFile(t *testing.T, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// exerciseBackend syncs a session between two machines through the backend,
+// then edits it on both sides.
+func exerciseBackend(t *testing.T, backend Backend) {
+	ctx := context.Background()
+	laptop, laptopSessions := newMachine(t, backend)
+	server, serverSessions := newMachine(t, backend)
+
+	writeFile(t, filepath.Join(laptopSessions, "chat.json"), "v1")
+	writeFile(t, filepath.Join(laptopSessions, ".hidden"), "skipped")
+	result, err := laptop.Push(ctx)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if !slices.Equal(result.Transferred, []string{"sessions/chat.json"}) {
+		t.Errorf("pushed %v", result.Transferred)
+	}
+
+	if result, err = server.Pull(ctx); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if got := readFile(t, filepath.Join(serverSessions, "chat.json")); got != "v1" || len(result.Conflicts) > 0 {
+		t.Fatalf("pulled %q with conflicts %v", got, result.Conflicts)
+	}
+
+	// Both machines change the session, the second push is a conflict
+	writeFile(t, filepath.Join(serverSessions, "chat.json"), "server")
+	writeFile(t, filepath.Join(laptopSessions, "chat.json"), "laptop")
+	if _, err = server.Push(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if result, err = laptop.Push(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(result.Conflicts, []string{"sessions/chat.json"}) || len(result.Transferred) > 0 {
+		t.Fatalf("push result = %+v, expected a conflict", result)
+	}
+
+	// Pulling keeps the local file and saves the remote copy next to it
+	if result, err = laptop.Pull(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(result.Conflicts, []string{"sessions/chat.json"}) {
+		t.Errorf("pull conflicts = %v", result.Conflicts)
+	}
+	if got := readFile(t, filepath.Join(laptopSessions, "chat.json")); got != "laptop" {
+		t.Errorf("local session overwritten with %q", got)
+	}
+	if got := readFile(t, filepath.Join(laptopSessions, "chat.json"+ConflictSuffix)); got != "server" {
+		t.Errorf("conflict copy = %q", got)
+	}
+
+	// Once merged, the push goes through
+	writeFile(t, filepath.Join(laptopSessions, "chat.json"), "merged")
+	if result, err = laptop.Push(ctx); err != nil || len(result.Conflicts) > 0 {
+		t.Fatalf("push after merge = %+v, %v", result, err)
+	}
+	if _, err = server.Pull(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(serverSessions, "chat.json")); got != "merged" {
+		t.Errorf("server session = %q after pulling the merge", got)
+	}
+}
+
+func TestSyncer(t *testing.T) {
+	exerciseBackend(t, &memoryBackend{files: map[string][]byte{}})
+}
+
+func TestSyncerPullKeepsLocalChanges(t *testing.T) {
+	ctx := context.Background()
+	backend := &memoryBackend{files: map[string][]byte{}}
+	laptop, sessions := newMachine(t, backend)
+	writeFile(t, filepath.Join(sessions, "chat.json"), "v1")
+	if _, err := laptop.Push(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A local change that is not pushed yet is not a conflict
+	writeFile(t, filepath.Join(sessions, "chat.json"), "v2")
+	result, err := laptop.Pull(ctx)
+	if err != nil || len(result.Conflicts)+len(result.Transferred) > 0 {
+		t.Fatalf("Pull() = %+v, %v, expected nothing to do", result, err)
+	}
+	if got := readFile(t, filepath.Join(sessions, "chat.json")); got != "v2" {
+		t.Errorf("local session = %q", got)
+	}
+}
+
+// davServer serves the WebDAV methods used by the backend from memory.
+func davServer(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	files := map[string][]byte{}
+	collections := map[string]bool{"/dav/": true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if data, ok := files[r.URL.Path]; ok {
+				_, _ = w.Write(data)
+			} else {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPut:
+			if !collections[path.Dir(r.URL.Path)+"/"] {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			files[r.URL.Path], _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		case "MKCOL":
+			if collections[r.URL.Path] {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			collections[r.URL.Path] = true
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebDAVBackend(t *testing.T) {
+	server := davServer(t)
+	backend, err := newWebDAVBackend(server.URL+"/dav", "me", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exerciseBackend(t, backend)
+}
+
+func TestS3Backend(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if data, ok := objects[r.URL.Path]; ok {
+				_, _ = w.Write(data)
+			} else {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	backend, err := newS3Backend(context.Background(), "s3://bucket/fabric", server.URL, "eu-west-1", "AKID", "SECRET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exerciseBackend(t, backend)
+
+	if _, ok := objects["/bucket/fabric/sessions/chat.json"]; !ok {
+		t.Errorf("objects %v do not hold the session under the prefix", slices.Collect(maps.Keys(objects)))
+	}
+}
+
+func TestGitBackend(t *testing.T) {
+	remote := t.TempDir()
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	laptop, err := newGitBackend(ctx, remote, filepath.Join(t.TempDir(), "repo"), "", "")
+	if err != nil {
+		t.Fatalf("newGitBackend() error = %v", err)
+	}
+	if err = laptop.Put(ctx, "sessions/chat.json", []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+	if err = laptop.Commit(ctx); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	server, err := newGitBackend(ctx, remote, filepath.Join(t.TempDir(), "repo"), "", "")
+	if err != nil {
+		t.Fatalf("newGitBackend() error = %v", err)
+	}
+	if data, err := server.Get(ctx, "sessions/chat.json"); err != nil || string(data) != "v1" {
+		t.Errorf("Get() = %q, %v", data, err)
+	}
+}
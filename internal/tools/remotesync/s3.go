@@ -0,0 +1,129 @@
+package remotesync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const defaultS3Region = "us-east-1"
+
+// s3Backend stores the files in an S3 bucket, or any S3 compatible storage
+// reachable through a custom endpoint, with path style requests signed by
+// SigV4.
+type s3Backend struct {
+	endpoint    *url.URL
+	bucket      string
+	prefix      string
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+// newS3Backend parses an s3://bucket/prefix URL. Without an access key, the
+// credentials come from the AWS default credential chain.
+func newS3Backend(ctx context.Context, rawURL, endpoint, region, accessKey, secretKey string) (ret *s3Backend, err error) {
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(rawURL, "s3://"), "/")
+	if bucket == "" {
+		return nil, fmt.Errorf(i18n.T("sync_error_invalid_s3_url"), rawURL)
+	}
+
+	configOpts := []func(*config.LoadOptions) error{}
+	if region != "" {
+		configOpts = append(configOpts, config.WithRegion(region))
+	}
+	if accessKey != "" {
+		configOpts = append(configOpts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")))
+	}
+	var cfg aws.Config
+	if cfg, err = config.LoadDefaultConfig(ctx, configOpts...); err != nil {
+		return
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultS3Region
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+
+	ret = &s3Backend{
+		bucket:      bucket,
+		prefix:      strings.Trim(prefix, "/"),
+		region:      cfg.Region,
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      http.DefaultClient,
+	}
+	ret.endpoint, err = url.Parse(endpoint)
+	return
+}
+
+func (o *s3Backend) Get(ctx context.Context, name string) (ret []byte, err error) {
+	var resp *http.Response
+	if resp, err = o.do(ctx, http.MethodGet, name, nil); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fs.ErrNotExist
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(i18n.T("sync_error_http_status"), http.MethodGet, name, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (o *s3Backend) Put(ctx context.Context, name string, data []byte) (err error) {
+	var resp *http.Response
+	if resp, err = o.do(ctx, http.MethodPut, name, data); err != nil {
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf(i18n.T("sync_error_http_status"), http.MethodPut, name, resp.Status)
+	}
+	return
+}
+
+func (o *s3Backend) Commit(context.Context) error {
+	return nil
+}
+
+func (o *s3Backend) do(ctx context.Context, method, name string, body []byte) (resp *http.Response, err error) {
+	key := name
+	if o.prefix != "" {
+		key = o.prefix + "/" + name
+	}
+	target := o.endpoint.JoinPath(append([]string{o.bucket}, strings.Split(key, "/")...)...)
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body)); err != nil {
+		return
+	}
+	payloadHash := hashOf(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	var creds aws.Credentials
+	if creds, err = o.credentials.Retrieve(ctx); err != nil {
+		return
+	}
+	if err = o.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", o.region, time.Now()); err != nil {
+		return
+	}
+	return o.client.Do(req)
+}
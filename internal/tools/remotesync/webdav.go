@@ -0,0 +1,109 @@
+package remotesync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// webdavBackend stores the files under a WebDAV folder, creating the
+// collections it needs.
+type webdavBackend struct {
+	base     *url.URL
+	username string
+	password string
+	client   *http.Client
+}
+
+func newWebDAVBackend(rawURL, username, password string) (ret *webdavBackend, err error) {
+	var base *url.URL
+	if base, err = url.Parse(strings.TrimSuffix(rawURL, "/") + "/"); err != nil {
+		return
+	}
+	ret = &webdavBackend{base: base, username: username, password: password, client: http.DefaultClient}
+	return
+}
+
+func (o *webdavBackend) Get(ctx context.Context, name string) (ret []byte, err error) {
+	var resp *http.Response
+	if resp, err = o.do(ctx, http.MethodGet, name, nil); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fs.ErrNotExist
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(i18n.T("sync_error_http_status"), http.MethodGet, name, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (o *webdavBackend) Put(ctx context.Context, name string, data []byte) (err error) {
+	var resp *http.Response
+	if resp, err = o.do(ctx, http.MethodPut, name, data); err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	// A missing parent collection fails with 409 Conflict
+	if resp.StatusCode == http.StatusConflict {
+		if err = o.makeCollections(ctx, path.Dir(name)); err != nil {
+			return
+		}
+		if resp, err = o.do(ctx, http.MethodPut, name, data); err != nil {
+			return
+		}
+		resp.Body.Close()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = fmt.Errorf(i18n.T("sync_error_http_status"), http.MethodPut, name, resp.Status)
+	}
+	return
+}
+
+func (o *webdavBackend) Commit(context.Context) error {
+	return nil
+}
+
+// makeCollections creates each collection of the folder path, ignoring those
+// that already exist.
+func (o *webdavBackend) makeCollections(ctx context.Context, folder string) (err error) {
+	current := ""
+	for part := range strings.SplitSeq(folder, "/") {
+		current = path.Join(current, part) + "/"
+		var resp *http.Response
+		if resp, err = o.do(ctx, "MKCOL", current, nil); err != nil {
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf(i18n.T("sync_error_http_status"), "MKCOL", current, resp.Status)
+		}
+	}
+	return
+}
+
+func (o *webdavBackend) do(ctx context.Context, method, name string, body []byte) (resp *http.Response, err error) {
+	var req *http.Request
+	target := o.base.JoinPath(strings.Split(name, "/")...)
+	if strings.HasSuffix(name, "/") {
+		target.Path += "/"
+	}
+	if req, err = http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body)); err != nil {
+		return
+	}
+	if o.username != "" || o.password != "" {
+		req.SetBasicAuth(o.username, o.password)
+	}
+	return o.client.Do(req)
+}
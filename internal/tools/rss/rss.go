@@ -0,0 +1,430 @@
+// Package rss provides podcast and blog feed ingestion for RSS 2.0 and Atom feeds.
+//
+// Feed entries are converted to plain text suitable for LLM processing. Audio
+// enclosures (podcast episodes) can be downloaded so they can be passed to
+// fabric's transcription support.
+package rss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const userAgent = "fabric-rss/1.0 (+https://github.com/danielmiessler/fabric)"
+
+var (
+	tagRegex        = regexp.MustCompile(`<[^>]*>`)
+	blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+	normalizeRegex  = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// dateLayouts lists the publication date formats seen in the wild, most common first.
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02",
+}
+
+// Client fetches and parses feeds.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a new feed client. Its client uses the default transport,
+// so that the proxy and CA settings apply, with a timeout so that a stalled
+// feed or enclosure does not hang.
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: 60 * time.Second}}
+}
+
+// Feed is the normalized representation of an RSS or Atom feed.
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []*Item
+}
+
+// Item is a single feed entry (blog post or podcast episode).
+type Item struct {
+	Title string
+	// TitleNormalized names the files of the item, unique within the feed. It
+	// falls back to the GUID, the date or the position of untitled items.
+	TitleNormalized string
+	GUID            string
+	Link            string
+	Published       time.Time
+	Author          string
+	Content         string
+	Enclosure       *Enclosure
+}
+
+// Enclosure describes a media file attached to an item.
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+// IsAudio reports whether the enclosure looks like an audio file.
+func (o *Enclosure) IsAudio() bool {
+	if strings.HasPrefix(o.Type, "audio/") {
+		return true
+	}
+	switch strings.ToLower(path.Ext(o.urlPath())) {
+	case ".mp3", ".m4a", ".aac", ".ogg", ".opus", ".wav", ".flac":
+		return true
+	}
+	return false
+}
+
+func (o *Enclosure) urlPath() string {
+	if u, err := url.Parse(o.URL); err == nil {
+		return u.Path
+	}
+	return o.URL
+}
+
+// FetchFeed downloads and parses the feed at feedURL.
+func (c *Client) FetchFeed(feedURL string) (ret *Feed, err error) {
+	var body []byte
+	if body, err = c.get(feedURL); err != nil {
+		return
+	}
+	return ParseFeed(body)
+}
+
+// ParseFeed parses RSS 2.0 or Atom feed content.
+func ParseFeed(data []byte) (ret *Feed, err error) {
+	var probe struct {
+		XMLName xml.Name
+	}
+	if err = xml.Unmarshal(data, &probe); err != nil {
+		err = fmt.Errorf(i18n.T("rss_error_parsing_feed"), err)
+		return
+	}
+
+	switch strings.ToLower(probe.XMLName.Local) {
+	case "rss", "rdf":
+		ret, err = parseRSS(data)
+	case "feed":
+		ret, err = parseAtom(data)
+	default:
+		err = fmt.Errorf(i18n.T("rss_error_unsupported_feed_format"), probe.XMLName.Local)
+	}
+	if err == nil {
+		nameItems(ret.Items)
+	}
+	return
+}
+
+// Latest returns up to limit items ordered from newest to oldest.
+// A limit of zero or less returns all items.
+func (o *Feed) Latest(limit int) []*Item {
+	items := make([]*Item, len(o.Items))
+	copy(items, o.Items)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Published.After(items[j].Published)
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// FormatAsText formats the item as human-readable text suitable for LLM processing.
+func (o *Item) FormatAsText() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Title: %s\n", o.Title)
+	if o.Author != "" {
+		fmt.Fprintf(&sb, "Author: %s\n", o.Author)
+	}
+	if !o.Published.IsZero() {
+		fmt.Fprintf(&sb, "Published: %s\n", o.Published.Format("2006-01-02"))
+	}
+	if o.Link != "" {
+		fmt.Fprintf(&sb, "URL: %s\n", o.Link)
+	}
+	if o.Content != "" {
+		fmt.Fprintf(&sb, "\n%s\n", o.Content)
+	}
+	return sb.String()
+}
+
+// DownloadEnclosure saves the item's enclosure into dir and returns the file path.
+func (c *Client) DownloadEnclosure(item *Item, dir string) (filePath string, err error) {
+	if item.Enclosure == nil || item.Enclosure.URL == "" {
+		err = fmt.Errorf(i18n.T("rss_error_no_enclosure"), item.Title)
+		return
+	}
+
+	ext := path.Ext(item.Enclosure.urlPath())
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(item.Enclosure.Type); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	filePath = filepath.Join(dir, item.TitleNormalized+ext)
+
+	var resp *http.Response
+	if resp, err = c.do(item.Enclosure.URL); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var file *os.File
+	if file, err = os.Create(filePath); err != nil {
+		err = fmt.Errorf(i18n.T("rss_error_downloading_enclosure"), item.Enclosure.URL, err)
+		return
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, resp.Body); err != nil {
+		err = fmt.Errorf(i18n.T("rss_error_downloading_enclosure"), item.Enclosure.URL, err)
+	}
+	return
+}
+
+func (c *Client) get(requestURL string) (ret []byte, err error) {
+	var resp *http.Response
+	if resp, err = c.do(requestURL); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if ret, err = io.ReadAll(resp.Body); err != nil {
+		err = fmt.Errorf(i18n.T("rss_error_fetching_feed"), requestURL, err)
+	}
+	return
+}
+
+func (c *Client) do(requestURL string) (resp *http.Response, err error) {
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodGet, requestURL, nil); err != nil {
+		err = fmt.Errorf(i18n.T("rss_error_fetching_feed"), requestURL, err)
+		return
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	if resp, err = c.httpClient.Do(req); err != nil {
+		err = fmt.Errorf(i18n.T("rss_error_fetching_feed"), requestURL, err)
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		err = fmt.Errorf(i18n.T("rss_error_unexpected_status"), requestURL, resp.StatusCode)
+		resp = nil
+	}
+	return
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title       string    `xml:"title"`
+		Link        string    `xml:"link"`
+		Description string    `xml:"description"`
+		Items       []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 (RDF) feeds place items next to the channel element.
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title          string `xml:"title"`
+	GUID           string `xml:"guid"`
+	Link           string `xml:"link"`
+	PubDate        string `xml:"pubDate"`
+	DCDate         string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author         string `xml:"author"`
+	Creator        string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Description    string `xml:"description"`
+	ContentEncoded string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	ItunesSummary  string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+	Enclosure      *struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
+}
+
+func parseRSS(data []byte) (ret *Feed, err error) {
+	var doc rssDocument
+	if err = xml.Unmarshal(data, &doc); err != nil {
+		err = fmt.Errorf(i18n.T("rss_error_parsing_feed"), err)
+		return
+	}
+
+	ret = &Feed{
+		Title:       strings.TrimSpace(doc.Channel.Title),
+		Link:        strings.TrimSpace(doc.Channel.Link),
+		Description: htmlToText(doc.Channel.Description),
+	}
+
+	for _, src := range append(doc.Channel.Items, doc.Items...) {
+		item := &Item{
+			Title:     strings.TrimSpace(src.Title),
+			GUID:      strings.TrimSpace(src.GUID),
+			Link:      strings.TrimSpace(src.Link),
+			Published: parseDate(firstNonEmpty(src.PubDate, src.DCDate)),
+			Author:    strings.TrimSpace(firstNonEmpty(src.Author, src.Creator)),
+			Content:   htmlToText(firstNonEmpty(src.ContentEncoded, src.Description, src.ItunesSummary)),
+		}
+		if src.Enclosure != nil && src.Enclosure.URL != "" {
+			item.Enclosure = &Enclosure{
+				URL:    src.Enclosure.URL,
+				Type:   src.Enclosure.Type,
+				Length: src.Enclosure.Length,
+			}
+		}
+		ret.Items = append(ret.Items, item)
+	}
+	return
+}
+
+type atomDocument struct {
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Summary string `xml:"summary"`
+	Content string `xml:"content"`
+}
+
+func parseAtom(data []byte) (ret *Feed, err error) {
+	var doc atomDocument
+	if err = xml.Unmarshal(data, &doc); err != nil {
+		err = fmt.Errorf(i18n.T("rss_error_parsing_feed"), err)
+		return
+	}
+
+	ret = &Feed{
+		Title:       strings.TrimSpace(doc.Title),
+		Link:        alternateLink(doc.Links),
+		Description: htmlToText(doc.Subtitle),
+	}
+
+	for _, src := range doc.Entries {
+		item := &Item{
+			Title:     strings.TrimSpace(src.Title),
+			GUID:      strings.TrimSpace(src.ID),
+			Link:      alternateLink(src.Links),
+			Published: parseDate(firstNonEmpty(src.Published, src.Updated)),
+			Author:    strings.TrimSpace(src.Author.Name),
+			Content:   htmlToText(firstNonEmpty(src.Content, src.Summary)),
+		}
+		for _, link := range src.Links {
+			if link.Rel == "enclosure" && link.Href != "" {
+				item.Enclosure = &Enclosure{URL: link.Href, Type: link.Type, Length: link.Length}
+				break
+			}
+		}
+		ret.Items = append(ret.Items, item)
+	}
+	return
+}
+
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// htmlToText strips markup from feed descriptions, which are frequently HTML fragments.
+func htmlToText(value string) string {
+	value = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n").Replace(value)
+	value = html.UnescapeString(tagRegex.ReplaceAllString(value, ""))
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.TrimSpace(blankLinesRegex.ReplaceAllString(value, "\n\n"))
+}
+
+func normalizeFileName(name string) string {
+	return strings.Trim(normalizeRegex.ReplaceAllString(name, "_"), "_")
+}
+
+// nameItems sets the file names of the items from their title, GUID, date or
+// position, numbering the duplicates. Feeds list the newest items first, so
+// the items are named from the last one for the names to stay the same as
+// items are added.
+func nameItems(items []*Item) {
+	used := map[string]bool{}
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		name := normalizeFileName(item.Title)
+		if name == "" {
+			name = normalizeFileName(item.GUID)
+		}
+		if name == "" && !item.Published.IsZero() {
+			name = item.Published.Format("2006-01-02")
+		}
+		if name == "" {
+			name = "item_" + strconv.Itoa(len(items)-i)
+		}
+		unique := name
+		for n := 2; used[strings.ToLower(unique)]; n++ {
+			unique = name + "_" + strconv.Itoa(n)
+		}
+		used[strings.ToLower(unique)] = true
+		item.TitleNormalized = unique
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
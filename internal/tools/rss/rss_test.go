@@ -0,0 +1,163 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Example Podcast</title>
+    <link>https://example.com</link>
+    <description>A show about examples</description>
+    <item>
+      <title>Episode 1: Getting Started</title>
+      <link>https://example.com/ep1</link>
+      <pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate>
+      <description>&lt;p&gt;First &amp;amp; foremost&lt;/p&gt;</description>
+      <enclosure url="https://cdn.example.com/ep1.mp3" type="audio/mpeg" length="1234"/>
+    </item>
+    <item>
+      <title>Episode 2: Going Further</title>
+      <link>https://example.com/ep2</link>
+      <pubDate>Mon, 08 Jan 2024 10:00:00 +0000</pubDate>
+      <content:encoded><![CDATA[<p>Second episode</p><p>More notes</p>]]></content:encoded>
+      <enclosure url="https://cdn.example.com/ep2.mp3?token=abc" type="" length="5678"/>
+    </item>
+  </channel>
+</rss>`
+
+const sampleAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Blog</title>
+  <link href="https://blog.example.com/" rel="alternate"/>
+  <link href="https://blog.example.com/feed.xml" rel="self"/>
+  <entry>
+    <title>Older Post</title>
+    <link href="https://blog.example.com/older"/>
+    <updated>2024-02-01T12:00:00Z</updated>
+    <author><name>Jane</name></author>
+    <summary>An older post</summary>
+  </entry>
+  <entry>
+    <title>Newer Post</title>
+    <link href="https://blog.example.com/newer" rel="alternate"/>
+    <published>2024-03-01T12:00:00Z</published>
+    <content type="html">&lt;p&gt;Hello &lt;b&gt;world&lt;/b&gt;&lt;/p&gt;</content>
+  </entry>
+</feed>`
+
+func TestParseFeedRSS(t *testing.T) {
+	feed, err := ParseFeed([]byte(sampleRSS))
+	if err != nil {
+		t.Fatalf("ParseFeed() error = %v", err)
+	}
+
+	if feed.Title != "Example Podcast" {
+		t.Errorf("feed.Title = %q, want %q", feed.Title, "Example Podcast")
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("len(feed.Items) = %d, want 2", len(feed.Items))
+	}
+
+	first := feed.Items[0]
+	if first.Content != "First & foremost" {
+		t.Errorf("first.Content = %q, want %q", first.Content, "First & foremost")
+	}
+	if first.TitleNormalized != "Episode_1_Getting_Started" {
+		t.Errorf("first.TitleNormalized = %q", first.TitleNormalized)
+	}
+	if first.Enclosure == nil || !first.Enclosure.IsAudio() {
+		t.Errorf("expected audio enclosure, got %+v", first.Enclosure)
+	}
+
+	second := feed.Items[1]
+	if second.Content != "Second episode\n\nMore notes" {
+		t.Errorf("second.Content = %q", second.Content)
+	}
+	// Type is empty, so audio detection falls back to the URL extension.
+	if second.Enclosure == nil || !second.Enclosure.IsAudio() {
+		t.Errorf("expected audio enclosure detected by extension, got %+v", second.Enclosure)
+	}
+}
+
+func TestParseFeedAtom(t *testing.T) {
+	feed, err := ParseFeed([]byte(sampleAtom))
+	if err != nil {
+		t.Fatalf("ParseFeed() error = %v", err)
+	}
+
+	if feed.Link != "https://blog.example.com/" {
+		t.Errorf("feed.Link = %q", feed.Link)
+	}
+
+	latest := feed.Latest(1)
+	if len(latest) != 1 {
+		t.Fatalf("len(Latest(1)) = %d, want 1", len(latest))
+	}
+	if latest[0].Title != "Newer Post" {
+		t.Errorf("Latest(1)[0].Title = %q, want %q", latest[0].Title, "Newer Post")
+	}
+	if latest[0].Content != "Hello world" {
+		t.Errorf("Latest(1)[0].Content = %q", latest[0].Content)
+	}
+
+	all := feed.Latest(0)
+	if len(all) != 2 || all[1].Author != "Jane" {
+		t.Errorf("Latest(0) = %+v", all)
+	}
+}
+
+func TestParseFeedFileNames(t *testing.T) {
+	feed, err := ParseFeed([]byte(`<rss version="2.0"><channel>
+  <item><title>Same</title></item>
+  <item><title>Same</title></item>
+  <item><title>日本語のタイトル</title></item>
+  <item><title>!!!</title><guid>https://example.com/?p=42</guid></item>
+  <item><pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate></item>
+  <item></item>
+</channel></rss>`))
+	if err != nil {
+		t.Fatalf("ParseFeed() error = %v", err)
+	}
+
+	want := []string{"Same_2", "Same", "日本語のタイトル", "https_example_com_p_42", "2024-01-01", "item_1"}
+	for i, item := range feed.Items {
+		if item.TitleNormalized != want[i] {
+			t.Errorf("Items[%d].TitleNormalized = %q, want %q", i, item.TitleNormalized, want[i])
+		}
+	}
+}
+
+func TestParseFeedUnsupported(t *testing.T) {
+	if _, err := ParseFeed([]byte(`<html><body>nope</body></html>`)); err == nil {
+		t.Error("expected error for non-feed document")
+	}
+	if _, err := ParseFeed([]byte(`not xml`)); err == nil {
+		t.Error("expected error for invalid XML")
+	}
+}
+
+func TestItemFormatAsText(t *testing.T) {
+	feed, err := ParseFeed([]byte(sampleRSS))
+	if err != nil {
+		t.Fatalf("ParseFeed() error = %v", err)
+	}
+
+	text := feed.Items[1].FormatAsText()
+	for _, want := range []string{"Title: Episode 2: Going Further", "Published: 2024-01-08", "URL: https://example.com/ep2", "More notes"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("FormatAsText() missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	// The default transport carries the proxy and CA settings, the timeout
+	// keeps a stalled download from hanging
+	client := NewClient()
+	if client.httpClient.Transport != nil || client.httpClient.Timeout == 0 {
+		t.Errorf("NewClient() HTTP client = %+v, want the default transport with a timeout", client.httpClient)
+	}
+}
@@ -0,0 +1,188 @@
+// Package sandbox lets the models run Python and Go code to compute their
+// answers, isolated in a Docker container or a firejail sandbox with no network
+// and limited memory and time, and processes in Docker.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	Docker   = "docker"
+	Firejail = "firejail"
+
+	DefaultTimeout  = 30 * time.Second
+	DefaultMemoryMB = 512
+
+	toolName = "run_code"
+	// maxProcesses bounds the processes of the code in Docker, which a fork
+	// bomb would otherwise exhaust. firejail has only RLIMIT_NPROC, which
+	// counts all the processes of the user, so it has no such bound.
+	maxProcesses = 64
+	// maxOutput is how much of the output of the code the model gets back
+	maxOutput = 16 << 10
+	// killGrace is how long the sandbox has to stop the code past its timeout
+	// before it is killed itself
+	killGrace = 10 * time.Second
+)
+
+// images are the Docker images running the code of each language
+var images = map[string]string{
+	"python": "python:3.12-slim",
+	"go":     "golang:1.24-alpine",
+}
+
+// commands read the code of each language from the standard input and run it
+var commands = map[string]string{
+	"python": "python3 -",
+	"go":     "cat > main.go && go run main.go",
+}
+
+// Sandbox runs the code the models write
+type Sandbox struct {
+	// Backend is Docker or Firejail
+	Backend  string
+	Timeout  time.Duration
+	MemoryMB int
+}
+
+// New returns a sandbox run by the backend, or by Docker when it is installed
+// and by firejail otherwise when backend is empty. A zero timeout or memory
+// limit is the default one.
+func New(backend string, timeout time.Duration, memoryMB int) (ret *Sandbox, err error) {
+	switch backend {
+	case "":
+		for _, candidate := range []string{Docker, Firejail} {
+			if _, lookErr := exec.LookPath(candidate); lookErr == nil {
+				backend = candidate
+				break
+			}
+		}
+		if backend == "" {
+			return nil, errors.New(i18n.T("sandbox_error_not_found"))
+		}
+	case Docker, Firejail:
+	default:
+		return nil, fmt.Errorf(i18n.T("sandbox_error_unknown_backend"), backend)
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if memoryMB <= 0 {
+		memoryMB = DefaultMemoryMB
+	}
+	return &Sandbox{Backend: backend, Timeout: timeout, MemoryMB: memoryMB}, nil
+}
+
+// Tools returns the tool running code
+func (o *Sandbox) Tools() []domain.Tool {
+	return []domain.Tool{{
+		Name: toolName,
+		Description: fmt.Sprintf("Run a Python or Go program and return its output and errors, e.g. to compute, "+
+			"analyze data or check an answer. It has no network, %d MB of memory and %s to run. Go code is a "+
+			"complete main package. Print the results to see them.", o.MemoryMB, o.Timeout),
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"language": map[string]any{"type": "string", "enum": []string{"python", "go"}},
+				"code":     map[string]any{"type": "string", "description": "The source code of the program"},
+			},
+			"required": []string{"language", "code"},
+		},
+	}}
+}
+
+// Call runs the code of the arguments and returns its output, ending with its
+// exit status when it failed
+func (o *Sandbox) Call(ctx context.Context, name string, arguments string) (ret string, err error) {
+	if name != toolName {
+		return "", fmt.Errorf(i18n.T("mcp_error_tool_not_found"), name)
+	}
+	var args struct {
+		Language string `json:"language"`
+		Code     string `json:"code"`
+	}
+	if err = json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf(i18n.T("mcp_error_invalid_arguments"), name, err)
+	}
+	var cmdArgs []string
+	if cmdArgs, err = o.command(strings.ToLower(args.Language)); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout+killGrace)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, cmdArgs[0], cmdArgs[1:]...)
+	cmd.Stdin = strings.NewReader(args.Code)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	runErr := cmd.Run()
+
+	ret = output.String()
+	if len(ret) > maxOutput {
+		ret = ret[:maxOutput] + "\n[output truncated]"
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case runErr == nil:
+	case errors.As(runErr, &exitErr) && exitErr.ExitCode() == 137:
+		ret += fmt.Sprintf("\n[killed: out of time (%s) or memory (%d MB)]", o.Timeout, o.MemoryMB)
+	case errors.As(runErr, &exitErr) && exitErr.ExitCode() > 0:
+		ret += fmt.Sprintf("\n[exit status %d]", exitErr.ExitCode())
+	default:
+		return "", fmt.Errorf(i18n.T("sandbox_error_run"), o.Backend, runErr)
+	}
+	return
+}
+
+// Close does nothing, every run having its own sandbox
+func (o *Sandbox) Close() error {
+	return nil
+}
+
+// command returns the command line running the code of the language, read
+// from its standard input, in the sandbox
+func (o *Sandbox) command(language string) (ret []string, err error) {
+	script, ok := commands[language]
+	if !ok {
+		return nil, fmt.Errorf(i18n.T("sandbox_error_language"), language)
+	}
+	// The code is killed past its timeout from within the sandbox, which then
+	// exits
+	script = fmt.Sprintf("timeout -s KILL %d sh -c '%s'", int(o.Timeout.Seconds()), script)
+	memory := strconv.Itoa(o.MemoryMB) + "m"
+	switch o.Backend {
+	case Docker:
+		ret = []string{Docker, "run", "--rm", "-i",
+			"--network=none",
+			"--memory=" + memory, "--memory-swap=" + memory,
+			"--cpus=1",
+			"--pids-limit=" + strconv.Itoa(maxProcesses),
+			"--read-only", "--tmpfs=/tmp:exec",
+			"--workdir=/tmp", "--env=HOME=/tmp", "--env=GOCACHE=/tmp/go-build", "--env=GOFLAGS=-buildvcs=false",
+			"--security-opt=no-new-privileges", "--cap-drop=ALL",
+			images[language], "sh", "-c", script}
+	case Firejail:
+		ret = []string{Firejail, "--quiet", "--noprofile",
+			"--net=none",
+			"--private", "--private-tmp",
+			"--rlimit-as=" + strconv.Itoa(o.MemoryMB<<20),
+			"--nonewprivs", "--caps.drop=all", "--seccomp",
+			"sh", "-c", "cd ~ && " + script}
+	default:
+		return nil, fmt.Errorf(i18n.T("sandbox_error_unknown_backend"), o.Backend)
+	}
+	return
+}
@@ -0,0 +1,84 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend puts first on PATH a backend running the script, with the code on
+// its standard input, and returns its directory
+func fakeBackend(t *testing.T, name string, script string) (dir string) {
+	dir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return
+}
+
+func TestNew(t *testing.T) {
+	// Only the fake firejail is found, whatever the host has installed
+	t.Setenv("PATH", fakeBackend(t, Firejail, "true"))
+	sandbox, err := New("", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &Sandbox{Backend: Firejail, Timeout: DefaultTimeout, MemoryMB: DefaultMemoryMB}, sandbox)
+
+	t.Setenv("PATH", t.TempDir())
+	_, err = New("", 0, 0)
+	assert.Error(t, err)
+
+	_, err = New("podman", 0, 0)
+	assert.ErrorContains(t, err, "podman")
+}
+
+func TestCommand(t *testing.T) {
+	sandbox := &Sandbox{Backend: Docker, Timeout: 20 * time.Second, MemoryMB: 256}
+	args, err := sandbox.command("python")
+	require.NoError(t, err)
+	command := strings.Join(args, " ")
+	for _, want := range []string{"--network=none", "--memory=256m", "--pids-limit=64", "--read-only", "python:3.12-slim"} {
+		assert.Contains(t, command, want)
+	}
+	assert.Equal(t, "timeout -s KILL 20 sh -c 'python3 -'", args[len(args)-1])
+
+	sandbox.Backend = Firejail
+	args, err = sandbox.command("go")
+	require.NoError(t, err)
+	command = strings.Join(args, " ")
+	for _, want := range []string{"--net=none", "--private", "--rlimit-as=268435456", "go run main.go"} {
+		assert.Contains(t, command, want)
+	}
+	assert.NotContains(t, command, "--rlimit-nproc", "RLIMIT_NPROC counts the other processes of the user")
+
+	_, err = sandbox.command("ruby")
+	assert.ErrorContains(t, err, "ruby")
+}
+
+func TestCall(t *testing.T) {
+	sandbox := &Sandbox{Backend: Docker, Timeout: time.Second, MemoryMB: 64}
+
+	fakeBackend(t, Docker, "cat")
+	output, err := sandbox.Call(context.Background(), toolName, `{"language":"Python","code":"print(6 * 7)"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "print(6 * 7)", output, "the code is given on the standard input")
+
+	fakeBackend(t, Docker, "echo 'NameError: x' >&2; exit 1")
+	output, err = sandbox.Call(context.Background(), toolName, `{"language":"python","code":"print(x)"}`)
+	require.NoError(t, err, "a failing program is a result for the model")
+	assert.Equal(t, "NameError: x\n\n[exit status 1]", output)
+
+	fakeBackend(t, Docker, "exit 137")
+	output, err = sandbox.Call(context.Background(), toolName, `{"language":"go","code":"package main"}`)
+	require.NoError(t, err)
+	assert.Contains(t, output, "[killed")
+
+	_, err = sandbox.Call(context.Background(), toolName, `{"language":"ruby","code":"puts 1"}`)
+	assert.Error(t, err)
+	_, err = sandbox.Call(context.Background(), "other", `{}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package scans
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/converter"
+)
+
+// burpIssues is the XML export of the issues of Burp Suite
+type burpIssues struct {
+	Version    string      `xml:"burpVersion,attr"`
+	ExportTime string      `xml:"exportTime,attr"`
+	Issues     []burpIssue `xml:"issue"`
+}
+
+type burpIssue struct {
+	Type       string `xml:"type"`
+	Name       string `xml:"name"`
+	Host       string `xml:"host"`
+	Path       string `xml:"path"`
+	Location   string `xml:"location"`
+	Severity   string `xml:"severity"`
+	Confidence string `xml:"confidence"`
+	Background string `xml:"issueBackground"`
+	Remedy     string `xml:"remediationBackground"`
+	Detail     string `xml:"issueDetail"`
+	Requests   []struct {
+		Request struct {
+			Method string `xml:"method,attr"`
+			Base64 bool   `xml:"base64,attr"`
+			Value  string `xml:",chardata"`
+		} `xml:"request"`
+	} `xml:"requestresponse"`
+	References      string `xml:"references"`
+	Classifications string `xml:"vulnerabilityClassifications"`
+}
+
+// burpMarkdown returns the Burp Suite XML issues as Markdown, the issues of
+// a type being one finding with their locations, details and requests
+func burpMarkdown(data []byte) (ret string, err error) {
+	var export burpIssues
+	if err = xml.Unmarshal(data, &export); err != nil {
+		return "", fmt.Errorf(i18n.T("scans_error_parsing"), "Burp Suite", err)
+	}
+
+	var findings []*finding
+	byType := map[string]*finding{}
+	hosts := map[string]bool{}
+	for _, issue := range export.Issues {
+		hosts[issue.Host] = true
+		location := strings.TrimSpace(issue.Host + cmp.Or(issue.Path, issue.Location))
+		if issue.Confidence != "" {
+			location += " (" + issue.Confidence + ")"
+		}
+		if issueDetail := htmlText(issue.Detail); issueDetail != "" {
+			location += ": " + truncate(strings.Join(strings.Fields(issueDetail), " "), 300)
+		}
+		for _, request := range issue.Requests {
+			if line := requestLine(request.Request.Value, request.Request.Base64); line != "" {
+				location += " `" + line + "`"
+				break
+			}
+		}
+		key := cmp.Or(issue.Type, issue.Name)
+		if f := byType[key]; f != nil {
+			f.locations = append(f.locations, location)
+			continue
+		}
+		f := &finding{
+			title:     issue.Name,
+			severity:  strings.ToLower(strings.Replace(issue.Severity, "Information", "Info", 1)),
+			locations: []string{location},
+			text:      htmlText(issue.Background),
+		}
+		f.details = append(f.details, detail(i18n.T("scans_remediation"), truncate(htmlText(issue.Remedy), 500))...)
+		f.details = append(f.details, detail(i18n.T("scans_classifications"), htmlList(issue.Classifications))...)
+		f.details = append(f.details, detail(i18n.T("scans_references"), htmlList(issue.References))...)
+		byType[key] = f
+		findings = append(findings, f)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# " + i18n.T("scans_burp_heading") + "\n\n")
+	if export.Version != "" {
+		sb.WriteString("- Burp Suite " + export.Version + ", " + export.ExportTime + "\n")
+	}
+	sb.WriteString("- " + fmt.Sprintf(i18n.T("scans_results"), len(export.Issues), len(hosts)) + "\n")
+	findingsMarkdown(&sb, findings)
+	return sb.String(), nil
+}
+
+// htmlText returns the HTML of the issue texts as Markdown
+func htmlText(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return ""
+	}
+	text, err := converter.HtmlToMarkdown(value, converter.MarkdownOptions{})
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(text)
+}
+
+// htmlList returns the HTML list of the issue classifications or
+// references on one line, comma separated
+func htmlList(value string) string {
+	var items []string
+	for _, line := range strings.Split(htmlText(value), "\n") {
+		if line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*+")); line != "" {
+			items = append(items, line)
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+// requestLine returns the first line of the request, leaving its headers
+// and body, which can hold credentials, out
+func requestLine(value string, encoded bool) string {
+	if encoded {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return ""
+		}
+		value = string(decoded)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(value), "\n")
+	return truncate(strings.TrimSpace(line), 200)
+}
@@ -0,0 +1,165 @@
+package scans
+
+import (
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// see https://nmap.org/book/nmap-dtd.html for the format
+type nmapRun struct {
+	Args  string     `xml:"args,attr"`
+	Start int64      `xml:"start,attr"`
+	Hosts []nmapHost `xml:"host"`
+	Stats struct {
+		Hosts struct {
+			Up    int `xml:"up,attr"`
+			Total int `xml:"total,attr"`
+		} `xml:"hosts"`
+	} `xml:"runstats"`
+}
+
+type nmapHost struct {
+	Status struct {
+		State string `xml:"state,attr"`
+	} `xml:"status"`
+	Addresses []struct {
+		Addr string `xml:"addr,attr"`
+		Type string `xml:"addrtype,attr"`
+	} `xml:"address"`
+	Hostnames []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"hostnames>hostname"`
+	ExtraPorts []struct {
+		State string `xml:"state,attr"`
+		Count int    `xml:"count,attr"`
+	} `xml:"ports>extraports"`
+	Ports []struct {
+		Protocol string `xml:"protocol,attr"`
+		ID       int    `xml:"portid,attr"`
+		State    struct {
+			State  string `xml:"state,attr"`
+			Reason string `xml:"reason,attr"`
+		} `xml:"state"`
+		Service struct {
+			Name      string `xml:"name,attr"`
+			Product   string `xml:"product,attr"`
+			Version   string `xml:"version,attr"`
+			ExtraInfo string `xml:"extrainfo,attr"`
+			Tunnel    string `xml:"tunnel,attr"`
+		} `xml:"service"`
+		Scripts []nmapScript `xml:"script"`
+	} `xml:"ports>port"`
+	OS []struct {
+		Name     string `xml:"name,attr"`
+		Accuracy string `xml:"accuracy,attr"`
+	} `xml:"os>osmatch"`
+	Scripts []nmapScript `xml:"hostscript>script"`
+}
+
+type nmapScript struct {
+	ID     string `xml:"id,attr"`
+	Output string `xml:"output,attr"`
+}
+
+// nmapMarkdown returns the nmap XML report as Markdown: the scan, then each
+// host up with its ports, services and versions, OS and script outputs
+func nmapMarkdown(data []byte) (ret string, err error) {
+	var run nmapRun
+	if err = xml.Unmarshal(data, &run); err != nil {
+		return "", fmt.Errorf(i18n.T("scans_error_parsing"), "nmap", err)
+	}
+	var sb strings.Builder
+	sb.WriteString("# " + i18n.T("scans_nmap_heading") + "\n\n")
+	if run.Args != "" {
+		sb.WriteString("- " + i18n.T("scans_command") + ": `" + run.Args + "`\n")
+	}
+	if run.Start > 0 {
+		sb.WriteString("- " + i18n.T("scans_started") + ": " + time.Unix(run.Start, 0).UTC().Format("2006-01-02 15:04 MST") + "\n")
+	}
+	up := 0
+	for _, host := range run.Hosts {
+		if host.Status.State == "up" {
+			up++
+		}
+	}
+	sb.WriteString("- " + fmt.Sprintf(i18n.T("scans_hosts_up"), up, max(run.Stats.Hosts.Total, len(run.Hosts))) + "\n")
+
+	for _, host := range run.Hosts {
+		if host.Status.State != "up" {
+			continue
+		}
+		sb.WriteString("\n## " + host.name() + "\n")
+		var facts []string
+		for _, match := range host.OS[:min(len(host.OS), 1)] {
+			facts = append(facts, "- OS: "+match.Name+" ("+match.Accuracy+"%)\n")
+		}
+		for _, extra := range host.ExtraPorts {
+			facts = append(facts, "- "+fmt.Sprintf(i18n.T("scans_ports_not_shown"), extra.Count, extra.State)+"\n")
+		}
+		if len(facts) > 0 {
+			sb.WriteString("\n" + strings.Join(facts, ""))
+		}
+		if len(host.Ports) > 0 {
+			sb.WriteString("\n" + i18n.T("scans_ports_header") + "\n|---|---|---|---|\n")
+		}
+		var scripts []string
+		for _, port := range host.Ports {
+			name := strconv.Itoa(port.ID) + "/" + port.Protocol
+			service := port.Service.Name
+			if port.Service.Tunnel != "" {
+				service = port.Service.Tunnel + "/" + service
+			}
+			version := strings.Join(strings.Fields(port.Service.Product+" "+port.Service.Version), " ")
+			if port.Service.ExtraInfo != "" {
+				version = strings.TrimSpace(version + " (" + port.Service.ExtraInfo + ")")
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", name, port.State.State, service, strings.ReplaceAll(version, "|", `\|`))
+			for _, script := range port.Scripts {
+				scripts = append(scripts, script.markdown(name+" "))
+			}
+		}
+		for _, script := range host.Scripts {
+			scripts = append(scripts, script.markdown(""))
+		}
+		if len(scripts) > 0 {
+			sb.WriteString("\n### " + i18n.T("scans_scripts") + "\n\n" + strings.Join(scripts, "\n") + "\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// name returns the address of the host, with its names
+func (o *nmapHost) name() string {
+	var addresses, names []string
+	for _, address := range o.Addresses {
+		if address.Type != "mac" {
+			addresses = append(addresses, address.Addr)
+		}
+	}
+	for _, hostname := range o.Hostnames {
+		if !slices.Contains(names, hostname.Name) {
+			names = append(names, hostname.Name)
+		}
+	}
+	ret := strings.Join(addresses, ", ")
+	if len(names) > 0 {
+		ret += " (" + strings.Join(names, ", ") + ")"
+	}
+	return ret
+}
+
+// markdown returns the output of the script as a list item, its lines
+// indented under it
+func (o *nmapScript) markdown(prefix string) string {
+	output := truncate(o.Output, maxDetailBytes)
+	if !strings.Contains(output, "\n") {
+		return "- " + prefix + o.ID + ": " + output
+	}
+	return "- " + prefix + o.ID + ":\n\n  ```\n  " + strings.ReplaceAll(output, "\n", "\n  ") + "\n  ```"
+}
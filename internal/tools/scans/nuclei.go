@@ -0,0 +1,129 @@
+package scans
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// nucleiResult is a result of nuclei -json or -jsonl, see
+// https://docs.projectdiscovery.io/tools/nuclei/running#output
+type nucleiResult struct {
+	TemplateID string `json:"template-id"`
+	Info       struct {
+		Name           string          `json:"name"`
+		Severity       string          `json:"severity"`
+		Description    string          `json:"description"`
+		Remediation    string          `json:"remediation"`
+		Tags           json.RawMessage `json:"tags"`
+		Reference      json.RawMessage `json:"reference"`
+		Classification struct {
+			CVE       json.RawMessage `json:"cve-id"`
+			CWE       json.RawMessage `json:"cwe-id"`
+			CVSSScore float64         `json:"cvss-score"`
+		} `json:"classification"`
+	} `json:"info"`
+	Type             string   `json:"type"`
+	Host             string   `json:"host"`
+	MatchedAt        string   `json:"matched-at"`
+	MatcherName      string   `json:"matcher-name"`
+	ExtractedResults []string `json:"extracted-results"`
+}
+
+// nucleiMarkdown returns the nuclei JSON array or JSON lines results as
+// Markdown, the results of a template being one finding with their
+// locations. It is not ok when the JSON is not of nuclei.
+func nucleiMarkdown(data []byte) (ret string, ok bool, err error) {
+	var results []nucleiResult
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err = json.Unmarshal(data, &results); err != nil {
+			return "", false, nil
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var result nucleiResult
+			if err = json.Unmarshal(line, &result); err != nil {
+				return "", false, nil
+			}
+			results = append(results, result)
+		}
+		if err = scanner.Err(); err != nil {
+			return
+		}
+	}
+	for _, result := range results {
+		if result.TemplateID == "" {
+			return "", false, nil
+		}
+	}
+
+	var findings []*finding
+	byTemplate := map[string]*finding{}
+	hosts := map[string]bool{}
+	for _, result := range results {
+		hosts[result.Host] = true
+		location := cmp.Or(result.MatchedAt, result.Host)
+		if result.MatcherName != "" {
+			location += " [" + result.MatcherName + "]"
+		}
+		if len(result.ExtractedResults) > 0 {
+			location += ": " + truncate(strings.Join(result.ExtractedResults, ", "), 300)
+		}
+		if f := byTemplate[result.TemplateID]; f != nil {
+			if !slices.Contains(f.locations, location) {
+				f.locations = append(f.locations, location)
+			}
+			continue
+		}
+		info := result.Info
+		f := &finding{
+			title:     cmp.Or(info.Name, result.TemplateID),
+			severity:  info.Severity,
+			locations: []string{location},
+			text:      strings.TrimSpace(info.Description),
+		}
+		f.details = append(f.details, detail(i18n.T("scans_template"), result.TemplateID+" ("+result.Type+")")...)
+		f.details = append(f.details, detail("CVE", jsonList(info.Classification.CVE))...)
+		f.details = append(f.details, detail("CWE", jsonList(info.Classification.CWE))...)
+		if info.Classification.CVSSScore > 0 {
+			f.details = append(f.details, detail("CVSS", fmt.Sprintf("%.1f", info.Classification.CVSSScore))...)
+		}
+		f.details = append(f.details, detail(i18n.T("scans_tags"), jsonList(info.Tags))...)
+		f.details = append(f.details, detail(i18n.T("scans_remediation"), truncate(info.Remediation, 500))...)
+		f.details = append(f.details, detail(i18n.T("scans_references"), jsonList(info.Reference))...)
+		byTemplate[result.TemplateID] = f
+		findings = append(findings, f)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# " + i18n.T("scans_nuclei_heading") + "\n\n")
+	sb.WriteString("- " + fmt.Sprintf(i18n.T("scans_results"), len(results), len(hosts)) + "\n")
+	findingsMarkdown(&sb, findings)
+	return sb.String(), true, nil
+}
+
+// jsonList returns the JSON string or list of strings, comma separated,
+// as nuclei writes the lists of one value as a string
+func jsonList(raw json.RawMessage) string {
+	var values []string
+	if json.Unmarshal(raw, &values) == nil {
+		return strings.Join(values, ", ")
+	}
+	var value string
+	if json.Unmarshal(raw, &value) == nil {
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+// Package scans normalizes the reports of security tools, nmap XML, nuclei
+// JSON and Burp Suite XML exports, into structured Markdown for the threat
+// analysis patterns: hosts and services, and findings grouped by severity
+// with their locations.
+package scans
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	// maxLocations is the most locations listed of a finding
+	maxLocations = 20
+	// maxDetailBytes is the most bytes kept of the text of a finding
+	maxDetailBytes = 1500
+)
+
+// ReadFile returns the report of the security tool in the file as Markdown
+func ReadFile(path string) (ret string, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	return ToMarkdown(filepath.Base(path), data)
+}
+
+// ToMarkdown returns the report as Markdown, its tool being detected from
+// its content
+func ToMarkdown(name string, data []byte) (string, error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	// The XML reports start with a doctype, long for Burp Suite
+	head := string(data[:min(len(data), 8192)])
+	switch {
+	case strings.Contains(head, "<nmaprun"):
+		return nmapMarkdown(data)
+	case strings.Contains(head, "<issues"):
+		return burpMarkdown(data)
+	case bytes.HasPrefix(data, []byte("{")) || bytes.HasPrefix(data, []byte("[")):
+		if ret, ok, err := nucleiMarkdown(data); ok || err != nil {
+			return ret, err
+		}
+	}
+	return "", fmt.Errorf(i18n.T("scans_error_unknown_format"), name)
+}
+
+// finding is a finding of a scanner, with where it was found
+type finding struct {
+	title     string
+	severity  string
+	details   []string
+	text      string
+	locations []string
+}
+
+// severities are the severities of the findings, most severe first
+var severities = []string{"critical", "high", "medium", "low", "info"}
+
+// severityName returns the heading of the severity
+func severityName(severity string) string {
+	if severity == "" {
+		return i18n.T("scans_unknown_severity")
+	}
+	return strings.ToUpper(severity[:1]) + severity[1:]
+}
+
+// findingsMarkdown writes the findings grouped by severity, most severe
+// first, with their count by severity
+func findingsMarkdown(sb *strings.Builder, findings []*finding) {
+	bySeverity := map[string][]*finding{}
+	for _, f := range findings {
+		severity := strings.ToLower(f.severity)
+		if !slices.Contains(severities, severity) {
+			severity = ""
+		}
+		bySeverity[severity] = append(bySeverity[severity], f)
+	}
+	order := append(slices.Clone(severities), "")
+
+	var counts []string
+	for _, severity := range order {
+		if n := len(bySeverity[severity]); n > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d", severityName(severity), n))
+		}
+	}
+	fmt.Fprintf(sb, "- %s: %d (%s)\n", i18n.T("scans_findings"), len(findings), strings.Join(counts, ", "))
+
+	for _, severity := range order {
+		if len(bySeverity[severity]) == 0 {
+			continue
+		}
+		sb.WriteString("\n## " + severityName(severity) + "\n")
+		for _, f := range bySeverity[severity] {
+			sb.WriteString("\n### " + f.title + "\n\n")
+			for _, detail := range f.details {
+				sb.WriteString("- " + detail + "\n")
+			}
+			if len(f.locations) > 0 {
+				sb.WriteString("- " + i18n.T("scans_locations") + ":\n")
+				for _, location := range f.locations[:min(len(f.locations), maxLocations)] {
+					sb.WriteString("  - " + location + "\n")
+				}
+				if n := len(f.locations) - maxLocations; n > 0 {
+					sb.WriteString("  - " + fmt.Sprintf(i18n.T("scans_more_locations"), n) + "\n")
+				}
+			}
+			if f.text != "" {
+				sb.WriteString("\n" + truncate(f.text, maxDetailBytes) + "\n")
+			}
+		}
+	}
+}
+
+// detail returns the "Label: value" detail, empty without value
+func detail(label, value string) []string {
+	if value = strings.TrimSpace(value); value == "" {
+		return nil
+	}
+	return []string{label + ": " + value}
+}
+
+func truncate(text string, size int) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= size {
+		return text
+	}
+	return strings.ToValidUTF8(text[:size], "") + "…"
+}
@@ -0,0 +1,130 @@
+package scans
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const nmapXML = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE nmaprun>
+<?xml-stylesheet href="file:///usr/share/nmap/nmap.xsl" type="text/xsl"?>
+<nmaprun scanner="nmap" args="nmap -sV -O -oX scan.xml 10.0.0.0/30" start="1792224000" version="7.95">
+<host><status state="up" reason="arp-response"/>
+<address addr="10.0.0.5" addrtype="ipv4"/><address addr="AA:BB:CC:DD:EE:FF" addrtype="mac"/>
+<hostnames><hostname name="web.example.com" type="PTR"/></hostnames>
+<ports><extraports state="closed" count="997"><extrareasons reason="reset" count="997"/></extraports>
+<port protocol="tcp" portid="22"><state state="open" reason="syn-ack"/><service name="ssh" product="OpenSSH" version="8.9p1 Ubuntu 3ubuntu0.10" extrainfo="Ubuntu Linux; protocol 2.0"/></port>
+<port protocol="tcp" portid="443"><state state="open" reason="syn-ack"/><service name="http" product="nginx" version="1.18.0" tunnel="ssl"/>
+<script id="http-title" output="Login"/><script id="ssl-cert" output="Subject: commonName=web.example.com&#xa;Not valid after:  2026-01-01T00:00:00"/></port>
+</ports>
+<os><osmatch name="Linux 5.0 - 5.14" accuracy="98"/><osmatch name="Linux 4.15" accuracy="90"/></os>
+<hostscript><script id="smb-os-discovery" output="Windows"/></hostscript>
+</host>
+<host><status state="down" reason="no-response"/><address addr="10.0.0.6" addrtype="ipv4"/></host>
+<runstats><hosts up="1" down="3" total="4"/></runstats>
+</nmaprun>`
+
+func TestNmap(t *testing.T) {
+	got, err := ToMarkdown("scan.xml", []byte(nmapXML))
+	if err != nil {
+		t.Fatalf("ToMarkdown() error = %v", err)
+	}
+	for _, want := range []string{
+		"# Nmap scan\n\n- Command: `nmap -sV -O -oX scan.xml 10.0.0.0/30`\n- Started: 2026-10-17 08:00 UTC\n- Hosts up: 1 of 4\n",
+		"## 10.0.0.5 (web.example.com)\n\n- OS: Linux 5.0 - 5.14 (98%)\n- 997 closed ports not shown\n",
+		"| 22/tcp | open | ssh | OpenSSH 8.9p1 Ubuntu 3ubuntu0.10 (Ubuntu Linux; protocol 2.0) |",
+		"| 443/tcp | open | ssl/http | nginx 1.18.0 |",
+		"### Script results\n\n- 443/tcp http-title: Login\n- 443/tcp ssl-cert:\n\n  ```\n  Subject: commonName=web.example.com\n  Not valid after:  2026-01-01T00:00:00\n  ```\n- smb-os-discovery: Windows\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToMarkdown() = %s\nwant %q", got, want)
+		}
+	}
+	if strings.Contains(got, "10.0.0.6") || strings.Contains(got, "AA:BB") {
+		t.Errorf("ToMarkdown() = %s, should skip the hosts down and MAC addresses", got)
+	}
+}
+
+func TestNuclei(t *testing.T) {
+	jsonl := `{"template-id":"CVE-2021-44228","info":{"name":"Apache Log4j RCE","severity":"critical","description":"Log4j JNDI injection.",` +
+		`"tags":["cve","rce"],"reference":"https://logging.apache.org/log4j/2.x/security.html","classification":{"cve-id":["CVE-2021-44228"],"cwe-id":["CWE-502"],"cvss-score":10}},` +
+		`"type":"http","host":"https://a.example.com","matched-at":"https://a.example.com/api","timestamp":"2026-10-17T08:00:00Z"}
+{"template-id":"CVE-2021-44228","info":{"name":"Apache Log4j RCE","severity":"critical"},"type":"http","host":"https://b.example.com","matched-at":"https://b.example.com/login"}
+
+{"template-id":"tech-detect","info":{"name":"Wappalyzer Technology Detection","severity":"info"},"type":"http","host":"https://a.example.com","matched-at":"https://a.example.com","matcher-name":"nginx"}
+{"template-id":"weak-tls","info":{"name":"Weak TLS","severity":"medium"},"type":"ssl","host":"a.example.com:443","extracted-results":["tls10","tls11"]}`
+	got, err := ToMarkdown("nuclei.jsonl", []byte(jsonl))
+	if err != nil {
+		t.Fatalf("ToMarkdown() error = %v", err)
+	}
+	for _, want := range []string{
+		"# Nuclei findings\n\n- Results: 4 on 3 hosts\n- Findings: 3 (Critical 1, Medium 1, Info 1)\n",
+		"## Critical\n\n### Apache Log4j RCE\n\n- Template: CVE-2021-44228 (http)\n- CVE: CVE-2021-44228\n- CWE: CWE-502\n- CVSS: 10.0\n- Tags: cve, rce\n" +
+			"- References: https://logging.apache.org/log4j/2.x/security.html\n- Found at:\n  - https://a.example.com/api\n  - https://b.example.com/login\n\nLog4j JNDI injection.\n",
+		"## Medium\n\n### Weak TLS\n\n- Template: weak-tls (ssl)\n- Found at:\n  - a.example.com:443: tls10, tls11\n",
+		"  - https://a.example.com [nginx]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToMarkdown() = %s\nwant %q", got, want)
+		}
+	}
+	if strings.Index(got, "## Medium") > strings.Index(got, "## Info") {
+		t.Errorf("ToMarkdown() = %s, want the most severe first", got)
+	}
+
+	// The JSON array of nuclei -json-export
+	if got, err = ToMarkdown("nuclei.json", []byte("["+strings.Split(jsonl, "\n")[4]+"]")); err != nil || !strings.Contains(got, "### Weak TLS") {
+		t.Errorf("ToMarkdown() of an array = %s, %v", got, err)
+	}
+	if _, err = ToMarkdown("other.json", []byte(`{"name": "not nuclei"}`)); err == nil {
+		t.Errorf("ToMarkdown() of other JSON expected an error")
+	}
+}
+
+func TestBurp(t *testing.T) {
+	request := base64.StdEncoding.EncodeToString([]byte("GET /search?q=%27 HTTP/1.1\r\nHost: shop.example.com\r\nCookie: session=secret\r\n\r\n"))
+	burp := `<?xml version="1.0"?>
+<!DOCTYPE issues [<!ELEMENT issues (issue*)>]>
+<issues burpVersion="2026.9" exportTime="Fri Oct 16 10:00:00 UTC 2026">
+<issue><serialNumber>1</serialNumber><type>1049088</type><name>SQL injection</name><host ip="10.0.0.5">https://shop.example.com</host>
+<path><![CDATA[/search]]></path><location><![CDATA[/search [q parameter]]]></location><severity>High</severity><confidence>Firm</confidence>
+<issueBackground><![CDATA[<p>SQL injection lets an attacker <b>read the database</b>.</p>]]></issueBackground>
+<remediationBackground><![CDATA[<p>Use parameterized queries.</p>]]></remediationBackground>
+<vulnerabilityClassifications><![CDATA[<ul><li><a href="https://cwe.mitre.org/data/definitions/89.html">CWE-89</a></li></ul>]]></vulnerabilityClassifications>
+<issueDetail><![CDATA[The <b>q</b> parameter appears to be vulnerable.]]></issueDetail>
+<requestresponse><request method="GET" base64="true"><![CDATA[` + request + `]]></request></requestresponse></issue>
+<issue><type>1049088</type><name>SQL injection</name><host>https://shop.example.com</host><path>/cart</path><severity>High</severity><confidence>Tentative</confidence></issue>
+<issue><type>5245344</type><name>Frameable response</name><host>https://shop.example.com</host><path>/</path><severity>Information</severity><confidence>Certain</confidence></issue>
+</issues>`
+	got, err := ToMarkdown("burp.xml", []byte(burp))
+	if err != nil {
+		t.Fatalf("ToMarkdown() error = %v", err)
+	}
+	for _, want := range []string{
+		"# Burp Suite issues\n\n- Burp Suite 2026.9, Fri Oct 16 10:00:00 UTC 2026\n- Results: 3 on 1 hosts\n- Findings: 2 (High 1, Info 1)\n",
+		"## High\n\n### SQL injection\n\n- Remediation: Use parameterized queries.\n- Classifications: CWE-89\n- Found at:\n" +
+			"  - https://shop.example.com/search (Firm): The **q** parameter appears to be vulnerable. `GET /search?q=%27 HTTP/1.1`\n" +
+			"  - https://shop.example.com/cart (Tentative)\n\nSQL injection lets an attacker **read the database**.\n",
+		"## Info\n\n### Frameable response\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToMarkdown() = %s\nwant %q", got, want)
+		}
+	}
+	if strings.Contains(got, "session=secret") {
+		t.Errorf("ToMarkdown() = %s, should leave the request headers out", got)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("just notes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFile(path); err == nil || !strings.Contains(err.Error(), "notes.txt") {
+		t.Errorf("ReadFile() of an unknown format error = %v", err)
+	}
+}
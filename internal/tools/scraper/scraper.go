@@ -0,0 +1,176 @@
+// Package scraper extracts the main content of web pages as Markdown without relying
+// on a hosted service.
+//
+// Pages are fetched with browser-like headers and run through a readability pass
+// before being converted to Markdown locally. JavaScript-heavy pages can optionally
+// be rendered with a locally installed headless Chrome or Chromium first; the browser
+// is looked up on PATH or taken from the FABRIC_CHROME_PATH environment variable.
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/tools/converter"
+	"github.com/go-shiori/go-readability"
+	"golang.org/x/net/html"
+)
+
+const (
+	userAgent     = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36"
+	chromeEnvVar  = "FABRIC_CHROME_PATH"
+	maxPageSize   = 20 << 20
+	renderTimeout = 60 * time.Second
+)
+
+var chromeCandidates = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "chrome"}
+
+type Client struct {
+	httpClient *http.Client
+	// RenderJS renders the page with headless Chrome before extraction.
+	RenderJS bool
+	// NoSandbox starts Chrome without its sandbox, for containers where it
+	// cannot start otherwise. Pages are untrusted so it stays opt-in.
+	NoSandbox bool
+	// Markdown controls whether links and images are kept in the output.
+	Markdown converter.MarkdownOptions
+}
+
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ScrapeURL returns the main content of the page at pageURL as Markdown.
+func (c *Client) ScrapeURL(pageURL string) (ret string, err error) {
+	var parsedURL *url.URL
+	if parsedURL, err = url.Parse(pageURL); err != nil || parsedURL.Host == "" ||
+		(parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return "", fmt.Errorf(i18n.T("scraper_error_invalid_url"), pageURL)
+	}
+
+	var page string
+	if c.RenderJS {
+		page, err = c.render(pageURL)
+	} else {
+		page, err = c.fetch(pageURL)
+	}
+	if err != nil {
+		return
+	}
+
+	return Extract(page, parsedURL, c.Markdown)
+}
+
+// Extract runs readability over the HTML page and converts the article to Markdown.
+// If readability cannot identify an article the whole body is converted instead.
+func Extract(page string, pageURL *url.URL, opts converter.MarkdownOptions) (ret string, err error) {
+	var article readability.Article
+	if article, err = readability.FromReader(strings.NewReader(page), pageURL); err != nil || article.Node == nil {
+		var doc *html.Node
+		if doc, err = html.Parse(strings.NewReader(page)); err != nil {
+			return "", fmt.Errorf(i18n.T("scraper_error_parsing_page"), err)
+		}
+		return converter.NodeToMarkdown(doc, opts), nil
+	}
+
+	var sb strings.Builder
+	if title := strings.TrimSpace(article.Title); title != "" {
+		sb.WriteString("# " + title + "\n\n")
+	}
+	if pageURL != nil {
+		sb.WriteString("URL Source: " + pageURL.String() + "\n")
+	}
+	if article.PublishedTime != nil {
+		sb.WriteString("Published Time: " + article.PublishedTime.Format(time.RFC3339) + "\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(converter.NodeToMarkdown(article.Node, opts))
+	ret = strings.TrimSpace(sb.String())
+	return
+}
+
+func (c *Client) fetch(pageURL string) (ret string, err error) {
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodGet, pageURL, nil); err != nil {
+		return "", fmt.Errorf(i18n.T("scraper_error_fetching_page"), pageURL, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	var resp *http.Response
+	if resp, err = c.httpClient.Do(req); err != nil {
+		return "", fmt.Errorf(i18n.T("scraper_error_fetching_page"), pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf(i18n.T("scraper_error_unexpected_status"), resp.StatusCode, pageURL)
+	}
+
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(resp.Body, maxPageSize)); err != nil {
+		return "", fmt.Errorf(i18n.T("scraper_error_fetching_page"), pageURL, err)
+	}
+	ret = string(body)
+	return
+}
+
+// render loads the page in headless Chrome and returns the DOM after scripts ran.
+func (c *Client) render(pageURL string) (ret string, err error) {
+	var chrome string
+	if chrome, err = findChrome(); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), renderTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, chrome, c.chromeArgs(pageURL)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return "", fmt.Errorf(i18n.T("scraper_error_rendering_page"), pageURL, err, strings.TrimSpace(stderr.String()))
+	}
+	ret = stdout.String()
+	return
+}
+
+// chromeArgs returns the arguments of headless Chrome dumping the DOM of the page
+func (c *Client) chromeArgs(pageURL string) (ret []string) {
+	ret = []string{
+		"--headless=new",
+		"--disable-gpu",
+		"--hide-scrollbars",
+		"--virtual-time-budget=10000",
+		"--user-agent=" + userAgent,
+	}
+	if c.NoSandbox {
+		ret = append(ret, "--no-sandbox")
+	}
+	return append(ret, "--dump-dom", pageURL)
+}
+
+func findChrome() (ret string, err error) {
+	if ret = os.Getenv(chromeEnvVar); ret != "" {
+		return
+	}
+	for _, candidate := range chromeCandidates {
+		if ret, err = exec.LookPath(candidate); err == nil {
+			return
+		}
+	}
+	return "", fmt.Errorf(i18n.T("scraper_error_chrome_not_found"), chromeEnvVar)
+}
@@ -0,0 +1,86 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const samplePage = `<!DOCTYPE html>
+<html>
+<head><title>Example Article</title></head>
+<body>
+  <nav><a href="/">Home</a> <a href="/about">About</a></nav>
+  <article>
+    <h1>Example Article</h1>
+    <p>This is the first paragraph of a fairly long article that talks about many things, so that
+    readability has enough text to decide this is the main content of the page.</p>
+    <p>The second paragraph adds <a href="https://example.com/more">a link</a> and some <strong>bold</strong>
+    text, along with more sentences to make the content long enough to be considered an article.</p>
+    <ul><li>First point</li><li>Second point</li></ul>
+  </article>
+  <footer>Copyright</footer>
+  <script>console.log("ignored")</script>
+</body>
+</html>`
+
+func TestScrapeURL(t *testing.T) {
+	var gotUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(samplePage))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.Markdown.KeepLinks = true
+	markdown, err := client.ScrapeURL(server.URL + "/post")
+	if err != nil {
+		t.Fatalf("ScrapeURL() error = %v", err)
+	}
+
+	if !strings.Contains(gotUserAgent, "Mozilla") {
+		t.Errorf("User-Agent = %q, want browser-like agent", gotUserAgent)
+	}
+	for _, want := range []string{"# Example Article", "URL Source: " + server.URL + "/post", "[a link](https://example.com/more)", "**bold**", "- Second point"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("ScrapeURL() missing %q in:\n%s", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "console.log") {
+		t.Errorf("ScrapeURL() should drop scripts:\n%s", markdown)
+	}
+}
+
+func TestScrapeURLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	if _, err := client.ScrapeURL(server.URL); err == nil {
+		t.Error("expected error for 404 response")
+	}
+	if _, err := client.ScrapeURL("ftp://example.com/file"); err == nil {
+		t.Error("expected error for unsupported scheme")
+	}
+}
+
+func TestChromeArgsSandbox(t *testing.T) {
+	client := NewClient()
+	if args := client.chromeArgs("https://example.com"); slices.Contains(args, "--no-sandbox") {
+		t.Errorf("expected Chrome to keep its sandbox by default, got %v", args)
+	}
+	client.NoSandbox = true
+	args := client.chromeArgs("https://example.com")
+	if !slices.Contains(args, "--no-sandbox") {
+		t.Errorf("expected --no-sandbox when opted in, got %v", args)
+	}
+	if args[len(args)-1] != "https://example.com" {
+		t.Errorf("expected the URL last, got %v", args)
+	}
+}
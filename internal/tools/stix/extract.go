@@ -0,0 +1,174 @@
+package stix
+
+import (
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Indicator is an indicator of compromise, of a STIX cyber observable type
+// like ipv4-addr or url, or of a file hash like file:SHA-256
+type Indicator struct {
+	Type  string
+	Value string
+}
+
+// Technique is a MITRE ATT&CK technique or sub-technique
+type Technique struct {
+	ID   string
+	Name string
+}
+
+// Extracted are the indicators, techniques and vulnerabilities found in a
+// text, in their order of appearance
+type Extracted struct {
+	Indicators      []Indicator
+	Techniques      []Technique
+	Vulnerabilities []string
+}
+
+// Empty returns whether nothing was found
+func (o *Extracted) Empty() bool {
+	return len(o.Indicators) == 0 && len(o.Techniques) == 0 && len(o.Vulnerabilities) == 0
+}
+
+var (
+	// defangs are the usual ways the reports defang their indicators, so that
+	// they are not clickable
+	defangs = strings.NewReplacer(
+		"hxxps://", "https://", "hxxp://", "http://", "hXXps://", "https://", "hXXp://", "http://",
+		"[://]", "://", "[:]", ":", "[.]", ".", "(.)", ".", "{.}", ".", "[dot]", ".", "(dot)", ".",
+		"[@]", "@", "[at]", "@",
+	)
+
+	urlRegex   = regexp.MustCompile(`(?i)\b(?:https?|ftp)://[^\s<>"'` + "`" + `)\]|]+`)
+	emailRegex = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}\b`)
+	ipv4Regex  = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`)
+	// ipv6Regex matches the candidates, checked by parsing them
+	ipv6Regex   = regexp.MustCompile(`(?i)[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}`)
+	hashRegex   = regexp.MustCompile(`\b(?:[a-fA-F0-9]{64}|[a-fA-F0-9]{40}|[a-fA-F0-9]{32})\b`)
+	domainRegex = regexp.MustCompile(`(?i)\b(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+([a-z]{2,24})\b`)
+	cveRegex    = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,7}\b`)
+	// techniqueRegex matches the ids of ATT&CK techniques, like T1566 or
+	// T1566.001
+	techniqueRegex = regexp.MustCompile(`\bT[1-9]\d{3}(?:\.\d{3})?\b`)
+
+	// genericTLDs are the generic top-level domains of the domains kept, with
+	// all the two letter ones but fileExtensions, so that file and code names
+	// like main.go or os.Exit are not taken for domains
+	genericTLDs = []string{
+		"app", "asia", "bid", "biz", "buzz", "cam", "cfd", "click", "cloud", "club", "com", "company",
+		"cyou", "dev", "digital", "email", "fun", "gov", "host", "icu", "info", "int", "life", "link",
+		"live", "lol", "mil", "mobi", "monster", "name", "net", "network", "news", "onion", "online", "org",
+		"page", "pro", "rest", "sbs", "services", "shop", "site", "space", "store", "support", "tech", "today",
+		"top", "vip", "website", "win", "work", "world", "xyz",
+	}
+	fileExtensions = []string{"cs", "db", "go", "js", "md", "py", "rb", "sh", "ts"}
+)
+
+// Extract returns the indicators of compromise, ATT&CK techniques and CVEs
+// of the text, defanged indicators included
+func Extract(text string) (ret *Extracted) {
+	ret = &Extracted{}
+	text = defangs.Replace(text)
+	seen := map[string]bool{}
+	add := func(kind, value string) {
+		if key := kind + "|" + value; !seen[key] {
+			seen[key] = true
+			ret.Indicators = append(ret.Indicators, Indicator{Type: kind, Value: value})
+		}
+	}
+
+	// The URLs and email addresses are taken out of the text first, so that
+	// their domains and addresses are not found again
+	rest := urlRegex.ReplaceAllStringFunc(text, func(match string) string {
+		add("url", strings.TrimRight(match, ".,;:!?*_'"))
+		return " "
+	})
+	rest = emailRegex.ReplaceAllStringFunc(rest, func(match string) string {
+		add("email-addr", strings.ToLower(match))
+		return " "
+	})
+	rest = ipv4Regex.ReplaceAllStringFunc(rest, func(match string) string {
+		if addr, err := netip.ParseAddr(match); err == nil && !addr.IsLoopback() && !addr.IsUnspecified() && match != "255.255.255.255" {
+			add("ipv4-addr", match)
+		}
+		return " "
+	})
+	for _, bounds := range ipv6Regex.FindAllStringIndex(rest, -1) {
+		// The candidates within words, as in Foo::Bar, are not addresses
+		if (bounds[0] > 0 && isWordByte(rest[bounds[0]-1])) || (bounds[1] < len(rest) && isWordByte(rest[bounds[1]])) {
+			continue
+		}
+		match := rest[bounds[0]:bounds[1]]
+		if addr, err := netip.ParseAddr(match); err == nil && addr.Is6() && !addr.IsLoopback() && !addr.IsUnspecified() {
+			add("ipv6-addr", addr.String())
+		}
+	}
+	for _, match := range hashRegex.FindAllString(rest, -1) {
+		add(map[int]string{32: "file:MD5", 40: "file:SHA-1", 64: "file:SHA-256"}[len(match)], strings.ToLower(match))
+	}
+	for _, match := range domainRegex.FindAllStringSubmatch(rest, -1) {
+		tld := strings.ToLower(match[1])
+		if (len(tld) == 2 && !slices.Contains(fileExtensions, tld)) || slices.Contains(genericTLDs, tld) {
+			add("domain-name", strings.ToLower(match[0]))
+		}
+	}
+
+	for _, match := range cveRegex.FindAllString(text, -1) {
+		if cve := strings.ToUpper(match); !slices.Contains(ret.Vulnerabilities, cve) {
+			ret.Vulnerabilities = append(ret.Vulnerabilities, cve)
+		}
+	}
+	for _, bounds := range techniqueRegex.FindAllStringIndex(text, -1) {
+		id := text[bounds[0]:bounds[1]]
+		name := techniqueName(text, bounds[0], bounds[1])
+		if i := slices.IndexFunc(ret.Techniques, func(t Technique) bool { return t.ID == id }); i >= 0 {
+			if ret.Techniques[i].Name == id {
+				ret.Techniques[i].Name = name
+			}
+			continue
+		}
+		ret.Techniques = append(ret.Techniques, Technique{ID: id, Name: name})
+	}
+	return
+}
+
+// techniqueName returns the name of the technique written next to its id,
+// as in "T1566.001 - Spearphishing Attachment" or "Phishing (T1566)", the id
+// otherwise
+func techniqueName(text string, start, end int) string {
+	lineStart := strings.LastIndex(text[:start], "\n") + 1
+	lineEnd := len(text)
+	if i := strings.Index(text[end:], "\n"); i >= 0 {
+		lineEnd = end + i
+	}
+	const marks = "*_`"
+	// The name follows a dash or colon, or is in the next cell of a table
+	after := strings.TrimLeft(text[end:lineEnd], " "+marks)
+	if sep := strings.TrimLeft(after, "-–—:|"); len(sep) < len(after) {
+		if i := strings.IndexAny(sep, "(,;|["); i >= 0 {
+			sep = sep[:i]
+		}
+		if name := strings.Trim(sep, " ."+marks); name != "" && unicode.IsLetter([]rune(name)[0]) {
+			return name
+		}
+	}
+	before := strings.TrimRight(text[lineStart:start], " "+marks)
+	if strings.HasSuffix(before, "(") || strings.HasSuffix(before, "[") {
+		before = before[:len(before)-1]
+		if i := strings.LastIndexAny(before, "-–—:|,;(["); i >= 0 {
+			before = before[i+1:]
+		}
+		if name := strings.Trim(before, " ."+marks); name != "" {
+			return name
+		}
+	}
+	return text[start:end]
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,98 @@
+package stix
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+var httpClient = &http.Client{Timeout: 60 * time.Second}
+
+// MISP pushes the bundles to a MISP instance as new events, with the
+// authentication key of a user allowed to add events
+type MISP struct {
+	*plugins.PluginBase
+	URL    *plugins.SetupQuestion
+	APIKey *plugins.SetupQuestion
+}
+
+func NewMISP() (ret *MISP) {
+	label := "MISP"
+
+	ret = &MISP{
+		PluginBase: &plugins.PluginBase{
+			Name:             i18n.T("misp_label"),
+			SetupDescription: i18n.T("misp_setup_description") + " " + i18n.T("optional_marker"),
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+	}
+
+	ret.URL = ret.AddSetupQuestion("URL", false)
+	ret.APIKey = ret.AddSetupQuestion("API Key", false)
+
+	return
+}
+
+// CheckConfigured checks that the instance is configured, before a chat
+// pushes its output to it
+func (o *MISP) CheckConfigured() error {
+	if o.URL.Value == "" || o.APIKey.Value == "" {
+		return errors.New(i18n.T("misp_not_configured"))
+	}
+	return nil
+}
+
+// Push imports the bundle as a new event of the instance and returns the URL
+// of the event, see https://www.misp-project.org/openapi/#tag/Events
+func (o *MISP) Push(bundle *Bundle) (eventURL string, err error) {
+	if err = o.CheckConfigured(); err != nil {
+		return
+	}
+	var data []byte
+	if data, err = json.Marshal(bundle); err != nil {
+		return
+	}
+	base := strings.TrimRight(o.URL.Value, "/")
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodPost, base+"/events/upload_stix/2", bytes.NewReader(data)); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", o.APIKey.Value)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		return "", fmt.Errorf(i18n.T("misp_error_request"), err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		// The errors of MISP explain themselves in their message
+		var apiError struct {
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(body[:min(len(body), 4096)]))
+		if json.Unmarshal(body, &apiError) == nil && apiError.Message != "" {
+			message = apiError.Message
+		}
+		return "", fmt.Errorf(i18n.T("misp_error_status"), resp.StatusCode, message)
+	}
+	var event struct {
+		Event struct {
+			ID string `json:"id"`
+		} `json:"Event"`
+	}
+	// The older instances do not return the event, only that it was imported
+	if json.Unmarshal(body, &event) != nil || event.Event.ID == "" {
+		return base + "/events/index", nil
+	}
+	return base + "/events/view/" + event.Event.ID, nil
+}
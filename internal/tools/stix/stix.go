@@ -0,0 +1,152 @@
+// Package stix maps the indicators of compromise and ATT&CK techniques of
+// the output of the threat analysis patterns to STIX 2.1 bundles, written to
+// files or pushed to a MISP instance, for SOC tooling.
+package stix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/google/uuid"
+)
+
+// namespace is the namespace of the deterministic identifiers of STIX 2.1,
+// so that the same indicator has the same id in every bundle
+var namespace = uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+
+// timeLayout is the layout of the timestamps of STIX, in milliseconds
+const timeLayout = "2006-01-02T15:04:05.000Z"
+
+// Object is a STIX domain object of a bundle: an identity, indicator,
+// attack pattern, vulnerability or report
+type Object struct {
+	Type               string              `json:"type"`
+	SpecVersion        string              `json:"spec_version"`
+	ID                 string              `json:"id"`
+	Created            string              `json:"created"`
+	Modified           string              `json:"modified"`
+	CreatedByRef       string              `json:"created_by_ref,omitempty"`
+	Name               string              `json:"name"`
+	Description        string              `json:"description,omitempty"`
+	IdentityClass      string              `json:"identity_class,omitempty"`
+	Pattern            string              `json:"pattern,omitempty"`
+	PatternType        string              `json:"pattern_type,omitempty"`
+	ValidFrom          string              `json:"valid_from,omitempty"`
+	ReportTypes        []string            `json:"report_types,omitempty"`
+	Published          string              `json:"published,omitempty"`
+	ObjectRefs         []string            `json:"object_refs,omitempty"`
+	ExternalReferences []ExternalReference `json:"external_references,omitempty"`
+}
+
+// ExternalReference refers to the object in ATT&CK or the CVE list
+type ExternalReference struct {
+	SourceName string `json:"source_name"`
+	ExternalID string `json:"external_id,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+// Bundle is a STIX 2.1 bundle
+type Bundle struct {
+	Type    string    `json:"type"`
+	ID      string    `json:"id"`
+	Objects []*Object `json:"objects"`
+}
+
+// Bundle returns the bundle of an indicator by indicator of compromise, an
+// attack pattern by technique and a vulnerability by CVE, and of the report
+// of the text referring to them all, created by fabric
+func (o *Extracted) Bundle(name, text string, now time.Time) (ret *Bundle, err error) {
+	if o.Empty() {
+		return nil, errors.New(i18n.T("stix_error_nothing_found"))
+	}
+	created := now.UTC().Format(timeLayout)
+	newObject := func(kind, key, title string) *Object {
+		return &Object{
+			Type:        kind,
+			SpecVersion: "2.1",
+			ID:          kind + "--" + uuid.NewSHA1(namespace, []byte(kind+"|"+key)).String(),
+			Created:     created,
+			Modified:    created,
+			Name:        title,
+		}
+	}
+
+	identity := newObject("identity", "fabric", "fabric")
+	identity.IdentityClass = "system"
+	ret = &Bundle{Type: "bundle", ID: "bundle--" + uuid.NewString(), Objects: []*Object{identity}}
+	for _, indicator := range o.Indicators {
+		object := newObject("indicator", indicator.Pattern(), indicator.Value)
+		object.CreatedByRef = identity.ID
+		object.Pattern = indicator.Pattern()
+		object.PatternType = "stix"
+		object.ValidFrom = created
+		ret.Objects = append(ret.Objects, object)
+	}
+	for _, technique := range o.Techniques {
+		object := newObject("attack-pattern", technique.ID, technique.Name)
+		object.CreatedByRef = identity.ID
+		object.ExternalReferences = []ExternalReference{{
+			SourceName: "mitre-attack",
+			ExternalID: technique.ID,
+			URL:        "https://attack.mitre.org/techniques/" + strings.ReplaceAll(technique.ID, ".", "/") + "/",
+		}}
+		ret.Objects = append(ret.Objects, object)
+	}
+	for _, cve := range o.Vulnerabilities {
+		object := newObject("vulnerability", cve, cve)
+		object.CreatedByRef = identity.ID
+		object.ExternalReferences = []ExternalReference{{SourceName: "cve", ExternalID: cve}}
+		ret.Objects = append(ret.Objects, object)
+	}
+
+	// The report is new each time, as its text
+	report := newObject("report", "", name)
+	report.ID = "report--" + uuid.NewString()
+	report.CreatedByRef = identity.ID
+	report.Description = text
+	report.ReportTypes = []string{"threat-report"}
+	report.Published = created
+	for _, object := range ret.Objects[1:] {
+		report.ObjectRefs = append(report.ObjectRefs, object.ID)
+	}
+	ret.Objects = append(ret.Objects, report)
+	return
+}
+
+// Pattern returns the STIX pattern matching the indicator
+func (o *Indicator) Pattern() string {
+	value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(o.Value)
+	if hash, ok := strings.CutPrefix(o.Type, "file:"); ok {
+		if strings.Contains(hash, "-") {
+			hash = "'" + hash + "'"
+		}
+		return "[file:hashes." + hash + " = '" + value + "']"
+	}
+	return "[" + o.Type + ":value = '" + value + "']"
+}
+
+// CheckOutput checks that the bundle can be written to the file, as JSON
+func CheckOutput(output string) error {
+	if !strings.EqualFold(filepath.Ext(output), ".json") {
+		return fmt.Errorf(i18n.T("stix_error_format"), output)
+	}
+	return nil
+}
+
+// Write writes the bundle to the JSON file
+func Write(bundle *Bundle, output string) (err error) {
+	if err = CheckOutput(output); err != nil {
+		return
+	}
+	var data []byte
+	if data, err = json.MarshalIndent(bundle, "", "  "); err != nil {
+		return
+	}
+	return os.WriteFile(output, append(data, '\n'), 0o644)
+}
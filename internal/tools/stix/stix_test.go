@@ -0,0 +1,167 @@
+package stix
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const report = `# Threat Report: Operation Example
+
+## IOCs
+
+- C2: 203.0.113[.]7 and 2001:db8::7, https://update.example-cdn.com/gate.php?id=1.
+- Dropper domain: evil-example[.]xyz, sender: billing[@]example-mail.com
+- Payload SHA-256: 9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08
+- MD5 d41d8cd98f00b204e9800998ecf8427e, defanged hxxp://203.0.113.8/a.exe
+
+## TTPs
+
+- T1566.001 - Spearphishing Attachment
+- Command and Scripting Interpreter: PowerShell (T1059.001)
+- T1566.001 again, and T1105
+
+| Tactic | Technique | Name |
+|---|---|---|
+| Persistence | T1547.001 | Registry Run Keys |
+
+Exploits CVE-2023-4966 (cve-2023-4966). Not IOCs: main.go, README.md, os.Exit, 127.0.0.1, std::vector, 10:30:00.`
+
+func TestExtract(t *testing.T) {
+	got := Extract(report)
+	var indicators []string
+	for _, indicator := range got.Indicators {
+		indicators = append(indicators, indicator.Type+" "+indicator.Value)
+	}
+	want := []string{
+		"url https://update.example-cdn.com/gate.php?id=1",
+		"url http://203.0.113.8/a.exe",
+		"email-addr billing@example-mail.com",
+		"ipv4-addr 203.0.113.7",
+		"ipv6-addr 2001:db8::7",
+		"file:SHA-256 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		"file:MD5 d41d8cd98f00b204e9800998ecf8427e",
+		"domain-name evil-example.xyz",
+	}
+	if strings.Join(indicators, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Extract() indicators =\n%s\nwant\n%s", strings.Join(indicators, "\n"), strings.Join(want, "\n"))
+	}
+
+	wantTechniques := []Technique{
+		{"T1566.001", "Spearphishing Attachment"},
+		{"T1059.001", "PowerShell"},
+		{"T1105", "T1105"},
+		{"T1547.001", "Registry Run Keys"},
+	}
+	if fmt.Sprint(got.Techniques) != fmt.Sprint(wantTechniques) {
+		t.Errorf("Extract() techniques = %v, want %v", got.Techniques, wantTechniques)
+	}
+	if fmt.Sprint(got.Vulnerabilities) != "[CVE-2023-4966]" {
+		t.Errorf("Extract() vulnerabilities = %v", got.Vulnerabilities)
+	}
+}
+
+func TestBundle(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	if _, err := Extract("Nothing to see here.").Bundle("fabric", "", now); err == nil {
+		t.Errorf("Bundle() without indicators expected an error")
+	}
+
+	extracted := &Extracted{
+		Indicators:      []Indicator{{"ipv4-addr", "203.0.113.7"}, {"file:SHA-256", "ab"}, {"url", `http://x.example.com/it's`}},
+		Techniques:      []Technique{{"T1566.001", "Spearphishing Attachment"}},
+		Vulnerabilities: []string{"CVE-2023-4966"},
+	}
+	bundle, err := extracted.Bundle("fabric analyze_threat_report", report, now)
+	if err != nil {
+		t.Fatalf("Bundle() error = %v", err)
+	}
+	if len(bundle.Objects) != 7 || bundle.Type != "bundle" || !strings.HasPrefix(bundle.ID, "bundle--") {
+		t.Fatalf("Bundle() = %+v", bundle)
+	}
+	identity, indicator, report := bundle.Objects[0], bundle.Objects[1], bundle.Objects[6]
+	if identity.Type != "identity" || indicator.CreatedByRef != identity.ID {
+		t.Errorf("Bundle() identity = %+v, indicator = %+v", identity, indicator)
+	}
+	if indicator.Pattern != "[ipv4-addr:value = '203.0.113.7']" || indicator.ValidFrom != "2026-10-17T09:00:00.000Z" || indicator.SpecVersion != "2.1" {
+		t.Errorf("Bundle() indicator = %+v", indicator)
+	}
+	if got := bundle.Objects[2].Pattern; got != "[file:hashes.'SHA-256' = 'ab']" {
+		t.Errorf("Bundle() hash pattern = %s", got)
+	}
+	if got := bundle.Objects[3].Pattern; got != `[url:value = 'http://x.example.com/it\'s']` {
+		t.Errorf("Bundle() escaped pattern = %s", got)
+	}
+	if got := bundle.Objects[4].ExternalReferences; got[0].URL != "https://attack.mitre.org/techniques/T1566/001/" || got[0].ExternalID != "T1566.001" {
+		t.Errorf("Bundle() attack pattern references = %+v", got)
+	}
+	if report.Type != "report" || report.Name != "fabric analyze_threat_report" || len(report.ObjectRefs) != 5 || report.ObjectRefs[4] != bundle.Objects[5].ID {
+		t.Errorf("Bundle() report = %+v", report)
+	}
+
+	// The same indicators have the same ids, the reports do not
+	again, _ := extracted.Bundle("fabric", "", now.Add(time.Hour))
+	if again.Objects[1].ID != indicator.ID || again.Objects[6].ID == report.ID {
+		t.Errorf("Bundle() ids = %s, %s, want the same indicator and a new report", again.Objects[1].ID, again.Objects[6].ID)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	bundle, _ := Extract(report).Bundle("fabric", report, time.Now())
+	if err := Write(bundle, filepath.Join(t.TempDir(), "out.txt")); err == nil {
+		t.Errorf("Write() to a .txt file expected an error")
+	}
+	path := filepath.Join(t.TempDir(), "out.json")
+	if err := Write(bundle, path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	var written map[string]any
+	if err := json.Unmarshal(data, &written); err != nil || written["type"] != "bundle" {
+		t.Errorf("Write() = %s, %v", data, err)
+	}
+}
+
+func TestMISPPush(t *testing.T) {
+	var pushed Bundle
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"name": "Authentication failed.", "message": "Authentication failed. Please make sure you pass the API key.", "url": "/events/upload_stix/2"}`)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/events/upload_stix/2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &pushed)
+		fmt.Fprint(w, `{"Event": {"id": "42", "info": "fabric"}}`)
+	}))
+	defer server.Close()
+
+	bundle, _ := Extract(report).Bundle("fabric", report, time.Now())
+	misp := NewMISP()
+	if _, err := misp.Push(bundle); err == nil {
+		t.Errorf("Push() without configuration expected an error")
+	}
+	misp.URL.Value, misp.APIKey.Value = server.URL+"/", "wrong"
+	if _, err := misp.Push(bundle); err == nil || !strings.Contains(err.Error(), "Please make sure you pass the API key") {
+		t.Errorf("Push() with a wrong key error = %v, want the message of MISP", err)
+	}
+	misp.APIKey.Value = "secret"
+	eventURL, err := misp.Push(bundle)
+	if err != nil || eventURL != server.URL+"/events/view/42" {
+		t.Errorf("Push() = %s, %v", eventURL, err)
+	}
+	if pushed.ID != bundle.ID || len(pushed.Objects) != len(bundle.Objects) {
+		t.Errorf("Push() sent %+v", pushed)
+	}
+}
@@ -0,0 +1,349 @@
+// Package tui draws the full-screen pickers and prompts of fabric --tui with
+// Bubble Tea.
+package tui
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	// minPreviewWidth is the terminal width under which the preview is hidden
+	minPreviewWidth = 60
+	maxListWidth    = 40
+)
+
+var (
+	boldStyle     = lipgloss.NewStyle().Bold(true)
+	dimStyle      = lipgloss.NewStyle().Faint(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+)
+
+// Item is an entry of a picker
+type Item struct {
+	// Value is what the item stands for, empty for an entry like "none"
+	Value string
+	// Label is shown and searched, Value when empty
+	Label string
+	// Detail is shown dimmed after the label, e.g. the vendor of a model
+	Detail string
+	// Preview returns the text shown next to the list, when set
+	Preview func() string
+}
+
+func (o Item) label() string {
+	if o.Label != "" {
+		return o.Label
+	}
+	return o.Value
+}
+
+// FilterValue is the label, for the list
+func (o Item) FilterValue() string {
+	return o.label()
+}
+
+// Result is how a picker or input ended
+type Result int
+
+const (
+	// Pending means the picker is still waiting for a choice
+	Pending Result = iota
+	// Selected means an item was chosen with Enter
+	Selected
+	// Back means the picker was left with Escape
+	Back
+	// Quit means Ctrl-C or Ctrl-D was pressed
+	Quit
+)
+
+// Picker is a Bubble Tea model of a list of items filtered by fuzzy search as
+// the query is typed, with the preview of the selected item on the right
+type Picker struct {
+	Title  string
+	items  []Item
+	query  textinput.Model
+	list   list.Model
+	result Result
+	width  int
+	height int
+	// previews caches the preview lines of the items by label
+	previews map[string][]string
+}
+
+// NewPicker creates a picker of the items, in their order until a query is typed
+func NewPicker(title string, items []Item) *Picker {
+	query := textinput.New()
+	query.Prompt = "> "
+	query.Focus()
+	ret := &Picker{Title: title, items: items, query: query, previews: map[string][]string{}}
+	ret.list = list.New(listItems(items), itemDelegate{}, 0, 0)
+	ret.list.SetShowTitle(false)
+	ret.list.SetShowStatusBar(false)
+	ret.list.SetShowPagination(false)
+	ret.list.SetShowHelp(false)
+	ret.list.SetFilteringEnabled(false)
+	ret.list.DisableQuitKeybindings()
+	ret.resize(80, 24)
+	return ret
+}
+
+// Pick shows the picker on the alternate screen of out until an item is
+// selected, or it is left with Escape or Ctrl-C
+func Pick(picker *Picker, in io.Reader, out io.Writer) (Result, error) {
+	if _, err := tea.NewProgram(picker, tea.WithAltScreen(), tea.WithInput(in), tea.WithOutput(out)).Run(); err != nil {
+		return Quit, err
+	}
+	return picker.result, nil
+}
+
+// Selected returns the item under the cursor, nil when nothing matches
+func (o *Picker) Selected() *Item {
+	if item, ok := o.list.SelectedItem().(Item); ok {
+		return &item
+	}
+	return nil
+}
+
+func (o *Picker) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update moves the cursor with the arrows, Page Up and Down, Home and End, and
+// types the query with the other keys
+func (o *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		o.resize(msg.Width, msg.Height)
+		return o, nil
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyUp:
+			o.list.CursorUp()
+		case tea.KeyDown:
+			o.list.CursorDown()
+		case tea.KeyPgUp:
+			o.list.PrevPage()
+		case tea.KeyPgDown:
+			o.list.NextPage()
+		case tea.KeyHome:
+			o.list.GoToStart()
+		case tea.KeyEnd:
+			o.list.GoToEnd()
+		case tea.KeyCtrlU:
+			o.query.Reset()
+			o.filter()
+		case tea.KeyEnter:
+			if o.Selected() != nil {
+				return o.done(Selected)
+			}
+		case tea.KeyEsc:
+			return o.done(Back)
+		case tea.KeyCtrlC, tea.KeyCtrlD:
+			return o.done(Quit)
+		default:
+			previous := o.query.Value()
+			var cmd tea.Cmd
+			o.query, cmd = o.query.Update(msg)
+			if o.query.Value() != previous {
+				o.filter()
+			}
+			return o, cmd
+		}
+		return o, nil
+	}
+	var cmd tea.Cmd
+	o.query, cmd = o.query.Update(msg)
+	return o, cmd
+}
+
+func (o *Picker) done(result Result) (tea.Model, tea.Cmd) {
+	o.result = result
+	return o, tea.Quit
+}
+
+func (o *Picker) filter() {
+	o.list.SetItems(listItems(Match(o.items, o.query.Value())))
+	o.list.ResetSelected()
+}
+
+// resize fits the list to the terminal, next to the preview when it is wide
+// enough
+func (o *Picker) resize(width, height int) {
+	o.width, o.height = width, height
+	listWidth := width
+	if width >= minPreviewWidth {
+		listWidth = min(maxListWidth, width/3)
+	}
+	o.list.SetSize(listWidth, max(1, height-4))
+	o.query.Width = max(1, width-len(o.query.Prompt)-1)
+}
+
+// View returns the picker: the title, the query, the matching items and the
+// preview of the selected one on the right, and the keys at the bottom.
+func (o *Picker) View() string {
+	if o.result != Pending {
+		return ""
+	}
+	rows := o.list.Height()
+	lines := []string{
+		boldStyle.Render(truncate(fmt.Sprintf("%s (%d/%d)", o.Title, len(o.list.Items()), len(o.items)), o.width)),
+		o.query.View(),
+		strings.Repeat("─", o.width),
+	}
+
+	var items []string
+	if len(o.list.Items()) > 0 {
+		items = strings.Split(o.list.View(), "\n")
+	}
+	var preview []string
+	previewWidth := o.width - o.list.Width() - 3
+	if previewWidth > 0 {
+		if item := o.Selected(); item != nil && item.Preview != nil {
+			preview = o.preview(item)
+		}
+	}
+	for row := range rows {
+		line := strings.Repeat(" ", o.list.Width())
+		if row < len(items) && items[row] != "" {
+			line = items[row]
+		}
+		if previewWidth > 0 {
+			line += " " + dimStyle.Render("│") + " "
+			if row < len(preview) {
+				line += truncate(preview[row], previewWidth)
+			}
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, dimStyle.Render(truncate(i18n.T("tui_picker_keys"), o.width)))
+	return strings.Join(lines, "\n")
+}
+
+func (o *Picker) preview(item *Item) []string {
+	key := item.label()
+	if lines, ok := o.previews[key]; ok {
+		return lines
+	}
+	text := strings.NewReplacer("\r", "", "\t", "    ").Replace(item.Preview())
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	o.previews[key] = lines
+	return lines
+}
+
+func listItems(items []Item) []list.Item {
+	ret := make([]list.Item, len(items))
+	for i, item := range items {
+		ret[i] = item
+	}
+	return ret
+}
+
+// itemDelegate draws the items of the list on a line each, padded to the width
+// of the list so that the selected one is highlighted across it
+type itemDelegate struct{}
+
+func (itemDelegate) Height() int                             { return 1 }
+func (itemDelegate) Spacing() int                            { return 0 }
+func (itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	item := listItem.(Item)
+	width := m.Width()
+	label := truncate(item.label(), width)
+	padding := width - utf8.RuneCountInString(label)
+	detail := ""
+	if item.Detail != "" && padding > 2 {
+		detail = truncate(" "+item.Detail, padding)
+		padding -= utf8.RuneCountInString(detail)
+		detail = dimStyle.Render(detail)
+	}
+	line := label + detail + strings.Repeat(" ", padding)
+	if index == m.Index() {
+		line = selectedStyle.Render(line)
+	}
+	io.WriteString(w, line)
+}
+
+// truncate cuts the text to the width, in runes, ending it with … when cut
+func truncate(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:width-1]) + "…"
+}
+
+// Match returns the items whose label has the letters of the query in order,
+// ignoring case, the best matches first. The items are returned as they are for
+// an empty query.
+func Match(items []Item, query string) []Item {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return items
+	}
+	type match struct {
+		item  Item
+		score int
+	}
+	var matches []match
+	for _, item := range items {
+		if score, ok := fuzzyScore(item.label(), query); ok {
+			matches = append(matches, match{item, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return b.score - a.score
+	})
+	ret := make([]Item, len(matches))
+	for i, m := range matches {
+		ret[i] = m.item
+	}
+	return ret
+}
+
+// fuzzyScore scores how well the label matches the lowercase query, its letters
+// scoring more when they follow each other or start the label or a word, and
+// shorter labels scoring more
+func fuzzyScore(label, query string) (score int, ok bool) {
+	runes := []rune(strings.ToLower(label))
+	next, previous := 0, -2
+	for _, q := range query {
+		found := false
+		for ; next < len(runes); next++ {
+			if runes[next] != q {
+				continue
+			}
+			score++
+			if next == previous+1 {
+				score += 5
+			}
+			if next == 0 {
+				score += 10
+			} else if !unicode.IsLetter(runes[next-1]) && !unicode.IsDigit(runes[next-1]) {
+				score += 8
+			}
+			previous = next
+			next++
+			found = true
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return score*100 - len(runes), true
+}
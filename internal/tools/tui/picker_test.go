@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func labels(items []Item) (ret []string) {
+	for _, item := range items {
+		ret = append(ret, item.label())
+	}
+	return
+}
+
+func TestMatch(t *testing.T) {
+	items := []Item{{Value: "analyze_claims"}, {Value: "summarize"}, {Value: "create_summary"}, {Value: "summarize_paper"}}
+
+	assert.Equal(t, labels(items), labels(Match(items, "")))
+	// Matches at the start of a word and shorter names come first
+	assert.Equal(t, []string{"summarize", "summarize_paper", "create_summary"}, labels(Match(items, "summ")))
+	assert.Equal(t, []string{"summarize_paper"}, labels(Match(items, "SumPap")))
+	assert.Empty(t, Match(items, "xyz"))
+}
+
+// press sends the keys to the model, runes typed as text
+func press(model tea.Model, keys ...any) {
+	for _, key := range keys {
+		switch key := key.(type) {
+		case string:
+			for _, r := range key {
+				model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			}
+		case tea.KeyType:
+			model.Update(tea.KeyMsg{Type: key})
+		}
+	}
+}
+
+func TestPickerKeys(t *testing.T) {
+	items := []Item{{Label: "(none)"}, {Value: "summarize"}, {Value: "extract_wisdom"}}
+	picker := NewPicker("Pattern", items)
+
+	press(picker, tea.KeyDown)
+	assert.Equal(t, Pending, picker.result)
+	assert.Equal(t, "summarize", picker.Selected().Value)
+	press(picker, tea.KeyEnd)
+	assert.Equal(t, "extract_wisdom", picker.Selected().Value)
+	press(picker, tea.KeyDown)
+	assert.Equal(t, "extract_wisdom", picker.Selected().Value)
+
+	// Typing filters and moves back to the best match, the keys of the list
+	// like j and k being typed
+	press(picker, "wis")
+	require.NotNil(t, picker.Selected())
+	assert.Equal(t, "extract_wisdom", picker.Selected().Value)
+	press(picker, "z")
+	assert.Nil(t, picker.Selected())
+	press(picker, tea.KeyEnter)
+	assert.Equal(t, Pending, picker.result)
+	press(picker, tea.KeyBackspace, tea.KeyEnter)
+	assert.Equal(t, Selected, picker.result)
+
+	picker = NewPicker("Pattern", items)
+	press(picker, "jk", tea.KeyCtrlU)
+	assert.Equal(t, "(none)", picker.Selected().label())
+	press(picker, tea.KeyEsc)
+	assert.Equal(t, Back, picker.result)
+	picker = NewPicker("Pattern", items)
+	press(picker, tea.KeyCtrlC)
+	assert.Equal(t, Quit, picker.result)
+}
+
+func TestPickerView(t *testing.T) {
+	picker := NewPicker("Pattern", []Item{
+		{Value: "summarize", Preview: func() string { return "# summarize\n\nSummarizes the input." }},
+		{Value: "extract_wisdom", Detail: "Ollama"},
+	})
+
+	picker.Update(tea.WindowSizeMsg{Width: 90, Height: 10})
+	lines := strings.Split(picker.View(), "\n")
+	require.Len(t, lines, 10)
+	assert.Contains(t, lines[0], "Pattern (2/2)")
+	assert.True(t, strings.HasPrefix(lines[1], "> "))
+	// The selected item has its preview on the right
+	assert.Contains(t, lines[3], "summarize")
+	assert.Contains(t, lines[3], "# summarize")
+	assert.Contains(t, lines[5], "Summarizes the input.")
+	assert.Contains(t, lines[4], "extract_wisdom")
+	assert.Contains(t, lines[4], "Ollama")
+
+	// Narrow terminals have no preview
+	picker.Update(tea.WindowSizeMsg{Width: 40, Height: 10})
+	lines = strings.Split(picker.View(), "\n")
+	assert.NotContains(t, lines[3], "# summarize")
+}
+
+func TestPickerViewScrolls(t *testing.T) {
+	var items []Item
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		items = append(items, Item{Value: name})
+	}
+	picker := NewPicker("Pattern", items)
+	picker.Update(tea.WindowSizeMsg{Width: 30, Height: 7})
+	press(picker, tea.KeyEnd)
+
+	lines := strings.Split(picker.View(), "\n")
+	assert.Contains(t, lines[5], "f")
+	assert.NotContains(t, strings.Join(lines[3:6], "\n"), "c")
+}
+
+func TestInput(t *testing.T) {
+	input := NewInput("Input of summarize")
+	press(input, tea.KeyCtrlD)
+	assert.Equal(t, Pending, input.result, "an empty input is not sent")
+	press(input, "first", tea.KeyEnter, "second", tea.KeyCtrlD)
+	assert.Equal(t, Selected, input.result)
+	assert.Equal(t, "first\nsecond", input.Value())
+
+	input = NewInput("Input of summarize")
+	press(input, tea.KeyEsc)
+	assert.Equal(t, Back, input.result)
+}
+
+func TestAgain(t *testing.T) {
+	model := &again{prompt: "Again?"}
+	press(model, "x", tea.KeyEnter)
+	assert.True(t, model.done && model.answer)
+
+	model = &again{prompt: "Again?"}
+	press(model, "q")
+	assert.True(t, model.done)
+	assert.False(t, model.answer)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "summarize", truncate("summarize", 9))
+	assert.Equal(t, "summ…", truncate("summarize", 5))
+	assert.Equal(t, "", truncate("summarize", 0))
+}
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// Input is a Bubble Tea model of a text area where the input of a pattern is
+// typed or pasted
+type Input struct {
+	Title  string
+	area   textarea.Model
+	result Result
+}
+
+// NewInput creates an empty input, of any length
+func NewInput(title string) *Input {
+	area := textarea.New()
+	area.ShowLineNumbers = false
+	area.MaxHeight = 0
+	area.Focus()
+	ret := &Input{Title: title, area: area}
+	ret.resize(80, 24)
+	return ret
+}
+
+// ReadInput shows the input on the alternate screen of out until Ctrl-D sends
+// it, or it is left with Escape or Ctrl-C
+func ReadInput(input *Input, in io.Reader, out io.Writer) (text string, result Result, err error) {
+	if _, err = tea.NewProgram(input, tea.WithAltScreen(), tea.WithInput(in), tea.WithOutput(out)).Run(); err != nil {
+		return "", Quit, err
+	}
+	return input.Value(), input.result, nil
+}
+
+// Value returns the text typed or pasted
+func (o *Input) Value() string {
+	return o.area.Value()
+}
+
+func (o *Input) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+func (o *Input) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		o.resize(msg.Width, msg.Height)
+		return o, nil
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlD:
+			if strings.TrimSpace(o.area.Value()) != "" {
+				o.result = Selected
+				return o, tea.Quit
+			}
+			return o, nil
+		case tea.KeyEsc:
+			o.result = Back
+			return o, tea.Quit
+		case tea.KeyCtrlC:
+			o.result = Quit
+			return o, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	o.area, cmd = o.area.Update(msg)
+	return o, cmd
+}
+
+func (o *Input) resize(width, height int) {
+	o.area.SetWidth(width)
+	o.area.SetHeight(max(1, height-2))
+}
+
+// View returns the title, the text area and the keys at the bottom
+func (o *Input) View() string {
+	if o.result != Pending {
+		return ""
+	}
+	return boldStyle.Render(o.Title) + "\n" + o.area.View() + "\n" + dimStyle.Render(i18n.T("tui_input_keys"))
+}
+
+// again is a Bubble Tea model asking on a line whether to go on, with Enter,
+// or to quit, with q, Escape or Ctrl-C
+type again struct {
+	prompt string
+	answer bool
+	done   bool
+}
+
+// Again asks on the terminal of out whether to go on after an output, which is
+// left on the screen
+func Again(prompt string, in io.Reader, out io.Writer) (bool, error) {
+	model := &again{prompt: prompt}
+	if _, err := tea.NewProgram(model, tea.WithInput(in), tea.WithOutput(out)).Run(); err != nil {
+		return false, err
+	}
+	return model.answer, nil
+}
+
+func (o *again) Init() tea.Cmd {
+	return nil
+}
+
+func (o *again) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Type == tea.KeyEnter:
+			o.answer, o.done = true, true
+		case key.Type == tea.KeyEsc, key.Type == tea.KeyCtrlC, key.Type == tea.KeyCtrlD, key.String() == "q":
+			o.done = true
+		default:
+			return o, nil
+		}
+		return o, tea.Quit
+	}
+	return o, nil
+}
+
+func (o *again) View() string {
+	if o.done {
+		return o.prompt + "\n"
+	}
+	return o.prompt
+}
@@ -0,0 +1,146 @@
+package voyage
+
+// see https://docs.voyageai.com/reference/embeddings-api for more information
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+const defaultBaseURL = "https://api.voyageai.com/v1"
+
+type Client struct {
+	*plugins.PluginBase
+	ApiKey *plugins.SetupQuestion
+
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient() (ret *Client) {
+
+	label := "Voyage"
+
+	ret = &Client{
+		PluginBase: &plugins.PluginBase{
+			Name:             i18n.T("voyage_label"),
+			SetupDescription: i18n.T("voyage_setup_description") + " " + i18n.T("optional_marker"),
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+		baseURL:    defaultBaseURL,
+		httpClient: &http.Client{},
+	}
+
+	ret.ApiKey = ret.AddSetupQuestion("API Key", false)
+
+	return
+}
+
+type embeddingsRequest struct {
+	Input []string `json:"input"`
+	Model string   `json:"model"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Embedding []float64 `json:"embedding"`
+		Index     int       `json:"index"`
+	} `json:"data"`
+}
+
+type rerankRequest struct {
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	Model     string   `json:"model"`
+	TopK      int      `json:"top_k,omitempty"`
+}
+
+type rerankResponse struct {
+	Data []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"data"`
+}
+
+type errorResponse struct {
+	Detail string `json:"detail"`
+}
+
+// Embeddings returns the embeddings of the inputs computed by a Voyage model such
+// as voyage-3.5.
+func (o *Client) Embeddings(ctx context.Context, inputs []string, model string) (ret [][]float64, err error) {
+	var result embeddingsResponse
+	if err = o.post(ctx, "/embeddings", embeddingsRequest{Input: inputs, Model: model}, &result); err != nil {
+		return
+	}
+
+	if len(result.Data) != len(inputs) {
+		return nil, fmt.Errorf(i18n.T("embeddings_count_mismatch"), len(result.Data), len(inputs))
+	}
+	ret = make([][]float64, len(inputs))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(inputs) {
+			return nil, fmt.Errorf(i18n.T("embeddings_count_mismatch"), len(result.Data), len(inputs))
+		}
+		ret[item.Index] = item.Embedding
+	}
+	return
+}
+
+// Rerank orders the documents by relevance to the query with a Voyage reranker
+// such as rerank-2.5.
+func (o *Client) Rerank(ctx context.Context, query string, documents []string, model string, topN int) (ret []ai.RerankResult, err error) {
+	var result rerankResponse
+	if err = o.post(ctx, "/rerank", rerankRequest{Query: query, Documents: documents, Model: model, TopK: topN}, &result); err != nil {
+		return
+	}
+
+	for _, item := range result.Data {
+		ret = append(ret, ai.RerankResult{Index: item.Index, Score: item.RelevanceScore})
+	}
+	ai.SortRerankResults(ret)
+	return
+}
+
+func (o *Client) post(ctx context.Context, path string, request, response any) (err error) {
+	var body []byte
+	if body, err = json.Marshal(request); err != nil {
+		return
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+path, bytes.NewReader(body)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+o.ApiKey.Value)
+
+	var resp *http.Response
+	if resp, err = o.httpClient.Do(req); err != nil {
+		return fmt.Errorf(i18n.T("voyage_error_sending_request"), err)
+	}
+	defer resp.Body.Close()
+
+	if body, err = io.ReadAll(resp.Body); err != nil {
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		var result errorResponse
+		if json.Unmarshal(body, &result) != nil || result.Detail == "" {
+			result.Detail = string(bytes.TrimSpace(body))
+		}
+		return fmt.Errorf(i18n.T("voyage_error_status"), resp.StatusCode, result.Detail)
+	}
+	if err = json.Unmarshal(body, response); err != nil {
+		err = fmt.Errorf(i18n.T("voyage_error_parsing_response"), err)
+	}
+	return
+}
@@ -0,0 +1,80 @@
+package voyage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient()
+	client.ApiKey.Value = "test-key"
+	client.baseURL = server.URL
+	return client
+}
+
+func TestEmbeddings(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var req embeddingsRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "voyage-3.5", req.Model)
+		assert.Equal(t, []string{"first", "second"}, req.Input)
+
+		_, _ = w.Write([]byte(`{"data":[{"embedding":[0.3,0.4],"index":1},{"embedding":[0.1,0.2],"index":0}]}`))
+	})
+
+	embeddings, err := client.Embeddings(context.Background(), []string{"first", "second"}, "voyage-3.5")
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{0.1, 0.2}, {0.3, 0.4}}, embeddings)
+}
+
+func TestEmbeddingsError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"detail":"Provided API key is invalid."}`))
+	})
+
+	_, err := client.Embeddings(context.Background(), []string{"text"}, "voyage-3.5")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Contains(t, err.Error(), "Provided API key is invalid.")
+}
+
+func TestEmbeddingsCountMismatch(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"embedding":[0.1],"index":0}]}`))
+	})
+
+	_, err := client.Embeddings(context.Background(), []string{"first", "second"}, "voyage-3.5")
+	assert.Error(t, err)
+}
+
+func TestRerank(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rerank", r.URL.Path)
+
+		var req rerankRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "rerank-2.5", req.Model)
+		assert.Equal(t, "question", req.Query)
+		assert.Equal(t, 2, req.TopK)
+
+		_, _ = w.Write([]byte(`{"data":[{"index":0,"relevance_score":0.2},{"index":2,"relevance_score":0.9}]}`))
+	})
+
+	results, err := client.Rerank(context.Background(), "question", []string{"a", "b", "c"}, "rerank-2.5", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []ai.RerankResult{{Index: 2, Score: 0.9}, {Index: 0, Score: 0.2}}, results)
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of
+	// "<timestamp>.<body>" with the secret, when one is set
+	SignatureHeader = "X-Fabric-Signature"
+	// TimestampHeader carries the Unix time of the delivery, signed with the
+	// body so that old deliveries cannot be replayed
+	TimestampHeader = "X-Fabric-Timestamp"
+	// EventHeader carries the event of the payload
+	EventHeader = "X-Fabric-Event"
+
+	EventCompleted = "completed"
+	EventFailed    = "failed"
+)
+
+const (
+	timeout  = 30 * time.Second
+	attempts = 3
+)
+
+// retryDelay is the delay before the second attempt, doubled for the next ones
+var retryDelay = time.Second
+
+// Payload is what is posted to a webhook when a request finishes
+type Payload struct {
+	Event  string `json:"event"`
+	Output string `json:"output,omitempty"`
+	// Results are the responses of each prompt of a --serve job
+	Results  []string  `json:"results,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Pattern  string    `json:"pattern,omitempty"`
+	Strategy string    `json:"strategy,omitempty"`
+	Context  string    `json:"context,omitempty"`
+	Session  string    `json:"session,omitempty"`
+	Vendor   string    `json:"vendor,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	JobID    string    `json:"jobId,omitempty"`
+	Finished time.Time `json:"finished"`
+}
+
+// Sign returns the value of SignatureHeader for the body sent at the timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts the payload as JSON to the URL, signed with the secret unless it
+// is empty. Network errors and server errors are retried, other answers than
+// 2xx fail at once.
+func Send(ctx context.Context, url, secret string, payload *Payload) (err error) {
+	var body []byte
+	if body, err = json.Marshal(payload); err != nil {
+		return
+	}
+	client := &http.Client{Timeout: timeout}
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		if retry, err = deliver(ctx, client, url, secret, payload.Event, body); err == nil || !retry || attempt == attempts {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func deliver(ctx context.Context, client *http.Client, url, secret, event string, body []byte) (retry bool, err error) {
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body)); err != nil {
+		return false, fmt.Errorf(i18n.T("webhook_failed"), url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+	}
+
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return ctx.Err() == nil, fmt.Errorf(i18n.T("webhook_failed"), url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests,
+			fmt.Errorf(i18n.T("webhook_failed_status"), url, resp.Status)
+	}
+	return false, nil
+}
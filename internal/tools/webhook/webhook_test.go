@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSend_Signed(t *testing.T) {
+	var got Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if signature := Sign("secret", r.Header.Get(TimestampHeader), body); r.Header.Get(SignatureHeader) != signature {
+			t.Errorf("signature = %q, want %q", r.Header.Get(SignatureHeader), signature)
+		}
+		if r.Header.Get(EventHeader) != EventCompleted {
+			t.Errorf("event = %q", r.Header.Get(EventHeader))
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("invalid payload %s: %v", body, err)
+		}
+	}))
+	defer server.Close()
+
+	payload := &Payload{Event: EventCompleted, Output: "Summary", Pattern: "summarize", Finished: time.Now()}
+	if err := Send(context.Background(), server.URL, "secret", payload); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.Output != "Summary" || got.Pattern != "summarize" {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestSend_Retries(t *testing.T) {
+	retryDelay = time.Millisecond
+	defer func() { retryDelay = time.Second }()
+
+	tests := []struct {
+		name     string
+		status   int
+		wantCall int32
+		wantErr  bool
+	}{
+		{name: "server error", status: http.StatusBadGateway, wantCall: attempts, wantErr: true},
+		{name: "client error", status: http.StatusNotFound, wantCall: 1, wantErr: true},
+		{name: "accepted", status: http.StatusAccepted, wantCall: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				if r.Header.Get(SignatureHeader) != "" {
+					t.Error("signed without a secret")
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := Send(context.Background(), server.URL, "", &Payload{Event: EventFailed, Error: "boom"})
+			if (err != nil) != tt.wantErr || calls.Load() != tt.wantCall {
+				t.Errorf("Send() error = %v after %d calls, want error %v after %d", err, calls.Load(), tt.wantErr, tt.wantCall)
+			}
+		})
+	}
+}
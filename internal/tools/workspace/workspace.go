@@ -0,0 +1,159 @@
+// Package workspace lets the models save the files they produce, restricted to
+// a workspace directory, every write being confirmed first unless told
+// otherwise and recorded in a transaction log.
+package workspace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+const (
+	toolName = "write_file"
+	// LogFile is the transaction log of the workspace, one JSON entry a line
+	LogFile = ".fabric-writes.jsonl"
+)
+
+// Confirm asks whether to write the content to the path of the workspace,
+// the operation being create or update
+type Confirm func(path, operation string, content string) bool
+
+// Entry is a write of the transaction log
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Path      string    `json:"path"`
+	Operation string    `json:"operation"`
+	Bytes     int       `json:"bytes"`
+	SHA256    string    `json:"sha256"`
+	// Status is written, or declined when the write was not confirmed
+	Status string `json:"status"`
+}
+
+// Workspace writes the files of the models in its directory
+type Workspace struct {
+	Dir string
+	// Confirm is asked before every write, nil writing without asking
+	Confirm Confirm
+
+	root *os.Root
+	// mu asks and writes one file at a time
+	mu sync.Mutex
+}
+
+// Open returns the workspace of the directory, created when missing
+func Open(dir string, confirm Confirm) (ret *Workspace, err error) {
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf(i18n.T("workspace_error_open"), dir, err)
+	}
+	var root *os.Root
+	if root, err = os.OpenRoot(dir); err != nil {
+		return nil, fmt.Errorf(i18n.T("workspace_error_open"), dir, err)
+	}
+	return &Workspace{Dir: dir, Confirm: confirm, root: root}, nil
+}
+
+// Tools returns the tool writing files
+func (o *Workspace) Tools() []domain.Tool {
+	return []domain.Tool{{
+		Name: toolName,
+		Description: "Save a file, e.g. a report, a script or data, to the workspace of the user, replacing the " +
+			"file of the same path. Call it once for each file. The path is relative to the workspace, whose " +
+			"subdirectories are created as needed.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path":    map[string]any{"type": "string", "description": "The relative path of the file, e.g. docs/summary.md"},
+				"content": map[string]any{"type": "string", "description": "The whole content of the file"},
+			},
+			"required": []string{"path", "content"},
+		},
+	}}
+}
+
+// Call writes the file of the arguments once confirmed and tells the model
+// what was done
+func (o *Workspace) Call(ctx context.Context, name string, arguments string) (ret string, err error) {
+	if name != toolName {
+		return "", fmt.Errorf(i18n.T("mcp_error_tool_not_found"), name)
+	}
+	var args struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	}
+	if err = json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf(i18n.T("mcp_error_invalid_arguments"), name, err)
+	}
+	path := filepath.Clean(filepath.FromSlash(args.Path))
+	if args.Path == "" || !filepath.IsLocal(path) || path == LogFile {
+		return "", fmt.Errorf(i18n.T("workspace_error_path"), args.Path)
+	}
+	if len(args.Content) > domain.MaxFileSize {
+		return "", fmt.Errorf(i18n.T("workspace_error_too_large"), args.Path, len(args.Content), domain.MaxFileSize)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entry := Entry{Path: filepath.ToSlash(path), Operation: "update", Bytes: len(args.Content), Status: "written"}
+	sum := sha256.Sum256([]byte(args.Content))
+	entry.SHA256 = hex.EncodeToString(sum[:])
+	if _, statErr := o.root.Stat(path); errors.Is(statErr, fs.ErrNotExist) {
+		entry.Operation = "create"
+	}
+
+	if o.Confirm != nil && !o.Confirm(entry.Path, entry.Operation, args.Content) {
+		entry.Status = "declined"
+		if err = o.record(entry); err != nil {
+			return
+		}
+		return fmt.Sprintf("The user declined writing %s, it was not saved.", entry.Path), nil
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err = o.root.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf(i18n.T("workspace_error_write"), entry.Path, err)
+		}
+	}
+	if err = o.root.WriteFile(path, []byte(args.Content), 0o644); err != nil {
+		return "", fmt.Errorf(i18n.T("workspace_error_write"), entry.Path, err)
+	}
+	if err = o.record(entry); err != nil {
+		return
+	}
+	return fmt.Sprintf("Saved %s (%s, %d bytes).", entry.Path, entry.Operation, entry.Bytes), nil
+}
+
+// record appends the entry to the transaction log
+func (o *Workspace) record(entry Entry) (err error) {
+	entry.Time = time.Now().UTC()
+	var line []byte
+	if line, err = json.Marshal(entry); err != nil {
+		return
+	}
+	var file *os.File
+	if file, err = o.root.OpenFile(LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err != nil {
+		return fmt.Errorf(i18n.T("workspace_error_log"), err)
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf(i18n.T("workspace_error_log"), err)
+	}
+	return file.Close()
+}
+
+// Close closes the workspace directory
+func (o *Workspace) Close() error {
+	return o.root.Close()
+}
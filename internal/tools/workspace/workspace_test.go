@@ -0,0 +1,80 @@
+package workspace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readLog returns the entries of the transaction log of the directory
+func readLog(t *testing.T, dir string) (ret []Entry) {
+	file, err := os.Open(filepath.Join(dir, LogFile))
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		ret = append(ret, entry)
+	}
+	return
+}
+
+func TestCall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	var asked []string
+	workspace, err := Open(dir, func(path, operation string, content string) bool {
+		asked = append(asked, operation+" "+path)
+		return path != "secret.txt"
+	})
+	require.NoError(t, err)
+	defer workspace.Close()
+
+	output, err := workspace.Call(context.Background(), toolName, `{"path":"docs/summary.md","content":"# Summary"}`)
+	require.NoError(t, err)
+	assert.Contains(t, output, "docs/summary.md")
+	content, err := os.ReadFile(filepath.Join(dir, "docs", "summary.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Summary", string(content))
+
+	_, err = workspace.Call(context.Background(), toolName, `{"path":"docs/summary.md","content":"# Summary v2"}`)
+	require.NoError(t, err)
+
+	output, err = workspace.Call(context.Background(), toolName, `{"path":"secret.txt","content":"no"}`)
+	require.NoError(t, err, "a declined write is a result for the model")
+	assert.Contains(t, output, "declined")
+	assert.NoFileExists(t, filepath.Join(dir, "secret.txt"))
+
+	assert.Equal(t, []string{"create docs/summary.md", "update docs/summary.md", "create secret.txt"}, asked)
+	entries := readLog(t, dir)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "written", entries[1].Status)
+	assert.Equal(t, "update", entries[1].Operation)
+	assert.Equal(t, len("# Summary v2"), entries[1].Bytes)
+	assert.Equal(t, "declined", entries[2].Status)
+}
+
+func TestCallStaysInWorkspace(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "out")
+	workspace, err := Open(dir, nil)
+	require.NoError(t, err)
+	defer workspace.Close()
+
+	for _, path := range []string{"", "../escape.txt", "/etc/passwd", "docs/../../escape.txt", LogFile} {
+		_, err = workspace.Call(context.Background(), toolName, `{"path":"`+path+`","content":"x"}`)
+		assert.Error(t, err, path)
+	}
+
+	// A symbolic link to outside of the workspace is not followed
+	require.NoError(t, os.Symlink(parent, filepath.Join(dir, "link")))
+	_, err = workspace.Call(context.Background(), toolName, `{"path":"link/escape.txt","content":"x"}`)
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(parent, "escape.txt"))
+}
@@ -0,0 +1,52 @@
+package youtube
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/i18n"
+)
+
+// channelBaseURL is where the pages of the channels given by their handle are
+var channelBaseURL = "https://www.youtube.com/"
+
+// channelIdRegex matches the ID of a channel, alone, in its URL or in the feed
+// link and metadata of its page
+var channelIdRegex = regexp.MustCompile(`(?:^|/channel/|channel_id=|"externalId":")(UC[a-zA-Z0-9_-]{22})`)
+
+// ChannelFeedURL returns the URL of the feed of the latest videos of a
+// channel, given by its ID, its URL or its @handle. The page of the channel
+// is fetched to find its ID when needed.
+func ChannelFeedURL(channel string) (ret string, err error) {
+	channel = strings.TrimSpace(channel)
+	if match := channelIdRegex.FindStringSubmatch(channel); match != nil {
+		return feedURL(match[1]), nil
+	}
+
+	pageURL := channel
+	if !strings.HasPrefix(channel, "http://") && !strings.HasPrefix(channel, "https://") {
+		pageURL = channelBaseURL + "@" + strings.TrimPrefix(channel, "@")
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	var resp *http.Response
+	if resp, err = client.Get(pageURL); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	var page []byte
+	if page, err = io.ReadAll(io.LimitReader(resp.Body, 10<<20)); err != nil {
+		return
+	}
+	if match := channelIdRegex.FindSubmatch(page); resp.StatusCode == http.StatusOK && match != nil {
+		return feedURL(string(match[1])), nil
+	}
+	return "", fmt.Errorf(i18n.T("youtube_channel_not_found"), channel)
+}
+
+func feedURL(channelId string) string {
+	return "https://www.youtube.com/feeds/videos.xml?channel_id=" + channelId
+}
@@ -0,0 +1,35 @@
+package youtube
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChannelFeedURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/@fabric" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`<link rel="alternate" type="application/rss+xml" href="https://www.youtube.com/feeds/videos.xml?channel_id=UCabcdefghijklmnopqrstuv">`))
+	}))
+	defer server.Close()
+	channelBaseURL = server.URL + "/"
+	defer func() { channelBaseURL = "https://www.youtube.com/" }()
+
+	want := "https://www.youtube.com/feeds/videos.xml?channel_id=UCabcdefghijklmnopqrstuv"
+	for _, channel := range []string{
+		"UCabcdefghijklmnopqrstuv",
+		"https://www.youtube.com/channel/UCabcdefghijklmnopqrstuv",
+		"@fabric",
+		"fabric",
+	} {
+		if got, err := ChannelFeedURL(channel); err != nil || got != want {
+			t.Errorf("ChannelFeedURL(%q) = %q, %v, want %q", channel, got, err, want)
+		}
+	}
+	if _, err := ChannelFeedURL("@missing"); err == nil {
+		t.Error("ChannelFeedURL() found a missing channel")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -509,7 +510,7 @@ func (o *YouTube) GrabComments(videoId string) (ret []string, err error) {
 	call := o.service.CommentThreads.List([]string{"snippet", "replies"}).VideoId(videoId).TextFormat("plainText").MaxResults(100)
 	var response *youtube.CommentThreadListResponse
 	if response, err = call.Do(); err != nil {
-		log.Printf(i18n.T("youtube_failed_fetch_comments"), err)
+		slog.Warn(fmt.Sprintf(i18n.T("youtube_failed_fetch_comments"), err))
 		return
 	}
 
@@ -0,0 +1,72 @@
+package util
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// fileState is what WatchFiles compares to tell a file changed
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// WatchFiles checks the files of the paths, and the files under the
+// directories among them, every interval until the context is done. It calls
+// onChange with the files created, modified or removed since the previous
+// check. Missing paths are watched for their creation.
+func WatchFiles(ctx context.Context, paths []string, interval time.Duration, onChange func(changed []string)) {
+	previous := snapshotFiles(paths)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current := snapshotFiles(paths)
+		if changed := changedFiles(previous, current); len(changed) > 0 {
+			onChange(changed)
+		}
+		previous = current
+	}
+}
+
+func snapshotFiles(paths []string) map[string]fileState {
+	ret := map[string]fileState{}
+	for _, root := range paths {
+		if root == "" {
+			continue
+		}
+		// Unreadable and missing paths are skipped, they count as changed once readable
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+			if info, infoErr := entry.Info(); infoErr == nil {
+				ret[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+	}
+	return ret
+}
+
+func changedFiles(previous, current map[string]fileState) (ret []string) {
+	for path, state := range current {
+		if previousState, ok := previous[path]; !ok || previousState != state {
+			ret = append(ret, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			ret = append(ret, path)
+		}
+	}
+	slices.Sort(ret)
+	return
+}
@@ -0,0 +1,184 @@
+// Package fabric lets Go programs run fabric patterns without shelling out.
+// The client uses the vendors, default model and patterns set up with
+// fabric --setup:
+//
+//	client, err := fabric.New(nil)
+//	if err != nil {
+//		return err
+//	}
+//	summary, err := client.RunPattern(ctx, &fabric.Request{Pattern: "summarize", Input: article})
+package fabric
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/i18n"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// Options configure a Client. The zero value uses ~/.config/fabric.
+type Options struct {
+	// ConfigDir is the directory of the .env file, the patterns, contexts and
+	// sessions, ~/.config/fabric when empty
+	ConfigDir string
+}
+
+// Request is a chat with a pattern. Only Input or Pattern is required; the
+// fields left empty use the defaults of the configuration.
+type Request struct {
+	// Pattern is the name of the pattern used as system prompt
+	Pattern string
+	// Input is the user message, e.g. the text the pattern works on
+	Input string
+	// Variables replace the {{variables}} of the pattern
+	Variables map[string]string
+	// Context names one or more comma separated contexts
+	Context string
+	// Session keeps the conversation under this name, continuing it if it exists
+	Session  string
+	Strategy string
+	// Model is the model name, the default model when empty
+	Model string
+	// Vendor is the vendor of the model, found from the model when empty
+	Vendor string
+	// Language is the language code of the response, e.g. "fr"
+	Language string
+
+	// Temperature, TopP and the penalties default to the values of the CLI
+	Temperature      *float64
+	TopP             *float64
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+	Seed             int
+	// Raw uses the defaults of the model instead of sending the temperature, top P, etc.
+	Raw bool
+	// Deterministic samples reproducibly, with temperature 0, top P 1 and the
+	// Seed or a fixed one, whatever the other sampling fields say
+	Deterministic bool
+}
+
+// Client runs patterns with the configured vendors. It is safe for concurrent
+// use as long as the requests do not share a session.
+type Client struct {
+	registry *core.PluginRegistry
+}
+
+// New loads the configuration and returns a client. The .env file of the
+// configuration directory is loaded into the environment, unless the
+// environment sets DEFAULT_MODEL and there is no .env file.
+func New(opts *Options) (ret *Client, err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	dir := opts.ConfigDir
+	if dir == "" {
+		var homedir string
+		if homedir, err = os.UserHomeDir(); err != nil {
+			return
+		}
+		dir = filepath.Join(homedir, ".config/fabric")
+	}
+
+	db := fsdb.NewDb(dir)
+	if err = db.Configure(); err != nil {
+		return
+	}
+	var registry *core.PluginRegistry
+	if registry, err = core.NewPluginRegistry(db); err != nil {
+		return
+	}
+	return &Client{registry: registry}, nil
+}
+
+// RunPattern sends the request and returns the response once complete.
+func (o *Client) RunPattern(ctx context.Context, req *Request) (string, error) {
+	return o.send(ctx, req, nil)
+}
+
+// Stream sends the request and calls onContent with the parts of the response
+// as they come, one call at a time and all of them before Stream returns. It
+// returns the whole response.
+func (o *Client) Stream(ctx context.Context, req *Request, onContent func(content string)) (ret string, err error) {
+	updates := make(chan domain.StreamUpdate)
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for update := range updates {
+			if update.Type == domain.StreamTypeContent && onContent != nil {
+				onContent(update.Content)
+			}
+		}
+	}()
+	ret, err = o.send(ctx, req, updates)
+	close(updates)
+	<-forwarded
+	return
+}
+
+// ListPatterns returns the names of the patterns, the custom ones included.
+func (o *Client) ListPatterns() ([]string, error) {
+	return o.registry.Db.Patterns.GetNames()
+}
+
+// send sends the request, streaming the response to updates unless it is nil
+func (o *Client) send(ctx context.Context, req *Request, updates chan domain.StreamUpdate) (ret string, err error) {
+	if req == nil || (req.Input == "" && req.Pattern == "") {
+		return "", errors.New(i18n.T("sdk_request_missing_input"))
+	}
+
+	var chatter *core.Chatter
+	if chatter, err = o.registry.GetChatter(req.Model, 0, req.Vendor, updates != nil, false); err != nil {
+		return
+	}
+
+	chatReq := &domain.ChatRequest{
+		ContextName:      req.Context,
+		SessionName:      req.Session,
+		PatternName:      req.Pattern,
+		PatternVariables: req.Variables,
+		StrategyName:     req.Strategy,
+		Language:         req.Language,
+	}
+	if chatReq.Language == "" {
+		chatReq.Language = o.registry.Language.DefaultLanguage.Value
+	}
+	if req.Input != "" {
+		chatReq.Message = &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: strings.TrimSpace(req.Input)}
+	}
+
+	chatOptions := &domain.ChatOptions{
+		Model:            req.Model,
+		Temperature:      valueOr(req.Temperature, domain.DefaultTemperature),
+		TopP:             valueOr(req.TopP, domain.DefaultTopP),
+		PresencePenalty:  valueOr(req.PresencePenalty, domain.DefaultPresencePenalty),
+		FrequencyPenalty: valueOr(req.FrequencyPenalty, domain.DefaultFrequencyPenalty),
+		Seed:             req.Seed,
+		Raw:              req.Raw,
+		UpdateChan:       updates,
+		// The response is returned, never printed
+		Quiet: true,
+	}
+	if req.Deterministic {
+		chatOptions.SetDeterministic()
+	}
+
+	var session *fsdb.Session
+	if session, err = chatter.Send(ctx, chatReq, chatOptions); err != nil {
+		return
+	}
+	return session.GetLastMessage().Content, nil
+}
+
+func valueOr(value *float64, fallback float64) float64 {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
@@ -0,0 +1,65 @@
+package fabric
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client answering with the Mock vendor, which has a
+// summarize pattern
+func newTestClient(t *testing.T) *Client {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("DEFAULT_VENDOR", "Mock")
+	t.Setenv("DEFAULT_MODEL", "echo")
+	t.Setenv("MOCK_LATENCY", "0")
+	patternDir := filepath.Join(dir, "patterns", "summarize")
+	require.NoError(t, os.MkdirAll(patternDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(patternDir, "system.md"), []byte("Summarize for {{audience}}."), 0644))
+
+	client, err := New(&Options{ConfigDir: dir})
+	require.NoError(t, err)
+	return client
+}
+
+func TestRunPattern(t *testing.T) {
+	client := newTestClient(t)
+
+	reply, err := client.RunPattern(context.Background(), &Request{
+		Pattern:   "summarize",
+		Input:     "the text",
+		Variables: map[string]string{"audience": "kids"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, reply, "Summarize for kids.")
+	assert.Contains(t, reply, "the text")
+
+	_, err = client.RunPattern(context.Background(), &Request{})
+	assert.Error(t, err, "a request without input nor pattern")
+}
+
+func TestStream(t *testing.T) {
+	client := newTestClient(t)
+
+	var streamed strings.Builder
+	reply, err := client.Stream(context.Background(), &Request{Input: "hello"}, func(content string) {
+		streamed.WriteString(content)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, reply, "hello")
+	assert.Equal(t, reply, streamed.String())
+}
+
+func TestListPatterns(t *testing.T) {
+	client := newTestClient(t)
+
+	names, err := client.ListPatterns()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"summarize"}, names)
+}
@@ -0,0 +1,97 @@
+#!/usr/bin/env python3
+
+"""Generates the description and tags of data/patterns/*/pattern.yaml from
+pattern_descriptions.json, keeping the other settings of the files (variables,
+requires) as they are.
+
+pattern_descriptions.json is the source of the descriptions and tags: edit it,
+then run this script. With --check the files are left unchanged and the script
+fails when one of them is out of date, as CI does.
+"""
+
+import json
+import os
+import re
+import sys
+
+PLAIN_SCALAR = re.compile(r"^[A-Za-z0-9(][^#]*$")
+
+
+def yaml_scalar(value):
+    """Return the value as a YAML scalar, quoted only when it must be"""
+    if PLAIN_SCALAR.match(value) and ": " not in value and not value.endswith(":"):
+        return value
+    return json.dumps(value, ensure_ascii=False)
+
+
+def split_blocks(content):
+    """Split the YAML mapping into (key, lines) blocks of its top-level keys"""
+    blocks = []
+    for line in content.splitlines():
+        if line and not line[0].isspace() and not line.startswith("-"):
+            blocks.append((line.split(":", 1)[0].strip(), [line]))
+        elif blocks:
+            blocks[-1][1].append(line)
+    return blocks
+
+
+def render(entry, existing):
+    """Return the pattern.yaml content of the pattern described by entry"""
+    lines = []
+    if entry:
+        if entry.get("description"):
+            lines.append("description: " + yaml_scalar(entry["description"]))
+        if entry.get("tags"):
+            lines.append("tags:")
+            lines.extend("  - " + yaml_scalar(tag) for tag in entry["tags"])
+    for key, block in split_blocks(existing):
+        if key not in ("description", "tags"):
+            lines.extend(block)
+    return "\n".join(lines) + "\n" if lines else ""
+
+
+def main():
+    check = "--check" in sys.argv[1:]
+    script_dir = os.path.dirname(os.path.abspath(__file__))
+    patterns_dir = os.path.join(script_dir, "..", "..", "data", "patterns")
+
+    with open(
+        os.path.join(script_dir, "pattern_descriptions.json"), "r", encoding="utf-8"
+    ) as f:
+        entries = {p["patternName"]: p for p in json.load(f)["patterns"]}
+
+    outdated = []
+    for dirname in sorted(os.listdir(patterns_dir)):
+        pattern_path = os.path.join(patterns_dir, dirname)
+        if not os.path.exists(os.path.join(pattern_path, "system.md")):
+            continue
+
+        metadata_path = os.path.join(pattern_path, "pattern.yaml")
+        existing = ""
+        if os.path.exists(metadata_path):
+            with open(metadata_path, "r", encoding="utf-8") as f:
+                existing = f.read()
+
+        content = render(entries.get(dirname), existing)
+        if content == existing:
+            continue
+        outdated.append(metadata_path)
+        if check:
+            continue
+        if content:
+            with open(metadata_path, "w", encoding="utf-8") as f:
+                f.write(content)
+        else:
+            os.remove(metadata_path)
+
+    if check and outdated:
+        print("Out of date with pattern_descriptions.json, run generate_pattern_metadata.py:")
+        for path in outdated:
+            print("  " + os.path.relpath(path, os.path.join(script_dir, "..", "..")))
+        sys.exit(1)
+    if not check:
+        print(f"Updated {len(outdated)} pattern.yaml files")
+
+
+if __name__ == "__main__":
+    main()